@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+// callJSON prints the raw tool result as JSON
+// callJSON 以 JSON 格式输出原始工具结果
+var callJSON bool
+
+// callCmd executes a single tool call and exits; the exit code is non-zero when the result is flagged isError
+// callCmd 执行一次工具调用后退出；结果被标记为 isError 时退出码非零
+var callCmd = &cobra.Command{
+	Use:   "call <tool> [key=value...]",
+	Short: "Call a tool once and exit",
+	Long: `执行一次工具调用并输出结果，适用于脚本和 CI。
+参数使用 key=value 格式，合法的 JSON 值（数字、布尔等）会按类型传递。
+工具返回 isError 时退出码为 1。`,
+	Example: `  k8s-mcp-client call list_resources resource_type=pods namespace=default --json
+  k8s-mcp-client call get_pod_logs pod_name=web-0 tail_lines=50`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		client, err := connectClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		return callTool(ctx, client, args[0], args[1:], callJSON)
+	},
+}
+
+func init() {
+	callCmd.Flags().BoolVar(&callJSON, "json", false, "Print the raw result as JSON")
+	rootCmd.AddCommand(callCmd)
+}
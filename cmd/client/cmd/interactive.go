@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+	"github.com/AceDarkknight/k8s-mcp/pkg/mcpclient"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/viper"
+)
+
+// historyFileName is the history file in the user's home directory
+// historyFileName 是用户主目录下的历史记录文件名
+const historyFileName = ".k8s-mcp-client_history"
+
+// interactiveCommands are the commands of the interactive shell
+// interactiveCommands 是交互式命令行支持的命令
+var interactiveCommands = []string{"help", "tools", "call", "resources", "read", "prompts", "prompt", "profile", "quit", "exit"}
+
+// completer provides tab completion of commands, tool and prompt names,
+// argument keys and resource URIs
+// completer 为命令、工具和提示名称、参数名以及资源 URI 提供 Tab 补全
+type completer struct {
+	mu         sync.RWMutex
+	client     *mcpclient.Client
+	toolArgs   map[string][]string // 工具名 -> 参数名 / tool name -> argument keys
+	promptArgs map[string][]string // 提示名 -> 参数名 / prompt name -> argument keys
+	uris       []string
+	profiles   []string
+}
+
+// setClient sets the client used to refresh the completions
+// setClient 设置用于刷新补全数据的客户端
+func (c *completer) setClient(client *mcpclient.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = client
+}
+
+// setProfiles sets the profile names completed after 'profile use'
+// setProfiles 设置 'profile use' 之后补全的 profile 名称
+func (c *completer) setProfiles(profiles []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.profiles = profiles
+}
+
+// toolsChanged reloads the tools after a tools/list_changed notification
+// toolsChanged 在收到 tools/list_changed 通知后重新加载工具
+func (c *completer) toolsChanged() {
+	c.mu.RLock()
+	client := c.client
+	c.mu.RUnlock()
+	if client == nil {
+		return
+	}
+	if err := c.refreshTools(context.Background(), client); err != nil {
+		logger.Get().Warn("Failed to refresh tools for completion", "error", err)
+	}
+}
+
+// refreshTools reloads the tool names and argument keys from tools/list
+// refreshTools 从 tools/list 重新加载工具名称和参数名
+func (c *completer) refreshTools(ctx context.Context, client *mcpclient.Client) error {
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return err
+	}
+
+	toolArgs := make(map[string][]string, len(tools))
+	for _, tool := range tools {
+		toolArgs[tool.Name] = schemaProperties(tool.InputSchema)
+	}
+
+	c.mu.Lock()
+	c.toolArgs = toolArgs
+	c.mu.Unlock()
+	return nil
+}
+
+// refreshPromptsAndResources reloads the prompt names, their arguments and the resource URIs
+// refreshPromptsAndResources 重新加载提示名称、提示参数和资源 URI
+func (c *completer) refreshPromptsAndResources(ctx context.Context, client *mcpclient.Client) error {
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		return err
+	}
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	promptArgs := make(map[string][]string, len(prompts))
+	for _, prompt := range prompts {
+		var args []string
+		for _, arg := range prompt.Arguments {
+			args = append(args, arg.Name)
+		}
+		promptArgs[prompt.Name] = args
+	}
+	var uris []string
+	for _, resource := range resources {
+		uris = append(uris, resource.URI)
+	}
+
+	c.mu.Lock()
+	c.promptArgs = promptArgs
+	c.uris = uris
+	c.mu.Unlock()
+	return nil
+}
+
+// Do implements readline.AutoCompleter
+// Do 实现 readline.AutoCompleter 接口
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	words := strings.Fields(string(line[:pos]))
+	// 光标位于空白之后时正在输入一个新词
+	// After trailing whitespace the user is starting a new word
+	if pos == 0 || line[pos-1] == ' ' {
+		words = append(words, "")
+	}
+	current := words[len(words)-1]
+
+	var candidates []string
+	suffix := " "
+	switch {
+	case len(words) == 1:
+		candidates = interactiveCommands
+	case len(words) == 2 && words[0] == "call":
+		candidates = keys(c.toolArgs)
+	case len(words) == 2 && words[0] == "prompt":
+		candidates = keys(c.promptArgs)
+	case len(words) == 2 && words[0] == "read":
+		candidates = c.uris
+	case len(words) == 2 && words[0] == "profile":
+		candidates = []string{"list", "use"}
+	case len(words) == 3 && words[0] == "profile" && words[1] == "use":
+		candidates = c.profiles
+	case len(words) > 2 && words[0] == "call":
+		candidates, suffix = unusedArgs(c.toolArgs[words[1]], words[2:len(words)-1]), "="
+	case len(words) > 2 && words[0] == "prompt":
+		candidates, suffix = unusedArgs(c.promptArgs[words[1]], words[2:len(words)-1]), "="
+	}
+
+	var matches [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, []rune(candidate[len(current):]+suffix))
+		}
+	}
+	return matches, len([]rune(current))
+}
+
+// keys returns the sorted keys of m
+// keys 返回 m 中排序后的键
+func keys(m map[string][]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// unusedArgs returns the argument keys not yet present as key=value in typed
+// unusedArgs 返回尚未以 key=value 形式输入的参数名
+func unusedArgs(args []string, typed []string) []string {
+	used := make(map[string]bool, len(typed))
+	for _, word := range typed {
+		if key, _, ok := strings.Cut(word, "="); ok {
+			used[key] = true
+		}
+	}
+	var result []string
+	for _, arg := range args {
+		if !used[arg] {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
+
+// schemaProperties returns the sorted property names of a JSON schema
+// schemaProperties 返回 JSON Schema 中排序后的属性名
+func schemaProperties(schema any) []string {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var parsed struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil
+	}
+	result := make([]string, 0, len(parsed.Properties))
+	for name := range parsed.Properties {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// historyFile returns the path of the history file, or "" when the home directory is unknown
+// historyFile 返回历史记录文件路径，无法确定主目录时返回空字符串
+func historyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFileName)
+}
+
+// runInteractive runs the interactive shell until quit or EOF. Ctrl+C at the
+// prompt clears the line; during a command it cancels the in-flight call. The
+// shell closes the client, or the one 'profile use' replaced it with, when it ends.
+// runInteractive 运行交互式命令行直到 quit 或 EOF。在提示符处按 Ctrl+C 清空当前行，命令执行期间按 Ctrl+C 取消正在进行的调用。
+// 结束时关闭客户端，或 'profile use' 替换后的客户端。
+func runInteractive(ctx context.Context, client *mcpclient.Client, comp *completer) error {
+	log := logger.Get()
+	defer func() { client.Close() }()
+
+	refreshCompletions(ctx, client, comp)
+	if file, err := loadProfiles(viper.GetString("profile-file")); err == nil {
+		comp.setProfiles(file.names())
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:            "> ",
+		HistoryFile:       historyFile(),
+		HistorySearchFold: true,
+		AutoComplete:      comp,
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "exit",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize readline: %w", err)
+	}
+	defer rl.Close()
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input := strings.TrimSpace(line)
+		if input == "" {
+			continue
+		}
+		if input == "quit" || input == "exit" {
+			return nil
+		}
+		if fields := strings.Fields(input); fields[0] == "profile" {
+			if switched, err := handleProfileCommand(ctx, fields[1:], comp); err != nil {
+				log.Error("Command execution failed", "error", err)
+			} else if switched != nil {
+				client.Close()
+				client = switched
+			}
+			continue
+		}
+
+		if err := runCancellable(ctx, func(ctx context.Context) error {
+			return handleCommand(ctx, client, input)
+		}); err != nil {
+			if errors.Is(err, context.Canceled) {
+				fmt.Println("Cancelled")
+				continue
+			}
+			log.Error("Command execution failed", "error", err)
+		}
+	}
+}
+
+// refreshCompletions loads the tools, prompts and resource URIs completed by the shell
+// refreshCompletions 加载命令行补全所用的工具、提示和资源 URI
+func refreshCompletions(ctx context.Context, client *mcpclient.Client, comp *completer) {
+	log := logger.Get()
+	if err := comp.refreshTools(ctx, client); err != nil {
+		log.Warn("Failed to load tools for completion", "error", err)
+	}
+	if err := comp.refreshPromptsAndResources(ctx, client); err != nil {
+		log.Warn("Failed to load prompts and resources for completion", "error", err)
+	}
+}
+
+// handleProfileCommand runs 'profile list' and 'profile use <name>' in the shell. use
+// connects with the profile for the rest of the session and returns the new client;
+// unlike the profile use subcommand it does not change the file's current profile.
+// handleProfileCommand 在命令行中执行 'profile list' 和 'profile use <name>'。use 在本次会话的剩余时间使用该 profile 连接并返回新客户端；
+// 与 profile use 子命令不同，它不修改文件中的当前 profile。
+func handleProfileCommand(ctx context.Context, args []string, comp *completer) (*mcpclient.Client, error) {
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		file, err := loadProfiles(viper.GetString("profile-file"))
+		if err != nil {
+			return nil, err
+		}
+		current := viper.GetString("profile")
+		if current == "" {
+			current = file.Current
+		}
+		printProfiles(os.Stdout, file, current)
+		return nil, nil
+	case len(args) == 2 && args[0] == "use":
+		previous := viper.GetString("profile")
+		viper.Set("profile", args[1])
+		client, err := connectClient(ctx, mcpclient.WithToolListChangedHandler(comp.toolsChanged))
+		if err != nil {
+			// Keep the settings of the session's current connection
+			// 保留本次会话当前连接的配置
+			viper.Set("profile", previous)
+			if restoreErr := applySelectedProfile(viper.GetViper()); restoreErr != nil {
+				logger.Get().Warn("Failed to restore the previous profile", "error", restoreErr)
+			}
+			return nil, fmt.Errorf("failed to switch to profile %s: %w", args[1], err)
+		}
+		comp.setClient(client)
+		refreshCompletions(ctx, client, comp)
+		fmt.Printf("Connected to: %s (profile %s)\n", viper.GetString("server"), args[1])
+		return client, nil
+	default:
+		fmt.Println("Usage: profile list | profile use <name>")
+		return nil, nil
+	}
+}
+
+// runCancellable runs fn with a context that is cancelled on Ctrl+C
+// runCancellable 使用可通过 Ctrl+C 取消的 context 执行 fn
+func runCancellable(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := fn(ctx)
+	if err != nil && ctx.Err() != nil {
+		return context.Canceled
+	}
+	return err
+}
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// tokenCommandTimeout bounds how long a profile's tokenCommand may run
+// tokenCommandTimeout 限制 profile 的 tokenCommand 的运行时间
+const tokenCommandTimeout = 30 * time.Second
+
+// Profile is a named connection to a server. TokenCommand is run through the shell
+// to print the token, so the token itself need not be stored in the file.
+// Profile 是一个命名的服务器连接。TokenCommand 通过 shell 执行并输出 token，因此 token 本身不必保存在文件中。
+type Profile struct {
+	Name               string `json:"name"`
+	Server             string `json:"server"`
+	Token              string `json:"token,omitempty"`
+	TokenCommand       string `json:"tokenCommand,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// profileFile is the layout of the profile file:
+//
+//	current: dev
+//	profiles:
+//	  - name: dev
+//	    server: https://mcp.dev.example.com:8443
+//	    token: dev-token
+//	  - name: prod
+//	    server: https://mcp.prod.example.com:8443
+//	    tokenCommand: vault kv get -field=token secret/k8s-mcp
+//
+// profileFile 是 profile 文件的格式
+type profileFile struct {
+	Current  string    `json:"current,omitempty"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// defaultProfileFile returns $XDG_CONFIG_HOME/k8s-mcp/client.yaml, else
+// ~/.config/k8s-mcp/client.yaml ("" when the home directory is unknown)
+// defaultProfileFile 返回 $XDG_CONFIG_HOME/k8s-mcp/client.yaml，否则为 ~/.config/k8s-mcp/client.yaml（无法确定主目录时返回空字符串）
+func defaultProfileFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "k8s-mcp", "client.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "k8s-mcp", "client.yaml")
+}
+
+// loadProfiles reads the profile file; a file that does not exist holds no profiles
+// loadProfiles 读取 profile 文件；文件不存在时视为没有 profile
+func loadProfiles(path string) (*profileFile, error) {
+	file := &profileFile{}
+	if path == "" {
+		return file, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("invalid profile file %s: %w", path, err)
+	}
+	seen := make(map[string]bool, len(file.Profiles))
+	for _, profile := range file.Profiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("invalid profile file %s: %w", path, err)
+		}
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("invalid profile file %s: duplicate profile %q", path, profile.Name)
+		}
+		seen[profile.Name] = true
+	}
+	return file, nil
+}
+
+// validate checks the fields a profile needs
+// validate 检查 profile 必需的字段
+func (p Profile) validate() error {
+	switch {
+	case p.Name == "":
+		return errors.New("profile without a name")
+	case p.Server == "":
+		return fmt.Errorf("profile %q has no server", p.Name)
+	case p.Token != "" && p.TokenCommand != "":
+		return fmt.Errorf("profile %q sets both token and tokenCommand", p.Name)
+	}
+	return nil
+}
+
+// save writes the profile file, readable by its owner only since it may hold tokens
+// save 写入 profile 文件；文件可能包含 token，因此只有所有者可读
+func (f *profileFile) save(path string) error {
+	if path == "" {
+		return errors.New("no profile file: the home directory is unknown, set --profile-file")
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".client-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the profile with a name
+// lookup 返回指定名称的 profile
+func (f *profileFile) lookup(name string) (*Profile, bool) {
+	for i := range f.Profiles {
+		if f.Profiles[i].Name == name {
+			return &f.Profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// names returns the sorted profile names
+// names 返回排序后的 profile 名称
+func (f *profileFile) names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for _, profile := range f.Profiles {
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applySelectedProfile loads the profile chosen by --profile (else the file's current
+// profile) into v's config layer, so explicit flags and environment variables still
+// override its values. Without a selected profile the config layer is cleared.
+// applySelectedProfile 将 --profile 选择的 profile（否则为文件中的当前 profile）加载到 v 的配置层，
+// 因此显式指定的标志和环境变量仍然覆盖其中的值。没有选择 profile 时清空配置层。
+func applySelectedProfile(v *viper.Viper) error {
+	path := v.GetString("profile-file")
+	file, err := loadProfiles(path)
+	if err != nil {
+		return err
+	}
+	name := v.GetString("profile")
+	if name == "" {
+		name = file.Current
+	}
+
+	settings := map[string]interface{}{}
+	if name != "" {
+		profile, ok := file.lookup(name)
+		if !ok {
+			return fmt.Errorf("profile %q not found in %s", name, path)
+		}
+		settings["server"] = profile.Server
+		if profile.Token != "" {
+			settings["token"] = profile.Token
+		}
+		if profile.TokenCommand != "" {
+			settings["token-command"] = profile.TokenCommand
+		}
+		if profile.InsecureSkipVerify {
+			settings["insecure-skip-verify"] = true
+		}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	v.SetConfigType("json")
+	return v.ReadConfig(bytes.NewReader(data))
+}
+
+// runTokenCommand runs command through the shell and returns what it prints on stdout,
+// trimmed. The token is never logged; stderr is only reported when the command fails.
+// runTokenCommand 通过 shell 执行 command 并返回其标准输出（去除首尾空白）。token 不会被记录；只有命令失败时才报告 stderr。
+func runTokenCommand(ctx context.Context, command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for children that keep stdout open after the command was killed
+	// 命令被终止后，不等待仍持有标准输出的子进程
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("tokenCommand timed out after %s", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("tokenCommand failed: %w: %s", err, message)
+		}
+		return "", fmt.Errorf("tokenCommand failed: %w", err)
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("tokenCommand printed no token")
+	}
+	return token, nil
+}
+
+// readToken reads a token without echoing it: at a hidden prompt on a terminal, else
+// the first line of stdin
+// readToken 在不回显的情况下读取 token：终端上使用隐藏输入的提示，否则读取标准输入的第一行
+func readToken(in io.Reader) (string, error) {
+	if f, ok := in.(*os.File); ok && readline.IsTerminal(int(f.Fd())) {
+		token, err := readline.Password("Token (leave empty for none): ")
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// printProfiles prints the profiles with their server and how they authenticate, never the token
+// printProfiles 输出 profile 及其服务器和认证方式，从不输出 token
+func printProfiles(out io.Writer, file *profileFile, current string) {
+	if len(file.Profiles) == 0 {
+		fmt.Fprintln(out, "No profiles; add one with 'k8s-mcp-client profile add'")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tSERVER\tAUTH")
+	for _, name := range file.names() {
+		profile, _ := file.lookup(name)
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		auth := "none"
+		switch {
+		case profile.Token != "":
+			auth = "token"
+		case profile.TokenCommand != "":
+			auth = "tokenCommand"
+		}
+		if profile.InsecureSkipVerify {
+			auth += ", insecure"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, profile.Server, auth)
+	}
+	w.Flush()
+}
+
+// profileTokenCommand is the tokenCommand of profile add
+// profileTokenCommand 为 profile add 的 tokenCommand
+var profileTokenCommand string
+
+// profileCmd groups the commands managing connection profiles
+// profileCmd 管理连接 profile 的命令组
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage connection profiles",
+	Long: `管理连接 profile。每个 profile 保存一个服务器的地址、token（或输出 token 的 tokenCommand）
+以及是否跳过 TLS 校验，通过 --profile 选择，未指定时使用 'profile use' 设置的当前 profile。
+显式指定的 --server、--token 等标志和环境变量优先于 profile 中的值。`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := loadProfiles(viper.GetString("profile-file"))
+		if err != nil {
+			return err
+		}
+		printProfiles(cmd.OutOrStdout(), file, file.Current)
+		return nil
+	},
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name> --server <url> [--token-command <command>] [--insecure-skip-verify]",
+	Short: "Add or replace a profile",
+	Long: `添加或替换一个 profile。未指定 --token-command 时，在终端上以不回显的方式提示输入 token，
+否则从标准输入读取第一行；token 不会出现在命令行和 shell 历史记录中。`,
+	Example: `  k8s-mcp-client profile add dev --server https://mcp.dev.example.com:8443
+  k8s-mcp-client profile add prod --server https://mcp.prod.example.com:8443 --token-command 'vault kv get -field=token secret/k8s-mcp'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("server") {
+			return errors.New("--server is required")
+		}
+		if cmd.Flags().Changed("token") {
+			return errors.New("--token would be kept in the shell history; enter the token at the prompt, pipe it on stdin or use --token-command")
+		}
+		profile := Profile{Name: args[0], Server: cfgServerURL, TokenCommand: profileTokenCommand, InsecureSkipVerify: cfgInsecureSkipVerify}
+		if profile.TokenCommand == "" {
+			token, err := readToken(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("failed to read the token: %w", err)
+			}
+			profile.Token = token
+		}
+		if err := profile.validate(); err != nil {
+			return err
+		}
+
+		path := viper.GetString("profile-file")
+		file, err := loadProfiles(path)
+		if err != nil {
+			return err
+		}
+		if existing, ok := file.lookup(profile.Name); ok {
+			*existing = profile
+		} else {
+			file.Profiles = append(file.Profiles, profile)
+		}
+		if err := file.save(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Profile %s saved to %s\n", profile.Name, path)
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the profile used when --profile is not given",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("profile-file")
+		file, err := loadProfiles(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := file.lookup(args[0]); !ok {
+			return fmt.Errorf("profile %q not found in %s", args[0], path)
+		}
+		file.Current = args[0]
+		if err := file.save(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	profileAddCmd.Flags().StringVar(&profileTokenCommand, "token-command", "", "Command printing the token on stdout, run through the shell on every connection")
+	profileCmd.AddCommand(profileListCmd, profileAddCmd, profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
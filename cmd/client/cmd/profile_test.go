@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// newProfileViper 创建与根命令相同绑定方式的 viper：连接标志、环境变量以及 profile 文件，args 为命令行参数
+func newProfileViper(t *testing.T, profileFile string, args ...string) *viper.Viper {
+	t.Helper()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("server", "https://localhost:8443", "")
+	flags.String("token", "", "")
+	flags.Bool("insecure-skip-verify", false, "")
+	flags.String("profile", "", "")
+	flags.String("profile-file", profileFile, "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	v := viper.New()
+	for _, name := range []string{"server", "token", "insecure-skip-verify", "profile", "profile-file"} {
+		v.BindPFlag(name, flags.Lookup(name))
+	}
+	v.BindEnv("server", "MCP_CLIENT_SERVER")
+	v.BindEnv("token", "MCP_CLIENT_TOKEN")
+	return v
+}
+
+// writeProfiles 将 profile 文件写入临时目录并返回其路径
+func writeProfiles(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write profiles: %v", err)
+	}
+	return path
+}
+
+// TestProfilePrecedence 测试 profile 的选择，以及显式标志和环境变量优先于 profile 中的值
+func TestProfilePrecedence(t *testing.T) {
+	path := writeProfiles(t, `current: dev
+profiles:
+  - name: dev
+    server: https://dev.example.com:8443
+    token: dev-token
+  - name: prod
+    server: https://prod.example.com:8443
+    token: prod-token
+    insecureSkipVerify: true
+`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		server   string
+		token    string
+		insecure bool
+	}{
+		{name: "current profile", server: "https://dev.example.com:8443", token: "dev-token"},
+		{name: "--profile", args: []string{"--profile", "prod"}, server: "https://prod.example.com:8443", token: "prod-token", insecure: true},
+		{name: "flags override the profile", args: []string{"--profile", "prod", "--server", "https://other:8443", "--token", "flag-token"}, server: "https://other:8443", token: "flag-token", insecure: true},
+		{name: "environment overrides the profile", env: map[string]string{"MCP_CLIENT_TOKEN": "env-token"}, server: "https://dev.example.com:8443", token: "env-token"},
+		{name: "flags override the environment", args: []string{"--token", "flag-token"}, env: map[string]string{"MCP_CLIENT_TOKEN": "env-token"}, server: "https://dev.example.com:8443", token: "flag-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			config, err := clientConfig(context.Background(), newProfileViper(t, path, tt.args...))
+			if err != nil {
+				t.Fatalf("clientConfig failed: %v", err)
+			}
+			if config.ServerURL != tt.server || config.AuthToken != tt.token || config.InsecureSkipVerify != tt.insecure {
+				t.Errorf("expected %s %s %v, got %s %s %v", tt.server, tt.token, tt.insecure, config.ServerURL, config.AuthToken, config.InsecureSkipVerify)
+			}
+		})
+	}
+
+	// 没有 profile 文件时使用标志默认值
+	config, err := clientConfig(context.Background(), newProfileViper(t, filepath.Join(t.TempDir(), "missing.yaml"), "--token", "t"))
+	if err != nil || config.ServerURL != "https://localhost:8443" {
+		t.Errorf("expected the default server, got %+v, %v", config, err)
+	}
+
+	// 切换 profile 时不会残留上一个 profile 的值
+	v := newProfileViper(t, path, "--profile", "prod")
+	if _, err := clientConfig(context.Background(), v); err != nil {
+		t.Fatalf("clientConfig failed: %v", err)
+	}
+	v.Set("profile", "dev")
+	if config, err := clientConfig(context.Background(), v); err != nil || config.InsecureSkipVerify || config.AuthToken != "dev-token" {
+		t.Errorf("expected the dev profile only, got %+v, %v", config, err)
+	}
+
+	if _, err := clientConfig(context.Background(), newProfileViper(t, path, "--profile", "staging")); err == nil || !strings.Contains(err.Error(), `profile "staging" not found`) {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+	for content, want := range map[string]string{
+		"profiles:\n  - name: a\n": `profile "a" has no server`,
+		"profiles:\n  - name: a\n    server: s\n    token: t\n    tokenCommand: c\n": "sets both token and tokenCommand",
+		"profiles:\n  - name: a\n    server: s\n  - name: a\n    server: s\n":        `duplicate profile "a"`,
+		"profiles:\n  - name: a\n    server: s\n    tokencmd: c\n":                   "unknown field",
+	} {
+		if _, err := loadProfiles(writeProfiles(t, content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q for %q, got %v", want, content, err)
+		}
+	}
+}
+
+// TestTokenCommand 测试 tokenCommand 的输出作为 token，失败和超时返回错误，显式 token 优先于 tokenCommand
+func TestTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands below need a POSIX shell")
+	}
+	ctx := context.Background()
+
+	if token, err := runTokenCommand(ctx, "printf ' secret-token\\n'", time.Second); err != nil || token != "secret-token" {
+		t.Errorf("expected secret-token, got %q, %v", token, err)
+	}
+	for command, want := range map[string]string{
+		"echo denied >&2; exit 3": "tokenCommand failed: exit status 3: denied",
+		"true":                    "tokenCommand printed no token",
+		"sleep 5":                 "tokenCommand timed out after 200ms",
+	} {
+		if _, err := runTokenCommand(ctx, command, 200*time.Millisecond); err == nil || err.Error() != want {
+			t.Errorf("%s: expected %q, got %v", command, want, err)
+		}
+	}
+
+	path := writeProfiles(t, `profiles:
+  - name: vault
+    server: https://prod.example.com:8443
+    tokenCommand: echo from-command
+`)
+	config, err := clientConfig(ctx, newProfileViper(t, path, "--profile", "vault"))
+	if err != nil || config.AuthToken != "from-command" {
+		t.Errorf("expected the token of the command, got %q, %v", config.AuthToken, err)
+	}
+	config, err = clientConfig(ctx, newProfileViper(t, path, "--profile", "vault", "--token", "explicit"))
+	if err != nil || config.AuthToken != "explicit" {
+		t.Errorf("expected the explicit token, got %q, %v", config.AuthToken, err)
+	}
+}
+
+// TestProfileSave 测试保存的文件只有所有者可读，且可以重新加载
+func TestProfileSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k8s-mcp", "client.yaml")
+	file := &profileFile{Current: "dev", Profiles: []Profile{{Name: "dev", Server: "https://dev:8443", Token: "secret"}}}
+	if err := file.save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	loaded, err := loadProfiles(path)
+	if err != nil || loaded.Current != "dev" || len(loaded.Profiles) != 1 || loaded.Profiles[0] != file.Profiles[0] {
+		t.Errorf("unexpected profiles %+v, %v", loaded, err)
+	}
+
+	var out strings.Builder
+	printProfiles(&out, loaded, "dev")
+	if strings.Contains(out.String(), "secret") || !strings.Contains(out.String(), "*        dev") {
+		t.Errorf("unexpected listing:\n%s", out.String())
+	}
+}
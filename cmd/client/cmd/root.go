@@ -1,15 +1,19 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
 	"github.com/AceDarkknight/k8s-mcp/pkg/mcpclient"
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
 
+	"github.com/chzyer/readline"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,6 +25,13 @@ var (
 	cfgServerURL          string
 	cfgAuthToken          string
 	cfgInsecureSkipVerify bool
+	cfgClientCert         string
+	cfgClientKey          string
+	cfgCACert             string
+	cfgCompressRequests   bool
+	cfgTransport          string
+	cfgProfile            string
+	cfgProfileFile        string
 
 	// 日志配置
 	logConfig = logger.NewDefaultConfig()
@@ -33,6 +44,7 @@ var rootCmd = &cobra.Command{
 	Short: "Kubernetes MCP Client",
 	Long: `k8s-mcp-client 是一个用于连接到 k8s-mcp 服务器的测试客户端。
 它支持通过 HTTP/SSE 连接，并带有 Token 认证。`,
+	SilenceUsage: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// 初始化日志系统
 		// 从 viper 获取 log-to-file 标志的值
@@ -57,17 +69,37 @@ func Execute() error {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	// Define flags directly on command
-	// 直接在命令上定义标志
-	rootCmd.Flags().StringVarP(&cfgServerURL, "server", "s", "https://localhost:8443", "MCP server URL")
-	rootCmd.Flags().StringVarP(&cfgAuthToken, "token", "t", "", "Authentication token (required)")
-	rootCmd.Flags().BoolVarP(&cfgInsecureSkipVerify, "insecure-skip-verify", "i", false, "Skip TLS certificate verification")
+	// Define connection flags on all commands
+	// 在所有命令上定义连接标志
+	rootCmd.PersistentFlags().StringVarP(&cfgServerURL, "server", "s", "https://localhost:8443", "MCP server URL")
+	rootCmd.PersistentFlags().StringVarP(&cfgAuthToken, "token", "t", "", "Authentication token (required unless --client-cert is set)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgInsecureSkipVerify, "insecure-skip-verify", "i", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&cfgClientCert, "client-cert", "", "", "Path to the client certificate (PEM) for mTLS authentication")
+	rootCmd.PersistentFlags().StringVarP(&cfgClientKey, "client-key", "", "", "Path to the client certificate key (PEM) for mTLS authentication")
+	rootCmd.PersistentFlags().StringVarP(&cfgCACert, "ca-cert", "", "", "Path to the CA (PEM) that signed the server certificate (optional, defaults to the system roots)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgCompressRequests, "compress-requests", "", false, "Gzip request bodies larger than 1KB, e.g. calls carrying large manifests")
+	rootCmd.PersistentFlags().StringVarP(&cfgTransport, "transport", "", mcpclient.TransportStreamable, "Transport: streamable (HTTP), or websocket for proxies that don't pass streamable HTTP through")
+	rootCmd.PersistentFlags().StringVarP(&cfgProfile, "profile", "p", "", "Connection profile to use (defaults to the one set with 'profile use'); explicit flags override its values")
+	rootCmd.PersistentFlags().StringVarP(&cfgProfileFile, "profile-file", "", defaultProfileFile(), "Path to the connection profile file")
 
 	// Bind flags to viper
 	// 将标志绑定到 viper
-	viper.BindPFlag("server", rootCmd.Flags().Lookup("server"))
-	viper.BindPFlag("token", rootCmd.Flags().Lookup("token"))
-	viper.BindPFlag("insecure-skip-verify", rootCmd.Flags().Lookup("insecure-skip-verify"))
+	viper.BindPFlag("server", rootCmd.PersistentFlags().Lookup("server"))
+	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
+	viper.BindPFlag("insecure-skip-verify", rootCmd.PersistentFlags().Lookup("insecure-skip-verify"))
+	viper.BindPFlag("client-cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	viper.BindPFlag("client-key", rootCmd.PersistentFlags().Lookup("client-key"))
+	viper.BindPFlag("ca-cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindPFlag("compress-requests", rootCmd.PersistentFlags().Lookup("compress-requests"))
+	viper.BindPFlag("transport", rootCmd.PersistentFlags().Lookup("transport"))
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	viper.BindPFlag("profile-file", rootCmd.PersistentFlags().Lookup("profile-file"))
+
+	// Script mode flags only apply to the root command
+	// 脚本模式标志只作用于根命令
+	rootCmd.Flags().StringVar(&scriptPath, "script", "", "Run the commands of a script file (\"-\" for stdin) instead of the interactive shell; used automatically when stdin is not a terminal")
+	rootCmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "In script mode, keep running after a failed command (the exit code is still 1)")
+	rootCmd.Flags().StringVar(&outputFormat, "output", outputText, "Script mode output: text, or json for one {command, ok, result} object per command")
 
 	// Bind logger flags
 	// 绑定日志标志（包括 log-to-file）
@@ -82,76 +114,115 @@ func initConfig() {
 	viper.BindEnv("server", "MCP_CLIENT_SERVER")
 	viper.BindEnv("token", "MCP_CLIENT_TOKEN")
 	viper.BindEnv("insecure-skip-verify", "MCP_CLIENT_INSECURE_SKIP_VERIFY")
+	viper.BindEnv("client-cert", "MCP_CLIENT_CERT")
+	viper.BindEnv("client-key", "MCP_CLIENT_KEY")
+	viper.BindEnv("ca-cert", "MCP_CLIENT_CA")
+	viper.BindEnv("compress-requests", "MCP_CLIENT_COMPRESS_REQUESTS")
+	viper.BindEnv("transport", "MCP_CLIENT_TRANSPORT")
+	viper.BindEnv("profile", "MCP_CLIENT_PROFILE")
+	viper.BindEnv("profile-file", "MCP_CLIENT_PROFILE_FILE")
 }
 
-// executeClient starts the MCP client
-// executeClient 启动 MCP 客户端
-func executeClient() {
-	// 获取 logger 实例
-	log := logger.Get()
-
-	// Read configuration from viper (flags override env vars)
-	// 从 viper 读取配置（标志覆盖环境变量）
-	serverURL := viper.GetString("server")
-	authToken := viper.GetString("token")
-	insecureSkipVerify := viper.GetBool("insecure-skip-verify")
-
-	// Validate required parameters
-	// 验证必需参数
-	if authToken == "" {
-		log.Error("--token is required")
-		os.Exit(1)
-	}
-
-	// Create client configuration
-	// 创建客户端配置
-	config := mcpclient.Config{
-		ServerURL:          serverURL,
-		AuthToken:          authToken,
-		InsecureSkipVerify: insecureSkipVerify,
+// connectClient creates a client from the configuration and connects it to the server
+// connectClient 根据配置创建客户端并连接到服务器
+func connectClient(ctx context.Context, opts ...mcpclient.Option) (*mcpclient.Client, error) {
+	config, err := clientConfig(ctx, viper.GetViper())
+	if err != nil {
+		return nil, err
 	}
 
 	// Create client instance
 	// 创建客户端实例
-	client, err := mcpclient.NewClient(config, mcpclient.WithUserAgent("k8s-mcp-client/1.0.0"))
+	opts = append([]mcpclient.Option{mcpclient.WithUserAgent(version.UserAgent("k8s-mcp-client"))}, opts...)
+	client, err := mcpclient.NewClient(config, opts...)
 	if err != nil {
-		log.Error("Failed to create client", "error", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
-	defer client.Close()
 
 	// Connect to server
 	// 连接到服务器
-	ctx := context.Background()
 	if err := client.Connect(ctx); err != nil {
-		log.Error("Connection failed", "error", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("connection failed: %w", err)
 	}
+	return client, nil
+}
 
-	fmt.Printf("Connected to: %s\n", serverURL)
-	fmt.Println("Type 'help' for available commands, 'quit' to exit")
-
-	// Interactive loop
-	// 交互式循环
-	scanner := bufio.NewScanner(os.Stdin)
-	for {
-		fmt.Print("> ")
-		if !scanner.Scan() {
-			break
+// clientConfig builds the client configuration from v: explicit flags override
+// environment variables, which override the selected profile and then the defaults
+// clientConfig 根据 v 构建客户端配置：显式指定的标志优先于环境变量，其次是所选的 profile，最后是默认值
+func clientConfig(ctx context.Context, v *viper.Viper) (mcpclient.Config, error) {
+	if err := applySelectedProfile(v); err != nil {
+		return mcpclient.Config{}, err
+	}
+	authToken := v.GetString("token")
+	clientCert := v.GetString("client-cert")
+
+	// A token given by flag or environment wins over the profile's tokenCommand
+	// 通过标志或环境变量指定的 token 优先于 profile 的 tokenCommand
+	if command := v.GetString("token-command"); authToken == "" && command != "" {
+		token, err := runTokenCommand(ctx, command, tokenCommandTimeout)
+		if err != nil {
+			return mcpclient.Config{}, err
 		}
+		authToken = token
+	}
 
-		input := strings.TrimSpace(scanner.Text())
-		if input == "" {
-			continue
-		}
+	// Validate required parameters
+	// 验证必需参数
+	if authToken == "" && clientCert == "" {
+		return mcpclient.Config{}, fmt.Errorf("--token is required unless --client-cert is set")
+	}
 
-		if input == "quit" || input == "exit" {
-			break
-		}
+	return mcpclient.Config{
+		ServerURL:          v.GetString("server"),
+		AuthToken:          authToken,
+		InsecureSkipVerify: v.GetBool("insecure-skip-verify"),
+		ClientCertPath:     clientCert,
+		ClientKeyPath:      v.GetString("client-key"),
+		CAPath:             v.GetString("ca-cert"),
+		CompressRequests:   v.GetBool("compress-requests"),
+		Transport:          v.GetString("transport"),
+	}, nil
+}
 
-		if err := handleCommand(ctx, client, input); err != nil {
-			log.Error("Command execution failed", "error", err)
+// executeClient starts the interactive MCP client
+// executeClient 启动交互式 MCP 客户端
+func executeClient() {
+	// 获取 logger 实例
+	log := logger.Get()
+
+	ctx := context.Background()
+
+	// Run a script when one is given or commands are piped in
+	// 指定了脚本或通过管道输入命令时执行脚本
+	if scriptPath == "" && !readline.IsTerminal(int(os.Stdin.Fd())) {
+		scriptPath = "-"
+	}
+	if scriptPath != "" {
+		if err := executeScript(ctx, scriptPath); err != nil {
+			log.Error("Script failed", "error", err)
+			os.Exit(1)
 		}
+		return
+	}
+
+	// Refresh the tool completions when the server's tool list changes
+	// 服务器工具列表变化时刷新工具补全
+	comp := &completer{}
+	client, err := connectClient(ctx, mcpclient.WithToolListChangedHandler(comp.toolsChanged))
+	if err != nil {
+		log.Error("Failed to connect", "error", err)
+		os.Exit(1)
+	}
+	comp.setClient(client)
+
+	serverURL := viper.GetString("server")
+	fmt.Printf("Connected to: %s\n", serverURL)
+	fmt.Println("Type 'help' for available commands, 'quit' to exit, Tab to complete")
+
+	if err := runInteractive(ctx, client, comp); err != nil {
+		log.Error("Interactive session failed", "error", err)
+		os.Exit(1)
 	}
 }
 
@@ -176,10 +247,26 @@ func handleCommand(ctx context.Context, client *mcpclient.Client, input string)
 		return listTools(ctx, client)
 	case "call":
 		if len(parts) < 2 {
-			fmt.Println("Usage: call <tool_name> [args...]")
+			fmt.Println("Usage: call <tool_name> [key=value...]")
 			return nil
 		}
-		return callTool(ctx, client, parts[1], parts[2:])
+		return callTool(ctx, client, parts[1], parts[2:], false)
+	case "resources":
+		return listResources(ctx, client)
+	case "read":
+		if len(parts) != 2 {
+			fmt.Println("Usage: read <uri>")
+			return nil
+		}
+		return readResource(ctx, client, parts[1])
+	case "prompts":
+		return listPrompts(ctx, client)
+	case "prompt":
+		if len(parts) < 2 {
+			fmt.Println("Usage: prompt <name> [key=value...]")
+			return nil
+		}
+		return getPrompt(ctx, client, parts[1], parts[2:])
 	default:
 		log.Error("Unknown command", "command", command)
 		return nil
@@ -188,16 +275,23 @@ func handleCommand(ctx context.Context, client *mcpclient.Client, input string)
 
 func showHelp() {
 	fmt.Println("Available commands:")
-	fmt.Println("  help                     - Show this help")
-	fmt.Println("  tools                    - List available tools")
-	fmt.Println("  call <tool> [args...]    - Call a tool")
-	fmt.Println("  quit                     - Exit the client")
+	fmt.Println("  help                          - Show this help")
+	fmt.Println("  tools                         - List available tools")
+	fmt.Println("  call <tool> [key=value...]    - Call a tool")
+	fmt.Println("  resources                     - List resources and resource templates")
+	fmt.Println("  read <uri>                    - Read a resource")
+	fmt.Println("  prompts                       - List available prompts")
+	fmt.Println("  prompt <name> [key=value...]  - Get a prompt")
+	fmt.Println("  profile list                  - List the connection profiles")
+	fmt.Println("  profile use <name>            - Reconnect with another profile for this session")
+	fmt.Println("  quit                          - Exit the client")
 	fmt.Println()
-	fmt.Println("Example tool calls:")
+	fmt.Println("Example commands:")
 	fmt.Println("  call get_cluster_status")
 	fmt.Println("  call list_pods namespace=default")
-	fmt.Println("  call get_events namespace=default")
-	fmt.Println("  call get_pod_logs pod_name=my-pod namespace=default")
+	fmt.Println("  call get_pod_logs pod_name=my-pod namespace=default tail_lines=50")
+	fmt.Println("  read k8s://clusters")
+	fmt.Println("  prompt generate_kubectl_commands intent=scale-web namespace=default")
 }
 
 func listTools(ctx context.Context, client *mcpclient.Client) error {
@@ -214,18 +308,16 @@ func listTools(ctx context.Context, client *mcpclient.Client) error {
 	return nil
 }
 
-func callTool(ctx context.Context, client *mcpclient.Client, toolName string, args []string) error {
-	// 获取 logger 实例
-	log := logger.Get()
+// errToolFailed is returned when the tool result is flagged isError
+// errToolFailed 表示工具结果被标记为 isError
+var errToolFailed = errors.New("tool returned an error")
 
-	// Parse simple arguments (key=value format)
-	// 解析简单参数（key=value 格式）
-	arguments := make(map[string]interface{})
-	for _, arg := range args {
-		if strings.Contains(arg, "=") {
-			parts := strings.SplitN(arg, "=", 2)
-			arguments[parts[0]] = parts[1]
-		}
+// callTool calls a tool and prints its text content, or the raw result as JSON when rawJSON is set
+// callTool 调用工具并输出文本内容，rawJSON 为 true 时输出原始 JSON 结果
+func callTool(ctx context.Context, client *mcpclient.Client, toolName string, args []string, rawJSON bool) error {
+	arguments, err := parseArguments(args)
+	if err != nil {
+		return err
 	}
 
 	// Call tool
@@ -237,15 +329,176 @@ func callTool(ctx context.Context, client *mcpclient.Client, toolName string, ar
 
 	// Display result
 	// 显示结果
+	if rawJSON {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		out := io.Writer(os.Stdout)
+		if result.IsError {
+			out = os.Stderr
+		}
+		for _, content := range result.Content {
+			if textContent, ok := content.(*mcp.TextContent); ok {
+				fmt.Fprintln(out, textContent.Text)
+			}
+		}
+	}
+
 	if result.IsError {
-		log.Error("Tool execution error", "tool", toolName)
+		return fmt.Errorf("%s: %w", toolName, errToolFailed)
+	}
+	return nil
+}
+
+// listResources prints the resources and resource templates
+// listResources 输出资源和资源模板列表
+func listResources(ctx context.Context, client *mcpclient.Client) error {
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		return err
+	}
+	templates, err := client.ListResourceTemplates(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Available resources:")
+	for _, resource := range resources {
+		fmt.Printf("  %s - %s\n", resource.URI, resource.Description)
+	}
+	if len(templates) > 0 {
+		fmt.Println("Resource templates:")
+		for _, template := range templates {
+			fmt.Printf("  %s - %s\n", template.URITemplate, template.Description)
+		}
+	}
+
+	return nil
+}
+
+// readResource prints the contents of a resource
+// readResource 输出资源内容
+func readResource(ctx context.Context, client *mcpclient.Client, uri string) error {
+	contents, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return err
 	}
 
-	for _, content := range result.Content {
-		if textContent, ok := content.(*mcp.TextContent); ok {
-			fmt.Println(textContent.Text)
+	for _, content := range contents {
+		fmt.Printf("URI: %s\n", content.URI)
+		if content.MIMEType != "" {
+			fmt.Printf("MIME type: %s\n", content.MIMEType)
+		}
+		if content.Blob != nil {
+			fmt.Printf("[binary data, %d bytes]\n", len(content.Blob))
+		} else {
+			fmt.Println(prettyText(content.Text))
 		}
 	}
 
 	return nil
 }
+
+// listPrompts prints the prompts and their arguments
+// listPrompts 输出提示及其参数
+func listPrompts(ctx context.Context, client *mcpclient.Client) error {
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Available prompts:")
+	for _, prompt := range prompts {
+		fmt.Printf("  %s - %s\n", prompt.Name, prompt.Description)
+		for _, arg := range prompt.Arguments {
+			required := ""
+			if arg.Required {
+				required = " (required)"
+			}
+			fmt.Printf("      %s%s: %s\n", arg.Name, required, arg.Description)
+		}
+	}
+
+	return nil
+}
+
+// getPrompt fetches a prompt with key=value arguments and prints its messages
+// getPrompt 使用 key=value 参数获取提示并输出其消息
+func getPrompt(ctx context.Context, client *mcpclient.Client, name string, args []string) error {
+	arguments, err := parsePromptArguments(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.GetPrompt(ctx, name, arguments)
+	if err != nil {
+		return err
+	}
+
+	if result.Description != "" {
+		fmt.Println(result.Description)
+	}
+	for _, message := range result.Messages {
+		switch content := message.Content.(type) {
+		case *mcp.TextContent:
+			fmt.Printf("[%s]\n%s\n", message.Role, content.Text)
+		case *mcp.EmbeddedResource:
+			fmt.Printf("[%s] resource %s\n", message.Role, content.Resource.URI)
+		default:
+			fmt.Printf("[%s] <non-text content>\n", message.Role)
+		}
+	}
+
+	return nil
+}
+
+// parsePromptArguments parses key=value prompt arguments; prompt arguments are always strings
+// parsePromptArguments 解析 key=value 格式的提示参数，提示参数始终为字符串
+func parsePromptArguments(args []string) (map[string]string, error) {
+	arguments := make(map[string]string)
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid argument %q, expected key=value", arg)
+		}
+		arguments[key] = value
+	}
+	return arguments, nil
+}
+
+// parseArguments parses key=value arguments. Values that are valid JSON
+// (numbers, booleans, arrays, objects) are decoded, everything else is kept as a string.
+// parseArguments 解析 key=value 参数，合法的 JSON 值（数字、布尔、数组、对象）会被解码，其他保留为字符串
+func parseArguments(args []string) (map[string]interface{}, error) {
+	arguments := make(map[string]interface{})
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected key=value", arg)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			arguments[key] = decoded
+		} else {
+			arguments[key] = value
+		}
+	}
+	return arguments, nil
+}
+
+// prettyText indents JSON text and returns other text unchanged
+// prettyText 缩进 JSON 文本，其他文本原样返回
+func prettyText(text string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return text
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(data)
+}
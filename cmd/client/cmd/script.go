@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+	"github.com/AceDarkknight/k8s-mcp/pkg/mcpclient"
+)
+
+// Output formats of script mode
+// 脚本模式的输出格式
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	// Script mode flags
+	// 脚本模式标志
+	scriptPath      string
+	continueOnError bool
+	outputFormat    string
+)
+
+// scriptCommand is one command of a script together with the timeout in effect for it
+// scriptCommand 是脚本中的一条命令及其生效的超时时间
+type scriptCommand struct {
+	line    int
+	text    string
+	fields  []string
+	timeout time.Duration
+}
+
+// scriptResult is the JSON envelope printed for each command with --output json
+// scriptResult 是 --output json 时为每条命令输出的 JSON 信封
+type scriptResult struct {
+	Command string      `json:"command"`
+	Line    int         `json:"line"`
+	OK      bool        `json:"ok"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// scriptOptions controls how a script runs
+// scriptOptions 控制脚本的执行方式
+type scriptOptions struct {
+	continueOnError bool
+	output          string
+}
+
+// parseScript reads commands line by line in the syntax of the interactive shell.
+// Blank lines and lines starting with # are skipped, "timeout <duration>" sets the
+// timeout of every following command (0 disables it), and quit or exit ends the script.
+// Every line is checked before anything runs so a typo fails the script up front.
+// parseScript 按交互式命令行的语法逐行读取命令。跳过空行和以 # 开头的行，"timeout <duration>" 设置之后每条命令的超时时间 (0 表示不限制)，
+// quit 或 exit 结束脚本。所有行在执行之前都会被检查，拼写错误会让脚本在开始前失败
+func parseScript(r io.Reader) ([]scriptCommand, error) {
+	var commands []scriptCommand
+	var timeout time.Duration
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+
+		switch fields[0] {
+		case "quit", "exit":
+			return commands, nil
+		case "timeout":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: usage: timeout <duration>", line)
+			}
+			d, err := time.ParseDuration(fields[1])
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("line %d: invalid timeout %q", line, fields[1])
+			}
+			timeout = d
+			continue
+		}
+
+		if err := checkScriptCommand(fields); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		commands = append(commands, scriptCommand{line: line, text: text, fields: fields, timeout: timeout})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return commands, nil
+}
+
+// checkScriptCommand validates the command name, its number of arguments and key=value arguments
+// checkScriptCommand 检查命令名称、参数个数以及 key=value 参数
+func checkScriptCommand(fields []string) error {
+	switch fields[0] {
+	case "help", "tools", "resources", "prompts":
+		if len(fields) != 1 {
+			return fmt.Errorf("%s takes no arguments", fields[0])
+		}
+	case "read":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: read <uri>")
+		}
+	case "call", "prompt":
+		if len(fields) < 2 {
+			return fmt.Errorf("usage: %s <name> [key=value...]", fields[0])
+		}
+		if _, err := parseArguments(fields[2:]); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown command %q", fields[0])
+	}
+	return nil
+}
+
+// runScript executes the commands in order. In text mode each command is echoed and
+// its output printed as in the interactive shell; in json mode one envelope per line
+// is written to w. A failed command (transport error or a result flagged isError)
+// stops the script unless continueOnError is set; either way an error is returned.
+// runScript 依次执行命令。text 模式下回显每条命令并像交互式命令行一样输出结果；json 模式下每条命令向 w 写入一行信封。
+// 命令失败 (传输错误或结果被标记为 isError) 时停止执行脚本，除非设置了 continueOnError；两种情况都会返回错误
+func runScript(ctx context.Context, client *mcpclient.Client, commands []scriptCommand, opts scriptOptions, w io.Writer) error {
+	failed := 0
+	for _, command := range commands {
+		if err := runScriptCommand(ctx, client, command, opts.output, w); err != nil {
+			failed++
+			if !opts.continueOnError {
+				return fmt.Errorf("script stopped at line %d (%s): %w", command.line, command.text, err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d script commands failed", failed, len(commands))
+	}
+	return nil
+}
+
+// runScriptCommand runs one command with its timeout and prints its output
+// runScriptCommand 在超时时间内执行一条命令并输出结果
+func runScriptCommand(ctx context.Context, client *mcpclient.Client, command scriptCommand, output string, w io.Writer) error {
+	if command.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, command.timeout)
+		defer cancel()
+	}
+
+	if output != outputJSON {
+		fmt.Fprintf(w, "> %s\n", command.text)
+		err := handleCommand(ctx, client, command.text)
+		if err != nil {
+			logger.Get().Error("Command execution failed", "line", command.line, "error", err)
+		}
+		return err
+	}
+
+	result, err := scriptCommandResult(ctx, client, command.fields)
+	envelope := scriptResult{Command: command.text, Line: command.line, OK: err == nil, Result: result}
+	if err != nil {
+		envelope.Error = err.Error()
+	}
+	if encodeErr := json.NewEncoder(w).Encode(envelope); encodeErr != nil {
+		return fmt.Errorf("failed to write result: %w", encodeErr)
+	}
+	return err
+}
+
+// scriptCommandResult executes a command and returns its raw result for the JSON envelope.
+// A tool result flagged isError is returned together with errToolFailed.
+// scriptCommandResult 执行命令并返回用于 JSON 信封的原始结果。被标记为 isError 的工具结果与 errToolFailed 一起返回
+func scriptCommandResult(ctx context.Context, client *mcpclient.Client, fields []string) (interface{}, error) {
+	switch fields[0] {
+	case "tools":
+		return client.ListTools(ctx)
+	case "call":
+		arguments, err := parseArguments(fields[2:])
+		if err != nil {
+			return nil, err
+		}
+		result, err := client.CallTool(ctx, fields[1], arguments)
+		if err != nil {
+			return nil, fmt.Errorf("tool call failed: %w", err)
+		}
+		if result.IsError {
+			return result, fmt.Errorf("%s: %w", fields[1], errToolFailed)
+		}
+		return result, nil
+	case "resources":
+		resources, err := client.ListResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		templates, err := client.ListResourceTemplates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"resources": resources, "resource_templates": templates}, nil
+	case "read":
+		return client.ReadResource(ctx, fields[1])
+	case "prompts":
+		return client.ListPrompts(ctx)
+	case "prompt":
+		arguments, err := parsePromptArguments(fields[2:])
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPrompt(ctx, fields[1], arguments)
+	default:
+		return nil, nil
+	}
+}
+
+// executeScript runs a script read from path ("-" for stdin)
+// executeScript 执行从 path 读取的脚本 ("-" 表示标准输入)
+func executeScript(ctx context.Context, path string) error {
+	if outputFormat != outputText && outputFormat != outputJSON {
+		return fmt.Errorf("invalid --output %q, expected %s or %s", outputFormat, outputText, outputJSON)
+	}
+
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open script: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	commands, err := parseScript(input)
+	if err != nil {
+		return err
+	}
+
+	client, err := connectClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return runScript(ctx, client, commands, scriptOptions{continueOnError: continueOnError, output: outputFormat}, os.Stdout)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/mcp"
+	"github.com/AceDarkknight/k8s-mcp/pkg/mcpclient"
+)
+
+// TestParseScript 测试注释、空行、timeout 指令、quit 以及执行前的语法检查
+func TestParseScript(t *testing.T) {
+	script := `# smoke test
+tools
+
+timeout 5s
+call list_namespaces
+  # indented comment
+timeout 0
+read k8s://clusters
+quit
+call never_runs
+`
+	commands, err := parseScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatalf("parseScript failed: %v", err)
+	}
+	want := []struct {
+		line    int
+		text    string
+		timeout time.Duration
+	}{{2, "tools", 0}, {5, "call list_namespaces", 5 * time.Second}, {8, "read k8s://clusters", 0}}
+	if len(commands) != len(want) {
+		t.Fatalf("expected %d commands, got %+v", len(want), commands)
+	}
+	for i, w := range want {
+		if commands[i].line != w.line || commands[i].text != w.text || commands[i].timeout != w.timeout {
+			t.Errorf("command %d: expected %+v, got %+v", i, w, commands[i])
+		}
+	}
+
+	for script, want := range map[string]string{
+		"tools\nlist pods":         "line 2: unknown command \"list\"",
+		"timeout soon":             "line 1: invalid timeout \"soon\"",
+		"call":                     "line 1: usage: call <name> [key=value...]",
+		"call list_pods namespace": "line 1: invalid argument \"namespace\", expected key=value",
+		"read":                     "line 1: usage: read <uri>",
+		"# comment\n\ntools extra": "line 3: tools takes no arguments",
+	} {
+		if _, err := parseScript(strings.NewReader(script)); err == nil || err.Error() != want {
+			t.Errorf("parseScript(%q): expected %q, got %v", script, want, err)
+		}
+	}
+}
+
+// TestRunScriptJSON 在模拟集群服务器上执行脚本：失败时默认停止，--continue-on-error 时继续执行，两种情况都返回错误
+func TestRunScriptJSON(t *testing.T) {
+	s := mcp.NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	s.RegisterResources()
+	s.RegisterPrompts()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	client, err := mcpclient.NewClient(mcpclient.Config{ServerURL: httpServer.URL, AuthToken: "test-token", UserAgent: "test"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	commands, err := parseScript(strings.NewReader(`timeout 10s
+call list_namespaces
+call get_resource resource_type=pods name=missing namespace=shop
+read k8s://clusters
+prompt generate_kubectl_commands intent=scale-web
+`))
+	if err != nil {
+		t.Fatalf("parseScript failed: %v", err)
+	}
+
+	run := func(continueOnError bool) ([]scriptResult, error) {
+		var out bytes.Buffer
+		err := runScript(ctx, client, commands, scriptOptions{continueOnError: continueOnError, output: outputJSON}, &out)
+		var results []scriptResult
+		decoder := json.NewDecoder(&out)
+		for decoder.More() {
+			var result scriptResult
+			if err := decoder.Decode(&result); err != nil {
+				t.Fatalf("invalid JSON output: %v", err)
+			}
+			results = append(results, result)
+		}
+		return results, err
+	}
+
+	// 默认在失败的命令处停止
+	results, err := run(false)
+	if err == nil || !strings.Contains(err.Error(), "script stopped at line 3") {
+		t.Errorf("expected the script to stop at line 3, got %v", err)
+	}
+	if len(results) != 2 || !results[0].OK || results[0].Result == nil || results[1].OK || results[1].Command != "call get_resource resource_type=pods name=missing namespace=shop" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].Result == nil || !strings.Contains(results[1].Error, "tool returned an error") {
+		t.Errorf("expected the isError result and error, got %+v", results[1])
+	}
+
+	// --continue-on-error 执行所有命令，但仍然返回错误
+	results, err = run(true)
+	if err == nil || err.Error() != "1 of 4 script commands failed" {
+		t.Errorf("expected one failed command, got %v", err)
+	}
+	if len(results) != 4 || !results[2].OK || !results[3].OK || results[3].Line != 5 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
+
+	"github.com/spf13/cobra"
+)
+
+// versionCmd prints the build information
+// versionCmd 输出构建信息
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, git commit and build date",
+	// Skip the logger initialization of the root command
+	// 跳过根命令的日志初始化
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintln(cmd.OutOrStdout(), version.String("k8s-mcp-client"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"os"
+
 	"github.com/AceDarkknight/k8s-mcp/cmd/client/cmd"
 )
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
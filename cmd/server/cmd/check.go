@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/internal/mcp"
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
+)
+
+// Outcomes of a preflight check; only checkFail makes the check command fail
+// 预检结果；只有 checkFail 会使 check 命令失败
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// certExpiryWarning is how long before its expiry the TLS certificate is reported as a warning
+// certExpiryWarning 为 TLS 证书到期前多久开始报告警告
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// clusterCheckConcurrency bounds the clusters probed at the same time
+// clusterCheckConcurrency 限制同时探测的集群数
+const clusterCheckConcurrency = 4
+
+var (
+	checkCluster string
+	checkJSON    bool
+	checkTimeout time.Duration
+)
+
+// checkResult is the outcome of one check
+// checkResult 是一项检查的结果
+type checkResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// checkReport is the output of the check command; OK is false when any check failed
+// checkReport 是 check 命令的输出；任一检查失败时 OK 为 false
+type checkReport struct {
+	OK     bool          `json:"ok"`
+	Checks []checkResult `json:"checks"`
+}
+
+// checkStep is one named preflight step. Steps don't depend on each other, so every step
+// runs even when an earlier one failed; a step covering several items, like the clusters
+// of the kubeconfig, returns one result per item.
+// checkStep 是一个具名的预检步骤。步骤之间互不依赖，前面的步骤失败时后面的步骤仍会执行；
+// 涵盖多个对象的步骤 (例如 kubeconfig 中的集群) 为每个对象返回一个结果。
+type checkStep struct {
+	name string
+	run  func(ctx context.Context) []checkResult
+}
+
+// preflightSteps returns the checks of the effective configuration, in report order
+// preflightSteps 按报告顺序返回对生效配置的检查
+func preflightSteps(cmd *cobra.Command, log logger.Logger) []checkStep {
+	single := func(name string, check func() checkResult) checkStep {
+		return checkStep{name: name, run: func(context.Context) []checkResult { return []checkResult{check()} }}
+	}
+	return []checkStep{
+		single("settings", func() checkResult {
+			if err := validateSettings(); err != nil {
+				return checkResult{Status: checkFail, Detail: err.Error()}
+			}
+			return checkResult{Status: checkPass}
+		}),
+		{name: "kubeconfig", run: func(ctx context.Context) []checkResult {
+			if viper.GetBool("mock") {
+				return []checkResult{{Name: "kubeconfig", Status: checkSkip, Detail: "mock mode, no kubeconfig is loaded"}}
+			}
+			opts := &k8s.Options{
+				Logger:      log,
+				Impersonate: rest.ImpersonationConfig{UserName: viper.GetString("impersonate-user"), Groups: viper.GetStringSlice("impersonate-group")},
+			}
+			return checkKubeconfig(ctx, opts, viper.GetString("kubeconfig"), viper.GetString("kubeconfig-dir"), checkCluster, checkTimeout)
+		}},
+		single("tls-certificate", func() checkResult {
+			if viper.GetBool("insecure") {
+				return checkResult{Name: "tls-certificate", Status: checkSkip, Detail: "HTTP mode (--insecure)"}
+			}
+			return checkTLSCertificate(viper.GetString("cert"), viper.GetString("key"), time.Now())
+		}),
+		single("client-ca", func() checkResult {
+			return checkFile("client-ca", viper.GetString("client-ca"), func(path string) error {
+				_, err := mcp.NewClientCertTLSConfig(path)
+				return err
+			})
+		}),
+		single("token-identities", func() checkResult {
+			return checkFile("token-identities", viper.GetString("token-identities"), func(path string) error {
+				_, err := mcp.LoadTokenIdentities(path)
+				return err
+			})
+		}),
+		single("k8s-client-config", func() checkResult {
+			return checkFile("k8s-client-config", viper.GetString("k8s-client-config"), func(path string) error {
+				_, err := k8s.LoadClusterClientSettings(path)
+				return err
+			})
+		}),
+		single("log-file", func() checkResult {
+			if !logToFile(cmd) {
+				return checkResult{Name: "log-file", Status: checkSkip, Detail: "logging to file is disabled"}
+			}
+			return checkWritable("log-file", viper.GetString("log-file"))
+		}),
+		single("audit-log", func() checkResult {
+			return checkWritable("audit-log", viper.GetString("audit-log"))
+		}),
+	}
+}
+
+// runPreflight runs every step and collects the results, named after their step unless they have their own name
+// runPreflight 执行所有步骤并汇总结果，结果没有自己的名称时使用步骤名称
+func runPreflight(ctx context.Context, steps []checkStep) checkReport {
+	report := checkReport{OK: true, Checks: []checkResult{}}
+	for _, step := range steps {
+		for _, result := range step.run(ctx) {
+			if result.Name == "" {
+				result.Name = step.name
+			}
+			if result.Status == checkFail {
+				report.OK = false
+			}
+			report.Checks = append(report.Checks, result)
+		}
+	}
+	return report
+}
+
+// checkKubeconfig loads the kubeconfig the way the server does and reports the load and,
+// per cluster, whether its context could be parsed and the API server answers a version
+// request within timeout. With cluster set only that cluster is probed.
+// checkKubeconfig 按服务器的方式加载 kubeconfig 并报告加载结果，以及每个集群的上下文能否解析、API server 能否在 timeout 内
+// 响应版本请求。指定 cluster 时只探测该集群。
+func checkKubeconfig(ctx context.Context, opts *k8s.Options, configPath, configDir, cluster string, timeout time.Duration) []checkResult {
+	paths, err := k8s.KubeConfigPaths(configPath, configDir)
+	if err != nil {
+		return []checkResult{{Name: "kubeconfig", Status: checkFail, Detail: err.Error()}}
+	}
+	cm := k8s.NewClusterManager(opts)
+	if err := cm.LoadKubeConfigs(configPath, configDir); err != nil {
+		return []checkResult{{Name: "kubeconfig", Status: checkFail, Detail: err.Error()}}
+	}
+
+	unavailable := cm.GetUnavailableClusters()
+	names := cm.GetClusters()
+	for name := range unavailable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := []checkResult{{
+		Name:   "kubeconfig",
+		Status: checkPass,
+		Detail: fmt.Sprintf("%d clusters (%d unavailable) from %s", len(names), len(unavailable), strings.Join(paths, ", ")),
+	}}
+
+	if cluster != "" {
+		found := false
+		for _, name := range names {
+			found = found || name == cluster
+		}
+		if !found {
+			return append(results, checkResult{Name: "cluster/" + cluster, Status: checkFail, Detail: fmt.Sprintf("cluster %s is not in the kubeconfig", cluster)})
+		}
+		names = []string{cluster}
+	}
+
+	reachability := cm.CheckReachability(ctx, names, 0, clusterCheckConcurrency, timeout)
+	for _, name := range names {
+		result := checkResult{Name: "cluster/" + name}
+		status, checked := reachability[name]
+		switch {
+		case unavailable[name] != nil:
+			result.Status, result.Detail = checkFail, unavailable[name].Error()
+		case !checked:
+			// CheckReachability leaves out the clusters whose client could not be built
+			// CheckReachability 不包含无法创建客户端的集群
+			_, err := cm.GetClientForCluster(name)
+			result.Status, result.Detail = checkFail, fmt.Sprint(err)
+		case !status.Reachable:
+			result.Status, result.Detail = checkFail, status.Error
+		default:
+			result.Status, result.Detail = checkPass, "reachable, "+status.Version
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkTLSCertificate loads the serving certificate and key and reports when the
+// certificate expires: expired or not yet valid certificates fail, and ones expiring
+// within certExpiryWarning are a warning
+// checkTLSCertificate 加载服务证书和私钥并报告证书的到期时间：已过期或尚未生效的证书检查失败，
+// certExpiryWarning 内到期的证书报告警告
+func checkTLSCertificate(certPath, keyPath string, now time.Time) checkResult {
+	result := checkResult{Name: "tls-certificate"}
+	if certPath == "" || keyPath == "" {
+		result.Status, result.Detail = checkFail, "--cert and --key are not set"
+		return result
+	}
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		result.Status, result.Detail = checkFail, fmt.Sprintf("failed to load TLS certificate: %v", err)
+		return result
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		result.Status, result.Detail = checkFail, fmt.Sprintf("failed to parse TLS certificate: %v", err)
+		return result
+	}
+
+	expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+	switch left := cert.NotAfter.Sub(now); {
+	case now.Before(cert.NotBefore):
+		result.Status, result.Detail = checkFail, "not valid before "+cert.NotBefore.UTC().Format(time.RFC3339)
+	case left <= 0:
+		result.Status, result.Detail = checkFail, "expired on "+expiry
+	case left < certExpiryWarning:
+		result.Status, result.Detail = checkWarn, fmt.Sprintf("expires on %s (in %d days)", expiry, int(left.Hours()/24))
+	default:
+		result.Status, result.Detail = checkPass, fmt.Sprintf("expires on %s (in %d days)", expiry, int(left.Hours()/24))
+	}
+	return result
+}
+
+// checkFile parses the file at path with load, as the server does at startup; an unset path is skipped
+// checkFile 与服务器启动时一样使用 load 解析 path 处的文件；未设置路径时跳过
+func checkFile(name, path string, load func(path string) error) checkResult {
+	if path == "" {
+		return checkResult{Name: name, Status: checkSkip, Detail: "not configured"}
+	}
+	if err := load(path); err != nil {
+		return checkResult{Name: name, Status: checkFail, Detail: err.Error()}
+	}
+	return checkResult{Name: name, Status: checkPass, Detail: path}
+}
+
+// checkWritable reports whether the file at path can be appended to, or created along with
+// its missing directories as the log writer would. Nothing is left behind on disk.
+// checkWritable 报告能否追加写入 path 处的文件，或像日志写入器一样连同缺失的目录一起创建该文件。检查不会在磁盘上留下任何内容。
+func checkWritable(name, path string) checkResult {
+	if path == "" {
+		return checkResult{Name: name, Status: checkSkip, Detail: "not configured"}
+	}
+	if err := probeWritable(path); err != nil {
+		return checkResult{Name: name, Status: checkFail, Detail: fmt.Sprintf("%s is not writable: %v", path, err)}
+	}
+	return checkResult{Name: name, Status: checkPass, Detail: path}
+}
+
+// probeWritable opens an existing file for appending, or creates and removes a temporary
+// file in the closest existing directory of a missing one
+// probeWritable 以追加方式打开已存在的文件；文件不存在时在最近的已存在目录中创建并删除一个临时文件
+func probeWritable(path string) error {
+	info, err := os.Stat(path)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("is a directory")
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	file, err := os.CreateTemp(dir, ".k8s-mcp-check-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// writeCheckReport prints the report as a table, or as JSON
+// writeCheckReport 以表格或 JSON 输出报告
+func writeCheckReport(w io.Writer, report checkReport, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	failed := 0
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	for _, check := range report.Checks {
+		if check.Status == checkFail {
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(check.Status), check.Name, check.Detail)
+	}
+	tw.Flush()
+
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		sb.WriteString(strings.TrimRight(line, " "))
+	}
+	if failed > 0 {
+		fmt.Fprintf(&sb, "\n%d of %d checks failed\n", failed, len(report.Checks))
+	} else {
+		fmt.Fprintf(&sb, "\nAll %d checks passed\n", len(report.Checks))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// checkCmd runs the preflight checks without starting the listener
+// checkCmd 执行预检而不启动监听
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the configuration, kubeconfig clusters, TLS certificate and files without starting the server",
+	Long: `check 按服务器启动时的方式检查生效的配置而不启动监听：配置项、kubeconfig 中每个上下文能否解析及其集群能否在超时内响应、
+TLS 证书和私钥能否加载及其到期时间、client CA、token 身份和客户端限流文件，以及日志文件和审计日志是否可写。
+任何检查失败时以非零状态退出。`,
+	Args: cobra.NoArgs,
+	// Skip the logger initialization of the root command: it would create the log file being checked
+	// 跳过根命令的日志初始化：它会创建待检查的日志文件
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return loadConfigFile()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		applyLoggingConfig()
+
+		// Only errors are logged, to stderr, so the report stays readable and --json parseable
+		// 只向 stderr 输出错误日志，使报告保持可读、--json 输出可解析
+		logCfg := logger.NewDefaultConfig()
+		logCfg.Level = "error"
+		logCfg.OutputPaths = []string{"stderr"}
+		if err := logger.Init(logCfg); err != nil {
+			return fmt.Errorf("failed to initialize logger: %w", err)
+		}
+
+		report := runPreflight(cmd.Context(), preflightSteps(cmd, logger.Get()))
+		if err := writeCheckReport(cmd.OutOrStdout(), report, checkJSON); err != nil {
+			return err
+		}
+		if !report.OK {
+			return fmt.Errorf("preflight check failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	checkCmd.Flags().StringVarP(&checkCluster, "cluster", "", "", "Only probe this cluster of the kubeconfig (optional, defaults to all)")
+	checkCmd.Flags().BoolVarP(&checkJSON, "json", "", false, "Print the report as JSON")
+	checkCmd.Flags().DurationVarP(&checkTimeout, "timeout", "", 5*time.Second, "Timeout of the version request to each cluster")
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/internal/mcp"
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+)
+
+// writeFile 在 dir 中写入文件并返回其路径
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// resultsByName 按名称索引检查结果
+func resultsByName(results []checkResult) map[string]checkResult {
+	byName := make(map[string]checkResult, len(results))
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	return byName
+}
+
+// TestCheckKubeconfig 测试可达的集群、无法连接的集群、无法解析的上下文，以及 --cluster 只探测指定集群
+func TestCheckKubeconfig(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"29","gitVersion":"v1.29.3"}`))
+	}))
+	defer apiServer.Close()
+
+	kubeconfig := writeFile(t, t.TempDir(), "config", `apiVersion: v1
+kind: Config
+clusters:
+- name: up
+  cluster:
+    server: `+apiServer.URL+`
+- name: down
+  cluster:
+    server: https://127.0.0.1:1
+- name: broken
+  cluster:
+    server: https://127.0.0.1:1
+    certificate-authority: /nonexistent/ca.crt
+contexts:
+- name: up
+  context: {cluster: up, user: test}
+- name: down
+  context: {cluster: down, user: test}
+- name: broken
+  context: {cluster: broken, user: test}
+current-context: up
+users:
+- name: test
+  user:
+    token: test
+`)
+	opts := &k8s.Options{Logger: logger.NewDefaultConsoleLogger()}
+
+	results := resultsByName(checkKubeconfig(context.Background(), opts, kubeconfig, "", "", 2*time.Second))
+	if len(results) != 4 {
+		t.Fatalf("expected the kubeconfig and three clusters, got %+v", results)
+	}
+	if got := results["kubeconfig"]; got.Status != checkPass || !strings.Contains(got.Detail, "3 clusters (1 unavailable)") {
+		t.Errorf("unexpected kubeconfig result %+v", got)
+	}
+	if got := results["cluster/up"]; got.Status != checkPass || got.Detail != "reachable, v1.29.3" {
+		t.Errorf("unexpected result for up %+v", got)
+	}
+	if got := results["cluster/down"]; got.Status != checkFail || !strings.Contains(got.Detail, "failed to connect to cluster down") {
+		t.Errorf("unexpected result for down %+v", got)
+	}
+	if got := results["cluster/broken"]; got.Status != checkFail || !strings.Contains(got.Detail, "ca.crt") {
+		t.Errorf("unexpected result for broken %+v", got)
+	}
+
+	// --cluster 只探测指定的集群，未知的集群检查失败
+	results = resultsByName(checkKubeconfig(context.Background(), opts, kubeconfig, "", "up", 2*time.Second))
+	if len(results) != 2 || results["cluster/up"].Status != checkPass {
+		t.Errorf("expected only up to be probed, got %+v", results)
+	}
+	results = resultsByName(checkKubeconfig(context.Background(), opts, kubeconfig, "", "missing", 2*time.Second))
+	if got := results["cluster/missing"]; got.Status != checkFail || !strings.Contains(got.Detail, "not in the kubeconfig") {
+		t.Errorf("unexpected result for an unknown cluster %+v", got)
+	}
+
+	// 无法读取的 kubeconfig
+	got := checkKubeconfig(context.Background(), opts, filepath.Join(t.TempDir(), "missing"), "", "", time.Second)
+	if len(got) != 1 || got[0].Name != "kubeconfig" || got[0].Status != checkFail {
+		t.Errorf("expected a failed kubeconfig check, got %+v", got)
+	}
+}
+
+// writeTestCertificate 写入有效期为 [notBefore, notAfter] 的自签名证书和私钥，返回其路径
+func writeTestCertificate(t *testing.T, dir string, notBefore, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "k8s-mcp"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certPath := writeFile(t, dir, "tls.crt", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	keyPath := writeFile(t, dir, "tls.key", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certPath, keyPath
+}
+
+// TestCheckTLSCertificate 测试有效、即将到期、已过期、尚未生效和无法加载的证书
+func TestCheckTLSCertificate(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	cases := []struct {
+		name       string
+		notBefore  time.Time
+		notAfter   time.Time
+		wantStatus string
+		wantDetail string
+	}{
+		{"valid", now.Add(-day), now.Add(90 * day), checkPass, "expires on 2027-01-16T12:00:00Z (in 90 days)"},
+		{"expiring", now.Add(-day), now.Add(10 * day), checkWarn, "(in 10 days)"},
+		{"expired", now.Add(-90 * day), now.Add(-day), checkFail, "expired on 2026-10-17T12:00:00Z"},
+		{"not yet valid", now.Add(day), now.Add(90 * day), checkFail, "not valid before 2026-10-19T12:00:00Z"},
+	}
+	for _, tc := range cases {
+		certPath, keyPath := writeTestCertificate(t, t.TempDir(), tc.notBefore, tc.notAfter)
+		got := checkTLSCertificate(certPath, keyPath, now)
+		if got.Status != tc.wantStatus || !strings.Contains(got.Detail, tc.wantDetail) {
+			t.Errorf("%s: expected %s with %q, got %+v", tc.name, tc.wantStatus, tc.wantDetail, got)
+		}
+	}
+
+	dir := t.TempDir()
+	certPath, _ := writeTestCertificate(t, dir, now.Add(-day), now.Add(90*day))
+	if got := checkTLSCertificate(certPath, filepath.Join(dir, "missing.key"), now); got.Status != checkFail || !strings.Contains(got.Detail, "failed to load TLS certificate") {
+		t.Errorf("expected a failure for a missing key, got %+v", got)
+	}
+	if got := checkTLSCertificate("", "", now); got.Status != checkFail {
+		t.Errorf("expected a failure without --cert and --key, got %+v", got)
+	}
+}
+
+// TestCheckFile 测试未配置的文件被跳过，无效的文件检查失败
+func TestCheckFile(t *testing.T) {
+	load := func(path string) error {
+		_, err := mcp.LoadTokenIdentities(path)
+		return err
+	}
+	dir := t.TempDir()
+
+	if got := checkFile("token-identities", "", load); got.Status != checkSkip {
+		t.Errorf("expected an unset file to be skipped, got %+v", got)
+	}
+	valid := writeFile(t, dir, "valid.yaml", "tokens:\n- token: abc\n  user: alice\n")
+	if got := checkFile("token-identities", valid, load); got.Status != checkPass {
+		t.Errorf("expected a valid file to pass, got %+v", got)
+	}
+	invalid := writeFile(t, dir, "invalid.yaml", "tokens:\n- token: abc\n")
+	if got := checkFile("token-identities", invalid, load); got.Status != checkFail || !strings.Contains(got.Detail, "needs both token and user") {
+		t.Errorf("expected an invalid file to fail, got %+v", got)
+	}
+	if got := checkFile("token-identities", filepath.Join(dir, "missing.yaml"), load); got.Status != checkFail {
+		t.Errorf("expected a missing file to fail, got %+v", got)
+	}
+}
+
+// TestCheckWritable 测试已存在的文件、需要创建目录的文件、目录和父路径为文件的情况，且检查不留下文件
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	existing := writeFile(t, dir, "server.log", "line\n")
+
+	if got := checkWritable("log-file", existing); got.Status != checkPass {
+		t.Errorf("expected an existing file to be writable, got %+v", got)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "line\n" {
+		t.Errorf("existing file was modified: %q", data)
+	}
+
+	missing := filepath.Join(dir, "logs", "nested", "server.log")
+	if got := checkWritable("log-file", missing); got.Status != checkPass {
+		t.Errorf("expected a file in missing directories to be writable, got %+v", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected the check to leave nothing behind, got %v", entries)
+	}
+
+	if got := checkWritable("log-file", dir); got.Status != checkFail || !strings.Contains(got.Detail, "is a directory") {
+		t.Errorf("expected a directory to fail, got %+v", got)
+	}
+	if got := checkWritable("audit-log", filepath.Join(existing, "audit.log")); got.Status != checkFail {
+		t.Errorf("expected a path below a file to fail, got %+v", got)
+	}
+	if got := checkWritable("audit-log", ""); got.Status != checkSkip {
+		t.Errorf("expected an unset path to be skipped, got %+v", got)
+	}
+}
+
+// TestRunPreflight 测试一个步骤失败不影响其他步骤执行，以及文本和 JSON 报告
+func TestRunPreflight(t *testing.T) {
+	ran := 0
+	step := func(name, status string) checkStep {
+		return checkStep{name: name, run: func(context.Context) []checkResult {
+			ran++
+			return []checkResult{{Status: status, Detail: name + " detail"}}
+		}}
+	}
+	report := runPreflight(context.Background(), []checkStep{step("first", checkFail), step("second", checkPass), step("third", checkWarn)})
+	if report.OK || ran != 3 || len(report.Checks) != 3 || report.Checks[0].Name != "first" {
+		t.Fatalf("unexpected report %+v after %d steps", report, ran)
+	}
+
+	var text bytes.Buffer
+	if err := writeCheckReport(&text, report, false); err != nil {
+		t.Fatalf("writeCheckReport failed: %v", err)
+	}
+	want := "FAIL  first   first detail\nPASS  second  second detail\nWARN  third   third detail\n\n1 of 3 checks failed\n"
+	if text.String() != want {
+		t.Errorf("unexpected text report:\n%s", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeCheckReport(&out, report, true); err != nil {
+		t.Fatalf("writeCheckReport failed: %v", err)
+	}
+	var decoded checkReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.OK || len(decoded.Checks) != 3 || decoded.Checks[2].Status != checkWarn {
+		t.Errorf("unexpected JSON report %s (%v)", out.String(), err)
+	}
+
+	report = runPreflight(context.Background(), []checkStep{step("only", checkWarn)})
+	if !report.OK {
+		t.Errorf("expected warnings not to fail the check, got %+v", report)
+	}
+}
@@ -0,0 +1,502 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/internal/mcp"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// fileConfig is the layout of the --config file. Every field mirrors a flag; fields
+// left out keep the flag's environment variable or default, and flags or environment
+// variables that are set take precedence over the file.
+// fileConfig 是 --config 文件的格式。每个字段对应一个标志；未设置的字段使用标志的环境变量或默认值，
+// 已设置的标志和环境变量优先于文件。
+type fileConfig struct {
+	Server     serverFileConfig     `json:"server"`
+	Auth       authFileConfig       `json:"auth"`
+	Kubernetes kubernetesFileConfig `json:"kubernetes"`
+	Features   featuresFileConfig   `json:"features"`
+	Logging    loggingFileConfig    `json:"logging"`
+}
+
+type serverFileConfig struct {
+	Port           *int          `json:"port,omitempty"`
+	Insecure       *bool         `json:"insecure,omitempty"`
+	TLS            tlsFileConfig `json:"tls"`
+	PageSize       *int          `json:"page_size,omitempty"`
+	MaxResultBytes *int          `json:"max_result_bytes,omitempty"`
+	AuditLog       *string       `json:"audit_log,omitempty"`
+	StateFile      *string       `json:"state_file,omitempty"`
+	HistorySize    *int          `json:"history_size,omitempty"`
+	StrictArgs     *bool         `json:"strict_args,omitempty"`
+	Language       *string       `json:"language,omitempty"`
+}
+
+type tlsFileConfig struct {
+	Cert         *string  `json:"cert,omitempty"`
+	Key          *string  `json:"key,omitempty"`
+	ClientCA     *string  `json:"client_ca,omitempty"`
+	MinVersion   *string  `json:"min_version,omitempty"`
+	CipherSuites []string `json:"cipher_suites,omitempty"`
+}
+
+type authFileConfig struct {
+	Token           *string         `json:"token,omitempty"`
+	TokenIdentities *string         `json:"token_identities,omitempty"`
+	OIDC            oidcFileConfig  `json:"oidc"`
+	AuthzWebhook    authzFileConfig `json:"authz_webhook"`
+}
+
+type authzFileConfig struct {
+	URL      *string `json:"url,omitempty"`
+	Timeout  *string `json:"timeout,omitempty"`
+	CacheTTL *string `json:"cache_ttl,omitempty"`
+	FailOpen *bool   `json:"fail_open,omitempty"`
+}
+
+type oidcFileConfig struct {
+	IssuerURL     *string `json:"issuer_url,omitempty"`
+	ClientID      *string `json:"client_id,omitempty"`
+	UsernameClaim *string `json:"username_claim,omitempty"`
+	GroupsClaim   *string `json:"groups_claim,omitempty"`
+}
+
+type kubernetesFileConfig struct {
+	Kubeconfig          *string               `json:"kubeconfig,omitempty"`
+	KubeconfigDir       *string               `json:"kubeconfig_dir,omitempty"`
+	QPS                 *float64              `json:"qps,omitempty"`
+	Burst               *int                  `json:"burst,omitempty"`
+	ClientConfig        *string               `json:"client_config,omitempty"`
+	AllowedNamespaces   []string              `json:"allowed_namespaces,omitempty"`
+	AllowClusterScope   *bool                 `json:"allow_cluster_scope,omitempty"`
+	ProtectedNamespaces []string              `json:"protected_namespaces,omitempty"`
+	CopyAllowedPaths    []string              `json:"copy_allowed_paths,omitempty"`
+	EagerConnect        *bool                 `json:"eager_connect,omitempty"`
+	WarmUp              *bool                 `json:"warm_up,omitempty"`
+	Impersonate         impersonateFileConfig `json:"impersonate"`
+	Mock                *bool                 `json:"mock,omitempty"`
+	MockData            *string               `json:"mock_data,omitempty"`
+}
+
+type impersonateFileConfig struct {
+	User   *string  `json:"user,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type featuresFileConfig struct {
+	Subscriptions    *bool `json:"subscriptions,omitempty"`
+	Exec             *bool `json:"exec,omitempty"`
+	Write            *bool `json:"write,omitempty"`
+	KubeconfigExport *bool `json:"kubeconfig_export,omitempty"`
+}
+
+// loggingFileConfig is applied to the same logger.Config the --log-* flags fill in
+// loggingFileConfig 应用到 --log-* 标志所填充的同一个 logger.Config
+type loggingFileConfig struct {
+	Level        *string `json:"level,omitempty"`
+	Format       *string `json:"format,omitempty"`
+	ToFile       *bool   `json:"to_file,omitempty"`
+	File         *string `json:"file,omitempty"`
+	MaxSize      *int    `json:"max_size,omitempty"`
+	MaxBackups   *int    `json:"max_backups,omitempty"`
+	MaxAge       *int    `json:"max_age,omitempty"`
+	Compress     *bool   `json:"compress,omitempty"`
+	Caller       *bool   `json:"caller,omitempty"`
+	Stacktrace   *bool   `json:"stacktrace,omitempty"`
+	Payloads     *bool   `json:"payloads,omitempty"`
+	PayloadLimit *int    `json:"payload_limit,omitempty"`
+}
+
+// flagValues returns the values set in the file keyed by flag name
+// flagValues 返回文件中已设置的值，以标志名称为键
+func (c *fileConfig) flagValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	setString := func(flag string, value *string) {
+		if value != nil {
+			values[flag] = *value
+		}
+	}
+	setBool := func(flag string, value *bool) {
+		if value != nil {
+			values[flag] = *value
+		}
+	}
+	setInt := func(flag string, value *int) {
+		if value != nil {
+			values[flag] = *value
+		}
+	}
+
+	if c.Server.Port != nil {
+		values["port"] = fmt.Sprint(*c.Server.Port)
+	}
+	setBool("insecure", c.Server.Insecure)
+	setString("cert", c.Server.TLS.Cert)
+	setString("key", c.Server.TLS.Key)
+	setString("client-ca", c.Server.TLS.ClientCA)
+	setString("tls-min-version", c.Server.TLS.MinVersion)
+	if c.Server.TLS.CipherSuites != nil {
+		values["tls-cipher-suites"] = c.Server.TLS.CipherSuites
+	}
+	setInt("page-size", c.Server.PageSize)
+	setInt("max-result-bytes", c.Server.MaxResultBytes)
+	setString("audit-log", c.Server.AuditLog)
+	setString("state-file", c.Server.StateFile)
+	setInt("history-size", c.Server.HistorySize)
+	setBool("strict-args", c.Server.StrictArgs)
+	setString("language", c.Server.Language)
+
+	setString("token", c.Auth.Token)
+	setString("token-identities", c.Auth.TokenIdentities)
+	setString("oidc-issuer-url", c.Auth.OIDC.IssuerURL)
+	setString("oidc-client-id", c.Auth.OIDC.ClientID)
+	setString("oidc-username-claim", c.Auth.OIDC.UsernameClaim)
+	setString("oidc-groups-claim", c.Auth.OIDC.GroupsClaim)
+	setString("authz-webhook-url", c.Auth.AuthzWebhook.URL)
+	setString("authz-webhook-timeout", c.Auth.AuthzWebhook.Timeout)
+	setString("authz-webhook-cache-ttl", c.Auth.AuthzWebhook.CacheTTL)
+	setBool("authz-webhook-fail-open", c.Auth.AuthzWebhook.FailOpen)
+
+	setString("kubeconfig", c.Kubernetes.Kubeconfig)
+	setString("kubeconfig-dir", c.Kubernetes.KubeconfigDir)
+	if c.Kubernetes.QPS != nil {
+		values["k8s-qps"] = *c.Kubernetes.QPS
+	}
+	setInt("k8s-burst", c.Kubernetes.Burst)
+	setString("k8s-client-config", c.Kubernetes.ClientConfig)
+	if c.Kubernetes.AllowedNamespaces != nil {
+		values["allowed-namespaces"] = strings.Join(c.Kubernetes.AllowedNamespaces, ",")
+	}
+	setBool("allow-cluster-scope", c.Kubernetes.AllowClusterScope)
+	if c.Kubernetes.ProtectedNamespaces != nil {
+		values["protected-namespaces"] = strings.Join(c.Kubernetes.ProtectedNamespaces, ",")
+	}
+	if c.Kubernetes.CopyAllowedPaths != nil {
+		values["copy-allowed-paths"] = strings.Join(c.Kubernetes.CopyAllowedPaths, ",")
+	}
+	setBool("eager-connect", c.Kubernetes.EagerConnect)
+	setBool("warm-up", c.Kubernetes.WarmUp)
+	setString("impersonate-user", c.Kubernetes.Impersonate.User)
+	if c.Kubernetes.Impersonate.Groups != nil {
+		values["impersonate-group"] = c.Kubernetes.Impersonate.Groups
+	}
+	setBool("mock", c.Kubernetes.Mock)
+	setString("mock-data", c.Kubernetes.MockData)
+
+	setBool("enable-subscriptions", c.Features.Subscriptions)
+	setBool("allow-exec", c.Features.Exec)
+	setBool("allow-write", c.Features.Write)
+	setBool("allow-kubeconfig-export", c.Features.KubeconfigExport)
+
+	setString("log-level", c.Logging.Level)
+	setString("log-format", c.Logging.Format)
+	setBool("log-to-file", c.Logging.ToFile)
+	setString("log-file", c.Logging.File)
+	setInt("log-max-size", c.Logging.MaxSize)
+	setInt("log-max-backups", c.Logging.MaxBackups)
+	setInt("log-max-age", c.Logging.MaxAge)
+	setBool("log-compress", c.Logging.Compress)
+	setBool("log-caller", c.Logging.Caller)
+	setBool("log-stacktrace", c.Logging.Stacktrace)
+	setBool("log-payloads", c.Logging.Payloads)
+	setInt("log-payload-limit", c.Logging.PayloadLimit)
+	return values
+}
+
+// loadConfigFile reads the --config file, if any, into viper's config layer so that
+// flags > env > file > defaults. Unknown keys and mistyped values are errors.
+// loadConfigFile 将 --config 文件（如果有）读入 viper 的配置层，使优先级为 标志 > 环境变量 > 文件 > 默认值。
+// 未知的键和类型错误的值会报错。
+func loadConfigFile() error {
+	path := viper.GetString("config")
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var file fileConfig
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return viper.MergeConfigMap(file.flagValues())
+}
+
+// applyLoggingConfig copies the effective --log-* settings into logConfig
+// applyLoggingConfig 将生效的 --log-* 设置写入 logConfig
+func applyLoggingConfig() {
+	logConfig.Level = viper.GetString("log-level")
+	logConfig.Format = viper.GetString("log-format")
+	logConfig.RotationConfig.Filename = viper.GetString("log-file")
+	logConfig.RotationConfig.MaxSize = viper.GetInt("log-max-size")
+	logConfig.RotationConfig.MaxBackups = viper.GetInt("log-max-backups")
+	logConfig.RotationConfig.MaxAge = viper.GetInt("log-max-age")
+	logConfig.RotationConfig.Compress = viper.GetBool("log-compress")
+	logConfig.EnableCaller = viper.GetBool("log-caller")
+	logConfig.EnableStacktrace = viper.GetBool("log-stacktrace")
+}
+
+// logToFile reports whether logs also go to the log file. The server logs to file
+// unless --log-to-file=false or logging.to_file: false is given.
+// logToFile 判断日志是否同时写入文件。除非指定 --log-to-file=false 或 logging.to_file: false，服务器默认写入文件。
+func logToFile(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("log-to-file") || viper.InConfig("log-to-file") {
+		return viper.GetBool("log-to-file")
+	}
+	return true
+}
+
+// validateSettings checks the effective settings that don't need the network or other files
+// validateSettings 检查不依赖网络和其他文件的生效配置
+func validateSettings() error {
+	if viper.GetString("token") == "" && viper.GetString("client-ca") == "" && viper.GetString("oidc-issuer-url") == "" {
+		return fmt.Errorf("--token is required unless --client-ca or --oidc-issuer-url is set")
+	}
+	if issuer := viper.GetString("oidc-issuer-url"); issuer != "" {
+		if !strings.HasPrefix(issuer, "https://") {
+			return fmt.Errorf("--oidc-issuer-url must be an https:// URL")
+		}
+		if viper.GetString("oidc-client-id") == "" {
+			return fmt.Errorf("--oidc-client-id is required with --oidc-issuer-url")
+		}
+		if viper.GetString("oidc-username-claim") == "" {
+			return fmt.Errorf("--oidc-username-claim must not be empty")
+		}
+	}
+	if webhook := viper.GetString("authz-webhook-url"); webhook != "" {
+		if !strings.HasPrefix(webhook, "https://") && !strings.HasPrefix(webhook, "http://") {
+			return fmt.Errorf("--authz-webhook-url must be an http:// or https:// URL")
+		}
+		if viper.GetDuration("authz-webhook-timeout") <= 0 {
+			return fmt.Errorf("--authz-webhook-timeout must be positive")
+		}
+	}
+	if _, err := mcp.ParseLanguage(viper.GetString("language")); err != nil {
+		return fmt.Errorf("invalid --language: %w", err)
+	}
+	if viper.GetInt("log-payload-limit") < 0 {
+		return fmt.Errorf("--log-payload-limit must not be negative")
+	}
+	if viper.GetInt("page-size") < 0 {
+		return fmt.Errorf("--page-size must not be negative")
+	}
+	if maxResultBytes := viper.GetInt("max-result-bytes"); maxResultBytes < 0 || maxResultBytes > mcp.MaxResultBytesCeiling {
+		return fmt.Errorf("--max-result-bytes must be between 0 and %d", mcp.MaxResultBytesCeiling)
+	}
+	if viper.GetFloat64("k8s-qps") < 0 || viper.GetInt("k8s-burst") < 0 {
+		return fmt.Errorf("--k8s-qps and --k8s-burst must not be negative")
+	}
+	if viper.GetString("impersonate-user") == "" && len(viper.GetStringSlice("impersonate-group")) > 0 {
+		return fmt.Errorf("--impersonate-group requires --impersonate-user")
+	}
+	if !viper.GetBool("insecure") && (viper.GetString("cert") == "" || viper.GetString("key") == "") {
+		return fmt.Errorf("--cert and --key are required for HTTPS mode (default). Use --insecure for HTTP mode.")
+	}
+	if viper.GetBool("insecure") && viper.GetString("client-ca") != "" {
+		return fmt.Errorf("--client-ca requires HTTPS mode and cannot be used with --insecure")
+	}
+	if _, err := mcp.NewTLSConfig(viper.GetString("tls-min-version"), viper.GetStringSlice("tls-cipher-suites")); err != nil {
+		return fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	if _, err := k8s.ParseNamespacePolicy(viper.GetString("allowed-namespaces"), false); err != nil {
+		return fmt.Errorf("invalid --allowed-namespaces: %w", err)
+	}
+	// The mock cluster has no API transport to enforce namespace restrictions or impersonation on
+	// 模拟集群没有 API 传输层，无法执行命名空间限制和身份模拟
+	if viper.GetBool("mock") && (viper.GetString("allowed-namespaces") != "" || viper.GetString("impersonate-user") != "") {
+		return fmt.Errorf("--allowed-namespaces and --impersonate-user cannot be used with --mock")
+	}
+	if viper.GetString("mock-data") != "" && !viper.GetBool("mock") {
+		return fmt.Errorf("--mock-data requires --mock")
+	}
+	if value := viper.GetString("copy-allowed-paths"); value != "" {
+		for _, dir := range strings.Split(value, ",") {
+			if !path.IsAbs(dir) {
+				return fmt.Errorf("--copy-allowed-paths must be absolute directories, got %q", dir)
+			}
+		}
+	}
+	return nil
+}
+
+// maskedValue hides a secret while still showing whether it is set
+// maskedValue 隐藏敏感值，同时仍显示其是否已设置
+func maskedValue(value string) *string {
+	if value != "" {
+		value = "******"
+	}
+	return &value
+}
+
+// effectiveConfig returns the settings in effect after applying flags, env and the
+// config file, in the config file layout, with secrets masked
+// effectiveConfig 以配置文件格式返回合并标志、环境变量和配置文件后生效的设置，敏感值已隐藏
+func effectiveConfig(cmd *cobra.Command) fileConfig {
+	str := func(key string) *string {
+		value := viper.GetString(key)
+		return &value
+	}
+	boolean := func(key string) *bool {
+		value := viper.GetBool(key)
+		return &value
+	}
+	integer := func(key string) *int {
+		value := viper.GetInt(key)
+		return &value
+	}
+
+	port := viper.GetInt("port")
+	qps := viper.GetFloat64("k8s-qps")
+	toFile := logToFile(cmd)
+	var allowedNamespaces, protectedNamespaces, copyAllowedPaths []string
+	if value := viper.GetString("allowed-namespaces"); value != "" {
+		allowedNamespaces = strings.Split(value, ",")
+	}
+	if value := viper.GetString("protected-namespaces"); value != "" {
+		protectedNamespaces = strings.Split(value, ",")
+	}
+	if value := viper.GetString("copy-allowed-paths"); value != "" {
+		copyAllowedPaths = strings.Split(value, ",")
+	}
+
+	return fileConfig{
+		Server: serverFileConfig{
+			Port:     &port,
+			Insecure: boolean("insecure"),
+			TLS: tlsFileConfig{
+				Cert:         str("cert"),
+				Key:          str("key"),
+				ClientCA:     str("client-ca"),
+				MinVersion:   str("tls-min-version"),
+				CipherSuites: viper.GetStringSlice("tls-cipher-suites"),
+			},
+			PageSize:       integer("page-size"),
+			MaxResultBytes: integer("max-result-bytes"),
+			AuditLog:       str("audit-log"),
+			StateFile:      str("state-file"),
+			HistorySize:    integer("history-size"),
+			StrictArgs:     boolean("strict-args"),
+			Language:       str("language"),
+		},
+		Auth: authFileConfig{
+			Token:           maskedValue(viper.GetString("token")),
+			TokenIdentities: str("token-identities"),
+			OIDC: oidcFileConfig{
+				IssuerURL:     str("oidc-issuer-url"),
+				ClientID:      str("oidc-client-id"),
+				UsernameClaim: str("oidc-username-claim"),
+				GroupsClaim:   str("oidc-groups-claim"),
+			},
+			AuthzWebhook: authzFileConfig{
+				URL:      str("authz-webhook-url"),
+				Timeout:  str("authz-webhook-timeout"),
+				CacheTTL: str("authz-webhook-cache-ttl"),
+				FailOpen: boolean("authz-webhook-fail-open"),
+			},
+		},
+		Kubernetes: kubernetesFileConfig{
+			Kubeconfig:          str("kubeconfig"),
+			KubeconfigDir:       str("kubeconfig-dir"),
+			QPS:                 &qps,
+			Burst:               integer("k8s-burst"),
+			ClientConfig:        str("k8s-client-config"),
+			AllowedNamespaces:   allowedNamespaces,
+			AllowClusterScope:   boolean("allow-cluster-scope"),
+			ProtectedNamespaces: protectedNamespaces,
+			CopyAllowedPaths:    copyAllowedPaths,
+			EagerConnect:        boolean("eager-connect"),
+			WarmUp:              boolean("warm-up"),
+			Impersonate: impersonateFileConfig{
+				User:   str("impersonate-user"),
+				Groups: viper.GetStringSlice("impersonate-group"),
+			},
+			Mock:     boolean("mock"),
+			MockData: str("mock-data"),
+		},
+		Features: featuresFileConfig{
+			Subscriptions:    boolean("enable-subscriptions"),
+			Exec:             boolean("allow-exec"),
+			Write:            boolean("allow-write"),
+			KubeconfigExport: boolean("allow-kubeconfig-export"),
+		},
+		Logging: loggingFileConfig{
+			Level:        str("log-level"),
+			Format:       str("log-format"),
+			ToFile:       &toFile,
+			File:         str("log-file"),
+			MaxSize:      integer("log-max-size"),
+			MaxBackups:   integer("log-max-backups"),
+			MaxAge:       integer("log-max-age"),
+			Compress:     boolean("log-compress"),
+			Caller:       boolean("log-caller"),
+			Stacktrace:   boolean("log-stacktrace"),
+			Payloads:     boolean("log-payloads"),
+			PayloadLimit: integer("log-payload-limit"),
+		},
+	}
+}
+
+// configCmd groups the configuration subcommands
+// configCmd 配置相关子命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the server configuration",
+}
+
+// configValidateCmd parses the configuration and prints the effective settings
+// configValidateCmd 解析配置并输出生效的设置
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration (--config file, env and flags) and print the effective settings with secrets masked",
+	Args:  cobra.NoArgs,
+	// Skip the logger initialization of the root command so only the configuration is printed
+	// 跳过根命令的日志初始化，只输出配置
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return loadConfigFile()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateSettings(); err != nil {
+			return err
+		}
+		// Referenced files are parsed too, as the server would at startup
+		// 与服务器启动时一样解析引用的文件
+		if path := viper.GetString("client-ca"); path != "" {
+			if _, err := mcp.NewClientCertTLSConfig(path); err != nil {
+				return err
+			}
+		}
+		if path := viper.GetString("token-identities"); path != "" {
+			if _, err := mcp.LoadTokenIdentities(path); err != nil {
+				return err
+			}
+		}
+		if path := viper.GetString("k8s-client-config"); path != "" {
+			if _, err := k8s.LoadClusterClientSettings(path); err != nil {
+				return err
+			}
+		}
+
+		data, err := yaml.Marshal(effectiveConfig(cmd))
+		if err != nil {
+			return fmt.Errorf("failed to print configuration: %w", err)
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -1,68 +1,242 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
 	"github.com/AceDarkknight/k8s-mcp/internal/mcp"
 	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
 )
 
 var (
 	// Configuration flags
 	// 配置标志
-	cfgPort       string
-	cfgCertPath   string
-	cfgKeyPath    string
-	cfgInsecure   bool
-	cfgAuthToken  string
-	cfgConfigPath string
+	cfgPort                string
+	cfgCertPath            string
+	cfgKeyPath             string
+	cfgTLSMinVersion       string
+	cfgTLSCipherSuites     []string
+	cfgInsecure            bool
+	cfgAuthToken           string
+	cfgClientCA            string
+	cfgOIDCIssuerURL       string
+	cfgOIDCClientID        string
+	cfgOIDCUsername        string
+	cfgOIDCGroups          string
+	cfgAuthzWebhookURL     string
+	cfgAuthzWebhookTimeout time.Duration
+	cfgAuthzWebhookTTL     time.Duration
+	cfgAuthzWebhookOpen    bool
+	cfgConfigPath          string
+	cfgConfigDir           string
+	cfgSubscribe           bool
+	cfgPageSize            int
+	cfgMaxResultBytes      int
+	cfgAuditLog            string
+	cfgStateFile           string
+	cfgHistorySize         int
+	cfgStrictArgs          bool
+	cfgLanguage            string
+	cfgLogPayloads         bool
+	cfgPayloadLogLimit     int
+	cfgK8sQPS              float32
+	cfgK8sBurst            int
+	cfgK8sClient           string
+	cfgAllowedNamespaces   string
+	cfgAllowClusterScope   bool
+	cfgImpersonateUser     string
+	cfgImpersonateGroups   []string
+	cfgTokenIdentities     string
+	cfgAllowExec           bool
+	cfgAllowWrite          bool
+	cfgAllowKubeconfig     bool
+	cfgProtectedNamespaces string
+	cfgCopyAllowedPaths    string
+	cfgEagerConnect        bool
+	cfgWarmUp              bool
+	cfgMock                bool
+	cfgMockData            string
+	cfgFile                string
 
 	// 日志配置
 	logConfig = logger.NewDefaultConfig()
 )
 
+// certReloadInterval is how often the TLS certificate files are checked for changes
+// certReloadInterval 检查 TLS 证书文件是否变化的间隔
+const certReloadInterval = 30 * time.Second
+
 // initConfig initializes configuration from flags and environment variables
 // initConfig 从标志和环境变量初始化配置
 func initConfig() {
 	// Bind environment variables
 	// 绑定环境变量
+	viper.BindEnv("config", "MCP_CONFIG")
 	viper.BindEnv("port", "MCP_PORT")
 	viper.BindEnv("cert", "MCP_CERT")
 	viper.BindEnv("key", "MCP_KEY")
+	viper.BindEnv("tls-min-version", "MCP_TLS_MIN_VERSION")
+	viper.BindEnv("tls-cipher-suites", "MCP_TLS_CIPHER_SUITES")
 	viper.BindEnv("insecure", "MCP_INSECURE")
 	viper.BindEnv("token", "MCP_TOKEN")
+	viper.BindEnv("client-ca", "MCP_CLIENT_CA")
+	viper.BindEnv("oidc-issuer-url", "MCP_OIDC_ISSUER_URL")
+	viper.BindEnv("oidc-client-id", "MCP_OIDC_CLIENT_ID")
+	viper.BindEnv("oidc-username-claim", "MCP_OIDC_USERNAME_CLAIM")
+	viper.BindEnv("oidc-groups-claim", "MCP_OIDC_GROUPS_CLAIM")
+	viper.BindEnv("authz-webhook-url", "MCP_AUTHZ_WEBHOOK_URL")
+	viper.BindEnv("authz-webhook-timeout", "MCP_AUTHZ_WEBHOOK_TIMEOUT")
+	viper.BindEnv("authz-webhook-cache-ttl", "MCP_AUTHZ_WEBHOOK_CACHE_TTL")
+	viper.BindEnv("authz-webhook-fail-open", "MCP_AUTHZ_WEBHOOK_FAIL_OPEN")
 	viper.BindEnv("kubeconfig", "MCP_KUBECONFIG")
+	viper.BindEnv("kubeconfig-dir", "MCP_KUBECONFIG_DIR")
+	viper.BindEnv("enable-subscriptions", "MCP_ENABLE_SUBSCRIPTIONS")
+	viper.BindEnv("page-size", "MCP_PAGE_SIZE")
+	viper.BindEnv("max-result-bytes", "MCP_MAX_RESULT_BYTES")
+	viper.BindEnv("audit-log", "MCP_AUDIT_LOG")
+	viper.BindEnv("state-file", "MCP_STATE_FILE")
+	viper.BindEnv("history-size", "MCP_HISTORY_SIZE")
+	viper.BindEnv("strict-args", "MCP_STRICT_ARGS")
+	viper.BindEnv("language", "MCP_LANGUAGE")
+	viper.BindEnv("log-payloads", "MCP_LOG_PAYLOADS")
+	viper.BindEnv("log-payload-limit", "MCP_LOG_PAYLOAD_LIMIT")
+	viper.BindEnv("k8s-qps", "MCP_K8S_QPS")
+	viper.BindEnv("k8s-burst", "MCP_K8S_BURST")
+	viper.BindEnv("k8s-client-config", "MCP_K8S_CLIENT_CONFIG")
+	viper.BindEnv("allowed-namespaces", "MCP_ALLOWED_NAMESPACES")
+	viper.BindEnv("allow-cluster-scope", "MCP_ALLOW_CLUSTER_SCOPE")
+	viper.BindEnv("impersonate-user", "MCP_IMPERSONATE_USER")
+	viper.BindEnv("impersonate-group", "MCP_IMPERSONATE_GROUP")
+	viper.BindEnv("token-identities", "MCP_TOKEN_IDENTITIES")
+	viper.BindEnv("allow-exec", "MCP_ALLOW_EXEC")
+	viper.BindEnv("allow-write", "MCP_ALLOW_WRITE")
+	viper.BindEnv("allow-kubeconfig-export", "MCP_ALLOW_KUBECONFIG_EXPORT")
+	viper.BindEnv("protected-namespaces", "MCP_PROTECTED_NAMESPACES")
+	viper.BindEnv("copy-allowed-paths", "MCP_COPY_ALLOWED_PATHS")
+	viper.BindEnv("eager-connect", "MCP_EAGER_CONNECT")
+	viper.BindEnv("warm-up", "MCP_WARM_UP")
+	viper.BindEnv("mock", "MCP_MOCK")
+	viper.BindEnv("mock-data", "MCP_MOCK_DATA")
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	// Define flags directly on command
-	// 直接在命令上定义标志
-	rootCmd.Flags().StringVarP(&cfgPort, "port", "p", "8443", "Port to listen on")
-	rootCmd.Flags().StringVarP(&cfgCertPath, "cert", "c", "", "Path to TLS certificate file (required for HTTPS)")
-	rootCmd.Flags().StringVarP(&cfgKeyPath, "key", "k", "", "Path to TLS key file (required for HTTPS)")
-	rootCmd.Flags().BoolVarP(&cfgInsecure, "insecure", "i", false, "Run in insecure HTTP mode (default is HTTPS)")
-	rootCmd.Flags().StringVarP(&cfgAuthToken, "token", "t", "", "Authentication token (required)")
-	rootCmd.Flags().StringVarP(&cfgConfigPath, "kubeconfig", "", "", "Path to kubeconfig file (optional)")
+	// Flags are persistent so "config validate" sees the same settings as the server
+	// 标志定义为持久标志，使 "config validate" 与服务器读取相同的设置
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "", "", "Path to a YAML config file; flags and environment variables take precedence over it (optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgPort, "port", "p", "8443", "Port to listen on")
+	rootCmd.PersistentFlags().StringVarP(&cfgCertPath, "cert", "c", "", "Path to TLS certificate file (required for HTTPS)")
+	rootCmd.PersistentFlags().StringVarP(&cfgKeyPath, "key", "k", "", "Path to TLS key file (required for HTTPS)")
+	rootCmd.PersistentFlags().StringVarP(&cfgTLSMinVersion, "tls-min-version", "", "1.2", "Minimum TLS version of the HTTPS listener: 1.2 or 1.3")
+	rootCmd.PersistentFlags().StringSliceVarP(&cfgTLSCipherSuites, "tls-cipher-suites", "", nil, "Comma-separated TLS 1.2 cipher suites, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 (optional, defaults to Go's; not allowed with --tls-min-version=1.3)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgInsecure, "insecure", "i", false, "Run in insecure HTTP mode (default is HTTPS)")
+	rootCmd.PersistentFlags().StringVarP(&cfgAuthToken, "token", "t", "", "Authentication token (required unless --client-ca or --oidc-issuer-url is set)")
+	rootCmd.PersistentFlags().StringVarP(&cfgClientCA, "client-ca", "", "", "Path to a PEM CA bundle; clients must present a certificate signed by it, whose CN/O become the caller identity (optional, makes --token optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgOIDCIssuerURL, "oidc-issuer-url", "", "", "HTTPS URL of an OIDC issuer; bearer tokens that are its JWTs are accepted and their claims become the caller identity (optional, makes --token optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgOIDCClientID, "oidc-client-id", "", "", "Client ID that must be in the aud claim of OIDC tokens (required with --oidc-issuer-url)")
+	rootCmd.PersistentFlags().StringVarP(&cfgOIDCUsername, "oidc-username-claim", "", "sub", "OIDC token claim used as the user name")
+	rootCmd.PersistentFlags().StringVarP(&cfgOIDCGroups, "oidc-groups-claim", "", "", "OIDC token claim used as the groups (optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgAuthzWebhookURL, "authz-webhook-url", "", "", "URL of an HTTP webhook that authorizes every tool call and resource read (optional)")
+	rootCmd.PersistentFlags().DurationVarP(&cfgAuthzWebhookTimeout, "authz-webhook-timeout", "", mcp.DefaultAuthzWebhookTimeout, "Timeout of one request to the authorization webhook")
+	rootCmd.PersistentFlags().DurationVarP(&cfgAuthzWebhookTTL, "authz-webhook-cache-ttl", "", mcp.DefaultAuthzWebhookCacheTTL, "How long an authorization webhook decision is reused for the same user, tool, cluster and namespace (negative disables the cache)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgAuthzWebhookOpen, "authz-webhook-fail-open", "", false, "Allow tool calls when the authorization webhook is unreachable or fails (default denies them)")
+	rootCmd.PersistentFlags().StringVarP(&cfgConfigPath, "kubeconfig", "", "", "Path to kubeconfig file, or several separated like $KUBECONFIG (optional, defaults to $KUBECONFIG or ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVarP(&cfgConfigDir, "kubeconfig-dir", "", "", "Directory whose *.yaml and *.yml kubeconfig files are merged with --kubeconfig, e.g. one file per cluster (optional)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgEagerConnect, "eager-connect", "", false, "Build the client of every kubeconfig context at startup and probe them all, instead of building each client on first use")
+	rootCmd.PersistentFlags().BoolVarP(&cfgWarmUp, "warm-up", "", true, "Without --eager-connect, build and probe the current context's client in the background at startup")
+	rootCmd.PersistentFlags().BoolVarP(&cfgMock, "mock", "", false, "Serve an in-memory mock cluster instead of the kubeconfig clusters, for demos and tests; writes only change the mock data")
+	rootCmd.PersistentFlags().StringVarP(&cfgMockData, "mock-data", "", "", "Directory of YAML/JSON fixtures seeding the mock cluster (optional, defaults to the built-in fixtures; requires --mock)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgSubscribe, "enable-subscriptions", "", false, "Enable resource subscriptions backed by Kubernetes watches")
+	rootCmd.PersistentFlags().IntVarP(&cfgPageSize, "page-size", "", 0, "Maximum number of tools per tools/list page (0 uses the SDK default of 1000)")
+	rootCmd.PersistentFlags().IntVarP(&cfgMaxResultBytes, "max-result-bytes", "", mcp.DefaultMaxResultBytes, "Size in bytes above which tool results are truncated; a call may override it with max_bytes")
+	rootCmd.PersistentFlags().StringVarP(&cfgAuditLog, "audit-log", "", "", "Path to the audit log file recording every tool call (optional, rotated with the --log-max-* settings)")
+	rootCmd.PersistentFlags().StringVarP(&cfgStateFile, "state-file", "", "", "Path to a JSON file remembering each caller's selected cluster and namespace and saved queries across restarts (optional)")
+	rootCmd.PersistentFlags().IntVarP(&cfgHistorySize, "history-size", "", mcp.DefaultHistorySize, "Number of recent tool calls kept for get_call_history and k8s://server/history (-1 disables it)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgStrictArgs, "strict-args", "", true, "Reject tool calls with unknown arguments, suggesting the closest valid name; when false they are dropped and logged")
+	rootCmd.PersistentFlags().StringVarP(&cfgLanguage, "language", "", "", "Language prompts ask answers in and fixed tool result strings use: en or zh (default none; clients can override it with _meta.language at initialize)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgLogPayloads, "log-payloads", "", false, "Log every JSON-RPC message at debug level with secret-looking values redacted; results can hold cluster data, so only enable it for debugging")
+	rootCmd.PersistentFlags().IntVarP(&cfgPayloadLogLimit, "log-payload-limit", "", mcp.DefaultPayloadLogLimit, "Maximum bytes of each payload logged with --log-payloads")
+	rootCmd.PersistentFlags().Float32VarP(&cfgK8sQPS, "k8s-qps", "", 50, "Maximum queries per second to each Kubernetes API server")
+	rootCmd.PersistentFlags().IntVarP(&cfgK8sBurst, "k8s-burst", "", 100, "Maximum burst of requests to each Kubernetes API server")
+	rootCmd.PersistentFlags().StringVarP(&cfgK8sClient, "k8s-client-config", "", "", "Path to a YAML file with per-cluster qps/burst overrides (optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgAllowedNamespaces, "allowed-namespaces", "", "", "Comma-separated namespaces (globs like team-a-* allowed) every operation is restricted to (optional)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgAllowClusterScope, "allow-cluster-scope", "", false, "With --allowed-namespaces, still allow cluster-scoped resources such as nodes")
+	rootCmd.PersistentFlags().StringVarP(&cfgImpersonateUser, "impersonate-user", "", "", "Run every Kubernetes API call as this user or service account (system:serviceaccount:<ns>:<name>) via impersonation")
+	rootCmd.PersistentFlags().StringSliceVarP(&cfgImpersonateGroups, "impersonate-group", "", nil, "Group to impersonate along with --impersonate-user (repeatable)")
+	rootCmd.PersistentFlags().StringVarP(&cfgTokenIdentities, "token-identities", "", "", "Path to a YAML file mapping extra bearer tokens to the user and groups they impersonate (optional)")
+	rootCmd.PersistentFlags().BoolVarP(&cfgAllowExec, "allow-exec", "", false, "Enable tools that run processes in pods, such as debug_pod")
+	rootCmd.PersistentFlags().BoolVarP(&cfgAllowWrite, "allow-write", "", false, "Enable tools that modify cluster objects, such as rollback_deployment and drain_node")
+	rootCmd.PersistentFlags().BoolVarP(&cfgAllowKubeconfig, "allow-kubeconfig-export", "", false, "Enable get_kubeconfig, which exports a minimal kubeconfig for a context (credentials redacted by default)")
+	rootCmd.PersistentFlags().StringVarP(&cfgProtectedNamespaces, "protected-namespaces", "", "", "Comma-separated namespaces delete_namespace refuses to delete, besides default and the kube-* system namespaces (optional)")
+	rootCmd.PersistentFlags().StringVarP(&cfgCopyAllowedPaths, "copy-allowed-paths", "", strings.Join(k8s.DefaultCopyAllowedPaths, ","), "Comma-separated directories cp_to_pod may write files under")
 
 	// Bind flags to viper
 	// 将标志绑定到 viper
-	viper.BindPFlag("port", rootCmd.Flags().Lookup("port"))
-	viper.BindPFlag("cert", rootCmd.Flags().Lookup("cert"))
-	viper.BindPFlag("key", rootCmd.Flags().Lookup("key"))
-	viper.BindPFlag("insecure", rootCmd.Flags().Lookup("insecure"))
-	viper.BindPFlag("token", rootCmd.Flags().Lookup("token"))
-	viper.BindPFlag("kubeconfig", rootCmd.Flags().Lookup("kubeconfig"))
-
-	// Bind logger flags
-	// 绑定日志标志（包括 log-to-file）
+	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+	viper.BindPFlag("port", rootCmd.PersistentFlags().Lookup("port"))
+	viper.BindPFlag("cert", rootCmd.PersistentFlags().Lookup("cert"))
+	viper.BindPFlag("key", rootCmd.PersistentFlags().Lookup("key"))
+	viper.BindPFlag("tls-min-version", rootCmd.PersistentFlags().Lookup("tls-min-version"))
+	viper.BindPFlag("tls-cipher-suites", rootCmd.PersistentFlags().Lookup("tls-cipher-suites"))
+	viper.BindPFlag("insecure", rootCmd.PersistentFlags().Lookup("insecure"))
+	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
+	viper.BindPFlag("client-ca", rootCmd.PersistentFlags().Lookup("client-ca"))
+	viper.BindPFlag("oidc-issuer-url", rootCmd.PersistentFlags().Lookup("oidc-issuer-url"))
+	viper.BindPFlag("oidc-client-id", rootCmd.PersistentFlags().Lookup("oidc-client-id"))
+	viper.BindPFlag("oidc-username-claim", rootCmd.PersistentFlags().Lookup("oidc-username-claim"))
+	viper.BindPFlag("oidc-groups-claim", rootCmd.PersistentFlags().Lookup("oidc-groups-claim"))
+	viper.BindPFlag("authz-webhook-url", rootCmd.PersistentFlags().Lookup("authz-webhook-url"))
+	viper.BindPFlag("authz-webhook-timeout", rootCmd.PersistentFlags().Lookup("authz-webhook-timeout"))
+	viper.BindPFlag("authz-webhook-cache-ttl", rootCmd.PersistentFlags().Lookup("authz-webhook-cache-ttl"))
+	viper.BindPFlag("authz-webhook-fail-open", rootCmd.PersistentFlags().Lookup("authz-webhook-fail-open"))
+	viper.BindPFlag("kubeconfig", rootCmd.PersistentFlags().Lookup("kubeconfig"))
+	viper.BindPFlag("kubeconfig-dir", rootCmd.PersistentFlags().Lookup("kubeconfig-dir"))
+	viper.BindPFlag("enable-subscriptions", rootCmd.PersistentFlags().Lookup("enable-subscriptions"))
+	viper.BindPFlag("page-size", rootCmd.PersistentFlags().Lookup("page-size"))
+	viper.BindPFlag("max-result-bytes", rootCmd.PersistentFlags().Lookup("max-result-bytes"))
+	viper.BindPFlag("audit-log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	viper.BindPFlag("state-file", rootCmd.PersistentFlags().Lookup("state-file"))
+	viper.BindPFlag("history-size", rootCmd.PersistentFlags().Lookup("history-size"))
+	viper.BindPFlag("strict-args", rootCmd.PersistentFlags().Lookup("strict-args"))
+	viper.BindPFlag("language", rootCmd.PersistentFlags().Lookup("language"))
+	viper.BindPFlag("log-payloads", rootCmd.PersistentFlags().Lookup("log-payloads"))
+	viper.BindPFlag("log-payload-limit", rootCmd.PersistentFlags().Lookup("log-payload-limit"))
+	viper.BindPFlag("k8s-qps", rootCmd.PersistentFlags().Lookup("k8s-qps"))
+	viper.BindPFlag("k8s-burst", rootCmd.PersistentFlags().Lookup("k8s-burst"))
+	viper.BindPFlag("k8s-client-config", rootCmd.PersistentFlags().Lookup("k8s-client-config"))
+	viper.BindPFlag("allowed-namespaces", rootCmd.PersistentFlags().Lookup("allowed-namespaces"))
+	viper.BindPFlag("allow-cluster-scope", rootCmd.PersistentFlags().Lookup("allow-cluster-scope"))
+	viper.BindPFlag("impersonate-user", rootCmd.PersistentFlags().Lookup("impersonate-user"))
+	viper.BindPFlag("impersonate-group", rootCmd.PersistentFlags().Lookup("impersonate-group"))
+	viper.BindPFlag("token-identities", rootCmd.PersistentFlags().Lookup("token-identities"))
+	viper.BindPFlag("allow-exec", rootCmd.PersistentFlags().Lookup("allow-exec"))
+	viper.BindPFlag("allow-write", rootCmd.PersistentFlags().Lookup("allow-write"))
+	viper.BindPFlag("allow-kubeconfig-export", rootCmd.PersistentFlags().Lookup("allow-kubeconfig-export"))
+	viper.BindPFlag("protected-namespaces", rootCmd.PersistentFlags().Lookup("protected-namespaces"))
+	viper.BindPFlag("copy-allowed-paths", rootCmd.PersistentFlags().Lookup("copy-allowed-paths"))
+	viper.BindPFlag("eager-connect", rootCmd.PersistentFlags().Lookup("eager-connect"))
+	viper.BindPFlag("warm-up", rootCmd.PersistentFlags().Lookup("warm-up"))
+	viper.BindPFlag("mock", rootCmd.PersistentFlags().Lookup("mock"))
+	viper.BindPFlag("mock-data", rootCmd.PersistentFlags().Lookup("mock-data"))
+
+	// Bind logger flags; they go through viper too so the config file can set them
+	// 绑定日志标志（包括 log-to-file），同样经过 viper，以便配置文件设置
 	logger.BindFlags(rootCmd.PersistentFlags(), logConfig)
+	for _, name := range []string{"log-level", "log-format", "log-to-file", "log-file", "log-max-size", "log-max-backups", "log-max-age", "log-compress", "log-caller", "log-stacktrace"} {
+		viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name))
+	}
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -73,14 +247,17 @@ var rootCmd = &cobra.Command{
 	Long: `k8s-mcp-server 是一个用于 Kubernetes 集群管理的 MCP 服务器。
 它通过 HTTP/SSE 提供对 Kubernetes 资源的只读访问，并支持 Token 认证。`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Errors from here on are about the configuration, not the command line usage
+		// 此后的错误与配置有关，而不是命令行用法
+		cmd.SilenceUsage = true
+		if err := loadConfigFile(); err != nil {
+			return err
+		}
+
 		// 初始化日志系统
 		// Server 端默认启用日志文件输出
-		// 从 viper 获取 log-to-file 标志的值，如果没有设置则默认为 true
-		logToFile := true
-		if cmd.Flags().Changed("log-to-file") {
-			logToFile = viper.GetBool("log-to-file")
-		}
-		logger.AdjustOutputPaths(logConfig, logToFile)
+		applyLoggingConfig()
+		logger.AdjustOutputPaths(logConfig, logToFile(cmd))
 		if err := logger.Init(logConfig); err != nil {
 			return fmt.Errorf("failed to initialize logger: %w", err)
 		}
@@ -103,40 +280,213 @@ func executeServer() {
 	// 获取 logger 实例
 	log := logger.Get()
 
-	// Read configuration from viper (flags override env vars)
-	// 从 viper 读取配置（标志覆盖环境变量）
+	// Read configuration from viper (flags > env vars > config file > defaults)
+	// 从 viper 读取配置（标志 > 环境变量 > 配置文件 > 默认值）
 	port := viper.GetString("port")
 	certPath := viper.GetString("cert")
 	keyPath := viper.GetString("key")
+	tlsMinVersion := viper.GetString("tls-min-version")
+	tlsCipherSuites := viper.GetStringSlice("tls-cipher-suites")
 	insecure := viper.GetBool("insecure")
 	authToken := viper.GetString("token")
+	clientCA := viper.GetString("client-ca")
+	oidcIssuerURL := viper.GetString("oidc-issuer-url")
 	configPath := viper.GetString("kubeconfig")
+	configDir := viper.GetString("kubeconfig-dir")
+	enableSubscriptions := viper.GetBool("enable-subscriptions")
+	pageSize := viper.GetInt("page-size")
+	maxResultBytes := viper.GetInt("max-result-bytes")
+	auditLogPath := viper.GetString("audit-log")
+	stateFile := viper.GetString("state-file")
+	historySize := viper.GetInt("history-size")
+	strictArgs := viper.GetBool("strict-args")
+	language, _ := mcp.ParseLanguage(viper.GetString("language"))
+	logPayloads := viper.GetBool("log-payloads")
+	payloadLogLimit := viper.GetInt("log-payload-limit")
+	k8sQPS := viper.GetFloat64("k8s-qps")
+	k8sBurst := viper.GetInt("k8s-burst")
+	k8sClientConfig := viper.GetString("k8s-client-config")
+	allowedNamespaces := viper.GetString("allowed-namespaces")
+	allowClusterScope := viper.GetBool("allow-cluster-scope")
+	impersonateUser := viper.GetString("impersonate-user")
+	impersonateGroups := viper.GetStringSlice("impersonate-group")
+	tokenIdentities := viper.GetString("token-identities")
+	allowExec := viper.GetBool("allow-exec")
+	allowWrite := viper.GetBool("allow-write")
+	allowKubeconfigExport := viper.GetBool("allow-kubeconfig-export")
+	var protectedNamespaces []string
+	if value := viper.GetString("protected-namespaces"); value != "" {
+		protectedNamespaces = strings.Split(value, ",")
+	}
+	var copyAllowedPaths []string
+	if value := viper.GetString("copy-allowed-paths"); value != "" {
+		copyAllowedPaths = strings.Split(value, ",")
+	}
+	eagerConnect := viper.GetBool("eager-connect")
+	warmUp := viper.GetBool("warm-up")
+	mockCluster := viper.GetBool("mock")
+	mockData := viper.GetString("mock-data")
 
 	// Validate required parameters
 	// 验证必需参数
-	if authToken == "" {
-		log.Error("--token is required")
+	if err := validateSettings(); err != nil {
+		log.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	serverOpts := &mcp.Options{
+		EnableSubscriptions:   enableSubscriptions,
+		ToolsPageSize:         pageSize,
+		MaxResultBytes:        maxResultBytes,
+		Logger:                log,
+		K8sClient:             k8s.ClientSettings{QPS: float32(k8sQPS), Burst: k8sBurst},
+		Impersonate:           rest.ImpersonationConfig{UserName: impersonateUser, Groups: impersonateGroups},
+		AllowExec:             allowExec,
+		AllowWrite:            allowWrite,
+		AllowKubeconfigExport: allowKubeconfigExport,
+		ProtectedNamespaces:   protectedNamespaces,
+		CopyAllowedPaths:      copyAllowedPaths,
+		EagerConnect:          eagerConnect,
+		StateFile:             stateFile,
+		HistorySize:           historySize,
+		LenientArgs:           !strictArgs,
+		Language:              language,
+		LogPayloads:           logPayloads,
+		PayloadLogLimit:       payloadLogLimit,
+	}
+	if allowExec {
+		log.Info("Exec tools enabled")
+	}
+	if allowWrite {
+		log.Info("Write tools enabled")
+	}
+	if allowKubeconfigExport {
+		log.Info("Kubeconfig export enabled")
+	}
+	if impersonateUser != "" {
+		log.Info("Impersonating Kubernetes identity", "user", impersonateUser, "groups", impersonateGroups)
+	}
+
+	// TLS version and cipher suites of the HTTPS listener
+	// HTTPS 监听器的 TLS 版本和密码套件
+	tlsConfig, err := mcp.NewTLSConfig(tlsMinVersion, tlsCipherSuites)
+	if err != nil {
+		log.Error("Invalid TLS settings", "error", err)
 		os.Exit(1)
 	}
 
-	if !insecure && (certPath == "" || keyPath == "") {
-		log.Error("--cert and --key are required for HTTPS mode (default). Use --insecure for HTTP mode.")
+	// Client certificates signed by the CA authenticate on their own
+	// 由该 CA 签发的客户端证书本身即可完成认证
+	if clientCA != "" {
+		clientCertConfig, err := mcp.NewClientCertTLSConfig(clientCA)
+		if err != nil {
+			log.Error("Failed to load client CA", "error", err)
+			os.Exit(1)
+		}
+		tlsConfig.ClientAuth = clientCertConfig.ClientAuth
+		tlsConfig.ClientCAs = clientCertConfig.ClientCAs
+		serverOpts.ClientCertAuth = true
+		log.Info("Client certificate authentication enabled", "client_ca", clientCA)
+	}
+
+	// JWTs of the OIDC issuer act as the user and groups in their claims
+	// OIDC 提供方签发的 JWT 以其声明中的用户和组访问集群
+	if oidcIssuerURL != "" {
+		serverOpts.OIDC = &mcp.OIDCConfig{
+			IssuerURL:     oidcIssuerURL,
+			ClientID:      viper.GetString("oidc-client-id"),
+			UsernameClaim: viper.GetString("oidc-username-claim"),
+			GroupsClaim:   viper.GetString("oidc-groups-claim"),
+		}
+		log.Info("OIDC authentication enabled", "issuer", oidcIssuerURL, "client_id", serverOpts.OIDC.ClientID)
+	}
+
+	// Every tool call is authorized by the webhook before it runs
+	// 每次工具调用在执行前由 webhook 授权
+	if authzWebhookURL := viper.GetString("authz-webhook-url"); authzWebhookURL != "" {
+		serverOpts.AuthzWebhook = &mcp.AuthzWebhookConfig{
+			URL:      authzWebhookURL,
+			Timeout:  viper.GetDuration("authz-webhook-timeout"),
+			CacheTTL: viper.GetDuration("authz-webhook-cache-ttl"),
+			FailOpen: viper.GetBool("authz-webhook-fail-open"),
+		}
+		log.Info("Authorization webhook enabled", "url", authzWebhookURL, "fail_open", serverOpts.AuthzWebhook.FailOpen)
+	}
+
+	// Extra tokens, each acting as its own Kubernetes identity
+	// 额外的 token，每个 token 以各自的 Kubernetes 身份访问集群
+	if tokenIdentities != "" {
+		identities, err := mcp.LoadTokenIdentities(tokenIdentities)
+		if err != nil {
+			log.Error("Failed to load token identities", "error", err)
+			os.Exit(1)
+		}
+		serverOpts.TokenIdentities = identities
+		log.Info("Token identities loaded", "path", tokenIdentities, "count", len(identities))
+	}
+
+	// Per-cluster rate limit overrides
+	// 按集群覆盖的限流配置
+	if k8sClientConfig != "" {
+		clusterClients, err := k8s.LoadClusterClientSettings(k8sClientConfig)
+		if err != nil {
+			log.Error("Failed to load k8s client config", "error", err)
+			os.Exit(1)
+		}
+		serverOpts.K8sClusterClients = clusterClients
+	}
+
+	// Namespace-scoped mode restricts every Kubernetes request to the allowed namespaces
+	// 命名空间受限模式将所有 Kubernetes 请求限制在允许的命名空间内
+	namespacePolicy, err := k8s.ParseNamespacePolicy(allowedNamespaces, allowClusterScope)
+	if err != nil {
+		log.Error("Invalid --allowed-namespaces", "error", err)
 		os.Exit(1)
 	}
+	if namespacePolicy.Restricted() {
+		serverOpts.NamespacePolicy = namespacePolicy
+		log.Info("Namespace-scoped mode enabled", "allowed_namespaces", namespacePolicy.Patterns(), "allow_cluster_scope", allowClusterScope)
+	}
+
+	// Audit log goes to its own file, reusing the log rotation settings
+	// 审计日志写入独立文件，复用日志轮转配置
+	if auditLogPath != "" {
+		auditWriter := logger.NewRotatingWriter(auditLogPath, logConfig.RotationConfig)
+		defer auditWriter.Close()
+		serverOpts.AuditLog = auditWriter
+		log.Info("Audit logging enabled", "path", auditLogPath)
+	}
 
 	// Create MCP server
 	// 创建 MCP 服务器
-	server := mcp.NewServer(authToken)
+	server := mcp.NewServer(authToken, serverOpts)
 
-	// Register tools
-	// 注册工具
+	// Register tools, resources and prompts
+	// 注册工具、资源和 prompt
 	server.RegisterTools()
+	server.RegisterResources()
+	server.RegisterPrompts()
 
-	// Load kubeconfig if provided or use default
-	// 加载 kubeconfig（如果提供）或使用默认值
-	if err := server.LoadKubeConfig(configPath); err != nil {
+	// The mock cluster replaces the kubeconfig clusters
+	// 模拟集群代替 kubeconfig 中的集群
+	if mockCluster {
+		if err := server.LoadMockCluster(mockData); err != nil {
+			log.Error("Failed to load mock cluster", "error", err)
+			os.Exit(1)
+		}
+		log.Warn("Mock mode: serving in-memory fixtures, no real cluster is contacted")
+		go server.ProbeClusters(context.Background())
+	} else if err := server.LoadKubeConfigs(configPath, configDir); err != nil {
 		log.Warn("Failed to load kubeconfig", "error", err)
 		log.Info("Server will start but won't be able to connect to clusters until kubeconfig is properly configured")
+	} else if eagerConnect {
+		// Check the reachability of every cluster in the background
+		// 在后台检查所有集群的可达性
+		go server.ProbeClusters(context.Background())
+	} else if warmUp {
+		// Only the current cluster's client is built ahead of the first call
+		// 只提前创建当前集群的客户端
+		go server.WarmUpCurrentCluster(context.Background())
 	}
 
 	// Create HTTP handler with authentication
@@ -154,10 +504,37 @@ func executeServer() {
 			os.Exit(1)
 		}
 	} else {
-		log.Info("Running in SECURE HTTPS mode")
-		if err := http.ListenAndServeTLS(addr, certPath, keyPath, handler); err != nil {
+		log.Info("Running in SECURE HTTPS mode", "tls_min_version", tlsMinVersion)
+
+		// Rotated certificates are picked up on SIGHUP or when the files change
+		// 收到 SIGHUP 或文件变化时加载轮换后的证书
+		certReloader, err := mcp.NewCertReloader(certPath, keyPath, log)
+		if err != nil {
+			log.Error("Failed to load TLS certificate", "error", err)
+			os.Exit(1)
+		}
+		tlsConfig.GetCertificate = certReloader.GetCertificate
+		go certReloader.Watch(context.Background(), certReloadInterval)
+		go reloadCertOnSIGHUP(certReloader, log)
+
+		httpServer := &http.Server{Addr: addr, Handler: handler, TLSConfig: tlsConfig}
+		if err := httpServer.ListenAndServeTLS("", ""); err != nil {
 			log.Error("Server error", "error", err)
 			os.Exit(1)
 		}
 	}
 }
+
+// reloadCertOnSIGHUP reloads the TLS certificate every time the process receives SIGHUP
+// reloadCertOnSIGHUP 每次进程收到 SIGHUP 时重新加载 TLS 证书
+func reloadCertOnSIGHUP(certReloader *mcp.CertReloader, log logger.Logger) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	for range hangups {
+		if err := certReloader.Reload(); err != nil {
+			log.Warn("Failed to reload TLS certificate, keeping the current one", "error", err)
+			continue
+		}
+		log.Info("TLS certificate reloaded on SIGHUP")
+	}
+}
@@ -0,0 +1,30 @@
+package k8s
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// now returns the current time; tests replace it to pin the clock
+// now 返回当前时间，测试中可替换以固定时钟
+var now = time.Now
+
+// formatAge renders the time elapsed since t the way kubectl does (e.g. 45s, 12m, 3h, 5d, 2y)
+// formatAge 以 kubectl 的方式显示距 t 经过的时间（例如 45s、12m、3h、5d、2y）
+func formatAge(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now().Sub(t.Time))
+}
+
+// formatTimestamp renders t as an RFC3339 UTC timestamp, or "" if unset
+// formatTimestamp 将 t 格式化为 RFC3339 UTC 时间戳，未设置时返回空字符串
+func formatTimestamp(t metav1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
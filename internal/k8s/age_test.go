@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestFormatAge 使用固定时钟测试 kubectl 风格的时长显示
+func TestFormatAge(t *testing.T) {
+	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return fixed }
+
+	tests := []struct {
+		name string
+		ago  time.Duration
+		want string
+	}{
+		{"sub-minute", 45 * time.Second, "45s"},
+		{"under two minutes", 119 * time.Second, "119s"},
+		{"minutes and seconds", 5*time.Minute + 30*time.Second, "5m30s"},
+		{"minutes", 12 * time.Minute, "12m"},
+		{"hours and minutes", 3*time.Hour + 20*time.Minute, "3h20m"},
+		{"hours", 30 * time.Hour, "30h"},
+		{"days and hours", 5*24*time.Hour + 2*time.Hour, "5d2h"},
+		{"days", 200 * 24 * time.Hour, "200d"},
+		{"just under two years", (2*365 - 1) * 24 * time.Hour, "729d"},
+		{"years and days", (2*365 + 10) * 24 * time.Hour, "2y10d"},
+		{"years", 9 * 365 * 24 * time.Hour, "9y"},
+		{"clock skew", -500 * time.Millisecond, "0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAge(metav1.NewTime(fixed.Add(-tt.ago))); got != tt.want {
+				t.Errorf("formatAge(%v ago) = %q, want %q", tt.ago, got, tt.want)
+			}
+		})
+	}
+
+	if got := formatAge(metav1.Time{}); got != "<unknown>" {
+		t.Errorf("expected <unknown> for zero time, got %q", got)
+	}
+}
+
+// TestFormatTimestamp 测试原始时间戳以 RFC3339 UTC 输出
+func TestFormatTimestamp(t *testing.T) {
+	ts := metav1.NewTime(time.Date(2024, 5, 2, 17, 13, 44, 0, time.FixedZone("CST", 8*3600)))
+	if got := formatTimestamp(ts); got != "2024-05-02T09:13:44Z" {
+		t.Errorf("unexpected timestamp %q", got)
+	}
+	if got := formatTimestamp(metav1.Time{}); got != "" {
+		t.Errorf("expected empty string for zero time, got %q", got)
+	}
+}
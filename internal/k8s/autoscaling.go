@@ -0,0 +1,247 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ListHorizontalPodAutoscalers lists horizontal pod autoscalers (autoscaling/v2) in a namespace
+// ListHorizontalPodAutoscalers 列出命名空间中的 HorizontalPodAutoscaler（autoscaling/v2）
+func (ro *ResourceOperations) ListHorizontalPodAutoscalers(ctx context.Context, namespace, clusterName string) ([]types.HorizontalPodAutoscaler, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.HorizontalPodAutoscaler, error) {
+			return ro.ListHorizontalPodAutoscalers(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hpas, err := client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontalpodautoscalers: %w", err)
+	}
+
+	var results []types.HorizontalPodAutoscaler
+	for _, hpa := range hpas.Items {
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+
+		var metrics []types.HPAMetric
+		for i, spec := range hpa.Spec.Metrics {
+			var status *autoscalingv2.MetricStatus
+			if i < len(hpa.Status.CurrentMetrics) && hpa.Status.CurrentMetrics[i].Type == spec.Type {
+				status = &hpa.Status.CurrentMetrics[i]
+			}
+			metrics = append(metrics, formatHPAMetric(spec, status))
+		}
+
+		var conditions []types.HPACondition
+		for _, condition := range hpa.Status.Conditions {
+			conditions = append(conditions, types.HPACondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
+
+		results = append(results, types.HorizontalPodAutoscaler{
+			Name:            hpa.Name,
+			Namespace:       hpa.Namespace,
+			Target:          hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+			MinReplicas:     minReplicas,
+			MaxReplicas:     hpa.Spec.MaxReplicas,
+			CurrentReplicas: hpa.Status.CurrentReplicas,
+			DesiredReplicas: hpa.Status.DesiredReplicas,
+			Metrics:         metrics,
+			Conditions:      conditions,
+			Age:             formatAge(hpa.CreationTimestamp),
+			CreatedAt:       formatTimestamp(hpa.CreationTimestamp),
+			Labels:          hpa.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// formatHPAMetric describes a metric of an autoscaler with its current value (from
+// status, if reported yet) and target
+// formatHPAMetric 描述自动扩缩容器的一个指标及其当前值（来自 status，尚未上报时为 <unknown>）和目标值
+func formatHPAMetric(spec autoscalingv2.MetricSpec, status *autoscalingv2.MetricStatus) types.HPAMetric {
+	metric := types.HPAMetric{Type: string(spec.Type), Current: "<unknown>"}
+
+	var target autoscalingv2.MetricTarget
+	var current *autoscalingv2.MetricValueStatus
+	switch spec.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if spec.Resource != nil {
+			metric.Name = string(spec.Resource.Name)
+			target = spec.Resource.Target
+		}
+		if status != nil && status.Resource != nil {
+			current = &status.Resource.Current
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if spec.ContainerResource != nil {
+			metric.Name = spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name)
+			target = spec.ContainerResource.Target
+		}
+		if status != nil && status.ContainerResource != nil {
+			current = &status.ContainerResource.Current
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if spec.Pods != nil {
+			metric.Name = spec.Pods.Metric.Name
+			target = spec.Pods.Target
+		}
+		if status != nil && status.Pods != nil {
+			current = &status.Pods.Current
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if spec.Object != nil {
+			metric.Name = spec.Object.DescribedObject.Kind + "/" + spec.Object.DescribedObject.Name + " " + spec.Object.Metric.Name
+			target = spec.Object.Target
+		}
+		if status != nil && status.Object != nil {
+			current = &status.Object.Current
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if spec.External != nil {
+			metric.Name = spec.External.Metric.Name
+			target = spec.External.Target
+		}
+		if status != nil && status.External != nil {
+			current = &status.External.Current
+		}
+	}
+
+	metric.Target = formatMetricTarget(target)
+	if current != nil {
+		metric.Current = formatMetricValue(*current, target.Type)
+	}
+	return metric
+}
+
+// formatMetricTarget formats a metric target the way kubectl does: "80%" for
+// utilization, "500m (avg)" for an average value and "30" for a value
+// formatMetricTarget 以 kubectl 的方式格式化指标目标值：利用率为 "80%"，平均值为 "500m (avg)"，数值为 "30"
+func formatMetricTarget(target autoscalingv2.MetricTarget) string {
+	switch target.Type {
+	case autoscalingv2.UtilizationMetricType:
+		if target.AverageUtilization != nil {
+			return fmt.Sprintf("%d%%", *target.AverageUtilization)
+		}
+	case autoscalingv2.AverageValueMetricType:
+		if target.AverageValue != nil {
+			return target.AverageValue.String() + " (avg)"
+		}
+	case autoscalingv2.ValueMetricType:
+		if target.Value != nil {
+			return target.Value.String()
+		}
+	}
+	return "<unknown>"
+}
+
+// formatMetricValue formats a current metric value in the same unit as its target
+// formatMetricValue 以与目标值相同的单位格式化当前指标值
+func formatMetricValue(value autoscalingv2.MetricValueStatus, targetType autoscalingv2.MetricTargetType) string {
+	switch {
+	case targetType == autoscalingv2.UtilizationMetricType && value.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *value.AverageUtilization)
+	case targetType == autoscalingv2.AverageValueMetricType && value.AverageValue != nil:
+		return value.AverageValue.String() + " (avg)"
+	case value.Value != nil:
+		return value.Value.String()
+	case value.AverageValue != nil:
+		return value.AverageValue.String() + " (avg)"
+	}
+	return "<unknown>"
+}
+
+// formatHPAMetrics joins the metrics as "cpu: 45%/80%, queue: 30/50"
+// formatHPAMetrics 将指标拼接为 "cpu: 45%/80%, queue: 30/50"
+func formatHPAMetrics(metrics []types.HPAMetric) string {
+	if len(metrics) == 0 {
+		return "<none>"
+	}
+	parts := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		parts = append(parts, fmt.Sprintf("%s: %s/%s", m.Name, m.Current, m.Target))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ListPodDisruptionBudgets lists pod disruption budgets in a namespace
+// ListPodDisruptionBudgets 列出命名空间中的 PodDisruptionBudget
+func (ro *ResourceOperations) ListPodDisruptionBudgets(ctx context.Context, namespace, clusterName string) ([]types.PodDisruptionBudget, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.PodDisruptionBudget, error) {
+			return ro.ListPodDisruptionBudgets(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list poddisruptionbudgets: %w", err)
+	}
+
+	var results []types.PodDisruptionBudget
+	for _, pdb := range pdbs.Items {
+		results = append(results, types.PodDisruptionBudget{
+			Name:               pdb.Name,
+			Namespace:          pdb.Namespace,
+			MinAvailable:       formatIntOrString(pdb.Spec.MinAvailable),
+			MaxUnavailable:     formatIntOrString(pdb.Spec.MaxUnavailable),
+			AllowedDisruptions: pdb.Status.DisruptionsAllowed,
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			ExpectedPods:       pdb.Status.ExpectedPods,
+			Age:                formatAge(pdb.CreationTimestamp),
+			CreatedAt:          formatTimestamp(pdb.CreationTimestamp),
+			Labels:             pdb.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// formatIntOrString formats an optional count or percentage, "N/A" if unset like kubectl
+// formatIntOrString 格式化可选的数量或百分比，未设置时与 kubectl 一样返回 "N/A"
+func formatIntOrString(value *intstr.IntOrString) string {
+	if value == nil {
+		return "N/A"
+	}
+	return value.String()
+}
@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// fakeAutoscalingAPIServer 模拟 HPA 和 PDB 的列表接口
+func fakeAutoscalingAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	minReplicas := int32(2)
+	utilization := int32(80)
+	currentUtilization := int32(45)
+	queueTarget := resource.MustParse("30")
+	queueCurrent := resource.MustParse("42")
+	minAvailable := intstr.FromInt(2)
+	maxUnavailable := intstr.FromString("25%")
+
+	responses := map[string]interface{}{
+		"/apis/autoscaling/v2/namespaces/shop/horizontalpodautoscalers": autoscalingv2.HorizontalPodAutoscalerList{Items: []autoscalingv2.HorizontalPodAutoscaler{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+					MinReplicas:    &minReplicas,
+					MaxReplicas:    10,
+					Metrics: []autoscalingv2.MetricSpec{
+						{Type: autoscalingv2.ResourceMetricSourceType, Resource: &autoscalingv2.ResourceMetricSource{
+							Name:   corev1.ResourceCPU,
+							Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &utilization},
+						}},
+						{Type: autoscalingv2.ExternalMetricSourceType, External: &autoscalingv2.ExternalMetricSource{
+							Metric: autoscalingv2.MetricIdentifier{Name: "queue_length"},
+							Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &queueTarget},
+						}},
+					},
+				},
+				Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+					CurrentReplicas: 10,
+					DesiredReplicas: 10,
+					CurrentMetrics: []autoscalingv2.MetricStatus{
+						{Type: autoscalingv2.ResourceMetricSourceType, Resource: &autoscalingv2.ResourceMetricStatus{
+							Name:    corev1.ResourceCPU,
+							Current: autoscalingv2.MetricValueStatus{AverageUtilization: &currentUtilization},
+						}},
+						{Type: autoscalingv2.ExternalMetricSourceType, External: &autoscalingv2.ExternalMetricStatus{
+							Metric:  autoscalingv2.MetricIdentifier{Name: "queue_length"},
+							Current: autoscalingv2.MetricValueStatus{AverageValue: &queueCurrent},
+						}},
+					},
+					Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+						{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionTrue, Reason: "TooManyReplicas", Message: "the desired replica count is more than the maximum replica count"},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "worker"},
+					MaxReplicas:    5,
+					Metrics: []autoscalingv2.MetricSpec{
+						{Type: autoscalingv2.ExternalMetricSourceType, External: &autoscalingv2.ExternalMetricSource{
+							Metric: autoscalingv2.MetricIdentifier{Name: "queue_length"},
+							Target: autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: &queueTarget},
+						}},
+					},
+				},
+			},
+		}},
+		"/apis/policy/v1/namespaces/shop/poddisruptionbudgets": policyv1.PodDisruptionBudgetList{Items: []policyv1.PodDisruptionBudget{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1, CurrentHealthy: 3, DesiredHealthy: 2, ExpectedPods: 3},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+				Status:     policyv1.PodDisruptionBudgetStatus{CurrentHealthy: 2, DesiredHealthy: 3, ExpectedPods: 4},
+			},
+		}},
+	}
+
+	return fakeAPIServer(t, responses)
+}
+
+// TestListHorizontalPodAutoscalers 测试 Resource 和 External 指标的当前值与目标值以及 HPA 状况
+func TestListHorizontalPodAutoscalers(t *testing.T) {
+	ro := newServerOperations(t, fakeAutoscalingAPIServer(t))
+
+	hpas, err := ro.ListHorizontalPodAutoscalers(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("ListHorizontalPodAutoscalers failed: %v", err)
+	}
+	if len(hpas) != 2 {
+		t.Fatalf("expected 2 autoscalers, got %+v", hpas)
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		min, max    int32
+		metrics     []types.HPAMetric
+		status      string
+		conditioned bool
+	}{
+		{
+			name:   "web",
+			target: "Deployment/web",
+			min:    2,
+			max:    10,
+			metrics: []types.HPAMetric{
+				{Type: "Resource", Name: "cpu", Current: "45%", Target: "80%"},
+				{Type: "External", Name: "queue_length", Current: "42 (avg)", Target: "30 (avg)"},
+			},
+			status:      "Target: Deployment/web, Replicas: 10/10 (min 2, max 10), Metrics: cpu: 45%/80%, queue_length: 42 (avg)/30 (avg)",
+			conditioned: true,
+		},
+		{
+			name:   "worker",
+			target: "StatefulSet/worker",
+			min:    1,
+			max:    5,
+			metrics: []types.HPAMetric{
+				{Type: "External", Name: "queue_length", Current: "<unknown>", Target: "30"},
+			},
+			status: "Target: StatefulSet/worker, Replicas: 0/0 (min 1, max 5), Metrics: queue_length: <unknown>/30",
+		},
+	}
+
+	infos, err := ToResourceInfos(hpas)
+	if err != nil {
+		t.Fatalf("ToResourceInfos failed: %v", err)
+	}
+	for i, tt := range tests {
+		hpa := hpas[i]
+		if hpa.Name != tt.name || hpa.Target != tt.target || hpa.MinReplicas != tt.min || hpa.MaxReplicas != tt.max {
+			t.Errorf("%s: unexpected autoscaler %+v", tt.name, hpa)
+		}
+		if !reflect.DeepEqual(hpa.Metrics, tt.metrics) {
+			t.Errorf("%s: metrics = %+v, want %+v", tt.name, hpa.Metrics, tt.metrics)
+		}
+		if infos[i].Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", tt.name, infos[i].Status, tt.status)
+		}
+		if tt.conditioned && (len(hpa.Conditions) != 1 || hpa.Conditions[0].Type != "ScalingLimited" || hpa.Conditions[0].Reason != "TooManyReplicas") {
+			t.Errorf("%s: unexpected conditions %+v", tt.name, hpa.Conditions)
+		}
+	}
+}
+
+// TestListPodDisruptionBudgets 测试 minAvailable/maxUnavailable 和允许的中断数
+func TestListPodDisruptionBudgets(t *testing.T) {
+	ro := newServerOperations(t, fakeAutoscalingAPIServer(t))
+
+	pdbs, err := ro.ListPodDisruptionBudgets(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("ListPodDisruptionBudgets failed: %v", err)
+	}
+
+	tests := []struct {
+		minAvailable, maxUnavailable string
+		allowed                      int32
+		status                       string
+	}{
+		{"2", "N/A", 1, "MinAvailable: 2, MaxUnavailable: N/A, Allowed disruptions: 1"},
+		{"N/A", "25%", 0, "MinAvailable: N/A, MaxUnavailable: 25%, Allowed disruptions: 0"},
+	}
+	if len(pdbs) != len(tests) {
+		t.Fatalf("expected %d budgets, got %+v", len(tests), pdbs)
+	}
+	infos, err := ToResourceInfos(pdbs)
+	if err != nil {
+		t.Fatalf("ToResourceInfos failed: %v", err)
+	}
+	for i, tt := range tests {
+		pdb := pdbs[i]
+		if pdb.MinAvailable != tt.minAvailable || pdb.MaxUnavailable != tt.maxUnavailable || pdb.AllowedDisruptions != tt.allowed {
+			t.Errorf("%s: unexpected budget %+v", pdb.Name, pdb)
+		}
+		if infos[i].Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", pdb.Name, infos[i].Status, tt.status)
+		}
+	}
+}
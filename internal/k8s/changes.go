@@ -0,0 +1,272 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxChangeEvents is the number of most recent events about the object GetChangeInfo keeps
+// maxChangeEvents 为 GetChangeInfo 保留的对象最近事件数
+const maxChangeEvents = 10
+
+// plainFieldName matches field names that can be written after a dot in a path
+// plainFieldName 匹配可以直接写在路径中点号之后的字段名
+var plainFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// GetChangeInfo reports who changed a resource and when: the field managers of
+// metadata.managedFields with the fields each owns as readable paths, newest first, whether
+// the last-applied-configuration annotation of 'kubectl apply' is present, and the most
+// recent events about the object. A failed event list is reported in EventsError.
+// GetChangeInfo 报告资源由谁在何时修改：metadata.managedFields 中的各字段管理者 (按时间从新到旧) 及其以可读路径表示的字段、
+// 是否存在 'kubectl apply' 的 last-applied-configuration 注解，以及与该对象相关的最近事件。事件列表失败时记录在 EventsError 中
+func (ro *ResourceOperations) GetChangeInfo(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string) (*types.ChangeInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if IsClusterScoped(resourceType) {
+		namespace = ""
+	}
+
+	// GetResourceDetails returns the object as the API server sent it, managedFields included
+	// GetResourceDetails 按 API server 返回的原样给出对象，包括 managedFields
+	resource, err := ro.GetResourceDetails(ctx, resourceType, namespace, name, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", resourceType, name, err)
+	}
+	obj, ok := resource.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+	setTypeMeta(obj)
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of %s %s: %w", resourceType, name, err)
+	}
+
+	info := &types.ChangeInfo{
+		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: accessor.GetNamespace(),
+		Name:      accessor.GetName(),
+		Managers:  fieldManagers(accessor.GetManagedFields()),
+		Events:    []types.Event{},
+	}
+	_, info.LastApplied = accessor.GetAnnotations()[lastAppliedAnnotation]
+	if len(info.Managers) > 0 {
+		info.LastChangedBy = info.Managers[0].Manager
+		info.LastChangedAt = info.Managers[0].Time
+	}
+
+	var client kubernetes.Interface
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err == nil {
+		filter := EventFilter{Kind: info.Kind, Name: info.Name}
+		var events *corev1.EventList
+		events, err = client.CoreV1().Events(info.Namespace).List(ctx, metav1.ListOptions{FieldSelector: filter.fieldSelector()})
+		if err == nil {
+			info.Events = recentObjectEvents(events.Items, info.Kind, info.Name, string(accessor.GetUID()))
+		}
+	}
+	if err != nil {
+		info.EventsError = fmt.Sprintf("failed to list events: %v", err)
+	}
+	return info, nil
+}
+
+// recentObjectEvents returns the last maxChangeEvents events about the object, oldest
+// first. Events recorded for an earlier object with the same name (another UID) are dropped.
+// recentObjectEvents 返回与对象相关的最近 maxChangeEvents 个事件，按时间从早到晚排列。同名的旧对象 (UID 不同) 的事件被丢弃
+func recentObjectEvents(events []corev1.Event, kind, name, uid string) []types.Event {
+	var matched []corev1.Event
+	for _, event := range events {
+		involved := event.InvolvedObject
+		if involved.Kind != kind || involved.Name != name {
+			continue
+		}
+		if uid != "" && involved.UID != "" && string(involved.UID) != uid {
+			continue
+		}
+		matched = append(matched, event)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return eventLastSeen(matched[i]).Time.Before(eventLastSeen(matched[j]).Time)
+	})
+	if len(matched) > maxChangeEvents {
+		matched = matched[len(matched)-maxChangeEvents:]
+	}
+	results := make([]types.Event, 0, len(matched))
+	for _, event := range matched {
+		results = append(results, toEvent(event))
+	}
+	return results
+}
+
+// fieldManagers converts managedFields entries, newest first
+// fieldManagers 转换 managedFields 条目，按时间从新到旧排列
+func fieldManagers(entries []metav1.ManagedFieldsEntry) []types.FieldManager {
+	sorted := make([]metav1.ManagedFieldsEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Time, sorted[j].Time
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.After(b.Time)
+	})
+
+	managers := make([]types.FieldManager, 0, len(sorted))
+	for _, entry := range sorted {
+		manager := types.FieldManager{
+			Manager:     entry.Manager,
+			Operation:   string(entry.Operation),
+			Subresource: entry.Subresource,
+			APIVersion:  entry.APIVersion,
+			Fields:      []string{},
+		}
+		if entry.Time != nil {
+			manager.Time = formatTimestamp(*entry.Time)
+		}
+		if entry.FieldsV1 != nil {
+			fields, err := FieldsV1Paths(entry.FieldsV1.Raw)
+			if err != nil {
+				fields = []string{fmt.Sprintf("<undecodable fieldsV1: %v>", err)}
+			}
+			manager.Fields = fields
+		}
+		managers = append(managers, manager)
+	}
+	return managers
+}
+
+// FieldsV1Paths decodes a fieldsV1 set into readable field paths, in document order.
+// A path is reported for every field the manager owns without owning fields below it:
+// "f:<name>" becomes ".name" (or ["name"] when the name has dots or slashes, like label
+// keys), "k:{...}" selects a list item by its keys as [key=value,...], "v:<value>" a set
+// item by value as [=value] and "i:<n>" an item by index as [n]. The "." marker, which
+// means the object itself is owned, only produces a path when nothing below it is owned.
+// FieldsV1Paths 将 fieldsV1 集合解码为可读的字段路径，按文档顺序排列。管理者拥有、且其下没有更深字段的每个字段产生一条路径：
+// "f:<name>" 写作 ".name" (名称包含点号或斜杠时写作 ["name"]，如标签键)，"k:{...}" 按键选择列表元素，写作 [key=value,...]，
+// "v:<value>" 按值选择集合元素，写作 [=value]，"i:<n>" 按下标选择元素，写作 [n]。
+// 表示对象本身被拥有的 "." 标记只在其下没有其他字段时才产生路径
+func FieldsV1Paths(raw []byte) ([]string, error) {
+	paths := []string{}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return paths, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := walkFieldsV1(decoder, "", &paths); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// walkFieldsV1 reads one fieldsV1 object from decoder, appending the paths below prefix.
+// The object is read token by token to keep the keys in document order.
+// walkFieldsV1 从 decoder 读取一个 fieldsV1 对象，追加 prefix 之下的路径。逐个读取 token 以保持键的文档顺序
+func walkFieldsV1(decoder *json.Decoder, prefix string, paths *[]string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected an object at %q, got %v", prefix, token)
+	}
+
+	children := 0
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		if key == "." {
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return err
+			}
+			continue
+		}
+		children++
+		if err := walkFieldsV1(decoder, prefix+fieldsV1Segment(key), paths); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	if children == 0 && prefix != "" {
+		*paths = append(*paths, strings.TrimPrefix(prefix, "."))
+	}
+	return nil
+}
+
+// fieldsV1Segment renders one fieldsV1 key as a path segment
+// fieldsV1Segment 将一个 fieldsV1 键渲染为路径片段
+func fieldsV1Segment(key string) string {
+	if len(key) < 2 || key[1] != ':' {
+		return "[" + key + "]"
+	}
+	value := key[2:]
+	switch key[0] {
+	case 'f':
+		if plainFieldName.MatchString(value) {
+			return "." + value
+		}
+		return "[" + strconv.Quote(value) + "]"
+	case 'k':
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &fields); err != nil {
+			return "[" + value + "]"
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		terms := make([]string, 0, len(names))
+		for _, name := range names {
+			terms = append(terms, name+"="+fieldsV1Value(fields[name]))
+		}
+		return "[" + strings.Join(terms, ",") + "]"
+	case 'v':
+		var item interface{}
+		if err := json.Unmarshal([]byte(value), &item); err != nil {
+			return "[=" + value + "]"
+		}
+		return "[=" + fieldsV1Value(item) + "]"
+	case 'i':
+		return "[" + value + "]"
+	}
+	return "[" + key + "]"
+}
+
+// fieldsV1Value renders a key or set value: strings unquoted, anything else as JSON
+// fieldsV1Value 渲染键值或集合元素的值：字符串不加引号，其他值使用 JSON
+func fieldsV1Value(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
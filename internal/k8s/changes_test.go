@@ -0,0 +1,155 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// loadManagedFields 读取 testdata/changes 中从真实集群采集的 managedFields
+func loadManagedFields(t *testing.T) []metav1.ManagedFieldsEntry {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "changes", "deployment.managedfields.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var entries []metav1.ManagedFieldsEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	return entries
+}
+
+// TestFieldsV1Paths 测试将 fieldsV1 解码为可读路径：按键选择的列表元素、带点号的标签键、集合元素、下标，
+// 以及 "." 只在其下没有其他字段时产生路径
+func TestFieldsV1Paths(t *testing.T) {
+	entries := loadManagedFields(t)
+	paths, err := FieldsV1Paths(entries[0].FieldsV1.Raw)
+	if err != nil {
+		t.Fatalf("FieldsV1Paths failed: %v", err)
+	}
+	want := []string{
+		`metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`,
+		`metadata.labels.app`,
+		`metadata.labels["app.kubernetes.io/part-of"]`,
+		`spec.progressDeadlineSeconds`,
+		`spec.replicas`,
+		`spec.revisionHistoryLimit`,
+		`spec.selector`,
+		`spec.strategy.rollingUpdate.maxSurge`,
+		`spec.strategy.rollingUpdate.maxUnavailable`,
+		`spec.strategy.type`,
+		`spec.template.metadata.labels.app`,
+		`spec.template.spec.containers[name=checkout].env[name=LOG_LEVEL].name`,
+		`spec.template.spec.containers[name=checkout].env[name=LOG_LEVEL].value`,
+		`spec.template.spec.containers[name=checkout].imagePullPolicy`,
+		`spec.template.spec.containers[name=checkout].name`,
+		`spec.template.spec.containers[name=checkout].ports[containerPort=8080,protocol=TCP].containerPort`,
+		`spec.template.spec.containers[name=checkout].ports[containerPort=8080,protocol=TCP].name`,
+		`spec.template.spec.containers[name=checkout].ports[containerPort=8080,protocol=TCP].protocol`,
+		`spec.template.spec.containers[name=checkout].resources.limits.memory`,
+		`spec.template.spec.containers[name=checkout].terminationMessagePath`,
+		`spec.template.spec.containers[name=checkout].terminationMessagePolicy`,
+		`spec.template.spec.dnsPolicy`,
+		`spec.template.spec.restartPolicy`,
+		`spec.template.spec.schedulerName`,
+		`spec.template.spec.securityContext`,
+		`spec.template.spec.terminationGracePeriodSeconds`,
+		`spec.template.spec.tolerations`,
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("unexpected paths:\n%q\nwant\n%q", paths, want)
+	}
+
+	paths, err = FieldsV1Paths(entries[3].FieldsV1.Raw)
+	want = []string{
+		`metadata.finalizers[=backup.example.com/protect]`,
+		`spec.template.spec.containers[name=checkout].args[0]`,
+	}
+	if err != nil || !reflect.DeepEqual(paths, want) {
+		t.Errorf("unexpected paths %q %v", paths, err)
+	}
+
+	// 只有 "." 的元素本身即为被拥有的字段
+	paths, err = FieldsV1Paths([]byte(`{"f:spec":{"f:ports":{"k:{\"port\":80}":{".":{}}}}}`))
+	if err != nil || !reflect.DeepEqual(paths, []string{"spec.ports[port=80]"}) {
+		t.Errorf("unexpected paths %q %v", paths, err)
+	}
+	if _, err := FieldsV1Paths([]byte(`{"f:spec":[]}`)); err == nil {
+		t.Errorf("expected an error for malformed fieldsV1")
+	}
+}
+
+// TestGetChangeInfo 测试字段管理者按时间从新到旧排列、last-applied 注解的检测，
+// 以及只保留与该对象 (相同 UID) 相关的事件
+func TestGetChangeInfo(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:          "checkout",
+		Namespace:     "shop",
+		UID:           "uid-2",
+		Annotations:   map[string]string{lastAppliedAnnotation: "{}"},
+		ManagedFields: loadManagedFields(t),
+	}}
+	at := func(hour int) metav1.Time { return metav1.NewTime(time.Date(2024, 5, 4, hour, 0, 0, 0, time.UTC)) }
+	event := func(name, kind, object, uid string, hour int) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object, UID: types.UID(uid)},
+			Reason:         name,
+			LastTimestamp:  at(hour),
+		}
+	}
+	client := fake.NewSimpleClientset(deployment,
+		event("ScalingReplicaSet", "Deployment", "checkout", "uid-2", 9),
+		event("Rollback", "Deployment", "checkout", "", 8),
+		event("Stale", "Deployment", "checkout", "uid-1", 7),
+		event("Other", "Deployment", "web", "uid-3", 10),
+	)
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+
+	info, err := NewResourceOperations(cm).GetChangeInfo(context.Background(), ResourceTypeDeployments, "shop", "checkout", "test")
+	if err != nil {
+		t.Fatalf("GetChangeInfo failed: %v", err)
+	}
+	if info.Kind != "Deployment" || !info.LastApplied || info.EventsError != "" {
+		t.Errorf("unexpected change info %+v", info)
+	}
+	if info.LastChangedBy != "kubectl-set" || info.LastChangedAt != "2024-05-04T08:02:17Z" {
+		t.Errorf("expected kubectl-set to be the last writer, got %s at %s", info.LastChangedBy, info.LastChangedAt)
+	}
+	var managers []string
+	for _, manager := range info.Managers {
+		managers = append(managers, manager.Manager)
+	}
+	if want := []string{"kubectl-set", "kube-controller-manager", "kubectl-client-side-apply", "backup-operator"}; !reflect.DeepEqual(managers, want) {
+		t.Errorf("managers = %v, want %v", managers, want)
+	}
+	if got := info.Managers[0].Fields; !reflect.DeepEqual(got, []string{"spec.template.spec.containers[name=checkout].image"}) {
+		t.Errorf("unexpected fields of kubectl-set %v", got)
+	}
+	if info.Managers[1].Subresource != "status" || info.Managers[3].Operation != "Apply" {
+		t.Errorf("unexpected managers %+v", info.Managers)
+	}
+	var reasons []string
+	for _, event := range info.Events {
+		reasons = append(reasons, event.Reason)
+	}
+	if want := []string{"Rollback", "ScalingReplicaSet"}; !reflect.DeepEqual(reasons, want) {
+		t.Errorf("events = %v, want %v", reasons, want)
+	}
+
+	if _, err := NewResourceOperations(cm).GetChangeInfo(context.Background(), ResourceTypeDeployments, "shop", "missing", "test"); err == nil {
+		t.Errorf("expected an error for a missing deployment")
+	}
+}
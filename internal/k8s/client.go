@@ -2,30 +2,148 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
 
 	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/openapi"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
-	"k8s.io/client-go/util/homedir"
 )
 
 // Options 定义 ClusterManager 的配置选项
 type Options struct {
 	// Logger 日志接口，如果为 nil 则使用默认的 console logger
 	Logger logger.Logger
+
+	// Client is applied to every cluster's rest.Config (zero fields keep the client-go defaults)
+	// Client 应用于每个集群的 rest.Config（零值字段保留 client-go 默认值）
+	Client ClientSettings
+
+	// ClusterClients overrides Client for individual clusters, keyed by cluster name
+	// ClusterClients 按集群名称覆盖单个集群的 Client 配置
+	ClusterClients map[string]ClientSettings
+
+	// UserAgent is sent with every API request (empty keeps the client-go default)
+	// UserAgent 随每个 API 请求发送（为空时保留 client-go 默认值）
+	UserAgent string
+
+	// NamespacePolicy restricts every API request to the allowed namespaces (nil allows all)
+	// NamespacePolicy 将所有 API 请求限制在允许的命名空间内（nil 时不限制）
+	NamespacePolicy *NamespacePolicy
+
+	// Impersonate runs every API request as this user and groups unless the request
+	// context carries its own identity (see WithImpersonation). Empty uses the kubeconfig credential.
+	// Impersonate 使所有 API 请求以该用户和组执行，请求上下文携带身份时以上下文为准（见 WithImpersonation），
+	// 为空时直接使用 kubeconfig 凭据。
+	Impersonate rest.ImpersonationConfig
+
+	// EagerConnect builds the clientset of every kubeconfig context while loading it instead
+	// of on the first call that uses the cluster
+	// EagerConnect 在加载 kubeconfig 时为每个上下文创建 clientset，而不是在首次使用集群时创建
+	EagerConnect bool
+}
+
+// ClientSettings tunes the client-side rate limiting of a cluster's API clients
+// ClientSettings 调整集群 API 客户端的客户端限流
+type ClientSettings struct {
+	QPS   float32 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// lazyClient builds the clientset of a kubeconfig cluster once, on first use. Building it
+// can run credential plugins such as aws eks get-token, so clusters that are never used cost nothing.
+// lazyClient 在首次使用时创建一次 kubeconfig 集群的 clientset。创建时可能运行 aws eks get-token 等凭据插件，
+// 因此从未使用的集群没有任何开销。
+type lazyClient struct {
+	once   sync.Once
+	config *rest.Config
+	client kubernetes.Interface
+	err    error
 }
 
 // ClusterManager manages multiple k8s clusters
 type ClusterManager struct {
-	clusters       map[string]*kubernetes.Clientset
+	clusters       map[string]kubernetes.Interface
 	configs        map[string]*rest.Config
 	currentCluster string
 	logger         logger.Logger
+
+	// defaultNamespaces holds the namespace set on each cluster's kubeconfig context
+	// defaultNamespaces 保存每个集群在 kubeconfig 上下文中设置的命名空间
+	defaultNamespaces map[string]string
+
+	clientSettings ClientSettings
+	clusterClients map[string]ClientSettings
+	userAgent      string
+
+	namespacePolicy *NamespacePolicy
+	impersonate     rest.ImpersonationConfig
+
+	// mockDynamic holds the dynamic clients of the fake clusters added by LoadMockCluster
+	// mockDynamic 保存 LoadMockCluster 添加的模拟集群的动态客户端
+	mockDynamic map[string]mockDynamicClient
+
+	// lazyClients holds the kubeconfig clusters whose clientset is not built until first use
+	// lazyClients 保存首次使用时才创建 clientset 的 kubeconfig 集群
+	lazyClients  map[string]*lazyClient
+	eagerConnect bool
+
+	// newClientset builds a clientset from a rest.Config; tests replace it to count the clients built
+	// newClientset 根据 rest.Config 创建 clientset，测试中替换它以统计创建的客户端数量
+	newClientset func(*rest.Config) (kubernetes.Interface, error)
+
+	// sources holds the kubeconfig file each cluster's context was loaded from
+	// sources 保存每个集群的上下文所在的 kubeconfig 文件
+	sources map[string]string
+
+	// kubeContexts holds, per kubeconfig context, a config with only that context and its
+	// cluster and user; clusterContexts maps each cluster to the context it was loaded from
+	// kubeContexts 按 kubeconfig 上下文保存只包含该上下文及其集群和用户的配置；clusterContexts 记录每个集群加载自哪个上下文
+	kubeContexts    map[string]*clientcmdapi.Config
+	clusterContexts map[string]string
+
+	// loadErrors holds the error of every kubeconfig cluster whose client could not be built
+	// loadErrors 保存 kubeconfig 中无法创建客户端的集群及其错误
+	loadErrors map[string]error
+
+	reachabilityMu sync.RWMutex
+	reachability   map[string]Reachability
+
+	// served caches, per cluster, whether discovery found an API resource (see ServesResource)
+	// served 按集群缓存发现接口是否找到某个 API 资源（见 ServesResource）
+	servedMu sync.Mutex
+	served   map[string]map[schema.GroupVersionResource]bool
+
+	// openAPI caches, per cluster, the discovery information and OpenAPI v3 documents read
+	// by ExplainResource; openAPIClients replaces a cluster's OpenAPI client (see SetOpenAPIClient)
+	// openAPI 按集群缓存 ExplainResource 读取的发现信息和 OpenAPI v3 文档；openAPIClients 替换集群的 OpenAPI 客户端（见 SetOpenAPIClient）
+	openAPIMu      sync.Mutex
+	openAPI        map[string]*openAPICache
+	openAPIClients map[string]openapi.Client
+
+	// discovery holds, per cluster, the memoized discovery client, REST mapper and dynamic
+	// client shared by tool calls; discoveryFetches counts the fetches that filled it
+	// discovery 按集群保存工具调用共享的带缓存发现客户端、REST 映射器和动态客户端；discoveryFetches 统计填充缓存的获取次数
+	discoveryMu      sync.Mutex
+	discovery        map[string]*clusterDiscovery
+	discoveryFetches atomic.Int64
+
+	// apiRetries counts the API requests retried after a transient failure
+	// apiRetries 统计暂时失败后重试的 API 请求次数
+	apiRetries atomic.Int64
 }
 
 // NewClusterManager creates a new cluster manager
@@ -38,51 +156,90 @@ func NewClusterManager(opts *Options) *ClusterManager {
 		log = logger.NewDefaultConsoleLogger()
 	}
 
-	return &ClusterManager{
-		clusters: make(map[string]*kubernetes.Clientset),
-		configs:  make(map[string]*rest.Config),
-		logger:   log,
+	cm := &ClusterManager{
+		clusters:          make(map[string]kubernetes.Interface),
+		configs:           make(map[string]*rest.Config),
+		lazyClients:       make(map[string]*lazyClient),
+		defaultNamespaces: make(map[string]string),
+		loadErrors:        make(map[string]error),
+		sources:           make(map[string]string),
+		kubeContexts:      make(map[string]*clientcmdapi.Config),
+		clusterContexts:   make(map[string]string),
+		reachability:      make(map[string]Reachability),
+		logger:            log,
+		newClientset: func(config *rest.Config) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(config)
+		},
+	}
+	if opts != nil {
+		cm.clientSettings = opts.Client
+		cm.clusterClients = opts.ClusterClients
+		cm.userAgent = opts.UserAgent
+		cm.namespacePolicy = opts.NamespacePolicy
+		cm.impersonate = opts.Impersonate
+		cm.eagerConnect = opts.EagerConnect
 	}
+	return cm
 }
 
-// LoadKubeConfigAndInitCluster loads kubeconfig and initializes clusters
-// LoadKubeConfigAndInitCluster 加载 kubeconfig 并初始化集群
+// LoadKubeConfigAndInitCluster loads the kubeconfig files of configPath (see LoadKubeConfigs)
+// LoadKubeConfigAndInitCluster 加载 configPath 中的 kubeconfig 文件（见 LoadKubeConfigs）
 func (cm *ClusterManager) LoadKubeConfigAndInitCluster(configPath string) error {
-	// Get the config file path
-	// 获取配置文件路径
-	configPath = cm.getKubeConfigPath(configPath)
+	return cm.LoadKubeConfigs(configPath, "")
+}
 
-	// Load the kubeconfig file
-	// 加载 kubeconfig 文件
-	config, err := clientcmd.LoadFromFile(configPath)
+// LoadKubeConfigs loads and merges the kubeconfig files of configPath and configDir (see
+// KubeConfigPaths and loadKubeConfigFiles) and initializes clusters. The clientsets
+// are built on first use unless EagerConnect is set. A context whose config or client
+// cannot be built doesn't stop the load: its cluster is recorded as unavailable with the
+// error. An error is returned only if the files can't be loaded or merged, or no cluster could be initialized.
+// LoadKubeConfigs 加载并合并 configPath 和 configDir 中的 kubeconfig 文件（见 KubeConfigPaths 和 loadKubeConfigFiles）并初始化集群。
+// 除非设置了 EagerConnect，clientset 在首次使用时才创建。单个上下文的配置或客户端创建失败不会中断加载，其集群会连同错误记录为不可用；
+// 只有文件无法加载或合并、或没有任何集群初始化成功时才返回错误。
+func (cm *ClusterManager) LoadKubeConfigs(configPath, configDir string) error {
+	paths, err := KubeConfigPaths(configPath, configDir)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
-
-	// Create clients for each cluster context
-	// 为每个集群上下文创建客户端
-	for contextName, context := range config.Contexts {
-		err := cm.addContextCluster(config, contextName, context)
-		if err != nil {
-			return err
-		}
+	config, err := loadKubeConfigFiles(paths)
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
+	cm.logger.Info("Loaded kubeconfig", "source", kubeConfigSource(configPath, configDir), "files", len(paths), "contexts", len(config.Contexts))
 
-	return nil
-}
-
-// getKubeConfigPath returns the kubeconfig path, using default if not specified
-// getKubeConfigPath 返回 kubeconfig 路径，如果未指定则使用默认值
-func (cm *ClusterManager) getKubeConfigPath(configPath string) string {
-	if configPath != "" {
-		return configPath
+	// Create clients for each cluster context, in a stable order
+	// 按固定顺序为每个集群上下文创建客户端
+	contextNames := make([]string, 0, len(config.Contexts))
+	for contextName := range config.Contexts {
+		contextNames = append(contextNames, contextName)
 	}
-
-	if home := homedir.HomeDir(); home != "" {
-		return filepath.Join(home, ".kube", "config")
+	sort.Strings(contextNames)
+
+	var loadErrs []error
+	for _, contextName := range contextNames {
+		context := config.Contexts[contextName]
+		if err := cm.addContextCluster(config, contextName, context); err != nil {
+			clusterName := context.Cluster
+			if clusterName == "" {
+				clusterName = contextName
+			}
+			cm.logger.Warn("Skipping kubeconfig context", "context", contextName, "cluster", clusterName, "error", err)
+			// Another context may already have initialized the same cluster
+			// 其他上下文可能已经成功初始化了同一集群
+			if !cm.hasCluster(clusterName) {
+				cm.loadErrors[clusterName] = err
+				if _, exists := cm.sources[clusterName]; !exists {
+					cm.sources[clusterName] = context.LocationOfOrigin
+				}
+			}
+			loadErrs = append(loadErrs, err)
+		}
 	}
 
-	return ""
+	if len(cm.GetClusters()) == 0 && len(loadErrs) > 0 {
+		return fmt.Errorf("no cluster could be initialized: %w", errors.Join(loadErrs...))
+	}
+	return nil
 }
 
 // addContextCluster adds a cluster from a kubeconfig context
@@ -100,16 +257,37 @@ func (cm *ClusterManager) addContextCluster(config *clientcmdapi.Config, context
 	if err != nil {
 		return fmt.Errorf("failed to create config for context %s: %w", contextName, err)
 	}
+	cm.applyClientSettings(clusterName, restConfig)
 
-	// Create kubernetes client
-	// 创建 kubernetes 客户端
-	clientset, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create client for context %s: %w", contextName, err)
+	// Create the kubernetes client now, or leave it to the first call that uses the cluster
+	// 立即创建 kubernetes 客户端，或留给首次使用该集群的调用创建
+	if cm.eagerConnect {
+		clientset, err := cm.newClientset(restConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create client for context %s: %w", contextName, err)
+		}
+		cm.clusters[clusterName] = clientset
+		delete(cm.lazyClients, clusterName)
+	} else {
+		cm.lazyClients[clusterName] = &lazyClient{config: restConfig}
+		delete(cm.clusters, clusterName)
 	}
-
-	cm.clusters[clusterName] = clientset
 	cm.configs[clusterName] = restConfig
+	cm.dropDiscovery(clusterName)
+	delete(cm.loadErrors, clusterName)
+
+	// Several contexts may point at the same cluster; the current context's namespace and file win
+	// 多个上下文可能指向同一集群，以当前上下文的命名空间和文件为准
+	if context.Namespace != "" {
+		if _, exists := cm.defaultNamespaces[clusterName]; !exists || contextName == config.CurrentContext {
+			cm.defaultNamespaces[clusterName] = context.Namespace
+		}
+	}
+	if _, exists := cm.sources[clusterName]; !exists || contextName == config.CurrentContext {
+		cm.sources[clusterName] = context.LocationOfOrigin
+		cm.clusterContexts[clusterName] = contextName
+	}
+	cm.kubeContexts[contextName] = minimalKubeConfig(config, contextName)
 
 	// Set first cluster as current if none set
 	// 如果未设置当前集群，则将第一个集群设置为当前集群
@@ -122,13 +300,18 @@ func (cm *ClusterManager) addContextCluster(config *clientcmdapi.Config, context
 
 // AddCluster adds a cluster with direct configuration
 func (cm *ClusterManager) AddCluster(name string, config *rest.Config) error {
-	clientset, err := kubernetes.NewForConfig(config)
+	config = rest.CopyConfig(config)
+	cm.applyClientSettings(name, config)
+
+	clientset, err := cm.newClientset(config)
 	if err != nil {
 		return fmt.Errorf("failed to create client for cluster %s: %w", name, err)
 	}
 
 	cm.clusters[name] = clientset
 	cm.configs[name] = config
+	delete(cm.lazyClients, name)
+	cm.dropDiscovery(name)
 
 	// Set as current if none set
 	if cm.currentCluster == "" {
@@ -138,50 +321,250 @@ func (cm *ClusterManager) AddCluster(name string, config *rest.Config) error {
 	return nil
 }
 
+// AddClientset adds a cluster served by an existing client, such as a fake clientset in tests.
+// No rest.Config is recorded, so client settings and dynamic clients are not available for it.
+// AddClientset 添加由现有客户端（例如测试中的 fake clientset）提供服务的集群。
+// 该集群没有 rest.Config，因此无法获取客户端配置和动态客户端。
+func (cm *ClusterManager) AddClientset(name string, client kubernetes.Interface) {
+	cm.clusters[name] = client
+	delete(cm.lazyClients, name)
+	delete(cm.loadErrors, name)
+	cm.dropDiscovery(name)
+
+	if cm.currentCluster == "" {
+		cm.currentCluster = name
+	}
+}
+
+// applyClientSettings sets the rate limits, user agent, impersonation, namespace guard and retries on a cluster's
+// rest.Config before any client is built from it. Per-cluster overrides win over the global settings.
+// applyClientSettings 在创建客户端之前为集群的 rest.Config 设置限流参数、UserAgent、身份模拟、命名空间守卫和重试，
+// 单个集群的覆盖配置优先于全局配置。
+func (cm *ClusterManager) applyClientSettings(clusterName string, config *rest.Config) {
+	settings := cm.clientSettings
+	if override, ok := cm.clusterClients[clusterName]; ok {
+		if override.QPS > 0 {
+			settings.QPS = override.QPS
+		}
+		if override.Burst > 0 {
+			settings.Burst = override.Burst
+		}
+	}
+
+	if settings.QPS > 0 {
+		config.QPS = settings.QPS
+	}
+	if settings.Burst > 0 {
+		config.Burst = settings.Burst
+	}
+	if cm.userAgent != "" {
+		config.UserAgent = cm.userAgent
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &impersonatingTransport{cm: cm, next: rt}
+	})
+	if cm.namespacePolicy.Restricted() {
+		policy := cm.namespacePolicy
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &namespaceGuard{policy: policy, next: rt}
+		})
+	}
+	// Outermost, so every attempt passes through the wrappers above
+	// 位于最外层，使每次尝试都经过上面的包装
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &retryingTransport{cm: cm, cluster: clusterName, baseDelay: retryBaseDelay, next: rt}
+	})
+}
+
+// EffectiveClientSettings reports the rate limits and user agent a cluster's clients
+// actually use, resolving unset fields to the client-go defaults
+// EffectiveClientSettings 返回集群客户端实际使用的限流参数和 UserAgent，未设置的字段解析为 client-go 默认值
+func (cm *ClusterManager) EffectiveClientSettings(clusterName string) (ClientSettings, string, error) {
+	config, exists := cm.configs[clusterName]
+	if !exists {
+		return ClientSettings{}, "", fmt.Errorf("cluster %s %w", clusterName, ErrClusterNotFound)
+	}
+
+	settings := ClientSettings{QPS: config.QPS, Burst: config.Burst}
+	if settings.QPS == 0 {
+		settings.QPS = rest.DefaultQPS
+	}
+	if settings.Burst == 0 {
+		settings.Burst = rest.DefaultBurst
+	}
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = rest.DefaultKubernetesUserAgent()
+	}
+	return settings, userAgent, nil
+}
+
+// DefaultClientSettings reports the rate limits of the clusters without a per-cluster
+// override, resolving unset fields to the client-go defaults
+// DefaultClientSettings 返回没有单独覆盖配置的集群使用的限流参数，未设置的字段解析为 client-go 默认值
+func (cm *ClusterManager) DefaultClientSettings() ClientSettings {
+	settings := cm.clientSettings
+	if settings.QPS == 0 {
+		settings.QPS = rest.DefaultQPS
+	}
+	if settings.Burst == 0 {
+		settings.Burst = rest.DefaultBurst
+	}
+	return settings
+}
+
 // GetClusters returns list of available cluster names
 func (cm *ClusterManager) GetClusters() []string {
-	clusters := make([]string, 0, len(cm.clusters))
+	clusters := make([]string, 0, len(cm.clusters)+len(cm.lazyClients))
 	for name := range cm.clusters {
 		clusters = append(clusters, name)
 	}
+	for name := range cm.lazyClients {
+		clusters = append(clusters, name)
+	}
 	return clusters
 }
 
+// hasCluster reports whether a cluster is loaded, whether or not its client is built yet
+// hasCluster 返回集群是否已加载，无论其客户端是否已创建
+func (cm *ClusterManager) hasCluster(clusterName string) bool {
+	if _, exists := cm.clusters[clusterName]; exists {
+		return true
+	}
+	_, exists := cm.lazyClients[clusterName]
+	return exists
+}
+
+// DefaultNamespace is used when neither the caller nor the kubeconfig context sets a namespace
+// DefaultNamespace 调用方和 kubeconfig 上下文都未指定命名空间时使用的命名空间
+const DefaultNamespace = "default"
+
+// GetDefaultNamespace returns the namespace of the cluster's kubeconfig context, or
+// "default" if the context doesn't set one. An empty name selects the current cluster.
+// GetDefaultNamespace 返回集群 kubeconfig 上下文中的命名空间，未设置时返回 "default"。
+// 名称为空时使用当前集群。
+func (cm *ClusterManager) GetDefaultNamespace(clusterName string) string {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	if namespace := cm.defaultNamespaces[clusterName]; namespace != "" {
+		return namespace
+	}
+	return DefaultNamespace
+}
+
+// SetDefaultNamespace overrides the default namespace of a cluster
+// SetDefaultNamespace 设置集群的默认命名空间
+func (cm *ClusterManager) SetDefaultNamespace(clusterName, namespace string) {
+	cm.defaultNamespaces[clusterName] = namespace
+}
+
 // GetCurrentCluster returns the current active cluster name
 func (cm *ClusterManager) GetCurrentCluster() string {
 	return cm.currentCluster
 }
 
+// GetUnavailableClusters returns the clusters from the kubeconfig that failed to load, with their errors
+// GetUnavailableClusters 返回 kubeconfig 中加载失败的集群及其错误
+func (cm *ClusterManager) GetUnavailableClusters() map[string]error {
+	unavailable := make(map[string]error, len(cm.loadErrors))
+	for name, err := range cm.loadErrors {
+		unavailable[name] = err
+	}
+	return unavailable
+}
+
+// clusterNotFound explains why a cluster has no client: its recorded load error, or that it's unknown
+// clusterNotFound 说明集群没有客户端的原因：记录的加载错误，或集群不存在
+func (cm *ClusterManager) clusterNotFound(clusterName string) error {
+	if err, failed := cm.loadErrors[clusterName]; failed {
+		return fmt.Errorf("cluster %s is %w: %w", clusterName, ErrClusterUnavailable, err)
+	}
+	return fmt.Errorf("client for cluster %s %w", clusterName, ErrClusterNotFound)
+}
+
 // SwitchCluster switches to a different cluster
 func (cm *ClusterManager) SwitchCluster(clusterName string) error {
-	if _, exists := cm.clusters[clusterName]; !exists {
-		return fmt.Errorf("cluster %s not found", clusterName)
+	if !cm.hasCluster(clusterName) {
+		if _, failed := cm.loadErrors[clusterName]; failed {
+			return cm.clusterNotFound(clusterName)
+		}
+		return fmt.Errorf("cluster %s %w", clusterName, ErrClusterNotFound)
 	}
 	cm.currentCluster = clusterName
 	return nil
 }
 
 // GetCurrentClient returns the kubernetes client for the current cluster
-func (cm *ClusterManager) GetCurrentClient() (*kubernetes.Clientset, error) {
+func (cm *ClusterManager) GetCurrentClient() (kubernetes.Interface, error) {
 	if cm.currentCluster == "" {
 		return nil, fmt.Errorf("no current cluster set")
 	}
 
-	client, exists := cm.clusters[cm.currentCluster]
+	return cm.GetClientForCluster(cm.currentCluster)
+}
+
+// GetClientForCluster returns the kubernetes client for a specific cluster, building it on first use
+// GetClientForCluster 返回指定集群的 kubernetes 客户端，首次使用时创建
+func (cm *ClusterManager) GetClientForCluster(clusterName string) (kubernetes.Interface, error) {
+	if client, exists := cm.clusters[clusterName]; exists {
+		return client, nil
+	}
+	lazy, exists := cm.lazyClients[clusterName]
 	if !exists {
-		return nil, fmt.Errorf("client for cluster %s not found", cm.currentCluster)
+		return nil, cm.clusterNotFound(clusterName)
 	}
 
-	return client, nil
+	lazy.once.Do(func() {
+		lazy.client, lazy.err = cm.newClientset(lazy.config)
+		if lazy.err != nil {
+			lazy.err = fmt.Errorf("failed to create client for cluster %s: %w", clusterName, lazy.err)
+			cm.logger.Warn("Failed to create cluster client", "cluster", clusterName, "error", lazy.err)
+			return
+		}
+		cm.logger.Debug("Created cluster client", "cluster", clusterName)
+	})
+	return lazy.client, lazy.err
 }
 
-// GetClientForCluster returns the kubernetes client for a specific cluster
-func (cm *ClusterManager) GetClientForCluster(clusterName string) (*kubernetes.Clientset, error) {
-	client, exists := cm.clusters[clusterName]
+// RESTConfig returns a copy of a cluster's rest.Config, for clients that are not built from
+// the clientset such as the SPDY executor of pods/exec. An empty name selects the current cluster.
+// RESTConfig 返回集群 rest.Config 的副本，用于不通过 clientset 创建的客户端，例如 pods/exec 的 SPDY 执行器。
+// 名称为空时使用当前集群。
+func (cm *ClusterManager) RESTConfig(clusterName string) (*rest.Config, error) {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	config, exists := cm.configs[clusterName]
 	if !exists {
-		return nil, fmt.Errorf("client for cluster %s not found", clusterName)
+		if _, added := cm.clusters[clusterName]; added {
+			return nil, fmt.Errorf("cluster %s has no REST config", clusterName)
+		}
+		return nil, cm.clusterNotFound(clusterName)
 	}
-	return client, nil
+	return rest.CopyConfig(config), nil
+}
+
+// GetDynamicClientForCluster returns a dynamic client and REST mapper for a cluster. Both are
+// built once per cluster and the mapper reads discovery through a memory cache (see
+// RESTMapping and InvalidateDiscovery). An empty name selects the current cluster.
+// GetDynamicClientForCluster 返回集群的动态客户端和 REST 映射器。两者按集群只创建一次，映射器通过内存缓存读取发现信息
+// (见 RESTMapping 和 InvalidateDiscovery)。名称为空时使用当前集群。
+func (cm *ClusterManager) GetDynamicClientForCluster(clusterName string) (dynamic.Interface, meta.RESTMapper, error) {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	if mock, exists := cm.mockDynamic[clusterName]; exists {
+		return mock.client, mock.mapper, nil
+	}
+	if _, exists := cm.configs[clusterName]; !exists {
+		return nil, nil, cm.clusterNotFound(clusterName)
+	}
+	cached, err := cm.clusterDiscoveryFor(clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cached.dynamic, cached.mapper, nil
 }
 
 // HealthCheck checks if the current cluster is reachable
@@ -199,17 +582,32 @@ func (cm *ClusterManager) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// HealthCheckCluster checks if a specific cluster is reachable
+// HealthCheckCluster checks if a specific cluster is reachable and caches the outcome
+// HealthCheckCluster 检查指定集群是否可达，并缓存检查结果
 func (cm *ClusterManager) HealthCheckCluster(ctx context.Context, clusterName string) error {
 	client, err := cm.GetClientForCluster(clusterName)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.Discovery().ServerVersion()
+	// Fake clientsets (mock mode) have no REST client; asking them for the version always succeeds
+	// 模拟模式使用的 fake clientset 没有 REST 客户端，直接查询版本即可
+	var serverVersion version.Info
+	if restClient := client.Discovery().RESTClient(); restClient != nil {
+		var body []byte
+		body, err = restClient.Get().AbsPath("/version").Do(ctx).Raw()
+		if err == nil {
+			err = json.Unmarshal(body, &serverVersion)
+		}
+	} else {
+		var info *version.Info
+		if info, err = client.Discovery().ServerVersion(); err == nil {
+			serverVersion = *info
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to connect to cluster %s: %w", clusterName, err)
+		err = fmt.Errorf("failed to connect to cluster %s: %w", clusterName, err)
 	}
-
-	return nil
+	cm.recordReachability(clusterName, serverVersion.GitVersion, err)
+	return err
 }
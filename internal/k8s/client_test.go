@@ -0,0 +1,372 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: prod-admin
+clusters:
+- name: prod
+  cluster:
+    server: https://127.0.0.1:1
+- name: staging
+  cluster:
+    server: https://127.0.0.1:2
+- name: dev
+  cluster:
+    server: https://127.0.0.1:3
+contexts:
+- name: prod-readonly
+  context:
+    cluster: prod
+    user: admin
+    namespace: monitoring
+- name: prod-admin
+  context:
+    cluster: prod
+    user: admin
+    namespace: payments
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+    namespace: web
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+users:
+- name: admin
+  user:
+    token: test
+`
+
+// TestDefaultNamespaceFromKubeConfig 测试从 kubeconfig 上下文读取默认命名空间
+func TestDefaultNamespaceFromKubeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+
+	tests := map[string]string{
+		// The current context wins when several contexts share a cluster
+		// 多个上下文共享集群时以当前上下文为准
+		"prod":    "payments",
+		"staging": "web",
+		// No namespace in the context falls back to "default"
+		// 上下文未设置命名空间时回退到 "default"
+		"dev":     DefaultNamespace,
+		"unknown": DefaultNamespace,
+	}
+	for cluster, want := range tests {
+		if got := cm.GetDefaultNamespace(cluster); got != want {
+			t.Errorf("GetDefaultNamespace(%q) = %q, want %q", cluster, got, want)
+		}
+	}
+
+	cm.SetDefaultNamespace("dev", "sandbox")
+	if got := cm.GetDefaultNamespace("dev"); got != "sandbox" {
+		t.Errorf("expected override to apply, got %q", got)
+	}
+}
+
+// TestClientSettingsApplied 测试 QPS/Burst 和 UserAgent 被应用到每个集群的 rest.Config，且单集群配置优先
+func TestClientSettingsApplied(t *testing.T) {
+	cm := NewClusterManager(&Options{
+		Client:         ClientSettings{QPS: 50, Burst: 100},
+		ClusterClients: map[string]ClientSettings{"prod": {QPS: 200}},
+		UserAgent:      "k8s-mcp/test",
+	})
+
+	config := &rest.Config{Host: "https://127.0.0.1:1"}
+	if err := cm.AddCluster("dev", config); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	if config.QPS != 0 || config.UserAgent != "" {
+		t.Errorf("AddCluster must not modify the caller's config: %+v", config)
+	}
+	if err := cm.AddCluster("prod", &rest.Config{Host: "https://127.0.0.1:2"}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+
+	tests := map[string]ClientSettings{
+		"dev":  {QPS: 50, Burst: 100},
+		"prod": {QPS: 200, Burst: 100},
+	}
+	for cluster, want := range tests {
+		got := cm.configs[cluster]
+		if got.QPS != want.QPS || got.Burst != want.Burst || got.UserAgent != "k8s-mcp/test" {
+			t.Errorf("%s: got qps=%v burst=%d ua=%q, want %+v", cluster, got.QPS, got.Burst, got.UserAgent, want)
+		}
+	}
+}
+
+// TestEffectiveClientSettingsDefaults 测试未配置时报告 client-go 默认值
+func TestEffectiveClientSettingsDefaults(t *testing.T) {
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("dev", &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+
+	settings, userAgent, err := cm.EffectiveClientSettings("dev")
+	if err != nil {
+		t.Fatalf("EffectiveClientSettings failed: %v", err)
+	}
+	if settings.QPS != rest.DefaultQPS || settings.Burst != rest.DefaultBurst || userAgent == "" {
+		t.Errorf("unexpected defaults: %+v %q", settings, userAgent)
+	}
+	if _, _, err := cm.EffectiveClientSettings("missing"); err == nil {
+		t.Errorf("expected error for unknown cluster")
+	}
+}
+
+// TestLoadClusterClientSettings 测试从文件加载按集群覆盖的配置
+func TestLoadClusterClientSettings(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "clients.yaml")
+	if err := os.WriteFile(valid, []byte("clusters:\n  prod:\n    qps: 100\n    burst: 200\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	settings, err := LoadClusterClientSettings(valid)
+	if err != nil {
+		t.Fatalf("LoadClusterClientSettings failed: %v", err)
+	}
+	if settings["prod"] != (ClientSettings{QPS: 100, Burst: 200}) {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+
+	for name, content := range map[string]string{
+		"unknown.yaml":  "clusters:\n  prod:\n    qsp: 100\n",
+		"negative.yaml": "clusters:\n  prod:\n    burst: -1\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := LoadClusterClientSettings(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+const testBrokenKubeConfig = `apiVersion: v1
+kind: Config
+current-context: good
+clusters:
+- name: good
+  cluster:
+    server: https://127.0.0.1:1
+- name: broken
+  cluster:
+    server: https://127.0.0.1:2
+    certificate-authority: /nonexistent/ca.crt
+contexts:
+- name: good
+  context:
+    cluster: good
+    user: admin
+- name: broken
+  context:
+    cluster: broken
+    user: admin
+users:
+- name: admin
+  user:
+    token: test
+`
+
+// TestLoadKubeConfigSkipsBrokenContexts 测试单个上下文出错时继续加载其他集群并记录错误
+func TestLoadKubeConfigSkipsBrokenContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testBrokenKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+
+	if clusters := cm.GetClusters(); len(clusters) != 1 || clusters[0] != "good" {
+		t.Errorf("expected only the good cluster to load, got %v", clusters)
+	}
+	unavailable := cm.GetUnavailableClusters()
+	if err, ok := unavailable["broken"]; !ok || !strings.Contains(err.Error(), "ca.crt") {
+		t.Errorf("expected the broken cluster with its load error, got %v", unavailable)
+	}
+
+	// 获取失败集群的客户端时返回记录的加载错误，而不是 "not found"
+	_, err := cm.GetClientForCluster("broken")
+	if err == nil || !strings.Contains(err.Error(), "unavailable") || !strings.Contains(err.Error(), "ca.crt") {
+		t.Errorf("expected the recorded load error, got %v", err)
+	}
+	if err := cm.SwitchCluster("broken"); err == nil || !strings.Contains(err.Error(), "ca.crt") {
+		t.Errorf("expected SwitchCluster to report the load error, got %v", err)
+	}
+	if _, err := cm.GetClientForCluster("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found for an unknown cluster, got %v", err)
+	}
+}
+
+// TestLoadKubeConfigAllContextsBroken 测试所有上下文都失败时返回错误
+func TestLoadKubeConfigAllContextsBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	config := strings.Replace(testBrokenKubeConfig, "server: https://127.0.0.1:1", "server: https://127.0.0.1:1\n    certificate-authority: /nonexistent/ca.crt", 1)
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err == nil {
+		t.Fatalf("expected an error when no cluster can be initialized")
+	}
+	if len(cm.GetUnavailableClusters()) != 2 {
+		t.Errorf("expected both clusters to be recorded, got %v", cm.GetUnavailableClusters())
+	}
+}
+
+// countingClientsets 替换 cm 的 clientset 构造函数，返回 fake clientset 并统计每个 API 服务器地址创建的次数
+func countingClientsets(cm *ClusterManager, err error) *sync.Map {
+	built := &sync.Map{}
+	cm.newClientset = func(config *rest.Config) (kubernetes.Interface, error) {
+		count, _ := built.LoadOrStore(config.Host, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+		if err != nil {
+			return nil, err
+		}
+		return fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}), nil
+	}
+	return built
+}
+
+// builtCount 返回为 host 创建 clientset 的次数
+func builtCount(built *sync.Map, host string) int32 {
+	count, ok := built.Load(host)
+	if !ok {
+		return 0
+	}
+	return count.(*atomic.Int32).Load()
+}
+
+// TestLazyClientConstruction 测试加载 kubeconfig 时不创建 clientset，工具调用首次使用集群时只创建一次
+func TestLazyClientConstruction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(nil)
+	built := countingClientsets(cm, nil)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+	if clusters := cm.GetClusters(); len(clusters) != 3 {
+		t.Errorf("expected 3 clusters, got %v", clusters)
+	}
+	if err := cm.SwitchCluster("staging"); err != nil {
+		t.Fatalf("SwitchCluster failed: %v", err)
+	}
+	if _, err := cm.RESTConfig("dev"); err != nil {
+		t.Fatalf("RESTConfig failed: %v", err)
+	}
+	built.Range(func(host, _ any) bool {
+		t.Errorf("expected no clientset before a tool call, built one for %s", host)
+		return true
+	})
+
+	// 并发的工具调用共享同一个 clientset
+	ro := NewResourceOperations(cm)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ro.ListNamespaces(context.Background(), "staging"); err != nil {
+				t.Errorf("ListNamespaces failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := cm.GetCurrentClient(); err != nil {
+		t.Fatalf("GetCurrentClient failed: %v", err)
+	}
+	if builtCount(built, "https://127.0.0.1:2") != 1 || builtCount(built, "https://127.0.0.1:1") != 0 || builtCount(built, "https://127.0.0.1:3") != 0 {
+		t.Errorf("expected only the staging clientset to be built once")
+	}
+
+	// 预热只创建当前集群的客户端
+	cm.WarmUpCurrentCluster(context.Background(), time.Second)
+	if status, ok := cm.GetReachability("staging"); !ok || !status.Reachable {
+		t.Errorf("expected the warm-up to probe the current cluster, got %+v", status)
+	}
+	if _, ok := cm.GetReachability("prod"); ok || builtCount(built, "https://127.0.0.1:1") != 0 {
+		t.Errorf("expected the other clusters to stay unbuilt")
+	}
+}
+
+// TestLazyClientError 测试创建客户端的错误 (例如凭据插件失败) 在首次使用时返回并包含集群名称，且不重复创建
+func TestLazyClientError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(nil)
+	pluginErr := errors.New("exec: executable aws not found")
+	built := countingClientsets(cm, pluginErr)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err != nil {
+		t.Fatalf("expected the load to succeed before any client is built, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := cm.GetClientForCluster("prod")
+		if !errors.Is(err, pluginErr) || !strings.Contains(err.Error(), "cluster prod") {
+			t.Errorf("expected the plugin error with the cluster name, got %v", err)
+		}
+	}
+	if builtCount(built, "https://127.0.0.1:1") != 1 {
+		t.Errorf("expected a single construction attempt, got %d", builtCount(built, "https://127.0.0.1:1"))
+	}
+}
+
+// TestEagerConnect 测试 EagerConnect 在加载 kubeconfig 时创建所有集群的 clientset
+func TestEagerConnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+
+	cm := NewClusterManager(&Options{EagerConnect: true})
+	built := countingClientsets(cm, nil)
+	if err := cm.LoadKubeConfigAndInitCluster(path); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+	// prod 有两个上下文，每个上下文都会创建一次
+	if builtCount(built, "https://127.0.0.1:1") != 2 || builtCount(built, "https://127.0.0.1:2") != 1 || builtCount(built, "https://127.0.0.1:3") != 1 {
+		t.Errorf("expected every context's clientset to be built at load")
+	}
+	if _, err := cm.GetClientForCluster("dev"); err != nil {
+		t.Errorf("GetClientForCluster failed: %v", err)
+	}
+}
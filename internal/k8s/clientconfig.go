@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// clientConfigFile is the layout of the per-cluster client settings file:
+//
+//	clusters:
+//	  prod:
+//	    qps: 100
+//	    burst: 200
+//
+// clientConfigFile 是按集群配置客户端参数的文件格式
+type clientConfigFile struct {
+	Clusters map[string]ClientSettings `json:"clusters"`
+}
+
+// LoadClusterClientSettings reads per-cluster QPS/Burst overrides from a YAML or JSON file
+// LoadClusterClientSettings 从 YAML 或 JSON 文件读取按集群覆盖的 QPS/Burst 配置
+func LoadClusterClientSettings(path string) (map[string]ClientSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client config: %w", err)
+	}
+
+	var file clientConfigFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid client config %s: %w", path, err)
+	}
+	for name, settings := range file.Clusters {
+		if settings.QPS < 0 || settings.Burst < 0 {
+			return nil, fmt.Errorf("invalid client config %s: qps and burst of cluster %s must not be negative", path, name)
+		}
+	}
+	return file.Clusters, nil
+}
@@ -0,0 +1,209 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterInfoTimeout bounds all the optional queries of GetClusterInfo together
+// clusterInfoTimeout 限制 GetClusterInfo 所有可选查询的总时长
+var clusterInfoTimeout = 10 * time.Second
+
+// API groups reported by GetClusterInfo
+// GetClusterInfo 报告的 API 组
+const (
+	metricsAPIGroup       = "metrics.k8s.io"
+	apiextensionsAPIGroup = "apiextensions.k8s.io"
+)
+
+// crdResource is the GroupVersionResource of CustomResourceDefinitions
+// crdResource 是 CustomResourceDefinition 的 GroupVersionResource
+var crdResource = schema.GroupVersionResource{Group: apiextensionsAPIGroup, Version: "v1", Resource: "customresourcedefinitions"}
+
+// clusterInfoQuery is an optional part of the cluster info. Run returns the fields it
+// fills; a field whose value is an error is reported unavailable, and an error from Run
+// marks all of keys unavailable
+// clusterInfoQuery 是集群信息中的可选部分，Run 返回其填充的字段；值为 error 的字段标记为不可用，
+// Run 返回错误时 keys 中的所有字段都标记为不可用
+type clusterInfoQuery struct {
+	keys []string
+	skip bool
+	run  func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// clusterInfoResult is the outcome of the query at index
+// clusterInfoResult 是第 index 个查询的结果
+type clusterInfoResult struct {
+	index  int
+	fields map[string]interface{}
+	err    error
+}
+
+// clusterInfoQueries returns the optional queries of GetClusterInfo
+// clusterInfoQueries 返回 GetClusterInfo 的可选查询
+func (ro *ResourceOperations) clusterInfoQueries(client kubernetes.Interface, clusterName, serverVersion string) []clusterInfoQuery {
+	allowClusterScope := ro.clusterManager.namespacePolicy.AllowClusterScope()
+
+	return []clusterInfoQuery{
+		{keys: []string{"namespaceCount"}, run: func(ctx context.Context) (map[string]interface{}, error) {
+			// Only the allowed namespaces are counted in namespace-scoped mode
+			// 命名空间受限模式下只统计允许的命名空间
+			namespaces, err := ro.ListNamespaces(ctx, clusterName)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"namespaceCount": len(namespaces)}, nil
+		}},
+		{keys: []string{"podCount"}, run: func(ctx context.Context) (map[string]interface{}, error) {
+			pods, err := ro.ListPods(ctx, "", clusterName)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"podCount": len(pods)}, nil
+		}},
+		// Nodes are cluster-scoped and may be hidden by the namespace policy
+		// 节点是集群级资源，可能被命名空间策略隐藏
+		{keys: []string{"nodeCount", "versionSkew"}, skip: !allowClusterScope, run: func(ctx context.Context) (map[string]interface{}, error) {
+			nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list nodes: %w", err)
+			}
+			fields := map[string]interface{}{"nodeCount": len(nodes.Items)}
+			if skew, err := versionSkew(serverVersion, nodes.Items); err != nil {
+				fields["versionSkew"] = err
+			} else {
+				fields["versionSkew"] = skew
+			}
+			return fields, nil
+		}},
+		{keys: []string{"metricsAPIAvailable", "apiextensionsAPIAvailable"}, run: func(ctx context.Context) (map[string]interface{}, error) {
+			groups, err := client.Discovery().ServerGroups()
+			if err != nil {
+				return nil, fmt.Errorf("failed to discover API groups: %w", err)
+			}
+			fields := map[string]interface{}{"metricsAPIAvailable": false, "apiextensionsAPIAvailable": false}
+			for _, group := range groups.Groups {
+				switch group.Name {
+				case metricsAPIGroup:
+					fields["metricsAPIAvailable"] = true
+				case apiextensionsAPIGroup:
+					fields["apiextensionsAPIAvailable"] = true
+				}
+			}
+			return fields, nil
+		}},
+		// CustomResourceDefinitions are cluster-scoped as well
+		// CustomResourceDefinition 同样是集群级资源
+		{keys: []string{"crdCount"}, skip: !allowClusterScope, run: func(ctx context.Context) (map[string]interface{}, error) {
+			if _, err := client.Discovery().ServerResourcesForGroupVersion(crdResource.GroupVersion().String()); err != nil {
+				return nil, fmt.Errorf("failed to discover %s: %w", crdResource.GroupVersion(), err)
+			}
+			dynamicClient, _, err := ro.clusterManager.GetDynamicClientForCluster(clusterName)
+			if err != nil {
+				return nil, err
+			}
+			crds, err := dynamicClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list customresourcedefinitions: %w", err)
+			}
+			return map[string]interface{}{"crdCount": len(crds.Items)}, nil
+		}},
+	}
+}
+
+// runClusterInfoQueries runs the queries concurrently and merges their fields into info.
+// A query that fails or is still running when clusterInfoTimeout expires is reported in
+// the returned map as "unavailable: <reason>" for each of its keys.
+// runClusterInfoQueries 并发执行查询并将字段合并到 info 中。失败的查询或 clusterInfoTimeout 到期时仍未完成的查询，
+// 其每个字段以 "unavailable: <原因>" 的形式记录在返回的 map 中。
+func runClusterInfoQueries(ctx context.Context, queries []clusterInfoQuery, info map[string]interface{}) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, clusterInfoTimeout)
+	defer cancel()
+
+	// The channel is buffered so queries finishing after the timeout do not block
+	// 通道带缓冲，超时后才完成的查询不会阻塞
+	results := make(chan clusterInfoResult, len(queries))
+	pending := make(map[int]bool)
+	for i, query := range queries {
+		if query.skip {
+			continue
+		}
+		pending[i] = true
+		go func() {
+			fields, err := query.run(ctx)
+			results <- clusterInfoResult{index: i, fields: fields, err: err}
+		}()
+	}
+
+	unavailable := make(map[string]string)
+	markUnavailable := func(keys []string, err error) {
+		for _, key := range keys {
+			unavailable[key] = fmt.Sprintf("unavailable: %v", err)
+		}
+	}
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.index)
+			if result.err != nil {
+				markUnavailable(queries[result.index].keys, result.err)
+				continue
+			}
+			for key, value := range result.fields {
+				if err, failed := value.(error); failed {
+					markUnavailable([]string{key}, err)
+					continue
+				}
+				info[key] = value
+			}
+		case <-ctx.Done():
+			for i := range pending {
+				markUnavailable(queries[i].keys, ctx.Err())
+			}
+			return unavailable
+		}
+	}
+	return unavailable
+}
+
+// versionSkew compares the kubelet versions of the nodes with the API server version
+// under the Kubernetes version skew policy
+// versionSkew 按 Kubernetes 版本偏差策略比较节点 kubelet 版本与 API server 版本
+func versionSkew(serverVersion string, nodes []corev1.Node) (*types.VersionSkew, error) {
+	server, err := utilversion.ParseGeneric(serverVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API server version %q: %w", serverVersion, err)
+	}
+	// Kubelets may be up to three minor versions older than the API server since 1.28, two before
+	// 从 1.28 起 kubelet 最多可以比 API server 旧三个次版本，此前为两个
+	maxSkew := 3
+	if server.Major() == 1 && server.Minor() < 28 {
+		maxSkew = 2
+	}
+
+	skew := &types.VersionSkew{APIServer: serverVersion, Kubelets: make(map[string]int), Supported: true}
+	for _, node := range nodes {
+		kubeletVersion := node.Status.NodeInfo.KubeletVersion
+		skew.Kubelets[kubeletVersion]++
+		kubelet, err := utilversion.ParseGeneric(kubeletVersion)
+		if err != nil {
+			continue
+		}
+		minorSkew := int(server.Minor()) - int(kubelet.Minor())
+		if kubelet.Major() != server.Major() || minorSkew < 0 || minorSkew > maxSkew {
+			skew.Supported = false
+		}
+		if minorSkew > skew.MaxMinorSkew {
+			skew.MaxMinorSkew = minorSkew
+		}
+	}
+	return skew, nil
+}
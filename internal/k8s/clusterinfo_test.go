@@ -0,0 +1,242 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// stubGroupsDiscovery 是 ServerGroups 由 serverGroups 实现的 fake discovery 客户端，
+// 用于模拟 fake clientset 无法注入的失败和延迟
+type stubGroupsDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	serverGroups func() (*metav1.APIGroupList, error)
+}
+
+func (d stubGroupsDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	return d.serverGroups()
+}
+
+// withServerGroups 返回 discovery 的 ServerGroups 由 serverGroups 实现的 clientset
+func withServerGroups(client *fake.Clientset, serverGroups func() (*metav1.APIGroupList, error)) discoveryClientset {
+	fakeDiscovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	return discoveryClientset{Clientset: client, discovery: stubGroupsDiscovery{FakeDiscovery: fakeDiscovery, serverGroups: serverGroups}}
+}
+
+// discoveryClientset 是使用指定 discovery 客户端的 fake clientset
+type discoveryClientset struct {
+	*fake.Clientset
+	discovery discovery.DiscoveryInterface
+}
+
+func (c discoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
+// clusterInfoFixture 返回一个命名空间、两个 Pod 和两个 kubelet 版本不同的节点
+func clusterInfoFixture() []runtime.Object {
+	node := func(name, kubelet string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubelet}}}
+	}
+	return []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+		node("node-1", "v1.28.4"),
+		node("node-2", "v1.27.8"),
+	}
+}
+
+// newClusterInfoFake 返回服务器版本为 v1.28.4 的 fake clientset，discovery 中包含给定的 API 组版本
+func newClusterInfoFake(groupVersions ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset(clusterInfoFixture()...)
+	fakeDiscovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: "v1.28.4", Platform: "linux/amd64"}
+	for _, gv := range groupVersions {
+		fakeDiscovery.Resources = append(fakeDiscovery.Resources, &metav1.APIResourceList{GroupVersion: gv})
+	}
+	return client
+}
+
+// TestGetClusterInfo 测试集群信息包含控制平面地址、API 可用性、CRD 数量、Pod 数量和 kubelet 版本偏差
+func TestGetClusterInfo(t *testing.T) {
+	client := newClusterInfoFake("v1", "metrics.k8s.io/v1beta1", "apiextensions.k8s.io/v1")
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+	cm.configs["test"] = &rest.Config{Host: "https://10.0.0.1:6443"}
+
+	crd := func(name string) runtime.Object {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		crd("widgets.example.com"), crd("gadgets.example.com"))
+	cm.mockDynamic = map[string]mockDynamicClient{"test": {client: dynamicClient}}
+
+	info, err := NewResourceOperations(cm).GetClusterInfo(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("GetClusterInfo failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"version":                   "v1.28.4",
+		"endpoint":                  "https://10.0.0.1:6443",
+		"namespaceCount":            1,
+		"nodeCount":                 2,
+		"podCount":                  2,
+		"crdCount":                  2,
+		"metricsAPIAvailable":       true,
+		"apiextensionsAPIAvailable": true,
+	}
+	for key, value := range want {
+		if info[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, info[key])
+		}
+	}
+	if _, found := info["unavailable"]; found {
+		t.Errorf("expected every query to succeed, got %v", info["unavailable"])
+	}
+}
+
+// TestGetClusterInfoSelectiveFailures 测试单个查询失败或超时只将对应字段标记为不可用，其他字段正常返回
+func TestGetClusterInfoSelectiveFailures(t *testing.T) {
+	client := newClusterInfoFake("v1")
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("pods are forbidden")
+	})
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", withServerGroups(client, func() (*metav1.APIGroupList, error) {
+		return nil, errors.New("discovery unavailable")
+	}))
+
+	info, err := NewResourceOperations(cm).GetClusterInfo(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("GetClusterInfo failed: %v", err)
+	}
+	if info["version"] != "v1.28.4" || info["nodeCount"] != 2 || info["namespaceCount"] != 1 {
+		t.Errorf("expected the successful fields, got %v", info)
+	}
+	unavailable, _ := info["unavailable"].(map[string]string)
+	for key, want := range map[string]string{
+		"podCount":                  "pods are forbidden",
+		"metricsAPIAvailable":       "discovery unavailable",
+		"apiextensionsAPIAvailable": "discovery unavailable",
+		"crdCount":                  "failed to discover apiextensions.k8s.io/v1",
+		"endpoint":                  "no REST config",
+	} {
+		if !strings.HasPrefix(unavailable[key], "unavailable: ") || !strings.Contains(unavailable[key], want) {
+			t.Errorf("expected %s to be unavailable with %q, got %q", key, want, unavailable[key])
+		}
+		if _, found := info[key]; found {
+			t.Errorf("expected no %s field, got %v", key, info[key])
+		}
+	}
+
+	// 超时的查询标记为不可用，已完成的查询保留结果
+	original := clusterInfoTimeout
+	clusterInfoTimeout = 50 * time.Millisecond
+	defer func() { clusterInfoTimeout = original }()
+
+	// fake clientset 在执行 reactor 时持有锁，因此通过 discovery 模拟慢查询，避免阻塞其他查询
+	client = newClusterInfoFake("v1")
+	cm = NewClusterManager(nil)
+	cm.AddClientset("test", withServerGroups(client, func() (*metav1.APIGroupList, error) {
+		time.Sleep(time.Second)
+		return &metav1.APIGroupList{}, nil
+	}))
+
+	started := time.Now()
+	info, err = NewResourceOperations(cm).GetClusterInfo(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("GetClusterInfo failed: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the shared timeout to bound the call, took %v", elapsed)
+	}
+	unavailable, _ = info["unavailable"].(map[string]string)
+	if !strings.Contains(unavailable["metricsAPIAvailable"], "deadline exceeded") || info["podCount"] != 2 || info["nodeCount"] != 2 {
+		t.Errorf("expected only the API group discovery to time out, got %v", info)
+	}
+}
+
+// TestGetClusterInfoVersionRequired 测试无法获取服务器版本时整个调用失败
+func TestGetClusterInfoVersionRequired(t *testing.T) {
+	client := newClusterInfoFake()
+	client.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+
+	if _, err := NewResourceOperations(cm).GetClusterInfo(context.Background(), "test"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the server version error, got %v", err)
+	}
+}
+
+// TestVersionSkew 测试 kubelet 版本偏差的计算和版本偏差策略
+func TestVersionSkew(t *testing.T) {
+	nodes := func(versions ...string) []corev1.Node {
+		var items []corev1.Node
+		for _, v := range versions {
+			items = append(items, corev1.Node{Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: v}}})
+		}
+		return items
+	}
+
+	tests := []struct {
+		name          string
+		server        string
+		kubelets      []string
+		wantSkew      int
+		wantSupported bool
+	}{
+		{"same version", "v1.28.4", []string{"v1.28.4", "v1.28.4"}, 0, true},
+		{"three minors older since 1.28", "v1.29.0-gke.1", []string{"v1.26.3", "v1.29.0"}, 3, true},
+		{"three minors older before 1.28", "v1.27.2", []string{"v1.24.1"}, 3, false},
+		{"kubelet newer than the API server", "v1.27.2", []string{"v1.28.0"}, 0, false},
+		{"unparseable kubelet is counted but ignored", "v1.28.4", []string{"unknown", "v1.27.0"}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew, err := versionSkew(tt.server, nodes(tt.kubelets...))
+			if err != nil {
+				t.Fatalf("versionSkew failed: %v", err)
+			}
+			if skew.MaxMinorSkew != tt.wantSkew || skew.Supported != tt.wantSupported || len(tt.kubelets) != sumCounts(skew.Kubelets) {
+				t.Errorf("unexpected skew %+v", skew)
+			}
+		})
+	}
+
+	if _, err := versionSkew("not-a-version", nil); err == nil {
+		t.Error("expected an error for an unparseable API server version")
+	}
+}
+
+// sumCounts 返回各版本节点数之和
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,629 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// compareIgnoredAnnotations are set by controllers or by the cluster's own history, so
+// they differ between clusters running the same configuration
+// compareIgnoredAnnotations 由控制器或集群自身的历史写入，运行相同配置的集群之间也会不同
+var compareIgnoredAnnotations = []string{
+	lastAppliedAnnotation,
+	"deployment.kubernetes.io/revision",
+	"deprecated.daemonset.template.generation",
+	"kubectl.kubernetes.io/restartedAt",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"pv.kubernetes.io/provisioned-by",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// compareIgnoredFields are allocated or bound by the cluster rather than configured
+// compareIgnoredFields 由集群分配或绑定，而不是由配置决定
+var compareIgnoredFields = [][]string{
+	{"metadata", "ownerReferences"},
+	{"spec", "clusterIP"},
+	{"spec", "clusterIPs"},
+	{"spec", "healthCheckNodePort"},
+	{"spec", "nodeName"},
+	{"spec", "volumeName"},
+	{"spec", "claimRef"},
+}
+
+// maxSummaryPaths caps the field paths listed by the fallback summary line
+// maxSummaryPaths 限制兜底摘要行列出的字段路径数量
+const maxSummaryPaths = 8
+
+// defaultCompareNamespaceTypes are compared by CompareNamespace when no types are given
+// defaultCompareNamespaceTypes 是 CompareNamespace 未指定类型时对比的资源类型
+var defaultCompareNamespaceTypes = []ResourceType{ResourceTypeDeployments, ResourceTypeConfigMaps}
+
+// namespaceLister lists the objects of one resource type in a namespace
+// namespaceLister 列出命名空间中某种资源类型的对象
+type namespaceLister func(ctx context.Context, client kubernetes.Interface, namespace string) (runtime.Object, error)
+
+// compareNamespaceTypes are the resource types CompareNamespace supports
+// compareNamespaceTypes 是 CompareNamespace 支持的资源类型
+var compareNamespaceTypes = []string{"configmaps", "cronjobs", "deployments", "ingresses", "secrets", "services", "statefulsets"}
+
+// namespaceListers maps the resource types CompareNamespace supports, singular and plural
+// namespaceListers 列出 CompareNamespace 支持的资源类型（单数和复数形式）
+var namespaceListers = func() map[ResourceType]namespaceLister {
+	listers := map[ResourceType]namespaceLister{}
+	register := func(plural, singular ResourceType, lister namespaceLister) {
+		listers[plural] = lister
+		listers[singular] = lister
+	}
+	register(ResourceTypeDeployments, ResourceTypeDeployment, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeStatefulSets, ResourceTypeStatefulSet, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeServices, ResourceTypeService, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.CoreV1().Services(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeConfigMaps, ResourceTypeConfigMap, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeSecrets, ResourceTypeSecret, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.CoreV1().Secrets(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeIngresses, ResourceTypeIngress, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.NetworkingV1().Ingresses(ns).List(ctx, metav1.ListOptions{})
+	})
+	register(ResourceTypeCronJobs, ResourceTypeCronJob, func(ctx context.Context, c kubernetes.Interface, ns string) (runtime.Object, error) {
+		return c.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	})
+	return listers
+}()
+
+// CompareResource fetches the same object from two clusters and compares them after
+// dropping status, server-populated metadata and cluster-specific fields. The result
+// holds a unified diff from clusterA to clusterB and a short summary of what differs;
+// an object missing from either cluster is reported in the summary rather than as an error.
+// CompareResource 从两个集群获取同一对象，移除 status、服务器填充的元数据和集群相关字段后进行对比。
+// 结果包含从 clusterA 到 clusterB 的 unified diff 以及差异摘要；对象在某个集群中不存在时在摘要中说明，而不是返回错误。
+func (ro *ResourceOperations) CompareResource(ctx context.Context, resourceType ResourceType, namespace, name, clusterA, clusterB string) (*types.ResourceComparison, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if clusterA == "" || clusterB == "" {
+		return nil, fmt.Errorf("cluster_a and cluster_b are required")
+	}
+	factory, ok := metadataClients[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type for compare: %s", resourceType)
+	}
+	if IsClusterScoped(resourceType) {
+		namespace = ""
+	}
+
+	objA, err := ro.fetchForCompare(ctx, factory, namespace, name, clusterA)
+	if err != nil {
+		return nil, err
+	}
+	objB, err := ro.fetchForCompare(ctx, factory, namespace, name, clusterB)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ResourceComparison{
+		ResourceType: string(resourceType),
+		Namespace:    namespace,
+		Name:         name,
+		ClusterA:     clusterA,
+		ClusterB:     clusterB,
+		ExistsInA:    objA != nil,
+		ExistsInB:    objB != nil,
+	}
+	ref := name
+	if namespace != "" {
+		ref = namespace + "/" + name
+	}
+	switch {
+	case objA == nil && objB == nil:
+		result.Summary = []string{fmt.Sprintf("%s %s exists in neither %s nor %s", resourceType, ref, clusterA, clusterB)}
+		return result, nil
+	case objA == nil:
+		result.Summary = []string{fmt.Sprintf("%s %s only exists in %s, it is missing from %s", resourceType, ref, clusterB, clusterA)}
+		return result, nil
+	case objB == nil:
+		result.Summary = []string{fmt.Sprintf("%s %s only exists in %s, it is missing from %s", resourceType, ref, clusterA, clusterB)}
+		return result, nil
+	}
+
+	diff, summary, err := compareObjects(objA, objB, clusterA, clusterB)
+	if err != nil {
+		return nil, err
+	}
+	result.Identical = diff == ""
+	result.Diff = diff
+	result.Summary = summary
+	return result, nil
+}
+
+// CompareNamespace compares which objects of each resource type exist in a namespace of
+// two clusters, and which of those present in both differ after normalization
+// CompareNamespace 对比两个集群的同一命名空间中各资源类型存在哪些对象，以及两边都存在的对象中哪些在规范化后不同
+func (ro *ResourceOperations) CompareNamespace(ctx context.Context, namespace string, resourceTypes []ResourceType, clusterA, clusterB string) (*types.NamespaceComparison, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if clusterA == "" || clusterB == "" {
+		return nil, fmt.Errorf("cluster_a and cluster_b are required")
+	}
+	if len(resourceTypes) == 0 {
+		resourceTypes = defaultCompareNamespaceTypes
+	}
+
+	clientA, err := ro.clusterManager.GetClientForCluster(clusterA)
+	if err != nil {
+		return nil, err
+	}
+	clientB, err := ro.clusterManager.GetClientForCluster(clusterB)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.NamespaceComparison{
+		Namespace: namespace,
+		ClusterA:  clusterA,
+		ClusterB:  clusterB,
+	}
+	for _, resourceType := range resourceTypes {
+		lister, ok := namespaceListers[resourceType]
+		if !ok {
+			return nil, fmt.Errorf("unsupported resource type for compare_namespace: %s (supported: %s)", resourceType, strings.Join(compareNamespaceTypes, ", "))
+		}
+		objectsA, err := listForCompare(ctx, lister, clientA, namespace, resourceType, clusterA)
+		if err != nil {
+			return nil, err
+		}
+		objectsB, err := listForCompare(ctx, lister, clientB, namespace, resourceType, clusterB)
+		if err != nil {
+			return nil, err
+		}
+		comparison, err := compareObjectSets(resourceType, objectsA, objectsB)
+		if err != nil {
+			return nil, err
+		}
+		result.Resources = append(result.Resources, comparison)
+	}
+	return result, nil
+}
+
+// fetchForCompare gets an object from a cluster as unstructured; nil means it does not exist
+// fetchForCompare 以 unstructured 形式从集群获取对象，nil 表示对象不存在
+func (ro *ResourceOperations) fetchForCompare(ctx context.Context, factory metadataClientFactory, namespace, name, clusterName string) (*unstructured.Unstructured, error) {
+	client, err := ro.clusterManager.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := factory(client, namespace).get(ctx, name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from cluster %s: %w", name, clusterName, err)
+	}
+	return toUnstructured(obj.(runtime.Object))
+}
+
+// listForCompare lists the objects of one resource type in a namespace, keyed by name
+// listForCompare 列出命名空间中某种资源类型的对象，以名称为键
+func listForCompare(ctx context.Context, lister namespaceLister, client kubernetes.Interface, namespace string, resourceType ResourceType, clusterName string) (map[string]*unstructured.Unstructured, error) {
+	list, err := lister(ctx, client, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in cluster %s: %w", resourceType, clusterName, err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s list: %w", resourceType, err)
+	}
+	objects := make(map[string]*unstructured.Unstructured, len(items))
+	for _, item := range items {
+		obj, err := toUnstructured(item)
+		if err != nil {
+			return nil, err
+		}
+		objects[obj.GetName()] = obj
+	}
+	return objects, nil
+}
+
+// toUnstructured converts a typed object, setting the apiVersion/kind the clientset leaves empty
+// toUnstructured 转换类型化对象，并设置 clientset 未填充的 apiVersion/kind
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	setTypeMeta(obj)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert object: %w", err)
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// compareObjectSets splits the names of two object sets into those only in one of them,
+// and those in both that differ or are identical after normalization
+// compareObjectSets 将两个对象集合的名称分为只在一侧存在的，以及两侧都存在且规范化后不同或相同的
+func compareObjectSets(resourceType ResourceType, objectsA, objectsB map[string]*unstructured.Unstructured) (types.NamespaceResourceComparison, error) {
+	comparison := types.NamespaceResourceComparison{ResourceType: string(resourceType)}
+	for name, objA := range objectsA {
+		objB, ok := objectsB[name]
+		if !ok {
+			comparison.OnlyInA = append(comparison.OnlyInA, name)
+			continue
+		}
+		yamlA, err := normalizedYAML(normalizeForCompare(objA))
+		if err != nil {
+			return comparison, err
+		}
+		yamlB, err := normalizedYAML(normalizeForCompare(objB))
+		if err != nil {
+			return comparison, err
+		}
+		if yamlA == yamlB {
+			comparison.Identical = append(comparison.Identical, name)
+		} else {
+			comparison.Different = append(comparison.Different, name)
+		}
+	}
+	for name := range objectsB {
+		if _, ok := objectsA[name]; !ok {
+			comparison.OnlyInB = append(comparison.OnlyInB, name)
+		}
+	}
+	sort.Strings(comparison.OnlyInA)
+	sort.Strings(comparison.OnlyInB)
+	sort.Strings(comparison.Different)
+	sort.Strings(comparison.Identical)
+	return comparison, nil
+}
+
+// compareObjects normalizes two copies of an object and returns the unified diff from a
+// to b (empty when identical) and a summary of the differences
+// compareObjects 规范化对象的两个副本，返回从 a 到 b 的 unified diff（相同时为空）以及差异摘要
+func compareObjects(a, b *unstructured.Unstructured, nameA, nameB string) (string, []string, error) {
+	a, b = normalizeForCompare(a), normalizeForCompare(b)
+	yamlA, err := normalizedYAML(a)
+	if err != nil {
+		return "", nil, err
+	}
+	yamlB, err := normalizedYAML(b)
+	if err != nil {
+		return "", nil, err
+	}
+	if yamlA == yamlB {
+		return "", nil, nil
+	}
+	diff, err := unifiedDiff(yamlA, yamlB, nameA+"/"+objectRef(a), nameB+"/"+objectRef(b))
+	if err != nil {
+		return "", nil, err
+	}
+	return diff, summarizeDifferences(a, b, nameA, nameB), nil
+}
+
+// normalizeForCompare returns a copy of obj without the fields that differ between
+// clusters running the same configuration: status, server-populated metadata,
+// cluster-specific annotations, allocated fields and empty values
+// normalizeForCompare 返回 obj 的副本，移除运行相同配置的集群之间也会不同的字段：status、
+// 服务器填充的元数据、集群相关注解、由集群分配的字段以及空值
+func normalizeForCompare(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	normalized := obj.DeepCopy()
+	content := normalized.Object
+	delete(content, "status")
+	for _, field := range diffIgnoredMetadataFields {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	for _, field := range compareIgnoredFields {
+		unstructured.RemoveNestedField(content, field...)
+	}
+	if ports, ok := nestedValue(content, "spec", "ports").([]interface{}); ok && normalized.GetKind() == "Service" {
+		for _, port := range ports {
+			if port, ok := port.(map[string]interface{}); ok {
+				delete(port, "nodePort")
+			}
+		}
+	}
+	annotationPaths := [][]string{{"metadata", "annotations"}}
+	if podSpec := podSpecPath(normalized.GetKind()); len(podSpec) > 1 {
+		template := podSpec[:len(podSpec)-1]
+		annotationPaths = append(annotationPaths, append(append([]string{}, template...), "metadata", "annotations"))
+	}
+	for _, path := range annotationPaths {
+		for _, annotation := range compareIgnoredAnnotations {
+			unstructured.RemoveNestedField(content, append(append([]string{}, path...), annotation)...)
+		}
+	}
+	SanitizeObject(content, false)
+	return normalized
+}
+
+// podSpecPath returns the path of the pod spec in objects of kind, or nil if it has none
+// podSpecPath 返回 kind 类型对象中 pod spec 的路径，没有 pod spec 时返回 nil
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return []string{"spec", "template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		return nil
+	}
+}
+
+// summarizeDifferences describes how two normalized objects differ in short sentences:
+// labels, replicas, container images, env vars and resources, and configmap or secret
+// keys are described individually, every other differing field is listed by path
+// summarizeDifferences 用简短的句子描述两个规范化对象的差异：标签、副本数、容器镜像、环境变量和资源、
+// 以及 configmap 或 secret 的键单独描述，其他不同的字段按路径列出
+func summarizeDifferences(a, b *unstructured.Unstructured, nameA, nameB string) []string {
+	s := &differenceSummary{nameA: nameA, nameB: nameB}
+	restA, restB := a.DeepCopy().Object, b.DeepCopy().Object
+
+	s.compareMaps("label", a.GetLabels(), b.GetLabels())
+	unstructured.RemoveNestedField(restA, "metadata", "labels")
+	unstructured.RemoveNestedField(restB, "metadata", "labels")
+
+	if podSpec := podSpecPath(a.GetKind()); podSpec != nil {
+		if a.GetKind() != "Pod" {
+			s.compareValue("replicas differ", nestedValue(a.Object, "spec", "replicas"), nestedValue(b.Object, "spec", "replicas"))
+			unstructured.RemoveNestedField(restA, "spec", "replicas")
+			unstructured.RemoveNestedField(restB, "spec", "replicas")
+		}
+		for _, field := range []string{"initContainers", "containers"} {
+			path := append(append([]string{}, podSpec...), field)
+			s.compareContainers(nestedValue(a.Object, path...), nestedValue(b.Object, path...))
+			unstructured.RemoveNestedField(restA, path...)
+			unstructured.RemoveNestedField(restB, path...)
+		}
+	}
+
+	if kind := a.GetKind(); kind == "ConfigMap" || kind == "Secret" {
+		for _, field := range []string{"data", "binaryData", "stringData"} {
+			s.compareKeys(nestedValue(a.Object, field), nestedValue(b.Object, field))
+			delete(restA, field)
+			delete(restB, field)
+		}
+	}
+
+	var paths []string
+	differingPaths(restA, restB, "", &paths)
+	sort.Strings(paths)
+	if len(paths) > maxSummaryPaths {
+		paths = append(paths[:maxSummaryPaths], fmt.Sprintf("and %d more", len(paths)-maxSummaryPaths))
+	}
+	if len(paths) > 0 {
+		s.add("other fields differ: %s", strings.Join(paths, ", "))
+	}
+	return s.lines
+}
+
+// differenceSummary collects the summary lines of summarizeDifferences
+// differenceSummary 收集 summarizeDifferences 的摘要行
+type differenceSummary struct {
+	nameA, nameB string
+	lines        []string
+}
+
+func (s *differenceSummary) add(format string, args ...interface{}) {
+	s.lines = append(s.lines, fmt.Sprintf(format, args...))
+}
+
+// compareValue reports a value that differs, showing both values after what
+// compareValue 报告不同的值，在 what 之后显示两侧的值
+func (s *differenceSummary) compareValue(what string, a, b interface{}) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	s.add("%s: %s in %s vs %s in %s", what, displayValue(a), s.nameA, displayValue(b), s.nameB)
+}
+
+// compareMaps reports the keys of two string maps that are only in one of them or differ
+// compareMaps 报告两个字符串 map 中只在一侧存在或值不同的键
+func (s *differenceSummary) compareMaps(what string, a, b map[string]string) {
+	for _, key := range unionKeys(a, b) {
+		valueA, inA := a[key]
+		valueB, inB := b[key]
+		switch {
+		case !inA:
+			s.add("%s %s only in %s", what, key, s.nameB)
+		case !inB:
+			s.add("%s %s only in %s", what, key, s.nameA)
+		case valueA != valueB:
+			s.add("%s %s differs: %s in %s vs %s in %s", what, key, valueA, s.nameA, valueB, s.nameB)
+		}
+	}
+}
+
+// compareKeys reports the configmap or secret keys only in one object or with different
+// values; values are not shown since secret values are fingerprints
+// compareKeys 报告只在一侧存在或值不同的 configmap 或 secret 键；secret 的值为指纹，因此不显示值
+func (s *differenceSummary) compareKeys(a, b interface{}) {
+	mapA, _ := a.(map[string]interface{})
+	mapB, _ := b.(map[string]interface{})
+	for _, key := range unionKeys(mapA, mapB) {
+		valueA, inA := mapA[key]
+		valueB, inB := mapB[key]
+		switch {
+		case !inA:
+			s.add("key %s only in %s", key, s.nameB)
+		case !inB:
+			s.add("key %s only in %s", key, s.nameA)
+		case !reflect.DeepEqual(valueA, valueB):
+			s.add("value of key %s differs", key)
+		}
+	}
+}
+
+// compareContainers matches containers by name and reports missing containers and
+// differing images, env vars and resources; other container fields are listed by path
+// compareContainers 按名称匹配容器，报告缺失的容器以及不同的镜像、环境变量和资源；容器的其他字段按路径列出
+func (s *differenceSummary) compareContainers(a, b interface{}) {
+	containersA, containersB := namedItems(a), namedItems(b)
+	for _, name := range unionKeys(containersA, containersB) {
+		containerA, inA := containersA[name]
+		containerB, inB := containersB[name]
+		switch {
+		case !inA:
+			s.add("container %s only in %s", name, s.nameB)
+			continue
+		case !inB:
+			s.add("container %s only in %s", name, s.nameA)
+			continue
+		}
+		restA, restB := copyMap(containerA), copyMap(containerB)
+
+		s.compareValue("image of container "+name+" differs", containerA["image"], containerB["image"])
+		s.compareValue("resources of container "+name+" differ", containerA["resources"], containerB["resources"])
+		envA, envB := namedItems(containerA["env"]), namedItems(containerB["env"])
+		for _, env := range unionKeys(envA, envB) {
+			varA, inA := envA[env]
+			varB, inB := envB[env]
+			switch {
+			case !inA:
+				s.add("env var %s of container %s only in %s", env, name, s.nameB)
+			case !inB:
+				s.add("env var %s of container %s only in %s", env, name, s.nameA)
+			case !reflect.DeepEqual(varA, varB):
+				s.add("env var %s of container %s differs: %s in %s vs %s in %s", env, name, displayEnvVar(varA), s.nameA, displayEnvVar(varB), s.nameB)
+			}
+		}
+		for _, field := range []string{"name", "image", "resources", "env"} {
+			delete(restA, field)
+			delete(restB, field)
+		}
+
+		var paths []string
+		differingPaths(restA, restB, "", &paths)
+		sort.Strings(paths)
+		if len(paths) > 0 {
+			s.add("container %s differs in %s", name, strings.Join(paths, ", "))
+		}
+	}
+}
+
+// differingPaths appends the dotted paths where a and b differ, descending into maps
+// present on both sides; lists are compared as a whole
+// differingPaths 追加 a 和 b 不同之处的点分路径，两侧都是 map 时向下递归，列表整体比较
+func differingPaths(a, b map[string]interface{}, prefix string, paths *[]string) {
+	for _, key := range unionKeys(a, b) {
+		valueA, valueB := a[key], b[key]
+		if reflect.DeepEqual(valueA, valueB) {
+			continue
+		}
+		mapA, okA := valueA.(map[string]interface{})
+		mapB, okB := valueB.(map[string]interface{})
+		if okA && okB {
+			differingPaths(mapA, mapB, prefix+key+".", paths)
+			continue
+		}
+		*paths = append(*paths, prefix+key)
+	}
+}
+
+// namedItems indexes a list of maps, such as containers or env vars, by their name field
+// namedItems 以 name 字段为键索引 map 列表，例如容器或环境变量
+func namedItems(list interface{}) map[string]map[string]interface{} {
+	items := map[string]map[string]interface{}{}
+	values, _ := list.([]interface{})
+	for _, value := range values {
+		if item, ok := value.(map[string]interface{}); ok {
+			if name, ok := item["name"].(string); ok {
+				items[name] = item
+			}
+		}
+	}
+	return items
+}
+
+// unionKeys returns the keys of two maps, sorted and without duplicates
+// unionKeys 返回两个 map 的键（已排序且去重）
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nestedValue returns the value at path, or nil if it is missing
+// nestedValue 返回 path 处的值，不存在时返回 nil
+func nestedValue(obj map[string]interface{}, path ...string) interface{} {
+	value, found, err := unstructured.NestedFieldNoCopy(obj, path...)
+	if !found || err != nil {
+		return nil
+	}
+	return value
+}
+
+// copyMap returns a shallow copy of m
+// copyMap 返回 m 的浅拷贝
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// displayValue renders a summary value, "unset" for a missing one
+// displayValue 渲染摘要中的值，缺失时为 "unset"
+func displayValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "unset"
+	case string:
+		return v
+	case map[string]interface{}:
+		return displayMap(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// displayMap renders a map compactly with sorted keys, like {limits: {cpu: 500m}}
+// displayMap 以排序后的键紧凑地渲染 map，例如 {limits: {cpu: 500m}}
+func displayMap(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+": "+displayValue(m[key]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// displayEnvVar renders an env var's value, or where it comes from
+// displayEnvVar 渲染环境变量的值或其来源
+func displayEnvVar(env map[string]interface{}) string {
+	if valueFrom, ok := env["valueFrom"].(map[string]interface{}); ok {
+		return "valueFrom " + displayMap(valueFrom)
+	}
+	return fmt.Sprintf("%q", displayValue(env["value"]))
+}
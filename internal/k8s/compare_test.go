@@ -0,0 +1,360 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// compareDeployment 返回用于对比测试的 Deployment，mutate 可修改副本
+func compareDeployment(mutate func(*appsv1.Deployment)) *appsv1.Deployment {
+	replicas := int32(2)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "shop",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "web",
+						Image: "shop/web:v1.2",
+						Env:   []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+					}},
+				},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(dep)
+	}
+	return dep
+}
+
+// TestNormalizeForCompare 测试对比前移除易变字段和集群相关字段，保留配置字段
+func TestNormalizeForCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     runtime.Object
+		removed [][]string
+		kept    [][]string
+	}{
+		{
+			name: "deployment",
+			obj: compareDeployment(func(d *appsv1.Deployment) {
+				d.UID = "3f1c"
+				d.ResourceVersion = "4711"
+				d.Generation = 7
+				d.CreationTimestamp = metav1.Now()
+				d.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+				d.Annotations = map[string]string{
+					"deployment.kubernetes.io/revision": "12",
+					"team":                              "shop",
+				}
+				d.Spec.Template.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "2024-05-01T10:00:00Z"}
+				d.Status.ReadyReplicas = 2
+			}),
+			removed: [][]string{
+				{"status"},
+				{"metadata", "uid"},
+				{"metadata", "resourceVersion"},
+				{"metadata", "generation"},
+				{"metadata", "creationTimestamp"},
+				{"metadata", "managedFields"},
+				{"metadata", "annotations", "deployment.kubernetes.io/revision"},
+				{"spec", "template", "metadata", "annotations"},
+				{"spec", "template", "metadata", "creationTimestamp"},
+			},
+			kept: [][]string{
+				{"metadata", "annotations", "team"},
+				{"spec", "replicas"},
+				{"spec", "template", "spec", "containers"},
+			},
+		},
+		{
+			name: "service",
+			obj: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec: corev1.ServiceSpec{
+					Type:       corev1.ServiceTypeNodePort,
+					ClusterIP:  "10.96.12.7",
+					ClusterIPs: []string{"10.96.12.7"},
+					Ports:      []corev1.ServicePort{{Port: 80, NodePort: 31080}},
+				},
+			},
+			removed: [][]string{{"spec", "clusterIP"}, {"spec", "clusterIPs"}},
+			kept:    [][]string{{"spec", "type"}, {"spec", "ports"}},
+		},
+		{
+			name: "pvc",
+			obj: &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "data",
+					Namespace:   "shop",
+					Annotations: map[string]string{"pv.kubernetes.io/bind-completed": "yes"},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-0b7f"},
+			},
+			removed: [][]string{{"metadata", "annotations"}, {"spec", "volumeName"}},
+		},
+		{
+			name: "pod owned by a replicaset",
+			obj: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "web-abcde",
+					Namespace:       "shop",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d4b9c8f6", UID: "9a1e"}},
+				},
+				Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "web"}}},
+			},
+			removed: [][]string{{"metadata", "ownerReferences"}, {"spec", "nodeName"}},
+			kept:    [][]string{{"spec", "containers"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := toUnstructured(tt.obj)
+			if err != nil {
+				t.Fatalf("toUnstructured failed: %v", err)
+			}
+			normalized := normalizeForCompare(obj).Object
+			for _, path := range tt.removed {
+				if value := nestedValue(normalized, path...); value != nil {
+					t.Errorf("expected %s to be removed, got %v", strings.Join(path, "."), value)
+				}
+			}
+			for _, path := range tt.kept {
+				if nestedValue(normalized, path...) == nil {
+					t.Errorf("expected %s to be kept", strings.Join(path, "."))
+				}
+			}
+		})
+	}
+
+	// NodePort 由集群分配，端口本身保留
+	svc, _ := toUnstructured(tests[1].obj)
+	ports := nestedValue(normalizeForCompare(svc).Object, "spec", "ports").([]interface{})
+	if port := ports[0].(map[string]interface{}); port["nodePort"] != nil || port["port"] == nil {
+		t.Errorf("expected nodePort removed and port kept, got %v", port)
+	}
+}
+
+// TestSummarizeDifferences 测试差异摘要
+func TestSummarizeDifferences(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b runtime.Object
+		want []string
+	}{
+		{
+			name: "image, env and replicas",
+			a:    compareDeployment(nil),
+			b: compareDeployment(func(d *appsv1.Deployment) {
+				replicas := int32(4)
+				d.Spec.Replicas = &replicas
+				d.Spec.Template.Spec.Containers[0].Image = "shop/web:v1.3"
+				d.Spec.Template.Spec.Containers[0].Env = append(d.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "FOO", Value: "bar"})
+			}),
+			want: []string{
+				"replicas differ: 2 in staging vs 4 in prod",
+				"image of container web differs: shop/web:v1.2 in staging vs shop/web:v1.3 in prod",
+				"env var FOO of container web only in prod",
+			},
+		},
+		{
+			name: "env value, resources and labels",
+			a: compareDeployment(func(d *appsv1.Deployment) {
+				d.Labels["tier"] = "frontend"
+			}),
+			b: compareDeployment(func(d *appsv1.Deployment) {
+				d.Labels["app"] = "web-prod"
+				d.Spec.Template.Spec.Containers[0].Env[0].Value = "debug"
+				d.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+			}),
+			want: []string{
+				"label app differs: web in staging vs web-prod in prod",
+				"label tier only in staging",
+				"resources of container web differ: unset in staging vs {limits: {cpu: 500m}} in prod",
+				`env var LOG_LEVEL of container web differs: "info" in staging vs "debug" in prod`,
+			},
+		},
+		{
+			name: "containers and other fields",
+			a:    compareDeployment(nil),
+			b: compareDeployment(func(d *appsv1.Deployment) {
+				d.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
+				d.Spec.Template.Spec.Containers[0].Args = []string{"--verbose"}
+				d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{Name: "proxy", Image: "envoy:v1"})
+			}),
+			want: []string{
+				"container proxy only in prod",
+				"container web differs in args",
+				"other fields differ: spec.strategy",
+			},
+		},
+		{
+			name: "configmap keys",
+			a: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+				Data:       map[string]string{"LOG_LEVEL": "info", "PORT": "8080", "OLD": "1"},
+			},
+			b: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+				Data:       map[string]string{"LOG_LEVEL": "debug", "PORT": "8080", "NEW": "1"},
+			},
+			want: []string{
+				"value of key LOG_LEVEL differs",
+				"key NEW only in prod",
+				"key OLD only in staging",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := toUnstructured(tt.a)
+			if err != nil {
+				t.Fatalf("toUnstructured failed: %v", err)
+			}
+			b, err := toUnstructured(tt.b)
+			if err != nil {
+				t.Fatalf("toUnstructured failed: %v", err)
+			}
+			got := summarizeDifferences(normalizeForCompare(a), normalizeForCompare(b), "staging", "prod")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summary mismatch\ngot:  %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompareResource 测试从两个集群获取对象并对比
+func TestCompareResource(t *testing.T) {
+	ro, _ := newFakeOperations(t, compareDeployment(func(d *appsv1.Deployment) {
+		d.ResourceVersion = "100"
+		d.Annotations = map[string]string{"deployment.kubernetes.io/revision": "3"}
+	}))
+	ro.clusterManager.AddClientset("prod", fake.NewSimpleClientset(
+		compareDeployment(func(d *appsv1.Deployment) {
+			d.ResourceVersion = "9000"
+			d.Annotations = map[string]string{"deployment.kubernetes.io/revision": "41"}
+			d.Spec.Template.Spec.Containers[0].Image = "shop/web:v1.3"
+		}),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"}},
+	))
+	ctx := context.Background()
+
+	result, err := ro.CompareResource(ctx, ResourceTypeDeployment, "shop", "web", "test", "prod")
+	if err != nil {
+		t.Fatalf("CompareResource failed: %v", err)
+	}
+	if !result.ExistsInA || !result.ExistsInB || result.Identical {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	for _, want := range []string{"--- test/shop/web", "+++ prod/shop/web", "-        image: shop/web:v1.2", "+        image: shop/web:v1.3"} {
+		if !strings.Contains(result.Diff, want) {
+			t.Errorf("expected %q in diff:\n%s", want, result.Diff)
+		}
+	}
+	for _, unwanted := range []string{"resourceVersion", "revision", "status"} {
+		if strings.Contains(result.Diff, unwanted) {
+			t.Errorf("expected %s to be normalized away:\n%s", unwanted, result.Diff)
+		}
+	}
+	if want := []string{"image of container web differs: shop/web:v1.2 in test vs shop/web:v1.3 in prod"}; !reflect.DeepEqual(result.Summary, want) {
+		t.Errorf("expected summary %q, got %q", want, result.Summary)
+	}
+
+	// 只在一个集群中存在
+	result, err = ro.CompareResource(ctx, ResourceTypeConfigMaps, "shop", "web-config", "test", "prod")
+	if err != nil {
+		t.Fatalf("CompareResource failed: %v", err)
+	}
+	if result.ExistsInA || !result.ExistsInB || result.Diff != "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if want := "configmaps shop/web-config only exists in prod, it is missing from test"; len(result.Summary) != 1 || result.Summary[0] != want {
+		t.Errorf("expected summary %q, got %q", want, result.Summary)
+	}
+
+	// 同一集群的对象与自身相同
+	result, err = ro.CompareResource(ctx, ResourceTypeDeployments, "shop", "web", "prod", "prod")
+	if err != nil {
+		t.Fatalf("CompareResource failed: %v", err)
+	}
+	if !result.Identical || result.Diff != "" || len(result.Summary) != 0 {
+		t.Errorf("expected identical result, got %+v", result)
+	}
+
+	for _, tt := range []struct {
+		resourceType     ResourceType
+		clusterA, wanted string
+	}{
+		{ResourceTypeEvents, "test", "unsupported resource type"},
+		{ResourceTypeDeployments, "", "cluster_a and cluster_b are required"},
+		{ResourceTypeDeployments, "missing", "missing"},
+	} {
+		if _, err := ro.CompareResource(ctx, tt.resourceType, "shop", "web", tt.clusterA, "prod"); err == nil || !strings.Contains(err.Error(), tt.wanted) {
+			t.Errorf("expected error containing %q, got %v", tt.wanted, err)
+		}
+	}
+}
+
+// TestCompareNamespace 测试对比两个集群中命名空间的对象集合
+func TestCompareNamespace(t *testing.T) {
+	configMap := func(name, value string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Data:       map[string]string{"value": value},
+		}
+	}
+	ro, _ := newFakeOperations(t,
+		compareDeployment(nil),
+		configMap("web-config", "1"),
+		configMap("feature-flags", "1"),
+		configMap("staging-only", "1"),
+	)
+	ro.clusterManager.AddClientset("prod", fake.NewSimpleClientset(
+		compareDeployment(nil),
+		configMap("web-config", "2"),
+		configMap("feature-flags", "1"),
+		configMap("prod-only", "1"),
+	))
+
+	result, err := ro.CompareNamespace(context.Background(), "shop", nil, "test", "prod")
+	if err != nil {
+		t.Fatalf("CompareNamespace failed: %v", err)
+	}
+	want := []string{"deployments", "configmaps"}
+	if len(result.Resources) != len(want) {
+		t.Fatalf("expected %d resource types, got %+v", len(want), result.Resources)
+	}
+	deployments, configMaps := result.Resources[0], result.Resources[1]
+	if deployments.ResourceType != "deployments" || !reflect.DeepEqual(deployments.Identical, []string{"web"}) || deployments.OnlyInA != nil || deployments.OnlyInB != nil || deployments.Different != nil {
+		t.Errorf("unexpected deployments comparison: %+v", deployments)
+	}
+	if !reflect.DeepEqual(configMaps.OnlyInA, []string{"staging-only"}) ||
+		!reflect.DeepEqual(configMaps.OnlyInB, []string{"prod-only"}) ||
+		!reflect.DeepEqual(configMaps.Different, []string{"web-config"}) ||
+		!reflect.DeepEqual(configMaps.Identical, []string{"feature-flags"}) {
+		t.Errorf("unexpected configmaps comparison: %+v", configMaps)
+	}
+
+	if _, err := ro.CompareNamespace(context.Background(), "shop", []ResourceType{ResourceTypePods}, "test", "prod"); err == nil || !strings.Contains(err.Error(), "supported: configmaps, cronjobs") {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+}
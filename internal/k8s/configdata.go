@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetConfigMapData returns only the data of a ConfigMap; BinaryData values are base64 encoded
+// GetConfigMapData 只返回 ConfigMap 的数据，BinaryData 的值为 base64 编码
+func (ro *ResourceOperations) GetConfigMapData(ctx context.Context, namespace, name, clusterName string) (*types.ConfigMapData, error) {
+	configMap, err := ro.getConfigMap(ctx, namespace, name, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return configMapData(configMap), nil
+}
+
+// GetConfigMapValue returns the value of a single ConfigMap key. binary reports
+// whether the key comes from BinaryData, in which case the value is base64 encoded.
+// GetConfigMapValue 返回 ConfigMap 中单个键的值。binary 表示该键来自 BinaryData，此时值为 base64 编码。
+func (ro *ResourceOperations) GetConfigMapValue(ctx context.Context, namespace, name, key, clusterName string) (value string, binary bool, err error) {
+	configMap, err := ro.getConfigMap(ctx, namespace, name, clusterName)
+	if err != nil {
+		return "", false, err
+	}
+	return configMapValue(configMap, key)
+}
+
+// GetSecretKeys returns the key names and value sizes of a Secret, never the values
+// GetSecretKeys 返回 Secret 的键名和值的大小，从不返回值本身
+func (ro *ResourceOperations) GetSecretKeys(ctx context.Context, namespace, name, clusterName string) (*types.SecretKeys, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	return secretKeys(secret), nil
+}
+
+// getConfigMap fetches a ConfigMap from a cluster (the current cluster if empty)
+// getConfigMap 从集群获取 ConfigMap（名称为空时使用当前集群）
+func (ro *ResourceOperations) getConfigMap(ctx context.Context, namespace, name, clusterName string) (*corev1.ConfigMap, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
+	}
+	return configMap, nil
+}
+
+// configMapData extracts the data of a ConfigMap
+// configMapData 提取 ConfigMap 的数据
+func configMapData(configMap *corev1.ConfigMap) *types.ConfigMapData {
+	data := &types.ConfigMapData{
+		Name:      configMap.Name,
+		Namespace: configMap.Namespace,
+		Data:      configMap.Data,
+	}
+	if len(configMap.BinaryData) > 0 {
+		data.BinaryData = make(map[string]string, len(configMap.BinaryData))
+		for key, value := range configMap.BinaryData {
+			data.BinaryData[key] = base64.StdEncoding.EncodeToString(value)
+		}
+	}
+	return data
+}
+
+// configMapValue looks a key up in Data, then in BinaryData (base64 encoded)
+// configMapValue 先在 Data 中查找键，再在 BinaryData 中查找（base64 编码）
+func configMapValue(configMap *corev1.ConfigMap, key string) (string, bool, error) {
+	if value, ok := configMap.Data[key]; ok {
+		return value, false, nil
+	}
+	if value, ok := configMap.BinaryData[key]; ok {
+		return base64.StdEncoding.EncodeToString(value), true, nil
+	}
+
+	keys := make([]string, 0, len(configMap.Data)+len(configMap.BinaryData))
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	for k := range configMap.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return "", false, fmt.Errorf("key %q not found in configmap %s/%s (available keys: %v)", key, configMap.Namespace, configMap.Name, keys)
+}
+
+// secretKeys lists the keys of a Secret with the decoded size of each value, sorted by key
+// secretKeys 按键名排序列出 Secret 的键及每个值解码后的大小
+func secretKeys(secret *corev1.Secret) *types.SecretKeys {
+	keys := make([]types.SecretKey, 0, len(secret.Data))
+	for key, value := range secret.Data {
+		keys = append(keys, types.SecretKey{Key: key, Size: len(value)})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+
+	return &types.SecretKeys{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      string(secret.Type),
+		Keys:      keys,
+	}
+}
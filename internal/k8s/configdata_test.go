@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestConfigMap 创建同时包含 Data 和 BinaryData 的测试 ConfigMap
+func newTestConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "shop"},
+		Data:       map[string]string{"LOG_LEVEL": "debug", "app.yaml": "port: 8080\n"},
+		BinaryData: map[string][]byte{"logo.png": {0x89, 0x50, 0x4e, 0x47}},
+	}
+}
+
+// TestConfigMapData 测试提取数据时包含 base64 编码的 BinaryData
+func TestConfigMapData(t *testing.T) {
+	data := configMapData(newTestConfigMap())
+
+	want := &types.ConfigMapData{
+		Name:       "app-config",
+		Namespace:  "shop",
+		Data:       map[string]string{"LOG_LEVEL": "debug", "app.yaml": "port: 8080\n"},
+		BinaryData: map[string]string{"logo.png": "iVBORw=="},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("configMapData() = %+v, want %+v", data, want)
+	}
+
+	if data := configMapData(&corev1.ConfigMap{}); data.BinaryData != nil {
+		t.Errorf("expected no binary_data for a text-only configmap, got %v", data.BinaryData)
+	}
+}
+
+// TestConfigMapValue 测试读取单个键，包括 BinaryData 和不存在的键
+func TestConfigMapValue(t *testing.T) {
+	configMap := newTestConfigMap()
+
+	value, binary, err := configMapValue(configMap, "app.yaml")
+	if err != nil || binary || value != "port: 8080\n" {
+		t.Errorf("configMapValue(app.yaml) = %q, %v, %v", value, binary, err)
+	}
+
+	value, binary, err = configMapValue(configMap, "logo.png")
+	if err != nil || !binary || value != "iVBORw==" {
+		t.Errorf("configMapValue(logo.png) = %q, %v, %v", value, binary, err)
+	}
+
+	_, _, err = configMapValue(configMap, "missing")
+	if err == nil || !strings.Contains(err.Error(), "[LOG_LEVEL app.yaml logo.png]") {
+		t.Errorf("expected an error listing the available keys, got %v", err)
+	}
+}
+
+// TestSecretKeys 测试只返回键名和大小，不包含值
+func TestSecretKeys(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"password": []byte("s3cr3t"), "username": []byte("admin"), "ca.crt": make([]byte, 1200)},
+	}
+
+	keys := secretKeys(secret)
+	want := &types.SecretKeys{
+		Name:      "db",
+		Namespace: "shop",
+		Type:      "Opaque",
+		Keys:      []types.SecretKey{{Key: "ca.crt", Size: 1200}, {Key: "password", Size: 6}, {Key: "username", Size: 5}},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("secretKeys() = %+v, want %+v", keys, want)
+	}
+}
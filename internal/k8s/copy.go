@@ -0,0 +1,355 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// DefaultCopyMaxBytes is the most CopyFromPod reads of a file when no cap is given
+// DefaultCopyMaxBytes 未指定上限时 CopyFromPod 最多读取的文件字节数
+const DefaultCopyMaxBytes = 1 << 20
+
+// copyStderrBytes bounds the output of tar kept for error messages
+// copyStderrBytes 限制为错误信息保留的 tar 输出大小
+const copyStderrBytes = 4 << 10
+
+// copyExitGrace is how long a failed download waits for tar to exit
+// copyExitGrace 是下载失败后等待 tar 退出的时间
+const copyExitGrace = 5 * time.Second
+
+// defaultContainerAnnotation names the container kubectl uses when none is given
+// defaultContainerAnnotation 指定未给出容器时 kubectl 使用的容器
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// DefaultCopyAllowedPaths are the directories CopyToPod writes under when none are configured
+// DefaultCopyAllowedPaths 是未配置时 CopyToPod 允许写入的目录
+var DefaultCopyAllowedPaths = []string{"/tmp"}
+
+// ErrPathNotAllowed is returned for a remote path that escapes with ".." or is outside the allowed directories
+// ErrPathNotAllowed 表示远程路径包含 ".." 或不在允许的目录中
+var ErrPathNotAllowed = errors.New("path not allowed")
+
+// execInPod runs a command in a container over the SPDY executor; tests replace it
+// execInPod 通过 SPDY 执行器在容器中运行命令，测试中会被替换
+var execInPod = streamExec
+
+// streamExec runs command in a container through the pods/exec subresource, wiring
+// the given streams to its stdin, stdout and stderr. A nil stdin is not attached.
+// streamExec 通过 pods/exec 子资源在容器中运行命令，将给定的流连接到其 stdin、stdout 和 stderr，stdin 为 nil 时不连接。
+func streamExec(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: stderr})
+}
+
+// ValidateCopyPath checks a remote path of cp_from_pod or cp_to_pod and returns it cleaned.
+// Paths with a ".." element are always rejected. With allowed set (uploads), the path must
+// also be absolute and inside one of the allowed directories.
+// ValidateCopyPath 检查 cp_from_pod 或 cp_to_pod 的远程路径并返回清理后的路径。包含 ".." 的路径总是被拒绝；
+// 设置 allowed 时（上传），路径还必须是绝对路径并位于某个允许的目录中。
+func ValidateCopyPath(remotePath string, allowed []string) (string, error) {
+	if remotePath == "" {
+		return "", fmt.Errorf("remote_path is required")
+	}
+	for _, element := range strings.Split(remotePath, "/") {
+		if element == ".." {
+			return "", fmt.Errorf("%w: %s contains \"..\"", ErrPathNotAllowed, remotePath)
+		}
+	}
+	cleaned := path.Clean(remotePath)
+	if base := path.Base(cleaned); base == "/" || base == "." {
+		return "", fmt.Errorf("%w: %s does not name a file", ErrPathNotAllowed, remotePath)
+	}
+	if allowed == nil {
+		return cleaned, nil
+	}
+
+	// The working directory of the container is unknown, so relative destinations could land anywhere
+	// 容器的工作目录未知，相对路径的目标可能位于任何位置
+	if !path.IsAbs(cleaned) {
+		return "", fmt.Errorf("%w: %s is not an absolute path", ErrPathNotAllowed, remotePath)
+	}
+	for _, dir := range allowed {
+		dir = path.Clean(dir)
+		if dir == "/" || strings.HasPrefix(cleaned, dir+"/") {
+			return cleaned, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is outside the allowed directories %s", ErrPathNotAllowed, remotePath, strings.Join(allowed, ", "))
+}
+
+// CopyFromPod reads a file from a container like kubectl cp: it runs tar in the container
+// and reads the first entry of the archive as it streams in. At most maxBytes of the file
+// are read; the transfer is then stopped and the result marked truncated.
+// CopyFromPod 与 kubectl cp 一样从容器中读取文件：在容器中运行 tar，并在归档流入时读取其第一个条目。
+// 最多读取文件的 maxBytes 字节，随后停止传输并将结果标记为截断。
+func (ro *ResourceOperations) CopyFromPod(ctx context.Context, namespace, podName, container, remotePath string, maxBytes int64, clusterName string) (*types.PodFile, []byte, error) {
+	remotePath, err := ValidateCopyPath(remotePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultCopyMaxBytes
+	}
+	client, config, container, err := ro.execTarget(ctx, namespace, podName, container, clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stdout, archive := io.Pipe()
+	stderr := &cappedBuffer{limit: copyStderrBytes}
+	done := make(chan error, 1)
+	go func() {
+		command := []string{"tar", "cf", "-", "-C", path.Dir(remotePath), path.Base(remotePath)}
+		err := execInPod(execCtx, config, client, namespace, podName, container, command, nil, archive, stderr)
+		archive.CloseWithError(err)
+		done <- err
+	}()
+
+	header, content, readErr := readTarFile(stdout, maxBytes)
+	// The rest of the archive is never read: stop the transfer instead of draining it.
+	// After a failure tar is given a moment to exit, so its error message arrives.
+	// 不再读取归档的其余部分：停止传输而不是读完它。失败后给 tar 一点时间退出，以便收到其错误信息。
+	stdout.Close()
+	if readErr == nil {
+		cancel()
+	}
+	var execErr error
+	select {
+	case execErr = <-done:
+	case <-time.After(copyExitGrace):
+		cancel()
+		execErr = <-done
+	}
+	if readErr != nil {
+		return nil, nil, fmt.Errorf("failed to copy %s from pod %s: %w", remotePath, podName, copyError(readErr, execErr, stderr))
+	}
+
+	return &types.PodFile{
+		Pod:       podName,
+		Namespace: namespace,
+		Container: container,
+		Path:      remotePath,
+		Size:      header.Size,
+		Bytes:     len(content),
+		Truncated: header.Size > int64(len(content)),
+		MimeType:  http.DetectContentType(content),
+	}, content, nil
+}
+
+// CopyToPod writes content to a file of a container like kubectl cp: a one-file tar
+// archive is streamed to tar in the container. The destination must be inside one of
+// the allowed directories; an existing file is overwritten.
+// CopyToPod 与 kubectl cp 一样向容器写入文件：将只包含一个文件的 tar 归档流式传给容器中的 tar。
+// 目标必须位于某个允许的目录中，已有的文件会被覆盖。
+func (ro *ResourceOperations) CopyToPod(ctx context.Context, namespace, podName, container, remotePath string, content []byte, allowed []string, clusterName string) (*types.PodFileUpload, error) {
+	if allowed == nil {
+		allowed = DefaultCopyAllowedPaths
+	}
+	remotePath, err := ValidateCopyPath(remotePath, allowed)
+	if err != nil {
+		return nil, err
+	}
+	client, config, container, err := ro.execTarget(ctx, namespace, podName, container, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := tarFile(path.Base(remotePath), content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build archive: %w", err)
+	}
+	output := &cappedBuffer{limit: copyStderrBytes}
+	command := []string{"tar", "xf", "-", "-C", path.Dir(remotePath)}
+	if err := execInPod(ctx, config, client, namespace, podName, container, command, bytes.NewReader(archive), output, output); err != nil {
+		return nil, fmt.Errorf("failed to copy %s to pod %s: %w", remotePath, podName, copyError(nil, err, output))
+	}
+
+	return &types.PodFileUpload{
+		Pod:       podName,
+		Namespace: namespace,
+		Container: container,
+		Path:      remotePath,
+		Bytes:     len(content),
+	}, nil
+}
+
+// execTarget returns the client and rest.Config to exec into a pod with, and the container
+// to use: the given one, or like kubectl the default-container annotation or the first container
+// execTarget 返回在 Pod 中执行命令所用的客户端和 rest.Config，以及使用的容器：指定的容器，
+// 否则与 kubectl 一样使用 default-container 注解或第一个容器
+func (ro *ResourceOperations) execTarget(ctx context.Context, namespace, podName, container, clusterName string) (kubernetes.Interface, *rest.Config, string, error) {
+	if podName == "" {
+		return nil, nil, "", fmt.Errorf("pod name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+	name := clusterName
+	if name == "" {
+		name = ro.clusterManager.currentCluster
+	}
+	if _, mock := ro.clusterManager.mockDynamic[name]; mock {
+		return nil, nil, "", fmt.Errorf("the mock cluster cannot run commands in pods")
+	}
+	config, err := ro.clusterManager.RESTConfig(clusterName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get pod: %w", err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, nil, "", fmt.Errorf("pod %s is %s; files can only be copied with a running pod", podName, pod.Status.Phase)
+	}
+	switch {
+	case container != "":
+		if !podHasContainer(pod, container) {
+			return nil, nil, "", fmt.Errorf("pod %s has no container named %s", podName, container)
+		}
+	case pod.Annotations[defaultContainerAnnotation] != "":
+		container = pod.Annotations[defaultContainerAnnotation]
+	case len(pod.Spec.Containers) > 0:
+		container = pod.Spec.Containers[0].Name
+	}
+	return client, config, container, nil
+}
+
+// readTarFile reads the first entry of a tar stream, which must be a regular file, and
+// at most maxBytes of its content
+// readTarFile 读取 tar 流的第一个条目（必须是普通文件），最多读取其内容的 maxBytes 字节
+func readTarFile(r io.Reader, maxBytes int64) (*tar.Header, []byte, error) {
+	reader := tar.NewReader(r)
+	header, err := reader.Next()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("tar produced no output")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	switch header.Typeflag {
+	case tar.TypeReg:
+	case tar.TypeDir:
+		return nil, nil, fmt.Errorf("%s is a directory; only single files can be copied", header.Name)
+	case tar.TypeSymlink:
+		return nil, nil, fmt.Errorf("%s is a symbolic link to %s; copy the target instead", header.Name, header.Linkname)
+	default:
+		return nil, nil, fmt.Errorf("%s is not a regular file", header.Name)
+	}
+
+	content := make([]byte, min(header.Size, maxBytes))
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+	}
+	return header, content, nil
+}
+
+// tarFile returns a tar archive holding one file
+// tarFile 返回只包含一个文件的 tar 归档
+func tarFile(name string, content []byte) ([]byte, error) {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+// copyError picks the most telling reason of a failed copy: what tar printed, then the
+// exec error, then the archive error
+// copyError 选择复制失败最有说明性的原因：依次为 tar 的输出、exec 错误和归档错误
+func copyError(readErr, execErr error, output *cappedBuffer) error {
+	if message := strings.TrimSpace(output.String()); message != "" {
+		return errors.New(message)
+	}
+	if execErr != nil {
+		return execErr
+	}
+	return readErr
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest. It is safe
+// for concurrent use, as the executor may still write after the call returns.
+// cappedBuffer 保留写入的前 limit 个字节并丢弃其余部分。可并发使用，因为调用返回后执行器仍可能写入。
+type cappedBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int
+}
+
+// Write implements io.Writer
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// String returns the kept bytes
+// String 返回保留的字节
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
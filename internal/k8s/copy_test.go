@@ -0,0 +1,215 @@
+package k8s
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// execFunc 是 execInPod 的签名
+type execFunc = func(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error
+
+// withExec 在测试期间用 exec 替换 execInPod
+func withExec(t *testing.T, exec execFunc) {
+	t.Helper()
+	original := execInPod
+	execInPod = exec
+	t.Cleanup(func() { execInPod = original })
+}
+
+// newCopyOperations 返回包含运行中 Pod shop/app-0（容器 app 和 sidecar）且带有 REST 配置的资源操作
+func newCopyOperations(t *testing.T, annotations map[string]string) *ResourceOperations {
+	t.Helper()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "shop", Annotations: annotations},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	ro, _ := newFakeOperations(t, pod)
+	ro.clusterManager.configs["test"] = &rest.Config{Host: "https://10.0.0.1:6443"}
+	return ro
+}
+
+// TestValidateCopyPath 测试拒绝包含 ".." 的路径，以及上传路径必须是允许目录中的绝对路径
+func TestValidateCopyPath(t *testing.T) {
+	allowed := []string{"/tmp", "/data/"}
+	tests := []struct {
+		path    string
+		allowed []string
+		want    string
+		wantErr bool
+	}{
+		{"/var/log/app.log", nil, "/var/log/app.log", false},
+		{"logs/app.log", nil, "logs/app.log", false},
+		{"/tmp/../etc/passwd", nil, "", true},
+		{"..", nil, "", true},
+		{"/", nil, "", true},
+		{"", nil, "", true},
+		{"/tmp/app/config.yaml", allowed, "/tmp/app/config.yaml", false},
+		{"/data//seed.sql", allowed, "/data/seed.sql", false},
+		{"/tmp", allowed, "", true},
+		{"/tmpfile", allowed, "", true},
+		{"/etc/passwd", allowed, "", true},
+		{"tmp/app.conf", allowed, "", true},
+		{"/tmp/a/../../etc/passwd", allowed, "", true},
+		{"/etc/app.conf", []string{"/"}, "/etc/app.conf", false},
+	}
+	for _, tt := range tests {
+		got, err := ValidateCopyPath(tt.path, tt.allowed)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ValidateCopyPath(%q, %v) = %q, %v", tt.path, tt.allowed, got, err)
+		}
+		if tt.path != "" && err != nil && !errors.Is(err, ErrPathNotAllowed) {
+			t.Errorf("expected ErrPathNotAllowed for %q, got %v", tt.path, err)
+		}
+	}
+}
+
+// TestCopyFromPod 测试通过 tar 读取文件、检测 MIME 类型、默认容器，以及超过上限时截断
+func TestCopyFromPod(t *testing.T) {
+	content := strings.Repeat("line of text\n", 200)
+	var command []string
+	var container string
+	withExec(t, func(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, c string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		command, container = cmd, c
+		archive, err := tarFile("app.log", []byte(content))
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(archive)
+		return err
+	})
+	ro := newCopyOperations(t, nil)
+
+	file, data, err := ro.CopyFromPod(context.Background(), "shop", "app-0", "", "/var/log/app.log", 0, "test")
+	if err != nil {
+		t.Fatalf("CopyFromPod failed: %v", err)
+	}
+	if strings.Join(command, " ") != "tar cf - -C /var/log app.log" || container != "app" {
+		t.Errorf("unexpected command %q in container %q", command, container)
+	}
+	if string(data) != content || file.Truncated || file.Size != int64(len(content)) || file.Bytes != len(content) || file.MimeType != "text/plain; charset=utf-8" {
+		t.Errorf("unexpected file %+v", file)
+	}
+
+	file, data, err = ro.CopyFromPod(context.Background(), "shop", "app-0", "sidecar", "/var/log/app.log", 100, "test")
+	if err != nil {
+		t.Fatalf("CopyFromPod failed: %v", err)
+	}
+	if container != "sidecar" || string(data) != content[:100] || !file.Truncated || file.Bytes != 100 || file.Size != int64(len(content)) {
+		t.Errorf("expected the first 100 bytes of the sidecar file, got %+v", file)
+	}
+
+	if _, _, err := ro.CopyFromPod(context.Background(), "shop", "app-0", "db", "/var/log/app.log", 0, "test"); err == nil || !strings.Contains(err.Error(), "no container named db") {
+		t.Errorf("expected an error for a missing container, got %v", err)
+	}
+}
+
+// TestCopyFromPodStopsAtCap 测试读到上限后停止传输，而不是读完一个很大的文件
+func TestCopyFromPodStopsAtCap(t *testing.T) {
+	written := 0
+	withExec(t, func(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		writer := tar.NewWriter(stdout)
+		if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "huge.bin", Size: 1 << 40, Mode: 0644}); err != nil {
+			return err
+		}
+		chunk := make([]byte, 32<<10)
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			n, err := writer.Write(chunk)
+			written += n
+			if err != nil {
+				return err
+			}
+		}
+	})
+	ro := newCopyOperations(t, map[string]string{defaultContainerAnnotation: "sidecar"})
+
+	file, data, err := ro.CopyFromPod(context.Background(), "shop", "app-0", "", "/data/huge.bin", 1<<20, "test")
+	if err != nil {
+		t.Fatalf("CopyFromPod failed: %v", err)
+	}
+	if len(data) != 1<<20 || !file.Truncated || file.Size != 1<<40 || file.Container != "sidecar" || file.MimeType != "application/octet-stream" {
+		t.Errorf("unexpected file %+v", file)
+	}
+	if written > 2<<20 {
+		t.Errorf("expected the transfer to stop near the cap, %d bytes were written", written)
+	}
+}
+
+// TestCopyFromPodErrors 测试报告 tar 的错误输出，以及拒绝目录
+func TestCopyFromPodErrors(t *testing.T) {
+	withExec(t, func(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		if command[len(command)-1] == "conf.d" {
+			writer := tar.NewWriter(stdout)
+			return writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "conf.d/", Mode: 0755})
+		}
+		stderr.Write([]byte("tar: missing.log: No such file or directory\n"))
+		return errors.New("command terminated with non-zero exit code: exit status 2")
+	})
+	ro := newCopyOperations(t, nil)
+
+	_, _, err := ro.CopyFromPod(context.Background(), "shop", "app-0", "", "/var/log/missing.log", 0, "test")
+	if err == nil || !strings.Contains(err.Error(), "No such file or directory") {
+		t.Errorf("expected the tar error, got %v", err)
+	}
+	_, _, err = ro.CopyFromPod(context.Background(), "shop", "app-0", "", "/etc/conf.d", 0, "test")
+	if err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("expected a directory error, got %v", err)
+	}
+	if _, _, err := ro.CopyFromPod(context.Background(), "shop", "app-0", "", "../secret", 0, "test"); !errors.Is(err, ErrPathNotAllowed) {
+		t.Errorf("expected ErrPathNotAllowed, got %v", err)
+	}
+}
+
+// TestCopyToPod 测试将单文件 tar 归档写入目标目录，以及拒绝允许目录之外的目标
+func TestCopyToPod(t *testing.T) {
+	var command []string
+	var name, received string
+	withExec(t, func(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, pod, container string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) error {
+		command = cmd
+		reader := tar.NewReader(stdin)
+		header, err := reader.Next()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(reader)
+		name, received = header.Name, string(data)
+		return err
+	})
+	ro := newCopyOperations(t, nil)
+
+	upload, err := ro.CopyToPod(context.Background(), "shop", "app-0", "", "/tmp/app/config.yaml", []byte("debug: true\n"), nil, "test")
+	if err != nil {
+		t.Fatalf("CopyToPod failed: %v", err)
+	}
+	if strings.Join(command, " ") != "tar xf - -C /tmp/app" || name != "config.yaml" || received != "debug: true\n" {
+		t.Errorf("unexpected command %q writing %s=%q", command, name, received)
+	}
+	if upload.Path != "/tmp/app/config.yaml" || upload.Bytes != 12 || upload.Container != "app" {
+		t.Errorf("unexpected upload %+v", upload)
+	}
+
+	command = nil
+	for _, path := range []string{"/etc/passwd", "/tmp/../etc/passwd", "config.yaml"} {
+		if _, err := ro.CopyToPod(context.Background(), "shop", "app-0", "", path, []byte("x"), nil, "test"); !errors.Is(err, ErrPathNotAllowed) {
+			t.Errorf("expected ErrPathNotAllowed for %s, got %v", path, err)
+		}
+	}
+	if _, err := ro.CopyToPod(context.Background(), "shop", "app-0", "", "/srv/www/index.html", []byte("x"), []string{"/srv/www"}, "test"); err != nil {
+		t.Errorf("expected a configured directory to be allowed, got %v", err)
+	}
+	if command == nil {
+		t.Error("expected the upload to the configured directory to run tar")
+	}
+}
@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultDebugImage is the image of an ephemeral debug container when none is given
+// DefaultDebugImage 未指定镜像时临时调试容器使用的镜像
+const DefaultDebugImage = "busybox"
+
+// ErrEphemeralContainersUnsupported is returned when the cluster does not serve the
+// pods/ephemeralcontainers subresource (Kubernetes before 1.23 or the feature gate is off)
+// ErrEphemeralContainersUnsupported 表示集群不提供 pods/ephemeralcontainers 子资源（Kubernetes 1.23 之前或关闭了特性开关）
+var ErrEphemeralContainersUnsupported = errors.New("ephemeral containers are not available on this cluster: the pods/ephemeralcontainers subresource requires Kubernetes 1.23+ (or the EphemeralContainers feature gate on older versions); use a debug pod instead")
+
+// DebugOptions describes the ephemeral container added by DebugPod
+// DebugOptions 描述 DebugPod 添加的临时容器
+type DebugOptions struct {
+	// Image defaults to DefaultDebugImage
+	// Image 默认为 DefaultDebugImage
+	Image string
+	// ContainerName defaults to a generated "debugger-xxxxx" name
+	// ContainerName 默认为生成的 "debugger-xxxxx" 名称
+	ContainerName string
+	// Command overrides the image entrypoint
+	// Command 覆盖镜像的 entrypoint
+	Command []string
+}
+
+// DebugPod adds an ephemeral container to a running pod, like kubectl debug -it. The
+// container gets stdin and a TTY so it stays up to be exec'd or attached into.
+// DebugPod 向运行中的 Pod 添加临时容器，与 kubectl debug -it 相同。
+// 容器分配 stdin 和 TTY，以便保持运行供 exec 或 attach 进入。
+func (ro *ResourceOperations) DebugPod(ctx context.Context, namespace, podName string, opts DebugOptions, clusterName string) (*types.DebugContainer, error) {
+	if podName == "" {
+		return nil, fmt.Errorf("pod name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("pod %s is %s; ephemeral containers can only be added to a running pod", podName, pod.Status.Phase)
+	}
+
+	image := opts.Image
+	if image == "" {
+		image = DefaultDebugImage
+	}
+	name := opts.ContainerName
+	if name == "" {
+		name = "debugger-" + utilrand.String(5)
+	}
+	if podHasContainer(pod, name) {
+		return nil, fmt.Errorf("pod %s already has a container named %s", podName, name)
+	}
+
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			Command:                  opts.Command,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			Stdin:                    true,
+			TTY:                      true,
+		},
+	})
+
+	if _, err := client.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{}); err != nil {
+		if ephemeralContainersUnsupported(err) {
+			return nil, ErrEphemeralContainersUnsupported
+		}
+		return nil, fmt.Errorf("failed to add ephemeral container: %w", err)
+	}
+
+	return &types.DebugContainer{
+		Pod:           podName,
+		Namespace:     namespace,
+		Container:     name,
+		Image:         image,
+		Command:       opts.Command,
+		AttachCommand: fmt.Sprintf("kubectl attach -it %s -c %s -n %s", podName, name, namespace),
+		ExecCommand:   fmt.Sprintf("kubectl exec -it %s -c %s -n %s -- sh", podName, name, namespace),
+	}, nil
+}
+
+// podHasContainer reports whether any container of the pod, including init and
+// ephemeral ones, is named name
+// podHasContainer 判断 Pod 中是否有名为 name 的容器（包括 init 和临时容器）
+func podHasContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ephemeralContainersUnsupported recognizes the errors of API servers without the
+// ephemeralcontainers subresource: 404 or 405 for the subresource itself, or a
+// validation error naming the disabled feature gate
+// ephemeralContainersUnsupported 识别不支持 ephemeralcontainers 子资源的 API server 返回的错误：
+// 子资源返回 404 或 405，或提示特性开关未启用的校验错误
+func ephemeralContainersUnsupported(err error) bool {
+	if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		return true
+	}
+	return (apierrors.IsInvalid(err) || apierrors.IsForbidden(err)) && strings.Contains(err.Error(), "EphemeralContainers")
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeDebugAPIServer 模拟 Pod 的 GET 和 ephemeralcontainers 子资源：updateStatus 为子资源返回的状态码，
+// 返回值函数给出子资源收到的请求方法和 Pod
+func fakeDebugAPIServer(t *testing.T, phase corev1.PodPhase, updateStatus int) (*httptest.Server, func() (string, *corev1.Pod)) {
+	t.Helper()
+
+	var method string
+	var updated *corev1.Pod
+	responses := map[string]interface{}{
+		"/api/v1/namespaces/default/pods/web": &corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "nginx"}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		},
+	}
+	server := fakeAPIServer(t, responses, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/api/v1/namespaces/default/pods/web/ephemeralcontainers" {
+			return false
+		}
+		method = r.Method
+		if updateStatus != http.StatusOK {
+			w.WriteHeader(updateStatus)
+			json.NewEncoder(w).Encode(metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonNotFound,
+				Message:  "the server could not find the requested resource",
+				Code:     int32(updateStatus),
+			})
+			return true
+		}
+		body, _ := io.ReadAll(r.Body)
+		updated = &corev1.Pod{}
+		if err := json.Unmarshal(body, updated); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return true
+		}
+		w.Write(body)
+		return true
+	})
+	return server, func() (string, *corev1.Pod) { return method, updated }
+}
+
+// TestDebugPod 测试 ephemeralcontainers 子资源更新请求的内容和返回的进入命令
+func TestDebugPod(t *testing.T) {
+	server, update := fakeDebugAPIServer(t, corev1.PodRunning, http.StatusOK)
+	ro := newServerOperations(t, server)
+
+	result, err := ro.DebugPod(context.Background(), "default", "web", DebugOptions{
+		ContainerName: "dbg",
+		Command:       []string{"sh", "-c", "sleep 3600"},
+	}, "test")
+	if err != nil {
+		t.Fatalf("DebugPod failed: %v", err)
+	}
+
+	method, pod := update()
+	if method != http.MethodPut || pod == nil {
+		t.Fatalf("expected a PUT to the ephemeralcontainers subresource, got %q", method)
+	}
+	if len(pod.Spec.Containers) != 1 || len(pod.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("unexpected pod spec: %+v", pod.Spec)
+	}
+	ec := pod.Spec.EphemeralContainers[0]
+	if ec.Name != "dbg" || ec.Image != DefaultDebugImage || !ec.Stdin || !ec.TTY ||
+		!reflect.DeepEqual(ec.Command, []string{"sh", "-c", "sleep 3600"}) {
+		t.Errorf("unexpected ephemeral container: %+v", ec)
+	}
+
+	if result.Container != "dbg" || result.Image != DefaultDebugImage ||
+		result.ExecCommand != "kubectl exec -it web -c dbg -n default -- sh" ||
+		result.AttachCommand != "kubectl attach -it web -c dbg -n default" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+// TestDebugPodGeneratedName 测试未指定容器名时生成 debugger-xxxxx 名称，以及容器名冲突
+func TestDebugPodGeneratedName(t *testing.T) {
+	server, update := fakeDebugAPIServer(t, corev1.PodRunning, http.StatusOK)
+	ro := newServerOperations(t, server)
+
+	result, err := ro.DebugPod(context.Background(), "default", "web", DebugOptions{Image: "nicolaka/netshoot"}, "test")
+	if err != nil {
+		t.Fatalf("DebugPod failed: %v", err)
+	}
+	if _, pod := update(); !strings.HasPrefix(result.Container, "debugger-") || pod.Spec.EphemeralContainers[0].Name != result.Container || result.Image != "nicolaka/netshoot" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := ro.DebugPod(context.Background(), "default", "web", DebugOptions{ContainerName: "app"}, "test"); err == nil {
+		t.Errorf("expected an error for a container name that is already used")
+	}
+}
+
+// TestDebugPodErrors 测试 Pod 未运行以及集群不支持临时容器时的错误
+func TestDebugPodErrors(t *testing.T) {
+	server, _ := fakeDebugAPIServer(t, corev1.PodPending, http.StatusOK)
+	if _, err := newServerOperations(t, server).DebugPod(context.Background(), "default", "web", DebugOptions{}, "test"); err == nil || !strings.Contains(err.Error(), "running pod") {
+		t.Errorf("unexpected error for a pending pod: %v", err)
+	}
+
+	server, _ = fakeDebugAPIServer(t, corev1.PodRunning, http.StatusNotFound)
+	if _, err := newServerOperations(t, server).DebugPod(context.Background(), "default", "web", DebugOptions{}, "test"); !errors.Is(err, ErrEphemeralContainersUnsupported) {
+		t.Errorf("expected ErrEphemeralContainersUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DeleteResource deletes one object, like 'kubectl delete', with background propagation
+// so the objects it owns are garbage collected. Namespaces are refused: DeleteNamespace
+// reports what they still hold. Checking ConfigMaps and Secrets for references is up to
+// the caller, see CheckReferences
+// DeleteResource 与 'kubectl delete' 一样删除一个对象，使用后台级联删除，由垃圾回收删除其拥有的对象。
+// 拒绝删除命名空间：DeleteNamespace 会报告其中仍有的对象。ConfigMap 和 Secret 的引用检查由调用者负责，见 CheckReferences
+func (ro *ResourceOperations) DeleteResource(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string) (*types.ResourceDeletion, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if resourceType == ResourceTypeNamespaces || resourceType == ResourceTypeNamespace {
+		return nil, fmt.Errorf("namespaces are deleted with delete_namespace")
+	}
+	factory, ok := metadataClients[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type for deletion: %s", resourceType)
+	}
+	if IsClusterScoped(resourceType) {
+		namespace = ""
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if err := factory(client, namespace).delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+		return nil, fmt.Errorf("failed to delete %s %s: %w", resourceType, name, err)
+	}
+	return &types.ResourceDeletion{
+		ResourceType: string(resourceType),
+		Namespace:    namespace,
+		Name:         name,
+		Message:      "deleted",
+	}, nil
+}
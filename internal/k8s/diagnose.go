@@ -0,0 +1,561 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// maxDiagnosisEvents is the number of most recent pod events ExplainPodFailure keeps
+	// maxDiagnosisEvents 为 ExplainPodFailure 保留的 Pod 最近事件数
+	maxDiagnosisEvents = 20
+	// diagnosisLogLines is the number of previous-container log lines ExplainPodFailure reads
+	// diagnosisLogLines 为 ExplainPodFailure 读取的上一个容器实例的日志行数
+	diagnosisLogLines = 50
+	// maxEvidenceLogLines bounds the log lines a rule quotes as evidence
+	// maxEvidenceLogLines 限制规则作为证据引用的日志行数
+	maxEvidenceLogLines = 3
+)
+
+// containerEvidence is what the diagnosis rules look at for one container: its
+// status, its memory limit, the pod events about it and its previous logs
+// containerEvidence 是诊断规则针对单个容器检查的内容：容器状态、内存限制、与其相关的 Pod 事件和上一个实例的日志
+type containerEvidence struct {
+	status      types.ContainerDiagnosis
+	memoryLimit string
+	events      []corev1.Event
+}
+
+// diagnosisRule maps a failure pattern to its likely cause. Match returns the evidence
+// supporting the rule, or nil when the rule does not apply
+// diagnosisRule 将一种故障模式映射到可能原因，Match 返回支持该规则的证据，不适用时返回 nil
+type diagnosisRule struct {
+	Name  string
+	Match func(e *containerEvidence) []string
+	Cause string
+}
+
+// diagnosisRules are tried in order for every container and the first match wins,
+// so specific rules come before the generic ones
+// diagnosisRules 对每个容器按顺序尝试，第一个命中的规则生效，因此具体的规则排在通用规则之前
+var diagnosisRules = []diagnosisRule{
+	{
+		Name:  "oom-killed",
+		Match: matchOOMKilled,
+		Cause: "The container used more memory than its limit and was killed by the kernel OOM killer; raise the memory limit or reduce the application's memory use",
+	},
+	{
+		Name:  "liveness-probe",
+		Match: matchLivenessProbe,
+		Cause: "The liveness probe keeps failing so the kubelet kills and restarts the container; check the probe's path, port and timeouts, and add a startupProbe if the application is slow to start",
+	},
+	{
+		Name:  "registry-auth",
+		Match: matchImagePull(registryAuthPattern),
+		Cause: "The registry rejected the pull because of missing or invalid credentials; check the imagePullSecrets of the pod or its service account and the credentials they hold",
+	},
+	{
+		Name:  "image-not-found",
+		Match: matchImagePull(imageNotFoundPattern),
+		Cause: "The image or tag does not exist in the registry; check the image name and tag",
+	},
+	{
+		Name:  "missing-config",
+		Match: matchMissingConfig,
+		Cause: "The pod references a ConfigMap or Secret, or a key in one, that does not exist; create it or fix the reference in the pod spec",
+	},
+	{
+		Name:  "bad-config",
+		Match: matchBadConfig,
+		Cause: "The application exits on startup because of invalid or missing configuration; check the ConfigMaps, Secrets, environment variables and arguments it reads",
+	},
+	{
+		Name:  "crash-exit",
+		Match: matchCrashExit,
+		Cause: "The application keeps exiting with an error; read the previous logs for the failure",
+	},
+}
+
+var (
+	livenessProbePattern = regexp.MustCompile(`Liveness probe failed`)
+	registryAuthPattern  = regexp.MustCompile(`(?i)\b401\b|unauthorized|authentication required|pull access denied|no basic auth credentials|authorization failed`)
+	imageNotFoundPattern = regexp.MustCompile(`(?i)not found|manifest unknown|name unknown|does not exist`)
+	missingConfigPattern = regexp.MustCompile(`(?i)(configmap|secret) "[^"]*" not found|couldn't find key`)
+	configErrorPattern   = regexp.MustCompile(`(?i)\bconfig(uration)?\b.*\b(error|invalid|missing|not found|failed|cannot|unable)\b|\b(error|invalid|missing|failed|cannot|unable)\b.*\bconfig(uration)?\b|missing required|environment variable \S+ (is )?not set|unknown (flag|option|field)|yaml: |json: cannot unmarshal|failed to parse`)
+)
+
+// exitCodeMeanings explains the exit codes with a conventional meaning
+// exitCodeMeanings 解释具有约定含义的退出码
+var exitCodeMeanings = map[int32]string{
+	1:   "the application reported an error",
+	126: "the command is not executable",
+	127: "the command was not found in the image",
+	137: "killed by SIGKILL",
+	139: "segmentation fault",
+	143: "terminated by SIGTERM",
+}
+
+// terminated returns the reason and exit code of the current or, failing that, the
+// last termination of the container
+// terminated 返回容器当前或上一次终止的原因和退出码
+func (e *containerEvidence) terminated() (string, int32, bool) {
+	if e.status.ExitCode != nil {
+		return e.status.Reason, *e.status.ExitCode, true
+	}
+	if e.status.LastExitCode != nil {
+		return e.status.LastReason, *e.status.LastExitCode, true
+	}
+	return "", 0, false
+}
+
+// waiting reports whether the container is waiting for one of the reasons
+// waiting 判断容器是否因给定原因之一处于等待状态
+func (e *containerEvidence) waiting(reasons ...string) bool {
+	if e.status.State != "waiting" {
+		return false
+	}
+	for _, reason := range reasons {
+		if e.status.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// eventsMatching returns the waiting message and the events about the container whose
+// message matches the pattern
+// eventsMatching 返回匹配模式的等待消息和与容器相关的事件
+func (e *containerEvidence) eventsMatching(pattern *regexp.Regexp) []string {
+	var matches []string
+	if e.status.State == "waiting" && pattern.MatchString(e.status.Message) {
+		matches = append(matches, fmt.Sprintf("waiting message: %s", e.status.Message))
+	}
+	for _, event := range e.events {
+		if pattern.MatchString(event.Message) {
+			matches = append(matches, fmt.Sprintf("event %s: %s", event.Reason, event.Message))
+		}
+	}
+	return matches
+}
+
+// logLinesMatching returns up to maxEvidenceLogLines previous log lines matching the pattern
+// logLinesMatching 返回最多 maxEvidenceLogLines 行匹配模式的上一个实例日志
+func (e *containerEvidence) logLinesMatching(pattern *regexp.Regexp) []string {
+	var matches []string
+	for _, line := range strings.Split(e.status.PreviousLogs, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && pattern.MatchString(line) {
+			matches = append(matches, "log: "+line)
+			if len(matches) == maxEvidenceLogLines {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// exitEvidence describes how the container last terminated
+// exitEvidence 描述容器上一次如何终止
+func exitEvidence(reason string, exitCode int32) string {
+	evidence := fmt.Sprintf("terminated with exit code %d", exitCode)
+	if reason != "" {
+		evidence += " (" + reason + ")"
+	}
+	if meaning, ok := exitCodeMeanings[exitCode]; ok {
+		evidence += ": " + meaning
+	}
+	return evidence
+}
+
+// matchOOMKilled matches a container whose current or last termination was OOMKilled
+// matchOOMKilled 匹配当前或上一次终止原因为 OOMKilled 的容器
+func matchOOMKilled(e *containerEvidence) []string {
+	if !e.status.OOMKilled {
+		return nil
+	}
+	var evidence []string
+	if e.status.Reason == "OOMKilled" && e.status.ExitCode != nil {
+		evidence = append(evidence, exitEvidence(e.status.Reason, *e.status.ExitCode))
+	} else if e.status.LastExitCode != nil {
+		evidence = append(evidence, "last "+exitEvidence(e.status.LastReason, *e.status.LastExitCode))
+	}
+	if e.memoryLimit != "" {
+		evidence = append(evidence, "memory limit "+e.memoryLimit)
+	}
+	return evidence
+}
+
+// matchLivenessProbe matches a container killed after failing liveness probes
+// matchLivenessProbe 匹配存活探针失败后被杀死的容器
+func matchLivenessProbe(e *containerEvidence) []string {
+	if e.status.RestartCount == 0 {
+		return nil
+	}
+	probes := e.eventsMatching(livenessProbePattern)
+	if len(probes) == 0 {
+		return nil
+	}
+	evidence := []string{fmt.Sprintf("%d restarts", e.status.RestartCount)}
+	if reason, exitCode, ok := e.terminated(); ok {
+		evidence = append(evidence, exitEvidence(reason, exitCode))
+	}
+	return append(evidence, probes...)
+}
+
+// matchImagePull returns a rule matching a container failing to pull its image with a
+// waiting message or event matching the pattern
+// matchImagePull 返回匹配拉取镜像失败、且等待消息或事件匹配模式的容器的规则
+func matchImagePull(pattern *regexp.Regexp) func(e *containerEvidence) []string {
+	return func(e *containerEvidence) []string {
+		if !e.waiting("ImagePullBackOff", "ErrImagePull") {
+			return nil
+		}
+		matches := e.eventsMatching(pattern)
+		if len(matches) == 0 {
+			return nil
+		}
+		return append([]string{"image " + e.status.Image}, matches...)
+	}
+}
+
+// matchMissingConfig matches a container that cannot be created because a referenced
+// ConfigMap, Secret or key is missing
+// matchMissingConfig 匹配因引用的 ConfigMap、Secret 或键不存在而无法创建的容器
+func matchMissingConfig(e *containerEvidence) []string {
+	if !e.waiting("CreateContainerConfigError") {
+		return nil
+	}
+	return e.eventsMatching(missingConfigPattern)
+}
+
+// matchBadConfig matches a crash-looping container whose previous logs report a
+// configuration error
+// matchBadConfig 匹配处于 CrashLoopBackOff 且上一个实例日志报告配置错误的容器
+func matchBadConfig(e *containerEvidence) []string {
+	if !e.waiting("CrashLoopBackOff") {
+		return nil
+	}
+	lines := e.logLinesMatching(configErrorPattern)
+	if len(lines) == 0 {
+		return nil
+	}
+	if reason, exitCode, ok := e.terminated(); ok {
+		return append([]string{exitEvidence(reason, exitCode)}, lines...)
+	}
+	return lines
+}
+
+// matchCrashExit matches any other crash-looping container, quoting its last log line
+// matchCrashExit 匹配其他处于 CrashLoopBackOff 的容器，并引用其最后一行日志
+func matchCrashExit(e *containerEvidence) []string {
+	if !e.waiting("CrashLoopBackOff") {
+		return nil
+	}
+	evidence := []string{fmt.Sprintf("%d restarts", e.status.RestartCount)}
+	if reason, exitCode, ok := e.terminated(); ok {
+		evidence = append(evidence, exitEvidence(reason, exitCode))
+	}
+	if logs := strings.TrimSpace(e.status.PreviousLogs); logs != "" {
+		evidence = append(evidence, "last log line: "+logs[strings.LastIndex(logs, "\n")+1:])
+	}
+	return evidence
+}
+
+// ExplainPodFailure gathers the container statuses, the last events and the previous
+// logs of the restarted containers of a pod and maps them to likely causes with
+// diagnosisRules
+// ExplainPodFailure 收集 Pod 的容器状态、最近的事件和重启过的容器上一个实例的日志，
+// 并通过 diagnosisRules 得出可能原因
+func (ro *ResourceOperations) ExplainPodFailure(ctx context.Context, namespace, podName, clusterName string) (*types.PodFailureExplanation, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+
+	filter := EventFilter{Kind: "Pod", Name: podName}
+	eventList, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: filter.fieldSelector()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	events := recentPodEvents(eventList.Items, pod)
+
+	containers := diagnoseContainers(pod)
+	for i := range containers {
+		c := &containers[i]
+		if c.RestartCount == 0 || c.LastExitCode == nil {
+			continue
+		}
+		tailLines := int64(diagnosisLogLines)
+		logs, err := ro.GetPodLogs(ctx, namespace, podName, PodLogOptions{Container: c.Name, TailLines: &tailLines, Previous: true}, clusterName)
+		if err != nil {
+			c.LogsError = err.Error()
+			continue
+		}
+		c.PreviousLogs = strings.TrimRight(logs, "\n")
+	}
+
+	return explainPodFailure(pod, containers, events), nil
+}
+
+// recentPodEvents returns the last maxDiagnosisEvents events about the pod, oldest first
+// recentPodEvents 返回与 Pod 相关的最近 maxDiagnosisEvents 个事件，按时间从早到晚排列
+func recentPodEvents(events []corev1.Event, pod *corev1.Pod) []corev1.Event {
+	var results []corev1.Event
+	for _, event := range events {
+		if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == pod.Name {
+			results = append(results, event)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return eventLastSeen(results[i]).Time.Before(eventLastSeen(results[j]).Time)
+	})
+	if len(results) > maxDiagnosisEvents {
+		results = results[len(results)-maxDiagnosisEvents:]
+	}
+	return results
+}
+
+// diagnoseContainers returns the state of the init containers and then the containers of the pod
+// diagnoseContainers 返回 Pod 的 init 容器和容器的状态，init 容器在前
+func diagnoseContainers(pod *corev1.Pod) []types.ContainerDiagnosis {
+	var results []types.ContainerDiagnosis
+	for _, status := range pod.Status.InitContainerStatuses {
+		c := containerDiagnosis(status)
+		c.Init = true
+		results = append(results, c)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		results = append(results, containerDiagnosis(status))
+	}
+	return results
+}
+
+// containerDiagnosis converts a container status
+// containerDiagnosis 转换容器状态
+func containerDiagnosis(status corev1.ContainerStatus) types.ContainerDiagnosis {
+	c := types.ContainerDiagnosis{
+		Name:         status.Name,
+		Image:        status.Image,
+		Ready:        status.Ready,
+		RestartCount: status.RestartCount,
+		State:        "unknown",
+	}
+	switch state := status.State; {
+	case state.Waiting != nil:
+		c.State = "waiting"
+		c.Reason = state.Waiting.Reason
+		c.Message = state.Waiting.Message
+	case state.Running != nil:
+		c.State = "running"
+	case state.Terminated != nil:
+		c.State = "terminated"
+		c.Reason = state.Terminated.Reason
+		c.Message = state.Terminated.Message
+		exitCode := state.Terminated.ExitCode
+		c.ExitCode = &exitCode
+		c.OOMKilled = state.Terminated.Reason == "OOMKilled"
+	}
+	if last := status.LastTerminationState.Terminated; last != nil {
+		c.LastReason = last.Reason
+		exitCode := last.ExitCode
+		c.LastExitCode = &exitCode
+		c.OOMKilled = c.OOMKilled || last.Reason == "OOMKilled"
+	}
+	return c
+}
+
+// explainPodFailure applies diagnosisRules to every container of the pod
+// explainPodFailure 对 Pod 的每个容器应用 diagnosisRules
+func explainPodFailure(pod *corev1.Pod, containers []types.ContainerDiagnosis, events []corev1.Event) *types.PodFailureExplanation {
+	explanation := &types.PodFailureExplanation{
+		Namespace:  pod.Namespace,
+		Pod:        pod.Name,
+		Status:     getPodStatus(pod),
+		Containers: containers,
+		Events:     []types.Event{},
+		Findings:   []types.FailureFinding{},
+	}
+	if explanation.Containers == nil {
+		explanation.Containers = []types.ContainerDiagnosis{}
+	}
+	for _, event := range events {
+		explanation.Events = append(explanation.Events, toEvent(event))
+	}
+
+	for _, c := range containers {
+		evidence := &containerEvidence{
+			status:      c,
+			memoryLimit: containerMemoryLimit(pod, c),
+			events:      containerEvents(events, c),
+		}
+		if finding := diagnoseContainer(evidence); finding != nil {
+			explanation.Findings = append(explanation.Findings, *finding)
+		}
+	}
+
+	explanation.Text = renderPodFailure(explanation)
+	return explanation
+}
+
+// diagnoseContainer returns the finding of the first rule matching the container, or nil
+// diagnoseContainer 返回第一个匹配该容器的规则得出的结论，没有匹配时返回 nil
+func diagnoseContainer(e *containerEvidence) *types.FailureFinding {
+	for _, rule := range diagnosisRules {
+		evidence := rule.Match(e)
+		if evidence == nil {
+			continue
+		}
+		return &types.FailureFinding{
+			Container: e.status.Name,
+			Rule:      rule.Name,
+			Symptom:   containerSymptom(e.status),
+			Evidence:  evidence,
+			Cause:     rule.Cause,
+		}
+	}
+	return nil
+}
+
+// containerMemoryLimit returns the memory limit of the container in the pod spec, or ""
+// containerMemoryLimit 返回 Pod spec 中容器的内存限制，未设置时返回空字符串
+func containerMemoryLimit(pod *corev1.Pod, c types.ContainerDiagnosis) string {
+	specs := pod.Spec.Containers
+	if c.Init {
+		specs = pod.Spec.InitContainers
+	}
+	for _, spec := range specs {
+		if spec.Name != c.Name {
+			continue
+		}
+		if limit, ok := spec.Resources.Limits[corev1.ResourceMemory]; ok {
+			return limit.String()
+		}
+	}
+	return ""
+}
+
+// containerEvents returns the pod-level events and the events about the container
+// containerEvents 返回 Pod 级别的事件和与该容器相关的事件
+func containerEvents(events []corev1.Event, c types.ContainerDiagnosis) []corev1.Event {
+	fieldPath := fmt.Sprintf("spec.containers{%s}", c.Name)
+	if c.Init {
+		fieldPath = fmt.Sprintf("spec.initContainers{%s}", c.Name)
+	}
+	var results []corev1.Event
+	for _, event := range events {
+		if event.InvolvedObject.FieldPath == "" || event.InvolvedObject.FieldPath == fieldPath {
+			results = append(results, event)
+		}
+	}
+	return results
+}
+
+// containerSymptom describes the observable state of a container
+// containerSymptom 描述容器可观察到的状态
+func containerSymptom(c types.ContainerDiagnosis) string {
+	var symptom string
+	switch c.State {
+	case "waiting":
+		symptom = "waiting in " + c.Reason
+	case "terminated":
+		symptom = fmt.Sprintf("terminated with exit code %d", *c.ExitCode)
+		if c.Reason != "" {
+			symptom += " (" + c.Reason + ")"
+		}
+	case "running":
+		symptom = "running"
+		if !c.Ready {
+			symptom += " but not ready"
+		}
+	default:
+		symptom = "in an unknown state"
+	}
+	if c.RestartCount > 0 {
+		symptom += fmt.Sprintf(" after %d restarts", c.RestartCount)
+	}
+	if c.LastExitCode != nil && c.State != "terminated" {
+		symptom += fmt.Sprintf(", last terminated with exit code %d", *c.LastExitCode)
+		if c.LastReason != "" {
+			symptom += " (" + c.LastReason + ")"
+		}
+	}
+	return symptom
+}
+
+// renderPodFailure renders the explanation as a report of containers, findings as
+// symptom, evidence and likely cause, events and previous logs
+// renderPodFailure 将诊断结果渲染为报告：容器、以"症状、证据、可能原因"列出的结论、事件和上一个实例的日志
+func renderPodFailure(explanation *types.PodFailureExplanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pod %s/%s: %s\n", explanation.Namespace, explanation.Pod, explanation.Status)
+
+	b.WriteString("\nContainers:\n")
+	if len(explanation.Containers) == 0 {
+		b.WriteString("  none reported yet\n")
+	}
+	for _, c := range explanation.Containers {
+		name := c.Name
+		if c.Init {
+			name += " (init)"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, containerSymptom(c))
+	}
+
+	b.WriteString("\nFindings:\n")
+	if len(explanation.Findings) == 0 {
+		b.WriteString("  No known failure pattern matched; check the events and logs\n")
+	}
+	for _, finding := range explanation.Findings {
+		fmt.Fprintf(&b, "  %s [%s]\n", finding.Container, finding.Rule)
+		fmt.Fprintf(&b, "    Symptom: %s\n", finding.Symptom)
+		for _, evidence := range finding.Evidence {
+			fmt.Fprintf(&b, "    Evidence: %s\n", evidence)
+		}
+		fmt.Fprintf(&b, "    Likely cause: %s\n", finding.Cause)
+	}
+
+	if len(explanation.Events) > 0 {
+		b.WriteString("\nRecent events:\n")
+		for _, event := range explanation.Events {
+			fmt.Fprintf(&b, "  %s %s (x%d): %s\n", event.Type, event.Reason, event.Count, event.Message)
+		}
+	}
+
+	for _, c := range explanation.Containers {
+		switch {
+		case c.LogsError != "":
+			fmt.Fprintf(&b, "\nPrevious logs of %s: unavailable: %s\n", c.Name, c.LogsError)
+		case c.PreviousLogs != "":
+			fmt.Fprintf(&b, "\nPrevious logs of %s:\n", c.Name)
+			for _, line := range strings.Split(c.PreviousLogs, "\n") {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,205 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// containerEvent 返回关于容器 app 的事件
+func containerEvent(reason, message string) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "app-0", FieldPath: "spec.containers{app}"},
+		Reason:         reason,
+		Message:        message,
+	}
+}
+
+// TestDiagnosisRules 逐条测试诊断规则：每个用例的证据应命中期望的规则，并且不被排在前面的规则抢先匹配
+func TestDiagnosisRules(t *testing.T) {
+	exit := func(code int32) *int32 { return &code }
+
+	tests := []struct {
+		name     string
+		evidence containerEvidence
+		wantRule string
+		want     string
+	}{
+		{
+			name: "exit 137 with OOMKilled points at the memory limit",
+			evidence: containerEvidence{
+				status:      types.ContainerDiagnosis{Name: "app", State: "waiting", Reason: "CrashLoopBackOff", RestartCount: 3, LastReason: "OOMKilled", LastExitCode: exit(137), OOMKilled: true},
+				memoryLimit: "256Mi",
+			},
+			wantRule: "oom-killed",
+			want:     "memory limit 256Mi",
+		},
+		{
+			name: "restarts with failing liveness probe",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{Name: "app", State: "running", RestartCount: 2, LastReason: "Error", LastExitCode: exit(137)},
+				events: []corev1.Event{containerEvent("Unhealthy", "Liveness probe failed: HTTP probe failed with statuscode: 500")},
+			},
+			wantRule: "liveness-probe",
+			want:     "Liveness probe failed",
+		},
+		{
+			name: "ImagePullBackOff with a 401 event is registry auth",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{Name: "app", Image: "registry.example.com/app:1.0", State: "waiting", Reason: "ImagePullBackOff"},
+				events: []corev1.Event{containerEvent("Failed", `Failed to pull image "registry.example.com/app:1.0": unexpected status code 401 Unauthorized`)},
+			},
+			wantRule: "registry-auth",
+			want:     "401 Unauthorized",
+		},
+		{
+			name: "ErrImagePull with manifest unknown is a missing image",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{Name: "app", Image: "nginx:9.9", State: "waiting", Reason: "ErrImagePull", Message: "rpc error: manifest for nginx:9.9 not found: manifest unknown"},
+			},
+			wantRule: "image-not-found",
+			want:     "waiting message: rpc error",
+		},
+		{
+			name: "CreateContainerConfigError with a missing configmap",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{Name: "app", State: "waiting", Reason: "CreateContainerConfigError", Message: `configmap "app-config" not found`},
+			},
+			wantRule: "missing-config",
+			want:     `configmap "app-config" not found`,
+		},
+		{
+			name: "CrashLoopBackOff with a config error in the logs is bad config",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{
+					Name: "app", State: "waiting", Reason: "CrashLoopBackOff", RestartCount: 5, LastReason: "Error", LastExitCode: exit(1),
+					PreviousLogs: "starting app\nerror: invalid config: missing field \"database.url\"\nexiting",
+				},
+			},
+			wantRule: "bad-config",
+			want:     `log: error: invalid config: missing field "database.url"`,
+		},
+		{
+			name: "other CrashLoopBackOff quotes the exit code and last log line",
+			evidence: containerEvidence{
+				status: types.ContainerDiagnosis{
+					Name: "app", State: "waiting", Reason: "CrashLoopBackOff", RestartCount: 5, LastReason: "Error", LastExitCode: exit(127),
+					PreviousLogs: "exec: \"/app/server\": not found",
+				},
+			},
+			wantRule: "crash-exit",
+			want:     "exit code 127 (Error): the command was not found in the image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := diagnoseContainer(&tt.evidence)
+			if finding == nil {
+				t.Fatalf("expected rule %s to match", tt.wantRule)
+			}
+			if finding.Rule != tt.wantRule || finding.Container != "app" || finding.Cause == "" {
+				t.Fatalf("unexpected finding %+v", finding)
+			}
+			if evidence := strings.Join(finding.Evidence, "\n"); !strings.Contains(evidence, tt.want) {
+				t.Errorf("expected evidence to contain %q, got:\n%s", tt.want, evidence)
+			}
+		})
+	}
+}
+
+// TestDiagnosisRulesNoMatch 测试健康容器、仍在拉取镜像的容器以及与其他容器相关的事件不会得出结论
+func TestDiagnosisRulesNoMatch(t *testing.T) {
+	healthy := &containerEvidence{status: types.ContainerDiagnosis{Name: "app", State: "running", Ready: true}}
+	if finding := diagnoseContainer(healthy); finding != nil {
+		t.Errorf("expected no finding for a healthy container, got %+v", finding)
+	}
+
+	creating := &containerEvidence{status: types.ContainerDiagnosis{Name: "app", State: "waiting", Reason: "ContainerCreating"}}
+	if finding := diagnoseContainer(creating); finding != nil {
+		t.Errorf("expected no finding for a container being created, got %+v", finding)
+	}
+
+	events := []corev1.Event{
+		{InvolvedObject: corev1.ObjectReference{FieldPath: "spec.containers{sidecar}"}, Message: "Liveness probe failed"},
+		{InvolvedObject: corev1.ObjectReference{}, Message: "Successfully assigned shop/app-0 to node-1"},
+		containerEvent("Unhealthy", "Liveness probe failed"),
+	}
+	got := containerEvents(events, types.ContainerDiagnosis{Name: "app"})
+	if len(got) != 2 || got[0].Message != events[1].Message || got[1].Message != events[2].Message {
+		t.Errorf("expected the pod-level and app events, got %+v", got)
+	}
+}
+
+// TestExplainPodFailure 测试收集容器状态、按时间保留最近的事件、读取重启过的容器的上一个实例日志，以及文本报告
+func TestExplainPodFailure(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "shop"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "app",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "app",
+				RestartCount:         4,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}},
+		},
+	}
+	objects := []runtime.Object{pod}
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 25; i++ {
+		event := containerEvent("BackOff", "Back-off restarting failed container")
+		event.Name = fmt.Sprintf("app-0.%d", i)
+		event.Namespace = "shop"
+		event.Count = int32(i + 1)
+		event.LastTimestamp = metav1.NewTime(start.Add(time.Duration(24-i) * time.Minute))
+		objects = append(objects, &event)
+	}
+	other := containerEvent("Killing", "other pod")
+	other.Name, other.Namespace, other.InvolvedObject.Name = "other", "shop", "app-1"
+	objects = append(objects, &other)
+
+	ro, _ := newFakeOperations(t, objects...)
+	explanation, err := ro.ExplainPodFailure(context.Background(), "shop", "app-0", "test")
+	if err != nil {
+		t.Fatalf("ExplainPodFailure failed: %v", err)
+	}
+
+	if len(explanation.Events) != maxDiagnosisEvents || explanation.Events[0].Count != 20 || explanation.Events[19].Count != 1 {
+		t.Errorf("expected the 20 most recent events oldest first, got %+v", explanation.Events)
+	}
+	c := explanation.Containers[0]
+	if c.PreviousLogs != "fake logs" || !c.OOMKilled || *c.LastExitCode != 137 {
+		t.Errorf("unexpected container %+v", c)
+	}
+	if len(explanation.Findings) != 1 || explanation.Findings[0].Rule != "oom-killed" {
+		t.Fatalf("unexpected findings %+v", explanation.Findings)
+	}
+	for _, want := range []string{
+		"Pod shop/app-0: CrashLoopBackOff",
+		"  app: waiting in CrashLoopBackOff after 4 restarts, last terminated with exit code 137 (OOMKilled)",
+		"  app [oom-killed]\n    Symptom: waiting in CrashLoopBackOff after 4 restarts",
+		"    Evidence: last terminated with exit code 137 (OOMKilled): killed by SIGKILL\n    Evidence: memory limit 128Mi\n    Likely cause: The container used more memory",
+		"Previous logs of app:\n  fake logs",
+	} {
+		if !strings.Contains(explanation.Text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, explanation.Text)
+		}
+	}
+
+	if _, err := ro.ExplainPodFailure(context.Background(), "shop", "missing", "test"); err == nil {
+		t.Error("expected an error for a missing pod")
+	}
+}
@@ -0,0 +1,169 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+// diffContextLines 每处变更前后显示的未修改行数
+const diffContextLines = 3
+
+// diffIgnoredMetadataFields are server-populated metadata fields dropped before diffing
+// diffIgnoredMetadataFields 对比前移除的由服务器填充的元数据字段
+var diffIgnoredMetadataFields = []string{"managedFields", "resourceVersion", "creationTimestamp", "uid", "generation", "selfLink"}
+
+// lastAppliedAnnotation is written by kubectl apply and only adds noise to diffs
+// lastAppliedAnnotation 由 kubectl apply 写入，只会给对比结果增加噪音
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// DiffResource compares a manifest (YAML or JSON) against the live object with the
+// same GVK, namespace and name, and returns a unified diff. It is read-only.
+// DiffResource 将清单（YAML 或 JSON）与具有相同 GVK、命名空间和名称的线上对象进行对比，
+// 返回 unified diff。该操作是只读的。
+func (ro *ResourceOperations) DiffResource(ctx context.Context, manifest, clusterName string) (string, error) {
+	desired, err := parseManifest(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	dynamicClient, _, err := ro.clusterManager.GetDynamicClientForCluster(clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	gvk := desired.GroupVersionKind()
+	mapping, err := ro.clusterManager.RESTMapping(clusterName, gvk)
+	if err != nil {
+		return "", fmt.Errorf("unknown resource kind %s: %w", gvk.String(), err)
+	}
+
+	var live *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if desired.GetNamespace() == "" {
+			desired.SetNamespace("default")
+		}
+		live, err = dynamicClient.Resource(mapping.Resource).Namespace(desired.GetNamespace()).Get(ctx, desired.GetName(), metav1.GetOptions{})
+	} else {
+		live, err = dynamicClient.Resource(mapping.Resource).Get(ctx, desired.GetName(), metav1.GetOptions{})
+	}
+
+	if apierrors.IsNotFound(err) {
+		return diffObjects(nil, desired)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get live %s %s: %w", desired.GetKind(), objectRef(desired), err)
+	}
+	return diffObjects(live, desired)
+}
+
+// parseManifest decodes a single YAML or JSON manifest into an unstructured object
+// parseManifest 将单个 YAML 或 JSON 清单解码为 unstructured 对象
+func parseManifest(manifest string) (*unstructured.Unstructured, error) {
+	data, err := yaml.YAMLToJSON([]byte(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("invalid manifest: metadata.name is required")
+	}
+	return obj, nil
+}
+
+// diffObjects renders a unified diff from live to desired after normalizing both.
+// A nil live object means the resource would be created.
+// diffObjects 规范化两个对象后生成从线上对象到期望对象的 unified diff，live 为 nil 表示资源将被创建
+func diffObjects(live, desired *unstructured.Unstructured) (string, error) {
+	desiredYAML, err := normalizedYAML(desired)
+	if err != nil {
+		return "", err
+	}
+
+	if live == nil {
+		return fmt.Sprintf("%s %s does not exist and would be created:\n\n%s", desired.GetKind(), objectRef(desired), desiredYAML), nil
+	}
+
+	liveYAML, err := normalizedYAML(live)
+	if err != nil {
+		return "", err
+	}
+	if liveYAML == desiredYAML {
+		return fmt.Sprintf("No differences for %s %s", desired.GetKind(), objectRef(desired)), nil
+	}
+
+	return unifiedDiff(liveYAML, desiredYAML, "live/"+objectRef(live), "manifest/"+objectRef(desired))
+}
+
+// unifiedDiff renders the unified diff from text a to text b
+// unifiedDiff 生成从文本 a 到文本 b 的 unified diff
+func unifiedDiff(a, b, fromFile, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  diffContextLines,
+	})
+}
+
+// normalizedYAML renders an object as YAML without status and server-populated metadata
+// normalizedYAML 将对象渲染为 YAML，移除 status 和由服务器填充的元数据
+func normalizedYAML(obj *unstructured.Unstructured) (string, error) {
+	content := obj.DeepCopy().Object
+	delete(content, "status")
+	for _, field := range diffIgnoredMetadataFields {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	unstructured.RemoveNestedField(content, "metadata", "annotations", lastAppliedAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(content, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(content, "metadata", "annotations")
+	}
+	if obj.GetKind() == "Secret" {
+		redactSecretValues(content)
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize %s: %w", objectRef(obj), err)
+	}
+	return string(data), nil
+}
+
+// redactSecretValues replaces secret values with a short fingerprint, so the diff
+// shows which keys changed without revealing their contents
+// redactSecretValues 将 secret 的值替换为简短指纹，对比结果只显示哪些键发生了变化而不泄露内容
+func redactSecretValues(content map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := content[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+			values[key] = "REDACTED sha256:" + hex.EncodeToString(sum[:])[:12]
+		}
+	}
+}
+
+// objectRef returns "namespace/name", or just "name" for cluster-scoped objects
+// objectRef 返回 "namespace/name"，集群级对象只返回 "name"
+func objectRef(obj *unstructured.Unstructured) string {
+	if ns := obj.GetNamespace(); ns != "" {
+		return ns + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDiffManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: default
+data:
+  LOG_LEVEL: debug
+  PORT: "8080"
+`
+
+// TestDiffObjectsChanged 测试对比前规范化服务器字段并输出 unified diff
+func TestDiffObjectsChanged(t *testing.T) {
+	desired, err := parseManifest(testDiffManifest)
+	if err != nil {
+		t.Fatalf("parseManifest failed: %v", err)
+	}
+	live, err := parseManifest(`{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "app-config",
+    "namespace": "default",
+    "resourceVersion": "12345",
+    "uid": "0b7f",
+    "creationTimestamp": "2024-01-01T00:00:00Z",
+    "managedFields": [{"manager": "kubectl"}],
+    "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+  },
+  "data": {"LOG_LEVEL": "info", "PORT": "8080"}
+}`)
+	if err != nil {
+		t.Fatalf("parseManifest failed: %v", err)
+	}
+
+	diff, err := diffObjects(live, desired)
+	if err != nil {
+		t.Fatalf("diffObjects failed: %v", err)
+	}
+	for _, want := range []string{"--- live/default/app-config", "+++ manifest/default/app-config", "-  LOG_LEVEL: info", "+  LOG_LEVEL: debug", "   PORT: \"8080\""} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected %q in diff:\n%s", want, diff)
+		}
+	}
+	for _, unwanted := range []string{"resourceVersion", "managedFields", "creationTimestamp", "last-applied-configuration"} {
+		if strings.Contains(diff, unwanted) {
+			t.Errorf("expected %s to be normalized away:\n%s", unwanted, diff)
+		}
+	}
+}
+
+// TestDiffObjectsIdentical 测试没有差异时的输出
+func TestDiffObjectsIdentical(t *testing.T) {
+	desired, _ := parseManifest(testDiffManifest)
+	live, _ := parseManifest(testDiffManifest)
+	live.SetResourceVersion("42")
+
+	diff, err := diffObjects(live, desired)
+	if err != nil {
+		t.Fatalf("diffObjects failed: %v", err)
+	}
+	if diff != "No differences for ConfigMap default/app-config" {
+		t.Errorf("unexpected output: %q", diff)
+	}
+}
+
+// TestDiffObjectsCreate 测试对象不存在时报告将被创建并输出完整清单
+func TestDiffObjectsCreate(t *testing.T) {
+	desired, _ := parseManifest(testDiffManifest)
+
+	diff, err := diffObjects(nil, desired)
+	if err != nil {
+		t.Fatalf("diffObjects failed: %v", err)
+	}
+	if !strings.HasPrefix(diff, "ConfigMap default/app-config does not exist and would be created:") || !strings.Contains(diff, "LOG_LEVEL: debug") {
+		t.Errorf("unexpected output:\n%s", diff)
+	}
+}
+
+// TestDiffObjectsRedactsSecrets 测试 secret 的值在对比结果中被脱敏
+func TestDiffObjectsRedactsSecrets(t *testing.T) {
+	desired, _ := parseManifest("apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: bmV3\n")
+	live, _ := parseManifest("apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: b2xk\n")
+
+	diff, err := diffObjects(live, desired)
+	if err != nil {
+		t.Fatalf("diffObjects failed: %v", err)
+	}
+	if strings.Contains(diff, "bmV3") || strings.Contains(diff, "b2xk") {
+		t.Errorf("secret values leaked into diff:\n%s", diff)
+	}
+	if !strings.Contains(diff, "-  password: REDACTED") || !strings.Contains(diff, "+  password: REDACTED") {
+		t.Errorf("expected the changed key to show up:\n%s", diff)
+	}
+}
+
+// TestParseManifestInvalid 测试无效清单
+func TestParseManifestInvalid(t *testing.T) {
+	for _, manifest := range []string{"kind: [", "apiVersion: v1\nkind: ConfigMap\n"} {
+		if _, err := parseManifest(manifest); err == nil {
+			t.Errorf("expected error for %q", manifest)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package k8s
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// clusterDiscovery holds a cluster's memoized discovery client, the REST mapper built on it
+// and its dynamic client, so tool calls don't repeat the discovery round trips
+// clusterDiscovery 保存集群的带缓存的发现客户端、基于它的 REST 映射器以及动态客户端，避免每次工具调用重复发现请求
+type clusterDiscovery struct {
+	mapper  *restmapper.DeferredDiscoveryRESTMapper
+	dynamic dynamic.Interface
+}
+
+// countingDiscovery counts every fetch of a cluster's API group list (with the resources,
+// when the server supports aggregated discovery) made to fill the memory cache
+// countingDiscovery 统计为填充内存缓存而获取集群 API 组列表 (服务器支持聚合发现时连同资源一起获取) 的次数
+type countingDiscovery struct {
+	discovery.DiscoveryInterface
+	cm *ClusterManager
+}
+
+// GroupsAndMaybeResources implements discovery.AggregatedDiscoveryInterface, which the memory
+// cache prefers over ServerGroups. Servers without aggregated discovery return no resources,
+// and the cache then fetches each group version on its own.
+// GroupsAndMaybeResources 实现 discovery.AggregatedDiscoveryInterface，内存缓存优先使用它而不是 ServerGroups。
+// 不支持聚合发现的服务器不返回资源，缓存随后逐个获取 group version。
+func (d countingDiscovery) GroupsAndMaybeResources() (*metav1.APIGroupList, map[schema.GroupVersion]*metav1.APIResourceList, map[schema.GroupVersion]error, error) {
+	d.cm.discoveryFetches.Add(1)
+	if aggregated, ok := d.DiscoveryInterface.(discovery.AggregatedDiscoveryInterface); ok {
+		return aggregated.GroupsAndMaybeResources()
+	}
+	groups, err := d.DiscoveryInterface.ServerGroups()
+	return groups, nil, nil, err
+}
+
+// clusterDiscoveryFor returns the discovery cache of a cluster, creating it on first use.
+// The dynamic client is only built for clusters with a rest.Config.
+// clusterDiscoveryFor 返回集群的发现缓存，首次使用时创建。只有具有 rest.Config 的集群才会创建动态客户端。
+func (cm *ClusterManager) clusterDiscoveryFor(clusterName string) (*clusterDiscovery, error) {
+	client, err := cm.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.discoveryMu.Lock()
+	defer cm.discoveryMu.Unlock()
+	if cached, ok := cm.discovery[clusterName]; ok {
+		return cached, nil
+	}
+	cached := &clusterDiscovery{
+		mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(countingDiscovery{DiscoveryInterface: client.Discovery(), cm: cm})),
+	}
+	if config, ok := cm.configs[clusterName]; ok {
+		if cached.dynamic, err = dynamic.NewForConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to create dynamic client for cluster %s: %w", clusterName, err)
+		}
+	}
+	if cm.discovery == nil {
+		cm.discovery = make(map[string]*clusterDiscovery)
+	}
+	cm.discovery[clusterName] = cached
+	return cached, nil
+}
+
+// RESTMapping maps a kind to its resource on a cluster. When the cached discovery information
+// has no match, e.g. for a CRD installed since it was fetched, it is invalidated once and the
+// lookup retried. An empty name selects the current cluster.
+// RESTMapping 将类型映射为集群上的资源。缓存的发现信息中没有匹配项时 (例如获取之后才安装的 CRD)，
+// 使缓存失效一次并重试。名称为空时使用当前集群。
+func (cm *ClusterManager) RESTMapping(clusterName string, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	mapper, err := cm.restMapper(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if resettable, ok := mapper.(meta.ResettableRESTMapper); ok && meta.IsNoMatchError(err) {
+		cm.logger.Debug("Refreshing stale discovery information", "cluster", clusterName, "kind", gvk.String())
+		resettable.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+// restMapper returns the REST mapper of a cluster: the fixed mapper of a mock cluster or the
+// mapper over the cluster's discovery cache
+// restMapper 返回集群的 REST 映射器：模拟集群使用固定的映射器，其他集群使用基于发现缓存的映射器
+func (cm *ClusterManager) restMapper(clusterName string) (meta.RESTMapper, error) {
+	if mock, exists := cm.mockDynamic[clusterName]; exists {
+		return mock.mapper, nil
+	}
+	cached, err := cm.clusterDiscoveryFor(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return cached.mapper, nil
+}
+
+// InvalidateDiscovery drops the cached discovery information of a cluster: the API resources
+// behind the REST mapper, the answers of ServesResource and the schemas of ExplainResource.
+// They are fetched again on next use. An empty name selects the current cluster.
+// InvalidateDiscovery 丢弃集群缓存的发现信息：REST 映射器使用的 API 资源、ServesResource 的结果以及
+// ExplainResource 的 schema，下次使用时重新获取。名称为空时使用当前集群。
+func (cm *ClusterManager) InvalidateDiscovery(clusterName string) error {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	if !cm.hasCluster(clusterName) {
+		return cm.clusterNotFound(clusterName)
+	}
+
+	cm.discoveryMu.Lock()
+	if cached, ok := cm.discovery[clusterName]; ok {
+		cached.mapper.Reset()
+	}
+	cm.discoveryMu.Unlock()
+
+	cm.servedMu.Lock()
+	delete(cm.served, clusterName)
+	cm.servedMu.Unlock()
+
+	cm.openAPIMu.Lock()
+	delete(cm.openAPI, clusterName)
+	cm.openAPIMu.Unlock()
+
+	cm.logger.Debug("Invalidated discovery information", "cluster", clusterName)
+	return nil
+}
+
+// dropDiscovery forgets the discovery cache and dynamic client of a cluster whose client is replaced
+// dropDiscovery 在集群客户端被替换时丢弃其发现缓存和动态客户端
+func (cm *ClusterManager) dropDiscovery(clusterName string) {
+	cm.discoveryMu.Lock()
+	defer cm.discoveryMu.Unlock()
+	delete(cm.discovery, clusterName)
+}
+
+// DiscoveryFetches returns how many times the API group list has been fetched to fill the
+// discovery caches since the manager was created
+// DiscoveryFetches 返回自 ClusterManager 创建以来为填充发现缓存而获取 API 组列表的次数
+func (cm *ClusterManager) DiscoveryFetches() int64 {
+	return cm.discoveryFetches.Load()
+}
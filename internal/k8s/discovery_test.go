@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+// newDiscoveryOperations 创建一个发现接口包含 pods 和 deployments 的集群，返回其 FakeDiscovery 以便测试中追加资源
+func newDiscoveryOperations(t *testing.T) (*ClusterManager, *fakediscovery.FakeDiscovery) {
+	t.Helper()
+	ro, client := newFakeOperations(t)
+	fake := client.Discovery().(*fakediscovery.FakeDiscovery)
+	fake.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}}},
+	}
+	return ro.clusterManager, fake
+}
+
+// TestRESTMappingCachesDiscovery 测试重复解析资源时只获取一次发现信息
+func TestRESTMappingCachesDiscovery(t *testing.T) {
+	cm, _ := newDiscoveryOperations(t)
+
+	tests := []struct {
+		gvk      schema.GroupVersionKind
+		resource schema.GroupVersionResource
+	}{
+		{schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+	}
+	for i := 0; i < 3; i++ {
+		for _, tt := range tests {
+			mapping, err := cm.RESTMapping("", tt.gvk)
+			if err != nil {
+				t.Fatalf("RESTMapping(%s) failed: %v", tt.gvk, err)
+			}
+			if mapping.Resource != tt.resource || mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				t.Errorf("expected %s, got %s (%s)", tt.resource, mapping.Resource, mapping.Scope.Name())
+			}
+		}
+	}
+	if cm.DiscoveryFetches() != 1 {
+		t.Errorf("expected discovery to be fetched once, got %d", cm.DiscoveryFetches())
+	}
+
+	// 替换集群客户端后丢弃旧的缓存
+	cm.AddClientset("test", cm.clusters["test"])
+	if _, err := cm.RESTMapping("test", tests[0].gvk); err != nil {
+		t.Fatalf("RESTMapping failed: %v", err)
+	}
+	if cm.DiscoveryFetches() != 2 {
+		t.Errorf("expected a new fetch for the replaced client, got %d", cm.DiscoveryFetches())
+	}
+}
+
+// TestRESTMappingStaleRetry 测试缓存中没有匹配的类型时使缓存失效一次并重试，以及 InvalidateDiscovery 显式失效
+func TestRESTMappingStaleRetry(t *testing.T) {
+	cm, fake := newDiscoveryOperations(t)
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	if _, err := cm.RESTMapping("test", deployment); err != nil {
+		t.Fatalf("RESTMapping failed: %v", err)
+	}
+
+	// 获取发现信息之后才安装的 CRD
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "stable.example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "crontabs", Kind: "CronTab", Namespaced: true}},
+	})
+	mapping, err := cm.RESTMapping("test", schema.GroupVersionKind{Group: "stable.example.com", Version: "v1", Kind: "CronTab"})
+	if err != nil {
+		t.Fatalf("expected the stale mapping to be refreshed, got %v", err)
+	}
+	if mapping.Resource.Resource != "crontabs" || cm.DiscoveryFetches() != 2 {
+		t.Errorf("expected crontabs after one refresh, got %s after %d fetches", mapping.Resource, cm.DiscoveryFetches())
+	}
+
+	// 刷新后仍不存在的类型只重试一次
+	_, err = cm.RESTMapping("test", schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+	if !meta.IsNoMatchError(err) {
+		t.Errorf("expected a no match error, got %v", err)
+	}
+	if cm.DiscoveryFetches() != 3 {
+		t.Errorf("expected a single retry for an unknown kind, got %d fetches", cm.DiscoveryFetches())
+	}
+
+	cm.servedMu.Lock()
+	cm.served = map[string]map[schema.GroupVersionResource]bool{"test": {{Group: "apps", Version: "v1", Resource: "deployments"}: true}}
+	cm.servedMu.Unlock()
+	if err := cm.InvalidateDiscovery("test"); err != nil {
+		t.Fatalf("InvalidateDiscovery failed: %v", err)
+	}
+	if _, ok := cm.served["test"]; ok {
+		t.Error("expected the ServesResource answers to be dropped")
+	}
+	if _, err := cm.RESTMapping("test", deployment); err != nil {
+		t.Fatalf("RESTMapping failed: %v", err)
+	}
+	if cm.DiscoveryFetches() != 4 {
+		t.Errorf("expected discovery to be fetched again after InvalidateDiscovery, got %d", cm.DiscoveryFetches())
+	}
+
+	if err := cm.InvalidateDiscovery("missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected an unknown cluster error, got %v", err)
+	}
+}
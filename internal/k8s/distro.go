@@ -0,0 +1,276 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DistroKind is a well-known resource type that only some Kubernetes distributions
+// serve, such as OpenShift Routes. It is handled through the dynamic client once
+// discovery shows the cluster serves it; other clusters reject it like any unknown type.
+// DistroKind 是只有部分 Kubernetes 发行版提供的常见资源类型，例如 OpenShift Route。发现集群提供该类型后通过动态客户端处理；
+// 其他集群像对待未知类型一样拒绝它。
+type DistroKind struct {
+	// Resource is the API resource of the kind
+	// Resource 为该类型的 API 资源
+	Resource schema.GroupVersionResource
+	// Kind is the object kind, e.g. Route
+	// Kind 为对象类型，例如 Route
+	Kind string
+	// Names are the resource_type values selecting the kind, plural first
+	// Names 为选择该类型的 resource_type 值，复数形式在前
+	Names []ResourceType
+	// ClusterScoped is set for kinds that are not namespaced
+	// ClusterScoped 表示该类型不属于命名空间
+	ClusterScoped bool
+	// Columns are the fields shown for each object when listing
+	// Columns 为列出时每个对象显示的字段
+	Columns []DistroColumn
+	// AsNamespace lists the objects in list_namespaces as if they were namespaces
+	// AsNamespace 表示在 list_namespaces 中将对象作为命名空间列出
+	AsNamespace bool
+}
+
+// DistroColumn is a summary field of a DistroKind, read from Path of the object
+// DistroColumn 是 DistroKind 的摘要字段，从对象的 Path 读取
+type DistroColumn struct {
+	Name string
+	Path []string
+	// Default is shown when the field is not set
+	// Default 为字段未设置时显示的值
+	Default string
+}
+
+// distroKinds are the distribution-specific kinds known to the server. Adding a kind
+// only needs a new entry here.
+// distroKinds 是服务器已知的发行版特有类型，新增类型只需在此添加一项
+var distroKinds = []DistroKind{
+	{
+		Resource: schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"},
+		Kind:     "Route",
+		Names:    []ResourceType{"routes", "route"},
+		Columns: []DistroColumn{
+			{Name: "host", Path: []string{"spec", "host"}},
+			{Name: "path", Path: []string{"spec", "path"}},
+			{Name: "service", Path: []string{"spec", "to", "name"}},
+			{Name: "port", Path: []string{"spec", "port", "targetPort"}},
+			{Name: "tls", Path: []string{"spec", "tls", "termination"}, Default: "none"},
+		},
+	},
+	{
+		Resource:      schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"},
+		Kind:          "Project",
+		Names:         []ResourceType{"projects", "project"},
+		ClusterScoped: true,
+		AsNamespace:   true,
+		Columns: []DistroColumn{
+			{Name: "status", Path: []string{"status", "phase"}},
+			{Name: "display_name", Path: []string{"metadata", "annotations", "openshift.io/display-name"}},
+		},
+	},
+}
+
+// LookupDistroKind returns the distribution-specific kind selected by a resource_type value
+// LookupDistroKind 返回 resource_type 值对应的发行版特有类型
+func LookupDistroKind(resourceType ResourceType) (DistroKind, bool) {
+	for _, kind := range distroKinds {
+		for _, name := range kind.Names {
+			if name == resourceType {
+				return kind, true
+			}
+		}
+	}
+	return DistroKind{}, false
+}
+
+// ServesResource reports whether a cluster serves an API resource. The answer comes from
+// discovery on first use and is cached per cluster; a discovery failure other than the
+// group version not being served is returned and not cached.
+// ServesResource 判断集群是否提供某个 API 资源。首次使用时通过发现接口查询并按集群缓存；
+// 除 group version 不存在外的发现错误会被返回且不缓存。
+func (cm *ClusterManager) ServesResource(clusterName string, resource schema.GroupVersionResource) (bool, error) {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	cm.servedMu.Lock()
+	served, ok := cm.served[clusterName][resource]
+	cm.servedMu.Unlock()
+	if ok {
+		return served, nil
+	}
+
+	client, err := cm.GetClientForCluster(clusterName)
+	if err != nil {
+		return false, err
+	}
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(resource.GroupVersion().String())
+	switch {
+	case apierrors.IsNotFound(err):
+		served = false
+	case err != nil:
+		return false, fmt.Errorf("failed to discover %s: %w", resource.GroupVersion(), err)
+	default:
+		served = false
+		for _, r := range resources.APIResources {
+			if r.Name == resource.Resource {
+				served = true
+				break
+			}
+		}
+	}
+
+	cm.servedMu.Lock()
+	defer cm.servedMu.Unlock()
+	if cm.served == nil {
+		cm.served = make(map[string]map[schema.GroupVersionResource]bool)
+	}
+	if cm.served[clusterName] == nil {
+		cm.served[clusterName] = make(map[schema.GroupVersionResource]bool)
+	}
+	cm.served[clusterName][resource] = served
+	return served, nil
+}
+
+// servedDistroKind returns an error unless the cluster serves the kind
+// servedDistroKind 集群不提供该类型时返回错误
+func (ro *ResourceOperations) servedDistroKind(kind DistroKind, clusterName string) error {
+	served, err := ro.clusterManager.ServesResource(clusterName, kind.Resource)
+	if err != nil {
+		return err
+	}
+	if !served {
+		return fmt.Errorf("unsupported resource type: %s (%s is not served by this cluster)", kind.Names[0], kind.Resource.GroupVersion())
+	}
+	return nil
+}
+
+// ListDistroResources lists the objects of a distribution-specific kind with its summary fields
+// ListDistroResources 列出发行版特有类型的对象及其摘要字段
+func (ro *ResourceOperations) ListDistroResources(ctx context.Context, kind DistroKind, namespace, clusterName string) ([]types.DistroResource, error) {
+	if err := ro.servedDistroKind(kind, clusterName); err != nil {
+		return nil, err
+	}
+	if kind.ClusterScoped {
+		namespace = ""
+	} else if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.DistroResource, error) {
+			return ro.ListDistroResources(ctx, kind, ns, clusterName)
+		})
+	}
+
+	dynamicClient, _, err := ro.clusterManager.GetDynamicClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	list, err := dynamicClient.Resource(kind.Resource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", kind.Resource.Resource, err)
+	}
+
+	results := make([]types.DistroResource, 0, len(list.Items))
+	for i := range list.Items {
+		results = append(results, toDistroResource(kind, &list.Items[i]))
+	}
+	return results, nil
+}
+
+// GetDistroResource gets one object of a distribution-specific kind
+// GetDistroResource 获取发行版特有类型的单个对象
+func (ro *ResourceOperations) GetDistroResource(ctx context.Context, kind DistroKind, namespace, name, clusterName string) (*unstructured.Unstructured, error) {
+	if err := ro.servedDistroKind(kind, clusterName); err != nil {
+		return nil, err
+	}
+	dynamicClient, _, err := ro.clusterManager.GetDynamicClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if kind.ClusterScoped {
+		return dynamicClient.Resource(kind.Resource).Get(ctx, name, metav1.GetOptions{})
+	}
+	return dynamicClient.Resource(kind.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// toDistroResource converts an object to its summary, reading the kind's columns
+// toDistroResource 将对象转换为摘要信息，读取该类型的各个字段
+func toDistroResource(kind DistroKind, obj *unstructured.Unstructured) types.DistroResource {
+	fields := make(map[string]string, len(kind.Columns))
+	for _, column := range kind.Columns {
+		value, found, err := unstructured.NestedFieldNoCopy(obj.Object, column.Path...)
+		switch {
+		case err == nil && found && value != nil && fmt.Sprint(value) != "":
+			fields[column.Name] = fmt.Sprint(value)
+		case column.Default != "":
+			fields[column.Name] = column.Default
+		}
+	}
+	created := obj.GetCreationTimestamp()
+	return types.DistroResource{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Kind:      kind.Kind,
+		Fields:    fields,
+		Age:       formatAge(created),
+		CreatedAt: formatTimestamp(created),
+		Labels:    obj.GetLabels(),
+	}
+}
+
+// distroStatus renders the summary fields in the kind's column order, e.g.
+// "host: web.apps.example.com, service: web, tls: edge"
+// distroStatus 按该类型的字段顺序渲染摘要字段，例如 "host: web.apps.example.com, service: web, tls: edge"
+func distroStatus(item types.DistroResource) string {
+	var parts []string
+	for _, kind := range distroKinds {
+		if kind.Kind != item.Kind {
+			continue
+		}
+		for _, column := range kind.Columns {
+			if value, ok := item.Fields[column.Name]; ok {
+				parts = append(parts, column.Name+": "+value)
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// listNamespaceKinds lists the objects of the served kinds marked AsNamespace as namespaces,
+// e.g. OpenShift Projects, which users may list even when they cannot list namespaces.
+// served is false when the cluster serves none of them.
+// listNamespaceKinds 将集群提供的 AsNamespace 类型的对象作为命名空间列出，例如 OpenShift Project，
+// 用户即使无权列出命名空间通常也可以列出 Project。集群不提供任何此类类型时 served 为 false。
+func (ro *ResourceOperations) listNamespaceKinds(ctx context.Context, clusterName string) (namespaces []types.Namespace, served bool, err error) {
+	for _, kind := range distroKinds {
+		if !kind.AsNamespace {
+			continue
+		}
+		ok, err := ro.clusterManager.ServesResource(clusterName, kind.Resource)
+		if err != nil {
+			return nil, served, err
+		}
+		if !ok {
+			continue
+		}
+		served = true
+		items, err := ro.ListDistroResources(ctx, kind, "", clusterName)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, item := range items {
+			namespaces = append(namespaces, types.Namespace{
+				Name:      item.Name,
+				Status:    item.Fields["status"],
+				Age:       item.Age,
+				CreatedAt: item.CreatedAt,
+				Labels:    item.Labels,
+			})
+		}
+	}
+	return namespaces, served, nil
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	routeResource   = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+	projectResource = schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"}
+)
+
+// openShiftObject 返回 OpenShift 资源的 unstructured 对象
+func openShiftObject(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// newOpenShiftOperations 返回一个集群，discovery 中包含 Route 和 Project，动态客户端中有两个 Route 和一个 Project；
+// openShift 为 false 时集群不提供这些 API 组
+func newOpenShiftOperations(openShift bool) (*ResourceOperations, *fake.Clientset) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}})
+	fakeDiscovery := client.Discovery().(*fakediscovery.FakeDiscovery)
+	fakeDiscovery.Resources = []*metav1.APIResourceList{{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "namespaces"}}}}
+	if openShift {
+		fakeDiscovery.Resources = append(fakeDiscovery.Resources,
+			&metav1.APIResourceList{GroupVersion: "route.openshift.io/v1", APIResources: []metav1.APIResource{{Name: "routes", Namespaced: true, Kind: "Route"}}},
+			&metav1.APIResourceList{GroupVersion: "project.openshift.io/v1", APIResources: []metav1.APIResource{{Name: "projects", Kind: "Project"}}},
+		)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{routeResource: "RouteList", projectResource: "ProjectList"},
+		openShiftObject("route.openshift.io/v1", "Route", "shop", "web", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"host": "web-shop.apps.example.com",
+				"to":   map[string]interface{}{"kind": "Service", "name": "web"},
+				"port": map[string]interface{}{"targetPort": int64(8080)},
+				"tls":  map[string]interface{}{"termination": "edge"},
+			},
+		}),
+		openShiftObject("route.openshift.io/v1", "Route", "shop", "api", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"host": "api-shop.apps.example.com",
+				"path": "/v1",
+				"to":   map[string]interface{}{"kind": "Service", "name": "api"},
+			},
+		}),
+		openShiftObject("project.openshift.io/v1", "Project", "", "shop", map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Active"},
+		}),
+	)
+
+	cm := NewClusterManager(nil)
+	cm.AddClientset("openshift", client)
+	cm.mockDynamic = map[string]mockDynamicClient{"openshift": {client: dynamicClient}}
+	return NewResourceOperations(cm), client
+}
+
+// discoveryCalls 统计 fake clientset 上的 discovery 请求次数
+func discoveryCalls(client *fake.Clientset) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource == "resource" {
+			count++
+		}
+	}
+	return count
+}
+
+// TestListDistroResources 测试 Route 列表包含 host、目标 Service、端口和 TLS 终止方式，且发现结果按集群缓存
+func TestListDistroResources(t *testing.T) {
+	ro, client := newOpenShiftOperations(true)
+	ctx := context.Background()
+
+	resources, err := ro.ListResourcesByType(ctx, "routes", "shop", "openshift")
+	if err != nil {
+		t.Fatalf("ListResourcesByType(routes) failed: %v", err)
+	}
+	routes := map[string]map[string]string{}
+	for _, route := range resources.([]types.DistroResource) {
+		if route.Kind != "Route" || route.Namespace != "shop" {
+			t.Errorf("unexpected route %+v", route)
+		}
+		routes[route.Name] = route.Fields
+	}
+	want := map[string]map[string]string{
+		"web": {"host": "web-shop.apps.example.com", "service": "web", "port": "8080", "tls": "edge"},
+		"api": {"host": "api-shop.apps.example.com", "path": "/v1", "service": "api", "tls": "none"},
+	}
+	for name, fields := range want {
+		for key, value := range fields {
+			if routes[name][key] != value {
+				t.Errorf("route %s: %s = %q, want %q (fields %v)", name, key, routes[name][key], value, routes[name])
+			}
+		}
+	}
+
+	infos, err := ToResourceInfos(resources)
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("ToResourceInfos failed: %+v %v", infos, err)
+	}
+	for _, info := range infos {
+		if info.Name == "web" && info.Status != "host: web-shop.apps.example.com, service: web, port: 8080, tls: edge" {
+			t.Errorf("unexpected status %q", info.Status)
+		}
+	}
+
+	route, err := ro.GetResourceDetails(ctx, "route", "shop", "web", "openshift")
+	if err != nil {
+		t.Fatalf("GetResourceDetails(route) failed: %v", err)
+	}
+	if host, _, _ := unstructured.NestedString(route.(*unstructured.Unstructured).Object, "spec", "host"); host != "web-shop.apps.example.com" {
+		t.Errorf("unexpected route %+v", route)
+	}
+	if !IsClusterScoped("projects") || IsClusterScoped("routes") {
+		t.Errorf("expected projects to be cluster-scoped and routes namespaced")
+	}
+
+	if calls := discoveryCalls(client); calls != 1 {
+		t.Errorf("expected the route discovery to be cached, got %d discovery calls", calls)
+	}
+}
+
+// TestDistroResourcesNotServed 测试不提供 OpenShift API 组的集群拒绝 routes，且不调用动态客户端
+func TestDistroResourcesNotServed(t *testing.T) {
+	ro, client := newOpenShiftOperations(false)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := ro.ListResourcesByType(ctx, "routes", "shop", "openshift")
+		if err == nil || !strings.Contains(err.Error(), "unsupported resource type: routes (route.openshift.io/v1 is not served by this cluster)") {
+			t.Errorf("expected routes to be unsupported, got %v", err)
+		}
+	}
+	if calls := discoveryCalls(client); calls != 1 {
+		t.Errorf("expected the missing group to be cached, got %d discovery calls", calls)
+	}
+	if _, err := ro.ListResourcesByType(ctx, "widgets", "shop", "openshift"); err == nil || err.Error() != "unsupported resource type: widgets" {
+		t.Errorf("expected unknown types to keep the old error, got %v", err)
+	}
+
+	namespaces, err := ro.ListNamespaces(ctx, "openshift")
+	if err != nil || len(namespaces) != 1 || namespaces[0].Name != "shop" {
+		t.Errorf("unexpected namespaces %+v %v", namespaces, err)
+	}
+}
+
+// TestListNamespacesProjects 测试无权列出命名空间时，list_namespaces 回退为列出 Project
+func TestListNamespacesProjects(t *testing.T) {
+	for _, openShift := range []bool{true, false} {
+		ro, client := newOpenShiftOperations(openShift)
+		client.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("denied"))
+		})
+
+		namespaces, err := ro.ListNamespaces(context.Background(), "openshift")
+		if !openShift {
+			if err == nil || !strings.Contains(err.Error(), "forbidden") {
+				t.Errorf("expected the forbidden error without projects, got %+v %v", namespaces, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ListNamespaces failed: %v", err)
+		}
+		if len(namespaces) != 1 || namespaces[0].Name != "shop" || namespaces[0].Status != "Active" {
+			t.Errorf("expected the project as a namespace, got %+v", namespaces)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// mirrorPodAnnotation marks the API mirror of a static pod, which cannot be evicted
+// mirrorPodAnnotation 标记静态 Pod 在 API 中的镜像 Pod，镜像 Pod 无法被驱逐
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// drainRetryInterval is how long DrainNode waits before retrying evictions refused
+// by a PodDisruptionBudget; tests shorten it
+// drainRetryInterval 为被 PodDisruptionBudget 拒绝的驱逐重试前的等待时间，测试中会缩短
+var drainRetryInterval = 5 * time.Second
+
+// DrainOptions controls which pods DrainNode may evict and how long it keeps trying
+// DrainOptions 控制 DrainNode 可以驱逐哪些 Pod 以及持续尝试的时间
+type DrainOptions struct {
+	// IgnoreDaemonSets skips DaemonSet pods instead of refusing to drain
+	// IgnoreDaemonSets 跳过 DaemonSet 的 Pod，而不是拒绝排空
+	IgnoreDaemonSets bool
+	// DeleteEmptyDirData allows evicting pods with emptyDir volumes, whose data is lost
+	// DeleteEmptyDirData 允许驱逐使用 emptyDir 卷的 Pod，其中的数据会丢失
+	DeleteEmptyDirData bool
+	// GracePeriodSeconds overrides the pods' termination grace period when set
+	// GracePeriodSeconds 设置时覆盖 Pod 的优雅终止时间
+	GracePeriodSeconds *int64
+	// Timeout bounds the retries of evictions refused by a PodDisruptionBudget
+	// Timeout 限制被 PodDisruptionBudget 拒绝的驱逐的重试时间
+	Timeout time.Duration
+}
+
+// CordonNode marks a node unschedulable (or schedulable again with unschedulable=false)
+// CordonNode 将节点标记为不可调度（unschedulable=false 时恢复为可调度）
+func (ro *ResourceOperations) CordonNode(ctx context.Context, name string, unschedulable bool, clusterName string) (*types.NodeSchedulingResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("node name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return setUnschedulable(ctx, client, name, unschedulable)
+}
+
+// setUnschedulable patches spec.unschedulable unless it already has the wanted value
+// setUnschedulable 在 spec.unschedulable 不是目标值时对其打补丁
+func setUnschedulable(ctx context.Context, client kubernetes.Interface, name string, unschedulable bool) (*types.NodeSchedulingResult, error) {
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	result := &types.NodeSchedulingResult{Node: name, Unschedulable: unschedulable}
+	if node.Spec.Unschedulable == unschedulable {
+		return result, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build node patch: %w", err)
+	}
+	if _, err := client.CoreV1().Nodes().Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to patch node: %w", err)
+	}
+	result.Changed = true
+	return result, nil
+}
+
+// DrainNode cordons a node and evicts its pods through the Eviction API, like kubectl
+// drain. As with kubectl, nothing is evicted if a pod cannot be drained (DaemonSet pods
+// without IgnoreDaemonSets, emptyDir pods without DeleteEmptyDirData, or pods not managed
+// by a controller). Evictions refused by a PodDisruptionBudget are retried until the
+// timeout; the pods still blocked are reported with the budget that blocked them.
+// DrainNode 先将节点标记为不可调度，再通过 Eviction API 驱逐其上的 Pod，与 kubectl drain 相同。
+// 与 kubectl 一样，只要有 Pod 无法排空（未设置 IgnoreDaemonSets 时的 DaemonSet Pod、未设置
+// DeleteEmptyDirData 时使用 emptyDir 的 Pod，或不受控制器管理的 Pod）就不会驱逐任何 Pod。
+// 被 PodDisruptionBudget 拒绝的驱逐会重试直到超时，仍被阻塞的 Pod 连同阻塞它的 PDB 一起报告。
+func (ro *ResourceOperations) DrainNode(ctx context.Context, name string, opts DrainOptions, clusterName string) (*types.DrainResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("node name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := setUnschedulable(ctx, client, name, true); err != nil {
+		return nil, err
+	}
+	result := &types.DrainResult{Node: name, Cordoned: true}
+
+	pods, err := ro.listNodePods(ctx, client, "", name, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var evictable []corev1.Pod
+	for _, pod := range pods {
+		ref := pod.Namespace + "/" + pod.Name
+		switch reason, skip := drainFilter(&pod, opts); {
+		case skip:
+			result.Skipped = append(result.Skipped, ref)
+		case reason != "":
+			result.Blocked = append(result.Blocked, types.DrainBlockedPod{Pod: ref, Reason: reason})
+		default:
+			evictable = append(evictable, pod)
+		}
+	}
+	if len(result.Blocked) > 0 {
+		result.Message = "no pods were evicted because some pods cannot be drained; the node stays cordoned"
+		return result, nil
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	for len(evictable) > 0 {
+		var refused []corev1.Pod
+		for _, pod := range evictable {
+			err := evictPod(ctx, client, &pod, opts.GracePeriodSeconds)
+			switch {
+			case err == nil, apierrors.IsNotFound(err):
+				result.Evicted = append(result.Evicted, pod.Namespace+"/"+pod.Name)
+			case apierrors.IsTooManyRequests(err):
+				refused = append(refused, pod)
+			default:
+				return nil, fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		evictable = refused
+		if len(evictable) == 0 || !time.Now().Add(drainRetryInterval).Before(deadline) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(drainRetryInterval):
+		}
+	}
+
+	for _, pod := range evictable {
+		budget, err := blockingBudget(ctx, client, &pod)
+		if err != nil {
+			return nil, err
+		}
+		result.Blocked = append(result.Blocked, types.DrainBlockedPod{
+			Pod:                 pod.Namespace + "/" + pod.Name,
+			Reason:              "eviction would violate a PodDisruptionBudget",
+			PodDisruptionBudget: budget,
+		})
+	}
+
+	result.Complete = len(result.Blocked) == 0
+	if result.Complete {
+		result.Message = fmt.Sprintf("evicted %d pods; they terminate within their grace period", len(result.Evicted))
+	} else {
+		result.Message = fmt.Sprintf("evicted %d pods, %d still blocked after %s", len(result.Evicted), len(result.Blocked), opts.Timeout)
+	}
+	return result, nil
+}
+
+// drainFilter decides what to do with a pod on a node being drained: skip it, block the
+// drain with a reason, or evict it (no reason, no skip)
+// drainFilter 决定排空节点时如何处理 Pod：跳过、以某个原因阻止排空，或驱逐（无原因且不跳过）
+func drainFilter(pod *corev1.Pod, opts DrainOptions) (string, bool) {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return "", true
+	}
+
+	controller := metav1.GetControllerOf(pod)
+	if controller != nil && controller.Kind == "DaemonSet" {
+		if opts.IgnoreDaemonSets {
+			return "", true
+		}
+		return "managed by DaemonSet " + controller.Name + "; set ignore_daemonsets to skip it", false
+	}
+	if controller == nil {
+		return "not managed by a controller, so it would not be recreated; delete it manually", false
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return "uses emptyDir volume " + volume.Name + "; set delete_emptydir_data to evict it and lose its data", false
+			}
+		}
+	}
+	return "", false
+}
+
+// evictPod asks the API server to evict a pod, honouring PodDisruptionBudgets
+// evictPod 请求 API server 驱逐 Pod，驱逐会遵守 PodDisruptionBudget
+func evictPod(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, gracePeriodSeconds *int64) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	if gracePeriodSeconds != nil {
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds}
+	}
+	return client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+}
+
+// blockingBudget returns the names of the PodDisruptionBudgets selecting a pod; in
+// policy/v1 an empty selector selects every pod in the namespace
+// blockingBudget 返回选中该 Pod 的 PodDisruptionBudget 名称；policy/v1 中空选择器选中命名空间内的所有 Pod
+func blockingBudget(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod) (string, error) {
+	budgets, err := client.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list poddisruptionbudgets: %w", err)
+	}
+
+	var names []string
+	for _, pdb := range budgets.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			names = append(names, pdb.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "<unknown>", nil
+	}
+	return strings.Join(names, ","), nil
+}
@@ -0,0 +1,232 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDrainTestPod 创建 worker-1 上由 controllerKind 控制的 Pod，controllerKind 为空时不受控制器管理
+func newDrainTestPod(name, controllerKind string, labels map[string]string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+	}
+	if controllerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: controllerKind, Name: name + "-owner", Controller: &controller}}
+	}
+	return pod
+}
+
+// drainAPIServer 记录排空过程中收到的节点补丁和驱逐请求
+type drainAPIServer struct {
+	mu           sync.Mutex
+	nodePatch    []string
+	evictions    map[string]int
+	gracePeriods []int64
+}
+
+// fakeDrainAPIServer 模拟节点、节点上的 Pod、PDB 和 Eviction 接口；blocked 中的 Pod 驱逐时总是返回 429
+func fakeDrainAPIServer(t *testing.T, pods []corev1.Pod, blocked ...string) (*httptest.Server, *drainAPIServer) {
+	t.Helper()
+
+	recorder := &drainAPIServer{evictions: map[string]int{}}
+	budgets := policyv1.PodDisruptionBudgetList{Items: []policyv1.PodDisruptionBudget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-pdb", Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-pdb", Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		},
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/api/v1/nodes/worker-1" && r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			recorder.nodePatch = append(recorder.nodePatch, string(body))
+			json.NewEncoder(w).Encode(corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}})
+		case r.URL.Path == "/api/v1/nodes/worker-1":
+			json.NewEncoder(w).Encode(corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}})
+		case r.URL.Path == "/api/v1/pods":
+			json.NewEncoder(w).Encode(corev1.PodList{Items: pods})
+		case r.URL.Path == "/apis/policy/v1/namespaces/default/poddisruptionbudgets":
+			json.NewEncoder(w).Encode(budgets)
+		case strings.HasSuffix(r.URL.Path, "/eviction"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/default/pods/"), "/eviction")
+			recorder.evictions[name]++
+			var eviction policyv1.Eviction
+			json.NewDecoder(r.Body).Decode(&eviction)
+			if eviction.DeleteOptions != nil && eviction.DeleteOptions.GracePeriodSeconds != nil {
+				recorder.gracePeriods = append(recorder.gracePeriods, *eviction.DeleteOptions.GracePeriodSeconds)
+			}
+			for _, b := range blocked {
+				if b == name {
+					w.WriteHeader(http.StatusTooManyRequests)
+					json.NewEncoder(w).Encode(metav1.Status{
+						Status:  metav1.StatusFailure,
+						Code:    http.StatusTooManyRequests,
+						Reason:  metav1.StatusReasonTooManyRequests,
+						Message: "Cannot evict pod as it would violate the pod's disruption budget.",
+					})
+					return
+				}
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(metav1.Status{Status: metav1.StatusSuccess})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, recorder
+}
+
+// shortenDrainRetries 缩短被 PDB 拒绝的驱逐的重试间隔
+func shortenDrainRetries(t *testing.T) {
+	t.Helper()
+	previous := drainRetryInterval
+	drainRetryInterval = 10 * time.Millisecond
+	t.Cleanup(func() { drainRetryInterval = previous })
+}
+
+// TestDrainNodePDBBlocked 测试被 PDB 阻止的驱逐会重试到超时，并报告阻止它的 PDB
+func TestDrainNodePDBBlocked(t *testing.T) {
+	shortenDrainRetries(t)
+
+	mirror := newDrainTestPod("kube-proxy-worker-1", "", nil)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	pods := []corev1.Pod{
+		newDrainTestPod("web-1", "ReplicaSet", map[string]string{"app": "web"}),
+		newDrainTestPod("db-0", "StatefulSet", map[string]string{"app": "db"}),
+		newDrainTestPod("fluentd-x2k9p", "DaemonSet", nil),
+		mirror,
+	}
+	server, recorder := fakeDrainAPIServer(t, pods, "db-0")
+	ro := newServerOperations(t, server)
+
+	grace := int64(30)
+	result, err := ro.DrainNode(context.Background(), "worker-1", DrainOptions{
+		IgnoreDaemonSets:   true,
+		GracePeriodSeconds: &grace,
+		Timeout:            100 * time.Millisecond,
+	}, "test")
+	if err != nil {
+		t.Fatalf("DrainNode failed: %v", err)
+	}
+
+	if !result.Cordoned || result.Complete {
+		t.Errorf("expected a cordoned, incomplete drain: %+v", result)
+	}
+	if want := []string{"default/web-1"}; !reflect.DeepEqual(result.Evicted, want) {
+		t.Errorf("evicted = %v, want %v", result.Evicted, want)
+	}
+	if want := []string{"default/fluentd-x2k9p", "default/kube-proxy-worker-1"}; !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("skipped = %v, want %v", result.Skipped, want)
+	}
+	if len(result.Blocked) != 1 || result.Blocked[0].Pod != "default/db-0" || result.Blocked[0].PodDisruptionBudget != "db-pdb" {
+		t.Errorf("unexpected blocked pods: %+v", result.Blocked)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.nodePatch) != 1 || recorder.nodePatch[0] != `{"spec":{"unschedulable":true}}` {
+		t.Errorf("unexpected node patches: %v", recorder.nodePatch)
+	}
+	if recorder.evictions["web-1"] != 1 || recorder.evictions["db-0"] < 2 {
+		t.Errorf("expected one eviction of web-1 and retries of db-0, got %v", recorder.evictions)
+	}
+	if recorder.evictions["fluentd-x2k9p"] != 0 || recorder.evictions["kube-proxy-worker-1"] != 0 {
+		t.Errorf("skipped pods must not be evicted: %v", recorder.evictions)
+	}
+	if len(recorder.gracePeriods) == 0 || recorder.gracePeriods[0] != 30 {
+		t.Errorf("expected grace period 30 in evictions, got %v", recorder.gracePeriods)
+	}
+}
+
+// TestDrainNodeRefusesUndrainablePods 测试存在无法排空的 Pod 时不驱逐任何 Pod
+func TestDrainNodeRefusesUndrainablePods(t *testing.T) {
+	withEmptyDir := newDrainTestPod("cache-1", "ReplicaSet", nil)
+	withEmptyDir.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+
+	tests := []struct {
+		name        string
+		pod         corev1.Pod
+		opts        DrainOptions
+		wantBlocked bool
+		wantReason  string
+	}{
+		{"daemonset without ignore_daemonsets", newDrainTestPod("fluentd-x2k9p", "DaemonSet", nil), DrainOptions{}, true, "DaemonSet"},
+		{"unmanaged pod", newDrainTestPod("debug", "", nil), DrainOptions{IgnoreDaemonSets: true}, true, "not managed by a controller"},
+		{"emptydir without delete_emptydir_data", withEmptyDir, DrainOptions{IgnoreDaemonSets: true}, true, "emptyDir volume scratch"},
+		{"emptydir with delete_emptydir_data", withEmptyDir, DrainOptions{IgnoreDaemonSets: true, DeleteEmptyDirData: true}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []corev1.Pod{newDrainTestPod("web-1", "ReplicaSet", nil), tt.pod}
+			server, recorder := fakeDrainAPIServer(t, pods)
+			ro := newServerOperations(t, server)
+
+			result, err := ro.DrainNode(context.Background(), "worker-1", tt.opts, "test")
+			if err != nil {
+				t.Fatalf("DrainNode failed: %v", err)
+			}
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			if !tt.wantBlocked {
+				if !result.Complete || len(result.Evicted) != 2 {
+					t.Errorf("expected both pods evicted: %+v", result)
+				}
+				return
+			}
+			if result.Complete || len(result.Evicted) != 0 || len(recorder.evictions) != 0 {
+				t.Errorf("expected no evictions: %+v, %v", result, recorder.evictions)
+			}
+			if len(result.Blocked) != 1 || !strings.Contains(result.Blocked[0].Reason, tt.wantReason) {
+				t.Errorf("unexpected blocked pods: %+v", result.Blocked)
+			}
+		})
+	}
+}
+
+// TestCordonNode 测试节点已处于目标状态时不发送补丁
+func TestCordonNode(t *testing.T) {
+	server, recorder := fakeDrainAPIServer(t, nil)
+	ro := newServerOperations(t, server)
+
+	result, err := ro.CordonNode(context.Background(), "worker-1", true, "test")
+	if err != nil || !result.Changed || !result.Unschedulable {
+		t.Fatalf("unexpected cordon result: %+v, %v", result, err)
+	}
+	// 模拟的节点始终可调度，因此 uncordon 无需修改
+	result, err = ro.CordonNode(context.Background(), "worker-1", false, "test")
+	if err != nil || result.Changed || result.Unschedulable {
+		t.Fatalf("unexpected uncordon result: %+v, %v", result, err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.nodePatch) != 1 {
+		t.Errorf("expected a single patch, got %v", recorder.nodePatch)
+	}
+}
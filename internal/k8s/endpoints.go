@@ -0,0 +1,438 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Sources of the endpoints reported by GetServiceEndpoints
+// GetServiceEndpoints 报告的端点来源
+const (
+	EndpointSourceSlices = "EndpointSlice"
+	EndpointSourceLegacy = "Endpoints"
+)
+
+// GetServiceEndpoints reports whether a service routes to anything: its ports against
+// the container ports of the pods it selects, its ready and not-ready endpoints from the
+// EndpointSlices (or the legacy Endpoints when EndpointSlices are unavailable) with the
+// pods behind them, and warnings for a selector matching no pod or failing readiness probes
+// GetServiceEndpoints 报告 Service 是否真正路由到后端：其端口与所选 Pod 容器端口的对应关系，EndpointSlice
+// （不可用时使用旧版 Endpoints）中就绪和未就绪的端点及其后端 Pod，以及选择器不匹配任何 Pod 或就绪探针失败的警告
+func (ro *ResourceOperations) GetServiceEndpoints(ctx context.Context, namespace, name, clusterName string) (*types.ServiceEndpoints, error) {
+	if name == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	var pods []corev1.Pod
+	if len(service.Spec.Selector) > 0 {
+		selector := labels.SelectorFromSet(service.Spec.Selector).String()
+		list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		pods = list.Items
+	}
+
+	var endpoints []types.ServiceEndpoint
+	source := ""
+	// ExternalName services are resolved through DNS and have no endpoints
+	// ExternalName 类型的 Service 通过 DNS 解析，没有端点
+	if service.Spec.Type != corev1.ServiceTypeExternalName {
+		endpoints, source, err = serviceEndpoints(ctx, client, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buildServiceEndpoints(service, pods, endpoints, source), nil
+}
+
+// serviceEndpoints lists the endpoints of a service from its EndpointSlices, falling back
+// to the legacy Endpoints object when the discovery.k8s.io/v1 API is not served or not allowed
+// serviceEndpoints 从 Service 的 EndpointSlice 中列出端点，discovery.k8s.io/v1 API 不存在或无权访问时回退到旧版 Endpoints 对象
+func serviceEndpoints(ctx context.Context, client kubernetes.Interface, namespace, name string) ([]types.ServiceEndpoint, string, error) {
+	slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err == nil {
+		return sliceEndpoints(slices.Items), EndpointSourceSlices, nil
+	}
+	if !apierrors.IsNotFound(err) && !apierrors.IsMethodNotSupported(err) && !apierrors.IsForbidden(err) {
+		return nil, "", fmt.Errorf("failed to list endpointslices: %w", err)
+	}
+
+	legacy, err := client.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, EndpointSourceLegacy, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get endpoints: %w", err)
+	}
+	return legacyEndpoints(legacy), EndpointSourceLegacy, nil
+}
+
+// sliceEndpoints flattens EndpointSlices into one entry per address; an endpoint whose
+// ready condition is unset counts as ready, as the API defines
+// sliceEndpoints 将 EndpointSlice 展开为每个地址一项；按照 API 的定义，未设置 ready 条件的端点视为就绪
+func sliceEndpoints(slices []discoveryv1.EndpointSlice) []types.ServiceEndpoint {
+	var endpoints []types.ServiceEndpoint
+	for _, slice := range slices {
+		var ports []string
+		for _, port := range slice.Ports {
+			var name string
+			var number int32
+			var protocol corev1.Protocol
+			if port.Name != nil {
+				name = *port.Name
+			}
+			if port.Port != nil {
+				number = *port.Port
+			}
+			if port.Protocol != nil {
+				protocol = *port.Protocol
+			}
+			ports = append(ports, formatEndpointPort(name, number, protocol))
+		}
+		for _, endpoint := range slice.Endpoints {
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			terminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+			for _, address := range endpoint.Addresses {
+				entry := types.ServiceEndpoint{Address: address, Ports: ports, Ready: ready, Terminating: terminating}
+				if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+					entry.Pod = endpoint.TargetRef.Name
+				}
+				if endpoint.NodeName != nil {
+					entry.Node = *endpoint.NodeName
+				}
+				endpoints = append(endpoints, entry)
+			}
+		}
+	}
+	return endpoints
+}
+
+// legacyEndpoints flattens the subsets of an Endpoints object into one entry per address
+// legacyEndpoints 将 Endpoints 对象的各个子集展开为每个地址一项
+func legacyEndpoints(object *corev1.Endpoints) []types.ServiceEndpoint {
+	var endpoints []types.ServiceEndpoint
+	for _, subset := range object.Subsets {
+		var ports []string
+		for _, port := range subset.Ports {
+			ports = append(ports, formatEndpointPort(port.Name, port.Port, port.Protocol))
+		}
+		add := func(addresses []corev1.EndpointAddress, ready bool) {
+			for _, address := range addresses {
+				entry := types.ServiceEndpoint{Address: address.IP, Ports: ports, Ready: ready}
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+					entry.Pod = address.TargetRef.Name
+				}
+				if address.NodeName != nil {
+					entry.Node = *address.NodeName
+				}
+				endpoints = append(endpoints, entry)
+			}
+		}
+		add(subset.Addresses, true)
+		add(subset.NotReadyAddresses, false)
+	}
+	return endpoints
+}
+
+// formatEndpointPort formats an endpoint port like "http 8080/TCP"
+// formatEndpointPort 将端点端口格式化为 "http 8080/TCP" 的形式
+func formatEndpointPort(name string, port int32, protocol corev1.Protocol) string {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	s := fmt.Sprintf("%d/%s", port, protocol)
+	if name != "" {
+		s = name + " " + s
+	}
+	return s
+}
+
+// buildServiceEndpoints matches the service ports, endpoints and selected pods and collects the warnings
+// buildServiceEndpoints 对照 Service 端口、端点和选中的 Pod，并收集警告
+func buildServiceEndpoints(service *corev1.Service, pods []corev1.Pod, endpoints []types.ServiceEndpoint, source string) *types.ServiceEndpoints {
+	result := &types.ServiceEndpoints{
+		Service:   service.Name,
+		Namespace: service.Namespace,
+		Type:      string(service.Spec.Type),
+		ClusterIP: service.Spec.ClusterIP,
+		Selector:  service.Spec.Selector,
+		Source:    source,
+		Ports:     make([]types.ServicePortMapping, 0, len(service.Spec.Ports)),
+		Endpoints: endpoints,
+		Pods:      make([]types.BackendPod, 0, len(pods)),
+	}
+	if result.Type == "" {
+		result.Type = string(corev1.ServiceTypeClusterIP)
+	}
+	if result.Endpoints == nil {
+		result.Endpoints = []types.ServiceEndpoint{}
+	}
+	sort.SliceStable(result.Endpoints, func(i, j int) bool {
+		return result.Endpoints[i].Address < result.Endpoints[j].Address
+	})
+	endpointOf := make(map[string]string)
+	for _, endpoint := range result.Endpoints {
+		if endpoint.Ready {
+			result.Ready++
+		} else {
+			result.NotReady++
+		}
+		if endpoint.Pod != "" {
+			endpointOf[endpoint.Pod] = endpoint.Address
+		}
+	}
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, port := range service.Spec.Ports {
+		mapping := servicePortMapping(port, pods)
+		for _, pod := range mapping.UnresolvedPods {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("port %s: pod %s has no container port named %q, so it gets no endpoint for this port", servicePortName(port), pod, mapping.TargetPort))
+		}
+		result.Ports = append(result.Ports, mapping)
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		backend := types.BackendPod{
+			Name:             pod.Name,
+			Status:           getPodStatus(pod),
+			Ready:            podIsReady(pod),
+			Endpoint:         endpointOf[pod.Name],
+			ReadinessFailing: failingReadiness(pod),
+		}
+		for _, container := range backend.ReadinessFailing {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("pod %s: readiness probe failing on container %s", pod.Name, container))
+		}
+		result.Pods = append(result.Pods, backend)
+	}
+
+	switch {
+	case service.Spec.Type == corev1.ServiceTypeExternalName:
+	case len(service.Spec.Selector) == 0 && len(result.Endpoints) == 0:
+		result.Warnings = append(result.Warnings, "service has no selector and no endpoints: nothing backs it until endpoints are added by hand")
+	case len(service.Spec.Selector) > 0 && len(pods) == 0:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("selector %s matches no pods: the service routes to nothing", labels.SelectorFromSet(service.Spec.Selector)))
+	case result.Ready == 0:
+		result.Warnings = append(result.Warnings, "no ready endpoints: the service routes to nothing")
+	}
+
+	result.Text = renderServiceEndpoints(result)
+	return result
+}
+
+// servicePortMapping resolves the target port of a service port in every selected pod.
+// A named target port must be a container port of that name; a numeric one need not be
+// declared, so it is only listed when a container declares it.
+// servicePortMapping 在每个选中的 Pod 中解析 Service 端口的目标端口。命名的目标端口必须是同名的容器端口；
+// 数字目标端口不要求声明，因此只在容器声明了该端口时列出。
+func servicePortMapping(port corev1.ServicePort, pods []corev1.Pod) types.ServicePortMapping {
+	target := port.TargetPort
+	if target.Type == intstr.Int && target.IntVal == 0 {
+		target = intstr.FromInt32(port.Port)
+	}
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	mapping := types.ServicePortMapping{
+		Name:       port.Name,
+		Protocol:   string(protocol),
+		Port:       port.Port,
+		TargetPort: target.String(),
+	}
+
+	seen := make(map[string]bool)
+	for i := range pods {
+		found := false
+		for _, container := range pods[i].Spec.Containers {
+			for _, containerPort := range container.Ports {
+				containerProtocol := containerPort.Protocol
+				if containerProtocol == "" {
+					containerProtocol = corev1.ProtocolTCP
+				}
+				if containerProtocol != protocol {
+					continue
+				}
+				if (target.Type == intstr.String && containerPort.Name == target.StrVal) ||
+					(target.Type == intstr.Int && containerPort.ContainerPort == target.IntVal) {
+					found = true
+					if key := fmt.Sprintf("%s:%d", container.Name, containerPort.ContainerPort); !seen[key] {
+						seen[key] = true
+						mapping.ContainerPorts = append(mapping.ContainerPorts, key)
+					}
+				}
+			}
+		}
+		if !found && target.Type == intstr.String {
+			mapping.UnresolvedPods = append(mapping.UnresolvedPods, pods[i].Name)
+		}
+	}
+	return mapping
+}
+
+// servicePortName names a service port by its name, or its number when unnamed
+// servicePortName 使用端口名称指代 Service 端口，未命名时使用端口号
+func servicePortName(port corev1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprint(port.Port)
+}
+
+// podIsReady reports whether the pod's Ready condition is true
+// podIsReady 判断 Pod 的 Ready 条件是否为 True
+func podIsReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// failingReadiness returns the running containers of a pod that have a readiness probe but are not ready
+// failingReadiness 返回 Pod 中配置了就绪探针、正在运行但未就绪的容器
+func failingReadiness(pod *corev1.Pod) []string {
+	probed := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		if container.ReadinessProbe != nil {
+			probed[container.Name] = true
+		}
+	}
+	var failing []string
+	for _, status := range pod.Status.ContainerStatuses {
+		if probed[status.Name] && status.State.Running != nil && !status.Ready {
+			failing = append(failing, status.Name)
+		}
+	}
+	return failing
+}
+
+// renderServiceEndpoints renders the result as text: the service, its ports, its endpoints,
+// the selected pods and the warnings
+// renderServiceEndpoints 将结果渲染为文本：Service、端口、端点、选中的 Pod 以及警告
+func renderServiceEndpoints(result *types.ServiceEndpoints) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Service %s/%s (%s", result.Namespace, result.Service, result.Type)
+	if result.ClusterIP != "" {
+		sb.WriteString(" " + result.ClusterIP)
+	}
+	sb.WriteString(")")
+	if len(result.Selector) > 0 {
+		fmt.Fprintf(&sb, ", selector %s", labels.SelectorFromSet(result.Selector))
+	} else {
+		sb.WriteString(", no selector")
+	}
+	sb.WriteString("\n")
+
+	if len(result.Ports) > 0 {
+		sb.WriteString("Ports:\n")
+		for _, port := range result.Ports {
+			fmt.Fprintf(&sb, "  %s %d/%s -> %s: ", servicePortLabel(port), port.Port, port.Protocol, port.TargetPort)
+			switch {
+			case len(port.ContainerPorts) > 0:
+				sb.WriteString(strings.Join(port.ContainerPorts, ", "))
+			case len(result.Pods) == 0:
+				sb.WriteString("no pods")
+			default:
+				sb.WriteString("not declared by the containers")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if result.Type == string(corev1.ServiceTypeExternalName) {
+		sb.WriteString("ExternalName services resolve through DNS and have no endpoints\n")
+	} else {
+		fmt.Fprintf(&sb, "Endpoints (%s): %d ready, %d not ready\n", result.Source, result.Ready, result.NotReady)
+		for _, endpoint := range result.Endpoints {
+			state := "ready"
+			switch {
+			case endpoint.Terminating:
+				state = "terminating"
+			case !endpoint.Ready:
+				state = "not ready"
+			}
+			fmt.Fprintf(&sb, "  %s %s", endpoint.Address, state)
+			if endpoint.Pod != "" {
+				sb.WriteString(": pod " + endpoint.Pod)
+			}
+			if endpoint.Node != "" {
+				sb.WriteString(" on " + endpoint.Node)
+			}
+			if len(endpoint.Ports) > 0 {
+				fmt.Fprintf(&sb, " [%s]", strings.Join(endpoint.Ports, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(result.Selector) > 0 {
+		fmt.Fprintf(&sb, "Pods (%d selected):\n", len(result.Pods))
+		for _, pod := range result.Pods {
+			ready := "not ready"
+			if pod.Ready {
+				ready = "ready"
+			}
+			fmt.Fprintf(&sb, "  %s %s, %s", pod.Name, pod.Status, ready)
+			if pod.Endpoint != "" {
+				sb.WriteString(", endpoint " + pod.Endpoint)
+			} else {
+				sb.WriteString(", no endpoint")
+			}
+			if len(pod.ReadinessFailing) > 0 {
+				sb.WriteString(", readiness failing: " + strings.Join(pod.ReadinessFailing, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		sb.WriteString("Warnings:\n")
+		for _, warning := range result.Warnings {
+			sb.WriteString("  - " + warning + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// servicePortLabel names a port mapping by its name, or "port" when unnamed
+// servicePortLabel 使用名称指代端口映射，未命名时使用 "port"
+func servicePortLabel(port types.ServicePortMapping) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return "port"
+}
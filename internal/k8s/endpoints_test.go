@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// webService 返回 shop 命名空间中选择 app=web 的 Service web，端口 http 指向命名端口 http，端口 9090 指向未声明的数字端口
+func webService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.96.0.10",
+			Selector:  map[string]string{"app": "web"},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("http"), Protocol: corev1.ProtocolTCP},
+				{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+}
+
+// webPod 返回带有 app=web 标签、声明 http 8080 端口并配置就绪探针的运行中 Pod
+func webPod(name string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:           "web",
+			Ports:          []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			ReadinessProbe: &corev1.Probe{},
+		}}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "web",
+				Ready: ready,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+// TestGetServiceEndpointsNoPods 测试选择器不匹配任何 Pod 时没有端点并给出警告
+func TestGetServiceEndpointsNoPods(t *testing.T) {
+	ro, _ := newFakeOperations(t, webService())
+
+	result, err := ro.GetServiceEndpoints(context.Background(), "shop", "web", "test")
+	if err != nil {
+		t.Fatalf("GetServiceEndpoints failed: %v", err)
+	}
+	if result.Ready != 0 || result.NotReady != 0 || len(result.Endpoints) != 0 || len(result.Pods) != 0 || result.Source != EndpointSourceSlices {
+		t.Errorf("expected no endpoints and pods, got %+v", result)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "selector app=web matches no pods: the service routes to nothing" {
+		t.Errorf("unexpected warnings %q", result.Warnings)
+	}
+	for _, want := range []string{
+		"Service shop/web (ClusterIP 10.96.0.10), selector app=web",
+		"  http 80/TCP -> http: no pods",
+		"Endpoints (EndpointSlice): 0 ready, 0 not ready",
+		"Pods (0 selected):",
+	} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, result.Text)
+		}
+	}
+
+	if _, err := ro.GetServiceEndpoints(context.Background(), "shop", "missing", "test"); err == nil {
+		t.Error("expected an error for a missing service")
+	}
+}
+
+// TestGetServiceEndpointsPartiallyReady 测试一个就绪、一个就绪探针失败的后端：端点计数、后端 Pod、端口映射和警告
+func TestGetServiceEndpointsPartiallyReady(t *testing.T) {
+	ready, notReady := true, false
+	endpoint := func(ip, pod, node string, isReady *bool) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{
+			Addresses:  []string{ip},
+			Conditions: discoveryv1.EndpointConditions{Ready: isReady},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "shop"},
+			NodeName:   &node,
+		}
+	}
+	name, port, protocol := "http", int32(8080), corev1.ProtocolTCP
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Name: "web-x7k2p", Namespace: "shop", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{endpoint("10.1.0.6", "web-1", "node-2", &notReady), endpoint("10.1.0.5", "web-0", "node-1", &ready)},
+		Ports:       []discoveryv1.EndpointPort{{Name: &name, Port: &port, Protocol: &protocol}},
+	}
+	other := slice.DeepCopy()
+	other.Name, other.Labels = "api-q8w3z", map[string]string{discoveryv1.LabelServiceName: "api"}
+	ro, _ := newFakeOperations(t, webService(), webPod("web-0", true), webPod("web-1", false), slice, other)
+
+	result, err := ro.GetServiceEndpoints(context.Background(), "shop", "web", "test")
+	if err != nil {
+		t.Fatalf("GetServiceEndpoints failed: %v", err)
+	}
+	if result.Ready != 1 || result.NotReady != 1 || len(result.Endpoints) != 2 {
+		t.Fatalf("expected one ready and one not ready endpoint, got %+v", result.Endpoints)
+	}
+	if e := result.Endpoints[0]; e.Address != "10.1.0.5" || !e.Ready || e.Pod != "web-0" || e.Node != "node-1" {
+		t.Errorf("unexpected endpoint %+v", e)
+	}
+	if p := result.Pods[1]; p.Name != "web-1" || p.Ready || p.Endpoint != "10.1.0.6" || len(p.ReadinessFailing) != 1 {
+		t.Errorf("unexpected backing pod %+v", p)
+	}
+	if http := result.Ports[0]; http.TargetPort != "http" || len(http.ContainerPorts) != 1 || http.ContainerPorts[0] != "web:8080" {
+		t.Errorf("unexpected port mapping %+v", http)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "pod web-1: readiness probe failing on container web" {
+		t.Errorf("unexpected warnings %q", result.Warnings)
+	}
+	for _, want := range []string{
+		"  http 80/TCP -> http: web:8080\n  metrics 9090/TCP -> 9090: not declared by the containers",
+		"Endpoints (EndpointSlice): 1 ready, 1 not ready\n  10.1.0.5 ready: pod web-0 on node-1 [http 8080/TCP]\n  10.1.0.6 not ready: pod web-1 on node-2 [http 8080/TCP]",
+		"  web-1 Running, not ready, endpoint 10.1.0.6, readiness failing: web",
+	} {
+		if !strings.Contains(result.Text, want) {
+			t.Errorf("expected text to contain %q, got:\n%s", want, result.Text)
+		}
+	}
+}
+
+// TestGetServiceEndpointsLegacyFallback 测试 EndpointSlice API 不可用时回退到旧版 Endpoints，以及没有就绪端点时的警告
+func TestGetServiceEndpointsLegacyFallback(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Subsets: []corev1.EndpointSubset{{
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.1.0.6", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-1"}}},
+			Ports:             []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+		}},
+	}
+	ro, client := newFakeOperations(t, webService(), webPod("web-1", false), endpoints)
+	client.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "")
+	})
+
+	result, err := ro.GetServiceEndpoints(context.Background(), "shop", "web", "test")
+	if err != nil {
+		t.Fatalf("GetServiceEndpoints failed: %v", err)
+	}
+	if result.Source != EndpointSourceLegacy || result.Ready != 0 || result.NotReady != 1 || result.Pods[0].Endpoint != "10.1.0.6" {
+		t.Errorf("expected the legacy not-ready endpoint, got %+v", result)
+	}
+	if strings.Join(result.Warnings, "\n") != "pod web-1: readiness probe failing on container web\nno ready endpoints: the service routes to nothing" {
+		t.Errorf("unexpected warnings %q", result.Warnings)
+	}
+}
@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrClusterNotFound is wrapped by the errors returned for a cluster that is not configured
+// ErrClusterNotFound 包装在访问未配置的集群时返回的错误中
+var ErrClusterNotFound = errors.New("not found")
+
+// ErrClusterUnavailable is wrapped by the errors returned for a cluster that failed to load
+// ErrClusterUnavailable 包装在访问加载失败的集群时返回的错误中
+var ErrClusterUnavailable = errors.New("unavailable")
+
+// Error reasons reported by ClassifyError besides the Kubernetes API status reasons
+// ClassifyError 在 Kubernetes API 状态原因之外报告的错误类别
+const (
+	ErrorReasonNamespaceNotAllowed = "NamespaceNotAllowed"
+	ErrorReasonNamespaceProtected  = "NamespaceProtected"
+	ErrorReasonPathNotAllowed      = "PathNotAllowed"
+	ErrorReasonClusterNotFound     = "ClusterNotFound"
+	ErrorReasonClusterUnavailable  = "ClusterUnavailable"
+	ErrorReasonClusterUnreachable  = "ClusterUnreachable"
+	ErrorReasonUnsupported         = "Unsupported"
+	ErrorReasonCanceled            = "Canceled"
+	ErrorReasonUnknown             = "Unknown"
+)
+
+// ClassifyError maps an error from a Kubernetes operation to a machine-readable
+// category: the API status reason (NotFound, Forbidden, Conflict, Timeout, ...) for API
+// errors, or one of the ErrorReason* values for the errors of this package, context
+// cancellation and network failures. Anything else is ErrorReasonUnknown.
+// ClassifyError 将 Kubernetes 操作的错误映射为机器可读的类别：API 错误使用 API 状态原因
+// （NotFound、Forbidden、Conflict、Timeout 等），本包的错误、context 取消和网络故障使用 ErrorReason* 值，
+// 其他错误为 ErrorReasonUnknown。
+func ClassifyError(err error) types.ToolErrorDetails {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Reason != metav1.StatusReasonUnknown {
+		details := classifyAPIStatus(err, status.Status())
+		// A ServerTimeout names the operation, not the object
+		// ServerTimeout 的 Name 是操作名称，而不是对象名称
+		if status.Status().Reason != metav1.StatusReasonServerTimeout {
+			details.Resource = statusResource(status.Status().Details)
+		}
+		return details
+	}
+
+	switch {
+	case errors.Is(err, ErrNamespaceNotAllowed):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonNamespaceNotAllowed,
+			Suggestion: "the server's namespace policy denies this namespace; use one of the allowed namespaces",
+		}
+	case errors.Is(err, ErrNamespaceProtected):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonNamespaceProtected,
+			Suggestion: "system namespaces and those in --protected-namespaces cannot be deleted; delete the objects in it instead",
+		}
+	case errors.Is(err, ErrPathNotAllowed):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonPathNotAllowed,
+			Suggestion: "use a path without \"..\"; files can only be copied into pods under --copy-allowed-paths",
+		}
+	case errors.Is(err, ErrClusterNotFound):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonClusterNotFound,
+			Suggestion: "check the cluster name against the clusters in the kubeconfig",
+		}
+	case errors.Is(err, ErrClusterUnavailable):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonClusterUnavailable,
+			Suggestion: "the cluster failed to load from the kubeconfig; fix its entry or use another cluster",
+		}
+	case errors.Is(err, ErrEphemeralContainersUnsupported):
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonUnsupported,
+			Suggestion: "the cluster does not support ephemeral containers; run a separate debug pod instead",
+		}
+	case errors.Is(err, ErrWaitTimeout):
+		return types.ToolErrorDetails{
+			Reason:     string(metav1.StatusReasonTimeout),
+			Retryable:  true,
+			Suggestion: "the condition was not met in time; check the object's status, or wait again with a longer timeout",
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.ToolErrorDetails{
+			Reason:     string(metav1.StatusReasonTimeout),
+			Retryable:  true,
+			Suggestion: "the request timed out; retry, possibly with a narrower query",
+		}
+	case errors.Is(err, context.Canceled):
+		return types.ToolErrorDetails{Reason: ErrorReasonCanceled}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return types.ToolErrorDetails{
+			Reason:     ErrorReasonClusterUnreachable,
+			Retryable:  true,
+			Suggestion: "the API server could not be reached; check the network and retry",
+		}
+	}
+	return types.ToolErrorDetails{Reason: ErrorReasonUnknown}
+}
+
+// classifyAPIStatus classifies an API error by its status reason
+// classifyAPIStatus 按状态原因对 API 错误分类
+func classifyAPIStatus(err error, status metav1.Status) types.ToolErrorDetails {
+	details := types.ToolErrorDetails{Reason: string(status.Reason)}
+	switch {
+	case apierrors.IsNotFound(err):
+		details.Suggestion = "check the name, namespace and cluster; list the resources to find the right name"
+	case apierrors.IsAlreadyExists(err):
+		details.Suggestion = "an object with this name already exists; pick another name or update the existing object"
+	case apierrors.IsConflict(err):
+		details.Retryable = true
+		details.Suggestion = "the object was modified concurrently; fetch it again and retry"
+	case apierrors.IsForbidden(err):
+		details.Suggestion = "the credentials lack RBAC permission for this request; ask a cluster administrator to grant it"
+	case apierrors.IsUnauthorized(err):
+		details.Suggestion = "the cluster rejected the credentials; check the kubeconfig or token"
+	case apierrors.IsInvalid(err):
+		details.Suggestion = "the object failed validation; fix the fields named in the message"
+	case apierrors.IsBadRequest(err):
+		details.Suggestion = "the request was malformed; check the arguments"
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		details.Retryable = true
+		details.Suggestion = "the request timed out; retry, possibly with a narrower query"
+	case apierrors.IsTooManyRequests(err):
+		details.Retryable = true
+		details.Suggestion = "the API server is throttling requests; retry later"
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+			details.Suggestion = fmt.Sprintf("the API server is throttling requests; retry after %d seconds", seconds)
+		}
+	case apierrors.IsServiceUnavailable(err), apierrors.IsInternalError(err):
+		details.Retryable = true
+		details.Suggestion = "the API server failed temporarily; retry later"
+	case apierrors.IsMethodNotSupported(err):
+		details.Suggestion = "the resource does not support this operation"
+	case apierrors.IsGone(err), apierrors.IsResourceExpired(err):
+		details.Retryable = true
+		details.Suggestion = "the requested resource version expired; retry to read the latest state"
+	case apierrors.IsRequestEntityTooLargeError(err):
+		details.Suggestion = "the request is too large; send a smaller object or patch"
+	}
+	return details
+}
+
+// statusResource formats the object named in an API status as kind[.group]/name
+// statusResource 将 API 状态中的对象格式化为 kind[.group]/name
+func statusResource(details *metav1.StatusDetails) string {
+	if details == nil || details.Kind == "" {
+		return ""
+	}
+	resource := details.Kind
+	if details.Group != "" {
+		resource += "." + details.Group
+	}
+	if details.Name != "" {
+		resource += "/" + details.Name
+	}
+	return resource
+}
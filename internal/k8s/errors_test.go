@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// TestClassifyError 测试每类 API 错误和本包错误映射到预期的类别、资源和是否可重试
+func TestClassifyError(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	dialErr := &url.Error{Op: "Get", URL: "https://10.0.0.1:6443/api", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	tests := []struct {
+		name      string
+		err       error
+		reason    string
+		resource  string
+		retryable bool
+	}{
+		{"not found", apierrors.NewNotFound(pods, "web-0"), "NotFound", "pods/web-0", false},
+		{"wrapped not found", fmt.Errorf("failed to get resource: %w", apierrors.NewNotFound(deployments, "web")), "NotFound", "deployments.apps/web", false},
+		{"already exists", apierrors.NewAlreadyExists(deployments, "web"), "AlreadyExists", "deployments.apps/web", false},
+		{"conflict", apierrors.NewConflict(deployments, "web", errors.New("object was modified")), "Conflict", "deployments.apps/web", true},
+		{"forbidden", apierrors.NewForbidden(pods, "web-0", errors.New("RBAC denied")), "Forbidden", "pods/web-0", false},
+		{"unauthorized", apierrors.NewUnauthorized("invalid token"), "Unauthorized", "", false},
+		{"invalid", apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", field.ErrorList{field.Required(field.NewPath("spec"), "")}), "Invalid", "Deployment.apps/web", false},
+		{"bad request", apierrors.NewBadRequest("bad selector"), "BadRequest", "", false},
+		{"timeout", apierrors.NewTimeoutError("request timed out", 5), "Timeout", "", true},
+		{"server timeout", apierrors.NewServerTimeout(pods, "list", 2), "ServerTimeout", "", true},
+		{"too many requests", apierrors.NewTooManyRequests("slow down", 3), "TooManyRequests", "", true},
+		{"service unavailable", apierrors.NewServiceUnavailable("etcd down"), "ServiceUnavailable", "", true},
+		{"internal error", apierrors.NewInternalError(errors.New("boom")), "InternalError", "", true},
+		{"method not supported", apierrors.NewMethodNotSupported(pods, "patch"), "MethodNotAllowed", "pods", false},
+		{"resource expired", apierrors.NewResourceExpired("too old resource version"), "Expired", "", true},
+		{"gone", apierrors.NewGone("gone"), "Gone", "", true},
+		{"request too large", apierrors.NewRequestEntityTooLargeError("limit is 3MB"), "RequestEntityTooLarge", "", false},
+		{"namespace policy", fmt.Errorf("%w: namespace \"kube-system\" is not in the allowed namespaces", ErrNamespaceNotAllowed), ErrorReasonNamespaceNotAllowed, "", false},
+		{"namespace policy in transport", &url.Error{Op: "Get", URL: "https://10.0.0.1:6443/api", Err: ErrNamespaceNotAllowed}, ErrorReasonNamespaceNotAllowed, "", false},
+		{"namespace protected", fmt.Errorf("%w: kube-system cannot be deleted", ErrNamespaceProtected), ErrorReasonNamespaceProtected, "", false},
+		{"path not allowed", fmt.Errorf("%w: /etc/passwd is outside the allowed directories /tmp", ErrPathNotAllowed), ErrorReasonPathNotAllowed, "", false},
+		{"cluster not found", fmt.Errorf("client for cluster prod %w", ErrClusterNotFound), ErrorReasonClusterNotFound, "", false},
+		{"cluster unavailable", fmt.Errorf("cluster prod is %w: %w", ErrClusterUnavailable, errors.New("bad ca.crt")), ErrorReasonClusterUnavailable, "", false},
+		{"ephemeral containers", ErrEphemeralContainersUnsupported, ErrorReasonUnsupported, "", false},
+		{"wait timeout", ErrWaitTimeout, "Timeout", "", true},
+		{"deadline exceeded", fmt.Errorf("failed to list pods: %w", context.DeadlineExceeded), "Timeout", "", true},
+		{"canceled", context.Canceled, ErrorReasonCanceled, "", false},
+		{"unreachable", dialErr, ErrorReasonClusterUnreachable, "", true},
+		{"unknown", errors.New("something else"), ErrorReasonUnknown, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			want := types.ToolErrorDetails{Reason: tt.reason, Resource: tt.resource, Retryable: tt.retryable}
+			got.Suggestion = ""
+			if got != want {
+				t.Errorf("ClassifyError(%v) = %+v, want %+v", tt.err, got, want)
+			}
+		})
+	}
+}
+
+// TestClassifyErrorSuggestion 测试建议信息，包括限流时服务器建议的重试间隔
+func TestClassifyErrorSuggestion(t *testing.T) {
+	if got := ClassifyError(apierrors.NewTooManyRequests("slow down", 3)).Suggestion; got != "the API server is throttling requests; retry after 3 seconds" {
+		t.Errorf("unexpected suggestion %q", got)
+	}
+	if got := ClassifyError(apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web-0")).Suggestion; got == "" {
+		t.Errorf("expected a suggestion for NotFound")
+	}
+	if got := ClassifyError(errors.New("something else")).Suggestion; got != "" {
+		t.Errorf("expected no suggestion for an unknown error, got %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxStreamedEvents bounds the events kept by StreamEvents; later events are dropped
+// and the stream is marked truncated
+// maxStreamedEvents 限制 StreamEvents 保留的事件数，超出的事件被丢弃并将结果标记为截断
+const maxStreamedEvents = 1000
+
+// EventFilter selects events by their involved object; empty fields match any object
+// EventFilter 按关联对象筛选事件，字段为空时匹配任意对象
+type EventFilter struct {
+	Kind string
+	Name string
+}
+
+// fieldSelector returns the involvedObject field selector of the filter
+// fieldSelector 返回筛选条件对应的 involvedObject 字段选择器
+func (f EventFilter) fieldSelector() string {
+	// fields.SelectorFromSet iterates a map, so build the terms in a fixed order
+	// fields.SelectorFromSet 遍历 map，因此按固定顺序构造条件
+	var selectors []fields.Selector
+	if f.Kind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", f.Kind))
+	}
+	if f.Name != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.name", f.Name))
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+// StreamEvents watches the events of a namespace for the given duration and returns those
+// created or updated in that time, in arrival order with their offset from the start.
+// Only new events are returned: the watch starts at the current resourceVersion. onEvent,
+// if set, is called for each event as it arrives. Reaching the duration is not an error;
+// a cancelled ctx returns the context error. The watch is always stopped before returning.
+// StreamEvents 在给定时长内监听命名空间中的事件，按到达顺序返回期间新建或更新的事件及其相对开始时间的偏移。
+// 监听从当前 resourceVersion 开始，因此只返回新事件。onEvent 不为空时在每个事件到达时调用。
+// 达到时长不是错误；ctx 取消时返回 context 错误。返回前总会停止监听。
+func (ro *ResourceOperations) StreamEvents(ctx context.Context, namespace string, filter EventFilter, duration time.Duration, clusterName string, onEvent func(types.StreamedEvent)) (*types.EventStream, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	streamCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	selector := filter.fieldSelector()
+	stream := &types.EventStream{Namespace: namespace, Filter: selector, Events: []types.StreamedEvent{}}
+	finish := func() *types.EventStream {
+		stream.Duration = time.Since(start).Round(time.Millisecond).String()
+		return stream
+	}
+	record := func(event *corev1.Event) {
+		if len(stream.Events) >= maxStreamedEvents {
+			stream.Truncated = true
+			return
+		}
+		streamed := types.StreamedEvent{
+			Offset:  "+" + time.Since(start).Round(time.Millisecond).String(),
+			Type:    event.Type,
+			Object:  event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Reason:  event.Reason,
+			Message: event.Message,
+			Source:  event.Source.Component,
+			Count:   int(event.Count),
+		}
+		stream.Events = append(stream.Events, streamed)
+		if onEvent != nil {
+			onEvent(streamed)
+		}
+	}
+
+	resourceVersion := ""
+	for {
+		// A list with limit 1 gives the current resourceVersion, so the watch skips past events
+		// limit 为 1 的列表请求返回当前 resourceVersion，使监听跳过已有事件
+		if resourceVersion == "" {
+			list, err := client.CoreV1().Events(namespace).List(streamCtx, metav1.ListOptions{FieldSelector: selector, Limit: 1})
+			if err != nil {
+				return streamError(ctx, streamCtx, finish(), fmt.Errorf("failed to list events: %w", err))
+			}
+			resourceVersion = list.ResourceVersion
+		}
+
+		w, err := client.CoreV1().Events(namespace).Watch(streamCtx, metav1.ListOptions{
+			FieldSelector:   selector,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			return streamError(ctx, streamCtx, finish(), fmt.Errorf("failed to watch events: %w", err))
+		}
+		resourceVersion, err = consumeEvents(streamCtx, w, resourceVersion, record)
+		w.Stop()
+		if err != nil {
+			return streamError(ctx, streamCtx, finish(), err)
+		}
+		// The watch was closed by the server; resume from the last resourceVersion after a short pause
+		// 服务器关闭了监听，短暂等待后从最后的 resourceVersion 继续
+		select {
+		case <-streamCtx.Done():
+			return streamError(ctx, streamCtx, finish(), streamCtx.Err())
+		case <-time.After(waitRetryInterval):
+		}
+	}
+}
+
+// consumeEvents records added and updated events until the watch closes or ctx is done,
+// and returns the resourceVersion to resume from ("" to start over with a fresh list)
+// consumeEvents 记录新增和更新的事件，直到监听关闭或 ctx 结束，返回继续监听的 resourceVersion（为空时重新列出）
+func consumeEvents(ctx context.Context, w watch.Interface, resourceVersion string, record func(*corev1.Event)) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				// A repeated event updates the count of the existing object
+				// 重复发生的事件会更新已有对象的计数
+				if e, ok := event.Object.(*corev1.Event); ok {
+					resourceVersion = e.ResourceVersion
+					record(e)
+				}
+			case watch.Error:
+				// An expired resourceVersion shows up here; list again for a fresh one
+				// resourceVersion 过期等错误会出现在这里，重新列出以获取新的 resourceVersion
+				return "", nil
+			}
+		}
+	}
+}
+
+// streamError maps a failure while streaming: reaching the duration ends the stream
+// normally and a cancelled parent context is returned as is
+// streamError 转换监听中的错误：达到时长时正常结束，父 context 取消时原样返回
+func streamError(parent, streamCtx context.Context, stream *types.EventStream, err error) (*types.EventStream, error) {
+	if parent.Err() != nil {
+		return stream, parent.Err()
+	}
+	if streamCtx.Err() != nil {
+		return stream, nil
+	}
+	return stream, err
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newStreamTestEvent 创建关联 Pod web-1 的事件
+func newStreamTestEvent(name, resourceVersion, reason string, count int32) *corev1.Event {
+	return &corev1.Event{
+		TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: resourceVersion},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1", Namespace: "default"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " message",
+		Source:         corev1.EventSource{Component: "kubelet"},
+		Count:          count,
+	}
+}
+
+// eventWatchRequest 记录收到的监听请求参数
+type eventWatchRequest struct {
+	fieldSelector   string
+	resourceVersion string
+}
+
+// fakeEventStreamServer 模拟事件的列表和监听接口：列表返回 resourceVersion 100，监听依次发送 events 后保持连接，
+// 直到客户端断开，首次断开时关闭 stopped
+func fakeEventStreamServer(t *testing.T, events ...*corev1.Event) (*httptest.Server, chan eventWatchRequest, chan struct{}) {
+	t.Helper()
+
+	watches := make(chan eventWatchRequest, 1)
+	stopped := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v1/namespaces/default/events" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("watch") != "true" {
+			json.NewEncoder(w).Encode(corev1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: "100"}})
+			return
+		}
+
+		select {
+		case watches <- eventWatchRequest{r.URL.Query().Get("fieldSelector"), r.URL.Query().Get("resourceVersion")}:
+		default:
+		}
+		flusher := w.(http.Flusher)
+		flusher.Flush()
+		for _, event := range events {
+			json.NewEncoder(w).Encode(map[string]interface{}{"type": "ADDED", "object": event})
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+		once.Do(func() { close(stopped) })
+	}))
+	t.Cleanup(server.Close)
+	return server, watches, stopped
+}
+
+// TestStreamEvents 测试只监听新事件、按 involvedObject 筛选，并按到达顺序返回带相对时间的事件
+func TestStreamEvents(t *testing.T) {
+	server, watches, stopped := fakeEventStreamServer(t,
+		newStreamTestEvent("web-1.a", "101", "BackOff", 1),
+		newStreamTestEvent("web-1.b", "102", "Unhealthy", 3),
+	)
+	ro := newServerOperations(t, server)
+
+	var arrived []string
+	stream, err := ro.StreamEvents(context.Background(), "default", EventFilter{Kind: "Pod", Name: "web-1"}, 300*time.Millisecond, "test", func(event types.StreamedEvent) {
+		arrived = append(arrived, event.Reason)
+	})
+	if err != nil {
+		t.Fatalf("StreamEvents failed: %v", err)
+	}
+
+	request := <-watches
+	if request.fieldSelector != "involvedObject.kind=Pod,involvedObject.name=web-1" || request.resourceVersion != "100" {
+		t.Errorf("unexpected watch request: %+v", request)
+	}
+	if stream.Filter != request.fieldSelector || stream.Namespace != "default" || stream.Duration == "" {
+		t.Errorf("unexpected stream: %+v", stream)
+	}
+	if len(stream.Events) != 2 || stream.Events[0].Reason != "BackOff" || stream.Events[1].Reason != "Unhealthy" {
+		t.Fatalf("unexpected events: %+v", stream.Events)
+	}
+	if event := stream.Events[1]; event.Object != "Pod/web-1" || event.Count != 3 || event.Source != "kubelet" || !strings.HasPrefix(event.Offset, "+") {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if strings.Join(arrived, ",") != "BackOff,Unhealthy" {
+		t.Errorf("onEvent got %v", arrived)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Error("watch was not stopped after the duration")
+	}
+}
+
+// TestStreamEventsCancelled 测试 ctx 取消时立即返回 context 错误并停止监听
+func TestStreamEventsCancelled(t *testing.T) {
+	server, _, stopped := fakeEventStreamServer(t, newStreamTestEvent("web-1.a", "101", "BackOff", 1))
+	ro := newServerOperations(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	stream, err := ro.StreamEvents(ctx, "default", EventFilter{}, time.Minute, "test", func(types.StreamedEvent) {
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("StreamEvents returned after %s", time.Since(start))
+	}
+	if len(stream.Events) != 1 || stream.Filter != "" {
+		t.Errorf("unexpected partial stream: %+v", stream)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Error("watch was not stopped after cancellation")
+	}
+}
@@ -0,0 +1,442 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/openapi"
+)
+
+// openAPISchemaTTL is how long the discovered resources and OpenAPI v3 documents of a
+// cluster are reused before they are fetched again, e.g. to see a newly installed CRD
+// openAPISchemaTTL 为集群已发现的资源和 OpenAPI v3 文档的复用时间，过期后重新获取，例如以便看到新安装的 CRD
+const openAPISchemaTTL = 10 * time.Minute
+
+// maxRefDepth bounds how many $ref and allOf wrappers are followed to resolve a schema
+// maxRefDepth 限制解析 schema 时跟随的 $ref 和 allOf 包装层数
+const maxRefDepth = 16
+
+// UnknownFieldError is returned by ExplainResource for a field path that does not exist.
+// Parent explains the nearest valid parent path, whose fields serve as a hint.
+// UnknownFieldError 是 ExplainResource 在字段路径不存在时返回的错误。Parent 为最近的有效父路径的说明，
+// 其子字段可作为提示
+type UnknownFieldError struct {
+	Field  string
+	Parent *types.ExplainResult
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("field %q does not exist in %s", e.Field, ExplainPath(e.Parent))
+}
+
+// ExplainPath returns the path an explanation describes, e.g. "deployments.spec.replicas"
+// ExplainPath 返回说明所描述的路径，例如 "deployments.spec.replicas"
+func ExplainPath(r *types.ExplainResult) string {
+	if r.FieldPath == "" {
+		return r.Resource
+	}
+	return r.Resource + "." + r.FieldPath
+}
+
+// openAPICache holds what explain reads from one cluster: the discovered API resources
+// and the OpenAPI v3 documents fetched so far, by group version path ("apis/apps/v1")
+// openAPICache 保存 explain 从一个集群读取的内容：已发现的 API 资源，以及按 group version 路径 ("apis/apps/v1") 已获取的 OpenAPI v3 文档
+type openAPICache struct {
+	mu        sync.Mutex
+	fetched   time.Time
+	groups    []*metav1.APIGroup
+	resources []*metav1.APIResourceList
+	paths     map[string]openapi.GroupVersion
+	documents map[string]*openAPIDocument
+}
+
+// openAPIDocument is the part of an OpenAPI v3 document explain navigates
+// openAPIDocument 是 explain 遍历的 OpenAPI v3 文档部分
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// openAPISchema is the part of a schema object explain renders
+// openAPISchema 是 explain 渲染的 schema 对象部分
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref"`
+	AllOf                []*openAPISchema          `json:"allOf"`
+	Type                 string                    `json:"type"`
+	Description          string                    `json:"description"`
+	Properties           map[string]*openAPISchema `json:"properties"`
+	Required             []string                  `json:"required"`
+	Items                *openAPISchema            `json:"items"`
+	AdditionalProperties *schemaOrBool             `json:"additionalProperties"`
+	IntOrString          bool                      `json:"x-kubernetes-int-or-string"`
+	GroupVersionKinds    []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind"`
+}
+
+// schemaOrBool decodes additionalProperties, which is either a schema or a boolean
+// schemaOrBool 解码 additionalProperties，其值为 schema 或布尔值
+type schemaOrBool struct {
+	schema *openAPISchema
+}
+
+func (s *schemaOrBool) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return json.Unmarshal(data, &s.schema)
+	}
+	return nil
+}
+
+// SetOpenAPIClient sets the client the OpenAPI v3 documents of a cluster are fetched
+// with, instead of its clientset's discovery client. Fake clientsets cannot serve them.
+// SetOpenAPIClient 设置获取集群 OpenAPI v3 文档的客户端，替代 clientset 的发现客户端。fake clientset 无法提供这些文档。
+func (cm *ClusterManager) SetOpenAPIClient(clusterName string, client openapi.Client) {
+	cm.openAPIMu.Lock()
+	defer cm.openAPIMu.Unlock()
+	if cm.openAPIClients == nil {
+		cm.openAPIClients = make(map[string]openapi.Client)
+	}
+	cm.openAPIClients[clusterName] = client
+	delete(cm.openAPI, clusterName)
+}
+
+// openAPIState returns the explain cache of a cluster, locked, with discovery and the
+// document paths fetched again once openAPISchemaTTL has passed. The caller unlocks it.
+// openAPIState 返回集群已加锁的 explain 缓存，超过 openAPISchemaTTL 后重新获取发现信息和文档路径。调用方负责解锁。
+func (cm *ClusterManager) openAPIState(clusterName string) (*openAPICache, error) {
+	client, err := cm.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.openAPIMu.Lock()
+	if cm.openAPI == nil {
+		cm.openAPI = make(map[string]*openAPICache)
+	}
+	cache, ok := cm.openAPI[clusterName]
+	if !ok {
+		cache = &openAPICache{}
+		cm.openAPI[clusterName] = cache
+	}
+	openAPIClient := cm.openAPIClients[clusterName]
+	cm.openAPIMu.Unlock()
+
+	cache.mu.Lock()
+	if !cache.fetched.IsZero() && time.Since(cache.fetched) < openAPISchemaTTL {
+		return cache, nil
+	}
+
+	if openAPIClient == nil {
+		// The fake discovery client panics when asked for OpenAPI v3
+		// fake 发现客户端在请求 OpenAPI v3 时会 panic
+		if _, fake := client.Discovery().(*fakediscovery.FakeDiscovery); fake {
+			cache.mu.Unlock()
+			return nil, fmt.Errorf("cluster %s does not serve OpenAPI v3 documents", clusterName)
+		}
+		openAPIClient = client.Discovery().OpenAPIV3()
+	}
+	groups, resources, err := client.Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		cache.mu.Unlock()
+		return nil, fmt.Errorf("failed to discover API resources: %w", err)
+	}
+	paths, err := openAPIClient.Paths()
+	if err != nil {
+		cache.mu.Unlock()
+		return nil, fmt.Errorf("failed to list OpenAPI v3 documents: %w", err)
+	}
+
+	cache.fetched = time.Now()
+	cache.groups = groups
+	cache.resources = resources
+	cache.paths = paths
+	cache.documents = make(map[string]*openAPIDocument)
+	return cache, nil
+}
+
+// lookupResource finds the resource selected by a resource_type value: its plural,
+// singular or short name or its kind, optionally qualified by a group
+// ("crontabs.stable.example.com"). The group's preferred version wins.
+// lookupResource 查找 resource_type 值选择的资源：复数、单数或简称，或者类型名，可以带 group 限定
+// ("crontabs.stable.example.com")。优先使用 group 的首选版本。
+func (c *openAPICache) lookupResource(resourceType string) (schema.GroupVersionKind, string, bool) {
+	name, group, qualified := strings.Cut(strings.ToLower(resourceType), ".")
+	preferred := make(map[string]string, len(c.groups))
+	for _, g := range c.groups {
+		preferred[g.Name] = g.PreferredVersion.Version
+	}
+
+	var found schema.GroupVersionKind
+	var plural string
+	for _, list := range c.resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || (qualified && gv.Group != group) {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !resourceMatches(r, name) {
+				continue
+			}
+			// Keep the first group found, in its preferred version
+			// 保留最先找到的 group，并使用其首选版本
+			if plural == "" || (found.Group == gv.Group && gv.Version == preferred[gv.Group]) {
+				found, plural = gv.WithKind(r.Kind), r.Name
+			}
+		}
+	}
+	return found, plural, plural != ""
+}
+
+// resourceMatches reports whether name is the plural, singular or short name or the kind of r
+// resourceMatches 判断 name 是否为 r 的复数、单数名称、简称或类型名
+func resourceMatches(r metav1.APIResource, name string) bool {
+	if r.Name == name || r.SingularName == name || strings.ToLower(r.Kind) == name {
+		return true
+	}
+	for _, short := range r.ShortNames {
+		if short == name {
+			return true
+		}
+	}
+	return false
+}
+
+// document returns the OpenAPI v3 document of a group version, fetching it on first use
+// document 返回 group version 的 OpenAPI v3 文档，首次使用时获取
+func (c *openAPICache) document(gv schema.GroupVersion) (*openAPIDocument, error) {
+	path := "apis/" + gv.String()
+	if gv.Group == "" {
+		path = "api/" + gv.Version
+	}
+	if doc, ok := c.documents[path]; ok {
+		return doc, nil
+	}
+	groupVersion, ok := c.paths[path]
+	if !ok {
+		return nil, fmt.Errorf("the cluster does not publish an OpenAPI v3 document for %s", gv)
+	}
+	data, err := groupVersion.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the OpenAPI v3 document of %s: %w", gv, err)
+	}
+	doc, err := parseOpenAPIDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI v3 document of %s: %w", gv, err)
+	}
+	c.documents[path] = doc
+	return doc, nil
+}
+
+// parseOpenAPIDocument decodes an OpenAPI v3 document in JSON
+// parseOpenAPIDocument 解码 JSON 格式的 OpenAPI v3 文档
+func parseOpenAPIDocument(data []byte) (*openAPIDocument, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ExplainResource describes a resource type or one of its fields like `kubectl explain`,
+// from the OpenAPI v3 schema the cluster publishes, so CRDs and aggregated APIs are
+// covered. fieldPath is dot-separated (spec.template.spec.containers); list fields are
+// stepped through to their items. A field that does not exist returns *UnknownFieldError.
+// ExplainResource 与 `kubectl explain` 相同，根据集群发布的 OpenAPI v3 schema 说明资源类型或其字段，
+// 因此也支持 CRD 和聚合 API。fieldPath 以点分隔 (spec.template.spec.containers)，列表字段会进入其元素。
+// 字段不存在时返回 *UnknownFieldError。
+func (ro *ResourceOperations) ExplainResource(ctx context.Context, resourceType, fieldPath, clusterName string) (*types.ExplainResult, error) {
+	if clusterName == "" {
+		clusterName = ro.clusterManager.GetCurrentCluster()
+	}
+	cache, err := ro.clusterManager.openAPIState(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer cache.mu.Unlock()
+
+	gvk, plural, ok := cache.lookupResource(resourceType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type: %s (not found by discovery)", resourceType)
+	}
+	doc, err := cache.document(gvk.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	root := doc.kindSchema(gvk)
+	if root == nil {
+		return nil, fmt.Errorf("the OpenAPI v3 document of %s has no schema for %s", gvk.GroupVersion(), gvk.Kind)
+	}
+	return doc.explain(gvk, plural, root, fieldPath)
+}
+
+// kindSchema returns the schema tagged with gvk in x-kubernetes-group-version-kind
+// kindSchema 返回 x-kubernetes-group-version-kind 中标记为 gvk 的 schema
+func (d *openAPIDocument) kindSchema(gvk schema.GroupVersionKind) *openAPISchema {
+	for _, s := range d.Components.Schemas {
+		for _, tag := range s.GroupVersionKinds {
+			if tag.Group == gvk.Group && tag.Version == gvk.Version && tag.Kind == gvk.Kind {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// explain walks fieldPath down from root and describes where it ends
+// explain 从 root 沿 fieldPath 向下遍历，并说明终点字段
+func (d *openAPIDocument) explain(gvk schema.GroupVersionKind, plural string, root *openAPISchema, fieldPath string) (*types.ExplainResult, error) {
+	result := &types.ExplainResult{
+		Kind:        gvk.Kind,
+		APIVersion:  gvk.GroupVersion().String(),
+		Resource:    plural,
+		Type:        d.typeName(root),
+		Description: d.description(root),
+		Fields:      d.fields(root),
+	}
+
+	current := root
+	var walked []string
+	for _, name := range strings.Split(strings.Trim(fieldPath, ". "), ".") {
+		if name == "" {
+			continue
+		}
+		field, ok := d.elements(current).Properties[name]
+		if !ok {
+			return nil, &UnknownFieldError{Field: name, Parent: result}
+		}
+		current = field
+		walked = append(walked, name)
+		result = &types.ExplainResult{
+			Kind:        result.Kind,
+			APIVersion:  result.APIVersion,
+			Resource:    result.Resource,
+			FieldPath:   strings.Join(walked, "."),
+			Type:        d.typeName(field),
+			Description: d.description(field),
+			Fields:      d.fields(field),
+		}
+	}
+	return result, nil
+}
+
+// resolve follows the $ref and single allOf wrappers of a schema, returning the schema
+// they lead to and the name of the last type referenced ("" for an inline schema)
+// resolve 跟随 schema 的 $ref 和单个 allOf 包装，返回最终的 schema 以及最后引用的类型名 (内联 schema 为 "")
+func (d *openAPIDocument) resolve(s *openAPISchema) (*openAPISchema, string) {
+	var ref string
+	for i := 0; s != nil && i < maxRefDepth; i++ {
+		switch {
+		case s.Ref != "":
+			name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+			ref = name[strings.LastIndex(name, ".")+1:]
+			s = d.Components.Schemas[name]
+		case len(s.AllOf) == 1 && len(s.Properties) == 0:
+			s = s.AllOf[0]
+		default:
+			return s, ref
+		}
+	}
+	if s == nil {
+		return &openAPISchema{}, ref
+	}
+	return s, ref
+}
+
+// elements resolves a schema and steps through lists to the schema of their items
+// elements 解析 schema，并对列表进入其元素的 schema
+func (d *openAPIDocument) elements(s *openAPISchema) *openAPISchema {
+	s, _ = d.resolve(s)
+	for i := 0; s.Type == "array" && s.Items != nil && i < maxRefDepth; i++ {
+		s, _ = d.resolve(s.Items)
+	}
+	return s
+}
+
+// typeName renders the type of a schema like kubectl explain: string, integer, []Container,
+// map[string]string, a referenced type's name such as ObjectMeta, or Object
+// typeName 以 kubectl explain 的方式渲染 schema 的类型：string、integer、[]Container、map[string]string、
+// 被引用的类型名 (例如 ObjectMeta) 或 Object
+func (d *openAPIDocument) typeName(s *openAPISchema) string {
+	target, ref := d.resolve(s)
+	switch {
+	case ref != "":
+		return ref
+	case target.Type == "array" && target.Items != nil:
+		return "[]" + d.typeName(target.Items)
+	case target.AdditionalProperties != nil && target.AdditionalProperties.schema != nil:
+		return "map[string]" + d.typeName(target.AdditionalProperties.schema)
+	case target.IntOrString:
+		return "IntOrString"
+	case target.Type == "" || target.Type == "object":
+		return "Object"
+	}
+	return target.Type
+}
+
+// description returns a schema's own description, else the one of the type it references
+// description 返回 schema 自身的描述，没有时返回其引用类型的描述
+func (d *openAPIDocument) description(s *openAPISchema) string {
+	if s.Description != "" {
+		return s.Description
+	}
+	target, _ := d.resolve(s)
+	return target.Description
+}
+
+// fields lists the immediate children of a schema (of its items for lists) by name,
+// each with its type and the first sentence of its description
+// fields 按名称列出 schema (列表为其元素) 的直接子字段，包含类型和描述的第一句
+func (d *openAPIDocument) fields(s *openAPISchema) []types.ExplainField {
+	s = d.elements(s)
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	fields := make([]types.ExplainField, 0, len(s.Properties))
+	for name, field := range s.Properties {
+		fields = append(fields, types.ExplainField{
+			Name:        name,
+			Type:        d.typeName(field),
+			Required:    required[name],
+			Description: firstSentence(d.description(field)),
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// firstSentence returns text up to the end of its first sentence or line
+// firstSentence 返回文本第一句或第一行的内容
+func firstSentence(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = strings.TrimSpace(text[:i])
+	}
+	for i := 0; i+2 < len(text); i++ {
+		// A period followed by a space and a capital letter, so "e.g. foo" is kept whole
+		// 句点后跟空格和大写字母，因此 "e.g. foo" 不会被截断
+		if text[i] == '.' && text[i+1] == ' ' && unicode.IsUpper(rune(text[i+2])) {
+			return text[:i+1]
+		}
+	}
+	return text
+}
@@ -0,0 +1,216 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+// countingGroupVersion 记录 Schema 被调用的次数，用于检查缓存
+type countingGroupVersion struct {
+	openapitest.FakeGroupVersion
+	calls *int
+}
+
+func (g countingGroupVersion) Schema(contentType string) ([]byte, error) {
+	*g.calls++
+	return g.FakeGroupVersion.Schema(contentType)
+}
+
+// newExplainOperations 创建一个发现接口包含 apps/v1 和示例 CRD 的集群，OpenAPI v3 文档取自 testdata/openapi
+// (apps/v1 为真实集群文档中 Deployment 引用的部分)。返回值 fetches 统计获取文档的次数
+func newExplainOperations(t *testing.T) (*ResourceOperations, *int) {
+	t.Helper()
+	ro, client := newFakeOperations(t)
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", ShortNames: []string{"po"}}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}},
+			{Name: "deployments/scale", Kind: "Scale"},
+		}},
+		{GroupVersion: "stable.example.com/v1", APIResources: []metav1.APIResource{{Name: "crontabs", SingularName: "crontab", Kind: "CronTab", ShortNames: []string{"ct"}}}},
+	}
+
+	fetches := 0
+	paths := map[string]openapi.GroupVersion{}
+	for path, file := range map[string]string{"apis/apps/v1": "apis__apps__v1.json", "apis/stable.example.com/v1": "apis__stable.example.com__v1.json"} {
+		data, err := os.ReadFile(filepath.Join("testdata", "openapi", file))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		paths[path] = countingGroupVersion{FakeGroupVersion: openapitest.FakeGroupVersion{GVSpec: data}, calls: &fetches}
+	}
+	ro.clusterManager.SetOpenAPIClient("test", &openapitest.FakeClient{PathsMap: paths})
+	return ro, &fetches
+}
+
+// fieldTypes 将子字段渲染为 "name <type>" 形式，必填字段带 "*" 后缀
+func fieldTypes(fields []types.ExplainField) map[string]string {
+	rendered := make(map[string]string, len(fields))
+	for _, field := range fields {
+		typ := field.Type
+		if field.Required {
+			typ += "*"
+		}
+		rendered[field.Name] = typ
+	}
+	return rendered
+}
+
+// TestExplainResource 测试按资源类型及字段路径解析 schema：引用类型名、列表、map、必填字段、CRD 内联 schema
+func TestExplainResource(t *testing.T) {
+	ro, _ := newExplainOperations(t)
+
+	tests := []struct {
+		resourceType string
+		fieldPath    string
+		kind         string
+		typ          string
+		description  string
+		fields       map[string]string
+	}{
+		{
+			resourceType: "deploy", kind: "Deployment", typ: "Object",
+			description: "Deployment enables declarative updates for Pods and ReplicaSets.",
+			fields:      map[string]string{"apiVersion": "string", "metadata": "ObjectMeta", "spec": "DeploymentSpec", "status": "DeploymentStatus"},
+		},
+		{
+			resourceType: "deployments", fieldPath: "spec", kind: "Deployment", typ: "DeploymentSpec",
+			description: "Specification of the desired behavior of the Deployment.",
+			fields:      map[string]string{"replicas": "integer", "selector": "LabelSelector*", "template": "PodTemplateSpec*", "strategy": "DeploymentStrategy"},
+		},
+		{
+			resourceType: "Deployment", fieldPath: "spec.template.spec.containers", kind: "Deployment", typ: "[]Container",
+			description: "List of containers belonging to the pod.",
+			fields:      map[string]string{"name": "string*", "ports": "[]ContainerPort", "env": "[]EnvVar", "resources": "ResourceRequirements"},
+		},
+		{
+			resourceType: "deployments.apps", fieldPath: ".spec.template.spec.containers.resources.limits", kind: "Deployment", typ: "map[string]Quantity",
+			description: "Limits describes the maximum amount of compute resources allowed.",
+		},
+		{
+			resourceType: "deployment", fieldPath: "metadata.labels", kind: "Deployment", typ: "map[string]string",
+		},
+		{
+			resourceType: "ct", kind: "CronTab", typ: "Object",
+			fields: map[string]string{"metadata": "ObjectMeta", "spec": "Object", "status": "Object"},
+		},
+		{
+			resourceType: "crontabs.stable.example.com", fieldPath: "spec", kind: "CronTab", typ: "Object",
+			fields: map[string]string{"cronSpec": "string*", "replicas": "integer", "maxUnavailable": "IntOrString", "selector": "map[string]string", "extra": "Object"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resourceType+"/"+tt.fieldPath, func(t *testing.T) {
+			result, err := ro.ExplainResource(context.Background(), tt.resourceType, tt.fieldPath, "")
+			if err != nil {
+				t.Fatalf("ExplainResource failed: %v", err)
+			}
+			if result.Kind != tt.kind || result.Type != tt.typ {
+				t.Errorf("expected %s <%s>, got %s <%s>", tt.kind, tt.typ, result.Kind, result.Type)
+			}
+			if tt.description != "" && !strings.HasPrefix(result.Description, tt.description) {
+				t.Errorf("expected the description to start with %q, got %q", tt.description, result.Description)
+			}
+			got := fieldTypes(result.Fields)
+			for name, typ := range tt.fields {
+				if got[name] != typ {
+					t.Errorf("expected field %s <%s>, got <%s>", name, typ, got[name])
+				}
+			}
+			if tt.fields == nil && len(result.Fields) != 0 {
+				t.Errorf("expected no fields, got %v", got)
+			}
+		})
+	}
+
+	result, _ := ro.ExplainResource(context.Background(), "deployments", "spec.template.spec", "")
+	if result.APIVersion != "apps/v1" || result.Resource != "deployments" || result.FieldPath != "spec.template.spec" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, field := range result.Fields {
+		if field.Name == "restartPolicy" && field.Description != "Restart policy for all containers within the pod." {
+			t.Errorf("expected the first sentence of the description, got %q", field.Description)
+		}
+	}
+}
+
+// TestExplainResourceUnknownField 测试字段不存在时返回最近的有效父路径及其子字段，资源类型不存在时返回错误
+func TestExplainResourceUnknownField(t *testing.T) {
+	ro, _ := newExplainOperations(t)
+
+	_, err := ro.ExplainResource(context.Background(), "deployments", "spec.template.spec.contianers.image", "")
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an UnknownFieldError, got %v", err)
+	}
+	if unknown.Field != "contianers" || ExplainPath(unknown.Parent) != "deployments.spec.template.spec" || unknown.Parent.Type != "PodSpec" {
+		t.Errorf("unexpected hint %q in %s <%s>", unknown.Field, ExplainPath(unknown.Parent), unknown.Parent.Type)
+	}
+	if fieldTypes(unknown.Parent.Fields)["containers"] != "[]Container*" {
+		t.Errorf("expected the parent's fields as a hint, got %v", fieldTypes(unknown.Parent.Fields))
+	}
+	if err.Error() != `field "contianers" does not exist in deployments.spec.template.spec` {
+		t.Errorf("unexpected error %q", err)
+	}
+
+	if _, err := ro.ExplainResource(context.Background(), "widgets", "", ""); err == nil || !strings.Contains(err.Error(), "unsupported resource type: widgets") {
+		t.Errorf("expected an unknown resource type error, got %v", err)
+	}
+	if _, err := ro.ExplainResource(context.Background(), "pods", "", ""); err == nil || !strings.Contains(err.Error(), "does not publish an OpenAPI v3 document for v1") {
+		t.Errorf("expected a missing document error, got %v", err)
+	}
+}
+
+// TestExplainResourceCache 测试文档在 TTL 内被复用，过期后重新获取
+func TestExplainResourceCache(t *testing.T) {
+	ro, fetches := newExplainOperations(t)
+	for i := 0; i < 3; i++ {
+		if _, err := ro.ExplainResource(context.Background(), "deployments", "spec", ""); err != nil {
+			t.Fatalf("ExplainResource failed: %v", err)
+		}
+	}
+	if *fetches != 1 {
+		t.Fatalf("expected the document to be fetched once, got %d", *fetches)
+	}
+
+	ro.clusterManager.openAPI["test"].fetched = time.Now().Add(-openAPISchemaTTL)
+	if _, err := ro.ExplainResource(context.Background(), "deployments", "spec", ""); err != nil {
+		t.Fatalf("ExplainResource failed: %v", err)
+	}
+	if *fetches != 2 {
+		t.Errorf("expected the document to be fetched again after the TTL, got %d", *fetches)
+	}
+}
+
+// TestExplainResourceFakeClientset 测试 fake clientset 未设置 OpenAPI 客户端时返回错误而不是 panic
+func TestExplainResourceFakeClientset(t *testing.T) {
+	ro, _ := newFakeOperations(t)
+	if _, err := ro.ExplainResource(context.Background(), "pods", "", ""); err == nil || !strings.Contains(err.Error(), "does not serve OpenAPI v3 documents") {
+		t.Errorf("expected an error, got %v", err)
+	}
+}
+
+// TestFirstSentence 测试描述截取第一句时不会在缩写处截断
+func TestFirstSentence(t *testing.T) {
+	for text, want := range map[string]string{
+		"Standard object's metadata. More info: https://example.com": "Standard object's metadata.",
+		"Name of the container, e.g. web. Cannot be updated.":        "Name of the container, e.g. web.",
+		"First line\n\nSecond paragraph.":                            "First line",
+		"No period":                                                  "No period",
+	} {
+		if got := firstSentence(text); got != want {
+			t.Errorf("firstSentence(%q) = %q, expected %q", text, got, want)
+		}
+	}
+}
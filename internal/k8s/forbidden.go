@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// namespaceListConcurrency bounds the per-namespace lists made when a cluster-wide list is forbidden
+// namespaceListConcurrency 限制集群范围列表被禁止时按命名空间列表请求的并发数
+const namespaceListConcurrency = 8
+
+// clusterWideListKey marks the cluster-wide list made by listAllNamespaces, so the list
+// function makes it instead of fanning out again
+// clusterWideListKey 标记 listAllNamespaces 发起的集群范围列表请求，使列表函数直接执行而不再次展开
+type clusterWideListKey struct{}
+
+// withClusterWideList marks ctx for the cluster-wide list of listAllNamespaces
+// withClusterWideList 为 listAllNamespaces 的集群范围列表请求标记 ctx
+func withClusterWideList(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clusterWideListKey{}, true)
+}
+
+// isClusterWideList reports whether ctx belongs to the cluster-wide list of listAllNamespaces
+// isClusterWideList 判断 ctx 是否属于 listAllNamespaces 的集群范围列表请求
+func isClusterWideList(ctx context.Context) bool {
+	clusterWide, _ := ctx.Value(clusterWideListKey{}).(bool)
+	return clusterWide
+}
+
+// SkippedNamespaces collects the namespaces all-namespaces lists skipped because they were forbidden
+// SkippedNamespaces 收集全命名空间列表因被禁止而跳过的命名空间
+type SkippedNamespaces struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// skippedNamespacesKey is the context key of the SkippedNamespaces of a call
+// skippedNamespacesKey 是一次调用的 SkippedNamespaces 的 context 键
+type skippedNamespacesKey struct{}
+
+// WithSkippedNamespaces returns a context whose all-namespaces lists record the forbidden
+// namespaces they skip in the returned SkippedNamespaces
+// WithSkippedNamespaces 返回一个 context，其中的全命名空间列表将跳过的被禁止命名空间记录到返回的 SkippedNamespaces
+func WithSkippedNamespaces(ctx context.Context) (context.Context, *SkippedNamespaces) {
+	skipped := &SkippedNamespaces{names: make(map[string]bool)}
+	return context.WithValue(ctx, skippedNamespacesKey{}, skipped), skipped
+}
+
+// Names returns the skipped namespaces, sorted
+// Names 返回排序后的跳过的命名空间
+func (s *SkippedNamespaces) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.names))
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recordSkippedNamespace adds namespace to the SkippedNamespaces of ctx, if any
+// recordSkippedNamespace 将 namespace 加入 ctx 中的 SkippedNamespaces (如果有)
+func recordSkippedNamespace(ctx context.Context, namespace string) {
+	if skipped, ok := ctx.Value(skippedNamespacesKey{}).(*SkippedNamespaces); ok {
+		skipped.mu.Lock()
+		skipped.names[namespace] = true
+		skipped.mu.Unlock()
+	}
+}
+
+// listEachNamespace lists every namespace with at most concurrency lists at a time and
+// concatenates the results in namespace order. Forbidden namespaces are skipped and
+// recorded, unless all of them are; any other error fails the list.
+// listEachNamespace 以不超过 concurrency 的并发分别列出每个命名空间，并按命名空间顺序合并结果。
+// 被禁止的命名空间被跳过并记录 (全部被禁止时除外)，其他错误使整个列表失败
+func listEachNamespace[T any](ctx context.Context, namespaces []string, concurrency int, list func(ctx context.Context, namespace string) ([]T, error)) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]T, len(namespaces))
+	errs := make([]error, len(namespaces))
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, ns := range namespaces {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = list(ctx, ns)
+			if errs[i] != nil && !apierrors.IsForbidden(errs[i]) {
+				// The first failure cancels the others; report it rather than their cancellation
+				// 第一个失败取消其他请求，返回该失败而不是其他请求的取消错误
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = errs[i]
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var forbidden []string
+	var items []T
+	for i, err := range errs {
+		if err != nil {
+			forbidden = append(forbidden, namespaces[i])
+			continue
+		}
+		items = append(items, results[i]...)
+	}
+	if len(forbidden) > 0 && len(forbidden) == len(namespaces) {
+		return nil, errs[0]
+	}
+	for _, ns := range forbidden {
+		recordSkippedNamespace(ctx, ns)
+	}
+	return items, nil
+}
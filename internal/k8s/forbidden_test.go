@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newForbiddenNamespacesClient 返回一个 fake clientset：集群范围的 Pod 列表和 forbidden 中命名空间的 Pod 列表返回 403
+func newForbiddenNamespacesClient(forbidden ...string) *fake.Clientset {
+	var objects []runtime.Object
+	for _, ns := range []string{"default", "secret-a", "secret-b", "shop"} {
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-" + ns, Namespace: ns}})
+	}
+	client := fake.NewSimpleClientset(objects...)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ns := action.GetNamespace()
+		for _, denied := range forbidden {
+			if ns == "" || ns == denied {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC denied"))
+			}
+		}
+		return false, nil, nil
+	})
+	return client
+}
+
+// TestListAllNamespacesSkipsForbidden 测试集群范围列表被禁止时改为按命名空间列出，跳过并记录被禁止的命名空间
+func TestListAllNamespacesSkipsForbidden(t *testing.T) {
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", newForbiddenNamespacesClient("secret-a", "secret-b"))
+	ctx, skipped := WithSkippedNamespaces(context.Background())
+
+	pods, err := NewResourceOperations(cm).ListPods(ctx, "", "test")
+	if err != nil {
+		t.Fatalf("ListPods failed: %v", err)
+	}
+	var got []string
+	for _, pod := range pods {
+		got = append(got, pod.Namespace+"/"+pod.Name)
+	}
+	if want := []string{"default/pod-default", "shop/pod-shop"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected pods %v, want %v", got, want)
+	}
+	if names := skipped.Names(); !reflect.DeepEqual(names, []string{"secret-a", "secret-b"}) {
+		t.Errorf("unexpected skipped namespaces %v", names)
+	}
+}
+
+// TestListAllNamespacesAllForbidden 测试所有命名空间都被禁止时返回原始的 Forbidden 错误
+func TestListAllNamespacesAllForbidden(t *testing.T) {
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", newForbiddenNamespacesClient("default", "secret-a", "secret-b", "shop"))
+	ctx, skipped := WithSkippedNamespaces(context.Background())
+
+	_, err := NewResourceOperations(cm).ListPods(ctx, "", "test")
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "RBAC denied") {
+		t.Errorf("expected the forbidden error, got %v", err)
+	}
+	if names := skipped.Names(); len(names) != 0 {
+		t.Errorf("expected nothing to be recorded, got %v", names)
+	}
+}
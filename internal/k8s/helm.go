@@ -0,0 +1,322 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// helmReleaseSecretType is the type of the Secrets Helm 3 stores releases in, one per revision
+// helmReleaseSecretType 是 Helm 3 存储 release 的 Secret 类型，每个版本一个 Secret
+const helmReleaseSecretType corev1.SecretType = "helm.sh/release.v1"
+
+// helmOwnerLabels select the release Secrets; their name, status and version labels
+// identify the release and revision without decoding the payload
+// helmOwnerLabels 选择 release Secret，其 name、status 和 version 标签无需解码内容即可标识 release 和版本
+var helmOwnerLabels = labels.Set{"owner": "helm"}
+
+// helmReleaseResource is the resource named in errors about releases
+// helmReleaseResource 是 release 相关错误中使用的资源名
+var helmReleaseResource = schema.GroupResource{Group: "helm.sh", Resource: "releases"}
+
+// helmManifestSeparator splits a release manifest into its YAML documents
+// helmManifestSeparator 将 release 的 manifest 拆分为多个 YAML 文档
+var helmManifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// helmRelease holds the fields of Helm's release record that are reported
+// helmRelease 保存 Helm release 记录中需要报告的字段
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		LastDeployed string `json:"last_deployed"`
+		Description  string `json:"description"`
+		Status       string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+		Values map[string]interface{} `json:"values"`
+	} `json:"chart"`
+	Config   map[string]interface{} `json:"config"`
+	Manifest string                 `json:"manifest"`
+}
+
+// decodeHelmRelease decodes the release payload of a release Secret: base64 of the
+// JSON record, gzipped by Helm 3 (older records may be plain JSON)
+// decodeHelmRelease 解码 release Secret 中的 release 内容：JSON 记录的 base64 编码，Helm 3 会先进行 gzip 压缩
+// (较早的记录可能是未压缩的 JSON)
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release encoding: %w", err)
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+		defer reader.Close()
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+	}
+	var release helmRelease
+	if err := json.Unmarshal(raw, &release); err != nil {
+		return nil, fmt.Errorf("invalid release record: %w", err)
+	}
+	return &release, nil
+}
+
+// helmSecretRevision returns the revision of a release Secret from its version label
+// helmSecretRevision 从 version 标签读取 release Secret 的版本号
+func helmSecretRevision(secret *corev1.Secret) int {
+	revision, _ := strconv.Atoi(secret.Labels["version"])
+	return revision
+}
+
+// listHelmSecrets lists the release Secrets of a namespace, of one release when name is set
+// listHelmSecrets 列出命名空间中的 release Secret，指定 name 时只列出该 release 的
+func listHelmSecrets(ctx context.Context, client kubernetes.Interface, namespace, name string) ([]corev1.Secret, error) {
+	set := labels.Merge(helmOwnerLabels, nil)
+	if name != "" {
+		set["name"] = name
+	}
+	selector, err := labels.ValidatedSelectorFromSet(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid helm release name %q: %w", name, err)
+	}
+	list, err := client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helm release secrets: %w", err)
+	}
+	secrets := make([]corev1.Secret, 0, len(list.Items))
+	for _, secret := range list.Items {
+		if secret.Type == helmReleaseSecretType {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
+}
+
+// ListHelmReleases lists the Helm releases of a namespace (all namespaces if empty) with
+// their latest revision, read from Helm's release Secrets without the helm binary. Only
+// the latest revision of each release is decoded; a release whose record cannot be
+// decoded is still listed with the status and revision of its labels.
+// ListHelmReleases 列出命名空间 (为空时为所有命名空间) 中的 Helm release 及其最新版本，直接读取 Helm 的 release Secret，
+// 不依赖 helm 命令。每个 release 只解码最新版本；记录无法解码的 release 仍然列出，使用其标签中的状态和版本号
+func (ro *ResourceOperations) ListHelmReleases(ctx context.Context, namespace, clusterName string) ([]types.HelmRelease, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.HelmRelease, error) {
+			return ro.ListHelmReleases(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := listHelmSecrets(ctx, client, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]*corev1.Secret)
+	for i := range secrets {
+		secret := &secrets[i]
+		key := secret.Namespace + "/" + secret.Labels["name"]
+		if current, ok := latest[key]; !ok || helmSecretRevision(secret) > helmSecretRevision(current) {
+			latest[key] = secret
+		}
+	}
+
+	releases := make([]types.HelmRelease, 0, len(latest))
+	for _, secret := range latest {
+		release := types.HelmRelease{
+			Name:      secret.Labels["name"],
+			Namespace: secret.Namespace,
+			Status:    secret.Labels["status"],
+			Revision:  helmSecretRevision(secret),
+		}
+		if record, err := decodeHelmRelease(secret.Data["release"]); err == nil {
+			release = toHelmRelease(record, secret.Namespace)
+		}
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+	return releases, nil
+}
+
+// GetHelmRelease returns one revision of a Helm release (the latest if revision is 0)
+// with its computed values and the objects of its manifest. The values are the chart's
+// default values overridden by the user-supplied ones, like 'helm get values --all';
+// they are not redacted.
+// GetHelmRelease 返回 Helm release 的某个版本 (revision 为 0 时为最新版本)，包括计算值和 manifest 中的对象。
+// 计算值为 chart 默认值被用户提供的值覆盖后的结果，与 'helm get values --all' 相同；计算值未脱敏
+func (ro *ResourceOperations) GetHelmRelease(ctx context.Context, namespace, name string, revision int, clusterName string) (*types.HelmReleaseDetails, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := listHelmSecrets(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(secrets) == 0 {
+		return nil, apierrors.NewNotFound(helmReleaseResource, name)
+	}
+
+	var chosen *corev1.Secret
+	revisions := make([]int, 0, len(secrets))
+	for i := range secrets {
+		secret := &secrets[i]
+		revisions = append(revisions, helmSecretRevision(secret))
+		if revision == 0 && (chosen == nil || helmSecretRevision(secret) > helmSecretRevision(chosen)) ||
+			revision != 0 && helmSecretRevision(secret) == revision {
+			chosen = secret
+		}
+	}
+	if chosen == nil {
+		sort.Ints(revisions)
+		revisionList := make([]string, len(revisions))
+		for i, r := range revisions {
+			revisionList[i] = strconv.Itoa(r)
+		}
+		return nil, fmt.Errorf("helm release %s has no revision %d; available revisions: %s", name, revision, strings.Join(revisionList, ", "))
+	}
+
+	record, err := decodeHelmRelease(chosen.Data["release"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode helm release %s revision %d: %w", name, helmSecretRevision(chosen), err)
+	}
+	release := toHelmRelease(record, namespace)
+	return &types.HelmReleaseDetails{
+		Name:         release.Name,
+		Namespace:    release.Namespace,
+		Chart:        release.Chart,
+		ChartVersion: release.ChartVersion,
+		AppVersion:   release.AppVersion,
+		Status:       release.Status,
+		Revision:     release.Revision,
+		LastDeployed: release.LastDeployed,
+		Description:  record.Info.Description,
+		Values:       coalesceHelmValues(record.Chart.Values, record.Config),
+		Resources:    parseHelmManifest(record.Manifest),
+	}, nil
+}
+
+// toHelmRelease summarizes a release record; namespace is used when the record has none
+// toHelmRelease 汇总 release 记录，记录中没有命名空间时使用 namespace
+func toHelmRelease(record *helmRelease, namespace string) types.HelmRelease {
+	if record.Namespace != "" {
+		namespace = record.Namespace
+	}
+	lastDeployed := record.Info.LastDeployed
+	if t, err := time.Parse(time.RFC3339Nano, lastDeployed); err == nil {
+		lastDeployed = t.UTC().Format(time.RFC3339)
+	}
+	return types.HelmRelease{
+		Name:         record.Name,
+		Namespace:    namespace,
+		Chart:        record.Chart.Metadata.Name,
+		ChartVersion: record.Chart.Metadata.Version,
+		AppVersion:   record.Chart.Metadata.AppVersion,
+		Status:       record.Info.Status,
+		Revision:     record.Version,
+		LastDeployed: lastDeployed,
+	}
+}
+
+// coalesceHelmValues merges the user-supplied values over the chart defaults: maps are
+// merged key by key, other values replace the default, and null removes the default
+// coalesceHelmValues 将用户提供的值合并到 chart 默认值之上：map 逐键合并，其他值替换默认值，null 删除默认值
+func coalesceHelmValues(defaults, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(overrides))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		override, overrideIsMap := value.(map[string]interface{})
+		current, currentIsMap := merged[key].(map[string]interface{})
+		if overrideIsMap && currentIsMap {
+			merged[key] = coalesceHelmValues(current, override)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// parseHelmManifest lists the objects of a rendered release manifest in manifest order;
+// documents that are empty or not objects are skipped
+// parseHelmManifest 按 manifest 中的顺序列出渲染后的 release manifest 中的对象，跳过空文档和非对象文档
+func parseHelmManifest(manifest string) []types.HelmResource {
+	resources := []types.HelmResource{}
+	for _, doc := range helmManifestSeparator.Split(manifest, -1) {
+		var object struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &object); err != nil || object.Kind == "" {
+			continue
+		}
+		resources = append(resources, types.HelmResource{
+			APIVersion: object.APIVersion,
+			Kind:       object.Kind,
+			Name:       object.Metadata.Name,
+			Namespace:  object.Metadata.Namespace,
+		})
+	}
+	return resources
+}
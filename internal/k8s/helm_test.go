@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// helmManifest 是测试 release 渲染出的 manifest
+const helmManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+---
+# Source: web/templates/empty.yaml
+`
+
+// helmReleaseSecret 按 Helm 3 的存储格式 (gzip 后 base64 编码的 JSON) 构造 release Secret
+func helmReleaseSecret(t *testing.T, namespace, name string, revision int, status string, record map[string]interface{}) *corev1.Secret {
+	t.Helper()
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("marshal release: %v", err)
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1." + name + ".v" + strconv.Itoa(revision),
+			Namespace: namespace,
+			Labels:    map[string]string{"owner": "helm", "name": name, "status": status, "version": strconv.Itoa(revision)},
+		},
+		Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))},
+	}
+}
+
+// helmRecord 返回 release 记录
+func helmRecord(namespace, name string, revision int, status, chartVersion string, config map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+		"version":   revision,
+		"info": map[string]interface{}{
+			"last_deployed": "2024-05-02T10:00:00.123456789+08:00",
+			"description":   "Upgrade complete",
+			"status":        status,
+		},
+		"chart": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "version": chartVersion, "appVersion": "1.25.3"},
+			"values": map[string]interface{}{
+				"replicaCount": 1,
+				"image":        map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+				"ingress":      map[string]interface{}{"enabled": false},
+			},
+		},
+		"config":   config,
+		"manifest": helmManifest,
+	}
+}
+
+// newHelmOperations 返回加载了测试 release 的 ResourceOperations
+func newHelmOperations(t *testing.T) *ResourceOperations {
+	t.Helper()
+	broken := helmReleaseSecret(t, "shop", "broken", 3, "failed", nil)
+	broken.Data["release"] = []byte("not base64!")
+	client := fake.NewSimpleClientset(
+		helmReleaseSecret(t, "shop", "web", 1, "superseded", helmRecord("shop", "web", 1, "superseded", "1.0.0", nil)),
+		helmReleaseSecret(t, "shop", "web", 2, "deployed", helmRecord("shop", "web", 2, "deployed", "1.1.0", map[string]interface{}{
+			"replicaCount": 3,
+			"image":        map[string]interface{}{"tag": "1.25.3"},
+			"ingress":      nil,
+			"auth":         map[string]interface{}{"password": "hunter2"},
+		})),
+		helmReleaseSecret(t, "data", "db", 1, "failed", helmRecord("data", "db", 1, "failed", "12.0.0", nil)),
+		broken,
+		// 带有 owner=helm 标签但不是 release 存储的 Secret
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "shop", Labels: map[string]string{"owner": "helm", "name": "web"}}, Type: corev1.SecretTypeTLS},
+	)
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+	return NewResourceOperations(cm)
+}
+
+// TestListHelmReleases 测试每个 release 只报告最新版本，无法解码的 release 使用标签中的信息，非 release Secret 被忽略
+func TestListHelmReleases(t *testing.T) {
+	ro := newHelmOperations(t)
+	releases, err := ro.ListHelmReleases(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("ListHelmReleases failed: %v", err)
+	}
+	want := []types.HelmRelease{
+		{Name: "db", Namespace: "data", Chart: "db", ChartVersion: "12.0.0", AppVersion: "1.25.3", Status: "failed", Revision: 1, LastDeployed: "2024-05-02T02:00:00Z"},
+		{Name: "broken", Namespace: "shop", Status: "failed", Revision: 3},
+		{Name: "web", Namespace: "shop", Chart: "web", ChartVersion: "1.1.0", AppVersion: "1.25.3", Status: "deployed", Revision: 2, LastDeployed: "2024-05-02T02:00:00Z"},
+	}
+	if !reflect.DeepEqual(releases, want) {
+		t.Errorf("unexpected releases:\n%+v\nwant\n%+v", releases, want)
+	}
+}
+
+// TestGetHelmRelease 测试计算值的合并、manifest 中的对象，以及指定版本和不存在的 release/版本
+func TestGetHelmRelease(t *testing.T) {
+	ro := newHelmOperations(t)
+	release, err := ro.GetHelmRelease(context.Background(), "shop", "web", 0, "test")
+	if err != nil {
+		t.Fatalf("GetHelmRelease failed: %v", err)
+	}
+	if release.Revision != 2 || release.ChartVersion != "1.1.0" || release.Description != "Upgrade complete" {
+		t.Errorf("expected the latest revision, got %+v", release)
+	}
+	wantValues := map[string]interface{}{
+		"replicaCount": float64(3),
+		"image":        map[string]interface{}{"repository": "nginx", "tag": "1.25.3"},
+		"auth":         map[string]interface{}{"password": "hunter2"},
+	}
+	if !reflect.DeepEqual(release.Values, wantValues) {
+		t.Errorf("unexpected computed values %v", release.Values)
+	}
+	wantResources := []types.HelmResource{
+		{APIVersion: "v1", Kind: "Service", Name: "web"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "shop"},
+	}
+	if !reflect.DeepEqual(release.Resources, wantResources) {
+		t.Errorf("unexpected resources %+v", release.Resources)
+	}
+
+	if release, err = ro.GetHelmRelease(context.Background(), "shop", "web", 1, "test"); err != nil || release.Revision != 1 || release.Status != "superseded" {
+		t.Errorf("expected revision 1, got %+v %v", release, err)
+	}
+	if _, err = ro.GetHelmRelease(context.Background(), "shop", "web", 5, "test"); err == nil || !strings.Contains(err.Error(), "available revisions: 1, 2") {
+		t.Errorf("expected the available revisions, got %v", err)
+	}
+	if _, err = ro.GetHelmRelease(context.Background(), "shop", "api", 0, "test"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for an unknown release, got %v", err)
+	}
+	if _, err = ro.GetHelmRelease(context.Background(), "shop", "web,owner=x", 0, "test"); err == nil {
+		t.Errorf("expected an invalid release name to be rejected")
+	}
+}
@@ -0,0 +1,321 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Sources and groupings of ListImages
+// ListImages 的镜像来源和分组方式
+const (
+	ImageSourcePods        = "pods"
+	ImageSourceDeployments = "deployments"
+	ImageGroupByRegistry   = "registry"
+)
+
+// defaultRegistry is the registry of images without a registry host, e.g. nginx:1.25
+// defaultRegistry 是没有镜像仓库地址的镜像 (例如 nginx:1.25) 所在的仓库
+const defaultRegistry = "docker.io"
+
+// ListImages lists the deduplicated container images of the pods, or of the deployment
+// templates, in a namespace (all namespaces if namespace is empty), with the workloads
+// using each image, their pull policies and pull secrets. Pods are reported as the
+// workload that created them, so replicas count once. groupBy "registry" adds the
+// counts per registry host.
+// ListImages 列出命名空间 (namespace 为空时为所有命名空间) 中 Pod 或 Deployment 模板使用的去重后的容器镜像，
+// 以及使用每个镜像的工作负载、拉取策略和拉取凭证。Pod 按创建它的工作负载报告，因此多个副本只计一次。
+// groupBy 为 "registry" 时附加按镜像仓库地址汇总的数量。
+func (ro *ResourceOperations) ListImages(ctx context.Context, namespace, source, groupBy, clusterName string) (*types.ImageInventory, error) {
+	if source == "" {
+		source = ImageSourcePods
+	}
+	if source != ImageSourcePods && source != ImageSourceDeployments {
+		return nil, fmt.Errorf("unsupported image source %q, use %s or %s", source, ImageSourcePods, ImageSourceDeployments)
+	}
+	if groupBy != "" && groupBy != ImageGroupByRegistry {
+		return nil, fmt.Errorf("unsupported group_by %q, use %s", groupBy, ImageGroupByRegistry)
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "namespace " + namespace
+	if namespace == "" {
+		scope = "all namespaces"
+		if ro.clusterManager.namespacePolicy.Restricted() {
+			scope = "allowed namespaces"
+		}
+	}
+
+	var templates []podTemplate
+	switch source {
+	case ImageSourcePods:
+		pods, err := ro.listPodObjects(ctx, client, namespace, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods {
+			kind, name := podWorkload(&pods[i])
+			templates = append(templates, podTemplate{kind, pods[i].Namespace, name, &pods[i].Spec})
+		}
+	case ImageSourceDeployments:
+		deployments, err := ro.listDeploymentObjects(ctx, client, namespace, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range deployments {
+			d := &deployments[i]
+			templates = append(templates, podTemplate{"Deployment", d.Namespace, d.Name, &d.Spec.Template.Spec})
+		}
+	}
+
+	inventory := &types.ImageInventory{Scope: scope, Source: source, Images: collectImages(templates)}
+	if groupBy == ImageGroupByRegistry {
+		inventory.Registries = SummarizeImageRegistries(inventory.Images)
+	}
+	return inventory, nil
+}
+
+// listPodObjects lists the pods of a namespace, iterating the allowed namespaces in namespace-scoped mode
+// listPodObjects 列出命名空间中的 Pod，命名空间受限模式下遍历允许的命名空间
+func (ro *ResourceOperations) listPodObjects(ctx context.Context, client kubernetes.Interface, namespace, clusterName string) ([]corev1.Pod, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]corev1.Pod, error) {
+			return ro.listPodObjects(ctx, client, ns, clusterName)
+		})
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	return pods.Items, nil
+}
+
+// listDeploymentObjects lists the deployments of a namespace, iterating the allowed namespaces in namespace-scoped mode
+// listDeploymentObjects 列出命名空间中的 Deployment，命名空间受限模式下遍历允许的命名空间
+func (ro *ResourceOperations) listDeploymentObjects(ctx context.Context, client kubernetes.Interface, namespace, clusterName string) ([]appsv1.Deployment, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]appsv1.Deployment, error) {
+			return ro.listDeploymentObjects(ctx, client, ns, clusterName)
+		})
+	}
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	return deployments.Items, nil
+}
+
+// podWorkload returns the workload a pod belongs to: the Deployment of its ReplicaSet
+// (named after the ReplicaSet without its pod-template-hash suffix), its other controller,
+// or the pod itself
+// podWorkload 返回 Pod 所属的工作负载：其 ReplicaSet 的 Deployment (ReplicaSet 名称去掉 pod-template-hash 后缀)、
+// 其他控制器，或 Pod 本身
+func podWorkload(pod *corev1.Pod) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// collectImages deduplicates the images of the pod specs, including init and ephemeral
+// containers, sorted by image reference
+// collectImages 对 Pod spec 中的镜像去重 (包括 init 容器和临时容器)，按镜像引用排序
+func collectImages(templates []podTemplate) []types.ImageUsage {
+	usages := map[string]*types.ImageUsage{}
+	add := func(template podTemplate, image, container string, policy corev1.PullPolicy) {
+		if image == "" {
+			return
+		}
+		usage, ok := usages[image]
+		if !ok {
+			registry, repository, tag, digest := ParseImageReference(image)
+			usage = &types.ImageUsage{Image: image, Registry: registry, Repository: repository, Tag: tag, Digest: digest, Mutable: digest == ""}
+			usages[image] = usage
+		}
+		if policy == "" {
+			policy = defaultPullPolicy(image)
+		}
+		usage.PullPolicies = appendUnique(usage.PullPolicies, string(policy))
+		for _, secret := range template.spec.ImagePullSecrets {
+			usage.PullSecrets = appendUnique(usage.PullSecrets, template.namespace+"/"+secret.Name)
+		}
+		for i := range usage.Workloads {
+			w := &usage.Workloads[i]
+			if w.Kind == template.kind && w.Namespace == template.namespace && w.Name == template.name {
+				w.Containers = appendUnique(w.Containers, container)
+				return
+			}
+		}
+		usage.Workloads = append(usage.Workloads, types.ImageWorkload{Kind: template.kind, Namespace: template.namespace, Name: template.name, Containers: []string{container}})
+	}
+
+	for _, template := range templates {
+		for _, c := range template.spec.InitContainers {
+			add(template, c.Image, "init:"+c.Name, c.ImagePullPolicy)
+		}
+		for _, c := range template.spec.Containers {
+			add(template, c.Image, c.Name, c.ImagePullPolicy)
+		}
+		for _, c := range template.spec.EphemeralContainers {
+			add(template, c.Image, "ephemeral:"+c.Name, c.ImagePullPolicy)
+		}
+	}
+
+	images := make([]types.ImageUsage, 0, len(usages))
+	for _, usage := range usages {
+		sort.Strings(usage.PullPolicies)
+		sort.Strings(usage.PullSecrets)
+		sort.Slice(usage.Workloads, func(i, j int) bool {
+			a, b := usage.Workloads[i], usage.Workloads[j]
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			return a.Name < b.Name
+		})
+		images = append(images, *usage)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Image < images[j].Image })
+	return images
+}
+
+// ParseImageReference splits an image reference into registry host, repository, tag and
+// digest the way the container runtime resolves it: without a registry host the image is
+// on docker.io, and single-name Docker Hub images are in library/
+// ParseImageReference 按容器运行时的解析方式将镜像引用拆分为镜像仓库地址、仓库、标签和摘要：没有仓库地址的镜像来自 docker.io，
+// 只有一段名称的 Docker Hub 镜像位于 library/ 下
+func ParseImageReference(image string) (registry, repository, tag, digest string) {
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	registry = defaultRegistry
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, name = host, name[i+1:]
+		}
+	}
+	if registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	return registry, name, tag, digest
+}
+
+// defaultPullPolicy is the pull policy the API server defaults a container to:
+// Always for :latest or untagged images, IfNotPresent otherwise
+// defaultPullPolicy 是 API server 为容器设置的默认拉取策略：:latest 或没有标签的镜像为 Always，其他为 IfNotPresent
+func defaultPullPolicy(image string) corev1.PullPolicy {
+	if imageUsesLatest(image) {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
+// appendUnique appends value unless the list already contains it
+// appendUnique 在列表中不存在 value 时追加
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// SummarizeImageRegistries counts the images, workloads and mutable images per registry host
+// SummarizeImageRegistries 按镜像仓库地址统计镜像数、工作负载数和没有通过摘要固定的镜像数
+func SummarizeImageRegistries(images []types.ImageUsage) []types.RegistryUsage {
+	summaries := map[string]*types.RegistryUsage{}
+	workloads := map[string]map[string]bool{}
+	for _, image := range images {
+		summary, ok := summaries[image.Registry]
+		if !ok {
+			summary = &types.RegistryUsage{Registry: image.Registry}
+			summaries[image.Registry] = summary
+			workloads[image.Registry] = map[string]bool{}
+		}
+		summary.Images++
+		if image.Mutable {
+			summary.MutableImages++
+		}
+		for _, w := range image.Workloads {
+			workloads[image.Registry][w.Kind+"/"+w.Namespace+"/"+w.Name] = true
+		}
+	}
+
+	registries := make([]types.RegistryUsage, 0, len(summaries))
+	for registry, summary := range summaries {
+		summary.Workloads = len(workloads[registry])
+		registries = append(registries, *summary)
+	}
+	sort.Slice(registries, func(i, j int) bool {
+		if registries[i].Images != registries[j].Images {
+			return registries[i].Images > registries[j].Images
+		}
+		return registries[i].Registry < registries[j].Registry
+	})
+	return registries
+}
+
+// RenderImageInventory renders the inventory as text, images sorted by the number of
+// workloads using them, followed by the registry summary when present
+// RenderImageInventory 将镜像清单渲染为文本，镜像按使用它的工作负载数排序，存在仓库汇总时附在其后
+func RenderImageInventory(inventory *types.ImageInventory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d images in %s (source: %s)\n", len(inventory.Images), inventory.Scope, inventory.Source)
+
+	images := append([]types.ImageUsage(nil), inventory.Images...)
+	sort.SliceStable(images, func(i, j int) bool { return len(images[i].Workloads) > len(images[j].Workloads) })
+	for _, image := range images {
+		pinning := "pinned"
+		if image.Mutable {
+			pinning = "mutable"
+		}
+		fmt.Fprintf(&b, "\n[%d] %s (%s, %s", len(image.Workloads), image.Image, pinning, strings.Join(image.PullPolicies, "/"))
+		if len(image.PullSecrets) > 0 {
+			fmt.Fprintf(&b, ", pull secrets %s", strings.Join(image.PullSecrets, ", "))
+		}
+		b.WriteString(")\n")
+		for _, w := range image.Workloads {
+			fmt.Fprintf(&b, "  %s %s/%s: %s\n", w.Kind, w.Namespace, w.Name, strings.Join(w.Containers, ", "))
+		}
+	}
+
+	if len(inventory.Registries) > 0 {
+		b.WriteString("\nRegistries:\n")
+		for _, r := range inventory.Registries {
+			fmt.Fprintf(&b, "  %s: %d images, %d workloads, %d mutable\n", r.Registry, r.Images, r.Workloads, r.MutableImages)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
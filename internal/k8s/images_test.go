@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const pinnedImage = "ghcr.io/acme/api@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// TestParseImageReference 测试镜像引用解析为仓库地址、仓库、标签和摘要
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image                             string
+		registry, repository, tag, digest string
+	}{
+		{"nginx", "docker.io", "library/nginx", "", ""},
+		{"nginx:1.25", "docker.io", "library/nginx", "1.25", ""},
+		{"bitnami/redis:7.2", "docker.io", "bitnami/redis", "7.2", ""},
+		{"registry.k8s.io/pause:3.9", "registry.k8s.io", "pause", "3.9", ""},
+		{"localhost:5000/team/app", "localhost:5000", "team/app", "", ""},
+		{"localhost/app:dev", "localhost", "app", "dev", ""},
+		{pinnedImage, "ghcr.io", "acme/api", "", "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{"quay.io/acme/worker:v2@sha256:abc", "quay.io", "acme/worker", "v2", "sha256:abc"},
+	}
+	for _, tt := range tests {
+		registry, repository, tag, digest := ParseImageReference(tt.image)
+		if registry != tt.registry || repository != tt.repository || tag != tt.tag || digest != tt.digest {
+			t.Errorf("ParseImageReference(%q) = %q %q %q %q, want %q %q %q %q",
+				tt.image, registry, repository, tag, digest, tt.registry, tt.repository, tt.tag, tt.digest)
+		}
+	}
+}
+
+// imageFixtures 返回 shop 命名空间中的一个 Deployment 及其两个副本 Pod (含 init 容器和临时容器)、一个独立 Pod 和一个 StatefulSet 的 Pod
+func imageFixtures() *fake.Clientset {
+	controller := true
+	replicaSetPod := func(name string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "shop",
+				Labels:    map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "5d8f7c"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "web-5d8f7c", Controller: &controller},
+				},
+			},
+			Spec: corev1.PodSpec{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr"}},
+				InitContainers:   []corev1.Container{{Name: "migrate", Image: pinnedImage}},
+				Containers: []corev1.Container{
+					{Name: "web", Image: "nginx:1.25", ImagePullPolicy: corev1.PullIfNotPresent},
+					{Name: "api", Image: pinnedImage, ImagePullPolicy: corev1.PullAlways},
+				},
+			},
+		}
+		return pod
+	}
+	debugged := replicaSetPod("web-5d8f7c-b")
+	debugged.Spec.EphemeralContainers = []corev1.EphemeralContainer{
+		{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}},
+	}
+
+	return fake.NewSimpleClientset(
+		replicaSetPod("web-5d8f7c-a"),
+		debugged,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "toolbox", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "shell", Image: "busybox:latest"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "db-0",
+				Namespace:       "shop",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "postgres", Image: "nginx:1.25"}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web", Image: "nginx:1.25"}},
+			}}},
+		},
+	)
+}
+
+// imagesByName 按镜像引用索引镜像
+func imagesByName(images []types.ImageUsage) map[string]types.ImageUsage {
+	byName := make(map[string]types.ImageUsage, len(images))
+	for _, image := range images {
+		byName[image.Image] = image
+	}
+	return byName
+}
+
+// TestListImages 测试按工作负载去重镜像，包括 init 容器和临时容器，区分摘要固定和仅有标签的镜像
+func TestListImages(t *testing.T) {
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", imageFixtures())
+	ro := NewResourceOperations(cm)
+
+	inventory, err := ro.ListImages(context.Background(), "shop", "", "", "test")
+	if err != nil {
+		t.Fatalf("ListImages failed: %v", err)
+	}
+	if inventory.Scope != "namespace shop" || inventory.Source != ImageSourcePods || inventory.Registries != nil {
+		t.Errorf("unexpected inventory %+v", inventory)
+	}
+	images := imagesByName(inventory.Images)
+	if len(images) != 4 {
+		t.Fatalf("expected 4 distinct images, got %+v", inventory.Images)
+	}
+
+	// 摘要固定的镜像：两个副本只计一次 Deployment，init 容器和普通容器合并
+	pinned := images[pinnedImage]
+	if pinned.Mutable || pinned.Registry != "ghcr.io" || pinned.Digest == "" {
+		t.Errorf("expected the digest-pinned image to be immutable, got %+v", pinned)
+	}
+	if len(pinned.Workloads) != 1 || pinned.Workloads[0].Kind != "Deployment" || pinned.Workloads[0].Name != "web" {
+		t.Fatalf("expected the replicas to be reported as deployment web, got %+v", pinned.Workloads)
+	}
+	if got := strings.Join(pinned.Workloads[0].Containers, ","); got != "init:migrate,api" {
+		t.Errorf("unexpected containers %q", got)
+	}
+	if got := strings.Join(pinned.PullPolicies, ","); got != "Always,IfNotPresent" {
+		t.Errorf("unexpected pull policies %q", got)
+	}
+	if got := strings.Join(pinned.PullSecrets, ","); got != "shop/ghcr" {
+		t.Errorf("unexpected pull secrets %q", got)
+	}
+
+	// 仅有标签的镜像：可变，由 Deployment 和 StatefulSet 共同使用
+	tagged := images["nginx:1.25"]
+	if !tagged.Mutable || tagged.Tag != "1.25" || tagged.Repository != "library/nginx" || len(tagged.Workloads) != 2 {
+		t.Errorf("expected the tag-only image to be mutable and used by two workloads, got %+v", tagged)
+	}
+
+	// 临时容器和 :latest 镜像的默认拉取策略为 Always
+	debug := images["busybox"]
+	if len(debug.Workloads) != 1 || debug.Workloads[0].Containers[0] != "ephemeral:debugger" || debug.PullPolicies[0] != "Always" {
+		t.Errorf("unexpected ephemeral container image %+v", debug)
+	}
+	latest := images["busybox:latest"]
+	if !latest.Mutable || latest.Workloads[0].Kind != "Pod" || latest.Workloads[0].Name != "toolbox" || latest.PullPolicies[0] != "Always" {
+		t.Errorf("unexpected standalone pod image %+v", latest)
+	}
+
+	// 文本按使用次数排序
+	text := RenderImageInventory(inventory)
+	if !strings.HasPrefix(text, "4 images in namespace shop (source: pods)\n\n[2] nginx:1.25 (mutable, IfNotPresent, pull secrets shop/ghcr)\n  Deployment shop/web: web\n  StatefulSet shop/db: postgres\n") {
+		t.Errorf("expected the most used image first, got:\n%s", text)
+	}
+}
+
+// TestListImagesSources 测试 deployments 来源、按镜像仓库汇总和非法参数
+func TestListImagesSources(t *testing.T) {
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", imageFixtures())
+	ro := NewResourceOperations(cm)
+	ctx := context.Background()
+
+	inventory, err := ro.ListImages(ctx, "", ImageSourceDeployments, "", "test")
+	if err != nil {
+		t.Fatalf("ListImages(deployments) failed: %v", err)
+	}
+	if inventory.Scope != "all namespaces" || len(inventory.Images) != 1 || inventory.Images[0].Workloads[0].Name != "web" {
+		t.Errorf("expected the deployment template image only, got %+v", inventory)
+	}
+
+	inventory, err = ro.ListImages(ctx, "shop", ImageSourcePods, ImageGroupByRegistry, "test")
+	if err != nil {
+		t.Fatalf("ListImages(group_by=registry) failed: %v", err)
+	}
+	want := []types.RegistryUsage{
+		{Registry: "docker.io", Images: 3, Workloads: 3, MutableImages: 3},
+		{Registry: "ghcr.io", Images: 1, Workloads: 1, MutableImages: 0},
+	}
+	if len(inventory.Registries) != len(want) {
+		t.Fatalf("unexpected registries %+v", inventory.Registries)
+	}
+	for i := range want {
+		if inventory.Registries[i] != want[i] {
+			t.Errorf("registry %d = %+v, want %+v", i, inventory.Registries[i], want[i])
+		}
+	}
+
+	if _, err := ro.ListImages(ctx, "shop", "jobs", "", "test"); err == nil {
+		t.Errorf("expected an unsupported source to fail")
+	}
+	if _, err := ro.ListImages(ctx, "shop", "", "team", "test"); err == nil {
+		t.Errorf("expected an unsupported group_by to fail")
+	}
+}
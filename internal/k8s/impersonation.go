@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// impersonationKey carries the identity a request should be made as
+// impersonationKey 携带请求需要模拟的身份
+type impersonationKey struct{}
+
+// WithImpersonation makes every API request issued with ctx run as the given
+// identity, overriding the server-wide impersonation. An empty UserName keeps
+// the server-wide setting.
+// WithImpersonation 使用 ctx 发出的所有 API 请求都以给定身份执行，覆盖服务器级的模拟配置；
+// UserName 为空时保留服务器级配置。
+func WithImpersonation(ctx context.Context, impersonate rest.ImpersonationConfig) context.Context {
+	if impersonate.UserName == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, impersonationKey{}, impersonate)
+}
+
+// Impersonation returns the identity API requests issued with ctx run as; an
+// empty UserName means the cluster credential is used directly
+// Impersonation 返回使用 ctx 发出的 API 请求所模拟的身份，UserName 为空表示直接使用集群凭据
+func (cm *ClusterManager) Impersonation(ctx context.Context) rest.ImpersonationConfig {
+	if impersonate, ok := ctx.Value(impersonationKey{}).(rest.ImpersonationConfig); ok {
+		return impersonate
+	}
+	return cm.impersonate
+}
+
+// impersonatingTransport sets the Impersonate-* headers from the request context,
+// falling back to the server-wide identity. Doing it per request lets all callers
+// share one clientset, connection pool and rate limiter per cluster.
+// impersonatingTransport 根据请求上下文设置 Impersonate-* 请求头，未设置时使用服务器级身份。
+// 按请求设置使所有调用者共享每个集群的同一个 clientset、连接池和限流器。
+type impersonatingTransport struct {
+	cm   *ClusterManager
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *impersonatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	impersonate := t.cm.Impersonation(req.Context())
+	if impersonate.UserName == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// Replace any impersonation set by the kubeconfig
+	// 替换 kubeconfig 中设置的模拟身份
+	req = utilnet.CloneRequest(req)
+	for key := range req.Header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(key), "Impersonate-") {
+			req.Header.Del(key)
+		}
+	}
+	req.Header.Set(transport.ImpersonateUserHeader, impersonate.UserName)
+	if impersonate.UID != "" {
+		req.Header.Set(transport.ImpersonateUIDHeader, impersonate.UID)
+	}
+	for _, group := range impersonate.Groups {
+		req.Header.Add(transport.ImpersonateGroupHeader, group)
+	}
+	return t.next.RoundTrip(req)
+}
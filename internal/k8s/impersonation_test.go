@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// fakeAccessReviewServer 模拟 SelfSubjectAccessReview 接口：只允许 alice 执行操作，并记录每个请求的模拟身份
+func fakeAccessReviewServer(t *testing.T) (*httptest.Server, func() []http.Header) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+
+		if r.URL.Path != "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var review authorizationv1.SelfSubjectAccessReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review.Status.Allowed = r.Header.Get("Impersonate-User") == "alice"
+		if review.Status.Allowed {
+			review.Status.Reason = `RBAC: allowed by RoleBinding "team-a/edit"`
+		}
+		review.Kind = "SelfSubjectAccessReview"
+		review.APIVersion = "authorization.k8s.io/v1"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]http.Header(nil), headers...)
+	}
+}
+
+// TestImpersonationHeaders 测试服务器级模拟身份和请求上下文中的身份
+func TestImpersonationHeaders(t *testing.T) {
+	apiServer, requests := fakeAccessReviewServer(t)
+
+	cm := NewClusterManager(&Options{Impersonate: rest.ImpersonationConfig{UserName: "readonly", Groups: []string{"viewers"}}})
+	// kubeconfig 中的模拟身份会被覆盖
+	if err := cm.AddCluster("test", &rest.Config{Host: apiServer.URL, Impersonate: rest.ImpersonationConfig{UserName: "kubeconfig-user"}}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	ro := NewResourceOperations(cm)
+	check := PermissionCheck{Verb: "delete", Resource: "pods", Namespace: "team-a"}
+
+	permission, err := ro.CheckPermission(context.Background(), check, "test")
+	if err != nil {
+		t.Fatalf("CheckPermission failed: %v", err)
+	}
+	if permission.Allowed || permission.Reason != "Permission denied" {
+		t.Errorf("unexpected permission for readonly: %+v", permission)
+	}
+
+	ctx := WithImpersonation(context.Background(), rest.ImpersonationConfig{UserName: "alice", Groups: []string{"team-a", "devs"}})
+	permission, err = ro.CheckPermission(ctx, check, "test")
+	if err != nil {
+		t.Fatalf("CheckPermission failed: %v", err)
+	}
+	if !permission.Allowed || permission.Reason != `RBAC: allowed by RoleBinding "team-a/edit"` {
+		t.Errorf("unexpected permission for alice: %+v", permission)
+	}
+	if got := cm.Impersonation(ctx).UserName; got != "alice" {
+		t.Errorf("Impersonation(ctx) = %q, want alice", got)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 API requests, got %d", len(got))
+	}
+	if user, groups := got[0].Get("Impersonate-User"), got[0].Values("Impersonate-Group"); user != "readonly" || !reflect.DeepEqual(groups, []string{"viewers"}) {
+		t.Errorf("unexpected default impersonation: %q %v", user, groups)
+	}
+	if user, groups := got[1].Get("Impersonate-User"), got[1].Values("Impersonate-Group"); user != "alice" || !reflect.DeepEqual(groups, []string{"team-a", "devs"}) {
+		t.Errorf("unexpected context impersonation: %q %v", user, groups)
+	}
+}
+
+// TestNoImpersonation 测试未配置模拟身份时不发送 Impersonate 请求头
+func TestNoImpersonation(t *testing.T) {
+	apiServer, requests := fakeAccessReviewServer(t)
+
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	// UserName 为空的上下文身份被忽略
+	ctx := WithImpersonation(context.Background(), rest.ImpersonationConfig{Groups: []string{"team-a"}})
+	if _, err := NewResourceOperations(cm).CheckPermission(ctx, PermissionCheck{Verb: "get", Resource: "pods"}, "test"); err != nil {
+		t.Fatalf("CheckPermission failed: %v", err)
+	}
+
+	for _, header := range requests() {
+		for key := range header {
+			if strings.HasPrefix(key, "Impersonate-") {
+				t.Errorf("unexpected header %s", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,619 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Categories reported by FindIssues, in report order
+// FindIssues 报告的问题类别，按报告顺序排列
+const (
+	IssueUnmanagedPod            = "unmanaged-pod"
+	IssueScaledToZero            = "scaled-to-zero"
+	IssueServiceWithoutEndpoints = "service-without-endpoints"
+	IssueUnusedConfigMap         = "unused-configmap"
+	IssueUnusedSecret            = "unused-secret"
+	IssueLatestImage             = "latest-image"
+	IssueMissingResources        = "missing-resources"
+)
+
+// Severities of the issues reported by FindIssues
+// FindIssues 报告的问题严重程度
+const (
+	IssueSeverityWarning = "warning"
+	IssueSeverityInfo    = "info"
+)
+
+// issuesPageSize is the page size FindIssues lists objects with
+// issuesPageSize 为 FindIssues 分页列出对象时的每页数量
+const issuesPageSize int64 = 500
+
+// issueCategories lists the categories in report order with their one-line remediation hint
+// issueCategories 按报告顺序列出问题类别及其一行修复建议
+var issueCategories = []struct {
+	name        string
+	remediation string
+}{
+	{IssueUnmanagedPod, "Run it from a Deployment, StatefulSet or Job so it is recreated when it dies or its node fails"},
+	{IssueScaledToZero, "Delete the Deployment if it is no longer needed, or scale it back up"},
+	{IssueServiceWithoutEndpoints, "Check that the selector matches running, ready pods; get_service_endpoints shows why they are missing"},
+	{IssueUnusedConfigMap, "Delete it if nothing reads it through the API, or mount it where it is needed"},
+	{IssueUnusedSecret, "Delete it if nothing reads it through the API, or mount it where it is needed"},
+	{IssueLatestImage, "Pin the image to a version tag or digest so rollouts and rollbacks are reproducible"},
+	{IssueMissingResources, "Set CPU and memory requests (and limits) so scheduling, quotas and evictions account for the container"},
+}
+
+// issuesScanLimit caps how many objects of each kind FindIssues scans, so a cluster-wide
+// scan of a large cluster stays bounded; a variable so tests can lower it
+// issuesScanLimit 限制 FindIssues 每种资源扫描的对象数量，使大集群的全集群扫描保持有界；定义为变量以便测试调低
+var issuesScanLimit = 5000
+
+// issueSystemNamespaces hold ConfigMaps and Secrets that control-plane components read
+// through the API, so they are not reported as unused
+// issueSystemNamespaces 中的 ConfigMap 和 Secret 由控制平面组件通过 API 读取，不报告为未使用
+var issueSystemNamespaces = map[string]bool{"kube-system": true, "kube-public": true, "kube-node-lease": true}
+
+// ignoredSecretTypes are managed by Kubernetes or tools and never referenced by a pod spec
+// ignoredSecretTypes 由 Kubernetes 或工具管理，从不被 Pod spec 引用
+var ignoredSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	corev1.SecretTypeBootstrapToken:      true,
+	"helm.sh/release.v1":                 true,
+}
+
+// issueScan lists the objects FindIssues inspects page by page, recording the kinds that
+// hit issuesScanLimit and the kinds that could not be listed
+// issueScan 分页列出 FindIssues 检查的对象，记录达到 issuesScanLimit 的资源类型以及无法列出的资源类型
+type issueScan struct {
+	namespaces []string
+	scanned    map[string]int
+	truncated  map[string]bool
+	failed     map[string]error
+}
+
+// newIssueScan returns a scan of one namespace, or of all namespaces when namespace is empty
+// newIssueScan 返回扫描一个命名空间 (namespace 为空时为所有命名空间) 的 issueScan
+func newIssueScan(namespace string) *issueScan {
+	return &issueScan{
+		namespaces: []string{namespace},
+		scanned:    map[string]int{},
+		truncated:  map[string]bool{},
+		failed:     map[string]error{},
+	}
+}
+
+// scanIssueList lists one kind in every scanned namespace, following the continue token
+// until the list ends or issuesScanLimit objects were read. A failed list is recorded
+// and yields no objects, so the checks depending on it are skipped
+// scanIssueList 在每个扫描的命名空间中列出一种资源，沿 continue 令牌翻页直到列表结束或读取了 issuesScanLimit 个对象。
+// 列出失败时记录错误并返回空结果，依赖它的检查会被跳过
+func scanIssueList[T any](scan *issueScan, kind string, list func(namespace string, opts metav1.ListOptions) ([]T, string, error)) []T {
+	var items []T
+	for i, ns := range scan.namespaces {
+		opts := metav1.ListOptions{Limit: issuesPageSize}
+		for {
+			page, next, err := list(ns, opts)
+			if err != nil {
+				scan.failed[kind] = err
+				return nil
+			}
+			items = append(items, page...)
+			more := next != "" || i < len(scan.namespaces)-1
+			if len(items) > issuesScanLimit || (len(items) == issuesScanLimit && more) {
+				items = items[:issuesScanLimit]
+				scan.truncated[kind] = true
+				scan.scanned[kind] = len(items)
+				return items
+			}
+			if next == "" {
+				break
+			}
+			opts.Continue = next
+		}
+	}
+	scan.scanned[kind] = len(items)
+	return items
+}
+
+// skip reports whether a check cannot run because one of the kinds it needs failed to
+// list, or, for complete, was truncated, and records why in the report
+// skip 判断检查是否因所需资源无法列出 (或在 complete 中的资源被截断) 而无法执行，并在报告中记录原因
+func (scan *issueScan) skip(report *types.IssueReport, category string, needed, complete []string) bool {
+	for _, kind := range append(append([]string(nil), needed...), complete...) {
+		if err := scan.failed[kind]; err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: cannot list %s: %v", category, kind, err))
+			return true
+		}
+	}
+	for _, kind := range complete {
+		if scan.truncated[kind] {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %s truncated at %d objects", category, kind, issuesScanLimit))
+			return true
+		}
+	}
+	return false
+}
+
+// FindIssues scans a namespace, or all namespaces when namespace is empty, for common
+// hygiene problems: pods not managed by a controller, deployments scaled to zero, services
+// without ready endpoints, ConfigMaps and Secrets no pod spec references, images on the
+// latest tag and containers without requests or limits. Every kind is listed in pages and
+// capped at issuesScanLimit objects
+// FindIssues 扫描命名空间（namespace 为空时为所有命名空间）中的常见卫生问题：不受控制器管理的 Pod、副本数为 0 的 Deployment、
+// 没有就绪端点的 Service、未被任何 Pod spec 引用的 ConfigMap 和 Secret、使用 latest 标签的镜像以及没有 requests 或 limits 的容器。
+// 每种资源都分页列出，最多扫描 issuesScanLimit 个对象
+func (ro *ResourceOperations) FindIssues(ctx context.Context, namespace, clusterName string) (*types.IssueReport, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "namespace " + namespace
+	scan := newIssueScan(namespace)
+	if namespace == "" {
+		scope = "all namespaces"
+		if ro.clusterManager.namespacePolicy.Restricted() {
+			scope = "allowed namespaces"
+			scan.namespaces, err = ro.clusterManager.AllowedNamespaces(ctx, clusterName)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	objects := &issueObjects{}
+	scanReferrers(ctx, client, scan, objects)
+	objects.services = scanIssueList(scan, "services", func(ns string, opts metav1.ListOptions) ([]corev1.Service, string, error) {
+		list, err := client.CoreV1().Services(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	objects.endpointSlices = scanIssueList(scan, "endpointslices", func(ns string, opts metav1.ListOptions) ([]discoveryv1.EndpointSlice, string, error) {
+		list, err := client.DiscoveryV1().EndpointSlices(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	objects.configMaps = scanIssueList(scan, "configmaps", func(ns string, opts metav1.ListOptions) ([]corev1.ConfigMap, string, error) {
+		list, err := client.CoreV1().ConfigMaps(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	objects.secrets = scanIssueList(scan, "secrets", func(ns string, opts metav1.ListOptions) ([]corev1.Secret, string, error) {
+		list, err := client.CoreV1().Secrets(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	objects.limitRanges = scanIssueList(scan, "limitranges", func(ns string, opts metav1.ListOptions) ([]corev1.LimitRange, string, error) {
+		list, err := client.CoreV1().LimitRanges(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	if err := scan.failed["pods"]; err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Clusters without the discovery.k8s.io/v1 API fall back to the legacy Endpoints
+	// 没有 discovery.k8s.io/v1 API 的集群回退到旧版 Endpoints
+	objects.endpointSource = EndpointSourceSlices
+	if scan.failed["endpointslices"] != nil {
+		objects.endpointSource = EndpointSourceLegacy
+		delete(scan.failed, "endpointslices")
+		objects.endpoints = scanIssueList(scan, "endpoints", func(ns string, opts metav1.ListOptions) ([]corev1.Endpoints, string, error) {
+			list, err := client.CoreV1().Endpoints(ns).List(ctx, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return list.Items, list.Continue, nil
+		})
+	}
+
+	return findIssues(scope, scan, objects), nil
+}
+
+// scanReferrers lists the objects that can reference ConfigMaps and Secrets: pods, the
+// workloads with pod templates, service accounts and ingresses
+// scanReferrers 列出可能引用 ConfigMap 和 Secret 的对象：Pod、带有 Pod 模板的工作负载、ServiceAccount 和 Ingress
+func scanReferrers(ctx context.Context, client kubernetes.Interface, scan *issueScan, o *issueObjects) {
+	o.pods = scanIssueList(scan, "pods", func(ns string, opts metav1.ListOptions) ([]corev1.Pod, string, error) {
+		list, err := client.CoreV1().Pods(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.deployments = scanIssueList(scan, "deployments", func(ns string, opts metav1.ListOptions) ([]appsv1.Deployment, string, error) {
+		list, err := client.AppsV1().Deployments(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.statefulSets = scanIssueList(scan, "statefulsets", func(ns string, opts metav1.ListOptions) ([]appsv1.StatefulSet, string, error) {
+		list, err := client.AppsV1().StatefulSets(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.daemonSets = scanIssueList(scan, "daemonsets", func(ns string, opts metav1.ListOptions) ([]appsv1.DaemonSet, string, error) {
+		list, err := client.AppsV1().DaemonSets(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.cronJobs = scanIssueList(scan, "cronjobs", func(ns string, opts metav1.ListOptions) ([]batchv1.CronJob, string, error) {
+		list, err := client.BatchV1().CronJobs(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.serviceAccounts = scanIssueList(scan, "serviceaccounts", func(ns string, opts metav1.ListOptions) ([]corev1.ServiceAccount, string, error) {
+		list, err := client.CoreV1().ServiceAccounts(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+	o.ingresses = scanIssueList(scan, "ingresses", func(ns string, opts metav1.ListOptions) ([]networkingv1.Ingress, string, error) {
+		list, err := client.NetworkingV1().Ingresses(ns).List(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Items, list.Continue, nil
+	})
+}
+
+// issueObjects holds the objects FindIssues scanned
+// issueObjects 保存 FindIssues 扫描的对象
+type issueObjects struct {
+	pods            []corev1.Pod
+	deployments     []appsv1.Deployment
+	statefulSets    []appsv1.StatefulSet
+	daemonSets      []appsv1.DaemonSet
+	cronJobs        []batchv1.CronJob
+	services        []corev1.Service
+	endpointSlices  []discoveryv1.EndpointSlice
+	endpoints       []corev1.Endpoints
+	endpointSource  string
+	configMaps      []corev1.ConfigMap
+	secrets         []corev1.Secret
+	serviceAccounts []corev1.ServiceAccount
+	ingresses       []networkingv1.Ingress
+	limitRanges     []corev1.LimitRange
+}
+
+// podTemplate is a pod spec to check for images and resources: a workload template, or
+// a pod not created from one
+// podTemplate 是需要检查镜像和资源的 Pod spec：工作负载模板，或不是由模板创建的 Pod
+type podTemplate struct {
+	kind, namespace, name string
+	spec                  *corev1.PodSpec
+}
+
+// templates returns the pod templates of the scanned workloads and the pods no scanned
+// workload kind creates, so images and resources are reported once per workload rather
+// than once per replica
+// templates 返回扫描到的工作负载的 Pod 模板以及不由这些工作负载类型创建的 Pod，使镜像和资源问题按工作负载而不是按副本报告
+func (o *issueObjects) templates() []podTemplate {
+	var templates []podTemplate
+	for i := range o.deployments {
+		d := &o.deployments[i]
+		templates = append(templates, podTemplate{"Deployment", d.Namespace, d.Name, &d.Spec.Template.Spec})
+	}
+	for i := range o.statefulSets {
+		s := &o.statefulSets[i]
+		templates = append(templates, podTemplate{"StatefulSet", s.Namespace, s.Name, &s.Spec.Template.Spec})
+	}
+	for i := range o.daemonSets {
+		d := &o.daemonSets[i]
+		templates = append(templates, podTemplate{"DaemonSet", d.Namespace, d.Name, &d.Spec.Template.Spec})
+	}
+	for i := range o.cronJobs {
+		c := &o.cronJobs[i]
+		templates = append(templates, podTemplate{"CronJob", c.Namespace, c.Name, &c.Spec.JobTemplate.Spec.Template.Spec})
+	}
+	for i := range o.pods {
+		pod := &o.pods[i]
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			switch owner.Kind {
+			case "ReplicaSet", "StatefulSet", "DaemonSet", "Job":
+				continue
+			}
+		}
+		templates = append(templates, podTemplate{"Pod", pod.Namespace, pod.Name, &pod.Spec})
+	}
+	return templates
+}
+
+// findIssues runs the checks over the scanned objects and builds the report
+// findIssues 对扫描的对象执行检查并生成报告
+func findIssues(scope string, scan *issueScan, o *issueObjects) *types.IssueReport {
+	report := &types.IssueReport{Scope: scope, Scanned: scan.scanned, Counts: map[string]int{}, Issues: []types.Issue{}}
+	for kind := range scan.truncated {
+		report.Truncated = append(report.Truncated, kind)
+	}
+	sort.Strings(report.Truncated)
+
+	add := func(category, severity, kind, namespace, name, message string) {
+		report.Issues = append(report.Issues, types.Issue{
+			Category: category, Severity: severity, Kind: kind, Namespace: namespace, Name: name, Message: message,
+		})
+	}
+	workloads := []string{"pods", "deployments", "statefulsets", "daemonsets", "cronjobs"}
+
+	for _, pod := range o.pods {
+		// Mirror pods of static manifests are managed by the kubelet
+		// 静态 Pod 的镜像 Pod 由 kubelet 管理
+		if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror || len(pod.OwnerReferences) > 0 {
+			continue
+		}
+		add(IssueUnmanagedPod, IssueSeverityWarning, "Pod", pod.Namespace, pod.Name,
+			fmt.Sprintf("not managed by any controller (%s)", pod.Status.Phase))
+	}
+
+	if !scan.skip(report, IssueScaledToZero, []string{"deployments"}, nil) {
+		for _, d := range o.deployments {
+			if d.Spec.Replicas != nil && *d.Spec.Replicas == 0 {
+				add(IssueScaledToZero, IssueSeverityInfo, "Deployment", d.Namespace, d.Name, "scaled to 0 replicas")
+			}
+		}
+	}
+
+	endpointKind := "endpointslices"
+	if o.endpointSource == EndpointSourceLegacy {
+		endpointKind = "endpoints"
+	}
+	if !scan.skip(report, IssueServiceWithoutEndpoints, []string{"services"}, []string{endpointKind}) {
+		endpoints := map[string][]types.ServiceEndpoint{}
+		for _, slice := range o.endpointSlices {
+			key := slice.Namespace + "/" + slice.Labels[discoveryv1.LabelServiceName]
+			endpoints[key] = append(endpoints[key], sliceEndpoints([]discoveryv1.EndpointSlice{slice})...)
+		}
+		for i := range o.endpoints {
+			endpoints[o.endpoints[i].Namespace+"/"+o.endpoints[i].Name] = legacyEndpoints(&o.endpoints[i])
+		}
+		for _, service := range o.services {
+			if service.Spec.Type == corev1.ServiceTypeExternalName {
+				continue
+			}
+			ready := 0
+			all := endpoints[service.Namespace+"/"+service.Name]
+			for _, e := range all {
+				if e.Ready {
+					ready++
+				}
+			}
+			if ready > 0 {
+				continue
+			}
+			selector := "no selector"
+			if len(service.Spec.Selector) > 0 {
+				selector = "selector " + labels.SelectorFromSet(service.Spec.Selector).String()
+			}
+			message := fmt.Sprintf("no endpoints (%s)", selector)
+			if len(all) > 0 {
+				message = fmt.Sprintf("no ready endpoints, %d not ready (%s)", len(all), selector)
+			}
+			add(IssueServiceWithoutEndpoints, IssueSeverityWarning, "Service", service.Namespace, service.Name, message)
+		}
+	}
+
+	refs := buildConfigReferences(o)
+	if !scan.skip(report, IssueUnusedConfigMap, []string{"configmaps"}, workloads) {
+		for _, cm := range o.configMaps {
+			// kube-root-ca.crt is published into every namespace by the control plane
+			// kube-root-ca.crt 由控制平面发布到每个命名空间
+			if cm.Name == "kube-root-ca.crt" || issueSystemNamespaces[cm.Namespace] || len(cm.OwnerReferences) > 0 ||
+				len(refs.configMaps[cm.Namespace+"/"+cm.Name]) > 0 {
+				continue
+			}
+			add(IssueUnusedConfigMap, IssueSeverityInfo, "ConfigMap", cm.Namespace, cm.Name, "not referenced by any pod spec")
+		}
+	}
+	if !scan.skip(report, IssueUnusedSecret, []string{"secrets"}, append(workloads, "serviceaccounts", "ingresses")) {
+		for _, secret := range o.secrets {
+			if ignoredSecretTypes[secret.Type] || issueSystemNamespaces[secret.Namespace] || len(secret.OwnerReferences) > 0 ||
+				len(refs.secrets[secret.Namespace+"/"+secret.Name]) > 0 {
+				continue
+			}
+			add(IssueUnusedSecret, IssueSeverityInfo, "Secret", secret.Namespace, secret.Name,
+				"not referenced by any pod spec, service account or ingress")
+		}
+	}
+
+	// LimitRange defaults are applied when pods are admitted, so templates in a namespace
+	// with container defaults get requests and limits even when they set none
+	// LimitRange 默认值在 Pod 准入时生效，因此有容器默认值的命名空间中，模板即使没有设置也会获得 requests 和 limits
+	defaulted := map[string]bool{}
+	for _, lr := range o.limitRanges {
+		for _, limit := range lr.Spec.Limits {
+			if limit.Type == corev1.LimitTypeContainer && (len(limit.Default) > 0 || len(limit.DefaultRequest) > 0) {
+				defaulted[lr.Namespace] = true
+			}
+		}
+	}
+	checkResources := !scan.skip(report, IssueMissingResources, []string{"limitranges"}, nil)
+	for _, template := range o.templates() {
+		for _, c := range template.spec.InitContainers {
+			if imageUsesLatest(c.Image) {
+				add(IssueLatestImage, IssueSeverityWarning, template.kind, template.namespace, template.name,
+					fmt.Sprintf("init container %s uses image %s", c.Name, c.Image))
+			}
+		}
+		for _, c := range template.spec.Containers {
+			if imageUsesLatest(c.Image) {
+				add(IssueLatestImage, IssueSeverityWarning, template.kind, template.namespace, template.name,
+					fmt.Sprintf("container %s uses image %s", c.Name, c.Image))
+			}
+			if !checkResources || defaulted[template.namespace] {
+				continue
+			}
+			if severity, message := containerResourceIssue(c); message != "" {
+				add(IssueMissingResources, severity, template.kind, template.namespace, template.name, message)
+			}
+		}
+	}
+
+	rank := map[string]int{}
+	remediation := map[string]string{}
+	for i, category := range issueCategories {
+		rank[category.name] = i
+		remediation[category.name] = category.remediation
+		report.Counts[category.name] = 0
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Category != b.Category {
+			return rank[a.Category] < rank[b.Category]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	for i := range report.Issues {
+		report.Issues[i].Remediation = remediation[report.Issues[i].Category]
+		report.Counts[report.Issues[i].Category]++
+	}
+
+	report.Text = renderIssueReport(report)
+	return report
+}
+
+// imageUsesLatest reports whether an image resolves to the latest tag: tagged :latest, or
+// untagged and not pinned by digest
+// imageUsesLatest 判断镜像是否解析为 latest 标签：标记为 :latest，或没有标签且没有通过摘要固定
+func imageUsesLatest(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
+
+// containerResourceIssue describes the CPU and memory requests and limits a container
+// lacks: missing requests are a warning, missing limits only are info. A resource with
+// only a limit is requested at the limit, so it counts as requested
+// containerResourceIssue 描述容器缺少的 CPU 和内存 requests 与 limits：缺少 requests 为 warning，仅缺少 limits 为 info。
+// 只设置了 limit 的资源按 limit 请求，因此视为已设置 requests
+func containerResourceIssue(c corev1.Container) (string, string) {
+	var noRequests, noLimits []string
+	for _, name := range usageResources {
+		_, limit := c.Resources.Limits[name]
+		if _, request := c.Resources.Requests[name]; !request && !limit {
+			noRequests = append(noRequests, string(name))
+		}
+		if !limit {
+			noLimits = append(noLimits, string(name))
+		}
+	}
+
+	var missing []string
+	if len(noRequests) > 0 {
+		missing = append(missing, strings.Join(noRequests, "/")+" requests")
+	}
+	if len(noLimits) > 0 {
+		missing = append(missing, strings.Join(noLimits, "/")+" limits")
+	}
+	if len(missing) == 0 {
+		return "", ""
+	}
+	severity := IssueSeverityInfo
+	if len(noRequests) > 0 {
+		severity = IssueSeverityWarning
+	}
+	return severity, fmt.Sprintf("container %s has no %s", c.Name, strings.Join(missing, " or "))
+}
+
+// renderIssueReport renders the issues grouped by category, each group headed by its
+// remediation hint
+// renderIssueReport 将问题按类别分组渲染，每组以修复建议开头
+func renderIssueReport(report *types.IssueReport) string {
+	var sb strings.Builder
+
+	warnings := 0
+	for _, issue := range report.Issues {
+		if issue.Severity == IssueSeverityWarning {
+			warnings++
+		}
+	}
+	if len(report.Issues) == 0 {
+		fmt.Fprintf(&sb, "No issues found in %s\n", report.Scope)
+	} else {
+		fmt.Fprintf(&sb, "%d issues in %s (%d warning, %d info)\n", len(report.Issues), report.Scope, warnings, len(report.Issues)-warnings)
+	}
+
+	var kinds []string
+	for kind := range report.Scanned {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	var scanned []string
+	for _, kind := range kinds {
+		scanned = append(scanned, fmt.Sprintf("%d %s", report.Scanned[kind], kind))
+	}
+	fmt.Fprintf(&sb, "Scanned: %s\n", strings.Join(scanned, ", "))
+	if len(report.Truncated) > 0 {
+		fmt.Fprintf(&sb, "Truncated at %d objects: %s\n", issuesScanLimit, strings.Join(report.Truncated, ", "))
+	}
+	for _, skipped := range report.Skipped {
+		fmt.Fprintf(&sb, "Skipped %s\n", skipped)
+	}
+
+	for _, category := range issueCategories {
+		if report.Counts[category.name] == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s (%d): %s\n", category.name, report.Counts[category.name], category.remediation)
+		for _, issue := range report.Issues {
+			if issue.Category != category.name {
+				continue
+			}
+			object := issue.Kind + " " + issue.Name
+			if issue.Namespace != "" {
+				object = issue.Kind + " " + issue.Namespace + "/" + issue.Name
+			}
+			fmt.Fprintf(&sb, "  [%s] %s: %s\n", issue.Severity, object, issue.Message)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// issueFixture 解码 testdata/issues.yaml：shop 命名空间中被引用和未被引用的 ConfigMap/Secret、副本数为 0 的 Deployment、
+// 没有端点的 Service、使用 latest 镜像的独立 Pod 等
+func issueFixture(t *testing.T) []runtime.Object {
+	t.Helper()
+	data, err := os.ReadFile("testdata/issues.yaml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	objects, err := decodeMockObjects(data)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	return objects
+}
+
+// TestFindIssues 测试每个类别的发现、引用图 (卷、envFrom、env valueFrom、imagePullSecrets、Ingress TLS) 以及文本输出
+func TestFindIssues(t *testing.T) {
+	ro, _ := newFakeOperations(t, issueFixture(t)...)
+
+	report, err := ro.FindIssues(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("FindIssues failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range report.Issues {
+		if issue.Remediation == "" {
+			t.Errorf("issue without remediation: %+v", issue)
+		}
+		got = append(got, fmt.Sprintf("%s %s %s %s/%s", issue.Category, issue.Severity, issue.Kind, issue.Namespace, issue.Name))
+	}
+	want := []string{
+		"unmanaged-pod warning Pod shop/debug",
+		"scaled-to-zero info Deployment shop/legacy",
+		"service-without-endpoints warning Service shop/legacy",
+		"unused-configmap info ConfigMap shop/stale-config",
+		"unused-secret info Secret shop/old-api-key",
+		"latest-image warning Deployment shop/legacy",
+		"latest-image warning Pod shop/debug",
+		"missing-resources info CronJob shop/report",
+		"missing-resources warning Deployment shop/legacy",
+		"missing-resources info Pod shop/debug",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.Counts["latest-image"] != 2 || report.Counts["unused-secret"] != 1 || len(report.Truncated) != 0 || len(report.Skipped) != 0 {
+		t.Errorf("unexpected counts, truncated or skipped checks: %+v", report)
+	}
+	assertGolden(t, "issues.txt", report.Text)
+}
+
+// TestFindIssuesLimits 测试达到扫描上限时截断列表并跳过依赖完整列表的检查，以及无权列出 Secret 时跳过 unused-secret 检查
+func TestFindIssuesLimits(t *testing.T) {
+	ro, client := newFakeOperations(t, issueFixture(t)...)
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", fmt.Errorf("access denied"))
+	})
+
+	limit := issuesScanLimit
+	issuesScanLimit = 1
+	defer func() { issuesScanLimit = limit }()
+
+	report, err := ro.FindIssues(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("FindIssues failed: %v", err)
+	}
+	if report.Scope != "namespace shop" || report.Scanned["pods"] != 1 || report.Scanned["configmaps"] != 1 {
+		t.Errorf("expected one object scanned per kind, got %+v", report.Scanned)
+	}
+	if strings.Join(report.Truncated, ",") != "configmaps,deployments,pods,services" {
+		t.Errorf("unexpected truncated kinds %q", report.Truncated)
+	}
+	wantSkipped := []string{
+		"unused-configmap: pods truncated at 1 objects",
+		`unused-secret: cannot list secrets: secrets is forbidden: access denied`,
+	}
+	if strings.Join(report.Skipped, "\n") != strings.Join(wantSkipped, "\n") {
+		t.Errorf("unexpected skipped checks %q", report.Skipped)
+	}
+	for _, issue := range report.Issues {
+		if issue.Category == IssueUnusedConfigMap || issue.Category == IssueUnusedSecret {
+			t.Errorf("unexpected %s issue from an incomplete scan: %+v", issue.Category, issue)
+		}
+	}
+	if !strings.Contains(report.Text, "Truncated at 1 objects: configmaps, deployments, pods, services") {
+		t.Errorf("expected the truncation note, got:\n%s", report.Text)
+	}
+}
+
+// TestImageUsesLatest 测试镜像标签的解析：带端口的仓库、摘要固定以及没有标签的镜像
+func TestImageUsesLatest(t *testing.T) {
+	for image, want := range map[string]bool{
+		"nginx":                               true,
+		"nginx:latest":                        true,
+		"nginx:1.25":                          false,
+		"registry.local:5000/team/app":        true,
+		"registry.local:5000/team/app:v2":     false,
+		"ghcr.io/org/app@sha256:0123456789ab": false,
+		"":                                    false,
+	} {
+		if got := imageUsesLatest(image); got != want {
+			t.Errorf("imageUsesLatest(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ListCronJobs lists cron jobs in a namespace
+// ListCronJobs 列出命名空间中的 CronJob
+func (ro *ResourceOperations) ListCronJobs(ctx context.Context, namespace, clusterName string) ([]types.CronJob, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.CronJob, error) {
+			return ro.ListCronJobs(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+
+	var results []types.CronJob
+	for _, cj := range cronJobs.Items {
+		var lastSchedule, lastSuccessful metav1.Time
+		if cj.Status.LastScheduleTime != nil {
+			lastSchedule = *cj.Status.LastScheduleTime
+		}
+		if cj.Status.LastSuccessfulTime != nil {
+			lastSuccessful = *cj.Status.LastSuccessfulTime
+		}
+
+		results = append(results, types.CronJob{
+			Name:               cj.Name,
+			Namespace:          cj.Namespace,
+			Schedule:           cj.Spec.Schedule,
+			Suspend:            cj.Spec.Suspend != nil && *cj.Spec.Suspend,
+			Active:             len(cj.Status.Active),
+			LastSchedule:       formatLastRun(lastSchedule),
+			LastScheduleTime:   formatTimestamp(lastSchedule),
+			LastSuccessfulTime: formatTimestamp(lastSuccessful),
+			Age:                formatAge(cj.CreationTimestamp),
+			CreatedAt:          formatTimestamp(cj.CreationTimestamp),
+			Labels:             cj.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// formatLastRun renders how long ago a cron job was scheduled, or "<none>" if it never was
+// formatLastRun 显示 CronJob 上次调度距今的时间，从未调度时返回 "<none>"
+func formatLastRun(t metav1.Time) string {
+	if t.IsZero() {
+		return "<none>"
+	}
+	return formatAge(t) + " ago"
+}
+
+// ListJobs lists jobs in a namespace. If cronJobName is set, only jobs owned by
+// that cron job (via ownerReferences) are returned.
+// ListJobs 列出命名空间中的 Job。指定 cronJobName 时仅返回（通过 ownerReferences）
+// 属于该 CronJob 的 Job。
+func (ro *ResourceOperations) ListJobs(ctx context.Context, namespace, clusterName, cronJobName string) ([]types.Job, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Job, error) {
+			return ro.ListJobs(ctx, ns, clusterName, cronJobName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	var results []types.Job
+	for _, job := range jobs.Items {
+		owner := jobCronJobOwner(&job)
+		if cronJobName != "" && owner != cronJobName {
+			continue
+		}
+
+		completions := "1"
+		if job.Spec.Completions != nil {
+			completions = fmt.Sprintf("%d", *job.Spec.Completions)
+		}
+		var started, completed metav1.Time
+		if job.Status.StartTime != nil {
+			started = *job.Status.StartTime
+		}
+		if job.Status.CompletionTime != nil {
+			completed = *job.Status.CompletionTime
+		}
+
+		results = append(results, types.Job{
+			Name:        job.Name,
+			Namespace:   job.Namespace,
+			Status:      jobStatus(&job),
+			Completions: fmt.Sprintf("%d/%s", job.Status.Succeeded, completions),
+			Active:      job.Status.Active,
+			Succeeded:   job.Status.Succeeded,
+			Failed:      job.Status.Failed,
+			Duration:    jobDuration(started, completed),
+			CronJob:     owner,
+			StartTime:   formatTimestamp(started),
+			Age:         formatAge(job.CreationTimestamp),
+			CreatedAt:   formatTimestamp(job.CreationTimestamp),
+			Labels:      job.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// jobCronJobOwner returns the name of the cron job that owns a job, or ""
+// jobCronJobOwner 返回拥有该 Job 的 CronJob 名称，没有则返回空字符串
+func jobCronJobOwner(job *batchv1.Job) string {
+	for _, ref := range job.OwnerReferences {
+		if ref.Kind == "CronJob" {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// jobStatus derives Complete, Failed, Suspended or Running from the job's conditions
+// jobStatus 根据 Job 的状况得出 Complete、Failed、Suspended 或 Running
+func jobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "Complete"
+		case batchv1.JobFailed:
+			if condition.Reason != "" {
+				return "Failed: " + condition.Reason
+			}
+			return "Failed"
+		case batchv1.JobSuspended:
+			return "Suspended"
+		}
+	}
+	return "Running"
+}
+
+// jobDuration is how long a job ran, or has been running so far if it hasn't completed
+// jobDuration 为 Job 的运行时长，尚未完成时为到目前为止的运行时长
+func jobDuration(started, completed metav1.Time) string {
+	if started.IsZero() {
+		return "<none>"
+	}
+	end := now()
+	if !completed.IsZero() {
+		end = completed.Time
+	}
+	return duration.HumanDuration(end.Sub(started.Time))
+}
@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeJobsAPIServer 模拟 CronJob 和 Job 的列表接口
+func fakeJobsAPIServer(t *testing.T, clock time.Time) *httptest.Server {
+	t.Helper()
+
+	at := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(clock.Add(-ago))
+		return &t
+	}
+	suspend := true
+	completions := int32(3)
+	ownedBy := func(name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: name}}
+	}
+
+	responses := map[string]interface{}{
+		"/apis/batch/v1/namespaces/ops/cronjobs": batchv1.CronJobList{Items: []batchv1.CronJob{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ops"},
+				Spec:       batchv1.CronJobSpec{Schedule: "0 2 * * *"},
+				Status: batchv1.CronJobStatus{
+					Active:             []corev1.ObjectReference{{Kind: "Job", Name: "backup-3"}},
+					LastScheduleTime:   at(5 * time.Hour),
+					LastSuccessfulTime: at(29 * time.Hour),
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "ops"},
+				Spec:       batchv1.CronJobSpec{Schedule: "*/15 * * * *", Suspend: &suspend},
+			},
+		}},
+		"/apis/batch/v1/namespaces/ops/jobs": batchv1.JobList{Items: []batchv1.Job{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-1", Namespace: "ops", OwnerReferences: ownedBy("backup")},
+				Status: batchv1.JobStatus{
+					Succeeded:      1,
+					StartTime:      at(29*time.Hour + 10*time.Minute),
+					CompletionTime: at(29 * time.Hour),
+					Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-2", Namespace: "ops", OwnerReferences: ownedBy("backup")},
+				Status: batchv1.JobStatus{
+					Failed:     6,
+					StartTime:  at(5*time.Hour + 30*time.Minute),
+					Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-3", Namespace: "ops", OwnerReferences: ownedBy("backup")},
+				Status:     batchv1.JobStatus{Active: 1, StartTime: at(2 * time.Minute)},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "ops"},
+				Spec:       batchv1.JobSpec{Completions: &completions},
+				Status:     batchv1.JobStatus{Succeeded: 2, Active: 1, StartTime: at(90 * time.Second)},
+			},
+		}},
+	}
+
+	return fakeAPIServer(t, responses)
+}
+
+// TestListCronJobs 测试 CronJob 的调度、暂停标志、活跃 Job 数和上次调度时间
+func TestListCronJobs(t *testing.T) {
+	clock := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	pinClock(t, clock)
+	ro := newServerOperations(t, fakeJobsAPIServer(t, clock))
+
+	cronJobs, err := ro.ListCronJobs(context.Background(), "ops", "test")
+	if err != nil {
+		t.Fatalf("ListCronJobs failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		suspend      bool
+		active       int
+		lastSchedule string
+		status       string
+	}{
+		{"backup", false, 1, "5h ago", "Schedule: 0 2 * * *, Suspend: false, Active: 1, Last schedule: 5h ago"},
+		{"report", true, 0, "<none>", "Schedule: */15 * * * *, Suspend: true, Active: 0, Last schedule: <none>"},
+	}
+	if len(cronJobs) != len(tests) {
+		t.Fatalf("expected %d cronjobs, got %+v", len(tests), cronJobs)
+	}
+	infos, err := ToResourceInfos(cronJobs)
+	if err != nil {
+		t.Fatalf("ToResourceInfos failed: %v", err)
+	}
+	for i, tt := range tests {
+		cj := cronJobs[i]
+		if cj.Name != tt.name || cj.Suspend != tt.suspend || cj.Active != tt.active || cj.LastSchedule != tt.lastSchedule {
+			t.Errorf("%s: unexpected cronjob %+v", tt.name, cj)
+		}
+		if infos[i].Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", tt.name, infos[i].Status, tt.status)
+		}
+	}
+	if cronJobs[0].LastSuccessfulTime != "2024-05-01T07:00:00Z" {
+		t.Errorf("unexpected last successful time %q", cronJobs[0].LastSuccessfulTime)
+	}
+}
+
+// TestListJobs 测试 Job 的完成数、失败状态和运行时长，以及按 CronJob 过滤
+func TestListJobs(t *testing.T) {
+	clock := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	pinClock(t, clock)
+	ro := newServerOperations(t, fakeJobsAPIServer(t, clock))
+
+	tests := []struct {
+		name    string
+		cronJob string
+		want    []string
+	}{
+		{"all jobs", "", []string{
+			"Complete, Completions: 1/1, Failed: 0, Duration: 10m",
+			"Failed: BackoffLimitExceeded, Completions: 0/1, Failed: 6, Duration: 5h30m",
+			"Running, Completions: 0/1, Failed: 0, Duration: 2m",
+			"Running, Completions: 2/3, Failed: 0, Duration: 90s",
+		}},
+		{"owned by backup", "backup", []string{
+			"Complete, Completions: 1/1, Failed: 0, Duration: 10m",
+			"Failed: BackoffLimitExceeded, Completions: 0/1, Failed: 6, Duration: 5h30m",
+			"Running, Completions: 0/1, Failed: 0, Duration: 2m",
+		}},
+		{"owned by missing cronjob", "report", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := ro.ListJobs(context.Background(), "ops", "test", tt.cronJob)
+			if err != nil {
+				t.Fatalf("ListJobs failed: %v", err)
+			}
+			if len(jobs) != len(tt.want) {
+				t.Fatalf("expected %d jobs, got %+v", len(tt.want), jobs)
+			}
+			infos, err := ToResourceInfos(jobs)
+			if err != nil {
+				t.Fatalf("ToResourceInfos failed: %v", err)
+			}
+			for i, want := range tt.want {
+				if infos[i].Status != want {
+					t.Errorf("%s: status = %q, want %q", jobs[i].Name, infos[i].Status, want)
+				}
+				if tt.cronJob != "" && jobs[i].CronJob != tt.cronJob {
+					t.Errorf("%s: unexpected owner %q", jobs[i].Name, jobs[i].CronJob)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// quotedKeyRex matches a bracketed map key in a dot-path, e.g. ['app.kubernetes.io/name']
+// quotedKeyRex 匹配 dot-path 中用方括号和引号括起的 map 键，例如 ['app.kubernetes.io/name']
+var quotedKeyRex = regexp.MustCompile(`\[\s*(?:'([^']*)'|"([^"]*)")\s*\]`)
+
+// JSONPathTemplate translates an extraction expression to a kubectl JSONPath template.
+// Expressions containing "{" are kubectl templates and are used as is; anything else is
+// a dot-path such as spec.template.spec.containers[0].image, wrapped in braces. In a
+// dot-path, map keys containing dots are written as metadata.labels['app.kubernetes.io/name']
+// or metadata.labels.app\.kubernetes\.io/name.
+// JSONPathTemplate 将提取表达式转换为 kubectl JSONPath 模板。包含 "{" 的表达式视为 kubectl 模板原样使用，
+// 其他表达式视为 dot-path (例如 spec.template.spec.containers[0].image) 并加上花括号。dot-path 中包含点的
+// map 键写作 metadata.labels['app.kubernetes.io/name'] 或 metadata.labels.app\.kubernetes\.io/name
+func JSONPathTemplate(expression string) string {
+	expression = strings.TrimSpace(expression)
+	if strings.Contains(expression, "{") {
+		return expression
+	}
+	// The jsonpath parser splits ['a.b'] on its dots, so quoted keys become escaped fields
+	// jsonpath 解析器会按点拆分 ['a.b']，因此将带引号的键转换为转义后的字段
+	path := quotedKeyRex.ReplaceAllStringFunc(expression, func(match string) string {
+		groups := quotedKeyRex.FindStringSubmatch(match)
+		key := groups[1] + groups[2]
+		return "." + strings.ReplaceAll(key, ".", `\.`)
+	})
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// ExtractJSONPath evaluates a JSONPath template or dot-path (see JSONPathTemplate) against
+// a resource and returns only the extracted values: strings as is, other values as compact
+// JSON, several matches one per line. A template mixing text and actions, such as
+// "{.metadata.name}:{.spec.replicas}", is rendered like kubectl -o jsonpath. A syntax error
+// reports the character where parsing fails; an expression that matches nothing is an error.
+// ExtractJSONPath 在资源上执行 JSONPath 模板或 dot-path (见 JSONPathTemplate)，只返回提取的值：字符串原样返回，
+// 其他值为紧凑的 JSON，多个匹配每行一个。混合文本和表达式的模板 (例如 "{.metadata.name}:{.spec.replicas}")
+// 按 kubectl -o jsonpath 的方式渲染。语法错误时报告解析失败的字符位置；没有匹配时返回错误
+func ExtractJSONPath(resource interface{}, expression string) (string, error) {
+	if strings.TrimSpace(expression) == "" {
+		return "", fmt.Errorf("jsonpath expression is empty")
+	}
+	template := JSONPathTemplate(expression)
+	j := jsonpath.New("jsonpath")
+	if err := j.Parse(template); err != nil {
+		return "", jsonPathSyntaxError(expression, template, err)
+	}
+
+	// Evaluate against the JSON form, so field names match the API rather than the Go types
+	// 在 JSON 形式上求值，使字段名与 API 一致而不是 Go 类型的字段名
+	if obj, ok := resource.(runtime.Object); ok {
+		setTypeMeta(obj)
+	}
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize resource: %w", err)
+	}
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return "", fmt.Errorf("failed to serialize resource: %w", err)
+	}
+
+	// A single action returns its matches one per line; a template is rendered as a whole
+	// 单个表达式每行返回一个匹配，模板整体渲染
+	parsed, _ := jsonpath.Parse("jsonpath", template)
+	if len(parsed.Root.Nodes) != 1 || parsed.Root.Nodes[0].Type() != jsonpath.NodeList {
+		var buf bytes.Buffer
+		if err := j.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("jsonpath %s matched nothing: %w", template, err)
+		}
+		return buf.String(), nil
+	}
+
+	results, err := j.FindResults(data)
+	if err != nil {
+		return "", fmt.Errorf("jsonpath %s matched nothing: %w", template, err)
+	}
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			text, err := jsonPathValueText(value)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, text)
+		}
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("jsonpath %s matched nothing", template)
+	}
+	return strings.Join(values, "\n"), nil
+}
+
+// jsonPathValueText renders one match: strings unquoted, anything else as compact JSON
+// jsonPathValueText 渲染单个匹配：字符串不加引号，其他值为紧凑的 JSON
+func jsonPathValueText(value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "null", nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.String {
+		return value.String(), nil
+	}
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize jsonpath result: %w", err)
+	}
+	return string(data), nil
+}
+
+// jsonPathSyntaxError reports where template fails to parse. The jsonpath parser does
+// not expose its position, so it is found as the longest prefix that still parses once
+// its action is closed.
+// jsonPathSyntaxError 报告模板解析失败的位置。jsonpath 解析器不提供出错位置，因此取补全右花括号后仍能解析的最长前缀
+func jsonPathSyntaxError(expression, template string, err error) error {
+	valid := 0
+	for i := len(template); i > 0; i-- {
+		if _, prefixErr := jsonpath.Parse("jsonpath", template[:i]+"}"); prefixErr == nil {
+			valid = i
+			break
+		}
+	}
+	source := template
+	if source != strings.TrimSpace(expression) {
+		source += " (from " + strings.TrimSpace(expression) + ")"
+	}
+	return fmt.Errorf("invalid jsonpath %s at character %d (%q): %v", source, valid+1, template[valid:], err)
+}
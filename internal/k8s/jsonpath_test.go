@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// jsonPathDeployment 返回带有包含点的标签/注解键和两个容器的 Deployment
+func jsonPathDeployment() *appsv1.Deployment {
+	replicas := int32(3)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "payments",
+			Namespace:   "shop",
+			Labels:      map[string]string{"app.kubernetes.io/name": "payments", "tier": "backend"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "7"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "registry.local/payments:2.4.1"},
+						{Name: "proxy", Image: "envoyproxy/envoy:v1.29"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestExtractJSONPath 测试 dot-path 和 kubectl 模板对数组、包含点的 map 键、非字符串值和混合文本模板的提取
+func TestExtractJSONPath(t *testing.T) {
+	for expression, want := range map[string]string{
+		"spec.template.spec.containers[0].image":                      "registry.local/payments:2.4.1",
+		".spec.template.spec.containers[-1].name":                     "proxy",
+		"spec.template.spec.containers[*].image":                      "registry.local/payments:2.4.1\nenvoyproxy/envoy:v1.29",
+		"{.spec.template.spec.containers[*].name}":                    "app\nproxy",
+		"{.spec.template.spec.containers[?(@.name=='proxy')].image}":  "envoyproxy/envoy:v1.29",
+		"metadata.labels['app.kubernetes.io/name']":                   "payments",
+		`metadata.annotations["deployment.kubernetes.io/revision"]`:   "7",
+		`metadata.labels.app\.kubernetes\.io/name`:                    "payments",
+		`{.metadata.annotations.deployment\.kubernetes\.io/revision}`: "7",
+		"metadata.labels":                   `{"app.kubernetes.io/name":"payments","tier":"backend"}`,
+		"spec.replicas":                     "3",
+		"kind":                              "Deployment",
+		"{.metadata.name}:{.spec.replicas}": "payments:3",
+		"$.metadata.namespace":              "shop",
+		"{range .spec.template.spec.containers[*]}{.name}={.image};{end}": "app=registry.local/payments:2.4.1;proxy=envoyproxy/envoy:v1.29;",
+	} {
+		got, err := ExtractJSONPath(jsonPathDeployment(), expression)
+		if err != nil {
+			t.Errorf("ExtractJSONPath(%q) failed: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ExtractJSONPath(%q) = %q, want %q", expression, got, want)
+		}
+	}
+}
+
+// TestExtractJSONPathErrors 测试路径不存在、没有匹配以及语法错误 (包含出错位置) 时的错误信息
+func TestExtractJSONPathErrors(t *testing.T) {
+	for expression, want := range map[string]string{
+		"spec.missing":                           "jsonpath {.spec.missing} matched nothing: missing is not found",
+		"metadata.labels['app.kubernetes.io/x']": `matched nothing: app.kubernetes.io/x is not found`,
+		"spec.template.spec.volumes[*]":          "matched nothing",
+		"{.metadata.name}-{.spec.paused}":        "matched nothing: paused is not found",
+		"{.spec.template.spec.containers[abc]}":  `invalid jsonpath {.spec.template.spec.containers[abc]} at character 32 ("[abc]}"): invalid array index abc`,
+		"spec.containers[0":                      `invalid jsonpath {.spec.containers[0} (from spec.containers[0) at character 18 ("[0}"): unterminated array`,
+		"{.metadata.name":                        "at character 16",
+		"  ":                                     "jsonpath expression is empty",
+	} {
+		_, err := ExtractJSONPath(jsonPathDeployment(), expression)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ExtractJSONPath(%q) error = %v, want it to contain %q", expression, err, want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeConfigPaths returns the kubeconfig files to load, in merge order: the entries of
+// configPath, a list separated like $KUBECONFIG (":" on Unix, ";" on Windows), then every
+// *.yaml and *.yml file of configDir in name order. With neither set, the precedence of
+// kubectl applies: the entries of $KUBECONFIG, or ~/.kube/config when it is unset or empty.
+// KubeConfigPaths 按合并顺序返回要加载的 kubeconfig 文件：先是 configPath 中的各项 (与 $KUBECONFIG 相同的分隔方式，
+// Unix 上为 ":"，Windows 上为 ";")，然后是 configDir 中按名称排序的所有 *.yaml 和 *.yml 文件。两者都未设置时与 kubectl
+// 的优先级相同：使用 $KUBECONFIG 中的各项，$KUBECONFIG 未设置或为空时使用 ~/.kube/config
+func KubeConfigPaths(configPath, configDir string) ([]string, error) {
+	if configPath == "" && configDir == "" {
+		configPath = strings.Join(clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence(), string(filepath.ListSeparator))
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	for _, path := range filepath.SplitList(configPath) {
+		add(path)
+	}
+
+	if configDir != "" {
+		entries, err := os.ReadDir(configDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kubeconfig directory: %w", err)
+		}
+		var files []string
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.Type().IsRegular() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(configDir, entry.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no kubeconfig files (*.yaml, *.yml) in %s", configDir)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			add(file)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no kubeconfig file found")
+	}
+	return paths, nil
+}
+
+// kubeConfigSource describes where the files of KubeConfigPaths(configPath, configDir)
+// come from, for the startup log
+// kubeConfigSource 描述 KubeConfigPaths(configPath, configDir) 返回的文件的来源，用于启动日志
+func kubeConfigSource(configPath, configDir string) string {
+	switch {
+	case configPath != "" && configDir != "":
+		return "kubeconfig flag and directory"
+	case configPath != "":
+		return "kubeconfig flag"
+	case configDir != "":
+		return "kubeconfig directory"
+	case len(filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar))) > 0:
+		return "$" + clientcmd.RecommendedConfigPathEnvVar
+	default:
+		return "default ~/.kube/config"
+	}
+}
+
+// loadKubeConfigFiles loads and merges kubeconfig files. As with clientcmd, the first
+// non-empty current-context wins and relative paths are resolved against the file that
+// contains them. Unlike clientcmd, a cluster, context or user defined differently in two
+// files is an error naming both files rather than the later one being silently ignored;
+// identical definitions are allowed. Every entry's LocationOfOrigin is the file it came from.
+// loadKubeConfigFiles 加载并合并 kubeconfig 文件。与 clientcmd 相同，第一个非空的 current-context 生效，相对路径相对于
+// 所在文件解析。与 clientcmd 不同的是，两个文件中定义不同的同名集群、上下文或用户会返回列出两个文件的错误，而不是静默忽略后者；
+// 完全相同的定义是允许的。每个条目的 LocationOfOrigin 为其来源文件
+func loadKubeConfigFiles(paths []string) (*clientcmdapi.Config, error) {
+	merged := clientcmdapi.NewConfig()
+	var collisions []error
+	for _, path := range paths {
+		config, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig %s: %w", path, err)
+		}
+		if err := clientcmd.ResolveLocalPaths(config); err != nil {
+			return nil, fmt.Errorf("failed to resolve paths in kubeconfig %s: %w", path, err)
+		}
+
+		collisions = append(collisions, mergeKubeConfigEntries("cluster", merged.Clusters, config.Clusters, func(c *clientcmdapi.Cluster) *string { return &c.LocationOfOrigin })...)
+		collisions = append(collisions, mergeKubeConfigEntries("user", merged.AuthInfos, config.AuthInfos, func(a *clientcmdapi.AuthInfo) *string { return &a.LocationOfOrigin })...)
+		collisions = append(collisions, mergeKubeConfigEntries("context", merged.Contexts, config.Contexts, func(c *clientcmdapi.Context) *string { return &c.LocationOfOrigin })...)
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = config.CurrentContext
+		}
+	}
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("conflicting kubeconfig files: %w", errors.Join(collisions...))
+	}
+	return merged, nil
+}
+
+// mergeKubeConfigEntries adds the entries of one file to the merged map, returning an
+// error for every name already defined differently by an earlier file
+// mergeKubeConfigEntries 将一个文件的条目加入合并后的 map，对前面的文件中已有不同定义的每个名称返回错误
+func mergeKubeConfigEntries[T any](kind string, merged, entries map[string]*T, origin func(*T) *string) []error {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var collisions []error
+	for _, name := range names {
+		entry := entries[name]
+		existing, ok := merged[name]
+		if !ok {
+			merged[name] = entry
+			continue
+		}
+		if !sameKubeConfigEntry(existing, entry, origin) {
+			collisions = append(collisions, fmt.Errorf("%s %q is defined in both %s and %s", kind, name, *origin(existing), *origin(entry)))
+		}
+	}
+	return collisions
+}
+
+// sameKubeConfigEntry compares two entries ignoring the file they came from
+// sameKubeConfigEntry 比较两个条目，忽略其来源文件
+func sameKubeConfigEntry[T any](a, b *T, origin func(*T) *string) bool {
+	left, right := *a, *b
+	*origin(&left), *origin(&right) = "", ""
+	return reflect.DeepEqual(left, right)
+}
+
+// ClusterSource returns the kubeconfig file the cluster's context was loaded from, or ""
+// for clusters that did not come from a kubeconfig
+// ClusterSource 返回集群的上下文所在的 kubeconfig 文件，不是来自 kubeconfig 的集群返回 ""
+func (cm *ClusterManager) ClusterSource(clusterName string) string {
+	return cm.sources[clusterName]
+}
+
+// kubeConfigRedacted replaces the credentials of an exported kubeconfig
+// kubeConfigRedacted 用于替换导出的 kubeconfig 中的凭据
+const kubeConfigRedacted = "REDACTED"
+
+// secretFlagName matches the names of exec plugin flags whose values are secrets, e.g.
+// --oidc-client-secret or --token
+// secretFlagName 匹配值为敏感信息的 exec 插件参数名，例如 --oidc-client-secret 或 --token
+var secretFlagName = regexp.MustCompile(`(?i)secret|token|password|passwd|key|credential`)
+
+// minimalKubeConfig returns a copy of config with only the given context and the
+// cluster and user it refers to, with the context as current-context
+// minimalKubeConfig 返回 config 的副本，只包含指定的上下文及其引用的集群和用户，并将该上下文设为 current-context
+func minimalKubeConfig(config *clientcmdapi.Config, contextName string) *clientcmdapi.Config {
+	context := config.Contexts[contextName].DeepCopy()
+	minimal := clientcmdapi.NewConfig()
+	minimal.CurrentContext = contextName
+	minimal.Contexts[contextName] = context
+	if cluster, ok := config.Clusters[context.Cluster]; ok {
+		minimal.Clusters[context.Cluster] = cluster.DeepCopy()
+	}
+	if user, ok := config.AuthInfos[context.AuthInfo]; ok {
+		minimal.AuthInfos[context.AuthInfo] = user.DeepCopy()
+	}
+	return minimal
+}
+
+// KubeConfigContexts returns the names of the kubeconfig contexts that were loaded, sorted
+// KubeConfigContexts 返回已加载的 kubeconfig 上下文名称，按名称排序
+func (cm *ClusterManager) KubeConfigContexts() []string {
+	names := make([]string, 0, len(cm.kubeContexts))
+	for name := range cm.kubeContexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClusterContext returns the kubeconfig context a cluster was loaded from (the current
+// context when several point at it), or "" for clusters that did not come from a kubeconfig
+// ClusterContext 返回集群加载自的 kubeconfig 上下文 (多个上下文指向同一集群时为当前上下文)，不是来自 kubeconfig 的集群返回 ""
+func (cm *ClusterManager) ClusterContext(clusterName string) string {
+	return cm.clusterContexts[clusterName]
+}
+
+// ExportKubeConfig returns a standalone kubeconfig with only the given context and its
+// cluster and user. Files the entries refer to (CA, client certificate and key, token
+// file) are embedded so the result works on another machine. With redact, the token,
+// password, client key, auth provider settings and exec environment values are replaced
+// with placeholders, leaving a connection template rather than a credential.
+// ExportKubeConfig 返回只包含指定上下文及其集群和用户的独立 kubeconfig。条目引用的文件 (CA、客户端证书和私钥、token 文件)
+// 会被嵌入，使结果可以在其他机器上使用。redact 为 true 时，token、密码、客户端私钥、auth provider 配置和 exec 环境变量的值
+// 被替换为占位符，结果只是连接模板而不是凭据
+func (cm *ClusterManager) ExportKubeConfig(contextName string, redact bool) ([]byte, error) {
+	stored, ok := cm.kubeContexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("context %q was not loaded from a kubeconfig", contextName)
+	}
+	config := stored.DeepCopy()
+
+	for name, cluster := range config.Clusters {
+		if err := clientcmdapi.FlattenContent(&cluster.CertificateAuthority, &cluster.CertificateAuthorityData, ""); err != nil {
+			return nil, fmt.Errorf("failed to embed the certificate authority of cluster %s: %w", name, err)
+		}
+	}
+	for name, user := range config.AuthInfos {
+		if redact {
+			redactAuthInfo(user)
+		}
+		if err := clientcmdapi.FlattenContent(&user.ClientCertificate, &user.ClientCertificateData, ""); err != nil {
+			return nil, fmt.Errorf("failed to embed the client certificate of user %s: %w", name, err)
+		}
+		if redact {
+			continue
+		}
+		if err := clientcmdapi.FlattenContent(&user.ClientKey, &user.ClientKeyData, ""); err != nil {
+			return nil, fmt.Errorf("failed to embed the client key of user %s: %w", name, err)
+		}
+		if user.TokenFile != "" {
+			token, err := os.ReadFile(user.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed the token of user %s: %w", name, err)
+			}
+			user.Token, user.TokenFile = strings.TrimSpace(string(token)), ""
+		}
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kubeconfig: %w", err)
+	}
+	return data, nil
+}
+
+// redactAuthInfo replaces the secrets of a kubeconfig user with placeholders, keeping
+// the fields that were set so the user knows what to fill in
+// redactAuthInfo 将 kubeconfig 用户中的敏感信息替换为占位符，保留已设置的字段以便使用者知道需要填写什么
+func redactAuthInfo(user *clientcmdapi.AuthInfo) {
+	if user.Token != "" || user.TokenFile != "" {
+		user.Token, user.TokenFile = kubeConfigRedacted, ""
+	}
+	if len(user.ClientKeyData) > 0 || user.ClientKey != "" {
+		user.ClientKey, user.ClientKeyData = kubeConfigRedacted, nil
+	}
+	if user.Password != "" {
+		user.Password = kubeConfigRedacted
+	}
+	if user.AuthProvider != nil {
+		for key := range user.AuthProvider.Config {
+			user.AuthProvider.Config[key] = kubeConfigRedacted
+		}
+	}
+	if user.Exec != nil {
+		for i := range user.Exec.Env {
+			user.Exec.Env[i].Value = kubeConfigRedacted
+		}
+		redactExecArgs(user.Exec.Args)
+	}
+}
+
+// redactExecArgs replaces the values of secret-looking flags in the arguments of an exec
+// plugin, both --flag=value and --flag value
+// redactExecArgs 替换 exec 插件参数中看起来是敏感信息的参数值，包括 --flag=value 和 --flag value 两种形式
+func redactExecArgs(args []string) {
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(args[i], "=")
+		if !secretFlagName.MatchString(strings.TrimLeft(name, "-")) {
+			continue
+		}
+		if hasValue {
+			args[i] = name + "=" + kubeConfigRedacted
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			args[i] = kubeConfigRedacted
+		}
+	}
+}
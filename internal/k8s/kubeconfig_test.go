@@ -0,0 +1,315 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// kubeConfigFor 返回只包含一个集群、上下文和用户的 kubeconfig，各名称均为 name
+func kubeConfigFor(name, server, caFile string) string {
+	config := `apiVersion: v1
+kind: Config
+current-context: ` + name + `
+clusters:
+- name: ` + name + `
+  cluster:
+    server: ` + server + `
+`
+	if caFile != "" {
+		config += "    certificate-authority: " + caFile + "\n"
+	}
+	return config + `contexts:
+- name: ` + name + `
+  context:
+    cluster: ` + name + `
+    user: admin
+    namespace: ` + name + `-apps
+users:
+- name: admin
+  user:
+    token: shared
+`
+}
+
+// writeKubeConfigs 在 dir 中写入 files (文件名 -> 内容)
+func writeKubeConfigs(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+// TestKubeConfigPaths 测试冒号分隔的列表、目录中的 *.yaml/*.yml 文件 (按名称排序并去重) 以及默认使用 $KUBECONFIG
+func TestKubeConfigPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeConfigs(t, dir, map[string]string{"b.yaml": "", "a.yml": "", "notes.txt": "", "C.YAML": ""})
+	if err := os.Mkdir(filepath.Join(dir, "nested.yaml"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	list := "/etc/kube/main" + string(os.PathListSeparator) + filepath.Join(dir, "b.yaml")
+	paths, err := KubeConfigPaths(list, dir)
+	if err != nil {
+		t.Fatalf("KubeConfigPaths failed: %v", err)
+	}
+	want := []string{"/etc/kube/main", filepath.Join(dir, "b.yaml"), filepath.Join(dir, "C.YAML"), filepath.Join(dir, "a.yml")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("KubeConfigPaths = %v, want %v", paths, want)
+	}
+
+	if _, err := KubeConfigPaths("", t.TempDir()); err == nil || !strings.Contains(err.Error(), "no kubeconfig files") {
+		t.Errorf("expected an error for a directory without kubeconfig files, got %v", err)
+	}
+
+	t.Setenv("KUBECONFIG", "/a"+string(os.PathListSeparator)+"/b")
+	if paths, err := KubeConfigPaths("", ""); err != nil || !reflect.DeepEqual(paths, []string{"/a", "/b"}) {
+		t.Errorf("expected $KUBECONFIG to be used by default, got %v %v", paths, err)
+	}
+}
+
+// TestLoadKubeConfigDir 测试从目录合并名称互不相同的文件、相同的共享用户、相对路径解析以及每个集群的来源文件
+func TestLoadKubeConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeConfigs(t, dir, map[string]string{
+		"prod.yaml":    kubeConfigFor("prod", "https://127.0.0.1:1", "certs/prod-ca.crt"),
+		"staging.yaml": kubeConfigFor("staging", "https://127.0.0.1:2", ""),
+	})
+	if err := os.Mkdir(filepath.Join(dir, "certs"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeKubeConfigs(t, filepath.Join(dir, "certs"), map[string]string{"prod-ca.crt": "ca"})
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigs("", dir); err != nil {
+		t.Fatalf("LoadKubeConfigs failed: %v", err)
+	}
+	if clusters := cm.GetClusters(); len(clusters) != 2 {
+		t.Fatalf("expected both clusters, got %v", clusters)
+	}
+	for cluster, file := range map[string]string{"prod": "prod.yaml", "staging": "staging.yaml"} {
+		if got := cm.ClusterSource(cluster); got != filepath.Join(dir, file) {
+			t.Errorf("ClusterSource(%s) = %q, want %q", cluster, got, filepath.Join(dir, file))
+		}
+		if got := cm.GetDefaultNamespace(cluster); got != cluster+"-apps" {
+			t.Errorf("GetDefaultNamespace(%s) = %q", cluster, got)
+		}
+	}
+	// 相对路径相对于所在文件解析
+	if got := cm.configs["prod"].TLSClientConfig.CAFile; got != filepath.Join(dir, "certs", "prod-ca.crt") {
+		t.Errorf("expected the CA path to be resolved against the file, got %q", got)
+	}
+
+	// 冒号分隔的列表与目录加载相同的集群
+	list := filepath.Join(dir, "staging.yaml") + string(os.PathListSeparator) + filepath.Join(dir, "prod.yaml")
+	cm = NewClusterManager(nil)
+	if err := cm.LoadKubeConfigAndInitCluster(list); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+	if len(cm.GetClusters()) != 2 || cm.ClusterSource("staging") != filepath.Join(dir, "staging.yaml") {
+		t.Errorf("unexpected clusters %v from %s", cm.GetClusters(), list)
+	}
+}
+
+// TestLoadKubeConfigFromEnv 测试未指定路径时加载 $KUBECONFIG 中的文件 (包括合并多个文件) 而不是 ~/.kube/config，
+// 以及日志中的来源描述
+func TestLoadKubeConfigFromEnv(t *testing.T) {
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, ".kube"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeKubeConfigs(t, filepath.Join(home, ".kube"), map[string]string{"config": kubeConfigFor("home", "https://127.0.0.1:9", "")})
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	writeKubeConfigs(t, dir, map[string]string{
+		"dev.yaml":  kubeConfigFor("dev", "https://127.0.0.1:1", ""),
+		"test.yaml": kubeConfigFor("test", "https://127.0.0.1:2", ""),
+	})
+
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "dev.yaml"))
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigs("", ""); err != nil {
+		t.Fatalf("LoadKubeConfigs failed: %v", err)
+	}
+	if contexts := cm.KubeConfigContexts(); !reflect.DeepEqual(contexts, []string{"dev"}) {
+		t.Errorf("expected the contexts of $KUBECONFIG only, got %v", contexts)
+	}
+	if source := kubeConfigSource("", ""); source != "$KUBECONFIG" {
+		t.Errorf("unexpected source %q", source)
+	}
+
+	// 多个文件合并，每个集群记录其来源文件
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "test.yaml")+string(os.PathListSeparator)+filepath.Join(dir, "dev.yaml"))
+	cm = NewClusterManager(nil)
+	if err := cm.LoadKubeConfigs("", ""); err != nil {
+		t.Fatalf("LoadKubeConfigs failed: %v", err)
+	}
+	if contexts := cm.KubeConfigContexts(); !reflect.DeepEqual(contexts, []string{"dev", "test"}) {
+		t.Errorf("expected the contexts of both files, got %v", contexts)
+	}
+	if source := cm.ClusterSource("test"); source != filepath.Join(dir, "test.yaml") {
+		t.Errorf("unexpected source of test: %q", source)
+	}
+
+	// 显式路径优先于 $KUBECONFIG
+	cm = NewClusterManager(nil)
+	if err := cm.LoadKubeConfigAndInitCluster(filepath.Join(home, ".kube", "config")); err != nil {
+		t.Fatalf("LoadKubeConfigAndInitCluster failed: %v", err)
+	}
+	if contexts := cm.KubeConfigContexts(); !reflect.DeepEqual(contexts, []string{"home"}) {
+		t.Errorf("expected the explicit file to win over $KUBECONFIG, got %v", contexts)
+	}
+	if source := kubeConfigSource("config", ""); source != "kubeconfig flag" {
+		t.Errorf("unexpected source %q", source)
+	}
+
+	t.Setenv("KUBECONFIG", "")
+	if source := kubeConfigSource("", ""); source != "default ~/.kube/config" {
+		t.Errorf("unexpected source %q", source)
+	}
+}
+
+// TestLoadKubeConfigCollisions 测试两个文件中定义不同的同名条目返回列出两个文件的错误，而不是静默覆盖
+func TestLoadKubeConfigCollisions(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeConfigs(t, dir, map[string]string{
+		"k3s.yaml":  kubeConfigFor("default", "https://127.0.0.1:6443", ""),
+		"kind.yaml": strings.ReplaceAll(kubeConfigFor("default", "https://127.0.0.1:7443", ""), "token: shared", "token: other"),
+		"prod.yaml": kubeConfigFor("prod", "https://127.0.0.1:1", ""),
+	})
+
+	cm := NewClusterManager(nil)
+	err := cm.LoadKubeConfigs("", dir)
+	if err == nil {
+		t.Fatalf("expected an error for conflicting files")
+	}
+	k3s, kind := filepath.Join(dir, "k3s.yaml"), filepath.Join(dir, "kind.yaml")
+	for _, want := range []string{
+		`cluster "default" is defined in both ` + k3s + " and " + kind,
+		`user "admin" is defined in both ` + k3s + " and " + kind,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error:\n%v", want, err)
+		}
+	}
+	// 上下文 default 在两个文件中的定义相同，不算冲突
+	if strings.Contains(err.Error(), `context "default"`) {
+		t.Errorf("identical contexts must not be reported:\n%v", err)
+	}
+	if len(cm.GetClusters()) != 0 {
+		t.Errorf("expected nothing to be loaded, got %v", cm.GetClusters())
+	}
+}
+
+// TestExportKubeConfig 测试导出的 kubeconfig 只包含指定上下文，嵌入引用的文件，且脱敏时不包含任何凭据
+func TestExportKubeConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeConfigs(t, dir, map[string]string{
+		"prod.yaml": strings.ReplaceAll(kubeConfigFor("prod", "https://127.0.0.1:1", "ca.crt"), "token: shared", "token: s3cr3t-token"),
+		"staging.yaml": `apiVersion: v1
+kind: Config
+clusters:
+- name: staging
+  cluster:
+    server: https://127.0.0.1:2
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: deployer
+users:
+- name: deployer
+  user:
+    client-certificate: client.crt
+    client-key: client.key
+    password: hunter2
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: get-token
+      args:
+      - oidc-login
+      - --oidc-issuer-url=https://issuer.example.com
+      - --oidc-client-secret=oidc-secret
+      - --token
+      - flag-token
+      interactiveMode: Never
+      env:
+      - name: API_KEY
+        value: exec-secret
+`,
+		"ca.crt":     "ca-content",
+		"client.crt": "cert-content",
+		"client.key": "key-content",
+	})
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadKubeConfigs(filepath.Join(dir, "prod.yaml")+string(os.PathListSeparator)+filepath.Join(dir, "staging.yaml"), ""); err != nil {
+		t.Fatalf("LoadKubeConfigs failed: %v", err)
+	}
+	if contexts := cm.KubeConfigContexts(); !reflect.DeepEqual(contexts, []string{"prod", "staging"}) || cm.ClusterContext("staging") != "staging" {
+		t.Fatalf("unexpected contexts %v", contexts)
+	}
+
+	secrets := []string{"s3cr3t-token", "hunter2", "exec-secret", "oidc-secret", "flag-token", "key-content", base64.StdEncoding.EncodeToString([]byte("key-content"))}
+	for _, contextName := range []string{"prod", "staging"} {
+		data, err := cm.ExportKubeConfig(contextName, true)
+		if err != nil {
+			t.Fatalf("ExportKubeConfig(%s) failed: %v", contextName, err)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("redacted kubeconfig of %s contains %q:\n%s", contextName, secret, data)
+			}
+		}
+		if !strings.Contains(string(data), "REDACTED") || !strings.Contains(string(data), "current-context: "+contextName) {
+			t.Errorf("expected placeholders and the current context in:\n%s", data)
+		}
+		if strings.Contains(string(data), dir) {
+			t.Errorf("expected the files to be embedded, got paths in:\n%s", data)
+		}
+	}
+
+	// exec 参数中只有敏感参数的值被替换
+	data, err := cm.ExportKubeConfig("staging", true)
+	if err != nil {
+		t.Fatalf("ExportKubeConfig failed: %v", err)
+	}
+	for _, want := range []string{"oidc-login", "--oidc-issuer-url=https://issuer.example.com", "--oidc-client-secret=REDACTED", "--token\n      - REDACTED"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the redacted kubeconfig:\n%s", want, data)
+		}
+	}
+
+	// 只包含指定的上下文、集群和用户，CA 被嵌入
+	data, err = cm.ExportKubeConfig("prod", true)
+	if err != nil {
+		t.Fatalf("ExportKubeConfig failed: %v", err)
+	}
+	if strings.Contains(string(data), "staging") || strings.Contains(string(data), "deployer") {
+		t.Errorf("expected only the prod context, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), base64.StdEncoding.EncodeToString([]byte("ca-content"))) {
+		t.Errorf("expected the CA to be embedded, got:\n%s", data)
+	}
+
+	// 不脱敏时导出凭据，客户端私钥被嵌入
+	data, err = cm.ExportKubeConfig("staging", false)
+	if err != nil {
+		t.Fatalf("ExportKubeConfig(redact=false) failed: %v", err)
+	}
+	for _, want := range []string{"hunter2", "exec-secret", "oidc-secret", "flag-token", base64.StdEncoding.EncodeToString([]byte("key-content"))} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the unredacted kubeconfig:\n%s", want, data)
+		}
+	}
+
+	if _, err := cm.ExportKubeConfig("missing", true); err == nil {
+		t.Errorf("expected an unknown context to fail")
+	}
+}
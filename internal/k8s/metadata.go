@@ -0,0 +1,262 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Metadata fields changed by PatchMetadata
+// PatchMetadata 修改的元数据字段
+const (
+	MetadataLabels      = "labels"
+	MetadataAnnotations = "annotations"
+)
+
+// metadataClient gets, patches and deletes the objects of one resource type in one namespace
+// metadataClient 获取、修补和删除某个命名空间中某种资源类型的对象
+type metadataClient interface {
+	get(ctx context.Context, name string) (metav1.Object, error)
+	patch(ctx context.Context, name string, pt k8stypes.PatchType, data []byte) (metav1.Object, error)
+	delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// typedClient is the part of every typed client-go resource interface used by metadataClient
+// typedClient 是 metadataClient 用到的 client-go 类型化资源接口的公共部分
+type typedClient[T metav1.Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Patch(ctx context.Context, name string, pt k8stypes.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// typedMetadataClient adapts a typed client to metadataClient
+// typedMetadataClient 将类型化客户端适配为 metadataClient
+type typedMetadataClient[T metav1.Object] struct {
+	client typedClient[T]
+}
+
+func (c typedMetadataClient[T]) get(ctx context.Context, name string) (metav1.Object, error) {
+	return c.client.Get(ctx, name, metav1.GetOptions{})
+}
+
+func (c typedMetadataClient[T]) patch(ctx context.Context, name string, pt k8stypes.PatchType, data []byte) (metav1.Object, error) {
+	return c.client.Patch(ctx, name, pt, data, metav1.PatchOptions{})
+}
+
+func (c typedMetadataClient[T]) delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete(ctx, name, opts)
+}
+
+// metadataClientFactory returns the metadataClient of a resource type in a namespace
+// metadataClientFactory 返回某种资源类型在命名空间中的 metadataClient
+type metadataClientFactory func(client kubernetes.Interface, namespace string) metadataClient
+
+// newMetadataClient wraps a typed client; the type parameter is inferred from it
+// newMetadataClient 包装类型化客户端，类型参数由其推断
+func newMetadataClient[T metav1.Object](client typedClient[T]) metadataClient {
+	return typedMetadataClient[T]{client: client}
+}
+
+// metadataClients maps the resource types whose metadata can be patched, singular and plural
+// metadataClients 列出可以修补元数据的资源类型（单数和复数形式）
+var metadataClients = func() map[ResourceType]metadataClientFactory {
+	factories := map[ResourceType]metadataClientFactory{}
+	register := func(plural, singular ResourceType, factory metadataClientFactory) {
+		factories[plural] = factory
+		factories[singular] = factory
+	}
+	register(ResourceTypePods, ResourceTypePod, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().Pods(ns))
+	})
+	register(ResourceTypeServices, ResourceTypeService, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().Services(ns))
+	})
+	register(ResourceTypeConfigMaps, ResourceTypeConfigMap, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().ConfigMaps(ns))
+	})
+	register(ResourceTypeSecrets, ResourceTypeSecret, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().Secrets(ns))
+	})
+	register(ResourceTypeNamespaces, ResourceTypeNamespace, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().Namespaces())
+	})
+	register(ResourceTypeNodes, ResourceTypeNode, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().Nodes())
+	})
+	register(ResourceTypePersistentVolumes, ResourceTypePersistentVolume, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().PersistentVolumes())
+	})
+	register(ResourceTypePersistentVolumeClaims, ResourceTypePersistentVolumeClaim, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.CoreV1().PersistentVolumeClaims(ns))
+	})
+	register(ResourceTypeDeployments, ResourceTypeDeployment, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.AppsV1().Deployments(ns))
+	})
+	register(ResourceTypeStatefulSets, ResourceTypeStatefulSet, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.AppsV1().StatefulSets(ns))
+	})
+	register(ResourceTypeDaemonSets, ResourceTypeDaemonSet, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.AppsV1().DaemonSets(ns))
+	})
+	register(ResourceTypeIngresses, ResourceTypeIngress, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.NetworkingV1().Ingresses(ns))
+	})
+	register(ResourceTypeNetworkPolicies, ResourceTypeNetworkPolicy, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.NetworkingV1().NetworkPolicies(ns))
+	})
+	register(ResourceTypeHorizontalPodAutoscalers, ResourceTypeHorizontalPodAutoscaler, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.AutoscalingV2().HorizontalPodAutoscalers(ns))
+	})
+	register(ResourceTypePodDisruptionBudgets, ResourceTypePodDisruptionBudget, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.PolicyV1().PodDisruptionBudgets(ns))
+	})
+	register(ResourceTypeCronJobs, ResourceTypeCronJob, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.BatchV1().CronJobs(ns))
+	})
+	register(ResourceTypeJobs, ResourceTypeJob, func(c kubernetes.Interface, ns string) metadataClient {
+		return newMetadataClient(c.BatchV1().Jobs(ns))
+	})
+	return factories
+}()
+
+// PatchMetadata sets or removes labels or annotations (field is MetadataLabels or
+// MetadataAnnotations) on an object with a JSON merge patch, like 'kubectl label' and
+// 'kubectl annotate'. A nil value removes the key. Without overwrite, changing the value
+// of an existing key is refused. Keys that already have the wanted value and removals of
+// missing keys are skipped; if nothing is left the object is not patched.
+// PatchMetadata 使用 JSON merge patch 设置或删除对象的标签或注解（field 为 MetadataLabels 或
+// MetadataAnnotations），与 'kubectl label' 和 'kubectl annotate' 相同。值为 nil 表示删除该键。
+// 未设置 overwrite 时拒绝修改已有键的值。已是目标值的键和不存在的键的删除会被跳过，没有剩余变更时不修补对象。
+func (ro *ResourceOperations) PatchMetadata(ctx context.Context, resourceType ResourceType, namespace, name, field string, changes map[string]*string, overwrite bool, clusterName string) (*types.MetadataResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no %s to change", field)
+	}
+	if err := validateMetadataChanges(field, changes); err != nil {
+		return nil, err
+	}
+	factory, ok := metadataClients[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type for %s: %s", field, resourceType)
+	}
+	if IsClusterScoped(resourceType) {
+		namespace = ""
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := factory(client, namespace)
+	obj, err := objects.get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", resourceType, name, err)
+	}
+	before := metadataField(obj, field)
+
+	patch, err := metadataPatch(field, before, changes, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.MetadataResult{
+		ResourceType: string(resourceType),
+		Namespace:    namespace,
+		Name:         name,
+		Field:        field,
+		Before:       before,
+		After:        before,
+	}
+	if patch == nil {
+		return result, nil
+	}
+
+	obj, err = objects.patch(ctx, name, k8stypes.MergePatchType, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch %s of %s %s: %w", field, resourceType, name, err)
+	}
+	result.After = metadataField(obj, field)
+	result.Changed = true
+	return result, nil
+}
+
+// validateMetadataChanges checks the keys, and for labels the values, like the API server
+// validateMetadataChanges 与 API server 一样检查键，以及标签的值
+func validateMetadataChanges(field string, changes map[string]*string) error {
+	for key, value := range changes {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid %s key %q: %s", strings.TrimSuffix(field, "s"), key, strings.Join(errs, "; "))
+		}
+		if field != MetadataLabels || value == nil {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(*value); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q for label %q: %s", *value, key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// metadataField returns the labels or annotations of obj, never nil
+// metadataField 返回 obj 的标签或注解，不会返回 nil
+func metadataField(obj metav1.Object, field string) map[string]string {
+	values := obj.GetAnnotations()
+	if field == MetadataLabels {
+		values = obj.GetLabels()
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	return values
+}
+
+// metadataPatch builds the merge patch applying changes to current; nil means nothing
+// changes. Conflicting keys are all reported, in order.
+// metadataPatch 构造将 changes 应用到 current 的 merge patch，nil 表示没有变更；所有冲突的键按顺序一并报告。
+func metadataPatch(field string, current map[string]string, changes map[string]*string, overwrite bool) ([]byte, error) {
+	values := map[string]interface{}{}
+	var conflicts []string
+	for key, value := range changes {
+		existing, exists := current[key]
+		switch {
+		case value == nil:
+			if exists {
+				values[key] = nil
+			}
+		case !exists:
+			values[key] = *value
+		case existing == *value:
+		case !overwrite:
+			conflicts = append(conflicts, fmt.Sprintf("%s=%s", key, existing))
+		default:
+			values[key] = *value
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("%s already set: %s; pass overwrite=true to change them", field, strings.Join(conflicts, ", "))
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{field: values},
+	})
+}
@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// strPtr 返回指向 s 的指针，用于表示要设置的值
+func strPtr(s string) *string {
+	return &s
+}
+
+// TestPatchMetadata 测试添加、修改和删除标签及注解时发送给 API server 的 merge patch
+func TestPatchMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		changes    map[string]*string
+		overwrite  bool
+		wantPatch  string
+		wantAfter  map[string]string
+		wantErr    string
+		wantChange bool
+	}{
+		{
+			name:       "add label",
+			field:      MetadataLabels,
+			changes:    map[string]*string{"tier": strPtr("frontend")},
+			wantPatch:  `{"metadata":{"labels":{"tier":"frontend"}}}`,
+			wantAfter:  map[string]string{"app": "web", "env": "prod", "tier": "frontend"},
+			wantChange: true,
+		},
+		{
+			name:       "change label with overwrite",
+			field:      MetadataLabels,
+			changes:    map[string]*string{"env": strPtr("staging")},
+			overwrite:  true,
+			wantPatch:  `{"metadata":{"labels":{"env":"staging"}}}`,
+			wantAfter:  map[string]string{"app": "web", "env": "staging"},
+			wantChange: true,
+		},
+		{
+			name:       "remove label and skip missing key",
+			field:      MetadataLabels,
+			changes:    map[string]*string{"env": nil, "missing": nil},
+			wantPatch:  `{"metadata":{"labels":{"env":null}}}`,
+			wantAfter:  map[string]string{"app": "web"},
+			wantChange: true,
+		},
+		{
+			name:       "several changes in one patch",
+			field:      MetadataLabels,
+			changes:    map[string]*string{"env": nil, "tier": strPtr("frontend"), "app": strPtr("web")},
+			wantPatch:  `{"metadata":{"labels":{"env":null,"tier":"frontend"}}}`,
+			wantAfter:  map[string]string{"app": "web", "tier": "frontend"},
+			wantChange: true,
+		},
+		{
+			name:       "add annotation",
+			field:      MetadataAnnotations,
+			changes:    map[string]*string{"example.com/owner": strPtr("team a")},
+			wantPatch:  `{"metadata":{"annotations":{"example.com/owner":"team a"}}}`,
+			wantAfter:  map[string]string{"note": "keep", "example.com/owner": "team a"},
+			wantChange: true,
+		},
+		{
+			name:      "same value is a no-op",
+			field:     MetadataLabels,
+			changes:   map[string]*string{"env": strPtr("prod")},
+			wantAfter: map[string]string{"app": "web", "env": "prod"},
+		},
+		{
+			name:    "change without overwrite",
+			field:   MetadataLabels,
+			changes: map[string]*string{"env": strPtr("staging"), "app": strPtr("api")},
+			wantErr: "labels already set: app=web, env=prod; pass overwrite=true",
+		},
+		{
+			name:    "invalid label value",
+			field:   MetadataLabels,
+			changes: map[string]*string{"tier": strPtr("front end")},
+			wantErr: `invalid value "front end" for label "tier"`,
+		},
+		{
+			name:    "invalid key",
+			field:   MetadataAnnotations,
+			changes: map[string]*string{"bad key": strPtr("x")},
+			wantErr: `invalid annotation key "bad key"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ro, client := newFakeOperations(t, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "web", "env": "prod"},
+				Annotations: map[string]string{"note": "keep"},
+			}})
+			var patches []string
+			client.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				patch := action.(k8stesting.PatchAction)
+				if patch.GetPatchType() != k8stypes.MergePatchType {
+					t.Errorf("unexpected patch type %s", patch.GetPatchType())
+				}
+				patches = append(patches, string(patch.GetPatch()))
+				return false, nil, nil
+			})
+
+			result, err := ro.PatchMetadata(context.Background(), ResourceTypePods, "default", "web", tt.field, tt.changes, tt.overwrite, "test")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(patches) != 0 {
+					t.Errorf("no patch expected, got %v", patches)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PatchMetadata failed: %v", err)
+			}
+
+			var wantPatches []string
+			if tt.wantPatch != "" {
+				wantPatches = []string{tt.wantPatch}
+			}
+			if !reflect.DeepEqual(patches, wantPatches) {
+				t.Errorf("patches: got %v, want %v", patches, wantPatches)
+			}
+			if result.Changed != tt.wantChange || !reflect.DeepEqual(result.After, tt.wantAfter) {
+				t.Errorf("unexpected result %+v", result)
+			}
+			wantBefore := map[string]string{"app": "web", "env": "prod"}
+			if tt.field == MetadataAnnotations {
+				wantBefore = map[string]string{"note": "keep"}
+			}
+			if !reflect.DeepEqual(result.Before, wantBefore) {
+				t.Errorf("before: got %v, want %v", result.Before, wantBefore)
+			}
+		})
+	}
+}
+
+// TestPatchMetadataResourceTypes 测试集群级资源忽略命名空间，以及不支持的类型和不存在的对象报错
+func TestPatchMetadataResourceTypes(t *testing.T) {
+	ro, _ := newFakeOperations(t, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	ctx := context.Background()
+	changes := map[string]*string{"disk": strPtr("ssd")}
+
+	result, err := ro.PatchMetadata(ctx, ResourceTypeNode, "ignored", "node-1", MetadataLabels, changes, false, "")
+	if err != nil || !result.Changed || result.Namespace != "" || result.After["disk"] != "ssd" || len(result.Before) != 0 {
+		t.Fatalf("unexpected node result %+v (%v)", result, err)
+	}
+
+	if _, err := ro.PatchMetadata(ctx, ResourceTypeEvents, "default", "e", MetadataLabels, changes, false, ""); err == nil || !strings.Contains(err.Error(), "unsupported resource type") {
+		t.Errorf("expected unsupported type error, got %v", err)
+	}
+	if _, err := ro.PatchMetadata(ctx, ResourceTypeDeployment, "default", "missing", MetadataLabels, changes, false, ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, err := ro.PatchMetadata(ctx, ResourceTypeNode, "", "node-1", MetadataLabels, nil, false, ""); err == nil {
+		t.Error("expected an error without changes")
+	}
+}
@@ -0,0 +1,351 @@
+package k8s
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// MockClusterName is the name of the cluster added by LoadMockCluster
+// MockClusterName 是 LoadMockCluster 添加的集群名称
+const MockClusterName = "mock"
+
+// mockServerVersion is reported as the version of the mock cluster
+// mockServerVersion 作为模拟集群的版本返回
+var mockServerVersion = version.Info{Major: "1", Minor: "28", GitVersion: "v1.28.4-mock", Platform: "linux/amd64"}
+
+// defaultMockData is the fixture set used when no data directory is given
+// defaultMockData 是未指定数据目录时使用的默认资源集
+//
+//go:embed mockdata/*.yaml
+var defaultMockData embed.FS
+
+// mockClusterScopedKinds are the built-in kinds the mock REST mapper treats as cluster-scoped
+// mockClusterScopedKinds 是模拟 REST 映射器视为集群级的内置资源类型
+var mockClusterScopedKinds = map[string]bool{
+	"Namespace": true, "Node": true, "PersistentVolume": true, "ComponentStatus": true,
+	"StorageClass": true, "CSIDriver": true, "CSINode": true, "VolumeAttachment": true,
+	"ClusterRole": true, "ClusterRoleBinding": true, "PriorityClass": true, "RuntimeClass": true,
+	"IngressClass": true, "CertificateSigningRequest": true,
+	"MutatingWebhookConfiguration": true, "ValidatingWebhookConfiguration": true,
+	"FlowSchema": true, "PriorityLevelConfiguration": true,
+}
+
+// mockDynamicClient is the dynamic client and REST mapper of a mock cluster
+// mockDynamicClient 是模拟集群的动态客户端和 REST 映射器
+type mockDynamicClient struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+// LoadMockCluster adds the in-memory cluster "mock" backed by a fake clientset seeded with
+// the objects in the YAML or JSON files under dataDir (the built-in fixtures if empty) and
+// makes it the current cluster. Reads see the fixtures and writes change them in memory only.
+// Namespaces referenced by the fixtures are created if missing, and objects without a
+// creationTimestamp are stamped with the load time.
+// LoadMockCluster 添加名为 "mock" 的内存集群，它由 fake clientset 支撑，预置 dataDir 下 YAML 或 JSON 文件中的对象
+// （为空时使用内置资源集），并将其设为当前集群。读取操作返回预置数据，写入操作只修改内存中的数据。
+// 资源引用但不存在的命名空间会被自动创建，未设置 creationTimestamp 的对象以加载时间为准。
+func (cm *ClusterManager) LoadMockCluster(dataDir string) error {
+	var fsys fs.FS
+	if dataDir == "" {
+		sub, err := fs.Sub(defaultMockData, "mockdata")
+		if err != nil {
+			return err
+		}
+		fsys = sub
+	} else {
+		info, err := os.Stat(dataDir)
+		if err != nil {
+			return fmt.Errorf("failed to read mock data: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("mock data %s is not a directory", dataDir)
+		}
+		fsys = os.DirFS(dataDir)
+	}
+
+	objects, err := readMockObjects(fsys)
+	if err != nil {
+		return err
+	}
+	objects = withMockNamespaces(objects, time.Now())
+
+	client := fake.NewSimpleClientset(objects...)
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &mockServerVersion
+	// The fake clientset only filters lists by label; apply field selectors too
+	// fake clientset 只按标签过滤列表，这里补充字段选择器的过滤
+	client.PrependReactor("list", "*", mockFieldSelectorReactor(client.Tracker()))
+
+	mapper := mockRESTMapper()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("get", "*", mockDynamicGetReactor(client.Tracker(), mapper))
+
+	config := &rest.Config{Host: "mock://" + MockClusterName}
+	cm.applyClientSettings(MockClusterName, config)
+
+	cm.AddClientset(MockClusterName, client)
+	cm.configs[MockClusterName] = config
+	if cm.mockDynamic == nil {
+		cm.mockDynamic = make(map[string]mockDynamicClient)
+	}
+	cm.mockDynamic[MockClusterName] = mockDynamicClient{client: dynamicClient, mapper: mapper}
+	cm.currentCluster = MockClusterName
+
+	cm.logger.Info("Mock cluster loaded", "cluster", MockClusterName, "objects", len(objects), "data", dataDir)
+	return nil
+}
+
+// readMockObjects decodes every object in the .yaml, .yml and .json files of fsys, in
+// lexical file order. Files may hold several documents and v1 List objects.
+// readMockObjects 按文件名顺序解码 fsys 中所有 .yaml、.yml 和 .json 文件里的对象，
+// 文件可以包含多个文档和 v1 List 对象。
+func readMockObjects(fsys fs.FS) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		decoded, err := decodeMockObjects(data)
+		if err != nil {
+			return fmt.Errorf("invalid mock data %s: %w", name, err)
+		}
+		objects = append(objects, decoded...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects found in mock data")
+	}
+	return objects, nil
+}
+
+// decodeMockObjects decodes the documents of one fixture file into typed objects
+// decodeMockObjects 将一个资源文件中的文档解码为类型化对象
+func decodeMockObjects(data []byte) ([]runtime.Object, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	deserializer := scheme.Codecs.UniversalDeserializer()
+
+	var objects []runtime.Object
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		raw.Raw = bytes.TrimSpace(raw.Raw)
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+
+		obj, _, err := deserializer.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := obj.(*corev1.List)
+		if !ok {
+			objects = append(objects, obj)
+			continue
+		}
+		for _, item := range list.Items {
+			itemObj, _, err := deserializer.Decode(item.Raw, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, itemObj)
+		}
+	}
+}
+
+// withMockNamespaces stamps objects without a creationTimestamp with now and appends a
+// Namespace for every namespace the objects use but don't define
+// withMockNamespaces 为未设置 creationTimestamp 的对象设置为 now，并为对象使用但未定义的命名空间追加 Namespace
+func withMockNamespaces(objects []runtime.Object, now time.Time) []runtime.Object {
+	defined := make(map[string]bool)
+	var used []string
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if creationTimestamp := accessor.GetCreationTimestamp(); creationTimestamp.IsZero() {
+			accessor.SetCreationTimestamp(metav1.NewTime(now))
+		}
+		if event, ok := obj.(*corev1.Event); ok && event.LastTimestamp.IsZero() {
+			event.FirstTimestamp = metav1.NewTime(now)
+			event.LastTimestamp = metav1.NewTime(now)
+		}
+		if _, ok := obj.(*corev1.Namespace); ok {
+			defined[accessor.GetName()] = true
+		}
+		if namespace := accessor.GetNamespace(); namespace != "" {
+			used = append(used, namespace)
+		}
+	}
+
+	for _, namespace := range used {
+		if defined[namespace] {
+			continue
+		}
+		defined[namespace] = true
+		objects = append(objects, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace, CreationTimestamp: metav1.NewTime(now)},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		})
+	}
+	return objects
+}
+
+// mockFieldSelectorReactor serves lists with a field selector from the tracker, keeping
+// only the items whose mockFields match
+// mockFieldSelectorReactor 从 tracker 返回带字段选择器的列表，只保留 mockFields 匹配的条目
+func mockFieldSelectorReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	objectReaction := k8stesting.ObjectReaction(tracker)
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		listAction, ok := action.(k8stesting.ListAction)
+		if !ok {
+			return false, nil, nil
+		}
+		selector := listAction.GetListRestrictions().Fields
+		if selector == nil || selector.Empty() {
+			return false, nil, nil
+		}
+
+		handled, list, err := objectReaction(action)
+		if !handled || err != nil {
+			return handled, list, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return true, nil, err
+		}
+		kept := make([]runtime.Object, 0, len(items))
+		for _, item := range items {
+			if selector.Matches(mockFields(item)) {
+				kept = append(kept, item)
+			}
+		}
+		if err := meta.SetList(list, kept); err != nil {
+			return true, nil, err
+		}
+		return true, list, nil
+	}
+}
+
+// mockFields returns the fields of obj that field selectors can match, mirroring the
+// fields the API server supports for pods and events
+// mockFields 返回字段选择器可匹配的对象字段，与 API server 对 Pod 和 Event 支持的字段一致
+func mockFields(obj runtime.Object) fields.Set {
+	set := fields.Set{}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		set["metadata.name"] = accessor.GetName()
+		set["metadata.namespace"] = accessor.GetNamespace()
+	}
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		set["spec.nodeName"] = o.Spec.NodeName
+		set["spec.restartPolicy"] = string(o.Spec.RestartPolicy)
+		set["spec.serviceAccountName"] = o.Spec.ServiceAccountName
+		set["status.phase"] = string(o.Status.Phase)
+		set["status.podIP"] = o.Status.PodIP
+	case *corev1.Event:
+		set["involvedObject.kind"] = o.InvolvedObject.Kind
+		set["involvedObject.namespace"] = o.InvolvedObject.Namespace
+		set["involvedObject.name"] = o.InvolvedObject.Name
+		set["involvedObject.uid"] = string(o.InvolvedObject.UID)
+		set["involvedObject.apiVersion"] = o.InvolvedObject.APIVersion
+		set["involvedObject.resourceVersion"] = o.InvolvedObject.ResourceVersion
+		set["involvedObject.fieldPath"] = o.InvolvedObject.FieldPath
+		set["reason"] = o.Reason
+		set["reportingComponent"] = o.ReportingController
+		set["source"] = o.Source.Component
+		set["type"] = o.Type
+	case *corev1.Node:
+		set["spec.unschedulable"] = fmt.Sprint(o.Spec.Unschedulable)
+	}
+	return set
+}
+
+// mockDynamicGetReactor serves dynamic gets from the typed clientset's tracker, so
+// the dynamic client sees the same objects, including those changed by writes
+// mockDynamicGetReactor 从类型化 clientset 的 tracker 响应动态客户端的 get 请求，
+// 使动态客户端看到相同的对象，包括被写入操作修改过的对象
+func mockDynamicGetReactor(tracker k8stesting.ObjectTracker, mapper meta.RESTMapper) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		getAction, ok := action.(k8stesting.GetAction)
+		if !ok || getAction.GetSubresource() != "" {
+			return false, nil, nil
+		}
+		gvr := getAction.GetResource()
+		obj, err := tracker.Get(gvr, getAction.GetNamespace(), getAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		result := &unstructured.Unstructured{Object: content}
+		if gvk, err := mapper.KindFor(gvr); err == nil {
+			result.SetGroupVersionKind(gvk)
+		}
+		return true, result, nil
+	}
+}
+
+// mockRESTMapper maps every built-in kind of the client-go scheme to its resource
+// mockRESTMapper 将 client-go scheme 中的所有内置类型映射到对应的资源
+func mockRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(scheme.Scheme.PrioritizedVersionsAllGroups())
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		scope := meta.RESTScopeNamespace
+		if mockClusterScopedKinds[gvk.Kind] {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+	return mapper
+}
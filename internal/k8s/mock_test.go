@@ -0,0 +1,159 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mockFixture 包含一个 List 文档、一个缺少命名空间定义的 Pod 和两个事件
+const mockFixture = `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+      namespace: team-a
+    data:
+      mode: demo
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: api-0
+      namespace: team-a
+    spec:
+      nodeName: node-a
+      containers:
+        - name: api
+          image: api:1.0
+---
+apiVersion: v1
+kind: Event
+metadata:
+  name: api-0.pulled
+  namespace: team-a
+involvedObject:
+  kind: Pod
+  name: api-0
+  namespace: team-a
+reason: Pulled
+---
+apiVersion: v1
+kind: Event
+metadata:
+  name: settings.updated
+  namespace: team-a
+involvedObject:
+  kind: ConfigMap
+  name: settings
+  namespace: team-a
+reason: Updated
+`
+
+// newMockOperations 从 data 写入的临时目录加载模拟集群
+func newMockOperations(t *testing.T, data string) *ResourceOperations {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixture.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	// 非资源文件会被忽略
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a fixture"), 0o600)
+
+	cm := NewClusterManager(nil)
+	if err := cm.LoadMockCluster(dir); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	return NewResourceOperations(cm)
+}
+
+// TestLoadMockCluster 测试加载 List 文档、补全命名空间以及健康检查和版本信息
+func TestLoadMockCluster(t *testing.T) {
+	ro := newMockOperations(t, mockFixture)
+	ctx := context.Background()
+
+	if current := ro.clusterManager.GetCurrentCluster(); current != MockClusterName {
+		t.Fatalf("expected current cluster %s, got %s", MockClusterName, current)
+	}
+	if err := ro.clusterManager.HealthCheckCluster(ctx, MockClusterName); err != nil {
+		t.Errorf("HealthCheckCluster failed: %v", err)
+	}
+
+	namespaces, err := ro.ListNamespaces(ctx, "")
+	if err != nil || len(namespaces) != 1 || namespaces[0].Name != "team-a" {
+		t.Fatalf("expected the team-a namespace to be created, got %+v (%v)", namespaces, err)
+	}
+
+	pods, err := ro.ListPods(ctx, "team-a", "")
+	if err != nil || len(pods) != 1 || pods[0].Name != "api-0" {
+		t.Fatalf("unexpected pods %+v (%v)", pods, err)
+	}
+
+	info, err := ro.GetClusterInfo(ctx, "")
+	if err != nil || !strings.Contains(info["version"].(string), "mock") {
+		t.Errorf("unexpected cluster info %+v (%v)", info, err)
+	}
+}
+
+// TestMockClusterFieldSelector 测试模拟集群的列表请求按字段选择器过滤
+func TestMockClusterFieldSelector(t *testing.T) {
+	ro := newMockOperations(t, mockFixture)
+	client, err := ro.clusterManager.GetCurrentClient()
+	if err != nil {
+		t.Fatalf("GetCurrentClient failed: %v", err)
+	}
+
+	events, err := client.CoreV1().Events("team-a").List(context.Background(), metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=api-0",
+	})
+	if err != nil || len(events.Items) != 1 || events.Items[0].Reason != "Pulled" {
+		t.Fatalf("expected only the pod event, got %+v (%v)", events, err)
+	}
+
+	pods, err := client.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{FieldSelector: "spec.nodeName=node-b"})
+	if err != nil || len(pods.Items) != 0 {
+		t.Fatalf("expected no pods on node-b, got %+v (%v)", pods, err)
+	}
+}
+
+// TestMockClusterDiff 测试 diff_resource 使用的动态客户端读取与类型化客户端相同的数据
+func TestMockClusterDiff(t *testing.T) {
+	ro := newMockOperations(t, mockFixture)
+
+	diff, err := ro.DiffResource(context.Background(), `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: team-a
+data:
+  mode: live
+`, "")
+	if err != nil {
+		t.Fatalf("DiffResource failed: %v", err)
+	}
+	if !strings.Contains(diff, "-  mode: demo") || !strings.Contains(diff, "+  mode: live") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
+
+// TestLoadMockClusterErrors 测试无效或为空的数据目录报错
+func TestLoadMockClusterErrors(t *testing.T) {
+	cm := NewClusterManager(nil)
+	if err := cm.LoadMockCluster(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+	if err := cm.LoadMockCluster(t.TempDir()); err == nil || !strings.Contains(err.Error(), "no objects") {
+		t.Errorf("expected an error for an empty directory, got %v", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"), 0o600)
+	if err := cm.LoadMockCluster(dir); err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("expected an error naming bad.yaml, got %v", err)
+	}
+}
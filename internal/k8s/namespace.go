@@ -0,0 +1,259 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultProtectedNamespaces are the namespaces DeleteNamespace always refuses to delete
+// DefaultProtectedNamespaces 是 DeleteNamespace 始终拒绝删除的命名空间
+var DefaultProtectedNamespaces = []string{"default", "kube-node-lease", "kube-public", "kube-system"}
+
+// ErrNamespaceProtected is returned by DeleteNamespace for a protected namespace
+// ErrNamespaceProtected 表示 DeleteNamespace 拒绝删除受保护的命名空间
+var ErrNamespaceProtected = errors.New("namespace is protected")
+
+// NamespaceDeleteOptions controls DeleteNamespace
+// NamespaceDeleteOptions 控制 DeleteNamespace 的行为
+type NamespaceDeleteOptions struct {
+	// Protected are refused besides DefaultProtectedNamespaces
+	// Protected 是除 DefaultProtectedNamespaces 之外同样拒绝删除的命名空间
+	Protected []string
+	// Wait blocks until the namespace is gone or Timeout expires
+	// Wait 阻塞直到命名空间被完全删除或 Timeout 到期
+	Wait bool
+	// Timeout bounds the wait
+	// Timeout 限制等待时间
+	Timeout time.Duration
+}
+
+// IsProtectedNamespace reports whether name is one of DefaultProtectedNamespaces or protected
+// IsProtectedNamespace 判断 name 是否属于 DefaultProtectedNamespaces 或 protected
+func IsProtectedNamespace(name string, protected []string) bool {
+	for _, list := range [][]string{DefaultProtectedNamespaces, protected} {
+		for _, namespace := range list {
+			if namespace == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CreateNamespace creates a namespace with the given labels and annotations
+// CreateNamespace 创建带有指定标签和注解的命名空间
+func (ro *ResourceOperations) CreateNamespace(ctx context.Context, name string, labels, annotations map[string]string, clusterName string) (*types.NamespaceCreation, error) {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid namespace name %q: %s", name, strings.Join(errs, "; "))
+	}
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid value for label %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	for key := range annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid annotation key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	// The namespace policy cannot tell which namespace a create request names
+	// 命名空间策略无法判断创建请求针对的是哪个命名空间
+	if ro.clusterManager.NamespacePolicy() != nil {
+		return nil, fmt.Errorf("%w: creating namespaces is not allowed in namespace-scoped mode", ErrNamespaceNotAllowed)
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace: %w", err)
+	}
+	return &types.NamespaceCreation{
+		Name:        namespace.Name,
+		Status:      string(namespace.Status.Phase),
+		Labels:      namespace.Labels,
+		Annotations: namespace.Annotations,
+	}, nil
+}
+
+// DeleteNamespace deletes a namespace and everything in it, refusing protected
+// namespaces. The result counts the workloads the namespace still held. With
+// opts.Wait it waits until the namespace is gone; if it is still terminating when
+// opts.Timeout expires, the result lists the finalizers and conditions holding it up.
+// DeleteNamespace 删除命名空间及其中的所有对象，拒绝删除受保护的命名空间。结果统计命名空间中仍有的工作负载。
+// 设置 opts.Wait 时等待命名空间被完全删除；opts.Timeout 到期时仍在终止中，则在结果中列出阻塞删除的 finalizer 和状况。
+func (ro *ResourceOperations) DeleteNamespace(ctx context.Context, name string, opts NamespaceDeleteOptions, clusterName string) (*types.NamespaceDeletion, error) {
+	if name == "" {
+		return nil, fmt.Errorf("namespace name is required")
+	}
+	if IsProtectedNamespace(name, opts.Protected) {
+		return nil, fmt.Errorf("%w: %s cannot be deleted", ErrNamespaceProtected, name)
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	workloads, err := countWorkloads(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to delete namespace: %w", err)
+	}
+	result := &types.NamespaceDeletion{Namespace: name, Workloads: workloads}
+	if !opts.Wait {
+		result.Message = "deletion requested; the namespace terminates once its contents are removed"
+		return result, nil
+	}
+
+	result.Waited = true
+	wait, err := ro.WaitForCondition(ctx, ResourceTypeNamespaces, "", name, ConditionDeleted, opts.Timeout, clusterName)
+	if wait != nil {
+		result.Elapsed = wait.Elapsed
+	}
+	switch {
+	case err == nil:
+		result.Terminated = true
+		result.Message = "namespace deleted"
+		return result, nil
+	case !errors.Is(err, ErrWaitTimeout):
+		return nil, err
+	}
+
+	namespace, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		result.Terminated = true
+		result.Message = "namespace deleted"
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	result.Phase = string(namespace.Status.Phase)
+	result.Finalizers = namespaceFinalizers(namespace)
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			result.Conditions = append(result.Conditions, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+		}
+	}
+	result.Message = fmt.Sprintf("namespace still %s after %s", strings.ToLower(result.Phase), opts.Timeout)
+	if len(result.Finalizers) > 0 {
+		result.Message += "; waiting on finalizers " + strings.Join(result.Finalizers, ", ")
+	}
+	return result, nil
+}
+
+// namespaceFinalizers returns the finalizers of the namespace object and of its spec
+// namespaceFinalizers 返回命名空间对象及其 spec 中的 finalizer
+func namespaceFinalizers(namespace *corev1.Namespace) []string {
+	finalizers := append([]string(nil), namespace.Finalizers...)
+	for _, finalizer := range namespace.Spec.Finalizers {
+		finalizers = append(finalizers, string(finalizer))
+	}
+	sort.Strings(finalizers)
+	return finalizers
+}
+
+// countWorkloads counts the workloads in a namespace by resource type, leaving out
+// types with none
+// countWorkloads 按资源类型统计命名空间中的工作负载，数量为 0 的类型不列出
+func countWorkloads(ctx context.Context, client kubernetes.Interface, namespace string) (map[string]int, error) {
+	counters := []struct {
+		resource string
+		count    func() (int, error)
+	}{
+		{"pods", func() (int, error) {
+			list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"deployments", func() (int, error) {
+			list, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"statefulsets", func() (int, error) {
+			list, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"daemonsets", func() (int, error) {
+			list, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"jobs", func() (int, error) {
+			list, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+		{"cronjobs", func() (int, error) {
+			list, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return len(list.Items), nil
+		}},
+	}
+
+	workloads := make(map[string]int)
+	for _, counter := range counters {
+		count, err := counter.count()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", counter.resource, err)
+		}
+		if count > 0 {
+			workloads[counter.resource] = count
+		}
+	}
+	return workloads, nil
+}
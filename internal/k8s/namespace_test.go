@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestCreateNamespace 测试创建带标签和注解的命名空间，以及名称、标签无效和命名空间已存在时的错误
+func TestCreateNamespace(t *testing.T) {
+	ro, client := newFakeOperations(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "existing"}})
+	ctx := context.Background()
+
+	labels := map[string]string{"team": "payments"}
+	annotations := map[string]string{"owner": "alice@example.com"}
+	result, err := ro.CreateNamespace(ctx, "payments", labels, annotations, "test")
+	if err != nil {
+		t.Fatalf("CreateNamespace failed: %v", err)
+	}
+	if result.Name != "payments" || !reflect.DeepEqual(result.Labels, labels) || !reflect.DeepEqual(result.Annotations, annotations) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	namespace, err := client.CoreV1().Namespaces().Get(ctx, "payments", metav1.GetOptions{})
+	if err != nil || namespace.Labels["team"] != "payments" || namespace.Annotations["owner"] != "alice@example.com" {
+		t.Errorf("expected the namespace to be created with its metadata, got %v %v", namespace, err)
+	}
+
+	if _, err := ro.CreateNamespace(ctx, "existing", nil, nil, "test"); !apierrors.IsAlreadyExists(err) {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+	if _, err := ro.CreateNamespace(ctx, "Bad_Name", nil, nil, "test"); err == nil || !strings.Contains(err.Error(), "invalid namespace name") {
+		t.Errorf("expected an invalid name error, got %v", err)
+	}
+	if _, err := ro.CreateNamespace(ctx, "other", map[string]string{"team": "not valid!"}, nil, "test"); err == nil || !strings.Contains(err.Error(), `invalid value for label "team"`) {
+		t.Errorf("expected an invalid label error, got %v", err)
+	}
+}
+
+// TestDeleteNamespaceProtected 测试系统命名空间和额外配置的受保护命名空间被拒绝删除
+func TestDeleteNamespaceProtected(t *testing.T) {
+	var objects []runtime.Object
+	for _, name := range []string{"default", "kube-system", "kube-public", "kube-node-lease", "prod"} {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	ro, client := newFakeOperations(t, objects...)
+	ctx := context.Background()
+
+	for _, name := range []string{"default", "kube-system", "kube-public", "kube-node-lease", "prod"} {
+		_, err := ro.DeleteNamespace(ctx, name, NamespaceDeleteOptions{Protected: []string{"prod"}}, "test")
+		if !errors.Is(err, ErrNamespaceProtected) {
+			t.Errorf("%s: expected ErrNamespaceProtected, got %v", name, err)
+		}
+		if _, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{}); err != nil {
+			t.Errorf("%s: expected the namespace to be kept, got %v", name, err)
+		}
+	}
+}
+
+// TestDeleteNamespace 测试删除前统计剩余的工作负载，以及 wait=true 时等待命名空间被完全删除
+func TestDeleteNamespace(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "web", Namespace: "shop"}
+	ro, client := newFakeOperations(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}},
+		&corev1.Pod{ObjectMeta: meta},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "shop"}},
+		&appsv1.Deployment{ObjectMeta: meta},
+	)
+	ctx := context.Background()
+
+	result, err := ro.DeleteNamespace(ctx, "shop", NamespaceDeleteOptions{Wait: true, Timeout: 5 * time.Second}, "test")
+	if err != nil {
+		t.Fatalf("DeleteNamespace failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Workloads, map[string]int{"pods": 2, "deployments": 1}) {
+		t.Errorf("unexpected workloads %v", result.Workloads)
+	}
+	if !result.Waited || !result.Terminated || len(result.Finalizers) != 0 {
+		t.Errorf("expected the namespace to be terminated, got %+v", result)
+	}
+	if _, err := client.CoreV1().Namespaces().Get(ctx, "shop", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the namespace to be deleted, got %v", err)
+	}
+
+	// 不等待时只发起删除
+	result, err = ro.DeleteNamespace(ctx, "scratch", NamespaceDeleteOptions{}, "test")
+	if err != nil || result.Waited || result.Terminated || len(result.Workloads) != 0 {
+		t.Errorf("unexpected result %+v: %v", result, err)
+	}
+
+	if _, err := ro.DeleteNamespace(ctx, "missing", NamespaceDeleteOptions{}, "test"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+// TestDeleteNamespaceStuckFinalizer 测试 finalizer 未完成时等待超时，并报告阻塞删除的 finalizer 和状况
+func TestDeleteNamespaceStuckFinalizer(t *testing.T) {
+	ro, client := newFakeOperations(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}})
+	ctx := context.Background()
+
+	// 模拟 API server：删除请求只将命名空间标记为 Terminating，finalizer 一直不完成
+	client.PrependReactor("delete", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		now := metav1.Now()
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "shop",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"example.com/cleanup"},
+			},
+			Spec: corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+			Status: corev1.NamespaceStatus{
+				Phase: corev1.NamespaceTerminating,
+				Conditions: []corev1.NamespaceCondition{
+					{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Message: "Some content in the namespace has finalizers remaining: example.com/protect in 1 resource instances"},
+					{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse, Message: "All resources successfully discovered"},
+				},
+			},
+		}
+		err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("namespaces"), namespace, "")
+		return true, nil, err
+	})
+
+	result, err := ro.DeleteNamespace(ctx, "shop", NamespaceDeleteOptions{Wait: true, Timeout: 200 * time.Millisecond}, "test")
+	if err != nil {
+		t.Fatalf("DeleteNamespace failed: %v", err)
+	}
+	if result.Terminated || result.Phase != "Terminating" {
+		t.Errorf("expected the namespace to still be terminating, got %+v", result)
+	}
+	if !reflect.DeepEqual(result.Finalizers, []string{"example.com/cleanup", "kubernetes"}) {
+		t.Errorf("unexpected finalizers %v", result.Finalizers)
+	}
+	if len(result.Conditions) != 1 || !strings.HasPrefix(result.Conditions[0], "NamespaceFinalizersRemaining: ") {
+		t.Errorf("expected only the true condition, got %v", result.Conditions)
+	}
+	if !strings.Contains(result.Message, "waiting on finalizers example.com/cleanup, kubernetes") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+}
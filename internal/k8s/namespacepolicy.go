@@ -0,0 +1,316 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrNamespaceNotAllowed is returned for API requests rejected by the namespace policy
+// ErrNamespaceNotAllowed 被命名空间策略拒绝的 API 请求返回此错误
+var ErrNamespaceNotAllowed = errors.New("denied by namespace policy")
+
+// clusterScopedResources are the built-in cluster-scoped resources; any other
+// resource requested without a namespace is a cross-namespace list
+// clusterScopedResources 内置的集群级资源；其他不带命名空间的资源请求都是跨命名空间列表
+var clusterScopedResources = map[string]bool{
+	"nodes":                           true,
+	"persistentvolumes":               true,
+	"storageclasses":                  true,
+	"csidrivers":                      true,
+	"csinodes":                        true,
+	"volumeattachments":               true,
+	"clusterroles":                    true,
+	"clusterrolebindings":             true,
+	"customresourcedefinitions":       true,
+	"apiservices":                     true,
+	"ingressclasses":                  true,
+	"priorityclasses":                 true,
+	"runtimeclasses":                  true,
+	"certificatesigningrequests":      true,
+	"mutatingwebhookconfigurations":   true,
+	"validatingwebhookconfigurations": true,
+}
+
+// reviewResources only ask the API server about the caller's own permissions and
+// are allowed regardless of the policy
+// reviewResources 只查询调用者自身的权限，不受策略限制
+var reviewResources = map[string]bool{
+	"selfsubjectaccessreviews": true,
+	"selfsubjectrulesreviews":  true,
+}
+
+// NamespacePolicy restricts every API request of the cluster clients to a set of
+// namespaces. Patterns are path.Match globs such as "team-a-*". A nil policy
+// allows everything.
+// NamespacePolicy 将集群客户端的所有 API 请求限制在一组命名空间内，模式为 path.Match
+// 通配符（如 "team-a-*"）。nil 策略不做任何限制。
+type NamespacePolicy struct {
+	patterns          []string
+	allowClusterScope bool
+}
+
+// ParseNamespacePolicy builds a policy from a comma-separated list of namespace
+// patterns. An empty list returns a nil policy (unrestricted).
+// ParseNamespacePolicy 根据逗号分隔的命名空间模式创建策略，列表为空时返回 nil（不限制）
+func ParseNamespacePolicy(allowed string, allowClusterScope bool) (*NamespacePolicy, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(allowed, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return &NamespacePolicy{patterns: patterns, allowClusterScope: allowClusterScope}, nil
+}
+
+// Restricted reports whether the policy limits namespaces at all
+// Restricted 返回策略是否限制了命名空间
+func (p *NamespacePolicy) Restricted() bool {
+	return p != nil
+}
+
+// AllowClusterScope reports whether cluster-scoped resources such as nodes may be read
+// AllowClusterScope 返回是否允许读取节点等集群级资源
+func (p *NamespacePolicy) AllowClusterScope() bool {
+	return p == nil || p.allowClusterScope
+}
+
+// Patterns returns the allowed namespace patterns
+// Patterns 返回允许的命名空间模式
+func (p *NamespacePolicy) Patterns() []string {
+	if p == nil {
+		return nil
+	}
+	return append([]string(nil), p.patterns...)
+}
+
+// Allows reports whether namespace matches one of the allowed patterns
+// Allows 返回命名空间是否匹配任一允许的模式
+func (p *NamespacePolicy) Allows(namespace string) bool {
+	if p == nil {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the names allowed by the policy, preserving order
+// Filter 返回策略允许的名称，保持原有顺序
+func (p *NamespacePolicy) Filter(namespaces []string) []string {
+	var allowed []string
+	for _, ns := range namespaces {
+		if p.Allows(ns) {
+			allowed = append(allowed, ns)
+		}
+	}
+	return allowed
+}
+
+// literalNamespaces returns the patterns sorted and deduplicated when none of them
+// is a glob, so the allowed set is known without listing the cluster's namespaces
+// literalNamespaces 当所有模式都不含通配符时返回排序去重后的模式，此时无需列出集群命名空间
+func (p *NamespacePolicy) literalNamespaces() ([]string, bool) {
+	seen := make(map[string]bool, len(p.patterns))
+	var namespaces []string
+	for _, pattern := range p.patterns {
+		if strings.ContainsAny(pattern, `*?[\`) {
+			return nil, false
+		}
+		if !seen[pattern] {
+			seen[pattern] = true
+			namespaces = append(namespaces, pattern)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, true
+}
+
+// namespaceEnumerationKey marks requests that list namespaces only to filter them
+// through the policy
+// namespaceEnumerationKey 标记仅为按策略过滤而列出命名空间的请求
+type namespaceEnumerationKey struct{}
+
+// withNamespaceEnumeration lets the namespaces list through the guard; the caller
+// must filter the result with the policy
+// withNamespaceEnumeration 允许命名空间列表请求通过守卫，调用者必须用策略过滤结果
+func withNamespaceEnumeration(ctx context.Context) context.Context {
+	return context.WithValue(ctx, namespaceEnumerationKey{}, true)
+}
+
+// checkRequest decides whether an API request is allowed by the policy
+// checkRequest 判断 API 请求是否被策略允许
+func (p *NamespacePolicy) checkRequest(req *http.Request) error {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	// Strip /api/v1 and /apis/<group>/<version>; anything else (/version,
+	// /healthz, /openapi) and discovery requests carry no resource data
+	// 去掉 /api/v1 和 /apis/<group>/<version> 前缀；其他路径（/version、/healthz、/openapi）和发现请求不含资源数据
+	var rest []string
+	switch parts[0] {
+	case "api":
+		if len(parts) < 2 {
+			return nil
+		}
+		rest = parts[2:]
+	case "apis":
+		if len(parts) < 3 {
+			return nil
+		}
+		rest = parts[3:]
+	default:
+		return nil
+	}
+	if len(rest) > 0 && rest[0] == "watch" {
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+
+	resource := rest[0]
+	if resource == "namespaces" {
+		if len(rest) == 1 {
+			if enumerating, _ := req.Context().Value(namespaceEnumerationKey{}).(bool); enumerating {
+				return nil
+			}
+			return fmt.Errorf("%w: listing all namespaces is not allowed, only %s", ErrNamespaceNotAllowed, strings.Join(p.patterns, ","))
+		}
+		if !p.Allows(rest[1]) {
+			return fmt.Errorf("%w: namespace %q is not in the allowed namespaces (%s)", ErrNamespaceNotAllowed, rest[1], strings.Join(p.patterns, ","))
+		}
+		return nil
+	}
+
+	if reviewResources[resource] {
+		return nil
+	}
+	if !clusterScopedResources[resource] {
+		return fmt.Errorf("%w: %s without a namespace is not allowed, pass one of the allowed namespaces (%s)", ErrNamespaceNotAllowed, resource, strings.Join(p.patterns, ","))
+	}
+	if !p.allowClusterScope {
+		return fmt.Errorf("%w: cluster-scoped resource %s is not allowed in namespace-scoped mode", ErrNamespaceNotAllowed, resource)
+	}
+	return nil
+}
+
+// namespaceGuard rejects API requests outside the namespace policy before they
+// leave the process, so every client built from the cluster's rest.Config
+// (typed, dynamic, logs, watches) is covered
+// namespaceGuard 在请求发出前拒绝策略之外的 API 请求，覆盖基于集群 rest.Config
+// 创建的所有客户端（类型化、动态、日志、watch）
+type namespaceGuard struct {
+	policy *NamespacePolicy
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (g *namespaceGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := g.policy.checkRequest(req); err != nil {
+		return nil, err
+	}
+	return g.next.RoundTrip(req)
+}
+
+// NamespacePolicy returns the namespace policy of the cluster clients (nil if unrestricted)
+// NamespacePolicy 返回集群客户端的命名空间策略（不限制时为 nil）
+func (cm *ClusterManager) NamespacePolicy() *NamespacePolicy {
+	return cm.namespacePolicy
+}
+
+// AllowedNamespaces returns the namespaces of a cluster allowed by the policy. Glob
+// patterns are expanded against the cluster's namespaces.
+// AllowedNamespaces 返回集群中策略允许的命名空间，通配符模式按集群中的命名空间展开
+func (cm *ClusterManager) AllowedNamespaces(ctx context.Context, clusterName string) ([]string, error) {
+	if namespaces, ok := cm.namespacePolicy.literalNamespaces(); ok {
+		return namespaces, nil
+	}
+
+	names, err := cm.listNamespaceNames(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return cm.namespacePolicy.Filter(names), nil
+}
+
+// listNamespaceNames lists the names of all namespaces of a cluster, bypassing the
+// guard; callers must filter the result
+// listNamespaceNames 绕过守卫列出集群所有命名空间的名称，调用者必须过滤结果
+func (cm *ClusterManager) listNamespaceNames(ctx context.Context, clusterName string) ([]string, error) {
+	if clusterName == "" {
+		clusterName = cm.currentCluster
+	}
+	client, err := cm.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.CoreV1().Namespaces().List(withNamespaceEnumeration(ctx), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// fanOut reports whether a list with the given namespace must go through listAllNamespaces,
+// which is every all-namespaces list except the cluster-wide attempt it makes itself
+// fanOut 返回该命名空间的列表请求是否需要经过 listAllNamespaces，即除其自身发起的集群范围请求外的所有全命名空间列表
+func (ro *ResourceOperations) fanOut(ctx context.Context, namespace string) bool {
+	return namespace == "" && !isClusterWideList(ctx)
+}
+
+// listAllNamespaces lists a kind in all namespaces. In namespace-scoped mode it lists each
+// allowed namespace in turn. Otherwise it makes one cluster-wide list, and when that is
+// forbidden, as with namespace-scoped RBAC, it lists every namespace separately instead.
+// Forbidden namespaces are skipped and recorded for the caller (see WithSkippedNamespaces).
+// listAllNamespaces 列出所有命名空间中的某种资源。命名空间受限模式下依次列出每个允许的命名空间。
+// 否则发起一次集群范围的列表请求，该请求被禁止时 (例如按命名空间授权的 RBAC) 改为分别列出每个命名空间。
+// 被禁止的命名空间被跳过并记录给调用者 (见 WithSkippedNamespaces)
+func listAllNamespaces[T any](ctx context.Context, ro *ResourceOperations, clusterName string, list func(ctx context.Context, namespace string) ([]T, error)) ([]T, error) {
+	if ro.clusterManager.namespacePolicy.Restricted() {
+		namespaces, err := ro.clusterManager.AllowedNamespaces(ctx, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		return listEachNamespace(ctx, namespaces, 1, list)
+	}
+
+	items, err := list(withClusterWideList(ctx), metav1.NamespaceAll)
+	if !apierrors.IsForbidden(err) {
+		return items, err
+	}
+	// The fallback is best effort: when it fails too, the forbidden list is reported
+	// 回退只是尽力而为：回退同样失败时返回被禁止的集群范围列表的错误
+	namespaces, nsErr := ro.clusterManager.listNamespaceNames(ctx, clusterName)
+	if nsErr != nil || len(namespaces) == 0 {
+		return nil, err
+	}
+	items, fallbackErr := listEachNamespace(ctx, namespaces, namespaceListConcurrency, list)
+	if fallbackErr != nil {
+		return nil, err
+	}
+	return items, nil
+}
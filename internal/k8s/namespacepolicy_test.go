@@ -0,0 +1,232 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+// TestParseNamespacePolicy 测试解析逗号分隔的模式以及空列表和非法模式
+func TestParseNamespacePolicy(t *testing.T) {
+	policy, err := ParseNamespacePolicy(" team-a-*, shared ,,", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+	if got := policy.Patterns(); !reflect.DeepEqual(got, []string{"team-a-*", "shared"}) {
+		t.Errorf("unexpected patterns: %v", got)
+	}
+
+	policy, err = ParseNamespacePolicy(" , ", false)
+	if err != nil || policy != nil {
+		t.Errorf("expected nil policy for an empty list, got %v, %v", policy, err)
+	}
+	if !policy.Allows("kube-system") || !policy.AllowClusterScope() || policy.Restricted() {
+		t.Errorf("nil policy must allow everything")
+	}
+
+	if _, err := ParseNamespacePolicy("team-[a", false); err == nil {
+		t.Errorf("expected an error for a malformed pattern")
+	}
+}
+
+// TestNamespacePolicyAllows 测试通配符匹配
+func TestNamespacePolicyAllows(t *testing.T) {
+	policy, err := ParseNamespacePolicy("team-a-*,shared,ci-?", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+
+	tests := map[string]bool{
+		"team-a-dev":  true,
+		"team-a-":     true,
+		"team-a":      false,
+		"team-b-dev":  false,
+		"shared":      true,
+		"shared-2":    false,
+		"ci-1":        true,
+		"ci-12":       false,
+		"kube-system": false,
+		"":            false,
+	}
+	for namespace, want := range tests {
+		if got := policy.Allows(namespace); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", namespace, got, want)
+		}
+	}
+
+	got := policy.Filter([]string{"default", "team-a-prod", "shared", "ci-7"})
+	if !reflect.DeepEqual(got, []string{"team-a-prod", "shared", "ci-7"}) {
+		t.Errorf("unexpected filtered namespaces: %v", got)
+	}
+}
+
+// TestNamespacePolicyCheckRequest 测试守卫对不同 API 路径的判定
+func TestNamespacePolicyCheckRequest(t *testing.T) {
+	scoped, _ := ParseNamespacePolicy("team-a-*", false)
+	clusterScoped, _ := ParseNamespacePolicy("team-a-*", true)
+
+	tests := []struct {
+		path         string
+		enumerate    bool
+		scoped       bool
+		clusterScope bool
+	}{
+		{path: "/version", scoped: true, clusterScope: true},
+		{path: "/api", scoped: true, clusterScope: true},
+		{path: "/apis/apps/v1", scoped: true, clusterScope: true},
+		{path: "/api/v1/namespaces/team-a-dev/pods", scoped: true, clusterScope: true},
+		{path: "/api/v1/namespaces/team-a-dev/pods/web/log", scoped: true, clusterScope: true},
+		{path: "/apis/apps/v1/namespaces/team-a-dev/deployments", scoped: true, clusterScope: true},
+		{path: "/api/v1/namespaces/team-a-dev", scoped: true, clusterScope: true},
+		{path: "/api/v1/watch/namespaces/team-a-dev/pods", scoped: true, clusterScope: true},
+		{path: "/api/v1/namespaces/kube-system/pods", scoped: false, clusterScope: false},
+		{path: "/api/v1/namespaces/kube-system", scoped: false, clusterScope: false},
+		{path: "/api/v1/namespaces", scoped: false, clusterScope: false},
+		{path: "/api/v1/namespaces", enumerate: true, scoped: true, clusterScope: true},
+		{path: "/api/v1/pods", scoped: false, clusterScope: false},
+		{path: "/apis/apps/v1/deployments", scoped: false, clusterScope: false},
+		{path: "/api/v1/nodes", scoped: false, clusterScope: true},
+		{path: "/apis/rbac.authorization.k8s.io/v1/clusterroles", scoped: false, clusterScope: true},
+		{path: "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", scoped: true, clusterScope: true},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.enumerate {
+			ctx = withNamespaceEnumeration(ctx)
+		}
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil).WithContext(ctx)
+
+		for _, c := range []struct {
+			policy *NamespacePolicy
+			want   bool
+		}{{scoped, tt.scoped}, {clusterScoped, tt.clusterScope}} {
+			err := c.policy.checkRequest(req)
+			if (err == nil) != c.want {
+				t.Errorf("%s (cluster scope %v): allowed = %v, want %v (err: %v)", tt.path, c.policy.AllowClusterScope(), err == nil, c.want, err)
+			}
+			if err != nil && !errors.Is(err, ErrNamespaceNotAllowed) {
+				t.Errorf("%s: expected ErrNamespaceNotAllowed, got %v", tt.path, err)
+			}
+		}
+	}
+}
+
+// fakePodsAPIServer 返回一个模拟 API 服务器：列出 namespaces 中的命名空间，每个命名空间有一个同名 Pod
+func fakePodsAPIServer(t *testing.T, namespaces []string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.URL.Path == "/api/v1/namespaces":
+			var items []string
+			for _, ns := range namespaces {
+				items = append(items, fmt.Sprintf(`{"metadata":{"name":%q},"status":{"phase":"Active"}}`, ns))
+			}
+			fmt.Fprintf(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[%s]}`, strings.Join(items, ","))
+		case len(parts) == 5 && parts[2] == "namespaces" && parts[4] == "pods":
+			fmt.Fprintf(w, `{"kind":"PodList","apiVersion":"v1","items":[{"metadata":{"name":"pod-%s","namespace":%q},"status":{"phase":"Running"}}]}`, parts[3], parts[3])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// TestListPodsFansOutOverAllowedNamespaces 测试受限模式下全命名空间列表遍历允许的命名空间
+func TestListPodsFansOutOverAllowedNamespaces(t *testing.T) {
+	apiServer, requests := fakePodsAPIServer(t, []string{"default", "kube-system", "team-a-dev", "team-a-prod", "team-b"})
+
+	policy, err := ParseNamespacePolicy("team-a-*", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+	cm := NewClusterManager(&Options{NamespacePolicy: policy})
+	if err := cm.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	ro := NewResourceOperations(cm)
+
+	pods, err := ro.ListPods(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("ListPods failed: %v", err)
+	}
+	var got []string
+	for _, pod := range pods {
+		got = append(got, pod.Namespace+"/"+pod.Name)
+	}
+	if want := []string{"team-a-dev/pod-team-a-dev", "team-a-prod/pod-team-a-prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected pods: %v, want %v", got, want)
+	}
+
+	want := []string{"/api/v1/namespaces", "/api/v1/namespaces/team-a-dev/pods", "/api/v1/namespaces/team-a-prod/pods"}
+	if got := requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected API requests: %v, want %v", got, want)
+	}
+
+	// 显式指定不允许的命名空间时，请求在发出前被拒绝
+	if _, err := ro.ListPods(context.Background(), "kube-system", "test"); !errors.Is(err, ErrNamespaceNotAllowed) {
+		t.Errorf("expected ErrNamespaceNotAllowed for kube-system, got %v", err)
+	}
+	if n := len(requests()); n != len(want) {
+		t.Errorf("disallowed request reached the API server (%d requests)", n)
+	}
+
+	// list_namespaces 只返回允许的命名空间
+	namespaces, err := ro.ListNamespaces(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("ListNamespaces failed: %v", err)
+	}
+	if len(namespaces) != 2 || namespaces[0].Name != "team-a-dev" || namespaces[1].Name != "team-a-prod" {
+		t.Errorf("unexpected namespaces: %+v", namespaces)
+	}
+
+	// 节点是集群级资源，未开启 --allow-cluster-scope 时被拒绝
+	if _, err := ro.ListResourcesByType(context.Background(), ResourceTypeNodes, "", "test"); !errors.Is(err, ErrNamespaceNotAllowed) {
+		t.Errorf("expected ErrNamespaceNotAllowed for nodes, got %v", err)
+	}
+}
+
+// TestAllowedNamespacesLiteral 测试全部为字面名称时无需列出集群命名空间
+func TestAllowedNamespacesLiteral(t *testing.T) {
+	apiServer, requests := fakePodsAPIServer(t, nil)
+
+	policy, _ := ParseNamespacePolicy("shared,team-a,shared", false)
+	cm := NewClusterManager(&Options{NamespacePolicy: policy})
+	if err := cm.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+
+	got, err := cm.AllowedNamespaces(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("AllowedNamespaces failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"shared", "team-a"}) {
+		t.Errorf("unexpected namespaces: %v", got)
+	}
+	if n := len(requests()); n != 0 {
+		t.Errorf("expected no API requests, got %v", requests())
+	}
+}
@@ -0,0 +1,278 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ingressClassAnnotation is the ingress class annotation that predates spec.ingressClassName
+// ingressClassAnnotation 早于 spec.ingressClassName 的 Ingress 类注解
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// ListIngresses lists ingresses in a namespace
+// ListIngresses 列出命名空间中的 Ingress
+func (ro *ResourceOperations) ListIngresses(ctx context.Context, namespace, clusterName string) ([]types.Ingress, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Ingress, error) {
+			return ro.ListIngresses(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ingresses, err := client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	var results []types.Ingress
+	for i := range ingresses.Items {
+		results = append(results, toIngress(&ingresses.Items[i]))
+	}
+
+	return results, nil
+}
+
+// toIngress converts an ingress to its summary
+// toIngress 将 Ingress 转换为摘要信息
+func toIngress(ing *networkingv1.Ingress) types.Ingress {
+	class := ing.Annotations[ingressClassAnnotation]
+	if ing.Spec.IngressClassName != nil {
+		class = *ing.Spec.IngressClassName
+	}
+
+	var tls []types.IngressTLS
+	for _, t := range ing.Spec.TLS {
+		tls = append(tls, types.IngressTLS{Hosts: t.Hosts, SecretName: t.SecretName})
+	}
+
+	return types.Ingress{
+		Name:      ing.Name,
+		Namespace: ing.Namespace,
+		Class:     class,
+		Hosts:     ingressHosts(ing),
+		Address:   ingressAddress(ing),
+		Rules:     ingressRoutes(ing),
+		TLS:       tls,
+		Age:       formatAge(ing.CreationTimestamp),
+		CreatedAt: formatTimestamp(ing.CreationTimestamp),
+		Labels:    ing.Labels,
+	}
+}
+
+// ingressHosts returns the distinct hosts of the rules, or "*" when no rule sets one
+// ingressHosts 返回规则中不重复的 host，没有规则指定 host 时返回 "*"
+func ingressHosts(ing *networkingv1.Ingress) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, rule := range ing.Spec.Rules {
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{"*"}
+	}
+	return hosts
+}
+
+// ingressAddress returns the load balancer IPs and hostnames of an ingress
+// ingressAddress 返回 Ingress 负载均衡器的 IP 和主机名
+func ingressAddress(ing *networkingv1.Ingress) string {
+	var addresses []string
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		} else if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+	}
+	return strings.Join(addresses, ",")
+}
+
+// ingressRoutes flattens the rules into host, path and backend mappings; the default
+// backend is listed last with host and path "*"
+// ingressRoutes 将规则展开为 host、path 到后端的映射，默认后端以 host 和 path 为 "*" 列在最后
+func ingressRoutes(ing *networkingv1.Ingress) []types.IngressRoute {
+	var routes []types.IngressRoute
+	for _, rule := range ing.Spec.Rules {
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			p := path.Path
+			if p == "" {
+				p = "/"
+			}
+			routes = append(routes, types.IngressRoute{Host: host, Path: p, Backend: formatIngressBackend(path.Backend)})
+		}
+	}
+	if ing.Spec.DefaultBackend != nil {
+		routes = append(routes, types.IngressRoute{Host: "*", Path: "*", Backend: formatIngressBackend(*ing.Spec.DefaultBackend)})
+	}
+	return routes
+}
+
+// formatIngressBackend formats a backend as service:port, or Kind/name for resource backends
+// formatIngressBackend 将后端格式化为 service:port，资源后端格式化为 Kind/name
+func formatIngressBackend(backend networkingv1.IngressBackend) string {
+	if svc := backend.Service; svc != nil {
+		port := svc.Port.Name
+		if port == "" {
+			port = strconv.Itoa(int(svc.Port.Number))
+		}
+		return svc.Name + ":" + port
+	}
+	if res := backend.Resource; res != nil {
+		return res.Kind + "/" + res.Name
+	}
+	return "<none>"
+}
+
+// DescribeIngressRoutes renders the host -> path -> backend mappings and the TLS
+// sections of an ingress, which is what routing problems usually come down to.
+// It returns "" for anything other than an ingress.
+// DescribeIngressRoutes 输出 Ingress 的 host -> path -> 后端映射及 TLS 配置，排查路由问题时
+// 通常只需要这些信息。非 Ingress 对象返回空字符串。
+func DescribeIngressRoutes(resource interface{}) string {
+	ing, ok := resource.(*networkingv1.Ingress)
+	if !ok {
+		return ""
+	}
+	summary := toIngress(ing)
+
+	var sb strings.Builder
+	sb.WriteString("Rules:\n")
+	host := ""
+	for _, route := range summary.Rules {
+		if route.Host != host {
+			host = route.Host
+			fmt.Fprintf(&sb, "  %s\n", host)
+		}
+		fmt.Fprintf(&sb, "    %s -> %s\n", route.Path, route.Backend)
+	}
+	if len(summary.Rules) == 0 {
+		sb.WriteString("  <none>\n")
+	}
+	if len(summary.TLS) > 0 {
+		sb.WriteString("TLS:\n")
+		for _, t := range summary.TLS {
+			secret := t.SecretName
+			if secret == "" {
+				secret = "<default certificate>"
+			}
+			fmt.Fprintf(&sb, "  %s terminates %s\n", secret, strings.Join(t.Hosts, ","))
+		}
+	}
+	if summary.Address != "" {
+		fmt.Fprintf(&sb, "Address: %s\n", summary.Address)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// ListNetworkPolicies lists network policies in a namespace
+// ListNetworkPolicies 列出命名空间中的 NetworkPolicy
+func (ro *ResourceOperations) ListNetworkPolicies(ctx context.Context, namespace, clusterName string) ([]types.NetworkPolicy, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.NetworkPolicy, error) {
+			return ro.ListNetworkPolicies(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := client.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networkpolicies: %w", err)
+	}
+
+	var results []types.NetworkPolicy
+	for _, np := range policies.Items {
+		selector := metav1.FormatLabelSelector(&np.Spec.PodSelector)
+		if selector == "<none>" {
+			selector = "<all pods>"
+		}
+
+		// Without explicit policyTypes, Ingress always applies and Egress only when egress rules exist
+		// 未显式设置 policyTypes 时，Ingress 始终生效，Egress 仅在存在出站规则时生效
+		policyTypes := np.Spec.PolicyTypes
+		if len(policyTypes) == 0 {
+			policyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+			if len(np.Spec.Egress) > 0 {
+				policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+			}
+		}
+		var typeNames []string
+		hasIngress, hasEgress := false, false
+		for _, policyType := range policyTypes {
+			typeNames = append(typeNames, string(policyType))
+			hasIngress = hasIngress || policyType == networkingv1.PolicyTypeIngress
+			hasEgress = hasEgress || policyType == networkingv1.PolicyTypeEgress
+		}
+
+		results = append(results, types.NetworkPolicy{
+			Name:        np.Name,
+			Namespace:   np.Namespace,
+			PodSelector: selector,
+			PolicyTypes: typeNames,
+			Ingress:     policyDirection(hasIngress, len(np.Spec.Ingress)),
+			Egress:      policyDirection(hasEgress, len(np.Spec.Egress)),
+			Age:         formatAge(np.CreationTimestamp),
+			CreatedAt:   formatTimestamp(np.CreationTimestamp),
+			Labels:      np.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// policyDirection describes how a network policy restricts one direction of traffic
+// policyDirection 描述 NetworkPolicy 对某个方向流量的限制
+func policyDirection(applies bool, rules int) string {
+	switch {
+	case !applies:
+		return "not restricted"
+	case rules == 0:
+		return "deny all"
+	case rules == 1:
+		return "1 rule"
+	default:
+		return fmt.Sprintf("%d rules", rules)
+	}
+}
@@ -0,0 +1,169 @@
+package k8s
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestIngress 创建一个包含多条规则、TLS 和默认后端的 Ingress
+func newTestIngress() *networkingv1.Ingress {
+	prefix := networkingv1.PathTypePrefix
+	class := "nginx"
+	group := "k8s.example.com"
+	backend := func(name string, port int32, portName string) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: name,
+			Port: networkingv1.ServiceBackendPort{Number: port, Name: portName},
+		}}
+	}
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "web"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+			DefaultBackend:   &networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{APIGroup: &group, Kind: "StorageBucket", Name: "static-assets"}},
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"shop.example.com", "www.example.com"}, SecretName: "shop-tls"},
+				{Hosts: []string{"api.example.com"}},
+			},
+			Rules: []networkingv1.IngressRule{
+				{Host: "shop.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/api", PathType: &prefix, Backend: backend("api", 8080, "")},
+						{Path: "/", PathType: &prefix, Backend: backend("frontend", 0, "http")},
+					},
+				}}},
+				{Host: "api.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{PathType: &prefix, Backend: backend("api", 8080, "")},
+					},
+				}}},
+			},
+		},
+		Status: networkingv1.IngressStatus{LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+			Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "203.0.113.10"}, {Hostname: "lb.example.com"}},
+		}},
+	}
+}
+
+// fakeNetworkAPIServer 模拟 Ingress 和 NetworkPolicy 的列表接口
+func fakeNetworkAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]interface{}{
+		"/apis/networking.k8s.io/v1/namespaces/web/ingresses": networkingv1.IngressList{Items: []networkingv1.Ingress{*newTestIngress()}},
+		"/apis/networking.k8s.io/v1/namespaces/web/networkpolicies": networkingv1.NetworkPolicyList{Items: []networkingv1.NetworkPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "web"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-frontend", Namespace: "web"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+					Ingress:     []networkingv1.NetworkPolicyIngressRule{{}, {}},
+				},
+			},
+		}},
+	}
+
+	return fakeAPIServer(t, responses)
+}
+
+// TestListIngresses 测试 Ingress 的 host、地址、路由和 TLS
+func TestListIngresses(t *testing.T) {
+	ro := newServerOperations(t, fakeNetworkAPIServer(t))
+
+	ingresses, err := ro.ListIngresses(context.Background(), "web", "test")
+	if err != nil {
+		t.Fatalf("ListIngresses failed: %v", err)
+	}
+	if len(ingresses) != 1 {
+		t.Fatalf("expected 1 ingress, got %+v", ingresses)
+	}
+
+	ing := ingresses[0]
+	if ing.Class != "nginx" || ing.Address != "203.0.113.10,lb.example.com" {
+		t.Errorf("unexpected class or address: %+v", ing)
+	}
+	if want := []string{"shop.example.com", "api.example.com"}; !reflect.DeepEqual(ing.Hosts, want) {
+		t.Errorf("hosts = %v, want %v", ing.Hosts, want)
+	}
+	wantRoutes := []types.IngressRoute{
+		{Host: "shop.example.com", Path: "/api", Backend: "api:8080"},
+		{Host: "shop.example.com", Path: "/", Backend: "frontend:http"},
+		{Host: "api.example.com", Path: "/", Backend: "api:8080"},
+		{Host: "*", Path: "*", Backend: "StorageBucket/static-assets"},
+	}
+	if !reflect.DeepEqual(ing.Rules, wantRoutes) {
+		t.Errorf("routes = %+v, want %+v", ing.Rules, wantRoutes)
+	}
+	if len(ing.TLS) != 2 || ing.TLS[0].SecretName != "shop-tls" || len(ing.TLS[0].Hosts) != 2 {
+		t.Errorf("unexpected tls: %+v", ing.TLS)
+	}
+
+	infos, err := ToResourceInfos(ingresses)
+	if err != nil || infos[0].Status != "Hosts: shop.example.com,api.example.com, Address: 203.0.113.10,lb.example.com" {
+		t.Errorf("unexpected resource info: %+v, %v", infos, err)
+	}
+}
+
+// TestDescribeIngressRoutes 测试 Ingress 路由描述，非 Ingress 对象返回空字符串
+func TestDescribeIngressRoutes(t *testing.T) {
+	want := strings.Join([]string{
+		"Rules:",
+		"  shop.example.com",
+		"    /api -> api:8080",
+		"    / -> frontend:http",
+		"  api.example.com",
+		"    / -> api:8080",
+		"  *",
+		"    * -> StorageBucket/static-assets",
+		"TLS:",
+		"  shop-tls terminates shop.example.com,www.example.com",
+		"  <default certificate> terminates api.example.com",
+		"Address: 203.0.113.10,lb.example.com",
+	}, "\n")
+	if got := DescribeIngressRoutes(newTestIngress()); got != want {
+		t.Errorf("unexpected description:\n%s\nwant:\n%s", got, want)
+	}
+	if got := DescribeIngressRoutes(newTestPod()); got != "" {
+		t.Errorf("expected no description for a pod, got %q", got)
+	}
+}
+
+// TestListNetworkPolicies 测试 NetworkPolicy 的 Pod 选择器和入站/出站规则
+func TestListNetworkPolicies(t *testing.T) {
+	ro := newServerOperations(t, fakeNetworkAPIServer(t))
+
+	policies, err := ro.ListNetworkPolicies(context.Background(), "web", "test")
+	if err != nil {
+		t.Fatalf("ListNetworkPolicies failed: %v", err)
+	}
+
+	tests := []struct {
+		podSelector, ingress, egress string
+		policyTypes                  []string
+	}{
+		{"<all pods>", "deny all", "deny all", []string{"Ingress", "Egress"}},
+		{"app=api", "2 rules", "not restricted", []string{"Ingress"}},
+	}
+	if len(policies) != len(tests) {
+		t.Fatalf("expected %d policies, got %+v", len(tests), policies)
+	}
+	for i, tt := range tests {
+		np := policies[i]
+		if np.PodSelector != tt.podSelector || np.Ingress != tt.ingress || np.Egress != tt.egress || !reflect.DeepEqual(np.PolicyTypes, tt.policyTypes) {
+			t.Errorf("%s: unexpected policy %+v", np.Name, np)
+		}
+	}
+}
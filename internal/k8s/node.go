@@ -0,0 +1,252 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// allocatedResources are the resources summed in the "Allocated resources" table, as in kubectl describe node
+// allocatedResources 为 "Allocated resources" 表中汇总的资源，与 kubectl describe node 相同
+var allocatedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+// DescribeNode returns the details of a node along with its non-terminated pods and the
+// sum of their requests and limits against the node's allocatable resources
+// DescribeNode 返回节点详情，以及节点上未终止的 Pod 和它们的 requests/limits 相对于可分配资源的汇总
+func (ro *ResourceOperations) DescribeNode(ctx context.Context, name, clusterName string) (*types.NodeDetails, error) {
+	if name == "" {
+		return nil, fmt.Errorf("node name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	pods, err := ro.listNodePods(ctx, client, "", name, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeNode(node, pods), nil
+}
+
+// listNodePods lists the non-terminated pods scheduled on a node
+// listNodePods 列出调度到节点上且未终止的 Pod
+func (ro *ResourceOperations) listNodePods(ctx context.Context, client kubernetes.Interface, namespace, nodeName, clusterName string) ([]corev1.Pod, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]corev1.Pod, error) {
+			return ro.listNodePods(ctx, client, ns, nodeName, clusterName)
+		})
+	}
+
+	selector := fields.AndSelectors(
+		fields.OneTermEqualSelector("spec.nodeName", nodeName),
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+	)
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
+	}
+	return pods.Items, nil
+}
+
+// describeNode builds the details of a node from the node and its non-terminated pods
+// describeNode 根据节点及其未终止的 Pod 生成节点详情
+func describeNode(node *corev1.Node, pods []corev1.Pod) *types.NodeDetails {
+	info := node.Status.NodeInfo
+	details := &types.NodeDetails{
+		Name:             node.Name,
+		Status:           "Unknown",
+		Roles:            extractNodeRoles(node),
+		Unschedulable:    node.Spec.Unschedulable,
+		KubeletVersion:   info.KubeletVersion,
+		OSImage:          info.OSImage,
+		KernelVersion:    info.KernelVersion,
+		Architecture:     info.Architecture,
+		ContainerRuntime: info.ContainerRuntimeVersion,
+		Capacity:         formatResourceList(node.Status.Capacity),
+		Allocatable:      formatResourceList(node.Status.Allocatable),
+		Age:              formatAge(node.CreationTimestamp),
+		CreatedAt:        formatTimestamp(node.CreationTimestamp),
+		Labels:           node.Labels,
+	}
+
+	for _, address := range node.Status.Addresses {
+		details.Addresses = append(details.Addresses, fmt.Sprintf("%s: %s", address.Type, address.Address))
+	}
+	for _, taint := range node.Spec.Taints {
+		details.Taints = append(details.Taints, taint.ToString())
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			details.Status = "NotReady"
+			if condition.Status == corev1.ConditionTrue {
+				details.Status = "Ready"
+			}
+		}
+		if nodePressureConditions[condition.Type] && condition.Status == corev1.ConditionTrue {
+			details.Pressure = append(details.Pressure, fmt.Sprintf("%s (%s): %s", condition.Type, condition.Reason, condition.Message))
+		}
+		details.Conditions = append(details.Conditions, types.NodeConditionDetail{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastHeartbeatTime:  formatTimestamp(condition.LastHeartbeatTime),
+			LastTransitionTime: formatTimestamp(condition.LastTransitionTime),
+		})
+	}
+
+	requested := corev1.ResourceList{}
+	limited := corev1.ResourceList{}
+	for i := range pods {
+		requests, limits := podRequestsAndLimits(&pods[i])
+		addResourceList(requested, requests)
+		addResourceList(limited, limits)
+
+		details.Pods = append(details.Pods, types.NodePod{
+			Namespace:      pods[i].Namespace,
+			Name:           pods[i].Name,
+			CPURequests:    formatAllocation(requests, node.Status.Allocatable, corev1.ResourceCPU),
+			CPULimits:      formatAllocation(limits, node.Status.Allocatable, corev1.ResourceCPU),
+			MemoryRequests: formatAllocation(requests, node.Status.Allocatable, corev1.ResourceMemory),
+			MemoryLimits:   formatAllocation(limits, node.Status.Allocatable, corev1.ResourceMemory),
+			Age:            formatAge(pods[i].CreationTimestamp),
+		})
+	}
+
+	for _, name := range allocatedResources {
+		details.Allocated = append(details.Allocated, types.AllocatedResource{
+			Resource: string(name),
+			Requests: formatAllocation(requested, node.Status.Allocatable, name),
+			Limits:   formatAllocation(limited, node.Status.Allocatable, name),
+		})
+	}
+
+	return details
+}
+
+// podRequestsAndLimits computes the effective requests and limits of a pod the way the
+// scheduler does: the sum over the containers and sidecars (restartable init containers),
+// raised to the peak of the init phase where that is higher, plus the pod overhead. A
+// resource with only a limit is requested at that limit, as the API server defaults it.
+// podRequestsAndLimits 按调度器的方式计算 Pod 的有效 requests 和 limits：各容器与 sidecar（可重启的 init 容器）之和，
+// 若 init 阶段的峰值更大则取其值，再加上 Pod overhead。只设置了 limit 的资源按该 limit 计算 request，与 API server 的默认值相同。
+func podRequestsAndLimits(pod *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResourceList(requests, containerRequests(c))
+		addResourceList(limits, c.Resources.Limits)
+	}
+
+	// Init containers run one at a time, each alongside the sidecars started before it;
+	// sidecars keep running next to the app containers
+	// init 容器逐个运行，每个都与之前启动的 sidecar 同时运行；sidecar 会与应用容器一直同时运行
+	initRequests, initLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	sidecarRequests, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResourceList(sidecarRequests, containerRequests(c))
+			addResourceList(sidecarLimits, c.Resources.Limits)
+			maxResourceList(initRequests, sidecarRequests)
+			maxResourceList(initLimits, sidecarLimits)
+			continue
+		}
+		stepRequests, stepLimits := containerRequests(c), corev1.ResourceList{}
+		addResourceList(stepRequests, sidecarRequests)
+		addResourceList(stepLimits, c.Resources.Limits)
+		addResourceList(stepLimits, sidecarLimits)
+		maxResourceList(initRequests, stepRequests)
+		maxResourceList(initLimits, stepLimits)
+	}
+	addResourceList(requests, sidecarRequests)
+	addResourceList(limits, sidecarLimits)
+	maxResourceList(requests, initRequests)
+	maxResourceList(limits, initLimits)
+
+	if pod.Spec.Overhead != nil {
+		addResourceList(requests, pod.Spec.Overhead)
+		// Overhead only counts towards limits that are set
+		// overhead 只计入已设置的 limits
+		for name, quantity := range pod.Spec.Overhead {
+			if value, ok := limits[name]; ok {
+				value.Add(quantity)
+				limits[name] = value
+			}
+		}
+	}
+	return requests, limits
+}
+
+// containerRequests returns a copy of the requests of a container, with each resource
+// that only has a limit requested at that limit
+// containerRequests 返回容器 requests 的副本，只设置了 limit 的资源以该 limit 作为 request
+func containerRequests(c corev1.Container) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for name, quantity := range c.Resources.Requests {
+		requests[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range c.Resources.Limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity.DeepCopy()
+		}
+	}
+	return requests
+}
+
+// addResourceList adds the quantities of src to dst
+// addResourceList 将 src 中的数量累加到 dst
+func addResourceList(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		value := dst[name]
+		value.Add(quantity)
+		dst[name] = value
+	}
+}
+
+// maxResourceList raises the quantities of dst to those of src where src is larger
+// maxResourceList 当 src 中的数量更大时，将 dst 中对应的数量提高到 src 的值
+func maxResourceList(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if value, ok := dst[name]; !ok || quantity.Cmp(value) > 0 {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// formatAllocation formats a quantity with its share of the allocatable amount, e.g. "750m (37%)"
+// formatAllocation 格式化数量及其占可分配量的比例，例如 "750m (37%)"
+func formatAllocation(list, allocatable corev1.ResourceList, name corev1.ResourceName) string {
+	quantity := list[name]
+	total, ok := allocatable[name]
+	if !ok || total.IsZero() {
+		return quantity.String()
+	}
+	var percent int64
+	if name == corev1.ResourceCPU {
+		percent = quantity.MilliValue() * 100 / total.MilliValue()
+	} else {
+		percent = quantity.Value() * 100 / total.Value()
+	}
+	return fmt.Sprintf("%s (%d%%)", quantity.String(), percent)
+}
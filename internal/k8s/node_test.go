@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestNode 创建一个带污点、内存压力且 2 CPU / 4Gi 可分配的节点
+func newTestNode() *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints: []corev1.Taint{
+				{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{
+				KubeletVersion:          "v1.28.4",
+				OSImage:                 "Ubuntu 22.04.3 LTS",
+				KernelVersion:           "5.15.0-91-generic",
+				Architecture:            "amd64",
+				ContainerRuntimeVersion: "containerd://1.7.2",
+			},
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.11"}},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory", Message: "kubelet has insufficient memory available"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasNoDiskPressure"},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady"},
+			},
+		},
+	}
+}
+
+// newNodeTestPod 创建调度到 worker-1 上、带指定 requests/limits 的 Pod
+func newNodeTestPod(name string, containers []corev1.ResourceRequirements, initContainers ...corev1.ResourceRequirements) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+	}
+	for _, r := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "c", Resources: r})
+	}
+	for _, r := range initContainers {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Name: "init", Resources: r})
+	}
+	return pod
+}
+
+// resources 构造 CPU/内存 requests 和可选的 limits
+func resources(cpu, memory, cpuLimit, memoryLimit string) corev1.ResourceRequirements {
+	r := corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}}
+	if cpuLimit != "" {
+		r.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+			corev1.ResourceMemory: resource.MustParse(memoryLimit),
+		}
+	}
+	return r
+}
+
+// TestDescribeNode 测试节点信息、压力状况以及 Pod requests/limits 相对可分配资源的汇总
+func TestDescribeNode(t *testing.T) {
+	pods := []corev1.Pod{
+		// 两个容器的 requests 相加
+		newNodeTestPod("web", []corev1.ResourceRequirements{
+			resources("250m", "256Mi", "500m", "512Mi"),
+			resources("250m", "256Mi", "500m", "512Mi"),
+		}),
+		// init 容器的 requests 大于普通容器时取 init 容器的值
+		newNodeTestPod("migrate", []corev1.ResourceRequirements{resources("100m", "128Mi", "", "")}, resources("500m", "1Gi", "", "")),
+	}
+
+	details := describeNode(newTestNode(), pods)
+
+	if details.Status != "Ready" || details.Roles != "worker" || !details.Unschedulable {
+		t.Errorf("unexpected status: %+v", details)
+	}
+	if details.KubeletVersion != "v1.28.4" || details.ContainerRuntime != "containerd://1.7.2" || details.OSImage != "Ubuntu 22.04.3 LTS" {
+		t.Errorf("unexpected node info: %+v", details)
+	}
+	if want := []string{"dedicated=batch:NoSchedule", "node.kubernetes.io/unschedulable:NoSchedule"}; !reflect.DeepEqual(details.Taints, want) {
+		t.Errorf("taints = %v, want %v", details.Taints, want)
+	}
+	if want := []string{"MemoryPressure (KubeletHasInsufficientMemory): kubelet has insufficient memory available"}; !reflect.DeepEqual(details.Pressure, want) {
+		t.Errorf("pressure = %v, want %v", details.Pressure, want)
+	}
+	if len(details.Conditions) != 3 {
+		t.Errorf("expected all 3 conditions, got %+v", details.Conditions)
+	}
+
+	wantPods := []types.NodePod{
+		{Namespace: "default", Name: "web", CPURequests: "500m (25%)", CPULimits: "1 (50%)", MemoryRequests: "512Mi (12%)", MemoryLimits: "1Gi (25%)", Age: "<unknown>"},
+		{Namespace: "default", Name: "migrate", CPURequests: "500m (25%)", CPULimits: "0 (0%)", MemoryRequests: "1Gi (25%)", MemoryLimits: "0 (0%)", Age: "<unknown>"},
+	}
+	if !reflect.DeepEqual(details.Pods, wantPods) {
+		t.Errorf("pods = %+v, want %+v", details.Pods, wantPods)
+	}
+
+	wantAllocated := []types.AllocatedResource{
+		{Resource: "cpu", Requests: "1 (50%)", Limits: "1 (50%)"},
+		{Resource: "memory", Requests: "1536Mi (37%)", Limits: "1Gi (25%)"},
+		{Resource: "ephemeral-storage", Requests: "0", Limits: "0"},
+	}
+	if !reflect.DeepEqual(details.Allocated, wantAllocated) {
+		t.Errorf("allocated = %+v, want %+v", details.Allocated, wantAllocated)
+	}
+}
+
+// TestDescribeNodeListsNodePods 测试通过 spec.nodeName 字段选择器查询未终止的 Pod
+func TestDescribeNodeListsNodePods(t *testing.T) {
+	var selector string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/nodes/worker-1":
+			json.NewEncoder(w).Encode(newTestNode())
+		case "/api/v1/pods":
+			selector = r.URL.Query().Get("fieldSelector")
+			json.NewEncoder(w).Encode(corev1.PodList{Items: []corev1.Pod{
+				newNodeTestPod("web", []corev1.ResourceRequirements{resources("250m", "256Mi", "", "")}),
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	ro := newServerOperations(t, server)
+
+	details, err := ro.DescribeNode(context.Background(), "worker-1", "test")
+	if err != nil {
+		t.Fatalf("DescribeNode failed: %v", err)
+	}
+	if selector != "spec.nodeName=worker-1,status.phase!=Succeeded,status.phase!=Failed" {
+		t.Errorf("unexpected field selector %q", selector)
+	}
+	if len(details.Pods) != 1 || details.Allocated[0].Requests != "250m (12%)" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodOwnerNone is the owner shown for pods without a controller
+// PodOwnerNone 是没有控制器的 Pod 显示的所有者
+const PodOwnerNone = "<none>"
+
+// replicaSetDeployments maps "namespace/replicaset" to the Deployment controlling the
+// ReplicaSet, listing the ReplicaSets of namespace once and only when one of pods is
+// controlled by a ReplicaSet. A failed list returns nil, so the pods show their ReplicaSet.
+// replicaSetDeployments 将 "namespace/replicaset" 映射到控制该 ReplicaSet 的 Deployment。
+// 只有存在由 ReplicaSet 控制的 Pod 时才列出 namespace 中的 ReplicaSet，且只列出一次。
+// 列出失败时返回 nil，此时 Pod 显示其 ReplicaSet
+func replicaSetDeployments(ctx context.Context, client kubernetes.Interface, namespace string, pods []corev1.Pod) map[string]string {
+	needed := false
+	for i := range pods {
+		if owner := metav1.GetControllerOf(&pods[i]); owner != nil && owner.Kind == "ReplicaSet" {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	deployments := make(map[string]string)
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == "Deployment" {
+			deployments[rs.Namespace+"/"+rs.Name] = owner.Name
+		}
+	}
+	return deployments
+}
+
+// podOwner returns the kind and name of the pod's controller, following a ReplicaSet to
+// its Deployment through deployments; both are empty for pods without a controller
+// podOwner 返回 Pod 控制器的类型和名称，通过 deployments 将 ReplicaSet 解析为其 Deployment；
+// 没有控制器的 Pod 两者均为空
+func podOwner(pod *corev1.Pod, deployments map[string]string) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+	if owner.Kind == "ReplicaSet" {
+		if deployment, ok := deployments[pod.Namespace+"/"+owner.Name]; ok {
+			return "Deployment", deployment
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// formatPodOwner formats an owner as kind/name, or PodOwnerNone
+// formatPodOwner 将所有者格式化为 kind/name，没有时为 PodOwnerNone
+func formatPodOwner(kind, name string) string {
+	if kind == "" {
+		return PodOwnerNone
+	}
+	return kind + "/" + name
+}
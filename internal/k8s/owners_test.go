@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// controllerRef 返回指向 kind/name 的控制器 ownerReference
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &controller}}
+}
+
+// TestListPodsOwners 测试 Pod 的直接控制器、经 ReplicaSet 解析出的 Deployment 和没有控制器的 Pod，
+// 以及每次调用只列出一次 ReplicaSet
+func TestListPodsOwners(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-5f6d", Namespace: "shop", OwnerReferences: controllerRef("Deployment", "web")}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-5f6d-a", Namespace: "shop", OwnerReferences: controllerRef("ReplicaSet", "web-5f6d")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-5f6d-b", Namespace: "shop", OwnerReferences: controllerRef("ReplicaSet", "web-5f6d")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone-x", Namespace: "shop", OwnerReferences: controllerRef("ReplicaSet", "standalone")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop", OwnerReferences: controllerRef("StatefulSet", "db")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "shop"}},
+	)
+	replicaSetLists := 0
+	client.PrependReactor("list", "replicasets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		replicaSetLists++
+		return false, nil, nil
+	})
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+
+	pods, err := NewResourceOperations(cm).ListPods(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("ListPods failed: %v", err)
+	}
+	want := map[string][2]string{
+		"web-5f6d-a":   {"Deployment/web", "Deployment"},
+		"web-5f6d-b":   {"Deployment/web", "Deployment"},
+		"standalone-x": {"ReplicaSet/standalone", "ReplicaSet"},
+		"db-0":         {"StatefulSet/db", "StatefulSet"},
+		"debug":        {PodOwnerNone, ""},
+	}
+	for _, pod := range pods {
+		if got := [2]string{pod.Owner, pod.OwnerKind}; got != want[pod.Name] {
+			t.Errorf("%s: expected owner %v, got %v", pod.Name, want[pod.Name], got)
+		}
+	}
+	if len(pods) != len(want) {
+		t.Errorf("expected %d pods, got %d", len(want), len(pods))
+	}
+	if replicaSetLists != 1 {
+		t.Errorf("expected one ReplicaSet list, got %d", replicaSetLists)
+	}
+
+	infos, err := ToResourceInfos(pods)
+	if err != nil || infos[0].Owner == "" || infos[0].Owner != pods[0].Owner {
+		t.Errorf("expected the owner in ResourceInfo, got %+v %v", infos, err)
+	}
+}
+
+// TestListPodsOwnersWithoutReplicaSets 测试没有 Pod 由 ReplicaSet 控制时不列出 ReplicaSet
+func TestListPodsOwnersWithoutReplicaSets(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop", OwnerReferences: controllerRef("StatefulSet", "db")}},
+	)
+	client.PrependReactor("list", "replicasets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		t.Error("unexpected ReplicaSet list")
+		return false, nil, nil
+	})
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+
+	pods, err := NewResourceOperations(cm).ListPods(context.Background(), "shop", "test")
+	if err != nil || len(pods) != 1 || pods[0].Owner != "StatefulSet/db" {
+		t.Errorf("unexpected pods %+v %v", pods, err)
+	}
+}
@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PermissionCheck describes an action to check with a SelfSubjectAccessReview
+// PermissionCheck 描述通过 SelfSubjectAccessReview 检查的操作
+type PermissionCheck struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespace   string
+}
+
+// CheckPermission asks the API server whether the identity of ctx (the impersonated
+// user, if any) may perform the action. The review runs through the same client as
+// every other call, so the answer matches what the tools would be allowed to do.
+// CheckPermission 询问 API server 当前 ctx 的身份（若有模拟则为模拟用户）能否执行该操作。
+// 审查与其他调用使用同一客户端，因此结果与工具实际可执行的操作一致。
+func (ro *ResourceOperations) CheckPermission(ctx context.Context, check PermissionCheck, clusterName string) (*types.RBACPermission, error) {
+	if check.Verb == "" || check.Resource == "" {
+		return nil, fmt.Errorf("verb and resource are required")
+	}
+
+	if clusterName == "" {
+		clusterName = ro.clusterManager.GetCurrentCluster()
+	}
+	client, err := ro.clusterManager.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	sar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   check.Namespace,
+				Verb:        check.Verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+				Name:        check.Name,
+			},
+		},
+	}
+	response, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return permissionFromStatus(response.Status), nil
+}
+
+// permissionFromStatus converts a review status, keeping the authorizer's reason
+// permissionFromStatus 转换审查结果，保留授权器给出的原因
+func permissionFromStatus(status authorizationv1.SubjectAccessReviewStatus) *types.RBACPermission {
+	reason := status.Reason
+	if status.EvaluationError != "" {
+		if reason != "" {
+			reason += "; "
+		}
+		reason += "evaluation error: " + status.EvaluationError
+	}
+	if reason == "" {
+		if status.Allowed {
+			reason = "Permission granted"
+		} else {
+			reason = "Permission denied"
+		}
+	}
+	return &types.RBACPermission{Allowed: status.Allowed, Reason: reason}
+}
+
+// ParsePermissionResource splits a kubectl-style resource such as "deployments.apps"
+// or "pods/log" into the resource, API group and subresource
+// ParsePermissionResource 将 kubectl 风格的资源（如 "deployments.apps" 或 "pods/log"）拆分为资源、API 组和子资源
+func ParsePermissionResource(resource string) (name, group, subresource string) {
+	name, subresource, _ = strings.Cut(resource, "/")
+	name, group, _ = strings.Cut(name, ".")
+	return name, group, subresource
+}
+
+// ListPermissions lists what the identity of ctx may do in a namespace using a
+// SelfSubjectRulesReview (kubectl auth can-i --list)
+// ListPermissions 使用 SelfSubjectRulesReview 列出当前 ctx 的身份在命名空间中的权限（kubectl auth can-i --list）
+func (ro *ResourceOperations) ListPermissions(ctx context.Context, namespace, clusterName string) (*types.PermissionList, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	if clusterName == "" {
+		clusterName = ro.clusterManager.GetCurrentCluster()
+	}
+	client, err := ro.clusterManager.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+	response, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	return permissionListFromStatus(namespace, response.Status), nil
+}
+
+// permissionListFromStatus converts a rules review status
+// permissionListFromStatus 转换规则审查结果
+func permissionListFromStatus(namespace string, status authorizationv1.SubjectRulesReviewStatus) *types.PermissionList {
+	list := &types.PermissionList{
+		Namespace:       namespace,
+		ResourceRules:   []types.ResourceRule{},
+		Incomplete:      status.Incomplete,
+		EvaluationError: status.EvaluationError,
+	}
+	for _, rule := range status.ResourceRules {
+		list.ResourceRules = append(list.ResourceRules, types.ResourceRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	for _, rule := range status.NonResourceRules {
+		list.NonResourceRules = append(list.NonResourceRules, types.NonResourceRule{
+			Verbs:           rule.Verbs,
+			NonResourceURLs: rule.NonResourceURLs,
+		})
+	}
+	return list
+}
@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// fakeAuthorizationServer 模拟 SelfSubjectAccessReview 和 SelfSubjectRulesReview 接口，
+// get 允许、delete 拒绝并给出原因、patch 返回评估错误
+func fakeAuthorizationServer(t *testing.T) (*ResourceOperations, *[]authorizationv1.ResourceAttributes) {
+	t.Helper()
+
+	var attributes []authorizationv1.ResourceAttributes
+	server := fakeAPIServer(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews":
+			var review authorizationv1.SelfSubjectAccessReview
+			json.Unmarshal(body, &review)
+			attributes = append(attributes, *review.Spec.ResourceAttributes)
+			switch review.Spec.ResourceAttributes.Verb {
+			case "get":
+				review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true, Reason: `RBAC: allowed by ClusterRoleBinding "view"`}
+			case "delete":
+				review.Status = authorizationv1.SubjectAccessReviewStatus{Denied: true, Reason: "no RBAC policy matched"}
+			case "patch":
+				review.Status = authorizationv1.SubjectAccessReviewStatus{EvaluationError: "webhook authorizer timed out"}
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(review)
+		case "/apis/authorization.k8s.io/v1/selfsubjectrulesreviews":
+			var review authorizationv1.SelfSubjectRulesReview
+			json.Unmarshal(body, &review)
+			review.Status = authorizationv1.SubjectRulesReviewStatus{
+				ResourceRules: []authorizationv1.ResourceRule{
+					{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+				},
+				NonResourceRules: []authorizationv1.NonResourceRule{
+					{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+				},
+				Incomplete: true,
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(review)
+		default:
+			return false
+		}
+		return true
+	})
+	return newServerOperations(t, server), &attributes
+}
+
+// TestCheckPermission 测试允许、带原因的拒绝和评估错误三种结果
+func TestCheckPermission(t *testing.T) {
+	ro, attributes := fakeAuthorizationServer(t)
+
+	tests := []struct {
+		verb string
+		want types.RBACPermission
+	}{
+		{"get", types.RBACPermission{Allowed: true, Reason: `RBAC: allowed by ClusterRoleBinding "view"`}},
+		{"delete", types.RBACPermission{Allowed: false, Reason: "no RBAC policy matched"}},
+		{"patch", types.RBACPermission{Allowed: false, Reason: "evaluation error: webhook authorizer timed out"}},
+	}
+	for _, tt := range tests {
+		got, err := ro.CheckPermission(context.Background(), PermissionCheck{Verb: tt.verb, Resource: "pods", Namespace: "payments"}, "")
+		if err != nil {
+			t.Fatalf("CheckPermission(%s) failed: %v", tt.verb, err)
+		}
+		if *got != tt.want {
+			t.Errorf("CheckPermission(%s) = %+v, want %+v", tt.verb, *got, tt.want)
+		}
+	}
+
+	// kubectl 风格的资源拆分为资源、组和子资源后发送
+	resource, group, subresource := ParsePermissionResource("deployments.apps/scale")
+	if _, err := ro.CheckPermission(context.Background(), PermissionCheck{Verb: "get", Resource: resource, Group: group, Subresource: subresource, Name: "web", Namespace: "payments"}, "test"); err != nil {
+		t.Fatalf("CheckPermission failed: %v", err)
+	}
+	want := authorizationv1.ResourceAttributes{Namespace: "payments", Verb: "get", Group: "apps", Resource: "deployments", Subresource: "scale", Name: "web"}
+	if got := (*attributes)[len(*attributes)-1]; got != want {
+		t.Errorf("unexpected review attributes: %+v", got)
+	}
+
+	if _, err := ro.CheckPermission(context.Background(), PermissionCheck{Verb: "get"}, "test"); err == nil {
+		t.Errorf("expected an error without a resource")
+	}
+}
+
+// TestParsePermissionResource 测试解析 kubectl 风格的资源
+func TestParsePermissionResource(t *testing.T) {
+	tests := map[string][3]string{
+		"pods":                        {"pods", "", ""},
+		"pods/log":                    {"pods", "", "log"},
+		"deployments.apps":            {"deployments", "apps", ""},
+		"ingresses.networking.k8s.io": {"ingresses", "networking.k8s.io", ""},
+		"*":                           {"*", "", ""},
+	}
+	for input, want := range tests {
+		name, group, subresource := ParsePermissionResource(input)
+		if got := [3]string{name, group, subresource}; got != want {
+			t.Errorf("ParsePermissionResource(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+// TestListPermissions 测试通过 SelfSubjectRulesReview 列出权限
+func TestListPermissions(t *testing.T) {
+	ro, _ := fakeAuthorizationServer(t)
+
+	got, err := ro.ListPermissions(context.Background(), "payments", "test")
+	if err != nil {
+		t.Fatalf("ListPermissions failed: %v", err)
+	}
+	want := &types.PermissionList{
+		Namespace: "payments",
+		ResourceRules: []types.ResourceRule{
+			{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+		},
+		NonResourceRules: []types.NonResourceRule{{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}}},
+		Incomplete:       true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected permissions: %+v", got)
+	}
+
+	if _, err := ro.ListPermissions(context.Background(), "", "test"); err == nil {
+		t.Errorf("expected an error without a namespace")
+	}
+}
@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Reachability is the cached outcome of the last health check of a cluster
+// Reachability 是集群最近一次健康检查的缓存结果
+type Reachability struct {
+	Reachable bool      `json:"reachable"`
+	Version   string    `json:"version,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// recordReachability caches the outcome of a health check and the server version it reported
+// recordReachability 缓存健康检查结果以及检查得到的服务器版本
+func (cm *ClusterManager) recordReachability(clusterName, serverVersion string, err error) {
+	status := Reachability{Reachable: err == nil, Version: serverVersion, CheckedAt: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	cm.reachabilityMu.Lock()
+	defer cm.reachabilityMu.Unlock()
+	cm.reachability[clusterName] = status
+}
+
+// GetReachability returns the cached reachability of a cluster; false if it has not been checked yet
+// GetReachability 返回集群缓存的可达性，尚未检查时返回 false
+func (cm *ClusterManager) GetReachability(clusterName string) (Reachability, bool) {
+	cm.reachabilityMu.RLock()
+	defer cm.reachabilityMu.RUnlock()
+	status, ok := cm.reachability[clusterName]
+	return status, ok
+}
+
+// ProbeClusters health-checks every loaded cluster with at most concurrency probes
+// in flight, each bounded by timeout, and caches the outcomes. It blocks until all
+// probes finish and is meant to run in the background after the kubeconfig is loaded.
+// ProbeClusters 对所有已加载的集群进行健康检查，最多同时进行 concurrency 个探测，每个探测受 timeout 限制，
+// 并缓存检查结果。该方法会阻塞直到所有探测完成，适合在加载 kubeconfig 后在后台运行。
+func (cm *ClusterManager) ProbeClusters(ctx context.Context, concurrency int, timeout time.Duration) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, name := range cm.GetClusters() {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			cm.probeCluster(ctx, name, timeout)
+		}(name)
+	}
+	wg.Wait()
+}
+
+// WarmUpCurrentCluster builds the client of the current cluster and probes it, bounded by
+// timeout, so the first tool call neither waits for the client nor finds out the cluster is
+// unreachable. The other clusters keep their clients unbuilt until they are used.
+// WarmUpCurrentCluster 创建当前集群的客户端并对其进行探测 (受 timeout 限制)，使第一次工具调用既不需要等待客户端创建，
+// 也不会才发现集群不可达。其他集群的客户端在使用之前不会创建。
+func (cm *ClusterManager) WarmUpCurrentCluster(ctx context.Context, timeout time.Duration) {
+	if cm.currentCluster == "" {
+		return
+	}
+	cm.probeCluster(ctx, cm.currentCluster, timeout)
+}
+
+// probeCluster health-checks one cluster, bounded by timeout, and logs the outcome
+// probeCluster 对一个集群进行健康检查 (受 timeout 限制) 并记录结果
+func (cm *ClusterManager) probeCluster(ctx context.Context, name string, timeout time.Duration) {
+	// A probe reports the cluster as it is, so it is not retried
+	// 探测报告集群的当前状态，因此不重试
+	probeCtx, cancel := context.WithTimeout(withoutRetries(ctx), timeout)
+	defer cancel()
+	if err := cm.HealthCheckCluster(probeCtx, name); err != nil {
+		cm.logger.Warn("Cluster is unreachable", "cluster", name, "error", err)
+	} else {
+		cm.logger.Debug("Cluster is reachable", "cluster", name)
+	}
+}
+
+// CheckReachability returns the reachability of the clusters, health-checking those
+// whose cached status is older than maxAge with at most concurrency probes in flight,
+// each bounded by timeout. Clusters that are not loaded are left out.
+// CheckReachability 返回集群的可达性，对缓存结果早于 maxAge 的集群重新检查，最多同时进行 concurrency 个探测，
+// 每个探测受 timeout 限制。未加载的集群不包含在结果中。
+func (cm *ClusterManager) CheckReachability(ctx context.Context, clusterNames []string, maxAge time.Duration, concurrency int, timeout time.Duration) map[string]Reachability {
+	var g errgroup.Group
+	g.SetLimit(max(concurrency, 1))
+	for _, name := range clusterNames {
+		if status, ok := cm.GetReachability(name); ok && time.Since(status.CheckedAt) < maxAge {
+			continue
+		}
+		if _, err := cm.GetClientForCluster(name); err != nil {
+			continue
+		}
+		g.Go(func() error {
+			probeCtx, cancel := context.WithTimeout(withoutRetries(ctx), timeout)
+			defer cancel()
+			cm.HealthCheckCluster(probeCtx, name)
+			return nil
+		})
+	}
+	g.Wait()
+
+	results := make(map[string]Reachability)
+	for _, name := range clusterNames {
+		if status, ok := cm.GetReachability(name); ok {
+			results[name] = status
+		}
+	}
+	return results
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// TestProbeClusters 测试后台探测缓存每个集群的可达性和检查时间
+func TestProbeClusters(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"28","gitVersion":"v1.28.4"}`))
+	}))
+	defer apiServer.Close()
+
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("up", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	if err := cm.AddCluster("down", &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+
+	if _, ok := cm.GetReachability("up"); ok {
+		t.Fatalf("expected no cached status before probing")
+	}
+
+	before := time.Now()
+	cm.ProbeClusters(context.Background(), 2, 2*time.Second)
+
+	up, ok := cm.GetReachability("up")
+	if !ok || !up.Reachable || up.Version != "v1.28.4" || up.Error != "" || up.CheckedAt.Before(before) {
+		t.Errorf("unexpected status for up: %+v", up)
+	}
+	down, ok := cm.GetReachability("down")
+	if !ok || down.Reachable || down.Error == "" {
+		t.Errorf("unexpected status for down: %+v", down)
+	}
+}
+
+// TestCheckReachability 测试在缓存有效期内复用检查结果、过期后重新检查，以及未加载的集群被忽略
+func TestCheckReachability(t *testing.T) {
+	var requests atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"29","gitVersion":"v1.29.3"}`))
+	}))
+	defer apiServer.Close()
+
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("up", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	if err := cm.AddCluster("down", &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+
+	names := []string{"up", "down", "missing"}
+	results := cm.CheckReachability(context.Background(), names, time.Minute, 2, 2*time.Second)
+	if len(results) != 2 || !results["up"].Reachable || results["up"].Version != "v1.29.3" || results["down"].Reachable || results["down"].Error == "" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	cm.CheckReachability(context.Background(), names, time.Minute, 2, 2*time.Second)
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected the cached result to be reused, got %d requests", got)
+	}
+
+	cm.CheckReachability(context.Background(), []string{"up"}, 0, 2, 2*time.Second)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected an expired result to be checked again, got %d requests", got)
+	}
+}
@@ -0,0 +1,234 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// configReferences is the reference graph from pod specs, service accounts and ingresses
+// to the ConfigMaps and Secrets they use, keyed by namespace/name, with the objects
+// referencing each one and how
+// configReferences 是从 Pod spec、ServiceAccount 和 Ingress 到其使用的 ConfigMap 和 Secret 的引用图，以 namespace/name 为键，
+// 记录引用每个对象的引用者及引用方式
+type configReferences struct {
+	configMaps map[string][]types.ConfigReference
+	secrets    map[string][]types.ConfigReference
+}
+
+func newConfigReferences() *configReferences {
+	return &configReferences{configMaps: map[string][]types.ConfigReference{}, secrets: map[string][]types.ConfigReference{}}
+}
+
+// addConfigReference records that the referrer kind/namespace/referrer uses namespace/name through via
+// addConfigReference 记录引用者 kind/namespace/referrer 通过 via 引用 namespace/name
+func addConfigReference(graph map[string][]types.ConfigReference, namespace, name, kind, referrer, via string) {
+	key := namespace + "/" + name
+	refs := graph[key]
+	for i := range refs {
+		if refs[i].Kind != kind || refs[i].Name != referrer {
+			continue
+		}
+		for _, existing := range refs[i].Via {
+			if existing == via {
+				return
+			}
+		}
+		refs[i].Via = append(refs[i].Via, via)
+		return
+	}
+	graph[key] = append(refs, types.ConfigReference{Kind: kind, Namespace: namespace, Name: referrer, Via: []string{via}})
+}
+
+// addPodSpec records the ConfigMaps and Secrets a pod spec mounts as volumes (including
+// projected and CSI volumes), pulls images with, or reads through envFrom and env
+// valueFrom in any init, app or ephemeral container. Optional references count as well
+// addPodSpec 记录 Pod spec 作为卷挂载 (包括 projected 和 CSI 卷)、用于拉取镜像，或在任一 init、应用或临时容器中通过
+// envFrom 和 env valueFrom 读取的 ConfigMap 和 Secret。可选引用同样计入
+func (r *configReferences) addPodSpec(kind, namespace, name string, spec *corev1.PodSpec) {
+	configMap := func(ref, via string) { addConfigReference(r.configMaps, namespace, ref, kind, name, via) }
+	secret := func(ref, via string) { addConfigReference(r.secrets, namespace, ref, kind, name, via) }
+
+	for _, ref := range spec.ImagePullSecrets {
+		secret(ref.Name, "imagePullSecrets")
+	}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			configMap(volume.ConfigMap.Name, "volume "+volume.Name)
+		case volume.Secret != nil:
+			secret(volume.Secret.SecretName, "volume "+volume.Name)
+		case volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil:
+			secret(volume.CSI.NodePublishSecretRef.Name, "csi volume "+volume.Name)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					configMap(source.ConfigMap.Name, "projected volume "+volume.Name)
+				}
+				if source.Secret != nil {
+					secret(source.Secret.Name, "projected volume "+volume.Name)
+				}
+			}
+		}
+	}
+
+	var containers []corev1.Container
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, c := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(c.EphemeralContainerCommon))
+	}
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				configMap(from.ConfigMapRef.Name, fmt.Sprintf("envFrom (container %s)", c.Name))
+			}
+			if from.SecretRef != nil {
+				secret(from.SecretRef.Name, fmt.Sprintf("envFrom (container %s)", c.Name))
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				configMap(env.ValueFrom.ConfigMapKeyRef.Name, fmt.Sprintf("env %s (container %s)", env.Name, c.Name))
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				secret(env.ValueFrom.SecretKeyRef.Name, fmt.Sprintf("env %s (container %s)", env.Name, c.Name))
+			}
+		}
+	}
+}
+
+// buildConfigReferences computes the reference graph of the scanned objects: every
+// workload template, the secrets of service accounts and the TLS secrets of ingresses.
+// Pods created by a workload are recorded only for objects no template references, e.g.
+// the pods of an old ReplicaSet still running after a rollout, so the referrers are the
+// workloads rather than each of their replicas
+// buildConfigReferences 计算扫描对象的引用图：所有工作负载模板、ServiceAccount 的 Secret 以及 Ingress 的 TLS Secret。
+// 由工作负载创建的 Pod 只在没有模板引用该对象时记录 (例如滚动更新后仍在运行的旧 ReplicaSet 的 Pod)，
+// 使引用者为工作负载而不是其每个副本
+func buildConfigReferences(o *issueObjects) *configReferences {
+	refs := newConfigReferences()
+	for _, template := range o.templates() {
+		refs.addPodSpec(template.kind, template.namespace, template.name, template.spec)
+	}
+	pods := newConfigReferences()
+	for i := range o.pods {
+		pods.addPodSpec("Pod", o.pods[i].Namespace, o.pods[i].Name, &o.pods[i].Spec)
+	}
+	for key, referrers := range pods.configMaps {
+		if _, ok := refs.configMaps[key]; !ok {
+			refs.configMaps[key] = referrers
+		}
+	}
+	for key, referrers := range pods.secrets {
+		if _, ok := refs.secrets[key]; !ok {
+			refs.secrets[key] = referrers
+		}
+	}
+
+	for _, sa := range o.serviceAccounts {
+		for _, ref := range sa.Secrets {
+			addConfigReference(refs.secrets, sa.Namespace, ref.Name, "ServiceAccount", sa.Name, "secrets")
+		}
+		for _, ref := range sa.ImagePullSecrets {
+			addConfigReference(refs.secrets, sa.Namespace, ref.Name, "ServiceAccount", sa.Name, "imagePullSecrets")
+		}
+	}
+	for _, ingress := range o.ingresses {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				addConfigReference(refs.secrets, ingress.Namespace, tls.SecretName, "Ingress", ingress.Name, "tls")
+			}
+		}
+	}
+	return refs
+}
+
+// CheckReferences lists the objects in the namespace that reference a ConfigMap or
+// Secret: workloads and pods through volumes (including projected and CSI volumes), env,
+// envFrom and imagePullSecrets, and for Secrets also service accounts and ingress TLS.
+// It uses the same reference graph as the unused ConfigMap and Secret checks of
+// FindIssues. Kinds that cannot be listed or hit issuesScanLimit are reported in Skipped
+// CheckReferences 列出命名空间中引用某个 ConfigMap 或 Secret 的对象：通过卷 (包括 projected 和 CSI 卷)、env、envFrom 和
+// imagePullSecrets 引用的工作负载和 Pod，对于 Secret 还包括 ServiceAccount 和 Ingress TLS。
+// 与 FindIssues 中未使用的 ConfigMap 和 Secret 检查使用相同的引用图。无法列出或达到 issuesScanLimit 的资源类型记录在 Skipped 中
+func (ro *ResourceOperations) CheckReferences(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string) (*types.ConfigReferenceReport, error) {
+	var kind string
+	switch resourceType {
+	case ResourceTypeConfigMaps, ResourceTypeConfigMap:
+		kind = "ConfigMap"
+	case ResourceTypeSecrets, ResourceTypeSecret:
+		kind = "Secret"
+	default:
+		return nil, fmt.Errorf("unsupported resource type for reference checks: %s (supported: configmaps, secrets)", resourceType)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "ConfigMap" {
+		_, err = client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	} else {
+		_, err = client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+
+	scan := newIssueScan(namespace)
+	objects := &issueObjects{}
+	scanReferrers(ctx, client, scan, objects)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &types.ConfigReferenceReport{Kind: kind, Namespace: namespace, Name: name, Scanned: scan.scanned}
+	needed := []string{"pods", "deployments", "statefulsets", "daemonsets", "cronjobs"}
+	refs := buildConfigReferences(objects)
+	graph := refs.configMaps
+	if kind == "Secret" {
+		needed = append(needed, "serviceaccounts", "ingresses")
+		graph = refs.secrets
+	}
+	for _, list := range needed {
+		if err := scan.failed[list]; err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("cannot list %s: %v", list, err))
+		} else if scan.truncated[list] {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s truncated at %d objects", list, issuesScanLimit))
+		}
+	}
+
+	report.References = append([]types.ConfigReference{}, graph[namespace+"/"+name]...)
+	sort.Slice(report.References, func(i, j int) bool {
+		a, b := report.References[i], report.References[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	report.Referenced = len(report.References) > 0
+	return report, nil
+}
@@ -0,0 +1,155 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// referenceFixture 解码 testdata/references.yaml：apps 命名空间中通过每种引用方式 (卷、projected 卷、CSI 卷、envFrom、
+// env valueFrom、imagePullSecrets、init 和临时容器、ServiceAccount、Ingress TLS) 引用的 ConfigMap 和 Secret
+func referenceFixture(t *testing.T) []runtime.Object {
+	t.Helper()
+	data, err := os.ReadFile("testdata/references.yaml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	objects, err := decodeMockObjects(data)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+	return objects
+}
+
+// TestCheckReferences 测试每种引用方式的引用者及引用方式；工作负载创建的 Pod 只在工作负载不再引用时列出
+func TestCheckReferences(t *testing.T) {
+	ro, _ := newFakeOperations(t, referenceFixture(t)...)
+
+	cases := []struct {
+		resourceType ResourceType
+		name         string
+		want         string
+	}{
+		{ResourceTypeConfigMaps, "api-config", "CronJob/report (volume config); Deployment/api (volume config)"},
+		{ResourceTypeConfigMap, "api-config-v1", "Pod/api-5c9f8b7d6-xyz12 (volume config)"},
+		{ResourceTypeConfigMaps, "feature-flags", "Deployment/api (env FEATURE_FLAGS (container api))"},
+		{ResourceTypeConfigMaps, "db-init", "StatefulSet/db (projected volume bundle)"},
+		{ResourceTypeConfigMaps, "agent-config", "DaemonSet/agent (envFrom (container agent))"},
+		{ResourceTypeConfigMaps, "debug-settings", "Pod/toolbox (envFrom (container debugger))"},
+		{ResourceTypeConfigMaps, "unused", ""},
+		{ResourceTypeSecrets, "api-env", "Deployment/api (envFrom (container api)); Pod/toolbox (env API_TOKEN (container shell))"},
+		{ResourceTypeSecrets, "registry", "Deployment/api (imagePullSecrets); ServiceAccount/builder (imagePullSecrets)"},
+		{ResourceTypeSecret, "db-credentials", "StatefulSet/db (projected volume bundle, env PGPASSWORD (container init))"},
+		{ResourceTypeSecrets, "agent-certs", "DaemonSet/agent (volume certs)"},
+		{ResourceTypeSecrets, "store-creds", "DaemonSet/agent (csi volume store)"},
+		{ResourceTypeSecrets, "builder-token", "ServiceAccount/builder (secrets)"},
+		{ResourceTypeSecrets, "api-tls", "Ingress/api (tls)"},
+	}
+	for _, tc := range cases {
+		report, err := ro.CheckReferences(context.Background(), tc.resourceType, "apps", tc.name, "test")
+		if err != nil {
+			t.Fatalf("%s: CheckReferences failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, ref := range report.References {
+			if ref.Namespace != "apps" {
+				t.Errorf("%s: unexpected referrer namespace %+v", tc.name, ref)
+			}
+			got = append(got, fmt.Sprintf("%s/%s (%s)", ref.Kind, ref.Name, strings.Join(ref.Via, ", ")))
+		}
+		if strings.Join(got, "; ") != tc.want || report.Referenced != (tc.want != "") || len(report.Skipped) != 0 {
+			t.Errorf("%s: expected %q, got %q (skipped %v)", tc.name, tc.want, strings.Join(got, "; "), report.Skipped)
+		}
+	}
+
+	// 其他命名空间中的同名 ConfigMap 不受引用
+	if report, err := ro.CheckReferences(context.Background(), ResourceTypeConfigMaps, "other", "api-config", "test"); err != nil || report.Referenced {
+		t.Errorf("expected no references in another namespace, got %+v %v", report, err)
+	}
+	if _, err := ro.CheckReferences(context.Background(), ResourceTypeConfigMaps, "apps", "missing", "test"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, err := ro.CheckReferences(context.Background(), ResourceTypeDeployments, "apps", "api", "test"); err == nil {
+		t.Error("expected deployments to be refused")
+	}
+}
+
+// TestCheckReferencesShared 测试 find_issues 使用同一引用图：只有未被引用的 ConfigMap 被报告为未使用
+func TestCheckReferencesShared(t *testing.T) {
+	ro, _ := newFakeOperations(t, referenceFixture(t)...)
+
+	report, err := ro.FindIssues(context.Background(), "apps", "test")
+	if err != nil {
+		t.Fatalf("FindIssues failed: %v", err)
+	}
+	var unused []string
+	for _, issue := range report.Issues {
+		if issue.Category == IssueUnusedConfigMap || issue.Category == IssueUnusedSecret {
+			unused = append(unused, issue.Kind+"/"+issue.Name)
+		}
+	}
+	if strings.Join(unused, ",") != "ConfigMap/unused" {
+		t.Errorf("expected only ConfigMap/unused to be unused, got %v", unused)
+	}
+}
+
+// TestCheckReferencesIncomplete 测试无法列出或被截断的资源类型记录在 Skipped 中
+func TestCheckReferencesIncomplete(t *testing.T) {
+	ro, client := newFakeOperations(t, referenceFixture(t)...)
+	client.PrependReactor("list", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "serviceaccounts"}, "", fmt.Errorf("access denied"))
+	})
+
+	// ConfigMap 不依赖 ServiceAccount
+	report, err := ro.CheckReferences(context.Background(), ResourceTypeConfigMaps, "apps", "unused", "test")
+	if err != nil || len(report.Skipped) != 0 {
+		t.Fatalf("expected a complete check, got %+v %v", report, err)
+	}
+
+	limit := issuesScanLimit
+	issuesScanLimit = 1
+	defer func() { issuesScanLimit = limit }()
+
+	report, err = ro.CheckReferences(context.Background(), ResourceTypeSecrets, "apps", "builder-token", "test")
+	if err != nil {
+		t.Fatalf("CheckReferences failed: %v", err)
+	}
+	want := []string{
+		"pods truncated at 1 objects",
+		"cannot list serviceaccounts: serviceaccounts is forbidden: access denied",
+	}
+	if strings.Join(report.Skipped, "\n") != strings.Join(want, "\n") || report.Referenced {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+// TestDeleteResource 测试删除对象、拒绝命名空间和不支持的资源类型
+func TestDeleteResource(t *testing.T) {
+	ro, client := newFakeOperations(t, referenceFixture(t)...)
+	ctx := context.Background()
+
+	result, err := ro.DeleteResource(ctx, ResourceTypeConfigMaps, "apps", "unused", "test")
+	if err != nil || result.Name != "unused" || result.Namespace != "apps" {
+		t.Fatalf("unexpected result %+v %v", result, err)
+	}
+	if _, err := client.CoreV1().ConfigMaps("apps").Get(ctx, "unused", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the ConfigMap to be deleted, got %v", err)
+	}
+	if _, err := ro.DeleteResource(ctx, ResourceTypeConfigMaps, "apps", "unused", "test"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, err := ro.DeleteResource(ctx, ResourceTypeNamespaces, "", "apps", "test"); err == nil || !strings.Contains(err.Error(), "delete_namespace") {
+		t.Errorf("expected namespaces to be refused, got %v", err)
+	}
+	if _, err := ro.DeleteResource(ctx, ResourceTypeEvents, "apps", "x", "test"); err == nil {
+		t.Error("expected events to be refused")
+	}
+}
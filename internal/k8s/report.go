@@ -0,0 +1,294 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Report section keys used in ClusterReport.Unavailable
+// ClusterReport.Unavailable 中使用的报告部分名称
+const (
+	ReportSectionOverview       = "overview"
+	ReportSectionNodes          = "nodes"
+	ReportSectionNamespaces     = "namespaces"
+	ReportSectionPods           = "pods"
+	ReportSectionDeployments    = "deployments"
+	ReportSectionStatefulSets   = "statefulsets"
+	ReportSectionEvents         = "events"
+	ReportSectionNodeConditions = "node_conditions"
+	ReportSectionVolumeClaims   = "volume_claims"
+)
+
+const (
+	// reportEventWindow is how far back the report looks for Warning events
+	// reportEventWindow 报告统计 Warning 事件的时间范围
+	reportEventWindow = time.Hour
+	// maxReportWarningEvents caps the Warning events listed in the report; the count covers all of them
+	// maxReportWarningEvents 报告中列出的 Warning 事件上限，计数包含全部事件
+	maxReportWarningEvents = 50
+	// reportQueryConcurrency limits the queries the report runs against the API server at once
+	// reportQueryConcurrency 报告同时向 API 服务器发起的查询数量上限
+	reportQueryConcurrency = 4
+)
+
+// nodePressureConditions are the node conditions reported when they are True
+// nodePressureConditions 为 True 时需要报告的节点状况
+var nodePressureConditions = map[corev1.NodeConditionType]bool{
+	corev1.NodeMemoryPressure:     true,
+	corev1.NodeDiskPressure:       true,
+	corev1.NodePIDPressure:        true,
+	corev1.NodeNetworkUnavailable: true,
+}
+
+// GenerateClusterReport assembles a snapshot of a cluster: overview, nodes, namespaces
+// with their pod and deployment counts, workloads that are not fully ready, recent
+// Warning events, node pressure conditions and unbound volume claims. The queries run
+// concurrently; a failed query leaves its section out and records the reason in
+// Unavailable instead of failing the whole report.
+// GenerateClusterReport 生成集群快照：概览、节点、命名空间及其 Pod 和 Deployment 数量、未就绪的工作负载、
+// 最近的 Warning 事件、节点压力状况和未绑定的存储卷声明。各查询并发执行，单个查询失败时跳过对应部分
+// 并在 Unavailable 中记录原因，而不是让整个报告失败。
+func (ro *ResourceOperations) GenerateClusterReport(ctx context.Context, clusterName string) (*types.ClusterReport, error) {
+	if clusterName == "" {
+		clusterName = ro.clusterManager.GetCurrentCluster()
+	}
+	if _, err := ro.clusterManager.GetClientForCluster(clusterName); err != nil {
+		return nil, err
+	}
+
+	var (
+		info         map[string]interface{}
+		nodes        []types.Node
+		conditions   []types.NodeCondition
+		namespaces   []types.Namespace
+		pods         []types.Pod
+		deployments  []types.Deployment
+		statefulSets []types.StatefulSet
+		events       []types.Event
+		claims       []types.PersistentVolumeClaim
+	)
+	allowClusterScope := ro.clusterManager.namespacePolicy.AllowClusterScope()
+
+	// Every query records its own error, so one failure does not cancel the others
+	// 每个查询单独记录错误，单个失败不会取消其他查询
+	queries := []struct {
+		section string
+		skip    bool
+		run     func() error
+	}{
+		{ReportSectionOverview, false, func() (err error) { info, err = ro.GetClusterInfo(ctx, clusterName); return }},
+		{ReportSectionNodes, !allowClusterScope, func() (err error) { nodes, err = ro.listNodes(ctx, clusterName); return }},
+		{ReportSectionNodeConditions, !allowClusterScope, func() (err error) { conditions, err = ro.listNodeConditions(ctx, clusterName); return }},
+		{ReportSectionNamespaces, false, func() (err error) { namespaces, err = ro.ListNamespaces(ctx, clusterName); return }},
+		{ReportSectionPods, false, func() (err error) { pods, err = ro.ListPods(ctx, "", clusterName); return }},
+		{ReportSectionDeployments, false, func() (err error) { deployments, err = ro.ListDeployments(ctx, "", clusterName); return }},
+		{ReportSectionStatefulSets, false, func() (err error) { statefulSets, err = ro.ListStatefulSets(ctx, "", clusterName); return }},
+		{ReportSectionEvents, false, func() (err error) { events, err = ro.listEvents(ctx, "", clusterName); return }},
+		{ReportSectionVolumeClaims, false, func() (err error) { claims, err = ro.ListPersistentVolumeClaims(ctx, "", clusterName); return }},
+	}
+	results := make([]error, len(queries))
+	var g errgroup.Group
+	g.SetLimit(reportQueryConcurrency)
+	for i, query := range queries {
+		if query.skip {
+			continue
+		}
+		g.Go(func() error {
+			results[i] = query.run()
+			return nil
+		})
+	}
+	g.Wait()
+
+	report := &types.ClusterReport{
+		Cluster:     clusterName,
+		GeneratedAt: formatTimestamp(metav1.NewTime(now())),
+	}
+	for i, query := range queries {
+		if results[i] == nil {
+			continue
+		}
+		if report.Unavailable == nil {
+			report.Unavailable = make(map[string]string)
+		}
+		report.Unavailable[query.section] = fmt.Sprintf("section unavailable: %v", results[i])
+	}
+
+	if info != nil {
+		report.Version, _ = info["version"].(string)
+		report.Platform, _ = info["platform"].(string)
+	}
+	if allowClusterScope && report.Unavailable[ReportSectionNodes] == "" {
+		report.Nodes = summarizeNodes(nodes)
+	}
+	report.NodeConditions = conditions
+	report.Namespaces = summarizeNamespaces(namespaces, pods, deployments)
+	report.UnreadyWorkloads = unreadyWorkloads(deployments, statefulSets)
+	report.WarningEvents, report.WarningEventCount = recentWarningEvents(events, now().Add(-reportEventWindow))
+	report.VolumeClaims = unboundClaims(claims)
+
+	return report, nil
+}
+
+// summarizeNodes counts nodes by readiness and collects their kubelet versions
+// summarizeNodes 按就绪状态统计节点数量并收集 kubelet 版本
+func summarizeNodes(nodes []types.Node) *types.NodeSummary {
+	summary := &types.NodeSummary{Total: len(nodes)}
+	versions := make(map[string]bool)
+	for _, node := range nodes {
+		if node.Status == "Ready" {
+			summary.Ready++
+		} else {
+			summary.NotReady++
+		}
+		if node.Version != "" && !versions[node.Version] {
+			versions[node.Version] = true
+			summary.Versions = append(summary.Versions, node.Version)
+		}
+	}
+	sort.Strings(summary.Versions)
+	return summary
+}
+
+// summarizeNamespaces counts the pods and deployments of each namespace
+// summarizeNamespaces 统计每个命名空间中的 Pod 和 Deployment 数量
+func summarizeNamespaces(namespaces []types.Namespace, pods []types.Pod, deployments []types.Deployment) []types.NamespaceSummary {
+	podCounts := make(map[string]int)
+	for _, pod := range pods {
+		podCounts[pod.Namespace]++
+	}
+	deploymentCounts := make(map[string]int)
+	for _, dep := range deployments {
+		deploymentCounts[dep.Namespace]++
+	}
+
+	var results []types.NamespaceSummary
+	for _, ns := range namespaces {
+		results = append(results, types.NamespaceSummary{
+			Name:        ns.Name,
+			Status:      ns.Status,
+			Pods:        podCounts[ns.Name],
+			Deployments: deploymentCounts[ns.Name],
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// unreadyWorkloads returns the deployments and statefulsets whose ready replicas are
+// fewer than their replicas
+// unreadyWorkloads 返回就绪副本数少于副本数的 Deployment 和 StatefulSet
+func unreadyWorkloads(deployments []types.Deployment, statefulSets []types.StatefulSet) []types.UnreadyWorkload {
+	var results []types.UnreadyWorkload
+	for _, dep := range deployments {
+		if !fullyReady(dep.Ready) {
+			results = append(results, types.UnreadyWorkload{Kind: "Deployment", Namespace: dep.Namespace, Name: dep.Name, Ready: dep.Ready})
+		}
+	}
+	for _, ss := range statefulSets {
+		if !fullyReady(ss.Ready) {
+			results = append(results, types.UnreadyWorkload{Kind: "StatefulSet", Namespace: ss.Namespace, Name: ss.Name, Ready: ss.Ready})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return results
+}
+
+// fullyReady reports whether a "ready/desired" replica count has every replica ready
+// fullyReady 返回 "就绪数/期望数" 格式的副本数是否全部就绪
+func fullyReady(ready string) bool {
+	readyCount, desired, ok := strings.Cut(ready, "/")
+	return !ok || readyCount == desired
+}
+
+// recentWarningEvents returns the Warning events last seen after since, newest first and
+// capped at maxReportWarningEvents, together with their total count
+// recentWarningEvents 返回 since 之后最后出现的 Warning 事件（按时间倒序，最多 maxReportWarningEvents 条）及其总数
+func recentWarningEvents(events []types.Event, since time.Time) ([]types.Event, int) {
+	var results []types.Event
+	for _, event := range events {
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		lastSeen, err := time.Parse(time.RFC3339, event.LastTimestamp)
+		if err != nil || lastSeen.Before(since) {
+			continue
+		}
+		results = append(results, event)
+	}
+	// RFC3339 UTC timestamps sort chronologically as strings
+	// RFC3339 UTC 时间戳按字符串排序即为时间顺序
+	sort.SliceStable(results, func(i, j int) bool { return results[i].LastTimestamp > results[j].LastTimestamp })
+
+	total := len(results)
+	if total > maxReportWarningEvents {
+		results = results[:maxReportWarningEvents]
+	}
+	return results, total
+}
+
+// listNodeConditions lists the pressure conditions that are True and the Ready
+// conditions that are not True across all nodes
+// listNodeConditions 列出所有节点中为 True 的压力状况以及不为 True 的 Ready 状况
+func (ro *ResourceOperations) listNodeConditions(ctx context.Context, clusterName string) ([]types.NodeCondition, error) {
+	client, err := ro.clusterManager.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var results []types.NodeCondition
+	for _, node := range nodes.Items {
+		for _, condition := range node.Status.Conditions {
+			abnormal := nodePressureConditions[condition.Type] && condition.Status == corev1.ConditionTrue
+			if condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue {
+				abnormal = true
+			}
+			if !abnormal {
+				continue
+			}
+			results = append(results, types.NodeCondition{
+				Node:    node.Name,
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// unboundClaims returns the persistent volume claims that are not Bound
+// unboundClaims 返回未处于 Bound 状态的 PersistentVolumeClaim
+func unboundClaims(claims []types.PersistentVolumeClaim) []types.PersistentVolumeClaim {
+	var results []types.PersistentVolumeClaim
+	for _, pvc := range claims {
+		if pvc.Status != string(corev1.ClaimBound) {
+			results = append(results, pvc)
+		}
+	}
+	return results
+}
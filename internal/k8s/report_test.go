@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// fakeReportAPIServer 模拟集群报告用到的列表接口，failing 中的路径返回 403
+func fakeReportAPIServer(t *testing.T, clock time.Time, failing ...string) *httptest.Server {
+	t.Helper()
+
+	ready := func(status corev1.ConditionStatus) []corev1.NodeCondition {
+		return []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+	}
+	responses := map[string]interface{}{
+		"/version": version.Info{GitVersion: "v1.28.4", Platform: "linux/amd64"},
+		"/api/v1/namespaces": corev1.NamespaceList{Items: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		}},
+		"/api/v1/nodes": corev1.NodeList{Items: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: corev1.NodeStatus{
+				Conditions: ready(corev1.ConditionTrue),
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.28.4"},
+			}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Status: corev1.NodeStatus{
+				Conditions: append(ready(corev1.ConditionTrue), corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasDiskPressure"}),
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.28.3"},
+			}},
+		}},
+		"/api/v1/pods": corev1.PodList{Items: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+		}},
+		"/apis/apps/v1/deployments": appsv1.DeploymentList{Items: []appsv1.Deployment{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Status: appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 1}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}, Status: appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 1}},
+		}},
+		"/apis/apps/v1/statefulsets": appsv1.StatefulSetList{Items: []appsv1.StatefulSet{
+			{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Status: appsv1.StatefulSetStatus{Replicas: 1, ReadyReplicas: 1}},
+		}},
+		"/api/v1/events": corev1.EventList{Items: []corev1.Event{
+			{ObjectMeta: metav1.ObjectMeta{Name: "e1", Namespace: "default"}, Type: corev1.EventTypeWarning, Reason: "BackOff",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-2"}, LastTimestamp: metav1.NewTime(clock.Add(-10 * time.Minute))},
+			{ObjectMeta: metav1.ObjectMeta{Name: "e2", Namespace: "default"}, Type: corev1.EventTypeWarning, Reason: "FailedMount",
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"}, LastTimestamp: metav1.NewTime(clock.Add(-2 * time.Minute))},
+			{ObjectMeta: metav1.ObjectMeta{Name: "e3", Namespace: "default"}, Type: corev1.EventTypeWarning, Reason: "Old",
+				LastTimestamp: metav1.NewTime(clock.Add(-2 * time.Hour))},
+			{ObjectMeta: metav1.ObjectMeta{Name: "e4", Namespace: "default"}, Type: corev1.EventTypeNormal, Reason: "Scheduled",
+				LastTimestamp: metav1.NewTime(clock.Add(-time.Minute))},
+		}},
+		"/api/v1/persistentvolumeclaims": corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		}},
+	}
+
+	return fakeAPIServer(t, responses, func(w http.ResponseWriter, r *http.Request) bool {
+		for _, path := range failing {
+			if r.URL.Path == path {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonForbidden,
+					Message:  "forbidden",
+					Code:     http.StatusForbidden,
+				})
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// pinClock 将 now 固定为 clock，测试结束后恢复
+func pinClock(t *testing.T, clock time.Time) {
+	t.Helper()
+	previous := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = previous })
+}
+
+// TestGenerateClusterReport 测试报告汇总各部分数据
+func TestGenerateClusterReport(t *testing.T) {
+	clock := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	pinClock(t, clock)
+	ro := newServerOperations(t, fakeReportAPIServer(t, clock))
+
+	report, err := ro.GenerateClusterReport(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GenerateClusterReport failed: %v", err)
+	}
+
+	if report.Cluster != "test" || report.Version != "v1.28.4" || report.GeneratedAt != "2024-05-02T12:00:00Z" {
+		t.Errorf("unexpected overview: %+v", report)
+	}
+	if len(report.Unavailable) != 0 {
+		t.Errorf("expected every section to be available, got %v", report.Unavailable)
+	}
+	if n := report.Nodes; n == nil || n.Total != 2 || n.Ready != 2 || strings.Join(n.Versions, ",") != "v1.28.3,v1.28.4" {
+		t.Errorf("unexpected node summary: %+v", n)
+	}
+	if len(report.NodeConditions) != 1 || report.NodeConditions[0].Node != "node-2" || report.NodeConditions[0].Type != "DiskPressure" {
+		t.Errorf("unexpected node conditions: %+v", report.NodeConditions)
+	}
+	if len(report.Namespaces) != 2 || report.Namespaces[0].Name != "default" || report.Namespaces[0].Pods != 2 || report.Namespaces[0].Deployments != 1 {
+		t.Errorf("unexpected namespaces: %+v", report.Namespaces)
+	}
+	if len(report.UnreadyWorkloads) != 1 || report.UnreadyWorkloads[0].Name != "web" || report.UnreadyWorkloads[0].Ready != "1/2" {
+		t.Errorf("unexpected unready workloads: %+v", report.UnreadyWorkloads)
+	}
+	if report.WarningEventCount != 2 || report.WarningEvents[0].Reason != "FailedMount" || report.WarningEvents[1].Object != "Pod/web-2" {
+		t.Errorf("unexpected warning events: %d %+v", report.WarningEventCount, report.WarningEvents)
+	}
+	if len(report.VolumeClaims) != 1 || report.VolumeClaims[0].Name != "data" || report.VolumeClaims[0].Status != "Pending" {
+		t.Errorf("unexpected volume claims: %+v", report.VolumeClaims)
+	}
+}
+
+// TestGenerateClusterReportPartialFailure 测试单个查询失败时只标记对应部分不可用
+func TestGenerateClusterReportPartialFailure(t *testing.T) {
+	clock := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	pinClock(t, clock)
+	ro := newServerOperations(t, fakeReportAPIServer(t, clock, "/api/v1/events"))
+
+	report, err := ro.GenerateClusterReport(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("GenerateClusterReport failed: %v", err)
+	}
+
+	if reason := report.Unavailable[ReportSectionEvents]; !strings.HasPrefix(reason, "section unavailable: ") || !strings.Contains(reason, "forbidden") {
+		t.Errorf("unexpected events reason: %q", reason)
+	}
+	if len(report.Unavailable) != 1 {
+		t.Errorf("expected only the events section to be unavailable, got %v", report.Unavailable)
+	}
+	if report.WarningEventCount != 0 || len(report.Namespaces) != 2 || report.Nodes == nil {
+		t.Errorf("expected the other sections to be filled in: %+v", report)
+	}
+
+	if _, err := ro.GenerateClusterReport(context.Background(), "missing"); err == nil {
+		t.Errorf("expected an error for an unknown cluster")
+	}
+}
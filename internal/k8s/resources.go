@@ -1,17 +1,23 @@
 package k8s
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/AceDarkknight/k8s-mcp/pkg/types"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
 )
 
 // ResourceType represents supported k8s resource types
@@ -36,15 +42,54 @@ const (
 	ResourceTypeEvent        ResourceType = "event"
 	ResourceTypeStatefulSets ResourceType = "statefulsets"
 	ResourceTypeStatefulSet  ResourceType = "statefulset"
+	ResourceTypeDaemonSets   ResourceType = "daemonsets"
+	ResourceTypeDaemonSet    ResourceType = "daemonset"
+
+	ResourceTypePersistentVolumes      ResourceType = "persistentvolumes"
+	ResourceTypePersistentVolume       ResourceType = "persistentvolume"
+	ResourceTypePersistentVolumeClaims ResourceType = "persistentvolumeclaims"
+	ResourceTypePersistentVolumeClaim  ResourceType = "persistentvolumeclaim"
+
+	ResourceTypeIngresses       ResourceType = "ingresses"
+	ResourceTypeIngress         ResourceType = "ingress"
+	ResourceTypeNetworkPolicies ResourceType = "networkpolicies"
+	ResourceTypeNetworkPolicy   ResourceType = "networkpolicy"
+
+	ResourceTypeHorizontalPodAutoscalers ResourceType = "horizontalpodautoscalers"
+	ResourceTypeHorizontalPodAutoscaler  ResourceType = "horizontalpodautoscaler"
+	ResourceTypePodDisruptionBudgets     ResourceType = "poddisruptionbudgets"
+	ResourceTypePodDisruptionBudget      ResourceType = "poddisruptionbudget"
+
+	ResourceTypeCronJobs ResourceType = "cronjobs"
+	ResourceTypeCronJob  ResourceType = "cronjob"
+	ResourceTypeJobs     ResourceType = "jobs"
+	ResourceTypeJob      ResourceType = "job"
 )
 
+// IsClusterScoped reports whether a resource type is not namespaced
+// IsClusterScoped 判断资源类型是否为集群级（不属于命名空间）
+func IsClusterScoped(resourceType ResourceType) bool {
+	switch resourceType {
+	case ResourceTypeNamespaces, ResourceTypeNamespace, ResourceTypeNodes, ResourceTypeNode,
+		ResourceTypePersistentVolumes, ResourceTypePersistentVolume:
+		return true
+	}
+	kind, ok := LookupDistroKind(resourceType)
+	return ok && kind.ClusterScoped
+}
+
 // ResourceInfo holds basic information about a k8s resource
 type ResourceInfo struct {
-	Name      string            `json:"name"`
-	Namespace string            `json:"namespace,omitempty"`
-	Kind      string            `json:"kind"`
-	Status    string            `json:"status,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status,omitempty"`
+	Restarts  int    `json:"restarts,omitempty"`
+	// Owner is the controller of a pod as kind/name, a ReplicaSet resolved to its Deployment
+	Owner     string            `json:"owner,omitempty"`
+	OwnerKind string            `json:"owner_kind,omitempty"`
 	Age       string            `json:"age,omitempty"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
@@ -60,9 +105,9 @@ func NewResourceOperations(cm *ClusterManager) *ResourceOperations {
 	}
 }
 
-// ListNamespaces lists all namespaces in current cluster
+// ListNamespaces lists all namespaces in current cluster (only the allowed ones in namespace-scoped mode)
 func (ro *ResourceOperations) ListNamespaces(ctx context.Context, clusterName string) ([]types.Namespace, error) {
-	var client *kubernetes.Clientset
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -74,26 +119,150 @@ func (ro *ResourceOperations) ListNamespaces(ctx context.Context, clusterName st
 		return nil, err
 	}
 
+	// In namespace-scoped mode the list is filtered down to the allowed namespaces
+	// 命名空间受限模式下，列表被过滤为允许的命名空间
+	policy := ro.clusterManager.namespacePolicy
+	if policy.Restricted() {
+		ctx = withNamespaceEnumeration(ctx)
+	}
+
 	namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
+		// Users of OpenShift may usually list their projects but not the namespaces
+		// OpenShift 用户通常可以列出自己的 Project，但无权列出命名空间
+		if apierrors.IsForbidden(err) && !policy.Restricted() {
+			if projects, served, projectErr := ro.listNamespaceKinds(ctx, clusterName); served && projectErr == nil {
+				return projects, nil
+			}
+		}
 		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
 
 	var results []types.Namespace
 	for _, ns := range namespaces.Items {
+		if !policy.Allows(ns.Name) {
+			continue
+		}
 		results = append(results, types.Namespace{
-			Name:   ns.Name,
-			Status: string(ns.Status.Phase),
-			Age:    ns.CreationTimestamp.String(),
+			Name:      ns.Name,
+			Status:    string(ns.Status.Phase),
+			Age:       formatAge(ns.CreationTimestamp),
+			CreatedAt: formatTimestamp(ns.CreationTimestamp),
+			Labels:    ns.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// ListResourceQuotas lists resource quotas in a namespace (all namespaces if namespace is empty)
+// ListResourceQuotas 列出命名空间中的 ResourceQuota（namespace 为空时列出所有命名空间）
+func (ro *ResourceOperations) ListResourceQuotas(ctx context.Context, namespace, clusterName string) ([]types.ResourceQuota, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.ResourceQuota, error) {
+			return ro.ListResourceQuotas(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	var results []types.ResourceQuota
+	for _, quota := range quotas.Items {
+		results = append(results, types.ResourceQuota{
+			Name:      quota.Name,
+			Namespace: quota.Namespace,
+			Hard:      formatResourceList(quota.Status.Hard),
+			Used:      formatResourceList(quota.Status.Used),
+		})
+	}
+
+	return results, nil
+}
+
+// ListLimitRanges lists limit ranges in a namespace (all namespaces if namespace is empty)
+// ListLimitRanges 列出命名空间中的 LimitRange（namespace 为空时列出所有命名空间）
+func (ro *ResourceOperations) ListLimitRanges(ctx context.Context, namespace, clusterName string) ([]types.LimitRange, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.LimitRange, error) {
+			return ro.ListLimitRanges(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	limitRanges, err := client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+
+	var results []types.LimitRange
+	for _, lr := range limitRanges.Items {
+		var items []types.LimitRangeItem
+		for _, limit := range lr.Spec.Limits {
+			items = append(items, types.LimitRangeItem{
+				Type:           string(limit.Type),
+				Max:            formatResourceList(limit.Max),
+				Min:            formatResourceList(limit.Min),
+				Default:        formatResourceList(limit.Default),
+				DefaultRequest: formatResourceList(limit.DefaultRequest),
+			})
+		}
+		results = append(results, types.LimitRange{
+			Name:      lr.Name,
+			Namespace: lr.Namespace,
+			Limits:    items,
 		})
 	}
 
 	return results, nil
 }
 
+// formatResourceList 将 ResourceList 转换为字符串映射
+func formatResourceList(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(list))
+	for name, quantity := range list {
+		result[string(name)] = quantity.String()
+	}
+	return result
+}
+
 // ListPods lists pods in a namespace
 func (ro *ResourceOperations) ListPods(ctx context.Context, namespace, clusterName string) ([]types.Pod, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Pod, error) {
+			return ro.ListPods(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -110,12 +279,17 @@ func (ro *ResourceOperations) ListPods(ctx context.Context, namespace, clusterNa
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
+	// Resolve ReplicaSets to their Deployment with one list for all pods
+	// 通过一次列表请求为所有 Pod 将 ReplicaSet 解析为其 Deployment
+	deployments := replicaSetDeployments(ctx, client, namespace, pods.Items)
+
 	var results []types.Pod
 	for _, pod := range pods.Items {
 		// 计算 Ready 状态
 		ready := calculatePodReady(&pod)
 		// 计算重启次数
 		restarts := calculatePodRestarts(&pod)
+		ownerKind, ownerName := podOwner(&pod, deployments)
 
 		results = append(results, types.Pod{
 			Name:      pod.Name,
@@ -123,7 +297,10 @@ func (ro *ResourceOperations) ListPods(ctx context.Context, namespace, clusterNa
 			Status:    getPodStatus(&pod),
 			Ready:     ready,
 			Restarts:  restarts,
-			Age:       pod.CreationTimestamp.String(),
+			Owner:     formatPodOwner(ownerKind, ownerName),
+			OwnerKind: ownerKind,
+			Age:       formatAge(pod.CreationTimestamp),
+			CreatedAt: formatTimestamp(pod.CreationTimestamp),
 			Labels:    pod.Labels,
 		})
 	}
@@ -145,9 +322,12 @@ func calculatePodReady(pod *corev1.Pod) string {
 	return fmt.Sprintf("%d/%d", readyContainers, totalContainers)
 }
 
-// calculatePodRestarts 计算 Pod 的重启次数
+// calculatePodRestarts 计算 Pod 的重启次数（包括 init 容器），与 kubectl get pods 一致
 func calculatePodRestarts(pod *corev1.Pod) int {
 	restarts := 0
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		restarts += int(containerStatus.RestartCount)
+	}
 	for _, containerStatus := range pod.Status.ContainerStatuses {
 		restarts += int(containerStatus.RestartCount)
 	}
@@ -157,9 +337,12 @@ func calculatePodRestarts(pod *corev1.Pod) int {
 // getPodStatus calculates a high-level status for a pod, similar to kubectl
 // getPodStatus 计算Pod的高级状态，类似于kubectl
 func getPodStatus(pod *corev1.Pod) string {
-	// 1. Check if pod is being deleted
-	// 1. 检查 Pod 是否正在删除
+	// 1. Check if pod is being deleted; pods on an unreachable node show as Unknown
+	// 1. 检查 Pod 是否正在删除，节点失联的 Pod 显示为 Unknown
 	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			return "Unknown"
+		}
 		return "Terminating"
 	}
 
@@ -173,54 +356,88 @@ func getPodStatus(pod *corev1.Pod) string {
 	// 3. 检查 Init 容器
 	// Init containers run before main containers and are used for setup tasks
 	// Init 容器在主容器之前运行，用于设置任务
-	for _, containerStatus := range pod.Status.InitContainerStatuses {
-		// Check if init container terminated with error
-		// 检查 init 容器是否以错误状态终止
-		if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode != 0 {
-			if containerStatus.State.Terminated.Reason != "" {
-				return "Init:" + containerStatus.State.Terminated.Reason
-			}
-			return "Init:Error"
-		}
-		// Check if init container is waiting with a reason
-		// 检查 init 容器是否因某个原因在等待
-		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" {
+	for i, containerStatus := range pod.Status.InitContainerStatuses {
+		state := containerStatus.State
+		switch {
+		case state.Terminated != nil && state.Terminated.ExitCode == 0:
+			// Finished successfully, check the next one
+			// 已成功完成，检查下一个
+			continue
+		case state.Terminated != nil:
+			// Init container terminated with error
+			// init 容器以错误状态终止
+			return "Init:" + terminatedReason(state.Terminated)
+		case state.Waiting != nil && state.Waiting.Reason != "" && state.Waiting.Reason != "PodInitializing":
 			// PodInitializing is a normal transient state, don't report it
 			// PodInitializing 是一个正常的瞬态，不报告它
-			if containerStatus.State.Waiting.Reason != "PodInitializing" {
-				return "Init:" + containerStatus.State.Waiting.Reason
-			}
+			return "Init:" + state.Waiting.Reason
+		default:
+			// Still initializing, show progress like kubectl (Init:1/3)
+			// 仍在初始化，像 kubectl 一样显示进度（Init:1/3）
+			return fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
 		}
 	}
 
 	// 4. Check Containers
 	// 4. 检查主容器
-	// Priority: Waiting (CrashLoopBackOff etc.) > Terminated (Error) > Running
-	// 优先级：等待（如 CrashLoopBackOff）> 终止（错误）> 运行
+	// Priority: Waiting (CrashLoopBackOff etc.) > Terminated with error (OOMKilled, Error) > Completed
+	// 优先级：等待（如 CrashLoopBackOff）> 错误终止（OOMKilled、Error）> 正常完成
+	var waiting, failed, completed string
+	hasRunning := false
 	for _, containerStatus := range pod.Status.ContainerStatuses {
-		// Check if container is waiting with a reason (highest priority)
-		// 检查容器是否因某个原因在等待（最高优先级）
-		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" {
-			return containerStatus.State.Waiting.Reason
-		}
-		// Check if container terminated with error (second priority)
-		// 检查容器是否以错误状态终止（第二优先级）
-		if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode != 0 {
-			if containerStatus.State.Terminated.Reason != "" {
-				return containerStatus.State.Terminated.Reason
+		state := containerStatus.State
+		switch {
+		case state.Waiting != nil && state.Waiting.Reason != "":
+			if waiting == "" {
+				waiting = state.Waiting.Reason
 			}
-			return "Error"
+		case state.Terminated != nil && state.Terminated.ExitCode != 0:
+			if failed == "" {
+				failed = terminatedReason(state.Terminated)
+			}
+		case state.Terminated != nil:
+			if completed == "" {
+				completed = terminatedReason(state.Terminated)
+			}
+		case state.Running != nil:
+			hasRunning = true
 		}
 	}
+	switch {
+	case waiting != "":
+		return waiting
+	case failed != "":
+		return failed
+	case completed != "" && !hasRunning:
+		return completed
+	}
 
 	// 5. If everything looks fine, return the Phase (Running, Pending, Succeeded)
 	// 5. 如果一切看起来正常，返回 Phase（Running, Pending, Succeeded）
 	return string(pod.Status.Phase)
 }
 
+// terminatedReason returns the reason of a terminated container, falling back to the signal or exit code
+// terminatedReason 返回已终止容器的原因，没有原因时使用信号或退出码
+func terminatedReason(terminated *corev1.ContainerStateTerminated) string {
+	if terminated.Reason != "" {
+		return terminated.Reason
+	}
+	if terminated.Signal != 0 {
+		return fmt.Sprintf("Signal:%d", terminated.Signal)
+	}
+	return fmt.Sprintf("ExitCode:%d", terminated.ExitCode)
+}
+
 // ListServices lists services in a namespace
 func (ro *ResourceOperations) ListServices(ctx context.Context, namespace, clusterName string) ([]types.Service, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Service, error) {
+			return ro.ListServices(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -248,7 +465,8 @@ func (ro *ResourceOperations) ListServices(ctx context.Context, namespace, clust
 			Type:      string(svc.Spec.Type),
 			ClusterIP: svc.Spec.ClusterIP,
 			Ports:     ports,
-			Age:       svc.CreationTimestamp.String(),
+			Age:       formatAge(svc.CreationTimestamp),
+			CreatedAt: formatTimestamp(svc.CreationTimestamp),
 			Labels:    svc.Labels,
 		})
 	}
@@ -270,7 +488,13 @@ func formatServicePorts(ports []corev1.ServicePort) string {
 
 // ListDeployments lists deployments in a namespace
 func (ro *ResourceOperations) ListDeployments(ctx context.Context, namespace, clusterName string) ([]types.Deployment, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Deployment, error) {
+			return ro.ListDeployments(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -299,7 +523,8 @@ func (ro *ResourceOperations) ListDeployments(ctx context.Context, namespace, cl
 			Ready:     ready,
 			UpToDate:  upToDate,
 			Available: available,
-			Age:       dep.CreationTimestamp.String(),
+			Age:       formatAge(dep.CreationTimestamp),
+			CreatedAt: formatTimestamp(dep.CreationTimestamp),
 			Labels:    dep.Labels,
 		})
 	}
@@ -309,7 +534,7 @@ func (ro *ResourceOperations) ListDeployments(ctx context.Context, namespace, cl
 
 // GetResourceDetails gets detailed information about a specific resource
 func (ro *ResourceOperations) GetResourceDetails(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string) (interface{}, error) {
-	var client *kubernetes.Clientset
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -336,7 +561,26 @@ func (ro *ResourceOperations) GetResourceDetails(ctx context.Context, resourceTy
 		return client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
 	case ResourceTypeNodes, ResourceTypeNode:
 		return client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypePersistentVolumes, ResourceTypePersistentVolume:
+		return client.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypePersistentVolumeClaims, ResourceTypePersistentVolumeClaim:
+		return client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypeIngresses, ResourceTypeIngress:
+		return client.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypeNetworkPolicies, ResourceTypeNetworkPolicy:
+		return client.NetworkingV1().NetworkPolicies(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypeHorizontalPodAutoscalers, ResourceTypeHorizontalPodAutoscaler:
+		return client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypePodDisruptionBudgets, ResourceTypePodDisruptionBudget:
+		return client.PolicyV1().PodDisruptionBudgets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypeCronJobs, ResourceTypeCronJob:
+		return client.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	case ResourceTypeJobs, ResourceTypeJob:
+		return client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
 	default:
+		if kind, ok := LookupDistroKind(resourceType); ok {
+			return ro.GetDistroResource(ctx, kind, namespace, name, clusterName)
+		}
 		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
 	}
 }
@@ -362,14 +606,39 @@ func (ro *ResourceOperations) ListResourcesByType(ctx context.Context, resourceT
 		return ro.listEvents(ctx, namespace, clusterName)
 	case ResourceTypeStatefulSets, ResourceTypeStatefulSet:
 		return ro.ListStatefulSets(ctx, namespace, clusterName)
+	case ResourceTypePersistentVolumes, ResourceTypePersistentVolume:
+		return ro.ListPersistentVolumes(ctx, clusterName)
+	case ResourceTypePersistentVolumeClaims, ResourceTypePersistentVolumeClaim:
+		return ro.ListPersistentVolumeClaims(ctx, namespace, clusterName)
+	case ResourceTypeIngresses, ResourceTypeIngress:
+		return ro.ListIngresses(ctx, namespace, clusterName)
+	case ResourceTypeNetworkPolicies, ResourceTypeNetworkPolicy:
+		return ro.ListNetworkPolicies(ctx, namespace, clusterName)
+	case ResourceTypeHorizontalPodAutoscalers, ResourceTypeHorizontalPodAutoscaler:
+		return ro.ListHorizontalPodAutoscalers(ctx, namespace, clusterName)
+	case ResourceTypePodDisruptionBudgets, ResourceTypePodDisruptionBudget:
+		return ro.ListPodDisruptionBudgets(ctx, namespace, clusterName)
+	case ResourceTypeCronJobs, ResourceTypeCronJob:
+		return ro.ListCronJobs(ctx, namespace, clusterName)
+	case ResourceTypeJobs, ResourceTypeJob:
+		return ro.ListJobs(ctx, namespace, clusterName, "")
 	default:
+		if kind, ok := LookupDistroKind(resourceType); ok {
+			return ro.ListDistroResources(ctx, kind, namespace, clusterName)
+		}
 		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
 	}
 }
 
 // ListConfigMaps lists configmaps in a namespace
 func (ro *ResourceOperations) ListConfigMaps(ctx context.Context, namespace, clusterName string) ([]types.ConfigMap, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.ConfigMap, error) {
+			return ro.ListConfigMaps(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -391,8 +660,9 @@ func (ro *ResourceOperations) ListConfigMaps(ctx context.Context, namespace, clu
 		results = append(results, types.ConfigMap{
 			Name:      cm.Name,
 			Namespace: cm.Namespace,
-			DataCount: len(cm.Data),
-			Age:       cm.CreationTimestamp.String(),
+			DataCount: len(cm.Data) + len(cm.BinaryData),
+			Age:       formatAge(cm.CreationTimestamp),
+			CreatedAt: formatTimestamp(cm.CreationTimestamp),
 			Labels:    cm.Labels,
 		})
 	}
@@ -402,7 +672,13 @@ func (ro *ResourceOperations) ListConfigMaps(ctx context.Context, namespace, clu
 
 // listSecrets lists secrets in a namespace
 func (ro *ResourceOperations) listSecrets(ctx context.Context, namespace, clusterName string) ([]ResourceInfo, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]ResourceInfo, error) {
+			return ro.listSecrets(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -426,7 +702,8 @@ func (ro *ResourceOperations) listSecrets(ctx context.Context, namespace, cluste
 			Namespace: secret.Namespace,
 			Kind:      "Secret",
 			Status:    fmt.Sprintf("Type: %s", secret.Type),
-			Age:       secret.CreationTimestamp.String(),
+			Age:       formatAge(secret.CreationTimestamp),
+			CreatedAt: formatTimestamp(secret.CreationTimestamp),
 			Labels:    secret.Labels,
 		})
 	}
@@ -436,7 +713,7 @@ func (ro *ResourceOperations) listSecrets(ctx context.Context, namespace, cluste
 
 // listNodes lists nodes in cluster
 func (ro *ResourceOperations) listNodes(ctx context.Context, clusterName string) ([]types.Node, error) {
-	var client *kubernetes.Clientset
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -471,12 +748,13 @@ func (ro *ResourceOperations) listNodes(ctx context.Context, clusterName string)
 		roles := extractNodeRoles(&node)
 
 		results = append(results, types.Node{
-			Name:    node.Name,
-			Status:  status,
-			Roles:   roles,
-			Version: node.Status.NodeInfo.KubeletVersion,
-			Age:     node.CreationTimestamp.String(),
-			Labels:  node.Labels,
+			Name:      node.Name,
+			Status:    status,
+			Roles:     roles,
+			Version:   node.Status.NodeInfo.KubeletVersion,
+			Age:       formatAge(node.CreationTimestamp),
+			CreatedAt: formatTimestamp(node.CreationTimestamp),
+			Labels:    node.Labels,
 		})
 	}
 
@@ -495,7 +773,7 @@ func extractNodeRoles(node *corev1.Node) string {
 		}
 		if strings.HasPrefix(k, "node-role.kubernetes.io/") {
 			role := strings.TrimPrefix(k, "node-role.kubernetes.io/")
-			if role != "" && role != "master" && role != "control-plane" {
+			if role != "" && role != "master" && role != "control-plane" && role != "worker" && role != "compute" {
 				roles = append(roles, role)
 			}
 		}
@@ -506,9 +784,35 @@ func extractNodeRoles(node *corev1.Node) string {
 	return strings.Join(roles, ",")
 }
 
+// ListEvents lists the events of a namespace (all namespaces if namespace is empty) last
+// seen within window; events without a timestamp are left out of a bounded window
+// ListEvents 列出命名空间（namespace 为空时为所有命名空间）中最后出现时间在 window 内的事件；时间段有边界时不包含没有时间戳的事件
+func (ro *ResourceOperations) ListEvents(ctx context.Context, namespace string, window TimeWindow, clusterName string) ([]types.Event, error) {
+	events, err := ro.listEvents(ctx, namespace, clusterName)
+	if err != nil || window.IsZero() {
+		return events, err
+	}
+
+	var results []types.Event
+	for _, event := range events {
+		lastSeen, err := time.Parse(time.RFC3339, event.LastTimestamp)
+		if err != nil || !window.Contains(lastSeen) {
+			continue
+		}
+		results = append(results, event)
+	}
+	return results, nil
+}
+
 // listEvents lists events in a namespace
 func (ro *ResourceOperations) listEvents(ctx context.Context, namespace, clusterName string) ([]types.Event, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.Event, error) {
+			return ro.listEvents(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -527,24 +831,45 @@ func (ro *ResourceOperations) listEvents(ctx context.Context, namespace, cluster
 
 	var results []types.Event
 	for _, event := range events.Items {
-		results = append(results, types.Event{
-			Type:      event.Type,
-			Reason:    event.Reason,
-			Message:   event.Message,
-			Source:    event.Source.Component,
-			Count:     int(event.Count),
-			FirstSeen: event.FirstTimestamp.String(),
-			LastSeen:  event.LastTimestamp.String(),
-			Labels:    event.Labels,
-		})
+		results = append(results, toEvent(event))
 	}
 
 	return results, nil
 }
 
-// GetSupportedResourceTypes returns all supported resource types
+// eventLastSeen returns when an event was last seen; events created through the
+// events.k8s.io API only set EventTime
+// eventLastSeen 返回事件最后出现的时间，通过 events.k8s.io API 创建的事件只设置了 EventTime
+func eventLastSeen(event corev1.Event) metav1.Time {
+	if event.LastTimestamp.IsZero() {
+		return metav1.NewTime(event.EventTime.Time)
+	}
+	return event.LastTimestamp
+}
+
+// toEvent converts a core event to its list_events form
+// toEvent 将 core 事件转换为 list_events 的形式
+func toEvent(event corev1.Event) types.Event {
+	lastSeen := eventLastSeen(event)
+	return types.Event{
+		Type:          event.Type,
+		Namespace:     event.Namespace,
+		Object:        event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Reason:        event.Reason,
+		Message:       event.Message,
+		Source:        event.Source.Component,
+		Count:         int(event.Count),
+		FirstSeen:     formatAge(event.FirstTimestamp),
+		LastSeen:      formatAge(lastSeen),
+		LastTimestamp: formatTimestamp(lastSeen),
+		Labels:        event.Labels,
+	}
+}
+
+// GetSupportedResourceTypes returns all supported resource types, including the
+// distribution-specific ones that only work on clusters serving them
 func (ro *ResourceOperations) GetSupportedResourceTypes() []ResourceType {
-	return []ResourceType{
+	resourceTypes := []ResourceType{
 		ResourceTypePods,
 		ResourceTypePod,
 		ResourceTypeServices,
@@ -563,27 +888,137 @@ func (ro *ResourceOperations) GetSupportedResourceTypes() []ResourceType {
 		ResourceTypeEvent,
 		ResourceTypeStatefulSets,
 		ResourceTypeStatefulSet,
+		ResourceTypePersistentVolumes,
+		ResourceTypePersistentVolume,
+		ResourceTypePersistentVolumeClaims,
+		ResourceTypePersistentVolumeClaim,
+		ResourceTypeIngresses,
+		ResourceTypeIngress,
+		ResourceTypeNetworkPolicies,
+		ResourceTypeNetworkPolicy,
+		ResourceTypeHorizontalPodAutoscalers,
+		ResourceTypeHorizontalPodAutoscaler,
+		ResourceTypePodDisruptionBudgets,
+		ResourceTypePodDisruptionBudget,
+		ResourceTypeCronJobs,
+		ResourceTypeCronJob,
+		ResourceTypeJobs,
+		ResourceTypeJob,
+	}
+	for _, kind := range distroKinds {
+		resourceTypes = append(resourceTypes, kind.Names...)
+	}
+	return resourceTypes
+}
+
+// IsSupportedResourceType reports whether resourceType is one of GetSupportedResourceTypes
+// IsSupportedResourceType 判断 resourceType 是否属于 GetSupportedResourceTypes
+func IsSupportedResourceType(resourceType ResourceType) bool {
+	for _, supported := range (&ResourceOperations{}).GetSupportedResourceTypes() {
+		if supported == resourceType {
+			return true
+		}
 	}
+	return false
+}
+
+// Output formats supported by SerializeResource
+// SerializeResource 支持的输出格式
+const (
+	OutputFormatJSON = "json"
+	OutputFormatYAML = "yaml"
+)
+
+// SerializeOptions controls how a resource is serialized
+// SerializeOptions 控制资源的序列化方式
+type SerializeOptions struct {
+	// Format is the output format ("json" or "yaml"), defaults to json
+	// Format 输出格式（"json" 或 "yaml"），默认为 json
+	Format string
+	// IncludeManagedFields keeps metadata.managedFields in the output
+	// IncludeManagedFields 在输出中保留 metadata.managedFields
+	IncludeManagedFields bool
+	// IncludeRaw skips SanitizeObject and returns the object as the API server sent it
+	// IncludeRaw 跳过 SanitizeObject，按 API server 返回的原样输出对象
+	IncludeRaw bool
 }
 
-// SerializeResource converts a k8s resource to JSON string
-func (ro *ResourceOperations) SerializeResource(resource interface{}) (string, error) {
-	data, err := json.MarshalIndent(resource, "", "  ")
+// SerializeResource converts a k8s resource to a JSON or YAML string.
+// The object is cleaned with SanitizeObject (keeping metadata.managedFields if
+// opts.IncludeManagedFields is set) unless opts.IncludeRaw is set.
+// A nil opts serializes to JSON with default options.
+func (ro *ResourceOperations) SerializeResource(resource interface{}, opts *SerializeOptions) (string, error) {
+	if opts == nil {
+		opts = &SerializeOptions{}
+	}
+
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = OutputFormatJSON
+	}
+	if format != OutputFormatJSON && format != OutputFormatYAML {
+		return "", fmt.Errorf("unsupported output format: %s (expected json or yaml)", opts.Format)
+	}
+
+	// Fill in apiVersion/kind so the output can be fed back to kubectl apply
+	// 填充 apiVersion/kind，使输出可以直接用于 kubectl apply
+	if obj, ok := resource.(runtime.Object); ok {
+		setTypeMeta(obj)
+	}
+
+	// Round-trip through a generic map so fields can be stripped regardless of type
+	// 通过通用 map 中转，以便与具体类型无关地删除字段
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize resource: %w", err)
+	}
+	var obj interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return "", fmt.Errorf("failed to serialize resource: %w", err)
+	}
+
+	if m, ok := obj.(map[string]interface{}); ok && !opts.IncludeRaw {
+		SanitizeObject(m, opts.IncludeManagedFields)
+	}
+
+	if format == OutputFormatYAML {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize resource to yaml: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := json.MarshalIndent(obj, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to serialize resource: %w", err)
 	}
 	return string(data), nil
 }
 
+// setTypeMeta sets apiVersion/kind on typed objects returned by the clientset,
+// which leaves TypeMeta empty.
+// setTypeMeta 为 clientset 返回的类型化对象设置 apiVersion/kind（clientset 不会填充 TypeMeta）。
+func setTypeMeta(obj runtime.Object) {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+}
+
 // DescribeResource provides detailed description of a resource
-func (ro *ResourceOperations) DescribeResource(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string) (string, error) {
+func (ro *ResourceOperations) DescribeResource(ctx context.Context, resourceType ResourceType, namespace, name, clusterName string, opts *SerializeOptions) (string, error) {
 	resource, err := ro.GetResourceDetails(ctx, resourceType, namespace, name, clusterName)
 	if err != nil {
 		return "", err
 	}
 
 	// Convert to JSON for detailed description
-	jsonStr, err := ro.SerializeResource(resource)
+	jsonStr, err := ro.SerializeResource(resource, opts)
 	if err != nil {
 		return "", err
 	}
@@ -591,9 +1026,13 @@ func (ro *ResourceOperations) DescribeResource(ctx context.Context, resourceType
 	return jsonStr, nil
 }
 
-// GetClusterInfo gets basic cluster information
+// GetClusterInfo gets basic cluster information. The server version is required; the
+// other fields come from optional queries run concurrently under clusterInfoTimeout, and
+// a failed query is listed under "unavailable" instead of failing the call
+// GetClusterInfo 获取集群基本信息。服务器版本是必需的；其他字段来自在 clusterInfoTimeout 内并发执行的可选查询，
+// 失败的查询列在 "unavailable" 中，不会导致整个调用失败
 func (ro *ResourceOperations) GetClusterInfo(ctx context.Context, clusterName string) (map[string]interface{}, error) {
-	var client *kubernetes.Clientset
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -611,33 +1050,87 @@ func (ro *ResourceOperations) GetClusterInfo(ctx context.Context, clusterName st
 		return nil, fmt.Errorf("failed to get server version: %w", err)
 	}
 
-	// Get nodes for basic cluster info
-	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	info := map[string]interface{}{
+		"version":   version.GitVersion,
+		"platform":  version.Platform,
+		"buildDate": version.BuildDate,
 	}
 
-	// Get namespaces count
-	namespaces, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %w", err)
-	}
+	unavailable := runClusterInfoQueries(ctx, ro.clusterInfoQueries(client, clusterName, version.GitVersion), info)
 
-	info := map[string]interface{}{
-		"version":        version.GitVersion,
-		"platform":       version.Platform,
-		"nodeCount":      len(nodes.Items),
-		"namespaceCount": len(namespaces.Items),
-		"buildDate":      version.BuildDate,
+	// The control plane endpoint is only known for clusters loaded from a REST config
+	// 只有通过 REST 配置加载的集群才知道控制平面地址
+	configName := clusterName
+	if configName == "" {
+		configName = ro.clusterManager.GetCurrentCluster()
+	}
+	if config, exists := ro.clusterManager.configs[configName]; exists {
+		info["endpoint"] = config.Host
+	} else {
+		unavailable["endpoint"] = "unavailable: no REST config for cluster " + configName
 	}
 
+	if len(unavailable) > 0 {
+		info["unavailable"] = unavailable
+	}
 	return info, nil
 }
 
+// PodLogOptions selects the log lines GetPodLogs returns
+// PodLogOptions 选择 GetPodLogs 返回的日志行
+type PodLogOptions struct {
+	// Container defaults to the pod's first container
+	// Container 默认为 Pod 的第一个容器
+	Container string
+	// TailLines limits the output to the last lines; nil means 100 unless SinceTime is set
+	// TailLines 只返回最后若干行；为 nil 时默认 100 行，设置了 SinceTime 时不限制
+	TailLines *int64
+	// Previous returns the logs of the previous container instance
+	// Previous 返回前一个容器实例的日志
+	Previous bool
+	// SinceTime returns only lines logged at or after this time; the API server truncates it to seconds
+	// SinceTime 只返回该时间及之后的日志行，API server 会将其截断到秒
+	SinceTime *time.Time
+	// SinceSeconds returns only lines logged in the last seconds by the node's clock
+	// SinceSeconds 只返回按节点时钟计算的最近若干秒内的日志行
+	SinceSeconds *int64
+	// Until drops the lines logged after this time. The API has no such option, so the
+	// lines are read with timestamps and reading stops at the first later line; TailLines
+	// then counts back from Until rather than from the end of the log
+	// Until 丢弃该时间之后的日志行。API 没有此选项，因此读取带时间戳的日志行并在遇到第一行更晚的日志时停止；
+	// 此时 TailLines 从 Until 往前计算，而不是从日志末尾
+	Until *time.Time
+	// Timestamps prefixes every line with its RFC3339Nano timestamp
+	// Timestamps 在每行前加上 RFC3339Nano 时间戳
+	Timestamps bool
+}
+
+// ApplyWindow selects the lines logged within a time window: a relative since becomes
+// SinceSeconds, an absolute one SinceTime, and until becomes Until
+// ApplyWindow 选择时间段内的日志行：相对的 since 转换为 SinceSeconds，绝对的 since 转换为 SinceTime，until 转换为 Until
+func (opts *PodLogOptions) ApplyWindow(window TimeWindow) {
+	switch {
+	case window.SinceDuration > 0:
+		seconds := int64((window.SinceDuration + time.Second - 1) / time.Second)
+		opts.SinceSeconds = &seconds
+	case !window.Since.IsZero():
+		since := window.Since
+		opts.SinceTime = &since
+	}
+	if !window.Until.IsZero() {
+		until := window.Until
+		opts.Until = &until
+	}
+}
+
+// maxLogBytes caps the logs GetPodLogs returns
+// maxLogBytes 限制 GetPodLogs 返回的日志大小
+const maxLogBytes = 1 * 1024 * 1024 // 1MB
+
 // GetPodLogs retrieves logs from a pod
 // GetPodLogs 从 Pod 获取日志
-func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName, containerName string, tailLines *int64, previous bool, clusterName string) (string, error) {
-	var client *kubernetes.Clientset
+func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName string, opts PodLogOptions, clusterName string) (string, error) {
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -651,13 +1144,15 @@ func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName
 
 	// Default tail lines to 100 if not specified
 	// 如果未指定，默认 tail lines 为 100
-	if tailLines == nil {
+	tailLines := opts.TailLines
+	if tailLines == nil && opts.SinceTime == nil && opts.SinceSeconds == nil {
 		defaultLines := int64(100)
 		tailLines = &defaultLines
 	}
 
 	// Get pod to determine container name if not specified
 	// 如果未指定容器名称，获取 Pod 以确定容器名称
+	containerName := opts.Container
 	if containerName == "" {
 		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 		if err != nil {
@@ -673,9 +1168,21 @@ func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName
 	// Create log request options
 	// 创建日志请求选项
 	logOptions := &corev1.PodLogOptions{
-		Container: containerName,
-		TailLines: tailLines,
-		Previous:  previous,
+		Container:    containerName,
+		TailLines:    tailLines,
+		Previous:     opts.Previous,
+		Timestamps:   opts.Timestamps,
+		SinceSeconds: opts.SinceSeconds,
+	}
+	if opts.SinceTime != nil {
+		sinceTime := metav1.NewTime(*opts.SinceTime)
+		logOptions.SinceTime = &sinceTime
+	}
+	// The tail of the lines before Until is taken after reading them
+	// Until 之前的日志行读取后再取最后若干行
+	if opts.Until != nil {
+		logOptions.TailLines = nil
+		logOptions.Timestamps = true
 	}
 
 	// Get logs as a stream
@@ -687,10 +1194,13 @@ func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName
 	}
 	defer logStream.Close()
 
+	if opts.Until != nil {
+		return readLogsUntil(logStream, *opts.Until, tailLines, opts.Timestamps)
+	}
+
 	// Read logs with a limit to prevent memory issues
 	// 读取日志并限制大小以防止内存问题
-	const maxBytes = 1 * 1024 * 1024 // 1MB
-	limitedReader := io.LimitReader(logStream, maxBytes)
+	limitedReader := io.LimitReader(logStream, maxLogBytes)
 	logBytes, err := io.ReadAll(limitedReader)
 	if err != nil {
 		return "", fmt.Errorf("failed to read logs: %w", err)
@@ -700,20 +1210,80 @@ func (ro *ResourceOperations) GetPodLogs(ctx context.Context, namespace, podName
 
 	// Check if logs were truncated
 	// 检查日志是否被截断
-	if int64(len(logBytes)) >= maxBytes {
-		logs += "\n\n[Logs truncated: exceeded 1MB limit]"
+	if int64(len(logBytes)) >= maxLogBytes {
+		logs += logsTruncatedNote
 	}
 
 	return logs, nil
 }
 
+// logsTruncatedNote is appended to logs cut at maxLogBytes
+// logsTruncatedNote 追加在超过 maxLogBytes 被截断的日志之后
+const logsTruncatedNote = "\n\n[Logs truncated: exceeded 1MB limit]"
+
+// readLogsUntil reads timestamped log lines until the first one logged after until,
+// keeps the last tail lines when tail is set and strips the timestamps unless
+// keepTimestamps is set. Like the unfiltered read, the output is capped at maxLogBytes
+// readLogsUntil 读取带时间戳的日志行，直到遇到第一行晚于 until 的日志；设置了 tail 时只保留最后 tail 行，
+// 未设置 keepTimestamps 时去掉时间戳。与不过滤时一样，输出限制为 maxLogBytes
+func readLogsUntil(r io.Reader, until time.Time, tail *int64, keepTimestamps bool) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogBytes)
+
+	var lines []string
+	size := 0
+	truncated := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		stamp, rest, _ := strings.Cut(line, " ")
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			if t.After(until) {
+				break
+			}
+			if !keepTimestamps {
+				line = rest
+			}
+		}
+
+		lines = append(lines, line)
+		size += len(line) + 1
+		if tail != nil && int64(len(lines)) > *tail {
+			size -= len(lines[0]) + 1
+			lines = lines[1:]
+		}
+		if tail == nil && size >= maxLogBytes {
+			truncated = true
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	logs := strings.Join(lines, "\n") + "\n"
+	if len(logs) > maxLogBytes {
+		logs, truncated = logs[:maxLogBytes], true
+	}
+	if truncated {
+		logs += logsTruncatedNote
+	}
+	return logs, nil
+}
+
 // CheckRBACPermission checks if the current user has permission to perform an action
 // CheckRBACPermission 检查当前用户是否有权限执行某个操作
-func (ro *ResourceOperations) CheckRBACPermission(ctx context.Context, verb, resource, namespace string) (bool, error) {
-	var client *kubernetes.Clientset
+func (ro *ResourceOperations) CheckRBACPermission(ctx context.Context, verb, resource, namespace, clusterName string) (bool, error) {
+	var client kubernetes.Interface
 	var err error
 
-	client, err = ro.clusterManager.GetCurrentClient()
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
 	if err != nil {
 		return false, err
 	}
@@ -742,7 +1312,13 @@ func (ro *ResourceOperations) CheckRBACPermission(ctx context.Context, verb, res
 
 // ListStatefulSets lists statefulsets in a namespace
 func (ro *ResourceOperations) ListStatefulSets(ctx context.Context, namespace, clusterName string) ([]types.StatefulSet, error) {
-	var client *kubernetes.Clientset
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.StatefulSet, error) {
+			return ro.ListStatefulSets(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
 	var err error
 
 	if clusterName != "" {
@@ -767,7 +1343,8 @@ func (ro *ResourceOperations) ListStatefulSets(ctx context.Context, namespace, c
 			Name:      ss.Name,
 			Namespace: ss.Namespace,
 			Ready:     ready,
-			Age:       ss.CreationTimestamp.String(),
+			Age:       formatAge(ss.CreationTimestamp),
+			CreatedAt: formatTimestamp(ss.CreationTimestamp),
 			Labels:    ss.Labels,
 		})
 	}
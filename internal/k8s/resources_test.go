@@ -0,0 +1,673 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden 将输出与 testdata 中的 golden 文件比较，-update 时重写文件
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %v", path, err)
+	}
+	if string(want) != got {
+		t.Errorf("output does not match %s:\n--- want ---\n%s\n--- got ---\n%s", path, want, got)
+	}
+}
+
+// testCreationTime 测试对象使用的固定创建时间
+var testCreationTime = metav1.NewTime(time.Date(2024, 5, 2, 9, 13, 44, 0, time.UTC))
+
+// testManagedFields 测试对象的 managedFields
+func testManagedFields() []metav1.ManagedFieldsEntry {
+	return []metav1.ManagedFieldsEntry{{
+		Manager:    "kubectl-client-side-apply",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		Time:       &testCreationTime,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{}}}}`)},
+	}}
+}
+
+// newTestPod 创建一个测试用 Pod
+func newTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "nginx",
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "nginx"},
+			CreationTimestamp: testCreationTime,
+			ManagedFields:     testManagedFields(),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "nginx",
+				Image: "nginx:1.25",
+				Ports: []corev1.ContainerPort{{ContainerPort: 80, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// newTestDeployment 创建一个测试用 Deployment
+func newTestDeployment() *appsv1.Deployment {
+	replicas := int32(2)
+	labels := map[string]string{"app": "web"}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web",
+			Namespace:         "default",
+			Labels:            labels,
+			CreationTimestamp: testCreationTime,
+			ManagedFields:     testManagedFields(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: "registry.example.com/web:v1.2.0"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2},
+	}
+}
+
+// TestSerializeResourceGolden 测试 Pod 和 Deployment 的 JSON/YAML 序列化输出
+func TestSerializeResourceGolden(t *testing.T) {
+	ro := NewResourceOperations(NewClusterManager(nil))
+
+	tests := []struct {
+		name   string
+		obj    runtime.Object
+		format string
+		golden string
+	}{
+		{"pod json", newTestPod(), OutputFormatJSON, "pod.json"},
+		{"pod yaml", newTestPod(), OutputFormatYAML, "pod.yaml"},
+		{"deployment json", newTestDeployment(), OutputFormatJSON, "deployment.json"},
+		{"deployment yaml", newTestDeployment(), OutputFormatYAML, "deployment.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ro.SerializeResource(tt.obj, &SerializeOptions{Format: tt.format})
+			if err != nil {
+				t.Fatalf("SerializeResource failed: %v", err)
+			}
+			if strings.Contains(out, "managedFields") {
+				t.Errorf("managedFields should be stripped by default")
+			}
+			assertGolden(t, tt.golden, out)
+		})
+	}
+}
+
+// TestSerializeResourceYAMLIsApplyable 测试 YAML 输出包含 apiVersion/kind，可用于 kubectl apply
+func TestSerializeResourceYAMLIsApplyable(t *testing.T) {
+	ro := NewResourceOperations(NewClusterManager(nil))
+
+	out, err := ro.SerializeResource(newTestDeployment(), &SerializeOptions{Format: OutputFormatYAML})
+	if err != nil {
+		t.Fatalf("SerializeResource failed: %v", err)
+	}
+
+	var decoded appsv1.Deployment
+	if err := yaml.UnmarshalStrict([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid Deployment YAML: %v", err)
+	}
+	if decoded.APIVersion != "apps/v1" || decoded.Kind != "Deployment" {
+		t.Errorf("expected apps/v1 Deployment, got %s %s", decoded.APIVersion, decoded.Kind)
+	}
+	if decoded.Name != "web" || *decoded.Spec.Replicas != 2 {
+		t.Errorf("unexpected decoded deployment: %+v", decoded.ObjectMeta)
+	}
+}
+
+// TestSerializeResourceManagedFields 测试 include_managed_fields 保留 managedFields
+func TestSerializeResourceManagedFields(t *testing.T) {
+	ro := NewResourceOperations(NewClusterManager(nil))
+
+	out, err := ro.SerializeResource(newTestPod(), &SerializeOptions{IncludeManagedFields: true})
+	if err != nil {
+		t.Fatalf("SerializeResource failed: %v", err)
+	}
+	if !strings.Contains(out, "managedFields") {
+		t.Errorf("expected managedFields in output")
+	}
+
+	if _, err := ro.SerializeResource(newTestPod(), &SerializeOptions{Format: "xml"}); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+}
+
+// waiting/terminated/running 构造容器状态的辅助函数
+func waiting(reason string, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{RestartCount: restarts, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+}
+
+func terminated(reason string, exitCode int32, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{RestartCount: restarts, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}}}
+}
+
+func running(ready bool, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Ready: ready, RestartCount: restarts, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+}
+
+// TestPodStatusSummary 测试 Pod 的状态、Ready 和重启次数与 kubectl get pods 一致
+func TestPodStatusSummary(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name         string
+		phase        corev1.PodPhase
+		reason       string
+		deleting     bool
+		initCount    int
+		initStatuses []corev1.ContainerStatus
+		statuses     []corev1.ContainerStatus
+		wantStatus   string
+		wantReady    string
+		wantRestarts int
+	}{
+		{
+			name:       "running",
+			phase:      corev1.PodRunning,
+			statuses:   []corev1.ContainerStatus{running(true, 0), running(true, 0)},
+			wantStatus: "Running", wantReady: "2/2",
+		},
+		{
+			name:       "crashloop while phase is running",
+			phase:      corev1.PodRunning,
+			statuses:   []corev1.ContainerStatus{running(true, 0), waiting("CrashLoopBackOff", 7)},
+			wantStatus: "CrashLoopBackOff", wantReady: "1/2", wantRestarts: 7,
+		},
+		{
+			name:       "image pull backoff",
+			phase:      corev1.PodPending,
+			statuses:   []corev1.ContainerStatus{waiting("ImagePullBackOff", 0)},
+			wantStatus: "ImagePullBackOff", wantReady: "0/1",
+		},
+		{
+			name:       "oom killed",
+			phase:      corev1.PodRunning,
+			statuses:   []corev1.ContainerStatus{terminated("OOMKilled", 137, 3)},
+			wantStatus: "OOMKilled", wantReady: "0/1", wantRestarts: 3,
+		},
+		{
+			name:       "crashloop wins over terminated error",
+			phase:      corev1.PodRunning,
+			statuses:   []corev1.ContainerStatus{terminated("Error", 1, 1), waiting("CrashLoopBackOff", 2)},
+			wantStatus: "CrashLoopBackOff", wantReady: "0/2", wantRestarts: 3,
+		},
+		{
+			name:       "exit code without reason",
+			phase:      corev1.PodFailed,
+			statuses:   []corev1.ContainerStatus{terminated("", 2, 0)},
+			wantStatus: "ExitCode:2", wantReady: "0/1",
+		},
+		{
+			name:       "completed job",
+			phase:      corev1.PodSucceeded,
+			statuses:   []corev1.ContainerStatus{terminated("Completed", 0, 0)},
+			wantStatus: "Completed", wantReady: "0/1",
+		},
+		{
+			name:       "completed sidecar with running main container",
+			phase:      corev1.PodRunning,
+			statuses:   []corev1.ContainerStatus{terminated("Completed", 0, 0), running(true, 0)},
+			wantStatus: "Running", wantReady: "1/2",
+		},
+		{
+			name:         "init container in progress",
+			phase:        corev1.PodPending,
+			initCount:    3,
+			initStatuses: []corev1.ContainerStatus{terminated("Completed", 0, 0), running(false, 0), waiting("PodInitializing", 0)},
+			statuses:     []corev1.ContainerStatus{waiting("PodInitializing", 0)},
+			wantStatus:   "Init:1/3", wantReady: "0/1",
+		},
+		{
+			name:         "init container crashloop",
+			phase:        corev1.PodPending,
+			initCount:    1,
+			initStatuses: []corev1.ContainerStatus{waiting("CrashLoopBackOff", 4)},
+			statuses:     []corev1.ContainerStatus{waiting("PodInitializing", 0)},
+			wantStatus:   "Init:CrashLoopBackOff", wantReady: "0/1", wantRestarts: 4,
+		},
+		{
+			name:         "init container failed",
+			phase:        corev1.PodPending,
+			initCount:    1,
+			initStatuses: []corev1.ContainerStatus{terminated("Error", 1, 0)},
+			wantStatus:   "Init:Error", wantReady: "0/1",
+		},
+		{
+			name:       "terminating",
+			phase:      corev1.PodRunning,
+			deleting:   true,
+			statuses:   []corev1.ContainerStatus{running(true, 0)},
+			wantStatus: "Terminating", wantReady: "1/1",
+		},
+		{
+			name:       "terminating on lost node",
+			phase:      corev1.PodRunning,
+			reason:     "NodeLost",
+			deleting:   true,
+			statuses:   []corev1.ContainerStatus{running(true, 0)},
+			wantStatus: "Unknown", wantReady: "1/1",
+		},
+		{
+			name:       "evicted",
+			phase:      corev1.PodFailed,
+			reason:     "Evicted",
+			wantStatus: "Evicted", wantReady: "0/1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod()
+			pod.Status = corev1.PodStatus{
+				Phase:                 tt.phase,
+				Reason:                tt.reason,
+				InitContainerStatuses: tt.initStatuses,
+				ContainerStatuses:     tt.statuses,
+			}
+			for i := 0; i < tt.initCount; i++ {
+				pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Name: "init"})
+			}
+			for len(pod.Spec.Containers) < len(tt.statuses) {
+				pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "sidecar"})
+			}
+			if tt.deleting {
+				pod.DeletionTimestamp = &now
+			}
+
+			if got := getPodStatus(pod); got != tt.wantStatus {
+				t.Errorf("status: got %q, want %q", got, tt.wantStatus)
+			}
+			if got := calculatePodReady(pod); got != tt.wantReady {
+				t.Errorf("ready: got %q, want %q", got, tt.wantReady)
+			}
+			if got := calculatePodRestarts(pod); got != tt.wantRestarts {
+				t.Errorf("restarts: got %d, want %d", got, tt.wantRestarts)
+			}
+		})
+	}
+}
+
+// TestGetPodLogsSinceTime 测试 SinceTime 和 Timestamps 传给 API server，且指定 SinceTime 时不再默认只取 100 行
+func TestGetPodLogsSinceTime(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte("2024-01-02T03:04:05.5Z ready\n"))
+	}))
+	defer server.Close()
+	ro := newServerOperations(t, server)
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logs, err := ro.GetPodLogs(context.Background(), "default", "web-0", PodLogOptions{Container: "app", SinceTime: &since, Timestamps: true}, "test")
+	if err != nil {
+		t.Fatalf("GetPodLogs failed: %v", err)
+	}
+	if logs != "2024-01-02T03:04:05.5Z ready\n" {
+		t.Errorf("unexpected logs %q", logs)
+	}
+	if query.Get("sinceTime") != "2024-01-02T03:04:05Z" || query.Get("timestamps") != "true" || query.Has("tailLines") {
+		t.Errorf("unexpected log query %v", query)
+	}
+}
+
+// TestGetPodLogsWindow 测试相对 since 转换为 sinceSeconds，until 丢弃之后的日志行、tail_lines 从 until 往前计算并去掉时间戳
+func TestGetPodLogsWindow(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte("2024-05-02T09:50:00Z one\n2024-05-02T09:55:00Z two\nmissing timestamp\n2024-05-02T09:59:59.9Z three\n2024-05-02T10:00:00.1Z four\n2024-05-02T10:01:00Z five\n"))
+	}))
+	defer server.Close()
+	ro := newServerOperations(t, server)
+
+	window, err := ParseTimeWindow("15m", "2024-05-02T10:00:00Z", time.Date(2024, 5, 2, 10, 5, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ParseTimeWindow failed: %v", err)
+	}
+	tail := int64(3)
+	opts := PodLogOptions{Container: "app", TailLines: &tail}
+	opts.ApplyWindow(window)
+	logs, err := ro.GetPodLogs(context.Background(), "default", "web-0", opts, "test")
+	if err != nil {
+		t.Fatalf("GetPodLogs failed: %v", err)
+	}
+	if logs != "two\nmissing timestamp\nthree\n" {
+		t.Errorf("unexpected logs %q", logs)
+	}
+	if query.Get("sinceSeconds") != "900" || query.Get("timestamps") != "true" || query.Has("tailLines") || query.Has("sinceTime") {
+		t.Errorf("unexpected log query %v", query)
+	}
+}
+
+// newFakeOperations 将预置 objects 的 fake clientset 注册为集群 "test"
+func newFakeOperations(t *testing.T, objects ...runtime.Object) (*ResourceOperations, *fake.Clientset) {
+	t.Helper()
+	client := fake.NewSimpleClientset(objects...)
+	cm := NewClusterManager(nil)
+	cm.AddClientset("test", client)
+	return NewResourceOperations(cm), client
+}
+
+// newServerOperations 将连接到 server 的 rest.Config 注册为集群 "test"
+func newServerOperations(t *testing.T, server *httptest.Server) *ResourceOperations {
+	t.Helper()
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("test", &rest.Config{Host: server.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	return NewResourceOperations(cm)
+}
+
+// fakeAPIServer 按请求路径返回 responses 中对应的 JSON 对象，未知路径返回 404；
+// hooks 依次先于 responses 处理每个请求，返回 true 表示请求已处理
+func fakeAPIServer(t *testing.T, responses map[string]interface{}, hooks ...func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for _, hook := range hooks {
+			if hook(w, r) {
+				return
+			}
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// listFixture 包含两个命名空间中的 Pod、Service、Deployment 和事件，以及两个节点
+func listFixture() []runtime.Object {
+	var objects []runtime.Object
+	for _, ns := range []string{"team-a", "team-b"} {
+		meta := metav1.ObjectMeta{Name: "web", Namespace: ns, Labels: map[string]string{"app": "web"}}
+		objects = append(objects,
+			&corev1.Pod{
+				ObjectMeta: meta,
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}}},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{running(true, 2)},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: meta,
+				Spec: corev1.ServiceSpec{
+					Type:      corev1.ServiceTypeClusterIP,
+					ClusterIP: "10.0.0.1",
+					Ports:     []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}, {Port: 443, Protocol: corev1.ProtocolTCP}},
+				},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: meta,
+				Status:     appsv1.DeploymentStatus{Replicas: 3, ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+			},
+			&corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: ns},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: ns},
+				Type:           corev1.EventTypeWarning,
+				Reason:         "BackOff",
+				Count:          4,
+				Source:         corev1.EventSource{Component: "kubelet"},
+			},
+		)
+	}
+	objects = append(objects,
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "cp-1", Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: "v1.28.4"},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+			},
+		},
+	)
+	return objects
+}
+
+// TestListResourcesFake 测试 ListPods/ListServices/ListDeployments/listEvents 对单个命名空间、全部命名空间和空结果的处理
+func TestListResourcesFake(t *testing.T) {
+	ro, _ := newFakeOperations(t, listFixture()...)
+	ctx := context.Background()
+
+	tests := []struct {
+		namespace string
+		want      []string
+	}{
+		{"team-a", []string{"team-a"}},
+		{"", []string{"team-a", "team-b"}},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run("namespace="+tt.namespace, func(t *testing.T) {
+			pods, err := ro.ListPods(ctx, tt.namespace, "test")
+			if err != nil || len(pods) != len(tt.want) {
+				t.Fatalf("ListPods: got %+v (%v)", pods, err)
+			}
+			for i, pod := range pods {
+				if pod.Namespace != tt.want[i] || pod.Status != "Running" || pod.Ready != "1/1" || pod.Restarts != 2 || pod.Labels["app"] != "web" {
+					t.Errorf("unexpected pod %+v", pod)
+				}
+			}
+
+			services, err := ro.ListServices(ctx, tt.namespace, "test")
+			if err != nil || len(services) != len(tt.want) {
+				t.Fatalf("ListServices: got %+v (%v)", services, err)
+			}
+			for i, svc := range services {
+				if svc.Namespace != tt.want[i] || svc.Type != "ClusterIP" || svc.Ports != "80/TCP, 443/TCP" {
+					t.Errorf("unexpected service %+v", svc)
+				}
+			}
+
+			deployments, err := ro.ListDeployments(ctx, tt.namespace, "test")
+			if err != nil || len(deployments) != len(tt.want) {
+				t.Fatalf("ListDeployments: got %+v (%v)", deployments, err)
+			}
+			for i, dep := range deployments {
+				if dep.Namespace != tt.want[i] || dep.Ready != "2/3" || dep.UpToDate != "3" || dep.Available != "2" {
+					t.Errorf("unexpected deployment %+v", dep)
+				}
+			}
+
+			events, err := ro.listEvents(ctx, tt.namespace, "test")
+			if err != nil || len(events) != len(tt.want) {
+				t.Fatalf("listEvents: got %+v (%v)", events, err)
+			}
+			for i, event := range events {
+				if event.Namespace != tt.want[i] || event.Object != "Pod/web" || event.Reason != "BackOff" || event.Count != 4 || event.Source != "kubelet" {
+					t.Errorf("unexpected event %+v", event)
+				}
+			}
+		})
+	}
+}
+
+// TestListEventsWindow 测试按最后出现时间过滤事件：包含两端，没有时间戳的事件在有边界的时间段中被排除
+func TestListEventsWindow(t *testing.T) {
+	event := func(name string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", Namespace: "shop"},
+			Reason:         name,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+	base := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+	ro, _ := newFakeOperations(t,
+		event("early", base.Add(-time.Minute)),
+		event("start", base),
+		event("middle", base.Add(30*time.Minute)),
+		event("late", base.Add(2*time.Hour)),
+		event("undated", time.Time{}),
+	)
+
+	window, err := ParseTimeWindow("2024-05-02T09:00:00Z", "1h", base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ParseTimeWindow failed: %v", err)
+	}
+	events, err := ro.ListEvents(context.Background(), "shop", window, "test")
+	if err != nil {
+		t.Fatalf("ListEvents failed: %v", err)
+	}
+	var reasons []string
+	for _, e := range events {
+		reasons = append(reasons, e.Reason)
+	}
+	if strings.Join(reasons, ",") != "middle,start" {
+		t.Errorf("expected the events within the window, got %v", reasons)
+	}
+
+	all, err := ro.ListEvents(context.Background(), "shop", TimeWindow{}, "test")
+	if err != nil || len(all) != 5 {
+		t.Errorf("expected every event without a window, got %d (%v)", len(all), err)
+	}
+}
+
+// TestListNodesFake 测试节点的就绪状态和角色，以及没有节点时返回空结果
+func TestListNodesFake(t *testing.T) {
+	ro, _ := newFakeOperations(t, listFixture()...)
+	nodes, err := ro.listNodes(context.Background(), "")
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("listNodes: got %+v (%v)", nodes, err)
+	}
+	if nodes[0].Name != "cp-1" || nodes[0].Status != "Ready" || nodes[0].Roles != "master" || nodes[0].Version != "v1.28.4" {
+		t.Errorf("unexpected node %+v", nodes[0])
+	}
+	if nodes[1].Name != "worker-1" || nodes[1].Status != "NotReady" || nodes[1].Roles != "<none>" {
+		t.Errorf("unexpected node %+v", nodes[1])
+	}
+
+	empty, _ := newFakeOperations(t)
+	if nodes, err := empty.listNodes(context.Background(), ""); err != nil || len(nodes) != 0 {
+		t.Errorf("expected no nodes, got %+v (%v)", nodes, err)
+	}
+}
+
+// TestGetResourceDetailsFake 测试按类型获取对象、对象不存在和不支持的类型
+func TestGetResourceDetailsFake(t *testing.T) {
+	ro, _ := newFakeOperations(t, listFixture()...)
+	ctx := context.Background()
+
+	tests := []struct {
+		resourceType ResourceType
+		namespace    string
+		name         string
+		wantErr      string
+	}{
+		{ResourceTypePod, "team-a", "web", ""},
+		{ResourceTypeServices, "team-b", "web", ""},
+		{ResourceTypeDeployment, "team-a", "web", ""},
+		{ResourceTypeNode, "", "worker-1", ""},
+		{ResourceTypePod, "team-a", "missing", `pods "missing" not found`},
+		{ResourceType("widgets"), "team-a", "web", "unsupported resource type: widgets"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resourceType)+"/"+tt.name, func(t *testing.T) {
+			obj, err := ro.GetResourceDetails(ctx, tt.resourceType, tt.namespace, tt.name, "test")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetResourceDetails failed: %v", err)
+			}
+			accessor, err := apimeta.Accessor(obj)
+			if err != nil || accessor.GetName() != tt.name || accessor.GetNamespace() != tt.namespace {
+				t.Errorf("unexpected object %+v", obj)
+			}
+		})
+	}
+}
+
+// TestListResourcesFakeErrors 测试通过 reactor 注入的 API 错误被包装返回，未知集群返回错误
+func TestListResourcesFakeErrors(t *testing.T) {
+	ro, client := newFakeOperations(t, listFixture()...)
+	client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: action.GetResource().Resource}, "", errors.New("denied"))
+	})
+	client.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("try again")
+	})
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"failed to list pods":        func() error { _, err := ro.ListPods(ctx, "team-a", "test"); return err },
+		"failed to list services":    func() error { _, err := ro.ListServices(ctx, "team-a", "test"); return err },
+		"failed to list deployments": func() error { _, err := ro.ListDeployments(ctx, "team-a", "test"); return err },
+		"failed to list nodes":       func() error { _, err := ro.listNodes(ctx, "test"); return err },
+		"failed to list events":      func() error { _, err := ro.listEvents(ctx, "team-a", "test"); return err },
+	}
+	for want, call := range calls {
+		err := call()
+		if err == nil || !strings.Contains(err.Error(), want) || !apierrors.IsForbidden(err) {
+			t.Errorf("expected forbidden error %q, got %v", want, err)
+		}
+	}
+
+	if _, err := ro.GetResourceDetails(ctx, ResourceTypeDeployment, "team-a", "web", "test"); !apierrors.IsServiceUnavailable(err) {
+		t.Errorf("expected service unavailable error, got %v", err)
+	}
+	if _, err := ro.ListPods(ctx, "team-a", "other"); err == nil || !strings.Contains(err.Error(), "client for cluster other not found") {
+		t.Errorf("expected unknown cluster error, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// API request retry policy: up to maxRetryAttempts attempts in total, backing off
+// exponentially from retryBaseDelay up to retryMaxDelay between them
+// API 请求重试策略：最多共尝试 maxRetryAttempts 次，两次尝试之间的等待从 retryBaseDelay 指数增长，最大 retryMaxDelay
+const (
+	maxRetryAttempts = 4
+	retryBaseDelay   = 200 * time.Millisecond
+	retryMaxDelay    = 5 * time.Second
+)
+
+// maxRetryStatusBody bounds the body read to tell a ServerTimeout from other 500 responses
+// maxRetryStatusBody 限制为区分 ServerTimeout 和其他 500 响应而读取的响应体大小
+const maxRetryStatusBody = 64 << 10
+
+// noRetryKey marks a context whose API requests must not be retried
+// noRetryKey 标记其 API 请求不重试的上下文
+type noRetryKey struct{}
+
+// withoutRetries disables retries for the API requests issued with ctx
+// withoutRetries 禁止重试使用 ctx 发出的 API 请求
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// retryingTransport retries API requests that failed transiently: 429, 503 and
+// ServerTimeout responses and dropped connections. Reads are retried on any of
+// them; mutating requests only when the connection was never made, the one
+// failure that guarantees the server did not apply them. Waits honor Retry-After
+// and never outlast the request context.
+// retryingTransport 重试暂时失败的 API 请求：429、503、ServerTimeout 响应和断开的连接。读请求遇到这些情况都会重试；
+// 修改请求只在连接未建立时重试，这是唯一能保证服务器没有执行请求的失败。等待时间遵循 Retry-After，且不超过请求上下文的期限。
+type retryingTransport struct {
+	cm        *ClusterManager
+	cluster   string
+	baseDelay time.Duration
+	next      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(noRetryKey{}) != nil {
+		return t.next.RoundTrip(req)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		reason, retryable := retryReason(req, resp, err)
+		if !retryable || attempt == maxRetryAttempts {
+			if retryable && resp != nil {
+				// Keep client-go from retrying an exhausted request again
+				// 避免 client-go 再次重试已经用尽重试次数的请求
+				resp.Header.Del("Retry-After")
+			}
+			return resp, err
+		}
+
+		delay := backoff(t.baseDelay, attempt)
+		if resp != nil {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+				delay = time.Duration(seconds) * time.Second
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		t.cm.logger.Debug("Retrying API request", "cluster", t.cluster, "method", req.Method, "path", req.URL.Path,
+			"attempt", attempt+1, "reason", reason, "delay", delay)
+		t.cm.apiRetries.Add(1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+}
+
+// retryReason reports whether an attempt failed transiently and may be retried,
+// and why. Only reads and requests that never reached the server qualify.
+// retryReason 判断一次尝试是否暂时失败、可以重试，并返回原因。只有读请求和未到达服务器的请求可以重试。
+func retryReason(req *http.Request, resp *http.Response, err error) (string, bool) {
+	read := req.Method == http.MethodGet || req.Method == http.MethodHead
+	if err != nil {
+		if req.Context().Err() != nil {
+			return "", false
+		}
+		var opErr *net.OpError
+		switch {
+		case errors.As(err, &opErr) && opErr.Op == "dial":
+			return "connection failed", true
+		case !read:
+			return "", false
+		case utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+			return "connection reset", true
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "connection timeout", true
+		}
+		return "", false
+	}
+
+	if !read {
+		return "", false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return "too many requests", true
+	case http.StatusServiceUnavailable:
+		return "service unavailable", true
+	case http.StatusInternalServerError:
+		return "server timeout", isServerTimeout(resp)
+	}
+	return "", false
+}
+
+// isServerTimeout reports whether a 500 response carries a ServerTimeout status,
+// leaving the body readable for the caller
+// isServerTimeout 判断 500 响应是否为 ServerTimeout 状态，调用者仍可读取响应体
+func isServerTimeout(resp *http.Response) bool {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRetryStatusBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	var status metav1.Status
+	return json.Unmarshal(data, &status) == nil && status.Reason == metav1.StatusReasonServerTimeout
+}
+
+// backoff returns the wait before the attempt after the given one
+// backoff 返回第 attempt 次尝试之后的等待时间
+func backoff(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// APIRetries returns how many API requests have been retried since the manager was created
+// APIRetries 返回自 ClusterManager 创建以来重试的 API 请求次数
+func (cm *ClusterManager) APIRetries() int64 {
+	return cm.apiRetries.Load()
+}
@@ -0,0 +1,201 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// roundTripFunc 将函数适配为 http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// statusResponse 构造带有 metav1.Status 响应体的响应
+func statusResponse(status *apierrors.StatusError, header http.Header) *http.Response {
+	body, _ := json.Marshal(status.ErrStatus)
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: int(status.ErrStatus.Code), Header: header, Body: io.NopCloser(strings.NewReader(string(body)))}
+}
+
+// TestRetryingTransport 测试哪些失败会被重试：读请求遇到限流、服务不可用、ServerTimeout 和连接断开时重试，
+// 修改请求只在连接未建立时重试
+func TestRetryingTransport(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	ok := func() (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	pods := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name     string
+		method   string
+		failure  func() (*http.Response, error)
+		attempts int
+	}{
+		{"read throttled", http.MethodGet, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewTooManyRequests("slow down", 0), http.Header{"Retry-After": {"0"}}), nil
+		}, 2},
+		{"read unavailable", http.MethodGet, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewServiceUnavailable("etcd leader changed"), nil), nil
+		}, 2},
+		{"read server timeout", http.MethodGet, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewServerTimeout(pods, "list", 1), nil), nil
+		}, 2},
+		{"read internal error", http.MethodGet, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewInternalError(io.ErrUnexpectedEOF), nil), nil
+		}, 1},
+		{"read not found", http.MethodGet, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewNotFound(pods, "web"), nil), nil
+		}, 1},
+		{"read connection reset", http.MethodGet, func() (*http.Response, error) { return nil, reset }, 2},
+		{"write connection refused", http.MethodPost, func() (*http.Response, error) { return nil, refused }, 2},
+		{"write throttled", http.MethodPatch, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewTooManyRequests("slow down", 0), nil), nil
+		}, 1},
+		{"write connection reset", http.MethodPost, func() (*http.Response, error) { return nil, reset }, 1},
+		{"write unavailable", http.MethodDelete, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewServiceUnavailable("etcd leader changed"), nil), nil
+		}, 1},
+		{"write server timeout", http.MethodPut, func() (*http.Response, error) {
+			return statusResponse(apierrors.NewServerTimeout(pods, "update", 1), nil), nil
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := NewClusterManager(nil)
+			var bodies []string
+			transport := &retryingTransport{cm: cm, cluster: "test", baseDelay: time.Millisecond, next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					body, _ := io.ReadAll(req.Body)
+					bodies = append(bodies, string(body))
+				}
+				if len(bodies) == 1 {
+					return tt.failure()
+				}
+				return ok()
+			})}
+
+			req, _ := http.NewRequest(tt.method, "https://cluster.example/api/v1/namespaces/shop/pods", strings.NewReader(`{"kind":"Pod"}`))
+			resp, err := transport.RoundTrip(req)
+			if len(bodies) != tt.attempts {
+				t.Fatalf("expected %d attempts, got %d", tt.attempts, len(bodies))
+			}
+			for _, body := range bodies {
+				if body != `{"kind":"Pod"}` {
+					t.Errorf("expected every attempt to send the request body, got %q", body)
+				}
+			}
+			if cm.APIRetries() != int64(tt.attempts-1) {
+				t.Errorf("expected %d retries counted, got %d", tt.attempts-1, cm.APIRetries())
+			}
+			if tt.attempts == 1 {
+				failure, failureErr := tt.failure()
+				if (err == nil) != (failureErr == nil) || (resp != nil) != (failure != nil) {
+					t.Errorf("expected the failure to be returned, got %v %v", resp, err)
+				}
+				if resp != nil {
+					if body, _ := io.ReadAll(resp.Body); !strings.Contains(string(body), `"kind":"Status"`) && !strings.Contains(string(body), `"code"`) {
+						t.Errorf("expected the status body to stay readable, got %q", body)
+					}
+				}
+			} else if err != nil || resp.StatusCode != http.StatusOK {
+				t.Errorf("expected the retry to succeed, got %v %v", resp, err)
+			}
+		})
+	}
+}
+
+// TestRetryingTransportLimits 测试重试次数上限、Retry-After 超出上下文期限时不再等待，以及取消的上下文
+func TestRetryingTransportLimits(t *testing.T) {
+	cm := NewClusterManager(nil)
+	attempts := 0
+	retryAfter := ""
+	transport := &retryingTransport{cm: cm, cluster: "test", baseDelay: time.Millisecond, next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return statusResponse(apierrors.NewTooManyRequests("slow down", 0), http.Header{"Retry-After": {retryAfter}}), nil
+	})}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://cluster.example/api/v1/pods", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempts != maxRetryAttempts {
+		t.Fatalf("expected %d attempts ending in 429, got %d: %v %v", maxRetryAttempts, attempts, resp, err)
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		t.Errorf("expected Retry-After to be dropped once retries are exhausted")
+	}
+
+	// Retry-After 超过上下文剩余时间时直接返回
+	attempts, retryAfter = 0, "30"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if resp, _ := transport.RoundTrip(req.WithContext(ctx)); resp.StatusCode != http.StatusTooManyRequests || attempts != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected one attempt without waiting, got %d after %s", attempts, time.Since(start))
+	}
+
+	// 上下文取消时停止等待
+	attempts, retryAfter = 0, "2"
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); err != nil || attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected the wait to stop when the context is canceled, got %d attempts after %s: %v", attempts, time.Since(start), err)
+	}
+}
+
+// TestRetryReadThroughClient 测试 ResourceOperations 的读方法通过集群客户端自动重试暂时失败的请求
+func TestRetryReadThroughClient(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(apierrors.NewServiceUnavailable("etcd leader changed").ErrStatus)
+			return
+		}
+		json.NewEncoder(w).Encode(corev1.NamespaceList{
+			TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
+			Items:    []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}},
+		})
+	}))
+	defer apiServer.Close()
+
+	cm := NewClusterManager(nil)
+	if err := cm.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	namespaces, err := NewResourceOperations(cm).ListNamespaces(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("ListNamespaces failed: %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0].Name != "shop" || requests != 2 || cm.APIRetries() != 1 {
+		t.Errorf("expected one retried request, got %d requests, %d retries: %+v", requests, cm.APIRetries(), namespaces)
+	}
+}
@@ -0,0 +1,483 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations the deployment controller and kubectl use to track rollouts
+// Deployment 控制器和 kubectl 用于记录发布的注解
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// RolloutHistory lists the revisions of a deployment from its ReplicaSets, oldest first.
+// With revision > 0 the pod template of that revision is included as YAML.
+// RolloutHistory 根据 Deployment 的 ReplicaSet 列出其历史版本，按版本号升序排列。
+// revision > 0 时以 YAML 形式附带该版本的 Pod 模板。
+func (ro *ResourceOperations) RolloutHistory(ctx context.Context, namespace, name string, revision int64, clusterName string) (*types.RolloutHistory, error) {
+	if name == "" {
+		return nil, fmt.Errorf("deployment name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, replicaSets, err := deploymentRevisions(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &types.RolloutHistory{
+		Deployment:      deployment.Name,
+		Namespace:       deployment.Namespace,
+		CurrentRevision: replicaSetRevision(&deployment.ObjectMeta),
+	}
+	for _, rs := range replicaSets {
+		rev := replicaSetRevision(&rs.ObjectMeta)
+		history.Revisions = append(history.Revisions, types.RolloutRevision{
+			Revision:      rev,
+			ReplicaSet:    rs.Name,
+			Replicas:      rs.Status.Replicas,
+			ReadyReplicas: rs.Status.ReadyReplicas,
+			ChangeCause:   rs.Annotations[changeCauseAnnotation],
+			Images:        templateImages(&rs.Spec.Template),
+			Current:       rev == history.CurrentRevision,
+			Age:           formatAge(rs.CreationTimestamp),
+			CreatedAt:     formatTimestamp(rs.CreationTimestamp),
+		})
+	}
+
+	if revision > 0 {
+		rs := findRevision(replicaSets, revision)
+		if rs == nil {
+			return nil, fmt.Errorf("revision %d of deployment %s not found", revision, name)
+		}
+		data, err := yaml.Marshal(revisionTemplate(rs))
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize pod template: %w", err)
+		}
+		history.Template = string(data)
+	}
+
+	return history, nil
+}
+
+// RollbackDeployment replaces the pod template of a deployment with the template of an
+// earlier revision, like kubectl rollout undo. A revision of 0 rolls back to the
+// revision before the current one.
+// RollbackDeployment 将 Deployment 的 Pod 模板替换为之前某个版本的模板，与 kubectl rollout undo 相同。
+// revision 为 0 时回滚到当前版本的上一个版本。
+func (ro *ResourceOperations) RollbackDeployment(ctx context.Context, namespace, name string, revision int64, clusterName string) (*types.RollbackResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("deployment name is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, replicaSets, err := deploymentRevisions(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.Spec.Paused {
+		return nil, fmt.Errorf("deployment %s is paused; resume it before rolling back", name)
+	}
+
+	current := replicaSetRevision(&deployment.ObjectMeta)
+	var target *appsv1.ReplicaSet
+	if revision > 0 {
+		target = findRevision(replicaSets, revision)
+		if target == nil {
+			return nil, fmt.Errorf("revision %d of deployment %s not found", revision, name)
+		}
+	} else {
+		// replicaSets is sorted by revision, so the last one below current is the previous
+		// replicaSets 已按版本号排序，小于当前版本的最后一个即为上一个版本
+		for i := range replicaSets {
+			if rev := replicaSetRevision(&replicaSets[i].ObjectMeta); rev < current {
+				target = &replicaSets[i]
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("deployment %s has no revision before %d to roll back to", name, current)
+		}
+	}
+
+	template := revisionTemplate(target)
+	result := &types.RollbackResult{
+		Deployment:   deployment.Name,
+		Namespace:    deployment.Namespace,
+		FromRevision: current,
+		ToRevision:   replicaSetRevision(&target.ObjectMeta),
+		Images:       templateImages(&template),
+	}
+	if apiequality.Semantic.DeepEqual(template, deployment.Spec.Template) {
+		result.Message = fmt.Sprintf("skipped rollback: the current template already matches revision %d", result.ToRevision)
+		return result, nil
+	}
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rollback patch: %w", err)
+	}
+	if _, err := client.AppsV1().Deployments(namespace).Patch(ctx, name, k8stypes.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+
+	result.Rolled = true
+	result.Message = fmt.Sprintf("rolled back to revision %d; the deployment controller records it as a new revision", result.ToRevision)
+	return result, nil
+}
+
+// deploymentRevisions gets a deployment and the ReplicaSets it controls, sorted by revision
+// deploymentRevisions 获取 Deployment 及其控制的 ReplicaSet，按版本号排序
+func deploymentRevisions(ctx context.Context, client kubernetes.Interface, namespace, name string) (*appsv1.Deployment, []appsv1.ReplicaSet, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector on deployment %s: %w", name, err)
+	}
+	list, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	// The selector may match ReplicaSets of other owners, so keep only the controlled ones
+	// 选择器可能匹配其他属主的 ReplicaSet，因此只保留由该 Deployment 控制的
+	var replicaSets []appsv1.ReplicaSet
+	for _, rs := range list.Items {
+		if metav1.IsControlledBy(&rs, deployment) {
+			replicaSets = append(replicaSets, rs)
+		}
+	}
+	sort.SliceStable(replicaSets, func(i, j int) bool {
+		return replicaSetRevision(&replicaSets[i].ObjectMeta) < replicaSetRevision(&replicaSets[j].ObjectMeta)
+	})
+
+	return deployment, replicaSets, nil
+}
+
+// replicaSetRevision parses the revision annotation, 0 if missing or malformed
+// replicaSetRevision 解析版本注解，缺失或格式错误时返回 0
+func replicaSetRevision(meta *metav1.ObjectMeta) int64 {
+	rev, err := strconv.ParseInt(meta.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return rev
+}
+
+// findRevision returns the ReplicaSet of a revision, or nil
+// findRevision 返回指定版本的 ReplicaSet，不存在时返回 nil
+func findRevision(replicaSets []appsv1.ReplicaSet, revision int64) *appsv1.ReplicaSet {
+	for i := range replicaSets {
+		if replicaSetRevision(&replicaSets[i].ObjectMeta) == revision {
+			return &replicaSets[i]
+		}
+	}
+	return nil
+}
+
+// revisionTemplate returns the pod template of a ReplicaSet without the pod-template-hash
+// label the controller adds, i.e. the template as it was in the deployment
+// revisionTemplate 返回 ReplicaSet 去掉控制器添加的 pod-template-hash 标签后的 Pod 模板，即 Deployment 中原本的模板
+func revisionTemplate(rs *appsv1.ReplicaSet) corev1.PodTemplateSpec {
+	template := *rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if len(template.Labels) == 0 {
+		template.Labels = nil
+	}
+	return template
+}
+
+// templateImages returns the images of the containers in a pod template
+// templateImages 返回 Pod 模板中各容器的镜像
+func templateImages(template *corev1.PodTemplateSpec) []string {
+	var images []string
+	for _, c := range template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+// readinessConditions maps the workload types WaitForReady supports to the wait_for
+// condition that means their rollout finished
+// readinessConditions 将 WaitForReady 支持的工作负载类型映射到表示其发布完成的 wait_for 条件
+var readinessConditions = map[ResourceType]string{
+	ResourceTypeDeployments:  "Complete",
+	ResourceTypeDeployment:   "Complete",
+	ResourceTypeStatefulSets: "Ready",
+	ResourceTypeStatefulSet:  "Ready",
+	ResourceTypeDaemonSets:   "Ready",
+	ResourceTypeDaemonSet:    "Ready",
+}
+
+// SupportsReadiness reports whether WaitForReady supports a resource type
+// SupportsReadiness 判断 WaitForReady 是否支持该资源类型
+func SupportsReadiness(resourceType ResourceType) bool {
+	_, ok := readinessConditions[resourceType]
+	return ok
+}
+
+// WaitForReady waits like wait_for until the rollout of a Deployment, StatefulSet or
+// DaemonSet finished and summarizes its final state. Running out of time is not an
+// error: the summary is marked TimedOut and names the first pod that is not ready.
+// WaitForReady 与 wait_for 相同地等待 Deployment、StatefulSet 或 DaemonSet 发布完成，并汇总其最终状态。
+// 超时不视为错误：摘要标记为 TimedOut，并给出第一个未就绪的 Pod。
+func (ro *ResourceOperations) WaitForReady(ctx context.Context, resourceType ResourceType, namespace, name string, timeout time.Duration, clusterName string) (*types.WorkloadReadiness, error) {
+	condition, ok := readinessConditions[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("readiness is only reported for deployments, statefulsets and daemonsets, not %s", resourceType)
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	waited, err := ro.WaitForCondition(ctx, resourceType, namespace, name, condition, timeout, clusterName)
+	timedOut := errors.Is(err, ErrWaitTimeout)
+	if err != nil && !timedOut {
+		return nil, err
+	}
+
+	target, err := newWaitTarget(client, resourceType, namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := target.get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", resourceType, name, err)
+	}
+	readiness, selector, err := workloadReadiness(obj)
+	if err != nil {
+		return nil, err
+	}
+	readiness.Elapsed = waited.Elapsed
+	readiness.TimedOut = timedOut && !readiness.Ready
+	if !readiness.Ready {
+		readiness.FailingPod, readiness.FailingReason = firstFailingPod(ctx, client, namespace, selector)
+	}
+	readiness.Summary = readinessSummary(readiness, timeout)
+	return readiness, nil
+}
+
+// workloadReadiness evaluates a Deployment, StatefulSet or DaemonSet and returns the
+// selector of its pods
+// workloadReadiness 评估 Deployment、StatefulSet 或 DaemonSet，并返回其 Pod 的选择器
+func workloadReadiness(obj runtime.Object) (*types.WorkloadReadiness, *metav1.LabelSelector, error) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return evaluateDeploymentReadiness(workload), workload.Spec.Selector, nil
+	case *appsv1.StatefulSet:
+		return evaluateStatefulSetReadiness(workload), workload.Spec.Selector, nil
+	case *appsv1.DaemonSet:
+		return evaluateDaemonSetReadiness(workload), workload.Spec.Selector, nil
+	}
+	return nil, nil, fmt.Errorf("unexpected object %T", obj)
+}
+
+// evaluateDeploymentReadiness treats a deployment as ready once its rollout is complete
+// as in kubectl rollout status: the current generation is observed and every replica is
+// updated and available, with no old replicas left
+// evaluateDeploymentReadiness 与 kubectl rollout status 一致地判断 Deployment 是否发布完成：
+// 当前 generation 已被观察到，所有副本均已更新且可用，且没有遗留的旧副本
+func evaluateDeploymentReadiness(deployment *appsv1.Deployment) *types.WorkloadReadiness {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	s := deployment.Status
+	return &types.WorkloadReadiness{
+		Kind:               "Deployment",
+		Name:               deployment.Name,
+		Namespace:          deployment.Namespace,
+		Generation:         deployment.Generation,
+		ObservedGeneration: s.ObservedGeneration,
+		Replicas:           replicas,
+		UpdatedReplicas:    s.UpdatedReplicas,
+		ReadyReplicas:      s.ReadyReplicas,
+		AvailableReplicas:  s.AvailableReplicas,
+		Ready: s.ObservedGeneration >= deployment.Generation &&
+			s.UpdatedReplicas == replicas &&
+			s.Replicas == replicas &&
+			s.AvailableReplicas == replicas,
+	}
+}
+
+// evaluateStatefulSetReadiness treats a statefulset as ready once the current generation
+// is observed, every replica is ready and the replicas above the rolling update partition
+// are updated (OnDelete statefulsets are not updated by the controller, so only readiness counts)
+// evaluateStatefulSetReadiness 在当前 generation 已被观察到、所有副本就绪且滚动更新分区以上的副本均已更新时
+// 认为 StatefulSet 就绪（OnDelete 策略的 StatefulSet 不由控制器更新，只看就绪副本）
+func evaluateStatefulSetReadiness(statefulSet *appsv1.StatefulSet) *types.WorkloadReadiness {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	s := statefulSet.Status
+	wantUpdated := replicas
+	strategy := statefulSet.Spec.UpdateStrategy
+	switch {
+	case strategy.Type == appsv1.OnDeleteStatefulSetStrategyType:
+		wantUpdated = 0
+	case strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil:
+		wantUpdated = replicas - *strategy.RollingUpdate.Partition
+	}
+	return &types.WorkloadReadiness{
+		Kind:               "StatefulSet",
+		Name:               statefulSet.Name,
+		Namespace:          statefulSet.Namespace,
+		Generation:         statefulSet.Generation,
+		ObservedGeneration: s.ObservedGeneration,
+		Replicas:           replicas,
+		UpdatedReplicas:    s.UpdatedReplicas,
+		ReadyReplicas:      s.ReadyReplicas,
+		AvailableReplicas:  s.AvailableReplicas,
+		Ready: s.ObservedGeneration >= statefulSet.Generation &&
+			s.UpdatedReplicas >= wantUpdated &&
+			s.ReadyReplicas == replicas,
+	}
+}
+
+// evaluateDaemonSetReadiness treats a daemonset as ready once the current generation is
+// observed and its pod is updated and available on every node it should run on
+// evaluateDaemonSetReadiness 在当前 generation 已被观察到、且所有应运行的节点上的 Pod 均已更新并可用时认为 DaemonSet 就绪
+func evaluateDaemonSetReadiness(daemonSet *appsv1.DaemonSet) *types.WorkloadReadiness {
+	s := daemonSet.Status
+	return &types.WorkloadReadiness{
+		Kind:               "DaemonSet",
+		Name:               daemonSet.Name,
+		Namespace:          daemonSet.Namespace,
+		Generation:         daemonSet.Generation,
+		ObservedGeneration: s.ObservedGeneration,
+		Replicas:           s.DesiredNumberScheduled,
+		UpdatedReplicas:    s.UpdatedNumberScheduled,
+		ReadyReplicas:      s.NumberReady,
+		AvailableReplicas:  s.NumberAvailable,
+		Ready: s.ObservedGeneration >= daemonSet.Generation &&
+			s.UpdatedNumberScheduled == s.DesiredNumberScheduled &&
+			s.NumberAvailable == s.DesiredNumberScheduled,
+	}
+}
+
+// firstFailingPod returns the first pod of a workload by name that is not ready, skipping
+// terminating ones, with the reason it is not ready; empty when all are ready or the pods
+// cannot be listed
+// firstFailingPod 按名称顺序返回工作负载中第一个未就绪的 Pod（跳过正在终止的 Pod）及其原因；
+// 全部就绪或无法列出 Pod 时返回空
+func firstFailingPod(ctx context.Context, client kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (string, string) {
+	if selector == nil {
+		return "", ""
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", ""
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return "", ""
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || podIsReady(pod) {
+			continue
+		}
+		return pod.Name, podNotReadyReason(pod)
+	}
+	return "", ""
+}
+
+// podNotReadyReason explains why a pod is not ready: its kubectl status (see getPodStatus)
+// followed by the most specific message, from a waiting or failed container, an
+// unschedulable condition or the Ready condition
+// podNotReadyReason 说明 Pod 未就绪的原因：kubectl 状态（见 getPodStatus）加上最具体的信息，
+// 依次来自等待中或失败的容器、无法调度的条件以及 Ready 条件
+func podNotReadyReason(pod *corev1.Pod) string {
+	reason := getPodStatus(pod)
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		switch state := status.State; {
+		case state.Waiting != nil && state.Waiting.Message != "":
+			return reason + ": " + state.Waiting.Message
+		case state.Terminated != nil && state.Terminated.ExitCode != 0 && state.Terminated.Message != "":
+			return reason + ": " + state.Terminated.Message
+		}
+	}
+	for _, conditionType := range []corev1.PodConditionType{corev1.PodScheduled, corev1.PodReady} {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == conditionType && c.Status != corev1.ConditionTrue && c.Message != "" {
+				return reason + ": " + c.Message
+			}
+		}
+	}
+	return reason
+}
+
+// readinessSummary renders the readiness as one line, e.g. "Deployment web is ready:
+// generation 3 observed, 3/3 updated, 3 ready, 3 available (took 12s)"
+// readinessSummary 将就绪摘要渲染为一行，例如 "Deployment web is ready: generation 3 observed, 3/3 updated, 3 ready, 3 available (took 12s)"
+func readinessSummary(r *types.WorkloadReadiness, timeout time.Duration) string {
+	generation := fmt.Sprintf("generation %d observed", r.Generation)
+	if r.ObservedGeneration < r.Generation {
+		generation = fmt.Sprintf("observed generation %d of %d", r.ObservedGeneration, r.Generation)
+	}
+	counts := fmt.Sprintf("%s, %d/%d updated, %d ready, %d available", generation, r.UpdatedReplicas, r.Replicas, r.ReadyReplicas, r.AvailableReplicas)
+	if r.Ready {
+		return fmt.Sprintf("%s %s is ready: %s (took %s)", r.Kind, r.Name, counts, r.Elapsed)
+	}
+	summary := fmt.Sprintf("%s %s is not ready after %s: %s", r.Kind, r.Name, timeout, counts)
+	if r.FailingPod != "" {
+		summary += fmt.Sprintf("; first failing pod %s: %s", r.FailingPod, r.FailingReason)
+	}
+	return summary
+}
@@ -0,0 +1,367 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// newRolloutDeployment 创建当前处于版本 3、镜像为 image 的 Deployment
+func newRolloutDeployment(image string, paused bool) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         k8stypes.UID("deploy-uid"),
+			Annotations: map[string]string{revisionAnnotation: "3"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Paused:   paused,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+			},
+		},
+	}
+}
+
+// newRolloutReplicaSet 创建属于 Deployment web 的指定版本的 ReplicaSet
+func newRolloutReplicaSet(revision, image, changeCause string, replicas int32) appsv1.ReplicaSet {
+	controller := true
+	annotations := map[string]string{revisionAnnotation: revision}
+	if changeCause != "" {
+		annotations[changeCauseAnnotation] = changeCause
+	}
+	return appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-" + revision,
+			Namespace:       "default",
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "deploy-uid", Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: "hash" + revision}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+			},
+		},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+	}
+}
+
+// fakeRolloutAPIServer 模拟 Deployment 和三个版本的 ReplicaSet（乱序返回，另含一个不属于该 Deployment 的 ReplicaSet），
+// 返回的函数给出收到的 PATCH 请求体
+func fakeRolloutAPIServer(t *testing.T, deployment *appsv1.Deployment) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	orphan := newRolloutReplicaSet("7", "nginx:other", "", 0)
+	orphan.Name = "web-orphan"
+	orphan.OwnerReferences = nil
+	replicaSets := appsv1.ReplicaSetList{Items: []appsv1.ReplicaSet{
+		newRolloutReplicaSet("3", "nginx:1.25", "kubectl set image deployment/web web=nginx:1.25", 3),
+		newRolloutReplicaSet("1", "nginx:1.23", "", 0),
+		orphan,
+		newRolloutReplicaSet("2", "nginx:1.24", "bump to 1.24", 0),
+	}}
+
+	var patches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/deployments/web" && r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patches = append(patches, string(body))
+			json.NewEncoder(w).Encode(deployment)
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/deployments/web":
+			json.NewEncoder(w).Encode(deployment)
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/replicasets" && r.URL.Query().Get("labelSelector") == "app=web":
+			json.NewEncoder(w).Encode(replicaSets)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string { return patches }
+}
+
+// TestRolloutHistory 测试按版本号排序、忽略不属于该 Deployment 的 ReplicaSet，以及输出指定版本的模板
+func TestRolloutHistory(t *testing.T) {
+	server, _ := fakeRolloutAPIServer(t, newRolloutDeployment("nginx:1.25", false))
+	ro := newServerOperations(t, server)
+
+	history, err := ro.RolloutHistory(context.Background(), "default", "web", 0, "test")
+	if err != nil {
+		t.Fatalf("RolloutHistory failed: %v", err)
+	}
+	if history.CurrentRevision != 3 || history.Template != "" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	tests := []struct {
+		revision    int64
+		replicaSet  string
+		replicas    int32
+		changeCause string
+		images      []string
+		current     bool
+	}{
+		{1, "web-1", 0, "", []string{"nginx:1.23"}, false},
+		{2, "web-2", 0, "bump to 1.24", []string{"nginx:1.24"}, false},
+		{3, "web-3", 3, "kubectl set image deployment/web web=nginx:1.25", []string{"nginx:1.25"}, true},
+	}
+	if len(history.Revisions) != len(tests) {
+		t.Fatalf("expected %d revisions, got %+v", len(tests), history.Revisions)
+	}
+	for i, tt := range tests {
+		rev := history.Revisions[i]
+		if rev.Revision != tt.revision || rev.ReplicaSet != tt.replicaSet || rev.Replicas != tt.replicas || rev.ChangeCause != tt.changeCause || rev.Current != tt.current || !reflect.DeepEqual(rev.Images, tt.images) {
+			t.Errorf("revision %d: unexpected %+v", tt.revision, rev)
+		}
+	}
+
+	history, err = ro.RolloutHistory(context.Background(), "default", "web", 2, "test")
+	if err != nil {
+		t.Fatalf("RolloutHistory with revision failed: %v", err)
+	}
+	if !strings.Contains(history.Template, "image: nginx:1.24") || strings.Contains(history.Template, appsv1.DefaultDeploymentUniqueLabelKey) {
+		t.Errorf("unexpected template:\n%s", history.Template)
+	}
+
+	if _, err := ro.RolloutHistory(context.Background(), "default", "web", 9, "test"); err == nil || !strings.Contains(err.Error(), "revision 9") {
+		t.Errorf("expected missing revision error, got %v", err)
+	}
+}
+
+// TestRollbackDeployment 测试回滚到上一个版本或指定版本时发送的模板补丁
+func TestRollbackDeployment(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		revision   int64
+		wantTo     int64
+		wantImage  string
+		wantRolled bool
+		wantErr    string
+	}{
+		{name: "previous revision", deployment: newRolloutDeployment("nginx:1.25", false), wantTo: 2, wantImage: "nginx:1.24", wantRolled: true},
+		{name: "explicit revision", deployment: newRolloutDeployment("nginx:1.25", false), revision: 1, wantTo: 1, wantImage: "nginx:1.23", wantRolled: true},
+		{name: "already at revision", deployment: newRolloutDeployment("nginx:1.25", false), revision: 3, wantTo: 3, wantImage: "nginx:1.25"},
+		{name: "missing revision", deployment: newRolloutDeployment("nginx:1.25", false), revision: 9, wantErr: "revision 9"},
+		{name: "paused", deployment: newRolloutDeployment("nginx:1.25", true), wantErr: "paused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, patches := fakeRolloutAPIServer(t, tt.deployment)
+			ro := newServerOperations(t, server)
+
+			result, err := ro.RollbackDeployment(context.Background(), "default", "web", tt.revision, "test")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(patches()) != 0 {
+					t.Errorf("expected no patch, got %v", patches())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RollbackDeployment failed: %v", err)
+			}
+			if result.FromRevision != 3 || result.ToRevision != tt.wantTo || result.Rolled != tt.wantRolled || !reflect.DeepEqual(result.Images, []string{tt.wantImage}) {
+				t.Errorf("unexpected result: %+v", result)
+			}
+
+			if !tt.wantRolled {
+				if len(patches()) != 0 {
+					t.Errorf("expected no patch, got %v", patches())
+				}
+				return
+			}
+			if len(patches()) != 1 {
+				t.Fatalf("expected 1 patch, got %v", patches())
+			}
+			var ops []struct {
+				Op    string                 `json:"op"`
+				Path  string                 `json:"path"`
+				Value corev1.PodTemplateSpec `json:"value"`
+			}
+			if err := json.Unmarshal([]byte(patches()[0]), &ops); err != nil {
+				t.Fatalf("invalid patch %s: %v", patches()[0], err)
+			}
+			if len(ops) != 1 || ops[0].Op != "replace" || ops[0].Path != "/spec/template" {
+				t.Fatalf("unexpected patch %s", patches()[0])
+			}
+			template := ops[0].Value
+			if template.Spec.Containers[0].Image != tt.wantImage || !reflect.DeepEqual(template.Labels, map[string]string{"app": "web"}) {
+				t.Errorf("unexpected patched template: %+v", template)
+			}
+		})
+	}
+}
+
+// readinessDeployment 构造期望 3 个副本、generation 为 2 的 Deployment，status 由 observed、updated、available 指定
+func readinessDeployment(observed int64, updated, available int32) *appsv1.Deployment {
+	replicas := int32(3)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: observed,
+			Replicas:           3,
+			UpdatedReplicas:    updated,
+			ReadyReplicas:      available,
+			AvailableReplicas:  available,
+		},
+	}
+}
+
+// TestWorkloadReadiness 测试 Deployment、StatefulSet 和 DaemonSet 发布过程中各阶段的就绪判断
+func TestWorkloadReadiness(t *testing.T) {
+	replicas := int32(3)
+	partition := int32(2)
+	statefulSet := func(strategy appsv1.StatefulSetUpdateStrategy, updated, ready int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Generation: 1},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, UpdateStrategy: strategy},
+			Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: updated, ReadyReplicas: ready},
+		}
+	}
+	daemonSet := func(updated, available int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", Generation: 4},
+			Status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     4,
+				DesiredNumberScheduled: 2,
+				UpdatedNumberScheduled: updated,
+				NumberReady:            available,
+				NumberAvailable:        available,
+			},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		obj   runtime.Object
+		ready bool
+	}{
+		{"deployment generation not observed", readinessDeployment(1, 3, 3), false},
+		{"deployment rolling", readinessDeployment(2, 1, 3), false},
+		{"deployment complete", readinessDeployment(2, 3, 3), true},
+		{"statefulset rolling", statefulSet(appsv1.StatefulSetUpdateStrategy{}, 2, 3), false},
+		{"statefulset partitioned", statefulSet(appsv1.StatefulSetUpdateStrategy{RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition}}, 1, 3), true},
+		{"statefulset on delete", statefulSet(appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, 0, 3), true},
+		{"statefulset not ready", statefulSet(appsv1.StatefulSetUpdateStrategy{}, 3, 2), false},
+		{"daemonset unavailable", daemonSet(2, 1), false},
+		{"daemonset ready", daemonSet(2, 2), true},
+	}
+	for _, tt := range tests {
+		readiness, _, err := workloadReadiness(tt.obj)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if readiness.Ready != tt.ready {
+			t.Errorf("%s: expected ready=%v, got %+v", tt.name, tt.ready, readiness)
+		}
+	}
+	if _, _, err := workloadReadiness(&corev1.Pod{}); err == nil {
+		t.Error("expected pods to be refused")
+	}
+}
+
+// TestWaitForReady 测试 Deployment 依次经过未观察到新 generation、滚动更新中和发布完成时，WaitForReady 在完成后返回就绪摘要
+func TestWaitForReady(t *testing.T) {
+	ro, client := newFakeOperations(t, readinessDeployment(1, 3, 3))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 依次写入各阶段的 status；最后一个阶段持续写入，确保在 watch 建立后也能收到
+	go func() {
+		stages := []appsv1.DeploymentStatus{
+			readinessDeployment(2, 1, 3).Status,
+			readinessDeployment(2, 2, 2).Status,
+			readinessDeployment(2, 3, 3).Status,
+		}
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			deployment, err := client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+			if err != nil {
+				return
+			}
+			deployment.Status = stages[min(i, len(stages)-1)]
+			client.AppsV1().Deployments("default").UpdateStatus(ctx, deployment, metav1.UpdateOptions{})
+		}
+	}()
+
+	readiness, err := ro.WaitForReady(ctx, ResourceTypeDeployments, "default", "web", 10*time.Second, "test")
+	if err != nil {
+		t.Fatalf("WaitForReady failed: %v", err)
+	}
+	if !readiness.Ready || readiness.TimedOut || readiness.ObservedGeneration != 2 || readiness.UpdatedReplicas != 3 || readiness.FailingPod != "" {
+		t.Errorf("unexpected readiness %+v", readiness)
+	}
+	if !strings.HasPrefix(readiness.Summary, "Deployment web is ready: generation 2 observed, 3/3 updated, 3 ready, 3 available (took ") {
+		t.Errorf("unexpected summary %q", readiness.Summary)
+	}
+
+	if _, err := ro.WaitForReady(ctx, ResourceTypeConfigMaps, "default", "web", time.Second, "test"); err == nil {
+		t.Error("expected configmaps to be refused")
+	}
+}
+
+// TestWaitForReadyTimeout 测试超时时返回未就绪的摘要，并给出第一个未就绪 (非终止中) 的 Pod 及原因
+func TestWaitForReadyTimeout(t *testing.T) {
+	now := metav1.Now()
+	pod := func(name string, ready corev1.ConditionStatus, waiting *corev1.ContainerStateWaiting) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "web", State: corev1.ContainerState{Waiting: waiting}}},
+			},
+		}
+	}
+	terminating := pod("web-0", corev1.ConditionFalse, nil)
+	terminating.DeletionTimestamp = &now
+	terminating.Finalizers = []string{"example.com/hold"}
+	ro, _ := newFakeOperations(t,
+		readinessDeployment(2, 3, 2),
+		terminating,
+		pod("web-a", corev1.ConditionTrue, nil),
+		pod("web-b", corev1.ConditionFalse, &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 10s restarting failed container=web"}),
+		pod("web-c", corev1.ConditionFalse, &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}),
+	)
+
+	readiness, err := ro.WaitForReady(context.Background(), ResourceTypeDeployment, "default", "web", 100*time.Millisecond, "test")
+	if err != nil {
+		t.Fatalf("WaitForReady failed: %v", err)
+	}
+	if readiness.Ready || !readiness.TimedOut || readiness.FailingPod != "web-b" || readiness.FailingReason != "CrashLoopBackOff: back-off 10s restarting failed container=web" {
+		t.Errorf("unexpected readiness %+v", readiness)
+	}
+	want := "Deployment web is not ready after 100ms: generation 2 observed, 3/3 updated, 2 ready, 2 available; first failing pod web-b: CrashLoopBackOff: back-off 10s restarting failed container=web"
+	if readiness.Summary != want {
+		t.Errorf("expected summary %q, got %q", want, readiness.Summary)
+	}
+}
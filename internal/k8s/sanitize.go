@@ -0,0 +1,71 @@
+package k8s
+
+// meaningfulEmptyFields are kept even when empty: an empty emptyDir still declares
+// the volume type, and an empty selector selects everything
+// meaningfulEmptyFields 即使为空也保留：空的 emptyDir 仍声明了卷类型，空的选择器表示选择全部
+var meaningfulEmptyFields = map[string]bool{
+	"emptyDir":          true,
+	"selector":          true,
+	"podSelector":       true,
+	"namespaceSelector": true,
+}
+
+// SanitizeObject removes noise that rarely helps reading an object but dominates
+// its size: metadata.managedFields (unless keepManagedFields), the last-applied
+// configuration annotation, and null values, empty maps and empty lists at any
+// depth. It works on the generic map form, so it applies to any kind, including
+// custom resources. Zero numbers, false and empty strings are kept since they
+// can be meaningful, as are the empty fields listed in meaningfulEmptyFields.
+// SanitizeObject 删除对阅读帮助很小却占据大部分体积的内容：metadata.managedFields
+// （keepManagedFields 时保留）、last-applied 注解，以及任意层级的 null、空 map 和空列表。
+// 它作用于通用 map 形式，因此适用于任何类型，包括自定义资源。数值 0、false、空字符串以及 meaningfulEmptyFields 中的空字段可能有意义，予以保留。
+func SanitizeObject(obj map[string]interface{}, keepManagedFields bool) {
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		if !keepManagedFields {
+			delete(metadata, "managedFields")
+		}
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+		}
+	}
+	pruneEmpty(obj)
+}
+
+// pruneEmpty removes null values, empty maps and empty lists from m recursively,
+// including maps left empty by the removal
+// pruneEmpty 递归删除 m 中的 null、空 map 和空列表，包括删除后变为空的 map
+func pruneEmpty(m map[string]interface{}) {
+	for key, value := range m {
+		if isEmptyValue(pruneValue(value)) && !(value != nil && meaningfulEmptyFields[key]) {
+			delete(m, key)
+		}
+	}
+}
+
+// pruneValue prunes the maps nested in value and returns it
+// pruneValue 清理 value 中嵌套的 map 并返回 value
+func pruneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		pruneEmpty(v)
+	case []interface{}:
+		for _, item := range v {
+			pruneValue(item)
+		}
+	}
+	return value
+}
+
+// isEmptyValue reports whether value is null, an empty map or an empty list
+// isEmptyValue 判断 value 是否为 null、空 map 或空列表
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestSanitizeSizeReduction 使用具有代表性的 Deployment 和 Pod（含 managedFields、last-applied 注解和 conditions）
+// 生成清理前后的 golden 文件，并检查体积减少的比例
+func TestSanitizeSizeReduction(t *testing.T) {
+	tests := []struct {
+		name         string
+		object       interface{}
+		minReduction float64
+	}{
+		{name: "deployment", object: &appsv1.Deployment{}, minReduction: 0.5},
+		{name: "pod", object: &corev1.Pod{}, minReduction: 0.3},
+	}
+
+	ro := NewResourceOperations(NewClusterManager(nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "sanitize", tt.name+".input.json"))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			if err := json.Unmarshal(data, tt.object); err != nil {
+				t.Fatalf("failed to decode fixture: %v", err)
+			}
+
+			raw, err := ro.SerializeResource(tt.object, &SerializeOptions{IncludeRaw: true})
+			if err != nil {
+				t.Fatalf("SerializeResource failed: %v", err)
+			}
+			sanitized, err := ro.SerializeResource(tt.object, nil)
+			if err != nil {
+				t.Fatalf("SerializeResource failed: %v", err)
+			}
+			assertGolden(t, filepath.Join("sanitize", tt.name+".raw.json"), raw)
+			assertGolden(t, filepath.Join("sanitize", tt.name+".sanitized.json"), sanitized)
+
+			reduction := 1 - float64(len(sanitized))/float64(len(raw))
+			t.Logf("%s: %d -> %d bytes (%.0f%% smaller)", tt.name, len(raw), len(sanitized), reduction*100)
+			if reduction < tt.minReduction {
+				t.Errorf("expected at least %.0f%% reduction, got %.0f%%", tt.minReduction*100, reduction*100)
+			}
+		})
+	}
+}
+
+// TestSanitizeObject 测试通用 map（如自定义资源）的清理规则
+func TestSanitizeObject(t *testing.T) {
+	obj := map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":          "w",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: "{}",
+			},
+			"labels": map[string]interface{}{"app": "w"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(0),
+			"paused":   false,
+			"note":     "",
+			"extra":    nil,
+			"items":    []interface{}{},
+			"nested":   map[string]interface{}{"inner": map[string]interface{}{"list": []interface{}{}}},
+			"selector": map[string]interface{}{},
+			"volumes":  []interface{}{map[string]interface{}{"name": "scratch", "emptyDir": map[string]interface{}{}}},
+		},
+		"status": map[string]interface{}{},
+	}
+
+	SanitizeObject(obj, false)
+
+	want := map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":   "w",
+			"labels": map[string]interface{}{"app": "w"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(0),
+			"paused":   false,
+			"note":     "",
+			"selector": map[string]interface{}{},
+			"volumes":  []interface{}{map[string]interface{}{"name": "scratch", "emptyDir": map[string]interface{}{}}},
+		},
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("unexpected sanitized object:\n%#v", obj)
+	}
+}
@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultSearchMaxResults is the default cap on search results
+// DefaultSearchMaxResults 搜索结果的默认上限
+const DefaultSearchMaxResults = 200
+
+// defaultSearchResourceTypes are searched when no resource types are given
+// defaultSearchResourceTypes 未指定资源类型时搜索的类型
+var defaultSearchResourceTypes = []ResourceType{
+	ResourceTypePods,
+	ResourceTypeDeployments,
+	ResourceTypeStatefulSets,
+	ResourceTypeServices,
+	ResourceTypeConfigMaps,
+	ResourceTypeSecrets,
+}
+
+// SearchOptions holds the parameters of a resource search
+// SearchOptions 资源搜索参数
+type SearchOptions struct {
+	// Query is a case-insensitive substring matched against resource names
+	// Query 对资源名称进行不区分大小写的子串匹配
+	Query string
+	// ResourceTypes limits the search to these types (defaults to common workload types)
+	// ResourceTypes 限定搜索的资源类型（默认为常见工作负载类型）
+	ResourceTypes []ResourceType
+	// LabelSelector filters resources by labels (e.g. "app=payments,tier!=cache")
+	// LabelSelector 按标签过滤资源（例如 "app=payments,tier!=cache"）
+	LabelSelector string
+	// Namespace to search in; empty searches all namespaces
+	// Namespace 搜索的命名空间，为空时搜索所有命名空间
+	Namespace string
+	// MaxResults caps the number of returned matches (defaults to DefaultSearchMaxResults)
+	// MaxResults 返回结果的上限（默认为 DefaultSearchMaxResults）
+	MaxResults int
+}
+
+// SearchResult holds the matches of a resource search
+// SearchResult 资源搜索结果
+type SearchResult struct {
+	Matches   []ResourceInfo `json:"matches"`
+	Total     int            `json:"total"`
+	Truncated bool           `json:"truncated"`
+}
+
+// SearchResources finds resources whose name contains the query across the selected types
+// SearchResources 在指定类型中查找名称包含查询字符串的资源
+func (ro *ResourceOperations) SearchResources(ctx context.Context, opts SearchOptions, clusterName string) (*SearchResult, error) {
+	selector := labels.Everything()
+	if opts.LabelSelector != "" {
+		var err error
+		selector, err = labels.Parse(opts.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", opts.LabelSelector, err)
+		}
+	}
+
+	resourceTypes := opts.ResourceTypes
+	if len(resourceTypes) == 0 {
+		resourceTypes = defaultSearchResourceTypes
+	}
+
+	var candidates []ResourceInfo
+	for _, resourceType := range resourceTypes {
+		resources, err := ro.ListResourcesByType(ctx, resourceType, opts.Namespace, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		infos, err := ToResourceInfos(resources)
+		if err != nil {
+			return nil, fmt.Errorf("resource type %s cannot be searched: %w", resourceType, err)
+		}
+		candidates = append(candidates, infos...)
+	}
+
+	return filterResourceInfos(candidates, opts.Query, selector, opts.MaxResults), nil
+}
+
+// filterResourceInfos keeps resources matching the name query and selector, sorted by
+// namespace, kind and name and capped at maxResults
+// filterResourceInfos 保留名称和标签选择器都匹配的资源，按命名空间、类型和名称排序，数量不超过 maxResults
+func filterResourceInfos(candidates []ResourceInfo, query string, selector labels.Selector, maxResults int) *SearchResult {
+	if maxResults <= 0 {
+		maxResults = DefaultSearchMaxResults
+	}
+	query = strings.ToLower(query)
+
+	matches := []ResourceInfo{}
+	for _, info := range candidates {
+		if query != "" && !strings.Contains(strings.ToLower(info.Name), query) {
+			continue
+		}
+		if !selector.Matches(labels.Set(info.Labels)) {
+			continue
+		}
+		matches = append(matches, info)
+	}
+
+	// sort before cutting, so the same matches are kept whatever order the candidates came in
+	// 先排序再截断，无论候选资源的顺序如何都保留相同的匹配项
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	result := &SearchResult{Matches: matches, Total: len(matches)}
+	if len(matches) > maxResults {
+		result.Matches, result.Truncated = matches[:maxResults], true
+	}
+	return result
+}
+
+// ToResourceInfos converts the typed results of ListResourcesByType to ResourceInfo
+// ToResourceInfos 将 ListResourcesByType 返回的类型化结果转换为 ResourceInfo
+func ToResourceInfos(resources interface{}) ([]ResourceInfo, error) {
+	var infos []ResourceInfo
+	switch list := resources.(type) {
+	case []ResourceInfo:
+		infos = list
+	case []types.Pod:
+		for _, pod := range list {
+			infos = append(infos, ResourceInfo{Name: pod.Name, Namespace: pod.Namespace, Kind: "Pod", Status: pod.Status, Restarts: pod.Restarts, Owner: pod.Owner, OwnerKind: pod.OwnerKind, Age: pod.Age, CreatedAt: pod.CreatedAt, Labels: pod.Labels})
+		}
+	case []types.Service:
+		for _, svc := range list {
+			infos = append(infos, ResourceInfo{Name: svc.Name, Namespace: svc.Namespace, Kind: "Service", Status: svc.Type, Age: svc.Age, CreatedAt: svc.CreatedAt, Labels: svc.Labels})
+		}
+	case []types.Deployment:
+		for _, dep := range list {
+			infos = append(infos, ResourceInfo{Name: dep.Name, Namespace: dep.Namespace, Kind: "Deployment", Status: "Ready: " + dep.Ready, Age: dep.Age, CreatedAt: dep.CreatedAt, Labels: dep.Labels})
+		}
+	case []types.StatefulSet:
+		for _, ss := range list {
+			infos = append(infos, ResourceInfo{Name: ss.Name, Namespace: ss.Namespace, Kind: "StatefulSet", Status: "Ready: " + ss.Ready, Age: ss.Age, CreatedAt: ss.CreatedAt, Labels: ss.Labels})
+		}
+	case []types.ConfigMap:
+		for _, cm := range list {
+			infos = append(infos, ResourceInfo{Name: cm.Name, Namespace: cm.Namespace, Kind: "ConfigMap", Status: fmt.Sprintf("Data: %d", cm.DataCount), Age: cm.Age, CreatedAt: cm.CreatedAt, Labels: cm.Labels})
+		}
+	case []types.Namespace:
+		for _, ns := range list {
+			infos = append(infos, ResourceInfo{Name: ns.Name, Kind: "Namespace", Status: ns.Status, Age: ns.Age, CreatedAt: ns.CreatedAt, Labels: ns.Labels})
+		}
+	case []types.Node:
+		for _, node := range list {
+			infos = append(infos, ResourceInfo{Name: node.Name, Kind: "Node", Status: node.Status, Age: node.Age, CreatedAt: node.CreatedAt, Labels: node.Labels})
+		}
+	case []types.PersistentVolume:
+		for _, pv := range list {
+			infos = append(infos, ResourceInfo{Name: pv.Name, Kind: "PersistentVolume", Status: pv.Status, Age: pv.Age, CreatedAt: pv.CreatedAt, Labels: pv.Labels})
+		}
+	case []types.PersistentVolumeClaim:
+		for _, pvc := range list {
+			infos = append(infos, ResourceInfo{Name: pvc.Name, Namespace: pvc.Namespace, Kind: "PersistentVolumeClaim", Status: pvc.Status, Age: pvc.Age, CreatedAt: pvc.CreatedAt, Labels: pvc.Labels})
+		}
+	case []types.Ingress:
+		for _, ing := range list {
+			address := ing.Address
+			if address == "" {
+				address = "<pending>"
+			}
+			infos = append(infos, ResourceInfo{Name: ing.Name, Namespace: ing.Namespace, Kind: "Ingress", Status: fmt.Sprintf("Hosts: %s, Address: %s", strings.Join(ing.Hosts, ","), address), Age: ing.Age, CreatedAt: ing.CreatedAt, Labels: ing.Labels})
+		}
+	case []types.NetworkPolicy:
+		for _, np := range list {
+			infos = append(infos, ResourceInfo{Name: np.Name, Namespace: np.Namespace, Kind: "NetworkPolicy", Status: fmt.Sprintf("Pods: %s, Ingress: %s, Egress: %s", np.PodSelector, np.Ingress, np.Egress), Age: np.Age, CreatedAt: np.CreatedAt, Labels: np.Labels})
+		}
+	case []types.HorizontalPodAutoscaler:
+		for _, hpa := range list {
+			infos = append(infos, ResourceInfo{Name: hpa.Name, Namespace: hpa.Namespace, Kind: "HorizontalPodAutoscaler", Status: fmt.Sprintf("Target: %s, Replicas: %d/%d (min %d, max %d), Metrics: %s", hpa.Target, hpa.CurrentReplicas, hpa.DesiredReplicas, hpa.MinReplicas, hpa.MaxReplicas, formatHPAMetrics(hpa.Metrics)), Age: hpa.Age, CreatedAt: hpa.CreatedAt, Labels: hpa.Labels})
+		}
+	case []types.PodDisruptionBudget:
+		for _, pdb := range list {
+			infos = append(infos, ResourceInfo{Name: pdb.Name, Namespace: pdb.Namespace, Kind: "PodDisruptionBudget", Status: fmt.Sprintf("MinAvailable: %s, MaxUnavailable: %s, Allowed disruptions: %d", pdb.MinAvailable, pdb.MaxUnavailable, pdb.AllowedDisruptions), Age: pdb.Age, CreatedAt: pdb.CreatedAt, Labels: pdb.Labels})
+		}
+	case []types.CronJob:
+		for _, cj := range list {
+			infos = append(infos, ResourceInfo{Name: cj.Name, Namespace: cj.Namespace, Kind: "CronJob", Status: fmt.Sprintf("Schedule: %s, Suspend: %t, Active: %d, Last schedule: %s", cj.Schedule, cj.Suspend, cj.Active, cj.LastSchedule), Age: cj.Age, CreatedAt: cj.CreatedAt, Labels: cj.Labels})
+		}
+	case []types.Job:
+		for _, job := range list {
+			infos = append(infos, ResourceInfo{Name: job.Name, Namespace: job.Namespace, Kind: "Job", Status: fmt.Sprintf("%s, Completions: %s, Failed: %d, Duration: %s", job.Status, job.Completions, job.Failed, job.Duration), Age: job.Age, CreatedAt: job.CreatedAt, Labels: job.Labels})
+		}
+	case []types.DistroResource:
+		for _, item := range list {
+			infos = append(infos, ResourceInfo{Name: item.Name, Namespace: item.Namespace, Kind: item.Kind, Status: distroStatus(item), Age: item.Age, CreatedAt: item.CreatedAt, Labels: item.Labels})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported result type %T", resources)
+	}
+	return infos, nil
+}
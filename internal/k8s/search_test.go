@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TestFilterResourceInfos 测试名称子串和标签选择器过滤
+func TestFilterResourceInfos(t *testing.T) {
+	candidates := []ResourceInfo{
+		{Name: "payments-api", Namespace: "prod", Kind: "Deployment", Labels: map[string]string{"app": "payments", "tier": "api"}},
+		{Name: "payments-worker", Namespace: "prod", Kind: "Deployment", Labels: map[string]string{"app": "payments", "tier": "worker"}},
+		{Name: "Payments-Config", Namespace: "dev", Kind: "ConfigMap"},
+		{Name: "orders-api", Namespace: "prod", Kind: "Deployment", Labels: map[string]string{"app": "orders"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		selector string
+		want     []string
+	}{
+		{"substring case-insensitive", "payments", "", []string{"dev/Payments-Config", "prod/payments-api", "prod/payments-worker"}},
+		{"label selector", "", "app=payments,tier!=worker", []string{"prod/payments-api"}},
+		{"query and selector", "api", "app", []string{"prod/orders-api", "prod/payments-api"}},
+		{"no match", "billing", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := labels.Parse(tt.selector)
+			if err != nil {
+				t.Fatalf("invalid selector: %v", err)
+			}
+			result := filterResourceInfos(candidates, tt.query, selector, 0)
+
+			var got []string
+			for _, info := range result.Matches {
+				got = append(got, info.Namespace+"/"+info.Name)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if result.Total != len(tt.want) || result.Truncated {
+				t.Errorf("unexpected total/truncated: %d/%v", result.Total, result.Truncated)
+			}
+		})
+	}
+}
+
+// TestFilterResourceInfosTruncated 测试结果数量上限和截断标记，以及截断前先排序
+func TestFilterResourceInfosTruncated(t *testing.T) {
+	var candidates []ResourceInfo
+	for i := 9; i >= 0; i-- {
+		candidates = append(candidates, ResourceInfo{Name: fmt.Sprintf("pod-%d", i), Namespace: "default", Kind: "Pod"})
+	}
+
+	result := filterResourceInfos(candidates, "pod", labels.Everything(), 3)
+	if len(result.Matches) != 3 || result.Total != 10 || !result.Truncated {
+		t.Errorf("expected 3 of 10 truncated matches, got %d of %d (truncated=%v)", len(result.Matches), result.Total, result.Truncated)
+	}
+	// 候选资源为倒序时仍保留排序后的前 3 个
+	for i, match := range result.Matches {
+		if want := fmt.Sprintf("pod-%d", i); match.Name != want {
+			t.Errorf("expected match %d to be %s, got %s", i, want, match.Name)
+		}
+	}
+}
+
+// TestToResourceInfos 测试类型化列表到 ResourceInfo 的转换
+func TestToResourceInfos(t *testing.T) {
+	infos, err := ToResourceInfos([]types.Pod{{Name: "web-0", Namespace: "default", Status: "Running", Labels: map[string]string{"app": "web"}}})
+	if err != nil {
+		t.Fatalf("ToResourceInfos failed: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Kind != "Pod" || infos[0].Labels["app"] != "web" {
+		t.Errorf("unexpected conversion: %+v", infos)
+	}
+
+	if _, err := ToResourceInfos([]types.Event{{Reason: "Scheduled"}}); err == nil {
+		t.Errorf("expected error for events")
+	}
+}
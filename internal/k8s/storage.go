@@ -0,0 +1,183 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// accessModeAbbreviations are the short access mode names kubectl prints
+// accessModeAbbreviations kubectl 显示的访问模式缩写
+var accessModeAbbreviations = map[corev1.PersistentVolumeAccessMode]string{
+	corev1.ReadWriteOnce:    "RWO",
+	corev1.ReadOnlyMany:     "ROX",
+	corev1.ReadWriteMany:    "RWX",
+	corev1.ReadWriteOncePod: "RWOP",
+}
+
+// ListPersistentVolumes lists persistent volumes in the cluster
+// ListPersistentVolumes 列出集群中的 PersistentVolume
+func (ro *ResourceOperations) ListPersistentVolumes(ctx context.Context, clusterName string) ([]types.PersistentVolume, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumes: %w", err)
+	}
+
+	var results []types.PersistentVolume
+	for _, pv := range volumes.Items {
+		claim := ""
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			claim = ref.Namespace + "/" + ref.Name
+		}
+		results = append(results, types.PersistentVolume{
+			Name:          pv.Name,
+			Capacity:      formatStorage(pv.Spec.Capacity),
+			AccessModes:   formatAccessModes(pv.Spec.AccessModes),
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+			Status:        string(pv.Status.Phase),
+			Claim:         claim,
+			StorageClass:  pv.Spec.StorageClassName,
+			Age:           formatAge(pv.CreationTimestamp),
+			CreatedAt:     formatTimestamp(pv.CreationTimestamp),
+			Labels:        pv.Labels,
+		})
+	}
+
+	return results, nil
+}
+
+// ListPersistentVolumeClaims lists persistent volume claims in a namespace. Pending
+// claims carry the message of their latest event, which usually says why
+// provisioning or binding is stuck.
+// ListPersistentVolumeClaims 列出命名空间中的 PersistentVolumeClaim。Pending 状态的 PVC
+// 附带最近一条相关事件的消息，通常说明了供应或绑定卡住的原因。
+func (ro *ResourceOperations) ListPersistentVolumeClaims(ctx context.Context, namespace, clusterName string) ([]types.PersistentVolumeClaim, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]types.PersistentVolumeClaim, error) {
+			return ro.ListPersistentVolumeClaims(ctx, ns, clusterName)
+		})
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumeclaims: %w", err)
+	}
+
+	var results []types.PersistentVolumeClaim
+	pending := false
+	for _, pvc := range claims.Items {
+		storageClass := ""
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		if pvc.Status.Phase == corev1.ClaimPending {
+			pending = true
+		}
+		results = append(results, types.PersistentVolumeClaim{
+			Name:         pvc.Name,
+			Namespace:    pvc.Namespace,
+			Status:       string(pvc.Status.Phase),
+			Volume:       pvc.Spec.VolumeName,
+			Capacity:     formatStorage(pvc.Status.Capacity),
+			AccessModes:  formatAccessModes(pvc.Status.AccessModes),
+			StorageClass: storageClass,
+			Age:          formatAge(pvc.CreationTimestamp),
+			CreatedAt:    formatTimestamp(pvc.CreationTimestamp),
+			Labels:       pvc.Labels,
+		})
+	}
+
+	// One extra list call, only when something is pending; the messages are best effort
+	// 只有存在 Pending 的 PVC 时才额外查询一次事件，消息仅尽力提供
+	if pending {
+		messages := claimEventMessages(ctx, client, namespace)
+		for i := range results {
+			if results[i].Status == string(corev1.ClaimPending) {
+				results[i].Message = messages[results[i].Namespace+"/"+results[i].Name]
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// claimEventMessages returns the message of the latest event of each claim in a
+// namespace, keyed by namespace/name; failures return no messages
+// claimEventMessages 返回命名空间中每个 PVC 最近一条事件的消息，键为 namespace/name，失败时返回空
+func claimEventMessages(ctx context.Context, client kubernetes.Interface, namespace string) map[string]string {
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.kind", "PersistentVolumeClaim").String(),
+	})
+	if err != nil {
+		return nil
+	}
+
+	messages := make(map[string]string)
+	latest := make(map[string]metav1.Time)
+	for _, event := range events.Items {
+		key := event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+		lastSeen := event.LastTimestamp
+		if lastSeen.IsZero() {
+			lastSeen = metav1.NewTime(event.EventTime.Time)
+		}
+		if seen, ok := latest[key]; ok && lastSeen.Before(&seen) {
+			continue
+		}
+		latest[key] = lastSeen
+		messages[key] = event.Message
+	}
+	return messages
+}
+
+// formatStorage returns the storage quantity of a resource list, or "" if unset
+// formatStorage 返回资源列表中的存储容量，未设置时返回空字符串
+func formatStorage(list corev1.ResourceList) string {
+	if quantity, ok := list[corev1.ResourceStorage]; ok {
+		return quantity.String()
+	}
+	return ""
+}
+
+// formatAccessModes formats access modes the way kubectl does, e.g. "RWO,ROX"
+// formatAccessModes 以 kubectl 的方式格式化访问模式，例如 "RWO,ROX"
+func formatAccessModes(modes []corev1.PersistentVolumeAccessMode) string {
+	abbreviations := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		if abbreviation, ok := accessModeAbbreviations[mode]; ok {
+			abbreviations = append(abbreviations, abbreviation)
+		} else {
+			abbreviations = append(abbreviations, string(mode))
+		}
+	}
+	return strings.Join(abbreviations, ",")
+}
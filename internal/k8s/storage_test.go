@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeStorageAPIServer 模拟 PV、PVC 和 PVC 事件的列表接口，返回的函数给出收到的事件字段选择器
+func fakeStorageAPIServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	storage := func(size string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+	}
+	standard := "standard"
+	responses := map[string]interface{}{
+		"/api/v1/persistentvolumes": corev1.PersistentVolumeList{Items: []corev1.PersistentVolume{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-bound"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity:                      storage("10Gi"),
+					AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+					StorageClassName:              "standard",
+					ClaimRef:                      &corev1.ObjectReference{Namespace: "shop", Name: "data"},
+				},
+				Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-released"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity:                      storage("5Gi"),
+					AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany, corev1.ReadOnlyMany},
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+					ClaimRef:                      &corev1.ObjectReference{Namespace: "shop", Name: "old"},
+				},
+				Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+			},
+		}},
+		"/api/v1/namespaces/shop/persistentvolumeclaims": corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-bound", StorageClassName: &standard},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Phase:       corev1.ClaimBound,
+					Capacity:    storage("10Gi"),
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "shop"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &standard},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+			},
+		}},
+		"/api/v1/namespaces/shop/events": corev1.EventList{Items: []corev1.Event{
+			{
+				ObjectMeta:     metav1.ObjectMeta{Name: "cache.1", Namespace: "shop"},
+				InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "cache"},
+				Message:        "waiting for first consumer to be created before binding",
+				LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)),
+			},
+			{
+				ObjectMeta:     metav1.ObjectMeta{Name: "cache.2", Namespace: "shop"},
+				InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "shop", Name: "cache"},
+				Message:        `storageclass.storage.k8s.io "standard" not found`,
+				LastTimestamp:  metav1.NewTime(time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)),
+			},
+		}},
+	}
+
+	var selectors []string
+	server := fakeAPIServer(t, responses, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/v1/namespaces/shop/events" {
+			selectors = append(selectors, r.URL.Query().Get("fieldSelector"))
+		}
+		return false
+	})
+	return server, func() []string { return selectors }
+}
+
+// TestListPersistentVolumes 测试 Bound 和 Released 状态的 PV
+func TestListPersistentVolumes(t *testing.T) {
+	server, _ := fakeStorageAPIServer(t)
+	ro := newServerOperations(t, server)
+
+	volumes, err := ro.ListPersistentVolumes(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("ListPersistentVolumes failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want types.PersistentVolume
+	}{
+		{"pv-bound", types.PersistentVolume{Name: "pv-bound", Capacity: "10Gi", AccessModes: "RWO", ReclaimPolicy: "Delete", Status: "Bound", Claim: "shop/data", StorageClass: "standard", Age: "<unknown>"}},
+		{"pv-released", types.PersistentVolume{Name: "pv-released", Capacity: "5Gi", AccessModes: "RWX,ROX", ReclaimPolicy: "Retain", Status: "Released", Claim: "shop/old", Age: "<unknown>"}},
+	}
+	if len(volumes) != len(tests) {
+		t.Fatalf("expected %d volumes, got %+v", len(tests), volumes)
+	}
+	for i, tt := range tests {
+		if got := volumes[i]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestListPersistentVolumeClaims 测试 Bound 和 Pending 状态的 PVC，Pending 的 PVC 附带最新的事件消息
+func TestListPersistentVolumeClaims(t *testing.T) {
+	server, selectors := fakeStorageAPIServer(t)
+	ro := newServerOperations(t, server)
+
+	claims, err := ro.ListPersistentVolumeClaims(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("ListPersistentVolumeClaims failed: %v", err)
+	}
+	if len(claims) != 2 {
+		t.Fatalf("expected 2 claims, got %+v", claims)
+	}
+
+	bound, pending := claims[0], claims[1]
+	if bound.Status != "Bound" || bound.Volume != "pv-bound" || bound.Capacity != "10Gi" || bound.AccessModes != "RWO" || bound.Message != "" {
+		t.Errorf("unexpected bound claim: %+v", bound)
+	}
+	if pending.Status != "Pending" || pending.Volume != "" || pending.StorageClass != "standard" {
+		t.Errorf("unexpected pending claim: %+v", pending)
+	}
+	if pending.Message != `storageclass.storage.k8s.io "standard" not found` {
+		t.Errorf("expected the latest event message, got %q", pending.Message)
+	}
+	if got := selectors(); len(got) != 1 || got[0] != "involvedObject.kind=PersistentVolumeClaim" {
+		t.Errorf("unexpected event queries: %v", got)
+	}
+}
@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeWindow is the period a time-bounded listing covers, resolved to absolute UTC times.
+// A zero Since or Until leaves that end open. SinceDuration keeps a relative since so APIs
+// with a relative form (such as sinceSeconds of pod logs) are not affected by clock skew
+// TimeWindow 是按时间过滤的列表所覆盖的时间段，已解析为绝对 UTC 时间。Since 或 Until 为零值表示该端不限制。
+// SinceDuration 保存相对的 since，使支持相对形式的 API (如 Pod 日志的 sinceSeconds) 不受时钟偏差影响
+type TimeWindow struct {
+	Since         time.Time
+	Until         time.Time
+	SinceDuration time.Duration
+	// Now is the time relative bounds were resolved against
+	// Now 为解析相对时间时使用的当前时间
+	Now time.Time
+}
+
+// ParseTimeWindow resolves since and until arguments against now, truncated to seconds. Each is empty (open),
+// "now", an RFC3339 timestamp with a timezone (Z or an offset such as +08:00, converted to
+// UTC) or a duration before now ("90s", "15m", "2h", "1d", "1d12h"). A window whose until
+// is before its since is rejected
+// ParseTimeWindow 以截断到秒的 now 为基准解析 since 和 until 参数。每个参数可以为空 (不限制)、"now"、带时区的 RFC3339 时间戳
+// (Z 或 +08:00 之类的偏移，转换为 UTC) 或距现在的时长 ("90s"、"15m"、"2h"、"1d"、"1d12h")。until 早于 since 时返回错误
+func ParseTimeWindow(since, until string, now time.Time) (TimeWindow, error) {
+	now = now.UTC().Truncate(time.Second)
+	window := TimeWindow{Now: now}
+
+	var err error
+	window.Since, window.SinceDuration, err = parseTimeBound("since", since, now)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	window.Until, _, err = parseTimeBound("until", until, now)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+
+	if !window.Since.IsZero() && !window.Until.IsZero() && window.Until.Before(window.Since) {
+		return TimeWindow{}, fmt.Errorf("until (%s) is before since (%s)", formatWindowTime(window.Until), formatWindowTime(window.Since))
+	}
+	return window, nil
+}
+
+// parseTimeBound resolves one bound of a window; the duration is set for relative values
+// parseTimeBound 解析时间段的一端；相对值同时返回其时长
+func parseTimeBound(name, value string, now time.Time) (time.Time, time.Duration, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return time.Time{}, 0, nil
+	case strings.EqualFold(value, "now"):
+		return now, 0, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.UTC(), 0, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return time.Time{}, 0, fmt.Errorf("invalid %s %q: the timestamp has no timezone, add Z for UTC or an offset such as +08:00", name, value)
+		}
+	}
+
+	d, err := parseWindowDuration(value)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid %s %q: expected an RFC3339 timestamp such as 2024-05-02T10:00:00Z or a duration before now such as 15m, 2h or 1d", name, value)
+	}
+	if d < 0 {
+		return time.Time{}, 0, fmt.Errorf("invalid %s %q: the duration counts back from now and must not be negative", name, value)
+	}
+	return now.Add(-d), d, nil
+}
+
+// parseWindowDuration parses a Go duration with an optional leading day count ("1d", "2d6h")
+// parseWindowDuration 解析 Go 时长，可以以天数开头 ("1d"、"2d6h")
+func parseWindowDuration(value string) (time.Duration, error) {
+	days, rest, ok := strings.Cut(value, "d")
+	if !ok {
+		return time.ParseDuration(value)
+	}
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid day count %q", days)
+	}
+	d := time.Duration(n) * 24 * time.Hour
+	if rest != "" {
+		extra, err := time.ParseDuration(rest)
+		if err != nil || extra < 0 {
+			return 0, fmt.Errorf("invalid duration %q", rest)
+		}
+		d += extra
+	}
+	return d, nil
+}
+
+// IsZero reports whether the window leaves both ends open
+// IsZero 判断时间段是否两端都不限制
+func (w TimeWindow) IsZero() bool {
+	return w.Since.IsZero() && w.Until.IsZero()
+}
+
+// Contains reports whether t falls within the window, bounds included
+// Contains 判断 t 是否在时间段内 (包含两端)
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	return w.Until.IsZero() || !t.After(w.Until)
+}
+
+// String renders the window as absolute UTC times, e.g.
+// "2024-05-02T09:45:00Z (15m ago) to 2024-05-02T10:00:00Z (now)", so the reader knows the
+// exact period regardless of its own notion of the current time
+// String 将时间段渲染为绝对 UTC 时间，例如 "2024-05-02T09:45:00Z (15m ago) to 2024-05-02T10:00:00Z (now)"，
+// 使读者无论如何理解当前时间都能知道确切的时间段
+func (w TimeWindow) String() string {
+	since := "the oldest available"
+	if !w.Since.IsZero() {
+		since = formatWindowTime(w.Since)
+		if w.SinceDuration > 0 {
+			since += " (" + formatWindowDuration(w.SinceDuration) + " ago)"
+		}
+	}
+	until := formatWindowTime(w.Now) + " (now)"
+	if !w.Until.IsZero() && !w.Until.Equal(w.Now) {
+		until = formatWindowTime(w.Until)
+	}
+	return since + " to " + until
+}
+
+// formatWindowTime formats a bound as RFC3339 in UTC, with fractional seconds only when set
+// formatWindowTime 将时间格式化为 UTC 的 RFC3339，只在有小数秒时显示小数秒
+func formatWindowTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatWindowDuration formats a relative since the way it is usually written ("15m", "2h", "1d12h")
+// formatWindowDuration 按常见写法格式化相对的 since ("15m"、"2h"、"1d12h")
+func formatWindowDuration(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	s := ""
+	if days > 0 {
+		s = strconv.Itoa(int(days)) + "d"
+	}
+	if d > 0 || s == "" {
+		rendered := d.String()
+		if strings.HasSuffix(rendered, "m0s") {
+			rendered = strings.TrimSuffix(rendered, "0s")
+		}
+		if strings.HasSuffix(rendered, "h0m") {
+			rendered = strings.TrimSuffix(rendered, "0m")
+		}
+		s += rendered
+	}
+	return s
+}
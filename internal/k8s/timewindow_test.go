@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseTimeWindow 测试相对时长、带时区的时间戳 (转换为 UTC)、now、开放的端点以及各种无效输入
+func TestParseTimeWindow(t *testing.T) {
+	now := time.Date(2024, 5, 2, 10, 0, 0, 500, time.UTC)
+	at := func(value string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return parsed
+	}
+
+	tests := []struct {
+		name, since, until string
+		wantSince          time.Time
+		wantUntil          time.Time
+		wantDuration       time.Duration
+		wantText           string
+		wantErr            string
+	}{
+		{name: "open", wantText: "the oldest available to 2024-05-02T10:00:00Z (now)"},
+		{name: "relative minutes", since: "15m", wantSince: at("2024-05-02T09:45:00Z"), wantDuration: 15 * time.Minute,
+			wantText: "2024-05-02T09:45:00Z (15m ago) to 2024-05-02T10:00:00Z (now)"},
+		{name: "relative days", since: "1d12h", until: "2h", wantSince: at("2024-04-30T22:00:00Z"), wantUntil: at("2024-05-02T08:00:00Z"),
+			wantDuration: 36 * time.Hour, wantText: "2024-04-30T22:00:00Z (1d12h ago) to 2024-05-02T08:00:00Z"},
+		{name: "mixed units", since: "1h30m", wantSince: at("2024-05-02T08:30:00Z"), wantDuration: 90 * time.Minute,
+			wantText: "2024-05-02T08:30:00Z (1h30m ago) to 2024-05-02T10:00:00Z (now)"},
+		{name: "offset converted to UTC", since: "2024-05-02T15:30:00+08:00", until: "now", wantSince: at("2024-05-02T07:30:00Z"),
+			wantUntil: at("2024-05-02T10:00:00Z"), wantText: "2024-05-02T07:30:00Z to 2024-05-02T10:00:00Z (now)"},
+		{name: "fractional seconds", since: "2024-05-02T09:59:59.25Z", wantSince: at("2024-05-02T09:59:59Z").Add(250 * time.Millisecond),
+			wantText: "2024-05-02T09:59:59.25Z to 2024-05-02T10:00:00Z (now)"},
+		{name: "until only", until: "2024-05-01T00:00:00Z", wantUntil: at("2024-05-01T00:00:00Z"),
+			wantText: "the oldest available to 2024-05-01T00:00:00Z"},
+		{name: "no timezone", since: "2024-05-02T09:00:00", wantErr: `invalid since "2024-05-02T09:00:00": the timestamp has no timezone`},
+		{name: "garbage", until: "yesterday", wantErr: `invalid until "yesterday": expected an RFC3339 timestamp`},
+		{name: "bad day count", since: "xd", wantErr: `invalid since "xd"`},
+		{name: "negative", since: "-5m", wantErr: `invalid since "-5m": the duration counts back from now and must not be negative`},
+		{name: "until before since", since: "1h", until: "2h", wantErr: "until (2024-05-02T08:00:00Z) is before since (2024-05-02T09:00:00Z)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := ParseTimeWindow(tt.since, tt.until, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeWindow failed: %v", err)
+			}
+			if !window.Since.Equal(tt.wantSince) || !window.Until.Equal(tt.wantUntil) || window.SinceDuration != tt.wantDuration {
+				t.Errorf("unexpected window %+v", window)
+			}
+			if window.Since.Location() != time.UTC && !window.Since.IsZero() {
+				t.Errorf("expected since in UTC, got %v", window.Since)
+			}
+			if got := window.String(); got != tt.wantText {
+				t.Errorf("String() = %q, want %q", got, tt.wantText)
+			}
+		})
+	}
+}
+
+// TestTimeWindowContains 测试两端都包含在时间段内
+func TestTimeWindowContains(t *testing.T) {
+	window, err := ParseTimeWindow("2024-05-02T09:00:00Z", "2024-05-02T10:00:00Z", time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ParseTimeWindow failed: %v", err)
+	}
+	for value, want := range map[string]bool{
+		"2024-05-02T08:59:59Z": false,
+		"2024-05-02T09:00:00Z": true,
+		"2024-05-02T10:00:00Z": true,
+		"2024-05-02T10:00:01Z": false,
+	} {
+		at, _ := time.Parse(time.RFC3339, value)
+		if got := window.Contains(at); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", value, got, want)
+		}
+	}
+}
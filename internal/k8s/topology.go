@@ -0,0 +1,360 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Relations of the topology edges
+// 关系图中边的关系
+const (
+	TopologyRoutes  = "routes"
+	TopologySelects = "selects"
+	TopologyOwns    = "owns"
+)
+
+// Flags marking problems on topology nodes
+// 标记关系图节点问题的标志
+const (
+	// TopologyOrphaned marks a service whose selector matches no pod
+	// TopologyOrphaned 标记选择器不匹配任何 Pod 的 Service
+	TopologyOrphaned = "orphaned"
+	// TopologyUnowned marks a pod without a controller
+	// TopologyUnowned 标记没有控制器的 Pod
+	TopologyUnowned = "unowned"
+	// TopologyMissing marks a service an ingress routes to that does not exist
+	// TopologyMissing 标记 Ingress 指向但不存在的 Service
+	TopologyMissing = "missing"
+)
+
+// GetWorkloadTopology maps what talks to what in a namespace: ingresses to their
+// backend services, services to the pods their selector matches, and pods to the
+// deployment, statefulset or other controller owning them
+// GetWorkloadTopology 描绘命名空间中的调用关系：Ingress 到后端 Service，Service 到其选择器匹配的 Pod，
+// 以及 Pod 到拥有它的 Deployment、StatefulSet 或其他控制器
+func (ro *ResourceOperations) GetWorkloadTopology(ctx context.Context, namespace, clusterName string) (*types.WorkloadTopology, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	services, err := client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	ingresses, err := client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	return buildTopology(namespace, pods.Items, services.Items, replicaSets.Items, ingresses.Items), nil
+}
+
+// topologyBuilder collects the nodes and edges of a topology
+// topologyBuilder 收集关系图的节点和边
+type topologyBuilder struct {
+	nodes map[string]*types.TopologyNode
+	edges []types.TopologyEdge
+}
+
+// node returns the node of kind/name, adding it if needed
+// node 返回 kind/name 对应的节点，不存在时添加
+func (b *topologyBuilder) node(kind, name string) *types.TopologyNode {
+	id := kind + "/" + name
+	if node, ok := b.nodes[id]; ok {
+		return node
+	}
+	node := &types.TopologyNode{ID: id, Kind: kind, Name: name}
+	b.nodes[id] = node
+	return node
+}
+
+// buildTopology builds the topology graph of a namespace from its objects and renders it as text
+// buildTopology 根据命名空间中的对象构建关系图，并渲染为文本
+func buildTopology(namespace string, pods []corev1.Pod, services []corev1.Service, replicaSets []appsv1.ReplicaSet, ingresses []networkingv1.Ingress) *types.WorkloadTopology {
+	b := &topologyBuilder{nodes: make(map[string]*types.TopologyNode)}
+	topology := &types.WorkloadTopology{Namespace: namespace}
+
+	// Deployments own their pods through a ReplicaSet
+	// Deployment 通过 ReplicaSet 拥有其 Pod
+	deploymentOf := make(map[string]string)
+	for i := range replicaSets {
+		if owner := metav1.GetControllerOf(&replicaSets[i]); owner != nil && owner.Kind == "Deployment" {
+			deploymentOf[replicaSets[i].Name] = owner.Name
+		}
+	}
+	for i := range pods {
+		pod := &pods[i]
+		node := b.node("Pod", pod.Name)
+		node.Status = getPodStatus(pod)
+
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			node.Flags = append(node.Flags, TopologyUnowned)
+			topology.UnownedPods = append(topology.UnownedPods, pod.Name)
+			continue
+		}
+		kind, name := owner.Kind, owner.Name
+		if deployment, ok := deploymentOf[name]; ok && kind == "ReplicaSet" {
+			kind, name = "Deployment", deployment
+		}
+		b.edges = append(b.edges, types.TopologyEdge{From: b.node(kind, name).ID, To: node.ID, Relation: TopologyOwns})
+	}
+
+	for i := range services {
+		service := &services[i]
+		node := b.node("Service", service.Name)
+		// Services without a selector have their endpoints managed by hand
+		// 没有选择器的 Service 由人工管理其端点
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(service.Spec.Selector)
+		matched := false
+		for j := range pods {
+			if selector.Matches(labels.Set(pods[j].Labels)) {
+				b.edges = append(b.edges, types.TopologyEdge{From: node.ID, To: "Pod/" + pods[j].Name, Relation: TopologySelects})
+				matched = true
+			}
+		}
+		if !matched {
+			node.Flags = append(node.Flags, TopologyOrphaned)
+			topology.OrphanedServices = append(topology.OrphanedServices, service.Name)
+		}
+	}
+
+	for i := range ingresses {
+		ing := &ingresses[i]
+		node := b.node("Ingress", ing.Name)
+		route := func(backend networkingv1.IngressBackend, detail string) {
+			if backend.Service == nil {
+				return
+			}
+			if _, ok := b.nodes["Service/"+backend.Service.Name]; !ok {
+				b.node("Service", backend.Service.Name).Flags = []string{TopologyMissing}
+			}
+			port := backend.Service.Port.Name
+			if port == "" {
+				port = fmt.Sprint(backend.Service.Port.Number)
+			}
+			b.edges = append(b.edges, types.TopologyEdge{
+				From:     node.ID,
+				To:       "Service/" + backend.Service.Name,
+				Relation: TopologyRoutes,
+				Detail:   fmt.Sprintf("%s (port %s)", detail, port),
+			})
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			host := rule.Host
+			if host == "" {
+				host = "*"
+			}
+			for _, path := range rule.HTTP.Paths {
+				p := path.Path
+				if p == "" {
+					p = "/"
+				}
+				route(path.Backend, host+p)
+			}
+		}
+		if ing.Spec.DefaultBackend != nil {
+			route(*ing.Spec.DefaultBackend, "default backend")
+		}
+	}
+
+	topology.Nodes = make([]types.TopologyNode, 0, len(b.nodes))
+	for _, node := range b.nodes {
+		topology.Nodes = append(topology.Nodes, *node)
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		a, c := topology.Nodes[i], topology.Nodes[j]
+		if topologyRank(a.Kind) != topologyRank(c.Kind) {
+			return topologyRank(a.Kind) < topologyRank(c.Kind)
+		}
+		return a.ID < c.ID
+	})
+	sort.SliceStable(b.edges, func(i, j int) bool {
+		if b.edges[i].From != b.edges[j].From {
+			return b.edges[i].From < b.edges[j].From
+		}
+		return b.edges[i].To < b.edges[j].To
+	})
+	topology.Edges = b.edges
+	if topology.Edges == nil {
+		topology.Edges = []types.TopologyEdge{}
+	}
+	sort.Strings(topology.OrphanedServices)
+	sort.Strings(topology.UnownedPods)
+	topology.Text = renderTopology(topology)
+	return topology
+}
+
+// topologyRank orders node kinds from the edge of the cluster inwards
+// topologyRank 按从集群入口到内部的顺序排列节点类型
+func topologyRank(kind string) int {
+	switch kind {
+	case "Ingress":
+		return 0
+	case "Service":
+		return 1
+	case "Pod":
+		return 3
+	default:
+		return 2
+	}
+}
+
+// renderTopology renders a topology as an indented tree: each ingress with the
+// services it routes to, each service with the pods it selects grouped by owner,
+// then the services no ingress routes to, the workloads no service selects and the
+// unowned pods no service selects
+// renderTopology 将关系图渲染为缩进的树：每个 Ingress 及其路由到的 Service，每个 Service 及其选择的 Pod（按控制器分组），
+// 然后是没有 Ingress 路由到的 Service、没有被 Service 选择的工作负载以及没有被 Service 选择的无控制器 Pod
+func renderTopology(topology *types.WorkloadTopology) string {
+	if len(topology.Nodes) == 0 {
+		return fmt.Sprintf("No ingresses, services or pods in namespace %s", topology.Namespace)
+	}
+
+	nodes := make(map[string]types.TopologyNode, len(topology.Nodes))
+	for _, node := range topology.Nodes {
+		nodes[node.ID] = node
+	}
+	ownerOf := make(map[string]string)
+	owned := make(map[string][]string)
+	selected := make(map[string][]string)
+	routed := make(map[string][]types.TopologyEdge)
+	isSelected := make(map[string]bool)
+	isRouted := make(map[string]bool)
+	for _, edge := range topology.Edges {
+		switch edge.Relation {
+		case TopologyOwns:
+			ownerOf[edge.To] = edge.From
+			owned[edge.From] = append(owned[edge.From], edge.To)
+		case TopologySelects:
+			selected[edge.From] = append(selected[edge.From], edge.To)
+			isSelected[edge.To] = true
+		case TopologyRoutes:
+			routed[edge.From] = append(routed[edge.From], edge)
+			isRouted[edge.To] = true
+		}
+	}
+
+	var sb strings.Builder
+	line := func(depth int, id, detail string) {
+		node := nodes[id]
+		sb.WriteString(strings.Repeat("  ", depth) + id)
+		if node.Status != "" {
+			fmt.Fprintf(&sb, " (%s)", node.Status)
+		}
+		if len(node.Flags) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(node.Flags, ", "))
+		}
+		if detail != "" {
+			sb.WriteString(" " + detail)
+		}
+		sb.WriteString("\n")
+	}
+	// pods writes pods grouped under their owners, owned pods first
+	// pods 将 Pod 按控制器分组输出，有控制器的 Pod 在前
+	pods := func(depth int, ids []string) {
+		var owners []string
+		byOwner := make(map[string][]string)
+		var unowned []string
+		for _, id := range ids {
+			owner, ok := ownerOf[id]
+			if !ok {
+				unowned = append(unowned, id)
+				continue
+			}
+			if _, seen := byOwner[owner]; !seen {
+				owners = append(owners, owner)
+			}
+			byOwner[owner] = append(byOwner[owner], id)
+		}
+		sort.Strings(owners)
+		for _, owner := range owners {
+			line(depth, owner, "")
+			for _, id := range byOwner[owner] {
+				line(depth+1, id, "")
+			}
+		}
+		for _, id := range unowned {
+			line(depth, id, "")
+		}
+	}
+	service := func(depth int, id, detail string) {
+		line(depth, id, detail)
+		pods(depth+1, selected[id])
+	}
+
+	for _, node := range topology.Nodes {
+		switch {
+		case node.Kind == "Ingress":
+			line(0, node.ID, "")
+			for _, edge := range routed[node.ID] {
+				service(1, edge.To, "<- "+edge.Detail)
+			}
+		case node.Kind == "Service" && !isRouted[node.ID]:
+			service(0, node.ID, "")
+		}
+	}
+
+	// Workloads and pods reached by no service
+	// 没有被任何 Service 选择的工作负载和 Pod
+	var unselected []string
+	for _, node := range topology.Nodes {
+		if node.Kind != "Pod" || isSelected[node.ID] {
+			continue
+		}
+		if owner, ok := ownerOf[node.ID]; ok && anySelected(owned[owner], isSelected) {
+			continue
+		}
+		unselected = append(unselected, node.ID)
+	}
+	pods(0, unselected)
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// anySelected reports whether a service selects any of the pods
+// anySelected 判断是否有 Service 选择了其中任一 Pod
+func anySelected(pods []string, isSelected map[string]bool) bool {
+	for _, pod := range pods {
+		if isSelected[pod] {
+			return true
+		}
+	}
+	return false
+}
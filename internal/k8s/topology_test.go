@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// topologyFixture 返回一个命名空间中的对象：Deployment web（经 ReplicaSet 拥有两个 Pod）、StatefulSet db、
+// 无控制器的 Pod debug、选择器不匹配任何 Pod 的 Service api、没有选择器的 Service external，
+// 以及路由到 web、api 和不存在的 legacy 的 Ingress shop
+func topologyFixture() []runtime.Object {
+	controller := true
+	ownedBy := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	pod := func(name, app string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": app}, OwnerReferences: owners},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	service := func(name string, selector map[string]string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"}, Spec: corev1.ServiceSpec{Selector: selector}}
+	}
+	backend := func(name string, port networkingv1.ServiceBackendPort) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: name, Port: port}}
+	}
+	prefix := networkingv1.PathTypePrefix
+
+	return []runtime.Object{
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "shop", OwnerReferences: ownedBy("Deployment", "web")}},
+		pod("web-abc-1", "web", ownedBy("ReplicaSet", "web-abc")),
+		pod("web-abc-2", "web", ownedBy("ReplicaSet", "web-abc")),
+		pod("db-0", "db", ownedBy("StatefulSet", "db")),
+		pod("debug", "debug", nil),
+		service("web", map[string]string{"app": "web"}),
+		service("db", map[string]string{"app": "db"}),
+		service("api", map[string]string{"app": "api"}),
+		service("external", nil),
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{
+				DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "legacy", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "shop.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &prefix, Backend: backend("web", networkingv1.ServiceBackendPort{Number: 80})},
+						{Path: "/api", PathType: &prefix, Backend: backend("api", networkingv1.ServiceBackendPort{Name: "http"})},
+					}}},
+				}},
+			},
+		},
+	}
+}
+
+// TestGetWorkloadTopology 测试 Ingress 到 Service、Service 到 Pod、控制器到 Pod 的关系，孤立 Service 和无控制器 Pod 的标记，以及文本形式
+func TestGetWorkloadTopology(t *testing.T) {
+	ro, _ := newFakeOperations(t, topologyFixture()...)
+
+	topology, err := ro.GetWorkloadTopology(context.Background(), "shop", "test")
+	if err != nil {
+		t.Fatalf("GetWorkloadTopology failed: %v", err)
+	}
+
+	var edges []string
+	for _, edge := range topology.Edges {
+		edges = append(edges, edge.From+" "+edge.Relation+" "+edge.To)
+	}
+	wantEdges := []string{
+		"Deployment/web owns Pod/web-abc-1",
+		"Deployment/web owns Pod/web-abc-2",
+		"Ingress/shop routes Service/api",
+		"Ingress/shop routes Service/legacy",
+		"Ingress/shop routes Service/web",
+		"Service/db selects Pod/db-0",
+		"Service/web selects Pod/web-abc-1",
+		"Service/web selects Pod/web-abc-2",
+		"StatefulSet/db owns Pod/db-0",
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("unexpected edges:\n%v\nwant:\n%v", edges, wantEdges)
+	}
+
+	flags := make(map[string][]string)
+	var ids []string
+	for _, node := range topology.Nodes {
+		ids = append(ids, node.ID)
+		if len(node.Flags) > 0 {
+			flags[node.ID] = node.Flags
+		}
+	}
+	wantIDs := []string{
+		"Ingress/shop",
+		"Service/api", "Service/db", "Service/external", "Service/legacy", "Service/web",
+		"Deployment/web", "StatefulSet/db",
+		"Pod/db-0", "Pod/debug", "Pod/web-abc-1", "Pod/web-abc-2",
+	}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("unexpected nodes %v", ids)
+	}
+	wantFlags := map[string][]string{
+		"Service/api":    {TopologyOrphaned},
+		"Service/legacy": {TopologyMissing},
+		"Pod/debug":      {TopologyUnowned},
+	}
+	if !reflect.DeepEqual(flags, wantFlags) {
+		t.Errorf("unexpected flags %v", flags)
+	}
+	if !reflect.DeepEqual(topology.OrphanedServices, []string{"api"}) || !reflect.DeepEqual(topology.UnownedPods, []string{"debug"}) {
+		t.Errorf("unexpected orphaned services %v or unowned pods %v", topology.OrphanedServices, topology.UnownedPods)
+	}
+	if topology.Edges[3].Detail != "default backend (port 80)" || topology.Edges[2].Detail != "shop.example.com/api (port http)" {
+		t.Errorf("unexpected route details %+v %+v", topology.Edges[2], topology.Edges[3])
+	}
+
+	wantText := `Ingress/shop
+  Service/api [orphaned] <- shop.example.com/api (port http)
+  Service/legacy [missing] <- default backend (port 80)
+  Service/web <- shop.example.com/ (port 80)
+    Deployment/web
+      Pod/web-abc-1 (Running)
+      Pod/web-abc-2 (Running)
+Service/db
+  StatefulSet/db
+    Pod/db-0 (Running)
+Service/external
+Pod/debug (Running) [unowned]`
+	if topology.Text != wantText {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", topology.Text, wantText)
+	}
+}
+
+// TestWorkloadTopologyUnselectedWorkload 测试没有被 Service 选择的工作负载作为根节点列出，以及空命名空间
+func TestWorkloadTopologyUnselectedWorkload(t *testing.T) {
+	controller := true
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0", OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "worker", Controller: &controller}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}}
+	topology := buildTopology("jobs", pods, nil, nil, nil)
+	if want := "StatefulSet/worker\n  Pod/worker-0 (Pending)"; topology.Text != want {
+		t.Errorf("unexpected text:\n%s", topology.Text)
+	}
+
+	topology = buildTopology("empty", nil, nil, nil, nil)
+	if topology.Text != "No ingresses, services or pods in namespace empty" || topology.Edges == nil || len(topology.Nodes) != 0 {
+		t.Errorf("unexpected empty topology %+v", topology)
+	}
+}
@@ -0,0 +1,385 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// topUsagePods is how many pods GetResourceUsage ranks by CPU and by memory requests
+// topUsagePods 为 GetResourceUsage 按 CPU 和内存 requests 排名的 Pod 数量
+const topUsagePods = 10
+
+// usageResources are the resources GetResourceUsage sums
+// usageResources 为 GetResourceUsage 汇总的资源
+var usageResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// quotaUsageKeys maps the CPU and memory keys of a ResourceQuota to whether they bound
+// requests (true) or limits (false) and to the resource they bound
+// quotaUsageKeys 将 ResourceQuota 中的 CPU 和内存键映射为其限制的是 requests（true）还是 limits（false）以及对应资源
+var quotaUsageKeys = []struct {
+	key      corev1.ResourceName
+	requests bool
+	resource corev1.ResourceName
+}{
+	{corev1.ResourceCPU, true, corev1.ResourceCPU},
+	{corev1.ResourceRequestsCPU, true, corev1.ResourceCPU},
+	{corev1.ResourceLimitsCPU, false, corev1.ResourceCPU},
+	{corev1.ResourceMemory, true, corev1.ResourceMemory},
+	{corev1.ResourceRequestsMemory, true, corev1.ResourceMemory},
+	{corev1.ResourceLimitsMemory, false, corev1.ResourceMemory},
+}
+
+// GetResourceUsage sums the CPU and memory requests and limits of the non-terminated
+// pods of a namespace, or of all namespaces when namespace is empty, and compares them
+// against the ResourceQuota hard limits and, cluster-wide, the allocatable resources of
+// the nodes
+// GetResourceUsage 汇总命名空间（namespace 为空时为所有命名空间）中未终止 Pod 的 CPU 和内存 requests/limits，
+// 并与 ResourceQuota 硬限制以及（所有命名空间范围时）节点可分配资源对比
+func (ro *ResourceOperations) GetResourceUsage(ctx context.Context, namespace, clusterName string) (*types.ResourceUsage, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := ro.listActivePods(ctx, client, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := ro.listQuotas(ctx, client, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "namespace " + namespace
+	var nodes []corev1.Node
+	if namespace == "" {
+		scope = "all namespaces"
+		if ro.clusterManager.namespacePolicy.Restricted() {
+			scope = "allowed namespaces"
+		}
+		list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		switch {
+		case errors.Is(err, ErrNamespaceNotAllowed):
+			// Nodes are cluster-scoped and may be hidden in namespace-scoped mode
+			// 节点是集群级资源，命名空间受限模式下可能不可见
+		case err != nil:
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		default:
+			nodes = list.Items
+		}
+	}
+
+	return summarizeResourceUsage(scope, pods, quotas, nodes), nil
+}
+
+// listActivePods lists the pods of a namespace that hold resources, i.e. are neither succeeded nor failed
+// listActivePods 列出命名空间中占用资源的 Pod，即未成功结束也未失败的 Pod
+func (ro *ResourceOperations) listActivePods(ctx context.Context, client kubernetes.Interface, namespace, clusterName string) ([]corev1.Pod, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]corev1.Pod, error) {
+			return ro.listActivePods(ctx, client, ns, clusterName)
+		})
+	}
+
+	list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// listQuotas lists the resource quotas of a namespace
+// listQuotas 列出命名空间中的 ResourceQuota
+func (ro *ResourceOperations) listQuotas(ctx context.Context, client kubernetes.Interface, namespace, clusterName string) ([]corev1.ResourceQuota, error) {
+	if ro.fanOut(ctx, namespace) {
+		return listAllNamespaces(ctx, ro, clusterName, func(ctx context.Context, ns string) ([]corev1.ResourceQuota, error) {
+			return ro.listQuotas(ctx, client, ns, clusterName)
+		})
+	}
+
+	list, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	return list.Items, nil
+}
+
+// summarizeResourceUsage builds the usage summary of the pods against the quotas and,
+// when nodes is non-empty, against the nodes' allocatable resources
+// summarizeResourceUsage 汇总 Pod 的资源用量，并与 ResourceQuota 以及（nodes 非空时）节点可分配资源对比
+func summarizeResourceUsage(scope string, pods []corev1.Pod, quotas []corev1.ResourceQuota, nodes []corev1.Node) *types.ResourceUsage {
+	usage := &types.ResourceUsage{Scope: scope, Pods: len(pods)}
+
+	requested, limited := corev1.ResourceList{}, corev1.ResourceList{}
+	namespaceRequests := make(map[string]corev1.ResourceList)
+	namespaceLimits := make(map[string]corev1.ResourceList)
+	withoutRequests := make(map[corev1.ResourceName]int)
+	withoutLimits := make(map[corev1.ResourceName]int)
+	podUsage := make([]podUsageEntry, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		requests, limits := podRequestsAndLimits(pod)
+		addResourceList(requested, requests)
+		addResourceList(limited, limits)
+		if namespaceRequests[pod.Namespace] == nil {
+			namespaceRequests[pod.Namespace] = corev1.ResourceList{}
+			namespaceLimits[pod.Namespace] = corev1.ResourceList{}
+		}
+		addResourceList(namespaceRequests[pod.Namespace], requests)
+		addResourceList(namespaceLimits[pod.Namespace], limits)
+
+		missingLimit := make(map[corev1.ResourceName]bool)
+		for _, name := range usageResources {
+			if !allContainersSet(pod, name, containerRequests) {
+				withoutRequests[name]++
+			}
+			if !allContainersSet(pod, name, func(c corev1.Container) corev1.ResourceList { return c.Resources.Limits }) {
+				withoutLimits[name]++
+				missingLimit[name] = true
+			}
+		}
+		podUsage = append(podUsage, podUsageEntry{pod: pod, requests: requests, limits: limits, missingLimit: missingLimit})
+	}
+
+	allocatable := corev1.ResourceList{}
+	for i := range nodes {
+		addResourceList(allocatable, nodes[i].Status.Allocatable)
+	}
+	for _, name := range usageResources {
+		entry := types.ResourceUsageEntry{
+			Resource:            string(name),
+			Requests:            formatUsageQuantity(name, requested[name]),
+			Limits:              formatUsageQuantity(name, limited[name]),
+			PodsWithoutRequests: withoutRequests[name],
+			PodsWithoutLimits:   withoutLimits[name],
+		}
+		if total, ok := allocatable[name]; ok && !total.IsZero() {
+			entry.Allocatable = formatUsageQuantity(name, total)
+			entry.RequestsPercent = usagePercent(name, requested[name], total)
+			entry.LimitsPercent = usagePercent(name, limited[name], total)
+		}
+		usage.Resources = append(usage.Resources, entry)
+	}
+
+	sort.Slice(quotas, func(i, j int) bool {
+		if quotas[i].Namespace != quotas[j].Namespace {
+			return quotas[i].Namespace < quotas[j].Namespace
+		}
+		return quotas[i].Name < quotas[j].Name
+	})
+	for _, quota := range quotas {
+		hard := quota.Spec.Hard
+		if len(hard) == 0 {
+			hard = quota.Status.Hard
+		}
+		// A scoped quota only covers some pods, so its own accounting is used
+		// 带 scope 的 ResourceQuota 只统计部分 Pod，因此使用其自身的统计
+		scoped := len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil
+		for _, k := range quotaUsageKeys {
+			limit, ok := hard[k.key]
+			if !ok {
+				continue
+			}
+			used := namespaceLimits[quota.Namespace][k.resource]
+			if k.requests {
+				used = namespaceRequests[quota.Namespace][k.resource]
+			}
+			if scoped {
+				used = quota.Status.Used[k.key]
+			}
+			var percent float64
+			if p := usagePercent(k.resource, used, limit); p != nil {
+				percent = *p
+			}
+			usage.Quotas = append(usage.Quotas, types.QuotaUsage{
+				Namespace: quota.Namespace,
+				Quota:     quota.Name,
+				Resource:  string(k.key),
+				Used:      formatUsageQuantity(k.resource, used),
+				Hard:      formatUsageQuantity(k.resource, limit),
+				Percent:   percent,
+			})
+		}
+	}
+
+	usage.TopCPU = topPodUsage(podUsage, corev1.ResourceCPU)
+	usage.TopMemory = topPodUsage(podUsage, corev1.ResourceMemory)
+	usage.Text = renderResourceUsage(usage)
+	return usage
+}
+
+// podUsageEntry holds the effective requests and limits of a pod for ranking
+// podUsageEntry 保存 Pod 的有效 requests 和 limits，用于排名
+type podUsageEntry struct {
+	pod              *corev1.Pod
+	requests, limits corev1.ResourceList
+	missingLimit     map[corev1.ResourceName]bool
+}
+
+// allContainersSet reports whether every app container of a pod sets the resource in
+// the list returned by get
+// allContainersSet 判断 Pod 的每个应用容器是否都在 get 返回的列表中设置了该资源
+func allContainersSet(pod *corev1.Pod, name corev1.ResourceName, get func(corev1.Container) corev1.ResourceList) bool {
+	for _, c := range pod.Spec.Containers {
+		if _, ok := get(c)[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// topPodUsage returns the pods with the largest requests of a resource, largest first
+// topPodUsage 返回该资源 requests 最大的 Pod，按从大到小排列
+func topPodUsage(entries []podUsageEntry, name corev1.ResourceName) []types.PodResourceUsage {
+	sorted := append([]podUsageEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].requests[name], sorted[j].requests[name]
+		if c := a.Cmp(b); c != 0 {
+			return c > 0
+		}
+		if sorted[i].pod.Namespace != sorted[j].pod.Namespace {
+			return sorted[i].pod.Namespace < sorted[j].pod.Namespace
+		}
+		return sorted[i].pod.Name < sorted[j].pod.Name
+	})
+	if len(sorted) > topUsagePods {
+		sorted = sorted[:topUsagePods]
+	}
+
+	var top []types.PodResourceUsage
+	for _, entry := range sorted {
+		limit := func(name corev1.ResourceName) string {
+			if entry.missingLimit[name] {
+				return ""
+			}
+			return formatUsageQuantity(name, entry.limits[name])
+		}
+		top = append(top, types.PodResourceUsage{
+			Namespace:      entry.pod.Namespace,
+			Name:           entry.pod.Name,
+			CPURequests:    formatUsageQuantity(corev1.ResourceCPU, entry.requests[corev1.ResourceCPU]),
+			CPULimits:      limit(corev1.ResourceCPU),
+			MemoryRequests: formatUsageQuantity(corev1.ResourceMemory, entry.requests[corev1.ResourceMemory]),
+			MemoryLimits:   limit(corev1.ResourceMemory),
+		})
+	}
+	return top
+}
+
+// formatUsageQuantity formats CPU in cores or millicores ("2", "1500m") and memory in
+// binary SI units rounded to one decimal ("1.5Gi", "512Mi")
+// formatUsageQuantity 将 CPU 格式化为核数或毫核（"2"、"1500m"），内存格式化为二进制 SI 单位并保留一位小数（"1.5Gi"、"512Mi"）
+func formatUsageQuantity(name corev1.ResourceName, quantity resource.Quantity) string {
+	if name == corev1.ResourceCPU {
+		return resource.NewMilliQuantity(quantity.MilliValue(), resource.DecimalSI).String()
+	}
+
+	value := float64(quantity.Value())
+	unit := ""
+	for _, next := range []string{"Ki", "Mi", "Gi", "Ti", "Pi"} {
+		if value < 1024 {
+			break
+		}
+		value /= 1024
+		unit = next
+	}
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64) + unit
+}
+
+// usagePercent returns quantity as a percentage of total rounded to one decimal, or nil
+// when total is zero. CPU is compared in millicores and memory in bytes.
+// usagePercent 返回 quantity 占 total 的百分比（保留一位小数），total 为 0 时返回 nil。CPU 按毫核比较，内存按字节比较。
+func usagePercent(name corev1.ResourceName, quantity, total resource.Quantity) *float64 {
+	part, whole := quantity.Value(), total.Value()
+	if name == corev1.ResourceCPU {
+		part, whole = quantity.MilliValue(), total.MilliValue()
+	}
+	if whole == 0 {
+		return nil
+	}
+	percent := math.Round(float64(part)*1000/float64(whole)) / 10
+	return &percent
+}
+
+// renderResourceUsage renders a usage summary as text tables
+// renderResourceUsage 将资源用量汇总渲染为文本表格
+func renderResourceUsage(usage *types.ResourceUsage) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Resource usage in %s (%d pods):\n", usage.Scope, usage.Pods)
+
+	withPercent := func(value string, percent *float64) string {
+		if percent == nil {
+			return value
+		}
+		return fmt.Sprintf("%s (%s%%)", value, strconv.FormatFloat(*percent, 'f', -1, 64))
+	}
+	orNone := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tREQUESTS\tLIMITS\tALLOCATABLE\tPODS WITHOUT REQUESTS\tPODS WITHOUT LIMITS")
+	for _, entry := range usage.Resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n", entry.Resource, withPercent(entry.Requests, entry.RequestsPercent),
+			withPercent(entry.Limits, entry.LimitsPercent), orNone(entry.Allocatable), entry.PodsWithoutRequests, entry.PodsWithoutLimits)
+	}
+	tw.Flush()
+
+	if len(usage.Quotas) > 0 {
+		sb.WriteString("\nResource quotas:\n")
+		tw = tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tQUOTA\tRESOURCE\tUSED\tHARD\tUSED%")
+		for _, quota := range usage.Quotas {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s%%\n", quota.Namespace, quota.Quota, quota.Resource, quota.Used, quota.Hard,
+				strconv.FormatFloat(quota.Percent, 'f', -1, 64))
+		}
+		tw.Flush()
+	}
+
+	for _, top := range []struct {
+		title string
+		pods  []types.PodResourceUsage
+	}{{"CPU", usage.TopCPU}, {"memory", usage.TopMemory}} {
+		if len(top.pods) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\nTop pods by %s requests:\n", top.title)
+		tw = tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tNAME\tCPU REQUESTS\tCPU LIMITS\tMEMORY REQUESTS\tMEMORY LIMITS")
+		for _, pod := range top.pods {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", pod.Namespace, pod.Name, pod.CPURequests, orNone(pod.CPULimits),
+				pod.MemoryRequests, orNone(pod.MemoryLimits))
+		}
+		tw.Flush()
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,274 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// usageContainer 构造容器，requests 和 limits 以 "cpu,memory" 形式给出，空字符串表示不设置，单项为 "-" 表示不设置该项
+func usageContainer(requests, limits string) corev1.Container {
+	parse := func(value string) corev1.ResourceList {
+		if value == "" {
+			return nil
+		}
+		list := corev1.ResourceList{}
+		parts := strings.Split(value, ",")
+		for i, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if parts[i] != "-" {
+				list[name] = resource.MustParse(parts[i])
+			}
+		}
+		return list
+	}
+	return corev1.Container{Name: "c", Resources: corev1.ResourceRequirements{Requests: parse(requests), Limits: parse(limits)}}
+}
+
+// sidecar 将容器标记为可重启的 init 容器
+func sidecar(c corev1.Container) corev1.Container {
+	always := corev1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+	return c
+}
+
+// usagePod 创建带指定容器和 init 容器的 Pod
+func usagePod(namespace, name string, containers []corev1.Container, initContainers ...corev1.Container) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: containers, InitContainers: initContainers},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// formatUsageList 将 ResourceList 格式化为便于比较的字符串映射
+func formatUsageList(list corev1.ResourceList) map[string]string {
+	result := map[string]string{}
+	for name, quantity := range list {
+		result[string(name)] = formatUsageQuantity(name, quantity)
+	}
+	return result
+}
+
+// TestPodRequestsAndLimitsEdgeCases 测试有效 requests/limits 的计算：未设置 requests、只设置 limits、混合单位、
+// init 容器取最大值以及 sidecar 累加
+func TestPodRequestsAndLimitsEdgeCases(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []corev1.Container
+		initContainers []corev1.Container
+		wantRequests   map[string]string
+		wantLimits     map[string]string
+	}{
+		{
+			name:         "requests unset",
+			containers:   []corev1.Container{usageContainer("", "")},
+			wantRequests: map[string]string{},
+			wantLimits:   map[string]string{},
+		},
+		{
+			name:         "limits only default the requests",
+			containers:   []corev1.Container{usageContainer("", "500m,256Mi")},
+			wantRequests: map[string]string{"cpu": "500m", "memory": "256Mi"},
+			wantLimits:   map[string]string{"cpu": "500m", "memory": "256Mi"},
+		},
+		{
+			name:         "limit defaults only the missing request",
+			containers:   []corev1.Container{usageContainer("100m,-", "1,128Mi")},
+			wantRequests: map[string]string{"cpu": "100m", "memory": "128Mi"},
+			wantLimits:   map[string]string{"cpu": "1", "memory": "128Mi"},
+		},
+		{
+			name:         "mixed units",
+			containers:   []corev1.Container{usageContainer("1.5,1Gi", ""), usageContainer("250m,512Mi", ""), usageContainer("0.25,1G", "")},
+			wantRequests: map[string]string{"cpu": "2", "memory": "2.4Gi"},
+			wantLimits:   map[string]string{},
+		},
+		{
+			name:           "init container above the containers",
+			containers:     []corev1.Container{usageContainer("100m,64Mi", "200m,128Mi")},
+			initContainers: []corev1.Container{usageContainer("1,1Gi", "")},
+			wantRequests:   map[string]string{"cpu": "1", "memory": "1Gi"},
+			wantLimits:     map[string]string{"cpu": "200m", "memory": "128Mi"},
+		},
+		{
+			name:           "init containers below the containers",
+			containers:     []corev1.Container{usageContainer("500m,256Mi", ""), usageContainer("500m,256Mi", "")},
+			initContainers: []corev1.Container{usageContainer("800m,384Mi", ""), usageContainer("200m,128Mi", "")},
+			wantRequests:   map[string]string{"cpu": "1", "memory": "512Mi"},
+			wantLimits:     map[string]string{},
+		},
+		{
+			name:           "sidecars add to the containers and later init containers",
+			containers:     []corev1.Container{usageContainer("500m,256Mi", "")},
+			initContainers: []corev1.Container{sidecar(usageContainer("100m,64Mi", "")), usageContainer("550m,128Mi", "")},
+			wantRequests:   map[string]string{"cpu": "650m", "memory": "320Mi"},
+			wantLimits:     map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := usagePod("default", "p", tt.containers, tt.initContainers...)
+			requests, limits := podRequestsAndLimits(&pod)
+			if got := formatUsageList(requests); !reflect.DeepEqual(got, tt.wantRequests) {
+				t.Errorf("expected requests %v, got %v", tt.wantRequests, got)
+			}
+			if got := formatUsageList(limits); !reflect.DeepEqual(got, tt.wantLimits) {
+				t.Errorf("expected limits %v, got %v", tt.wantLimits, got)
+			}
+		})
+	}
+}
+
+// TestFormatUsageQuantity 测试 CPU 按毫核、内存按二进制 SI 单位格式化，以及百分比计算
+func TestFormatUsageQuantity(t *testing.T) {
+	tests := []struct {
+		name     corev1.ResourceName
+		quantity string
+		want     string
+	}{
+		{corev1.ResourceCPU, "1500m", "1500m"},
+		{corev1.ResourceCPU, "2", "2"},
+		{corev1.ResourceCPU, "0.1", "100m"},
+		{corev1.ResourceCPU, "0", "0"},
+		{corev1.ResourceMemory, "512Mi", "512Mi"},
+		{corev1.ResourceMemory, "1536Mi", "1.5Gi"},
+		{corev1.ResourceMemory, "500M", "476.8Mi"},
+		{corev1.ResourceMemory, "1000", "1000"},
+		{corev1.ResourceMemory, "0", "0"},
+	}
+	for _, tt := range tests {
+		if got := formatUsageQuantity(tt.name, resource.MustParse(tt.quantity)); got != tt.want {
+			t.Errorf("%s %s: expected %s, got %s", tt.name, tt.quantity, tt.want, got)
+		}
+	}
+
+	if got := usagePercent(corev1.ResourceCPU, resource.MustParse("750m"), resource.MustParse("2")); got == nil || *got != 37.5 {
+		t.Errorf("expected 37.5%%, got %v", got)
+	}
+	if got := usagePercent(corev1.ResourceMemory, resource.MustParse("1Gi"), resource.MustParse("3Gi")); got == nil || *got != 33.3 {
+		t.Errorf("expected 33.3%%, got %v", got)
+	}
+	if got := usagePercent(corev1.ResourceCPU, resource.MustParse("1"), resource.Quantity{}); got != nil {
+		t.Errorf("expected no percentage of zero, got %v", *got)
+	}
+}
+
+// TestSummarizeResourceUsage 测试汇总、与 ResourceQuota 和节点可分配资源的对比、未设置 requests/limits 的 Pod 计数以及排名
+func TestSummarizeResourceUsage(t *testing.T) {
+	pods := []corev1.Pod{
+		usagePod("shop", "web", []corev1.Container{usageContainer("500m,512Mi", "1,1Gi")}),
+		usagePod("shop", "worker", []corev1.Container{usageContainer("1,256Mi", "")}),
+		usagePod("batch", "report", []corev1.Container{usageContainer("", "")}),
+	}
+	for i := 0; i < 12; i++ {
+		pods = append(pods, usagePod("batch", fmt.Sprintf("job-%02d", i), []corev1.Container{usageContainer("10m,16Mi", "")}))
+	}
+	quotas := []corev1.ResourceQuota{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "shop"},
+			Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:  resource.MustParse("2"),
+				corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+				corev1.ResourcePods:         resource.MustParse("10"),
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort", Namespace: "batch"},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeNotBestEffort},
+			},
+			Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}},
+		},
+	}
+	nodes := []corev1.Node{
+		{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")}}},
+		{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")}}},
+	}
+
+	usage := summarizeResourceUsage("all namespaces", pods, quotas, nodes)
+	if usage.Pods != 15 {
+		t.Errorf("expected 15 pods, got %d", usage.Pods)
+	}
+
+	cpu, memory := usage.Resources[0], usage.Resources[1]
+	if cpu.Requests != "1620m" || cpu.Limits != "1" || cpu.Allocatable != "4" || *cpu.RequestsPercent != 40.5 || *cpu.LimitsPercent != 25 {
+		t.Errorf("unexpected cpu usage %+v", cpu)
+	}
+	if cpu.PodsWithoutRequests != 1 || cpu.PodsWithoutLimits != 14 {
+		t.Errorf("unexpected cpu pod counts %+v", cpu)
+	}
+	if memory.Requests != "960Mi" || memory.Limits != "1Gi" || memory.Allocatable != "8Gi" || *memory.RequestsPercent != 11.7 {
+		t.Errorf("unexpected memory usage %+v", memory)
+	}
+
+	var quotaRows []string
+	for _, q := range usage.Quotas {
+		quotaRows = append(quotaRows, fmt.Sprintf("%s/%s %s %s/%s %v", q.Namespace, q.Quota, q.Resource, q.Used, q.Hard, q.Percent))
+	}
+	wantQuotas := []string{
+		"batch/best-effort memory 256Mi/1Gi 25",
+		"shop/compute requests.cpu 1500m/2 75",
+		"shop/compute limits.memory 1Gi/4Gi 25",
+	}
+	if !reflect.DeepEqual(quotaRows, wantQuotas) {
+		t.Errorf("unexpected quotas %v", quotaRows)
+	}
+
+	if len(usage.TopCPU) != topUsagePods || usage.TopCPU[0].Name != "worker" || usage.TopCPU[1].Name != "web" || usage.TopCPU[2].Name != "job-00" {
+		t.Errorf("unexpected top cpu %+v", usage.TopCPU)
+	}
+	if usage.TopMemory[0].Name != "web" || usage.TopMemory[0].MemoryLimits != "1Gi" || usage.TopMemory[1].MemoryLimits != "" {
+		t.Errorf("unexpected top memory %+v", usage.TopMemory)
+	}
+
+	for _, want := range []string{
+		"Resource usage in all namespaces (15 pods):",
+		"cpu       1620m (40.5%)",
+		"shop       compute      requests.cpu   1500m  2     75%",
+		"Top pods by memory requests:",
+		"shop       web     500m          1           512Mi            1Gi",
+		"shop       worker  1             -           256Mi            -",
+	} {
+		if !strings.Contains(usage.Text, want) {
+			t.Errorf("expected %q in the text:\n%s", want, usage.Text)
+		}
+	}
+}
+
+// TestGetResourceUsage 测试单个命名空间不与节点对比，所有命名空间时与节点可分配资源对比，且已结束的 Pod 不计入
+func TestGetResourceUsage(t *testing.T) {
+	web := usagePod("shop", "web", []corev1.Container{usageContainer("500m,512Mi", "")})
+	done := usagePod("shop", "migrate", []corev1.Container{usageContainer("2,2Gi", "")})
+	done.Status.Phase = corev1.PodSucceeded
+	other := usagePod("batch", "report", []corev1.Container{usageContainer("250m,256Mi", "")})
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")}},
+	}
+	ro, _ := newFakeOperations(t, &web, &done, &other, node)
+	ctx := context.Background()
+
+	usage, err := ro.GetResourceUsage(ctx, "shop", "test")
+	if err != nil {
+		t.Fatalf("GetResourceUsage failed: %v", err)
+	}
+	if usage.Scope != "namespace shop" || usage.Pods != 1 || usage.Resources[0].Requests != "500m" || usage.Resources[0].Allocatable != "" {
+		t.Errorf("unexpected namespace usage %+v", usage)
+	}
+
+	usage, err = ro.GetResourceUsage(ctx, "", "test")
+	if err != nil {
+		t.Fatalf("GetResourceUsage failed: %v", err)
+	}
+	if usage.Scope != "all namespaces" || usage.Pods != 2 || usage.Resources[0].Requests != "750m" || usage.Resources[0].Allocatable != "4" {
+		t.Errorf("unexpected cluster usage %+v", usage.Resources)
+	}
+}
@@ -0,0 +1,440 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConditionDeleted is met once the object no longer exists; it applies to every kind
+// ConditionDeleted 在对象不存在时满足，适用于所有类型
+const ConditionDeleted = "Deleted"
+
+// waitRetryInterval is the pause before re-watching after the server closes a watch
+// waitRetryInterval 服务器关闭监听后重新监听前的等待时间
+const waitRetryInterval = time.Second
+
+// ErrWaitTimeout is returned by WaitForCondition when the timeout expires first
+// ErrWaitTimeout 表示 WaitForCondition 在条件满足前超时
+var ErrWaitTimeout = errors.New("timed out waiting for condition")
+
+// waitTarget knows how to fetch and watch one kind and which conditions it supports
+// waitTarget 描述某种资源的获取和监听方式以及支持的条件
+type waitTarget struct {
+	conditions []string
+	get        func(ctx context.Context, name string) (runtime.Object, error)
+	watch      func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	// evaluate reports whether condition is met and summarizes the observed status
+	// evaluate 判断条件是否满足，并汇总观察到的状态
+	evaluate func(obj runtime.Object, condition string) (bool, string)
+}
+
+// newWaitTarget returns the waitTarget for a resource type
+// newWaitTarget 返回资源类型对应的 waitTarget
+func newWaitTarget(client kubernetes.Interface, resourceType ResourceType, namespace string) (*waitTarget, error) {
+	switch resourceType {
+	case ResourceTypePods, ResourceTypePod:
+		pods := client.CoreV1().Pods(namespace)
+		return &waitTarget{
+			conditions: []string{"Ready", "ContainersReady", "Initialized", "PodScheduled", "Running", "Succeeded", "Failed"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return pods.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return pods.Watch(ctx, opts)
+			},
+			evaluate: evaluatePod,
+		}, nil
+	case ResourceTypeDeployments, ResourceTypeDeployment:
+		deployments := client.AppsV1().Deployments(namespace)
+		return &waitTarget{
+			conditions: []string{"Available", "Progressing", "Complete"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return deployments.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return deployments.Watch(ctx, opts)
+			},
+			evaluate: evaluateDeployment,
+		}, nil
+	case ResourceTypeStatefulSets, ResourceTypeStatefulSet:
+		statefulSets := client.AppsV1().StatefulSets(namespace)
+		return &waitTarget{
+			conditions: []string{"Ready"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return statefulSets.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return statefulSets.Watch(ctx, opts)
+			},
+			evaluate: evaluateStatefulSet,
+		}, nil
+	case ResourceTypeDaemonSets, ResourceTypeDaemonSet:
+		daemonSets := client.AppsV1().DaemonSets(namespace)
+		return &waitTarget{
+			conditions: []string{"Ready"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return daemonSets.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return daemonSets.Watch(ctx, opts)
+			},
+			evaluate: evaluateDaemonSet,
+		}, nil
+	case ResourceTypeNodes, ResourceTypeNode:
+		nodes := client.CoreV1().Nodes()
+		return &waitTarget{
+			conditions: []string{"Ready"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return nodes.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return nodes.Watch(ctx, opts)
+			},
+			evaluate: evaluateNode,
+		}, nil
+	case ResourceTypeNamespaces, ResourceTypeNamespace:
+		namespaces := client.CoreV1().Namespaces()
+		return &waitTarget{
+			conditions: []string{"Active"},
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return namespaces.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return namespaces.Watch(ctx, opts)
+			},
+			evaluate: evaluateNamespace,
+		}, nil
+	case ResourceTypeServices, ResourceTypeService:
+		services := client.CoreV1().Services(namespace)
+		return &waitTarget{
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return services.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return services.Watch(ctx, opts)
+			},
+		}, nil
+	case ResourceTypeConfigMaps, ResourceTypeConfigMap:
+		configMaps := client.CoreV1().ConfigMaps(namespace)
+		return &waitTarget{
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return configMaps.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return configMaps.Watch(ctx, opts)
+			},
+		}, nil
+	case ResourceTypeSecrets, ResourceTypeSecret:
+		secrets := client.CoreV1().Secrets(namespace)
+		return &waitTarget{
+			get: func(ctx context.Context, name string) (runtime.Object, error) {
+				return secrets.Get(ctx, name, metav1.GetOptions{})
+			},
+			watch: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+				return secrets.Watch(ctx, opts)
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported resource type for wait: %s", resourceType)
+	}
+}
+
+// canonicalCondition matches condition case-insensitively against the supported ones
+// canonicalCondition 不区分大小写地将 condition 匹配到支持的条件
+func (t *waitTarget) canonicalCondition(resourceType ResourceType, condition string) (string, error) {
+	supported := append([]string{}, t.conditions...)
+	supported = append(supported, ConditionDeleted)
+	for _, c := range supported {
+		if strings.EqualFold(c, condition) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported condition %q for %s (supported: %s)", condition, resourceType, strings.Join(supported, ", "))
+}
+
+// WaitForCondition waits until the named object meets condition, watching the single
+// object instead of polling. It returns as soon as the condition holds, ErrWaitTimeout
+// (with the last observed status in the result) when timeout expires first, and the
+// context error when ctx is cancelled. The watch is always stopped before returning.
+// WaitForCondition 通过监听单个对象（而非轮询）等待其满足条件。条件满足时立即返回；
+// 超时返回 ErrWaitTimeout（结果中包含最后观察到的状态）；ctx 取消时返回 context 错误。返回前总会停止监听。
+func (ro *ResourceOperations) WaitForCondition(ctx context.Context, resourceType ResourceType, namespace, name, condition string, timeout time.Duration, clusterName string) (*types.WaitResult, error) {
+	if name == "" || condition == "" {
+		return nil, fmt.Errorf("name and condition are required")
+	}
+
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := newWaitTarget(client, resourceType, namespace)
+	if err != nil {
+		return nil, err
+	}
+	condition, err = target.canonicalCondition(resourceType, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := &types.WaitResult{Condition: condition}
+	finish := func(met bool) *types.WaitResult {
+		result.Met = met
+		result.Elapsed = time.Since(start).Round(time.Millisecond).String()
+		return result
+	}
+	// observe evaluates obj (nil when the object does not exist) and records its status
+	// observe 评估 obj（对象不存在时为 nil）并记录其状态
+	observe := func(obj runtime.Object) bool {
+		if obj == nil {
+			result.Status = "not found"
+			return condition == ConditionDeleted
+		}
+		if condition == ConditionDeleted {
+			result.Status = "exists"
+			if accessor, err := metav1ObjectOf(obj); err == nil && accessor.GetDeletionTimestamp() != nil {
+				result.Status = "terminating"
+			}
+			return false
+		}
+		met, status := target.evaluate(obj, condition)
+		result.Status = status
+		return met
+	}
+
+	for {
+		// Read the current state, then watch from its resourceVersion so no change is missed
+		// 先读取当前状态，再从其 resourceVersion 开始监听，避免遗漏变化
+		obj, err := target.get(waitCtx, name)
+		resourceVersion := ""
+		switch {
+		case apierrors.IsNotFound(err):
+			obj = nil
+		case err != nil:
+			return waitError(ctx, waitCtx, finish(false), fmt.Errorf("failed to get %s %s: %w", resourceType, name, err))
+		default:
+			if accessor, err := metav1ObjectOf(obj); err == nil {
+				resourceVersion = accessor.GetResourceVersion()
+			}
+		}
+		if observe(obj) {
+			return finish(true), nil
+		}
+
+		w, err := target.watch(waitCtx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			return waitError(ctx, waitCtx, finish(false), fmt.Errorf("failed to watch %s %s: %w", resourceType, name, err))
+		}
+		met, err := watchUntil(waitCtx, w, observe)
+		w.Stop()
+		if met {
+			return finish(true), nil
+		}
+		if err != nil {
+			return waitError(ctx, waitCtx, finish(false), err)
+		}
+		// The watch was closed by the server; start over from a fresh read after a short pause
+		// 服务器关闭了监听，短暂等待后重新读取并继续
+		select {
+		case <-waitCtx.Done():
+			return waitError(ctx, waitCtx, finish(false), waitCtx.Err())
+		case <-time.After(waitRetryInterval):
+		}
+	}
+}
+
+// watchUntil consumes events until observe reports the condition met, the watch
+// closes (false, nil) or ctx is done
+// watchUntil 处理事件，直到条件满足、监听关闭（返回 false, nil）或 ctx 结束
+func watchUntil(ctx context.Context, w watch.Interface, observe func(runtime.Object) bool) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if observe(event.Object) {
+					return true, nil
+				}
+			case watch.Deleted:
+				if observe(nil) {
+					return true, nil
+				}
+			case watch.Error:
+				// An expired resourceVersion shows up here; re-list and watch again
+				// resourceVersion 过期等错误会出现在这里，重新读取后再次监听
+				return false, nil
+			}
+		}
+	}
+}
+
+// waitError maps a failure while waiting: the timeout becomes ErrWaitTimeout and a
+// cancelled parent context is returned as is
+// waitError 转换等待中的错误：超时转为 ErrWaitTimeout，父 context 取消时原样返回
+func waitError(parent, waitCtx context.Context, result *types.WaitResult, err error) (*types.WaitResult, error) {
+	if parent.Err() != nil {
+		return result, parent.Err()
+	}
+	if waitCtx.Err() != nil {
+		return result, ErrWaitTimeout
+	}
+	return result, err
+}
+
+// metav1ObjectOf returns the object metadata of a typed object
+// metav1ObjectOf 返回类型化对象的元数据
+func metav1ObjectOf(obj runtime.Object) (metav1.Object, error) {
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %T has no metadata", obj)
+	}
+	return accessor, nil
+}
+
+// conditionSummary formats a condition as "Type=Status (Reason: message)"
+// conditionSummary 将条件格式化为 "Type=Status (Reason: message)"
+func conditionSummary(condType, status, reason, message string) string {
+	summary := condType + "=" + status
+	switch {
+	case reason != "" && message != "":
+		summary += " (" + reason + ": " + message + ")"
+	case reason != "":
+		summary += " (" + reason + ")"
+	case message != "":
+		summary += " (" + message + ")"
+	}
+	return summary
+}
+
+// evaluatePod checks a pod condition (Ready, ContainersReady, ...) or phase (Running, Succeeded, Failed)
+// evaluatePod 检查 Pod 的条件（Ready、ContainersReady 等）或阶段（Running、Succeeded、Failed）
+func evaluatePod(obj runtime.Object, condition string) (bool, string) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	status := "phase=" + string(pod.Status.Phase)
+	switch condition {
+	case "Running", "Succeeded", "Failed":
+		if pod.Status.Reason != "" {
+			status += " (" + pod.Status.Reason + ")"
+		}
+		return string(pod.Status.Phase) == condition, status
+	}
+	for _, c := range pod.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.Status == corev1.ConditionTrue, status + ", " + conditionSummary(string(c.Type), string(c.Status), c.Reason, c.Message)
+		}
+	}
+	return false, status + ", " + condition + " not reported"
+}
+
+// evaluateDeployment checks a deployment condition, or Complete for a finished rollout
+// as in kubectl rollout status
+// evaluateDeployment 检查 Deployment 的条件，或与 kubectl rollout status 一致的 Complete（发布完成）
+func evaluateDeployment(obj runtime.Object, condition string) (bool, string) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	s := deployment.Status
+	status := fmt.Sprintf("replicas=%d, updated=%d, ready=%d, available=%d", replicas, s.UpdatedReplicas, s.ReadyReplicas, s.AvailableReplicas)
+
+	if condition == "Complete" {
+		return evaluateDeploymentReadiness(deployment).Ready, status
+	}
+	for _, c := range s.Conditions {
+		if string(c.Type) == condition {
+			return c.Status == corev1.ConditionTrue, status + ", " + conditionSummary(string(c.Type), string(c.Status), c.Reason, c.Message)
+		}
+	}
+	return false, status + ", " + condition + " not reported"
+}
+
+// evaluateStatefulSet treats a statefulset as Ready once its rollout finished (see
+// evaluateStatefulSetReadiness)
+// evaluateStatefulSet 在发布完成时认为 StatefulSet 处于 Ready（见 evaluateStatefulSetReadiness）
+func evaluateStatefulSet(obj runtime.Object, condition string) (bool, string) {
+	statefulSet, ok := obj.(*appsv1.StatefulSet)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	readiness := evaluateStatefulSetReadiness(statefulSet)
+	status := fmt.Sprintf("replicas=%d, updated=%d, ready=%d", readiness.Replicas, readiness.UpdatedReplicas, readiness.ReadyReplicas)
+	return readiness.Ready, status
+}
+
+// evaluateDaemonSet treats a daemonset as Ready once its rollout finished (see
+// evaluateDaemonSetReadiness)
+// evaluateDaemonSet 在发布完成时认为 DaemonSet 处于 Ready（见 evaluateDaemonSetReadiness）
+func evaluateDaemonSet(obj runtime.Object, condition string) (bool, string) {
+	daemonSet, ok := obj.(*appsv1.DaemonSet)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	readiness := evaluateDaemonSetReadiness(daemonSet)
+	status := fmt.Sprintf("desired=%d, updated=%d, ready=%d, available=%d", readiness.Replicas, readiness.UpdatedReplicas, readiness.ReadyReplicas, readiness.AvailableReplicas)
+	return readiness.Ready, status
+}
+
+// evaluateNode checks the node's Ready condition
+// evaluateNode 检查节点的 Ready 条件
+func evaluateNode(obj runtime.Object, condition string) (bool, string) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	for _, c := range node.Status.Conditions {
+		if string(c.Type) == condition {
+			return c.Status == corev1.ConditionTrue, conditionSummary(string(c.Type), string(c.Status), c.Reason, c.Message)
+		}
+	}
+	return false, condition + " not reported"
+}
+
+// evaluateNamespace checks the namespace phase
+// evaluateNamespace 检查命名空间的阶段
+func evaluateNamespace(obj runtime.Object, condition string) (bool, string) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return false, fmt.Sprintf("unexpected object %T", obj)
+	}
+	return string(namespace.Status.Phase) == condition, "phase=" + string(namespace.Status.Phase)
+}
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeDeploymentWatchServer 模拟单个 Deployment 的 GET 和 watch 接口：GET 返回 initial（nil 时返回 404），
+// watch 依次发送 events 中的事件后保持连接，直到客户端断开；watchClosed 在 watch 连接结束时关闭
+func fakeDeploymentWatchServer(t *testing.T, initial *appsv1.Deployment, events []map[string]interface{}) (*httptest.Server, <-chan struct{}) {
+	t.Helper()
+
+	watchClosed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/deployments/web":
+			if initial == nil {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonNotFound,
+					Code:     http.StatusNotFound,
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(initial)
+		case r.URL.Path == "/apis/apps/v1/namespaces/default/deployments" && r.URL.Query().Get("watch") == "true":
+			defer close(watchClosed)
+			if r.URL.Query().Get("fieldSelector") != "metadata.name=web" {
+				t.Errorf("unexpected field selector %q", r.URL.Query().Get("fieldSelector"))
+			}
+			encoder := json.NewEncoder(w)
+			for _, event := range events {
+				encoder.Encode(event)
+			}
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, watchClosed
+}
+
+// newWaitDeployment 构造 Available 条件为 available 的 Deployment
+func newWaitDeployment(available corev1.ConditionStatus, availableReplicas int32) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: "10", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      availableReplicas,
+			AvailableReplicas:  availableReplicas,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentAvailable,
+				Status: available,
+				Reason: "MinimumReplicasUnavailable",
+			}},
+		},
+	}
+}
+
+// TestWaitForConditionMet 测试 watch 事件使条件满足时立即返回
+func TestWaitForConditionMet(t *testing.T) {
+	server, watchClosed := fakeDeploymentWatchServer(t, newWaitDeployment(corev1.ConditionFalse, 0), []map[string]interface{}{
+		{"type": "MODIFIED", "object": newWaitDeployment(corev1.ConditionFalse, 1)},
+		{"type": "MODIFIED", "object": newWaitDeployment(corev1.ConditionTrue, 2)},
+	})
+	ro := newServerOperations(t, server)
+
+	result, err := ro.WaitForCondition(context.Background(), ResourceTypeDeployment, "default", "web", "available", 10*time.Second, "test")
+	if err != nil {
+		t.Fatalf("WaitForCondition failed: %v", err)
+	}
+	if !result.Met || result.Condition != "Available" || result.Elapsed == "" || !strings.Contains(result.Status, "available=2") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 返回前停止 watch，服务端连接随之结束
+	select {
+	case <-watchClosed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("watch was not stopped")
+	}
+}
+
+// TestWaitForConditionTimeout 测试超时返回 ErrWaitTimeout 和最后观察到的状态
+func TestWaitForConditionTimeout(t *testing.T) {
+	server, _ := fakeDeploymentWatchServer(t, newWaitDeployment(corev1.ConditionFalse, 0), []map[string]interface{}{
+		{"type": "MODIFIED", "object": newWaitDeployment(corev1.ConditionFalse, 1)},
+	})
+	ro := newServerOperations(t, server)
+
+	result, err := ro.WaitForCondition(context.Background(), ResourceTypeDeployment, "default", "web", "Available", 200*time.Millisecond, "test")
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout, got %v", err)
+	}
+	if result.Met || !strings.Contains(result.Status, "available=1") || !strings.Contains(result.Status, "Available=False (MinimumReplicasUnavailable)") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+// TestWaitForConditionCancelled 测试请求取消时停止等待并返回 context 错误
+func TestWaitForConditionCancelled(t *testing.T) {
+	server, watchClosed := fakeDeploymentWatchServer(t, newWaitDeployment(corev1.ConditionFalse, 0), nil)
+	ro := newServerOperations(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if _, err := ro.WaitForCondition(ctx, ResourceTypeDeployment, "default", "web", "Available", time.Minute, "test"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-watchClosed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("watch was not stopped after cancellation")
+	}
+}
+
+// TestWaitForConditionDeleted 测试对象不存在时 Deleted 条件立即满足，以及 watch 中的删除事件
+func TestWaitForConditionDeleted(t *testing.T) {
+	server, _ := fakeDeploymentWatchServer(t, nil, nil)
+	result, err := newServerOperations(t, server).WaitForCondition(context.Background(), ResourceTypeDeployment, "default", "web", "deleted", time.Second, "test")
+	if err != nil || !result.Met || result.Status != "not found" {
+		t.Errorf("unexpected result: %+v, %v", result, err)
+	}
+
+	server, _ = fakeDeploymentWatchServer(t, newWaitDeployment(corev1.ConditionTrue, 2), []map[string]interface{}{
+		{"type": "DELETED", "object": newWaitDeployment(corev1.ConditionTrue, 2)},
+	})
+	result, err = newServerOperations(t, server).WaitForCondition(context.Background(), ResourceTypeDeployment, "default", "web", "Deleted", 10*time.Second, "test")
+	if err != nil || !result.Met {
+		t.Errorf("unexpected result: %+v, %v", result, err)
+	}
+}
+
+// TestWaitForConditionUnsupported 测试不支持的条件和资源类型
+func TestWaitForConditionUnsupported(t *testing.T) {
+	server, _ := fakeDeploymentWatchServer(t, nil, nil)
+	ro := newServerOperations(t, server)
+
+	_, err := ro.WaitForCondition(context.Background(), ResourceTypeDeployment, "default", "web", "Ready", time.Second, "test")
+	if err == nil || !strings.Contains(err.Error(), "supported: Available, Progressing, Complete, Deleted") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ro.WaitForCondition(context.Background(), ResourceTypeEvents, "default", "web", "Deleted", time.Second, "test"); err == nil {
+		t.Errorf("expected an error for events")
+	}
+}
+
+// TestEvaluatePod 测试 Pod 的条件和阶段判断
+func TestEvaluatePod(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady", Message: "containers with unready status: [web]"},
+		},
+	}}
+
+	if met, status := evaluatePod(pod, "Ready"); met || status != "phase=Running, Ready=False (ContainersNotReady: containers with unready status: [web])" {
+		t.Errorf("Ready: met=%v status=%q", met, status)
+	}
+	if met, _ := evaluatePod(pod, "Running"); !met {
+		t.Errorf("expected Running to be met")
+	}
+	if met, status := evaluatePod(pod, "PodScheduled"); met || status != "phase=Running, PodScheduled not reported" {
+		t.Errorf("PodScheduled: met=%v status=%q", met, status)
+	}
+}
@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WatchNamespaces starts a watch on namespaces in a cluster
+// WatchNamespaces 监听集群中的命名空间变化
+func (ro *ResourceOperations) WatchNamespaces(ctx context.Context, clusterName string) (watch.Interface, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := client.CoreV1().Namespaces().Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch namespaces: %w", err)
+	}
+	return w, nil
+}
+
+// WatchPods starts a watch on pods in a namespace (all namespaces if namespace is empty)
+// WatchPods 监听命名空间中的 Pod 变化（namespace 为空时监听所有命名空间）
+func (ro *ResourceOperations) WatchPods(ctx context.Context, namespace, clusterName string) (watch.Interface, error) {
+	var client kubernetes.Interface
+	var err error
+
+	if clusterName != "" {
+		client, err = ro.clusterManager.GetClientForCluster(clusterName)
+	} else {
+		client, err = ro.clusterManager.GetCurrentClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := client.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch pods: %w", err)
+	}
+	return w, nil
+}
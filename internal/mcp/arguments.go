@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxSuggestionDistance is the largest edit distance at which an unknown name is
+// considered a typo of a valid one
+// maxSuggestionDistance 未知名称被视为某个有效名称的拼写错误时允许的最大编辑距离
+const maxSuggestionDistance = 2
+
+// toolArguments describes the top-level arguments a tool accepts
+// toolArguments 描述工具接受的顶层参数
+type toolArguments struct {
+	names []string
+	// closed is set when the input schema rejects additional properties
+	// closed 表示输入 schema 不接受额外属性
+	closed bool
+}
+
+// argumentsMiddleware checks the top-level argument names of tools/call against the
+// tool's input schema before the SDK validates them. Unknown names fail the call with
+// the valid names and a did-you-mean suggestion; with lenient arguments they are
+// dropped and logged instead.
+// argumentsMiddleware 在 SDK 校验之前按工具的输入 schema 检查 tools/call 的顶层参数名。未知参数会使调用失败，
+// 并列出有效参数和拼写建议；启用宽松参数时改为丢弃未知参数并记录日志
+func (s *Server) argumentsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != "tools/call" || !ok || len(params.Arguments) == 0 {
+			return next(ctx, method, req)
+		}
+		var args map[string]json.RawMessage
+		if json.Unmarshal(params.Arguments, &args) != nil {
+			return next(ctx, method, req)
+		}
+		accepted, err := s.toolArguments(ctx, req, params.Name)
+		if err != nil || !accepted.closed {
+			return next(ctx, method, req)
+		}
+
+		var unknown []string
+		for name := range args {
+			if !containsString(accepted.names, name) {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) == 0 {
+			return next(ctx, method, req)
+		}
+		sort.Strings(unknown)
+
+		if !s.lenientArgs {
+			return toolError(unknownArgumentsMessage(params.Name, unknown, accepted.names)), nil
+		}
+		s.logger.Warn("Dropping unknown tool arguments", "tool", params.Name, "arguments", unknown)
+		for _, name := range unknown {
+			delete(args, name)
+		}
+		if params.Arguments, err = json.Marshal(args); err != nil {
+			return nil, fmt.Errorf("failed to rewrite arguments: %w", err)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// unknownArgumentsMessage explains which arguments a tool does not accept, e.g.
+// `list_pods does not accept argument "name_space" (did you mean "namespace"?); valid arguments: ...`
+// unknownArgumentsMessage 说明工具不接受哪些参数，并附带拼写建议和有效参数
+func unknownArgumentsMessage(tool string, unknown, valid []string) string {
+	parts := make([]string, 0, len(unknown))
+	for _, name := range unknown {
+		part := fmt.Sprintf("%q", name)
+		if suggestion := suggestName(name, valid); suggestion != "" {
+			part += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		parts = append(parts, part)
+	}
+	noun := "argument"
+	if len(unknown) > 1 {
+		noun = "arguments"
+	}
+	validNames := "none"
+	if len(valid) > 0 {
+		validNames = strings.Join(valid, ", ")
+	}
+	return fmt.Sprintf("%s does not accept %s %s; valid arguments: %s", tool, noun, strings.Join(parts, ", "), validNames)
+}
+
+// toolArguments returns the top-level argument names of a registered tool
+// toolArguments 返回已注册工具的顶层参数名
+func (s *Server) toolArguments(ctx context.Context, req mcp.Request, name string) (toolArguments, error) {
+	tools, err := s.listTools(ctx, req.GetSession())
+	if err != nil {
+		return toolArguments{}, err
+	}
+	for _, tool := range tools {
+		if tool.Name != name {
+			continue
+		}
+		var schema struct {
+			Properties           map[string]json.RawMessage `json:"properties"`
+			AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+		}
+		data, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return toolArguments{}, err
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return toolArguments{}, err
+		}
+		accepted := toolArguments{closed: string(schema.AdditionalProperties) == "false", names: make([]string, 0, len(schema.Properties))}
+		for property := range schema.Properties {
+			accepted.names = append(accepted.names, property)
+		}
+		sort.Strings(accepted.names)
+		return accepted, nil
+	}
+	return toolArguments{}, fmt.Errorf("unknown tool %q", name)
+}
+
+// listTools returns every registered tool, following tools/list pagination
+// listTools 返回所有已注册的工具，按 tools/list 的分页依次获取
+func (s *Server) listTools(ctx context.Context, session mcp.Session) ([]*mcp.Tool, error) {
+	serverSession, _ := session.(*mcp.ServerSession)
+	var tools []*mcp.Tool
+	params := &mcp.ListToolsParams{}
+	for {
+		result, err := s.methodHandler(ctx, "tools/list", &mcp.ListToolsRequest{Session: serverSession, Params: params})
+		if err != nil {
+			return nil, err
+		}
+		list, ok := result.(*mcp.ListToolsResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result %T", result)
+		}
+		tools = append(tools, list.Tools...)
+		if list.NextCursor == "" {
+			return tools, nil
+		}
+		params = &mcp.ListToolsParams{Cursor: list.NextCursor}
+	}
+}
+
+// suggestName returns the candidate closest to name, or "" if none is close. Names
+// that only differ in case, '_' and '-' (labelSelector, label-selector) match first;
+// otherwise the candidate within maxSuggestionDistance edits wins, ties going to the
+// first in order.
+// suggestName 返回与 name 最接近的候选名称，没有足够接近的候选时返回 ""。只有大小写、'_' 和 '-' 不同的名称
+// (labelSelector、label-selector) 优先匹配；否则选择编辑距离不超过 maxSuggestionDistance 的候选，距离相同时取排在前面的
+func suggestName(name string, candidates []string) string {
+	normalized := normalizeName(name)
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range candidates {
+		if normalizeName(candidate) == normalized {
+			return candidate
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// normalizeName lower-cases a name and removes '_' and '-'
+// normalizeName 将名称转为小写并去掉 '_' 和 '-'
+func normalizeName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// editDistance returns the Levenshtein distance between a and b
+// editDistance 返回 a 和 b 之间的 Levenshtein 编辑距离
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
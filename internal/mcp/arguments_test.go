@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestSuggestName 测试拼写建议：大小写和分隔符不同的名称优先，其次为编辑距离不超过 2 的名称
+func TestSuggestName(t *testing.T) {
+	candidates := []string{"all_namespaces", "cluster_name", "label_selector", "namespace"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"name_space", "namespace"},
+		{"namspace", "namespace"},
+		{"Namespace", "namespace"},
+		{"labelSelector", "label_selector"},
+		{"label-selector", "label_selector"},
+		{"cluster", ""},
+		{"password", ""},
+	}
+	for _, tt := range tests {
+		if got := suggestName(tt.name, candidates); got != tt.want {
+			t.Errorf("suggestName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// callWithArguments 调用工具并返回结果文本，协议错误时失败
+func callWithArguments(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	text := ""
+	if len(result.Content) > 0 {
+		if content, ok := result.Content[0].(*mcp.TextContent); ok {
+			text = content.Text
+		}
+	}
+	return result, text
+}
+
+// TestUnknownArguments 测试未知参数返回工具错误，列出有效参数并给出拼写建议
+func TestUnknownArguments(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, text := callWithArguments(t, session, "list_pods", map[string]any{"name_space": "shop"})
+	if !result.IsError {
+		t.Fatalf("expected an unknown argument to fail the call, got %s", text)
+	}
+	for _, want := range []string{`list_pods does not accept argument "name_space" (did you mean "namespace"?)`, "valid arguments: all_namespaces, namespace"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+
+	result, text = callWithArguments(t, session, "search_resources", map[string]any{"namespace": "shop", "password": "hunter2", "labelSelector": "app=web"})
+	if !result.IsError || !strings.Contains(text, `search_resources does not accept arguments "labelSelector" (did you mean "label_selector"?), "password"; valid`) {
+		t.Errorf("expected both unknown arguments to be listed, got %s", text)
+	}
+
+	// 合法参数不受影响
+	if result, text = callWithArguments(t, session, "list_pods", map[string]any{"namespace": "shop"}); result.IsError {
+		t.Errorf("expected valid arguments to pass, got %s", text)
+	}
+}
+
+// TestLenientArguments 测试宽松模式下丢弃未知参数并继续执行调用
+func TestLenientArguments(t *testing.T) {
+	s := NewServer("test-token", &Options{LenientArgs: true})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, text := callWithArguments(t, session, "list_pods", map[string]any{"namespace": "shop", "name_space": "kube-system"})
+	if result.IsError {
+		t.Fatalf("expected the unknown argument to be dropped, got %s", text)
+	}
+	if !strings.Contains(text, "web-7d4b9c8f6-abcde") {
+		t.Errorf("expected the pods of namespace shop, got %s", text)
+	}
+}
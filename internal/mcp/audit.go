@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+// Audit outcomes
+// 审计结果
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeError   = "error"
+	auditOutcomePanic   = "panic"
+)
+
+// auditRedacted replaces the value of secret-looking arguments
+// auditRedacted 替换疑似敏感参数的值
+const auditRedacted = "[REDACTED]"
+
+// auditSensitiveKeys are substrings of argument names whose values are redacted
+// auditSensitiveKeys 参数名包含这些子串时其值会被脱敏
+var auditSensitiveKeys = []string{"token", "password", "passwd", "secret", "credential", "apikey", "api_key", "authorization"}
+
+// auditBlobKeys are argument names whose string values are file contents, e.g. the content
+// of cp_to_pod; they are replaced with their size and a hash prefix
+// auditBlobKeys 参数名为这些值时其字符串值为文件内容 (例如 cp_to_pod 的 content)，替换为大小和哈希前缀
+var auditBlobKeys = map[string]bool{"content": true}
+
+// auditManifestKeys are argument names whose string values are object manifests, e.g. the
+// manifest of diff_resource; the values of Secret manifests are redacted
+// auditManifestKeys 参数名为这些值时其字符串值为对象清单 (例如 diff_resource 的 manifest)，Secret 清单中的值会被脱敏
+var auditManifestKeys = map[string]bool{"manifest": true}
+
+// lastAppliedAnnotation holds a copy of the object kubectl apply was given, secret data included
+// lastAppliedAnnotation 保存 kubectl apply 收到的对象副本，其中包括 Secret 数据
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// auditedMethods are the MCP methods recorded in the audit log
+// auditedMethods 记录到审计日志的 MCP 方法
+var auditedMethods = map[string]bool{
+	"tools/call":     true,
+	"resources/read": true,
+	"prompts/get":    true,
+}
+
+// AuditEntry is one line of the audit log
+// AuditEntry 审计日志中的一行记录
+type AuditEntry struct {
+	Time       time.Time              `json:"time"`
+	Caller     string                 `json:"caller"`
+	Method     string                 `json:"method"`
+	Tool       string                 `json:"tool,omitempty"`
+	URI        string                 `json:"uri,omitempty"`
+	Prompt     string                 `json:"prompt,omitempty"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Cluster    string                 `json:"cluster,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+	// PrevHash is the hex SHA-256 of the previous line without its newline, empty for the
+	// first entry written since the server started
+	// PrevHash 为上一行 (不含换行符) 的十六进制 SHA-256，服务器启动后写入的第一条记录为空
+	PrevHash string `json:"prev_hash,omitempty"`
+}
+
+// auditLogger writes audit entries as JSON lines. Each line carries the hash of the line
+// written before it, so a modified or removed line breaks the chain of the lines after it.
+// auditLogger 以 JSON 行的形式写入审计记录。每行包含前一行的哈希，修改或删除某一行会使其后各行的哈希链断开。
+type auditLogger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	prevHash string
+}
+
+// record writes one entry; concurrent calls never interleave lines
+// record 写入一条记录，并发调用不会交错
+func (a *auditLogger) record(entry AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry.PrevHash = a.prevHash
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		return
+	}
+	sum := sha256.Sum256(line)
+	a.prevHash = hex.EncodeToString(sum[:])
+}
+
+// auditMiddleware records every audited request, including requests whose handler panics
+// auditMiddleware 记录所有需要审计的请求，包括处理器发生 panic 的请求
+func (s *Server) auditMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+		if !auditedMethods[method] {
+			return next(ctx, method, req)
+		}
+
+		entry := newAuditEntry(method, req)
+		start := time.Now()
+		defer func() {
+			entry.DurationMs = time.Since(start).Milliseconds()
+			if r := recover(); r != nil {
+				entry.Outcome = auditOutcomePanic
+				entry.Error = fmt.Sprint(r)
+				s.audit.record(entry)
+				panic(r)
+			}
+			entry.Outcome, entry.Error = auditOutcome(result, err)
+			s.audit.record(entry)
+		}()
+
+		return next(ctx, method, req)
+	}
+}
+
+// newAuditEntry extracts the target and redacted arguments of a request
+// newAuditEntry 提取请求的目标和脱敏后的参数
+func newAuditEntry(method string, req mcp.Request) AuditEntry {
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		Caller: callerIdentity(req),
+		Method: method,
+	}
+
+	switch params := req.GetParams().(type) {
+	case *mcp.CallToolParamsRaw:
+		entry.Tool = params.Name
+		var args map[string]interface{}
+		if len(params.Arguments) > 0 && json.Unmarshal(params.Arguments, &args) == nil {
+			entry.Arguments = redactArguments(args)
+			if cluster, ok := args["cluster_name"].(string); ok {
+				entry.Cluster = cluster
+			}
+		}
+	case *mcp.ReadResourceParams:
+		entry.URI = params.URI
+		if parsed, err := parseResourceURI(params.URI); err == nil {
+			entry.Cluster = parsed.Cluster
+		}
+	case *mcp.GetPromptParams:
+		entry.Prompt = params.Name
+		if len(params.Arguments) > 0 {
+			args := make(map[string]interface{}, len(params.Arguments))
+			for k, v := range params.Arguments {
+				args[k] = v
+			}
+			entry.Arguments = redactArguments(args)
+			entry.Cluster = params.Arguments["cluster_name"]
+		}
+	}
+	return entry
+}
+
+// auditOutcome classifies the result of a request; tool errors are reported in-band via IsError
+// auditOutcome 判断请求结果，工具错误通过 IsError 在结果中返回
+func auditOutcome(result mcp.Result, err error) (string, string) {
+	if err != nil {
+		return auditOutcomeError, err.Error()
+	}
+	if res, ok := result.(*mcp.CallToolResult); ok && res.IsError {
+		var msgs []string
+		for _, content := range res.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				msgs = append(msgs, text.Text)
+			}
+		}
+		return auditOutcomeError, strings.Join(msgs, "; ")
+	}
+	return auditOutcomeSuccess, ""
+}
+
+// callerIdentity identifies the caller by its client certificate CN, its OIDC user or
+// else a fingerprint of its bearer token, so the audit log never contains the token itself
+// callerIdentity 使用客户端证书的 CN、OIDC 用户或 bearer token 的指纹标识调用者，审计日志中不会出现 token 本身
+func callerIdentity(req mcp.Request) string {
+	extra := req.GetExtra()
+	if extra == nil || extra.Header == nil {
+		return "local"
+	}
+	if identity, ok := headerCertIdentity(extra.Header); ok {
+		return "cert:" + identity.User
+	}
+	if identity, ok := headerOIDCIdentity(extra.Header); ok {
+		return "oidc:" + identity.User
+	}
+	const prefix = "Bearer "
+	authHeader := extra.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, prefix) {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(authHeader, prefix)))
+	return "token:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// redactArguments returns a copy of args with secret-looking values replaced, recursively:
+// values of secret-looking names, file contents and the data of Secret manifests
+// redactArguments 返回参数的副本，递归替换疑似敏感的值：名称疑似敏感的参数值、文件内容以及 Secret 清单中的数据
+func redactArguments(args map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		str, isString := v.(string)
+		switch {
+		case isSensitiveKey(k):
+			redacted[k] = auditRedacted
+		case isString && auditBlobKeys[strings.ToLower(k)]:
+			redacted[k] = redactBlob(str)
+		case isString && auditManifestKeys[strings.ToLower(k)]:
+			redacted[k] = redactManifest(str)
+		default:
+			redacted[k] = redactValue(v)
+		}
+	}
+	return redacted
+}
+
+// redactBlob replaces a file content with its size and a hash prefix, so calls with the
+// same content can still be matched
+// redactBlob 将文件内容替换为其大小和哈希前缀，仍可据此判断调用是否使用了相同的内容
+func redactBlob(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%s %d bytes, sha256 %s", auditRedacted, len(value), hex.EncodeToString(sum[:])[:12])
+}
+
+// redactManifest redacts the data, stringData and last applied configuration of a Secret
+// manifest. Other kinds are kept as they are; a manifest that cannot be parsed may still
+// hold a secret, so it is replaced like a file content.
+// redactManifest 脱敏 Secret 清单中的 data、stringData 和 last-applied-configuration 注解，其他类型保持不变；
+// 无法解析的清单仍可能包含敏感信息，因此与文件内容一样被替换
+func redactManifest(manifest string) string {
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil || obj == nil {
+		return redactBlob(manifest)
+	}
+	if kind, _ := obj["kind"].(string); kind != "Secret" {
+		return manifest
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if values, ok := obj[field].(map[string]interface{}); ok {
+			for key := range values {
+				values[key] = auditRedacted
+			}
+		}
+	}
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			if _, ok := annotations[lastAppliedAnnotation]; ok {
+				annotations[lastAppliedAnnotation] = auditRedacted
+			}
+		}
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return redactBlob(manifest)
+	}
+	return string(data)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return redactArguments(val)
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = redactValue(item)
+		}
+		return items
+	}
+	return v
+}
+
+// isSensitiveKey reports whether an argument name looks like it holds a secret
+// isSensitiveKey 判断参数名是否像是包含敏感信息
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range auditSensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
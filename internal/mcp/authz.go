@@ -0,0 +1,338 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultAuthzWebhookTimeout bounds one request to the authorization webhook
+	// DefaultAuthzWebhookTimeout 限制单次授权 webhook 请求的时间
+	DefaultAuthzWebhookTimeout = 2 * time.Second
+	// DefaultAuthzWebhookCacheTTL is how long a webhook decision is reused
+	// DefaultAuthzWebhookCacheTTL webhook 决定被复用的时间
+	DefaultAuthzWebhookCacheTTL = 10 * time.Second
+	// authzMaxResponseBytes limits the webhook response read
+	// authzMaxResponseBytes 限制读取的 webhook 响应大小
+	authzMaxResponseBytes = 64 << 10
+)
+
+// AuthzWebhookConfig configures authorizing every tool call and resource read with an HTTP webhook
+// AuthzWebhookConfig 配置通过 HTTP webhook 对每次工具调用和资源读取进行授权
+type AuthzWebhookConfig struct {
+	// URL receives a POST of an AuthzRequest per tool call and answers with an AuthzResponse
+	// URL 每次工具调用接收一个 AuthzRequest 的 POST 请求，并返回 AuthzResponse
+	URL string
+	// Timeout bounds one webhook request (0 uses DefaultAuthzWebhookTimeout)
+	// Timeout 限制单次 webhook 请求的时间（0 表示使用 DefaultAuthzWebhookTimeout）
+	Timeout time.Duration
+	// CacheTTL is how long a decision is reused for the same user, tool, resource URI,
+	// clusters and namespace (0 uses DefaultAuthzWebhookCacheTTL, a negative value disables the cache)
+	// CacheTTL 同一用户、工具、资源 URI、集群和命名空间复用决定的时间（0 表示使用 DefaultAuthzWebhookCacheTTL，负数表示不缓存）
+	CacheTTL time.Duration
+	// FailOpen allows calls when the webhook cannot be reached or answers with an error;
+	// by default they are denied
+	// FailOpen 在 webhook 无法访问或返回错误时允许调用，默认拒绝
+	FailOpen bool
+}
+
+// AuthzRequest is the document POSTed to the authorization webhook
+// AuthzRequest 是发送给授权 webhook 的文档
+type AuthzRequest struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+	Tool   string   `json:"tool"`
+	// ArgumentsSummary holds the arguments with secrets redacted and long strings shortened
+	// ArgumentsSummary 保存脱敏并截短长字符串后的参数
+	ArgumentsSummary map[string]interface{} `json:"arguments_summary,omitempty"`
+	// URI is the resource read by a resources/read request, whose Tool is "resources/read"
+	// URI 是 resources/read 请求读取的资源，此时 Tool 为 "resources/read"
+	URI string `json:"uri,omitempty"`
+	// Cluster is the cluster the call works in, or "*" for a call across all clusters
+	// Cluster 是调用所在的集群，跨所有集群的调用为 "*"
+	Cluster string `json:"cluster"`
+	// Clusters lists every registered cluster when Cluster is "*"
+	// Clusters 在 Cluster 为 "*" 时列出所有已注册的集群
+	Clusters []string `json:"clusters,omitempty"`
+	// Namespace is the namespace the call works in, empty for all namespaces
+	// Namespace 是调用所在的命名空间，所有命名空间时为空
+	Namespace string `json:"namespace"`
+}
+
+// authzReadResource is the tool name of the webhook requests for resources/read
+// authzReadResource 是 resources/read 对应的 webhook 请求中的工具名
+const authzReadResource = "resources/read"
+
+// target names what a request asks for in denial messages: the resource URI or the tool
+// target 返回拒绝信息中请求的对象：资源 URI 或工具名
+func (r AuthzRequest) target() string {
+	if r.URI != "" {
+		return r.URI
+	}
+	return r.Tool
+}
+
+// AuthzResponse is the decision of the authorization webhook
+// AuthzResponse 是授权 webhook 的决定
+type AuthzResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// authzCacheKey identifies the decisions that are reused
+// authzCacheKey 标识可以复用的决定
+type authzCacheKey struct {
+	user, tool, uri, cluster, clusters, namespace string
+}
+
+// authzCacheEntry is a cached decision and when it expires
+// authzCacheEntry 是缓存的决定及其过期时间
+type authzCacheEntry struct {
+	response AuthzResponse
+	expires  time.Time
+}
+
+// authzWebhook asks the webhook whether a tool call is allowed, caching decisions for
+// CacheTTL. Failures to get a decision are never cached, so the webhook is asked again
+// on the next call.
+// authzWebhook 向 webhook 询问工具调用是否被允许，并将决定缓存 CacheTTL。未能获得决定的情况不会被缓存，
+// 下一次调用会再次询问 webhook
+type authzWebhook struct {
+	config AuthzWebhookConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[authzCacheKey]authzCacheEntry
+}
+
+// newAuthzWebhook creates a webhook client with the defaults applied
+// newAuthzWebhook 创建应用了默认值的 webhook 客户端
+func newAuthzWebhook(config AuthzWebhookConfig) *authzWebhook {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultAuthzWebhookTimeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultAuthzWebhookCacheTTL
+	}
+	return &authzWebhook{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		cache:  make(map[authzCacheKey]authzCacheEntry),
+	}
+}
+
+// authorize returns the webhook's decision for request, from the cache when possible
+// authorize 返回 webhook 对 request 的决定，尽可能使用缓存
+func (w *authzWebhook) authorize(ctx context.Context, request AuthzRequest) (AuthzResponse, error) {
+	key := authzCacheKey{
+		user:      request.User,
+		tool:      request.Tool,
+		uri:       request.URI,
+		cluster:   request.Cluster,
+		clusters:  strings.Join(request.Clusters, ","),
+		namespace: request.Namespace,
+	}
+	if w.config.CacheTTL > 0 {
+		w.mu.Lock()
+		entry, ok := w.cache[key]
+		w.mu.Unlock()
+		if ok && w.now().Before(entry.expires) {
+			return entry.response, nil
+		}
+	}
+
+	response, err := w.post(ctx, request)
+	if err != nil {
+		return AuthzResponse{}, err
+	}
+	if w.config.CacheTTL > 0 {
+		w.mu.Lock()
+		now := w.now()
+		for cached, entry := range w.cache {
+			if !now.Before(entry.expires) {
+				delete(w.cache, cached)
+			}
+		}
+		w.cache[key] = authzCacheEntry{response: response, expires: now.Add(w.config.CacheTTL)}
+		w.mu.Unlock()
+	}
+	return response, nil
+}
+
+// post sends one request to the webhook; non-2xx statuses and malformed bodies are errors
+// post 向 webhook 发送一次请求，非 2xx 状态码和无法解析的响应均视为错误
+func (w *authzWebhook) post(ctx context.Context, request AuthzRequest) (AuthzResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return AuthzResponse{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.config.Timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return AuthzResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return AuthzResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return AuthzResponse{}, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	var response AuthzResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, authzMaxResponseBytes)).Decode(&response); err != nil {
+		return AuthzResponse{}, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	return response, nil
+}
+
+// authzMiddleware asks the authorization webhook about every tools/call and resources/read
+// before it is dispatched. A call working in several clusters is authorized once per
+// cluster and runs only when all are allowed. A denial fails the call with the webhook's
+// reason; when no decision can be obtained the call is allowed or denied according to FailOpen.
+// authzMiddleware 在分发之前向授权 webhook 询问每个 tools/call 和 resources/read。涉及多个集群的调用对每个集群
+// 分别授权，全部允许时才执行。被拒绝的调用以 webhook 给出的原因失败；无法获得决定时按 FailOpen 允许或拒绝调用
+func (s *Server) authzMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		var requests []AuthzRequest
+		switch params := req.GetParams().(type) {
+		case *mcp.CallToolParamsRaw:
+			if method == "tools/call" {
+				requests = s.authzToolRequests(ctx, req, params)
+			}
+		case *mcp.ReadResourceParams:
+			if method == "resources/read" {
+				requests = []AuthzRequest{s.authzResourceRequest(ctx, req, params)}
+			}
+		}
+
+		for _, request := range requests {
+			denied := s.authzDenial(ctx, request)
+			switch {
+			case denied == "":
+				continue
+			case method == "tools/call":
+				return toolError(denied), nil
+			default:
+				return nil, fmt.Errorf("%s", denied)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// authzDenial asks the webhook about one request and returns the denial message, empty
+// when the request is allowed
+// authzDenial 向 webhook 询问一个请求，返回拒绝信息，允许时返回空字符串
+func (s *Server) authzDenial(ctx context.Context, request AuthzRequest) string {
+	response, err := s.authz.authorize(ctx, request)
+	switch {
+	case err != nil && s.authz.config.FailOpen:
+		s.logger.Warn("Authorization webhook unavailable, allowing call", "target", request.target(), "cluster", request.Cluster, "user", request.User, "error", err)
+	case err != nil:
+		s.logger.Warn("Authorization webhook unavailable, denying call", "target", request.target(), "cluster", request.Cluster, "user", request.User, "error", err)
+		return fmt.Sprintf("%s denied: authorization webhook unavailable", request.target())
+	case !response.Allowed:
+		reason := response.Reason
+		if reason == "" {
+			reason = "denied by the authorization webhook"
+		}
+		return fmt.Sprintf("%s denied: %s", request.target(), reason)
+	}
+	return ""
+}
+
+// authzCaller fills in the caller's identity: its impersonated user, or the audit caller
+// name without one
+// authzCaller 填写调用者身份：模拟的用户，没有时为审计中的调用者名称
+func (s *Server) authzCaller(request *AuthzRequest, req mcp.Request) {
+	if identity, ok := s.requestIdentity(req); ok {
+		request.User, request.Groups = identity.User, identity.Groups
+	} else {
+		request.User = callerIdentity(req)
+	}
+}
+
+// authzToolRequests describes a tool call for the webhook: the caller, the redacted
+// arguments and the cluster and namespace the call works in, defaults included. A call
+// across all clusters is described with cluster "*" and the registered clusters;
+// compare_resource and compare_namespace get one request for each of their two clusters.
+// authzToolRequests 为 webhook 描述一次工具调用：调用者、脱敏后的参数，以及调用实际使用的集群和命名空间 (包括默认值)。
+// 跨所有集群的调用使用集群 "*" 并列出已注册的集群；compare_resource 和 compare_namespace 为其两个集群各生成一个请求
+func (s *Server) authzToolRequests(ctx context.Context, req mcp.Request, params *mcp.CallToolParamsRaw) []AuthzRequest {
+	request := AuthzRequest{Tool: params.Name}
+	s.authzCaller(&request, req)
+
+	var args map[string]interface{}
+	if len(params.Arguments) > 0 && json.Unmarshal(params.Arguments, &args) == nil {
+		request.ArgumentsSummary = summarizeArguments(redactArguments(args))
+	}
+	namespace, _ := args["namespace"].(string)
+	allNamespaces, _ := args["all_namespaces"].(bool)
+
+	clusterA, _ := args["cluster_a"].(string)
+	clusterB, _ := args["cluster_b"].(string)
+	if clusterA != "" || clusterB != "" {
+		request.Cluster = s.resolveClusterName(ctx, clusterA)
+		request.Namespace, _ = s.resolveNamespace(ctx, namespace, allNamespaces, request.Cluster)
+		requests := []AuthzRequest{request}
+		if other := s.resolveClusterName(ctx, clusterB); other != request.Cluster {
+			request.Cluster = other
+			requests = append(requests, request)
+		}
+		return requests
+	}
+
+	clusterName, _ := args["cluster_name"].(string)
+	allClusters, _ := args["all_clusters"].(bool)
+	if isAllClusters(allClusters, clusterName) {
+		request.Cluster, request.Clusters = allClustersWildcard, s.authzClusters()
+		request.Namespace, _ = s.resolveNamespace(ctx, namespace, allNamespaces, "")
+		return []AuthzRequest{request}
+	}
+	request.Cluster = s.resolveClusterName(ctx, clusterName)
+	request.Namespace, _ = s.resolveNamespace(ctx, namespace, allNamespaces, request.Cluster)
+	return []AuthzRequest{request}
+}
+
+// authzResourceRequest describes a resources/read request for the webhook, with the
+// cluster and namespace of the URI. k8s://clusters lists every cluster, so it is described
+// like a call across all clusters; k8s://server/history has no cluster.
+// authzResourceRequest 为 webhook 描述一次 resources/read 请求，包括 URI 中的集群和命名空间。
+// k8s://clusters 列出所有集群，因此与跨所有集群的调用相同；k8s://server/history 没有集群
+func (s *Server) authzResourceRequest(ctx context.Context, req mcp.Request, params *mcp.ReadResourceParams) AuthzRequest {
+	request := AuthzRequest{Tool: authzReadResource, URI: params.URI}
+	s.authzCaller(&request, req)
+	parsed, err := parseResourceURI(params.URI)
+	switch {
+	case err != nil, parsed.Kind == resourceKindHistory:
+	case parsed.Kind == resourceKindClusters:
+		request.Cluster, request.Clusters = allClustersWildcard, s.authzClusters()
+	default:
+		request.Cluster, request.Namespace = parsed.Cluster, parsed.Namespace
+	}
+	return request
+}
+
+// authzClusters returns the registered clusters in name order
+// authzClusters 按名称顺序返回已注册的集群
+func (s *Server) authzClusters() []string {
+	clusters := s.clusterManager.GetClusters()
+	sort.Strings(clusters)
+	return clusters
+}
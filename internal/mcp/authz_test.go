@@ -0,0 +1,245 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// authzRecorder 是测试用的授权 webhook，记录收到的请求并按 decide 返回决定
+type authzRecorder struct {
+	mu       sync.Mutex
+	requests []AuthzRequest
+	decide   func(AuthzRequest) (int, AuthzResponse)
+}
+
+func (r *authzRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var request AuthzRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.requests = append(r.requests, request)
+	decide := r.decide
+	r.mu.Unlock()
+
+	status, response := decide(request)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// count 返回 webhook 收到的请求数
+func (r *authzRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// newAuthzServer 返回使用 webhook 授权、加载了模拟集群的服务器及其客户端会话
+func newAuthzServer(t *testing.T, config AuthzWebhookConfig) (*Server, *mcp.ClientSession) {
+	t.Helper()
+	s := NewServer("test-token", &Options{AuthzWebhook: &config})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	return s, connectTestClient(t, s, nil)
+}
+
+// callAuthorized 调用工具并返回结果和第一个文本内容，协议错误时失败
+func callAuthorized(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	text := ""
+	if len(result.Content) > 0 {
+		if content, ok := result.Content[0].(*mcp.TextContent); ok {
+			text = content.Text
+		}
+	}
+	return result, text
+}
+
+// TestAuthzWebhookAllowDeny 测试 webhook 收到的文档以及允许和拒绝 (带原因) 的调用
+func TestAuthzWebhookAllowDeny(t *testing.T) {
+	webhook := &authzRecorder{decide: func(request AuthzRequest) (int, AuthzResponse) {
+		if request.Tool == "list_nodes" {
+			return http.StatusOK, AuthzResponse{Allowed: false, Reason: "nodes are reserved for the platform team"}
+		}
+		return http.StatusOK, AuthzResponse{Allowed: true}
+	}}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+	_, session := newAuthzServer(t, AuthzWebhookConfig{URL: server.URL})
+
+	if result, text := callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"}); result.IsError || !strings.Contains(text, "web-7d4b9c8f6-abcde") {
+		t.Fatalf("expected an allowed call to run, got %s", text)
+	}
+	webhook.mu.Lock()
+	request := webhook.requests[0]
+	webhook.mu.Unlock()
+	if request.User != "local" || request.Tool != "list_pods" || request.Cluster != "mock" || request.Namespace != "shop" || request.ArgumentsSummary["namespace"] != "shop" {
+		t.Errorf("unexpected webhook request %+v", request)
+	}
+
+	result, text := callAuthorized(t, session, "list_nodes", nil)
+	if !result.IsError || text != "list_nodes denied: nodes are reserved for the platform team" {
+		t.Errorf("expected the denial reason, got %v %q", result.IsError, text)
+	}
+}
+
+// TestAuthzWebhookClusters 测试跨所有集群的调用、compare 工具的两个集群以及资源读取都按实际涉及的集群授权，
+// 且默认集群的决定不会被复用到这些调用
+func TestAuthzWebhookClusters(t *testing.T) {
+	webhook := &authzRecorder{decide: func(request AuthzRequest) (int, AuthzResponse) {
+		for _, cluster := range append([]string{request.Cluster}, request.Clusters...) {
+			if cluster == "prod" {
+				return http.StatusOK, AuthzResponse{Allowed: false, Reason: "prod is read by the platform team only"}
+			}
+		}
+		return http.StatusOK, AuthzResponse{Allowed: true}
+	}}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+	s, session := newAuthzServer(t, AuthzWebhookConfig{URL: server.URL, CacheTTL: time.Minute})
+	s.clusterManager.AddClientset("prod", fake.NewSimpleClientset())
+	s.RegisterResources()
+
+	// 默认集群被允许，其决定被缓存
+	if result, text := callAuthorized(t, session, "list_namespaces", nil); result.IsError {
+		t.Fatalf("expected list_namespaces on the default cluster to run, got %s", text)
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+	}{
+		{"wildcard", "list_namespaces", map[string]any{"cluster_name": "*"}},
+		{"all clusters", "list_namespaces", map[string]any{"all_clusters": true}},
+		{"compare", "compare_namespace", map[string]any{"namespace": "shop", "cluster_a": "mock", "cluster_b": "prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, text := callAuthorized(t, session, tt.tool, tt.args)
+			if !result.IsError || text != tt.tool+" denied: prod is read by the platform team only" {
+				t.Errorf("expected %s to be denied, got %v %q", tt.tool, result.IsError, text)
+			}
+		})
+	}
+
+	webhook.mu.Lock()
+	requests := append([]AuthzRequest(nil), webhook.requests...)
+	webhook.mu.Unlock()
+	if len(requests) != 4 {
+		t.Fatalf("expected the default cluster, all clusters and both compared clusters to be asked, got %+v", requests)
+	}
+	if all := requests[1]; all.Cluster != "*" || !reflect.DeepEqual(all.Clusters, []string{"mock", "prod"}) {
+		t.Errorf("expected the call across all clusters to list them, got %+v", all)
+	}
+	if a, b := requests[2], requests[3]; a.Tool != "compare_namespace" || a.Cluster != "mock" || b.Cluster != "prod" || b.Namespace != "shop" {
+		t.Errorf("expected one request per compared cluster, got %+v and %+v", a, b)
+	}
+
+	// resources/read 同样需要授权
+	ctx := context.Background()
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "k8s://cluster/mock/namespace/shop/pods"}); err != nil {
+		t.Fatalf("expected reading a resource of the default cluster to be allowed, got %v", err)
+	}
+	webhook.mu.Lock()
+	read := webhook.requests[len(webhook.requests)-1]
+	webhook.mu.Unlock()
+	if read.Tool != "resources/read" || read.URI != "k8s://cluster/mock/namespace/shop/pods" || read.Cluster != "mock" || read.Namespace != "shop" {
+		t.Errorf("unexpected webhook request for resources/read %+v", read)
+	}
+	for _, uri := range []string{"k8s://cluster/prod/namespaces", "k8s://clusters"} {
+		if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil || !strings.Contains(err.Error(), uri+" denied") {
+			t.Errorf("expected reading %s to be denied, got %v", uri, err)
+		}
+	}
+}
+
+// TestAuthzWebhookCache 测试相同用户、工具、集群和命名空间的决定被缓存，过期后重新询问
+func TestAuthzWebhookCache(t *testing.T) {
+	webhook := &authzRecorder{decide: func(AuthzRequest) (int, AuthzResponse) {
+		return http.StatusOK, AuthzResponse{Allowed: true}
+	}}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+	s, session := newAuthzServer(t, AuthzWebhookConfig{URL: server.URL, CacheTTL: time.Minute})
+	now := time.Now()
+	s.authz.now = func() time.Time { return now }
+
+	callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"})
+	callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"})
+	if got := webhook.count(); got != 1 {
+		t.Errorf("expected the second call to hit the cache, got %d webhook requests", got)
+	}
+
+	// 命名空间不同时重新询问
+	callAuthorized(t, session, "list_pods", map[string]any{"namespace": "kube-system"})
+	if got := webhook.count(); got != 2 {
+		t.Errorf("expected another namespace to ask the webhook, got %d webhook requests", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"})
+	if got := webhook.count(); got != 3 {
+		t.Errorf("expected an expired decision to ask the webhook again, got %d webhook requests", got)
+	}
+}
+
+// TestAuthzWebhookOutage 测试 webhook 出错、超时或无法访问时按失败策略允许或拒绝调用，且结果不被缓存
+func TestAuthzWebhookOutage(t *testing.T) {
+	webhook := &authzRecorder{decide: func(AuthzRequest) (int, AuthzResponse) {
+		return http.StatusInternalServerError, AuthzResponse{}
+	}}
+	failing := httptest.NewServer(webhook)
+	defer failing.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	for _, url := range []string{failing.URL, slow.URL, down.URL} {
+		for _, failOpen := range []bool{false, true} {
+			_, session := newAuthzServer(t, AuthzWebhookConfig{URL: url, Timeout: 100 * time.Millisecond, FailOpen: failOpen})
+			result, text := callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"})
+			if failOpen && result.IsError {
+				t.Errorf("%s: expected fail-open to allow the call, got %s", url, text)
+			}
+			if !failOpen && (!result.IsError || text != "list_pods denied: authorization webhook unavailable") {
+				t.Errorf("%s: expected fail-closed to deny the call, got %v %q", url, result.IsError, text)
+			}
+		}
+	}
+
+	// 失败不被缓存：webhook 恢复后立即生效
+	_, session := newAuthzServer(t, AuthzWebhookConfig{URL: failing.URL})
+	if result, _ := callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"}); !result.IsError {
+		t.Fatalf("expected the call to be denied while the webhook fails")
+	}
+	webhook.mu.Lock()
+	webhook.decide = func(AuthzRequest) (int, AuthzResponse) { return http.StatusOK, AuthzResponse{Allowed: true} }
+	webhook.mu.Unlock()
+	if result, text := callAuthorized(t, session, "list_pods", map[string]any{"namespace": "shop"}); result.IsError {
+		t.Errorf("expected the recovered webhook to be asked again, got %s", text)
+	}
+}
@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// batchToolName is the tool that runs other tools; it cannot be nested
+// batchToolName 执行其他工具的工具，不能嵌套
+const batchToolName = "batch_call"
+
+// maxBatchCalls is the maximum number of calls in one batch_call
+// maxBatchCalls 单次 batch_call 最多包含的调用数
+const maxBatchCalls = 10
+
+// captureHandler returns a middleware that keeps the handler it wraps in target, so a
+// tool can send requests through the same chain as the client
+// captureHandler 返回一个将其包装的处理器保存到 target 的中间件，使工具可以通过与客户端相同的处理链发送请求
+func captureHandler(target *mcp.MethodHandler) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		*target = next
+		return next
+	}
+}
+
+// BatchCall is one tool call of batch_call
+// BatchCall 是 batch_call 中的一次工具调用
+type BatchCall struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// BatchCallEntry is the result of one call, as the tool would have returned it
+// BatchCallEntry 是单次调用的结果，与直接调用该工具时返回的内容相同
+type BatchCallEntry struct {
+	Tool              string        `json:"tool"`
+	IsError           bool          `json:"is_error"`
+	Content           []mcp.Content `json:"content"`
+	StructuredContent interface{}   `json:"structured_content,omitempty"`
+}
+
+// BatchCallResult represents the result of batch_call tool
+// BatchCallResult 表示 batch_call 工具的结果
+type BatchCallResult struct {
+	// Results are in the order of the calls
+	// Results 与调用的顺序相同
+	Results []BatchCallEntry `json:"results"`
+}
+
+// handleBatchCall handles batch_call tool. Each call goes through the full request
+// chain (auditing, history, impersonation) as its own tools/call; a failing call
+// only fails its entry.
+// handleBatchCall 处理 batch_call 工具。每次调用作为独立的 tools/call 经过完整的请求处理链 (审计、调用历史、身份模拟)；
+// 单个调用失败只影响其对应的结果
+func (s *Server) handleBatchCall(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Calls  []BatchCall `json:"calls"`
+	Serial bool        `json:"serial,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	BatchCallResult,
+	error,
+) {
+	if len(input.Calls) == 0 || len(input.Calls) > maxBatchCalls {
+		return toolError(fmt.Sprintf("calls must have between 1 and %d entries, got %d", maxBatchCalls, len(input.Calls))), BatchCallResult{Results: []BatchCallEntry{}}, nil
+	}
+	mutating, err := s.mutatingTools(ctx, req)
+	if err != nil {
+		return nil, BatchCallResult{}, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	results := make([]BatchCallEntry, len(input.Calls))
+	call := func(i int) {
+		results[i] = s.runBatchCall(ctx, req, input.Calls[i], mutating, input.Serial)
+	}
+	if input.Serial {
+		for i := range input.Calls {
+			call(i)
+		}
+		return nil, BatchCallResult{Results: results}, nil
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.fanOutConcurrency)
+	for i := range input.Calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			call(i)
+		}(i)
+	}
+	wg.Wait()
+	return nil, BatchCallResult{Results: results}, nil
+}
+
+// runBatchCall runs one call of a batch with the session and credentials of the batch request
+// runBatchCall 以 batch 请求的会话和凭据执行其中的一次调用
+func (s *Server) runBatchCall(ctx context.Context, req *mcp.CallToolRequest, call BatchCall, mutating map[string]bool, serial bool) BatchCallEntry {
+	switch {
+	case call.Tool == batchToolName:
+		return batchCallError(call.Tool, "batch_call cannot be nested")
+	case mutating[call.Tool] && !(s.allowWrite && serial):
+		return batchCallError(call.Tool, fmt.Sprintf("%s modifies the cluster and is not allowed in a batch; set serial=true to run it in order", call.Tool))
+	}
+	if query, ok := s.batchSavedQuery(req, call); ok {
+		switch {
+		case query.Tool == batchToolName:
+			return batchCallError(call.Tool, fmt.Sprintf("query %q runs batch_call, which cannot be nested", query.Name))
+		case query.Mutating && !(s.allowWrite && serial):
+			return batchCallError(call.Tool, fmt.Sprintf("query %q runs %s, which modifies the cluster, and is not allowed in a batch; set serial=true to run it in order", query.Name, query.Tool))
+		}
+	}
+
+	callResult, err := s.dispatchToolCall(ctx, req, call.Tool, call.Arguments)
+	if err != nil {
+		return batchCallError(call.Tool, err.Error())
+	}
+	return BatchCallEntry{
+		Tool:              call.Tool,
+		IsError:           callResult.IsError,
+		Content:           callResult.Content,
+		StructuredContent: callResult.StructuredContent,
+	}
+}
+
+// batchSavedQuery returns the saved query a run_query call of a batch runs, so the batch
+// rules apply to the tool behind it
+// batchSavedQuery 返回批量调用中 run_query 调用所执行的已保存查询，使批量调用的规则同样适用于其背后的工具
+func (s *Server) batchSavedQuery(req *mcp.CallToolRequest, call BatchCall) (SavedQuery, bool) {
+	if call.Tool != "run_query" || s.preferences == nil {
+		return SavedQuery{}, false
+	}
+	name, _ := call.Arguments["name"].(string)
+	return s.preferences.savedQuery(s.preferencesKey(req), name)
+}
+
+// dispatchToolCall runs a tool call through the full request chain with the session and
+// credentials of req. The content of the result is never nil.
+// dispatchToolCall 以 req 的会话和凭据通过完整的请求处理链执行一次工具调用，结果的 content 不为 nil
+func (s *Server) dispatchToolCall(ctx context.Context, req *mcp.CallToolRequest, tool string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	args, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments: %v", err)
+	}
+	result, err := s.callHandler(ctx, "tools/call", &mcp.CallToolRequest{
+		Session: req.Session,
+		Params:  &mcp.CallToolParamsRaw{Name: tool, Arguments: args},
+		Extra:   req.Extra,
+	})
+	if err != nil {
+		return nil, err
+	}
+	callResult, ok := result.(*mcp.CallToolResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result %T", result)
+	}
+	if callResult.Content == nil {
+		callResult.Content = []mcp.Content{}
+	}
+	return callResult, nil
+}
+
+// batchCallError is the entry of a call that failed or was refused
+// batchCallError 是失败或被拒绝的调用对应的结果
+func batchCallError(tool, message string) BatchCallEntry {
+	return BatchCallEntry{Tool: tool, IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: message}}}
+}
+
+// mutatingTools returns the registered tools that change the cluster, i.e. those
+// annotated without readOnlyHint (read-only tools have no annotations)
+// mutatingTools 返回会修改集群的已注册工具，即带有注解但没有 readOnlyHint 的工具 (只读工具没有注解)
+func (s *Server) mutatingTools(ctx context.Context, req *mcp.CallToolRequest) (map[string]bool, error) {
+	tools, err := s.listTools(ctx, req.Session)
+	if err != nil {
+		return nil, err
+	}
+	mutating := map[string]bool{}
+	for _, tool := range tools {
+		if tool.Annotations != nil && !tool.Annotations.ReadOnlyHint {
+			mutating[tool.Name] = true
+		}
+	}
+	return mutating, nil
+}
@@ -0,0 +1,173 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// batchEntry 是解析后的 batch_call 单个结果
+type batchEntry struct {
+	Tool              string                   `json:"tool"`
+	IsError           bool                     `json:"is_error"`
+	Content           []map[string]interface{} `json:"content"`
+	StructuredContent map[string]interface{}   `json:"structured_content"`
+}
+
+// callBatch 调用 batch_call 并解析每个调用的结果
+func callBatch(t *testing.T, session *mcp.ClientSession, args map[string]any) []batchEntry {
+	t.Helper()
+	result := callTool(t, session, "batch_call", args)
+	var batch struct {
+		Results []batchEntry `json:"results"`
+	}
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &batch); err != nil {
+		t.Fatalf("failed to decode batch result: %v", err)
+	}
+	return batch.Results
+}
+
+// entryText 返回结果中第一个文本内容
+func entryText(entry batchEntry) string {
+	if len(entry.Content) == 0 {
+		return ""
+	}
+	text, _ := entry.Content[0]["text"].(string)
+	return text
+}
+
+// TestBatchCall 测试 batch_call 按调用顺序返回结果，单个调用失败不影响其他调用，每个调用都被审计
+func TestBatchCall(t *testing.T) {
+	var audit bytes.Buffer
+	s := NewServer("test-token", &Options{AuditLog: &audit})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	results := callBatch(t, session, map[string]any{"calls": []map[string]any{
+		{"tool": "list_pods", "arguments": map[string]any{"namespace": "shop"}},
+		{"tool": "get_resource", "arguments": map[string]any{"resource_type": "deployments", "namespace": "shop", "name": "missing"}},
+		{"tool": "get_events", "arguments": map[string]any{"namespace": "shop"}},
+		{"tool": "no_such_tool"},
+		{"tool": "list_pods", "arguments": map[string]any{"bogus": true}},
+		{"tool": "batch_call", "arguments": map[string]any{"calls": []any{}}},
+	}})
+
+	wantTools := []string{"list_pods", "get_resource", "get_events", "no_such_tool", "list_pods", "batch_call"}
+	wantErrors := []bool{false, true, false, true, true, true}
+	if len(results) != len(wantTools) {
+		t.Fatalf("expected %d results, got %+v", len(wantTools), results)
+	}
+	for i, entry := range results {
+		if entry.Tool != wantTools[i] || entry.IsError != wantErrors[i] {
+			t.Errorf("result %d = %s (is_error %v), want %s (is_error %v): %s", i, entry.Tool, entry.IsError, wantTools[i], wantErrors[i], entryText(entry))
+		}
+		if len(entry.Content) == 0 {
+			t.Errorf("result %d has no content", i)
+		}
+	}
+	if !strings.Contains(results[0].StructuredContent["pods"].(string), "web-7d4b9c8f6-abcde") {
+		t.Errorf("unexpected pods %v", results[0].StructuredContent)
+	}
+	if !strings.Contains(entryText(results[1]), "not found") {
+		t.Errorf("expected the missing deployment error, got %q", entryText(results[1]))
+	}
+	if !strings.Contains(entryText(results[5]), "cannot be nested") {
+		t.Errorf("expected nesting to be refused, got %q", entryText(results[5]))
+	}
+
+	// batch_call 自身和其中的每个调用都写入审计日志
+	if got := strings.Count(audit.String(), `"method":"tools/call","tool":"list_pods"`); got != 2 {
+		t.Errorf("expected both list_pods calls to be audited, got %d:\n%s", got, audit.String())
+	}
+	if !strings.Contains(audit.String(), `"tool":"batch_call"`) {
+		t.Errorf("expected the batch to be audited:\n%s", audit.String())
+	}
+
+	tooMany := make([]map[string]any, maxBatchCalls+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]any{"tool": "list_namespaces"}
+	}
+	for _, calls := range [][]map[string]any{{}, tooMany} {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "batch_call", Arguments: map[string]any{"calls": calls}})
+		if err != nil || !result.IsError {
+			t.Errorf("expected %d calls to be a tool error, got %+v %v", len(calls), result, err)
+		}
+	}
+}
+
+// TestBatchCallMutating 测试修改集群的工具在并发 batch 中被拒绝，只有在启用写操作且 serial=true 时按顺序执行
+func TestBatchCallMutating(t *testing.T) {
+	s := NewServer("test-token", &Options{AllowWrite: true})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	calls := []map[string]any{
+		{"tool": "cordon_node", "arguments": map[string]any{"node_name": "node-1", "confirm": true}},
+		{"tool": "describe_node", "arguments": map[string]any{"node_name": "node-1"}},
+	}
+	results := callBatch(t, session, map[string]any{"calls": calls})
+	if !results[0].IsError || !strings.Contains(entryText(results[0]), "cordon_node modifies the cluster") {
+		t.Errorf("expected cordon_node to be refused, got %+v", results[0])
+	}
+	if results[1].IsError {
+		t.Errorf("expected describe_node to run, got %s", entryText(results[1]))
+	}
+
+	// changed 为 true 说明被拒绝的 cordon 没有执行
+	results = callBatch(t, session, map[string]any{"calls": calls, "serial": true})
+	if results[0].IsError || results[0].StructuredContent["changed"] != true {
+		t.Errorf("expected cordon_node to run with serial=true, got %+v", results[0])
+	}
+
+	// 通过 run_query 执行的已保存查询同样受批量调用规则约束
+	querySession := newQueryServer(t, filepath.Join(t.TempDir(), "state.json"), true)
+	if _, result := callQueryTool[SaveQueryResult](t, querySession, "save_query", map[string]any{
+		"name":      "cordon",
+		"tool":      "cordon_node",
+		"arguments": map[string]any{"node_name": "node-1", "confirm": true},
+	}); result.IsError {
+		t.Fatalf("save_query failed: %s", resultText(result))
+	}
+	queryCalls := []map[string]any{{"tool": "run_query", "arguments": map[string]any{"name": "cordon"}}}
+	results = callBatch(t, querySession, map[string]any{"calls": queryCalls})
+	if !results[0].IsError || !strings.Contains(entryText(results[0]), "runs cordon_node, which modifies the cluster") {
+		t.Errorf("expected the saved cordon_node query to be refused, got %+v", results[0])
+	}
+	results = callBatch(t, querySession, map[string]any{"calls": queryCalls, "serial": true})
+	if results[0].IsError {
+		t.Errorf("expected the saved query to run with serial=true, got %s", entryText(results[0]))
+	}
+
+	// batch_call 不能保存为查询，否则可以通过 run_query 嵌套
+	_, result := callQueryTool[SaveQueryResult](t, querySession, "save_query", map[string]any{
+		"name":      "nested",
+		"tool":      batchToolName,
+		"arguments": map[string]any{"calls": calls},
+	})
+	if !result.IsError || !strings.Contains(resultText(result), "cannot be saved as a query") {
+		t.Errorf("expected batch_call to be refused by save_query, got %s", resultText(result))
+	}
+
+	// 未启用写操作时，修改集群的工具没有注册
+	readOnly := NewServer("test-token", nil)
+	if err := readOnly.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	readOnly.RegisterTools()
+	results = callBatch(t, connectTestClient(t, readOnly, nil), map[string]any{"calls": calls, "serial": true})
+	if !results[0].IsError || results[1].IsError {
+		t.Errorf("expected only cordon_node to fail without write tools, got %+v", results)
+	}
+}
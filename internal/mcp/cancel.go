@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// httpRequestHeader carries the ID under which HTTPRequestMiddleware registered the
+// context of a POST, so the MCP handlers can find it. Copies sent by the client are removed.
+// httpRequestHeader 携带 HTTPRequestMiddleware 登记 POST 请求上下文时使用的 ID，
+// 供 MCP 处理器查找。客户端自行发送的同名请求头会被删除
+const httpRequestHeader = "X-K8s-Mcp-Request-Id"
+
+// httpRequests tracks the contexts of the POST requests being served
+// httpRequests 记录正在处理的 POST 请求的上下文
+type httpRequests struct {
+	next     atomic.Uint64
+	contexts sync.Map // string -> context.Context
+}
+
+// register stores ctx under a new ID and returns the ID and a function removing it
+// register 以新的 ID 保存 ctx，返回该 ID 和删除它的函数
+func (h *httpRequests) register(ctx context.Context) (string, func()) {
+	id := strconv.FormatUint(h.next.Add(1), 10)
+	h.contexts.Store(id, ctx)
+	return id, func() { h.contexts.Delete(id) }
+}
+
+// lookup returns the context registered under id
+// lookup 返回以 id 登记的上下文
+func (h *httpRequests) lookup(id string) (context.Context, bool) {
+	ctx, ok := h.contexts.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return ctx.(context.Context), true
+}
+
+// HTTPRequestMiddleware registers the context of every POST while it is served, so a
+// call made by the POST is cancelled when the client disconnects or gives up on it
+// (the SDK runs calls on the session's context, which outlives the request)
+// HTTPRequestMiddleware 在处理每个 POST 请求期间登记其上下文，使客户端断开或放弃请求时取消其中的调用
+// （SDK 在会话的上下文中运行调用，会话比请求存在得更久）
+func (s *Server) HTTPRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(httpRequestHeader)
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, unregister := s.httpRequests.register(r.Context())
+		defer unregister()
+		r.Header.Set(httpRequestHeader, id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpCancelMiddleware cancels a request once the HTTP request that carried it is done,
+// which stops its Kubernetes calls. Requests from other transports are passed through.
+// httpCancelMiddleware 在承载请求的 HTTP 请求结束时取消该请求，从而停止其 Kubernetes 调用。
+// 其他传输的请求原样传递
+func (s *Server) httpCancelMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		extra := req.GetExtra()
+		if extra == nil || extra.Header == nil {
+			return next(ctx, method, req)
+		}
+		httpCtx, ok := s.httpRequests.lookup(extra.Header.Get(httpRequestHeader))
+		if !ok {
+			return next(ctx, method, req)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(httpCtx, func() {
+			s.logger.Debug("HTTP request ended, cancelling the call", "method", method)
+			cancel()
+		})
+		defer stop()
+		return next(ctx, method, req)
+	}
+}
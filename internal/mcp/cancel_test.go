@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestHTTPDisconnectCancelsCall 测试 HTTP 客户端放弃请求后正在执行的工具调用被取消，fake clientset 的监听随之停止
+func TestHTTPDisconnectCancelsCall(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	})
+	watcher := watch.NewFake()
+	started := make(chan struct{})
+	var once sync.Once
+	client.PrependWatchReactor("pods", func(k8stesting.Action) (bool, watch.Interface, error) {
+		once.Do(func() { close(started) })
+		return true, watcher, nil
+	})
+
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	_, sessionID := postRPC(t, httpServer.URL, "", initializeBody("2025-06-18"))
+	postRPC(t, httpServer.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"wait_for","arguments":{"resource_type":"pods","name":"web","condition":"Ready","namespace":"shop","timeout_seconds":60}}}`
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, httpServer.URL, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set(sessionIDHeader, sessionID)
+	// 客户端伪造的请求 ID 被忽略
+	req.Header.Set(httpRequestHeader, "1")
+	go func() {
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait_for never started watching")
+	}
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !watcher.IsStopped() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the abandoned call to stop its watch")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
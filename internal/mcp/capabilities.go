@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// featureGate is an optional feature of the server, turned on by a server flag. The
+// features of get_server_info, the initialize instructions and get_server_capabilities
+// are all generated from featureGates. RegisterTools registers the tools of a gate on
+// its own; TestFeatureGateTools fails when the tools listed here drift from it.
+// featureGate 是由服务器参数开启的可选功能。get_server_info 的 features、initialize 的说明以及
+// get_server_capabilities 都根据 featureGates 生成。开关对应的工具由 RegisterTools 单独注册，
+// 这里列出的工具与其不一致时 TestFeatureGateTools 会失败。
+type featureGate struct {
+	name        string
+	description string
+	flag        string
+	// tools are only registered when the gate is enabled (cp_to_pod needs both exec and write)
+	// tools 只在开关启用时注册（cp_to_pod 同时需要 exec 和 write）
+	tools   []string
+	enabled func(s *Server) bool
+}
+
+// featureGates lists the optional features, the ones that decide which tools exist first
+// featureGates 列出所有可选功能，决定哪些工具存在的功能排在前面
+var featureGates = []featureGate{
+	{
+		name:        "write",
+		description: "tools that modify cluster objects; all but create_namespace ask the user for confirmation",
+		flag:        "--allow-write",
+		tools:       []string{"rollback_deployment", "cordon_node", "uncordon_node", "drain_node", "label_resource", "annotate_resource", "delete_resource", "create_namespace", "delete_namespace", "cp_to_pod"},
+		enabled:     func(s *Server) bool { return s.allowWrite },
+	},
+	{
+		name:        "exec",
+		description: "tools that run processes in pods or copy files out of them",
+		flag:        "--allow-exec",
+		tools:       []string{"debug_pod", "cp_from_pod", "cp_to_pod"},
+		enabled:     func(s *Server) bool { return s.allowExec },
+	},
+	{
+		name:        "kubeconfig_export",
+		description: "export of a minimal kubeconfig per context, credentials redacted by default",
+		flag:        "--allow-kubeconfig-export",
+		tools:       []string{"get_kubeconfig"},
+		enabled:     func(s *Server) bool { return s.allowKubeconfigExport },
+	},
+	{
+		name:        "call_history",
+		description: "the recent tool calls made to this server",
+		flag:        "--history-size",
+		tools:       []string{historyToolName},
+		enabled:     func(s *Server) bool { return s.history != nil },
+	},
+	{
+		name:        "state_file",
+		description: "saved queries, and each caller's cluster and namespace remembered across restarts",
+		flag:        "--state-file",
+		tools:       []string{"save_query", "run_query", "list_queries", "delete_query"},
+		enabled:     func(s *Server) bool { return s.preferences != nil },
+	},
+	{
+		name:        "subscriptions",
+		description: "resources/subscribe notifications backed by Kubernetes watches",
+		flag:        "--enable-subscriptions",
+		enabled:     func(s *Server) bool { return s.subscriptions != nil },
+	},
+	{
+		name:        "audit_log",
+		description: "every tool call is recorded in an audit log",
+		flag:        "--audit-log",
+		enabled:     func(s *Server) bool { return s.audit != nil },
+	},
+	{
+		name:        "authz_webhook",
+		description: "every tool call is authorized by a webhook, which may deny it",
+		flag:        "--authz-webhook-url",
+		enabled:     func(s *Server) bool { return s.authz != nil },
+	},
+	{
+		name:        "client_cert_auth",
+		description: "callers may authenticate with a TLS client certificate",
+		flag:        "--client-ca",
+		enabled:     func(s *Server) bool { return s.clientCertAuth },
+	},
+	{
+		name:        "oidc_auth",
+		description: "callers may authenticate with an OIDC token",
+		flag:        "--oidc-issuer-url",
+		enabled:     func(s *Server) bool { return s.oidc != nil },
+	},
+}
+
+// ServerCapabilities describes what this server allows, as returned by get_server_capabilities
+// ServerCapabilities 描述服务器允许的操作，由 get_server_capabilities 返回
+type ServerCapabilities struct {
+	Features   []FeatureCapability `json:"features"`
+	Namespaces NamespaceCapability `json:"namespaces"`
+	Limits     ServerLimits        `json:"limits"`
+	RateLimits RateLimits          `json:"rate_limits"`
+}
+
+// FeatureCapability is one feature gate, the flag that enables it and the tools it registers
+// FeatureCapability 是一个功能开关，包括启用它的参数和它注册的工具
+type FeatureCapability struct {
+	Name        string   `json:"name"`
+	Enabled     bool     `json:"enabled"`
+	Description string   `json:"description"`
+	Flag        string   `json:"flag"`
+	Tools       []string `json:"tools,omitempty"`
+}
+
+// NamespaceCapability summarizes the namespace policy (see --allowed-namespaces)
+// NamespaceCapability 概述命名空间策略（见 --allowed-namespaces）
+type NamespaceCapability struct {
+	Restricted        bool     `json:"restricted"`
+	Allowed           []string `json:"allowed,omitempty"`
+	AllowClusterScope bool     `json:"allow_cluster_scope"`
+}
+
+// ServerLimits are the size and time limits applied to tool calls
+// ServerLimits 是应用于工具调用的大小和时间限制
+type ServerLimits struct {
+	MaxResultBytes       int `json:"max_result_bytes"`
+	ToolsPageSize        int `json:"tools_page_size,omitempty"`
+	FanOutConcurrency    int `json:"fan_out_concurrency"`
+	FanOutTimeoutSeconds int `json:"fan_out_timeout_seconds"`
+	MaxWaitSeconds       int `json:"max_wait_seconds"`
+}
+
+// RateLimits are the client-side rate limits of the Kubernetes API requests: the default
+// and the clusters whose effective settings differ from it
+// RateLimits 是 Kubernetes API 请求的客户端限流参数：默认值以及实际配置与默认值不同的集群
+type RateLimits struct {
+	Default  k8s.ClientSettings            `json:"default"`
+	Clusters map[string]k8s.ClientSettings `json:"clusters,omitempty"`
+}
+
+// capabilities collects the feature gates, namespace policy and limits of the server
+// capabilities 收集服务器的功能开关、命名空间策略和各项限制
+func (s *Server) capabilities() ServerCapabilities {
+	features := make([]FeatureCapability, 0, len(featureGates))
+	for _, gate := range featureGates {
+		features = append(features, FeatureCapability{
+			Name:        gate.name,
+			Enabled:     gate.enabled(s),
+			Description: gate.description,
+			Flag:        gate.flag,
+			Tools:       gate.tools,
+		})
+	}
+
+	policy := s.clusterManager.NamespacePolicy()
+	rateLimits := RateLimits{Default: s.clusterManager.DefaultClientSettings()}
+	for _, name := range s.clusterManager.GetClusters() {
+		settings, _, err := s.clusterManager.EffectiveClientSettings(name)
+		if err != nil || settings == rateLimits.Default {
+			continue
+		}
+		if rateLimits.Clusters == nil {
+			rateLimits.Clusters = make(map[string]k8s.ClientSettings)
+		}
+		rateLimits.Clusters[name] = settings
+	}
+
+	return ServerCapabilities{
+		Features: features,
+		Namespaces: NamespaceCapability{
+			Restricted:        policy.Restricted(),
+			Allowed:           policy.Patterns(),
+			AllowClusterScope: policy.AllowClusterScope(),
+		},
+		Limits: ServerLimits{
+			MaxResultBytes:       s.maxResultBytes,
+			ToolsPageSize:        s.toolsPageSize,
+			FanOutConcurrency:    s.fanOutConcurrency,
+			FanOutTimeoutSeconds: int(s.fanOutTimeout.Seconds()),
+			MaxWaitSeconds:       int(maxWaitTimeout.Seconds()),
+		},
+		RateLimits: rateLimits,
+	}
+}
+
+// features reports whether each feature gate is enabled, for get_server_info
+// features 返回每个功能开关是否启用，用于 get_server_info
+func (s *Server) features() map[string]bool {
+	features := make(map[string]bool, len(featureGates))
+	for _, gate := range featureGates {
+		features[gate.name] = gate.enabled(s)
+	}
+	return features
+}
+
+// instructions renders the capabilities as the instructions of the initialize result, so
+// agents know up front which tools exist and which limits apply
+// instructions 将服务器能力渲染为 initialize 结果中的说明，使代理预先知道哪些工具可用以及适用哪些限制
+func (s *Server) instructions() string {
+	capabilities := s.capabilities()
+	var enabled, disabled []string
+	for _, feature := range capabilities.Features {
+		line := "- " + feature.Name + ": " + feature.Description
+		if len(feature.Tools) > 0 {
+			line += " (" + strings.Join(feature.Tools, ", ") + ")"
+		}
+		if feature.Enabled {
+			enabled = append(enabled, line)
+		} else {
+			disabled = append(disabled, line+"; enabled with "+feature.Flag)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("This server inspects Kubernetes clusters. Optional features decide which other tools exist; get_server_capabilities returns this summary as JSON.\n")
+	if len(enabled) > 0 {
+		b.WriteString("\nEnabled features:\n" + strings.Join(enabled, "\n") + "\n")
+	}
+	if len(disabled) > 0 {
+		b.WriteString("\nDisabled features (the tools listed here are not registered, so don't try them; tell the user the server operator can enable them):\n" + strings.Join(disabled, "\n") + "\n")
+	}
+
+	b.WriteString("\nNamespaces: ")
+	if ns := capabilities.Namespaces; ns.Restricted {
+		fmt.Fprintf(&b, "every operation is restricted to %s", strings.Join(ns.Allowed, ", "))
+		if !ns.AllowClusterScope {
+			b.WriteString("; cluster-scoped resources such as nodes are refused")
+		}
+		b.WriteString(".\n")
+	} else {
+		b.WriteString("all namespaces are allowed.\n")
+	}
+
+	limits := capabilities.Limits
+	fmt.Fprintf(&b, "Limits: tool results above %d bytes are truncated (max_bytes changes it per call); calls across all clusters query %d clusters at a time and stop after %ds; waits stop after at most %ds.\n",
+		limits.MaxResultBytes, limits.FanOutConcurrency, limits.FanOutTimeoutSeconds, limits.MaxWaitSeconds)
+
+	rateLimits := capabilities.RateLimits
+	fmt.Fprintf(&b, "Rate limits: Kubernetes API requests are limited to %s per cluster", formatRateLimit(rateLimits.Default))
+	if len(rateLimits.Clusters) > 0 {
+		names := make([]string, 0, len(rateLimits.Clusters))
+		for name := range rateLimits.Clusters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		overrides := make([]string, 0, len(names))
+		for _, name := range names {
+			overrides = append(overrides, name+": "+formatRateLimit(rateLimits.Clusters[name]))
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(overrides, "; "))
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// formatRateLimit renders client settings as "50 QPS, burst 100"
+// formatRateLimit 将客户端配置渲染为 "50 QPS, burst 100"
+func formatRateLimit(settings k8s.ClientSettings) string {
+	return fmt.Sprintf("%g QPS, burst %d", settings.QPS, settings.Burst)
+}
+
+// instructionsMiddleware sets the instructions of initialize results from the server's
+// current configuration
+// instructionsMiddleware 根据服务器当前的配置设置 initialize 结果中的说明
+func (s *Server) instructionsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if initResult, ok := result.(*mcp.InitializeResult); ok && err == nil && method == "initialize" {
+			initResult.Instructions = s.instructions()
+		}
+		return result, err
+	}
+}
+
+// handleGetServerCapabilities handles get_server_capabilities tool
+// handleGetServerCapabilities 处理 get_server_capabilities 工具
+func (s *Server) handleGetServerCapabilities(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+	*mcp.CallToolResult,
+	ServerCapabilities,
+	error,
+) {
+	return nil, s.capabilities(), nil
+}
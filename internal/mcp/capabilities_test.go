@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+)
+
+// registeredTools 返回服务器注册的工具名称
+func registeredTools(t *testing.T, s *Server) map[string]bool {
+	t.Helper()
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	names := map[string]bool{}
+	for tool, err := range session.Tools(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("listing tools failed: %v", err)
+		}
+		names[tool.Name] = true
+	}
+	return names
+}
+
+// TestFeatureGateTools 测试每个功能开关列出的工具与关闭该开关时消失的工具一致
+func TestFeatureGateTools(t *testing.T) {
+	allEnabled := func() Options {
+		return Options{
+			AllowWrite:            true,
+			AllowExec:             true,
+			AllowKubeconfigExport: true,
+			StateFile:             filepath.Join(t.TempDir(), "state.json"),
+			EnableSubscriptions:   true,
+		}
+	}
+	disable := map[string]func(*Options){
+		"write":             func(o *Options) { o.AllowWrite = false },
+		"exec":              func(o *Options) { o.AllowExec = false },
+		"kubeconfig_export": func(o *Options) { o.AllowKubeconfigExport = false },
+		"call_history":      func(o *Options) { o.HistorySize = -1 },
+		"state_file":        func(o *Options) { o.StateFile = "" },
+		"subscriptions":     func(o *Options) { o.EnableSubscriptions = false },
+	}
+
+	opts := allEnabled()
+	all := registeredTools(t, NewServer("test-token", &opts))
+	for _, gate := range featureGates {
+		off, ok := disable[gate.name]
+		if !ok {
+			continue
+		}
+		t.Run(gate.name, func(t *testing.T) {
+			opts := allEnabled()
+			off(&opts)
+			s := NewServer("test-token", &opts)
+			if gate.enabled(s) {
+				t.Fatalf("expected %s to be disabled", gate.name)
+			}
+			remaining := registeredTools(t, s)
+			var missing []string
+			for name := range all {
+				if !remaining[name] {
+					missing = append(missing, name)
+				}
+			}
+			sort.Strings(missing)
+			want := append([]string(nil), gate.tools...)
+			sort.Strings(want)
+			if strings.Join(missing, ",") != strings.Join(want, ",") {
+				t.Errorf("disabling %s removed %v, the gate lists %v", gate.name, missing, want)
+			}
+		})
+	}
+}
+
+// TestServerCapabilities 测试切换功能开关、命名空间策略和限制后，initialize 说明和 get_server_capabilities 的输出随之变化
+func TestServerCapabilities(t *testing.T) {
+	policy, err := k8s.ParseNamespacePolicy("team-a,team-b-*", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		opts         Options
+		instructions []string
+		check        func(t *testing.T, c ServerCapabilities)
+	}{
+		{
+			name: "defaults",
+			instructions: []string{
+				"Disabled features (the tools listed here are not registered",
+				"- write: tools that modify cluster objects; all but create_namespace ask the user for confirmation (rollback_deployment,",
+				"- exec: tools that run processes in pods or copy files out of them (debug_pod, cp_from_pod, cp_to_pod); enabled with --allow-exec",
+				"Namespaces: all namespaces are allowed.",
+				"tool results above 1048576 bytes are truncated",
+				"limited to 5 QPS, burst 10 per cluster.",
+			},
+			check: func(t *testing.T, c ServerCapabilities) {
+				if c.Features[0].Name != "write" || c.Features[0].Enabled || c.Features[0].Flag != "--allow-write" {
+					t.Errorf("expected write to be disabled, got %+v", c.Features[0])
+				}
+				if c.Namespaces.Restricted || !c.Namespaces.AllowClusterScope || c.Limits.MaxResultBytes != DefaultMaxResultBytes || c.Limits.MaxWaitSeconds != 300 {
+					t.Errorf("unexpected namespaces or limits: %+v %+v", c.Namespaces, c.Limits)
+				}
+				if c.RateLimits.Default != (k8s.ClientSettings{QPS: 5, Burst: 10}) || c.RateLimits.Clusters != nil {
+					t.Errorf("unexpected rate limits: %+v", c.RateLimits)
+				}
+			},
+		},
+		{
+			name: "write and restricted",
+			opts: Options{
+				AllowWrite:        true,
+				NamespacePolicy:   policy,
+				MaxResultBytes:    4096,
+				K8sClient:         k8s.ClientSettings{QPS: 50, Burst: 100},
+				K8sClusterClients: map[string]k8s.ClientSettings{"prod": {QPS: 10}},
+			},
+			instructions: []string{
+				"Enabled features:\n- write: tools that modify cluster objects",
+				"Namespaces: every operation is restricted to team-a, team-b-*; cluster-scoped resources such as nodes are refused.",
+				"tool results above 4096 bytes are truncated",
+				"limited to 50 QPS, burst 100 per cluster (prod: 10 QPS, burst 100).",
+			},
+			check: func(t *testing.T, c ServerCapabilities) {
+				if !c.Features[0].Enabled || len(c.Features[0].Tools) == 0 {
+					t.Errorf("expected write to be enabled with its tools, got %+v", c.Features[0])
+				}
+				if !c.Namespaces.Restricted || c.Namespaces.AllowClusterScope || strings.Join(c.Namespaces.Allowed, ",") != "team-a,team-b-*" {
+					t.Errorf("unexpected namespaces: %+v", c.Namespaces)
+				}
+				if c.Limits.MaxResultBytes != 4096 {
+					t.Errorf("unexpected limits: %+v", c.Limits)
+				}
+				if c.RateLimits.Clusters["prod"] != (k8s.ClientSettings{QPS: 10, Burst: 100}) || len(c.RateLimits.Clusters) != 1 {
+					t.Errorf("expected only the prod override, got %+v", c.RateLimits)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			s := NewServer("test-token", &opts)
+			for _, name := range []string{"dev", "prod"} {
+				if err := s.clusterManager.AddCluster(name, &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+					t.Fatalf("AddCluster(%s) failed: %v", name, err)
+				}
+			}
+			s.RegisterTools()
+			session := connectTestClient(t, s, nil)
+
+			instructions := session.InitializeResult().Instructions
+			for _, want := range tt.instructions {
+				if !strings.Contains(instructions, want) {
+					t.Errorf("expected %q in the instructions:\n%s", want, instructions)
+				}
+			}
+			if strings.Contains(instructions, "Enabled features:\n- write:") != opts.AllowWrite {
+				t.Errorf("unexpected enabled features in the instructions:\n%s", instructions)
+			}
+
+			capabilities, _ := callQueryTool[ServerCapabilities](t, session, "get_server_capabilities", nil)
+			if len(capabilities.Features) != len(featureGates) {
+				t.Fatalf("expected %d features, got %+v", len(featureGates), capabilities.Features)
+			}
+			tt.check(t, capabilities)
+		})
+	}
+}
+
+// TestInstructionsOnlyForInitialize 测试说明只设置在 initialize 结果中
+func TestInstructionsOnlyForInitialize(t *testing.T) {
+	s := NewServer("test-token", nil)
+	handler := s.instructionsMiddleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.InitializeResult{}, nil
+	})
+	result, _ := handler(context.Background(), "ping", &mcp.ServerRequest[*mcp.InitializeParams]{})
+	if result.(*mcp.InitializeResult).Instructions != "" {
+		t.Error("expected no instructions outside of initialize")
+	}
+}
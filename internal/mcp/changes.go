@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleGetChangeInfo handles get_change_info tool
+// handleGetChangeInfo 处理 get_change_info 工具
+func (s *Server) handleGetChangeInfo(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	ClusterName  string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ChangeInfo,
+	error,
+) {
+	empty := types.ChangeInfo{Managers: []types.FieldManager{}, Events: []types.Event{}}
+	if input.Name == "" {
+		return toolError("name is required"), empty, nil
+	}
+	resourceType := k8s.ResourceType(input.ResourceType)
+	if resourceType == k8s.ResourceTypeEvents || resourceType == k8s.ResourceTypeEvent {
+		return toolError("get_change_info does not support events"), empty, nil
+	}
+
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace := input.Namespace
+	if !k8s.IsClusterScoped(resourceType) {
+		namespace, _ = s.resolveNamespace(ctx, namespace, false, clusterName)
+	}
+
+	info, err := s.resourceOps.GetChangeInfo(ctx, resourceType, namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, empty, fmt.Errorf("failed to get change info: %w", err)
+	}
+	return nil, *info, nil
+}
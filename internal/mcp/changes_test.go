@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestGetChangeInfoTool 测试 get_change_info 使用会话命名空间返回字段管理者及其字段路径，并拒绝 events
+func TestGetChangeInfoTool(t *testing.T) {
+	changed := metav1.NewTime(time.Date(2024, 5, 4, 8, 2, 17, 0, time.UTC))
+	client := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "checkout",
+		Namespace: "default",
+		ManagedFields: []metav1.ManagedFieldsEntry{{
+			Manager:    "kubectl-set",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "apps/v1",
+			Time:       &changed,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:image":{}}}}}}}`)},
+		}},
+	}})
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_change_info", Arguments: map[string]any{"resource_type": "deployments", "name": "checkout"}})
+	if err != nil || result.IsError {
+		t.Fatalf("get_change_info failed: %+v %v", result, err)
+	}
+	data, _ := json.Marshal(result.StructuredContent)
+	var info types.ChangeInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if info.LastChangedBy != "kubectl-set" || info.LastChangedAt != "2024-05-04T08:02:17Z" || info.LastApplied {
+		t.Errorf("unexpected change info %+v", info)
+	}
+	if len(info.Managers) != 1 || len(info.Managers[0].Fields) != 1 || info.Managers[0].Fields[0] != "spec.template.spec.containers[name=app].image" {
+		t.Errorf("unexpected managers %+v", info.Managers)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_change_info", Arguments: map[string]any{"resource_type": "events", "name": "x"}})
+	if err != nil || !result.IsError {
+		t.Errorf("expected a tool error for events, got %+v %v", result, err)
+	}
+}
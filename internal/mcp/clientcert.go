@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Headers carrying the verified client certificate identity from AuthMiddleware to
+// the MCP handlers, which only see the request headers. AuthMiddleware removes any
+// copies sent by the client before setting them.
+// 将已验证的客户端证书身份从 AuthMiddleware 传递给只能看到请求头的 MCP 处理器，
+// AuthMiddleware 会先删除客户端自行发送的同名请求头
+const (
+	clientCertUserHeader   = "X-K8s-Mcp-Client-Cert-User"
+	clientCertGroupsHeader = "X-K8s-Mcp-Client-Cert-Groups"
+)
+
+// NewClientCertTLSConfig returns a TLS config that requires every client to present a
+// certificate signed by one of the CAs in the PEM file at caPath
+// NewClientCertTLSConfig 返回要求每个客户端出示由 caPath（PEM 文件）中的 CA 签发的证书的 TLS 配置
+func NewClientCertTLSConfig(caPath string) (*tls.Config, error) {
+	data, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("invalid client CA %s: no PEM certificates found", caPath)
+	}
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// certIdentity maps the verified client certificate of r to an identity the way the
+// Kubernetes API server does: the CN is the user and each O is a group
+// certIdentity 按照 Kubernetes API server 的规则将 r 已验证的客户端证书映射为身份：CN 为用户，每个 O 为一个组
+func certIdentity(r *http.Request) (Identity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return Identity{}, false
+	}
+	return Identity{User: subject.CommonName, Groups: subject.Organization}, true
+}
+
+// setCertIdentityHeaders replaces the client certificate identity headers of r with
+// the identity of its verified certificate, if any
+// setCertIdentityHeaders 使用 r 已验证证书的身份（如果有）替换客户端证书身份请求头
+func setCertIdentityHeaders(r *http.Request) (Identity, bool) {
+	r.Header.Del(clientCertUserHeader)
+	r.Header.Del(clientCertGroupsHeader)
+	identity, ok := certIdentity(r)
+	if !ok {
+		return Identity{}, false
+	}
+	r.Header.Set(clientCertUserHeader, identity.User)
+	for _, group := range identity.Groups {
+		r.Header.Add(clientCertGroupsHeader, group)
+	}
+	return identity, true
+}
+
+// headerCertIdentity reads the client certificate identity set by AuthMiddleware
+// headerCertIdentity 读取 AuthMiddleware 设置的客户端证书身份
+func headerCertIdentity(header http.Header) (Identity, bool) {
+	user := header.Get(clientCertUserHeader)
+	if user == "" {
+		return Identity{}, false
+	}
+	return Identity{User: user, Groups: header.Values(clientCertGroupsHeader)}, true
+}
@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// testCA 是测试用的自签名 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCA 生成自签名 CA
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// clientCert 签发 CN 为 user、O 为 groups 的客户端证书
+func (ca *testCA) clientCert(t *testing.T, user string, groups ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: user, Organization: groups},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create client certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// TestNewClientCertTLSConfig 测试读取客户端 CA 文件
+func TestNewClientCertTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caPath, newTestCA(t).pem, 0o600)
+
+	config, err := NewClientCertTLSConfig(caPath)
+	if err != nil {
+		t.Fatalf("NewClientCertTLSConfig failed: %v", err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert || config.ClientCAs == nil {
+		t.Errorf("unexpected TLS config: %+v", config)
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.pem")
+	os.WriteFile(invalidPath, []byte("not a certificate"), 0o600)
+	for _, path := range []string{invalidPath, filepath.Join(dir, "missing.pem")} {
+		if _, err := NewClientCertTLSConfig(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
+
+// TestClientCertAuth 测试 mTLS 握手：CA 签发的证书无需 token 即可调用，证书身份用于身份模拟；
+// 没有证书或证书由其他 CA 签发时握手失败
+func TestClientCertAuth(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var review authorizationv1.SelfSubjectAccessReview
+		json.Unmarshal(body, &review)
+		review.Status.Allowed = r.Header.Get("Impersonate-User") == "alice"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	defer apiServer.Close()
+
+	ca := newTestCA(t)
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caPath, ca.pem, 0o600)
+	tlsConfig, err := NewClientCertTLSConfig(caPath)
+	if err != nil {
+		t.Fatalf("NewClientCertTLSConfig failed: %v", err)
+	}
+
+	s := NewServer("", &Options{ClientCertAuth: true})
+	if err := s.clusterManager.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	httpServer := httptest.NewUnstartedServer(s.CreateHTTPHandler())
+	httpServer.TLS = tlsConfig
+	httpServer.StartTLS()
+	defer httpServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(httpServer.Certificate())
+	connect := func(certs ...tls.Certificate) (*mcp.ClientSession, error) {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		return client.Connect(context.Background(), &mcp.StreamableClientTransport{
+			Endpoint: httpServer.URL,
+			HTTPClient: &http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+			}},
+		}, nil)
+	}
+
+	session, err := connect(ca.clientCert(t, "alice", "team-a"))
+	if err != nil {
+		t.Fatalf("connect with a client certificate failed: %v", err)
+	}
+	defer session.Close()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "check_permissions",
+		Arguments: map[string]any{"verb": "delete", "resource": "pods", "namespace": "team-a"},
+	})
+	if err != nil {
+		t.Fatalf("check_permissions failed: %v", err)
+	}
+	var decoded PermissionsResult
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &decoded)
+	if !decoded.Allowed || decoded.User != "alice" || !reflect.DeepEqual(decoded.Groups, []string{"team-a"}) {
+		t.Errorf("unexpected result for the certificate identity: %+v", decoded)
+	}
+
+	if _, err := connect(); err == nil {
+		t.Errorf("expected the handshake to fail without a client certificate")
+	}
+	if _, err := connect(newTestCA(t).clientCert(t, "mallory")); err == nil {
+		t.Errorf("expected the handshake to fail for a certificate from another CA")
+	}
+}
+
+// TestAuthMiddlewareClientCertHeaders 测试客户端伪造的证书身份请求头会被删除，且空 token 不会匹配未设置的服务器 token
+func TestAuthMiddlewareClientCertHeaders(t *testing.T) {
+	var seen http.Header
+	s := NewServer("", &Options{ClientCertAuth: true})
+	handler := s.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(clientCertUserHeader, "admin")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || seen != nil {
+		t.Errorf("expected a request without certificate or token to be rejected, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected an empty token to be rejected, got %d", rec.Code)
+	}
+
+	s = NewServer("server-token", &Options{ClientCertAuth: true})
+	handler = s.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer server-token")
+	req.Header.Set(clientCertUserHeader, "admin")
+	req.Header.Add(clientCertGroupsHeader, "system:masters")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if _, ok := headerCertIdentity(seen); ok {
+		t.Errorf("expected forged certificate headers to be removed, got %v", seen)
+	}
+}
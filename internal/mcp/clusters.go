@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// list_clusters health checks: each cluster gets a short deadline, and results are
+// reused for a while so repeated listings do not probe every cluster again
+// list_clusters 的健康检查：每个集群使用较短的超时，结果在一段时间内复用，避免重复列出时再次探测所有集群
+const (
+	clusterHealthTimeout = 3 * time.Second
+	clusterHealthMaxAge  = 30 * time.Second
+)
+
+// ClusterEntry is one cluster of list_clusters; Reachable is omitted when the health
+// check is skipped
+// ClusterEntry 是 list_clusters 中的一个集群，跳过健康检查时省略 Reachable
+type ClusterEntry struct {
+	Name      string `json:"name"`
+	Current   bool   `json:"current"`
+	Reachable *bool  `json:"reachable,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Source is the kubeconfig file the cluster's context came from
+	// Source 为集群的上下文所在的 kubeconfig 文件
+	Source string `json:"source,omitempty"`
+}
+
+// ClustersResult represents the result of list_clusters tool
+// ClustersResult 表示 list_clusters 工具的结果
+type ClustersResult struct {
+	Clusters string         `json:"clusters"`
+	Items    []ClusterEntry `json:"items"`
+}
+
+// handleListClusters handles list_clusters tool
+// handleListClusters 处理 list_clusters 工具
+func (s *Server) handleListClusters(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	SkipHealthCheck bool   `json:"skip_health_check,omitempty"`
+	Columns         string `json:"columns,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	ClustersResult,
+	error,
+) {
+	current := s.currentCluster(ctx)
+	names := s.clusterManager.GetClusters()
+	sort.Strings(names)
+
+	items := make([]ClusterEntry, 0, len(names))
+	if input.SkipHealthCheck {
+		for _, name := range names {
+			items = append(items, ClusterEntry{Name: name, Current: name == current, Source: s.clusterManager.ClusterSource(name)})
+		}
+	} else {
+		reachability := s.clusterManager.CheckReachability(ctx, names, clusterHealthMaxAge, clusterProbeConcurrency, clusterHealthTimeout)
+		for _, name := range names {
+			entry := ClusterEntry{Name: name, Current: name == current, Source: s.clusterManager.ClusterSource(name)}
+			if status, ok := reachability[name]; ok {
+				entry.Reachable = &status.Reachable
+				entry.Version = status.Version
+				entry.Error = status.Error
+			}
+			items = append(items, entry)
+		}
+	}
+
+	// Contexts that failed to load are never reachable
+	// 加载失败的上下文始终不可达
+	failed := s.clusterManager.GetUnavailableClusters()
+	failedNames := make([]string, 0, len(failed))
+	for name := range failed {
+		failedNames = append(failedNames, name)
+	}
+	sort.Strings(failedNames)
+	for _, name := range failedNames {
+		reachable := false
+		items = append(items, ClusterEntry{Name: name, Reachable: &reachable, Error: "unavailable: " + failed[name].Error(), Source: s.clusterManager.ClusterSource(name)})
+	}
+
+	text, err := formatClusters(s.sessionLanguage(req.Session), items, input.Columns)
+	if err != nil {
+		return toolError(err.Error()), ClustersResult{Items: []ClusterEntry{}}, nil
+	}
+	return nil, ClustersResult{Clusters: text, Items: items}, nil
+}
+
+// clusterTableColumns are the columns of the list_clusters table; the source is only
+// shown by default when the clusters come from several kubeconfig files
+// clusterTableColumns 是 list_clusters 表格的列；集群来自多个 kubeconfig 文件时才默认显示来源
+var clusterTableColumns = []tableColumn{
+	{header: "NAME", key: "name"},
+	{header: "CURRENT", key: "current"},
+	{header: "STATUS", key: "status"},
+	{header: "VERSION", key: "version"},
+	{header: "SOURCE", key: "source", hideUniform: true},
+}
+
+// formatClusters renders the clusters as a table with the given columns (see
+// selectTableColumns). STATUS is "reachable", "unreachable: <error>", or "unchecked"
+// when the health check was skipped. An empty list is reported in language.
+// formatClusters 使用指定的列 (见 selectTableColumns) 将集群渲染为表格。STATUS 为 "reachable"、
+// "unreachable: <错误>"，跳过健康检查时为 "unchecked"。集群为空时使用指定语言说明
+func formatClusters(language string, items []ClusterEntry, columns string) (string, error) {
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		status := "unchecked"
+		switch {
+		case item.Reachable == nil:
+		case *item.Reachable:
+			status = "reachable"
+		default:
+			status = "unreachable: " + item.Error
+		}
+		rows = append(rows, map[string]interface{}{
+			"name":    item.Name,
+			"current": item.Current,
+			"status":  status,
+			"version": item.Version,
+			"source":  item.Source,
+		})
+	}
+	selected, err := selectTableColumns(clusterTableColumns, columns, rows)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return localize(language, msgNoClustersLoaded), nil
+	}
+	return renderTable(rows, selected), nil
+}
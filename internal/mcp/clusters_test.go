@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestListClusters 测试 list_clusters 标记当前集群并报告可达性和版本，以及 skip_health_check 只列出名称
+func TestListClusters(t *testing.T) {
+	s := newTestServer(t, "offline")
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	decode := func(args map[string]any) ClustersResult {
+		t.Helper()
+		result := callTool(t, session, "list_clusters", args)
+		var clusters ClustersResult
+		data, _ := json.Marshal(result.StructuredContent)
+		if err := json.Unmarshal(data, &clusters); err != nil {
+			t.Fatalf("failed to decode clusters: %v", err)
+		}
+		return clusters
+	}
+
+	clusters := decode(nil)
+	if len(clusters.Items) != 2 {
+		t.Fatalf("unexpected clusters %+v", clusters)
+	}
+	mock, offline := clusters.Items[0], clusters.Items[1]
+	if mock.Name != "mock" || !mock.Current || mock.Reachable == nil || !*mock.Reachable || mock.Version != "v1.28.4-mock" {
+		t.Errorf("unexpected mock entry %+v", mock)
+	}
+	if offline.Name != "offline" || offline.Current || offline.Reachable == nil || *offline.Reachable || offline.Error == "" {
+		t.Errorf("unexpected offline entry %+v", offline)
+	}
+	lines := strings.Split(clusters.Clusters, "\n")
+	if len(lines) != 3 || strings.Join(strings.Fields(lines[1]), " ") != "mock true reachable v1.28.4-mock" ||
+		!strings.HasPrefix(strings.Join(strings.Fields(lines[2]), " "), "offline false unreachable: failed to connect to cluster") {
+		t.Errorf("unexpected text:\n%s", clusters.Clusters)
+	}
+
+	clusters = decode(map[string]any{"skip_health_check": true})
+	want := "NAME     CURRENT  STATUS     VERSION\n" +
+		"mock     true     unchecked  <none>\n" +
+		"offline  false    unchecked  <none>"
+	if clusters.Clusters != want || clusters.Items[1].Reachable != nil {
+		t.Errorf("unexpected bare listing %+v", clusters)
+	}
+
+	// 只输出选择的列
+	clusters = decode(map[string]any{"skip_health_check": true, "columns": "name,current"})
+	if clusters.Clusters != "NAME     CURRENT\nmock     true\noffline  false" {
+		t.Errorf("unexpected columns listing:\n%s", clusters.Clusters)
+	}
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_clusters", Arguments: map[string]any{"columns": "name,region"}})
+	if err != nil || !result.IsError || !strings.Contains(resultText(result), "unknown column") {
+		t.Errorf("expected an unknown column error, got %+v %v", result, err)
+	}
+}
+
+// TestListClustersSources 测试从多个 kubeconfig 文件加载时 list_clusters 给出每个集群的来源文件
+func TestListClustersSources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"prod", "staging"} {
+		config := strings.ReplaceAll(`apiVersion: v1
+kind: Config
+clusters:
+- name: NAME
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: NAME
+  context:
+    cluster: NAME
+    user: NAME
+users:
+- name: NAME
+  user:
+    token: test
+`, "NAME", name)
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(config), 0o600); err != nil {
+			t.Fatalf("write kubeconfig: %v", err)
+		}
+	}
+
+	s := NewServer("test-token", nil)
+	if err := s.LoadKubeConfigs("", dir); err != nil {
+		t.Fatalf("LoadKubeConfigs failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "list_clusters", map[string]any{"skip_health_check": true})
+	var clusters ClustersResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &clusters); err != nil {
+		t.Fatalf("failed to decode clusters: %v", err)
+	}
+	lines := strings.Split(clusters.Clusters, "\n")
+	// 来源不同时默认显示 SOURCE 列；长路径可能被截断，因此只检查列标题
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "SOURCE") || clusters.Items[1].Source != filepath.Join(dir, "staging.yaml") {
+		t.Errorf("unexpected listing %+v", clusters)
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleCompareResource handles compare_resource tool
+// handleCompareResource 处理 compare_resource 工具
+func (s *Server) handleCompareResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	ClusterA     string `json:"cluster_a"`
+	ClusterB     string `json:"cluster_b"`
+}) (
+	*mcp.CallToolResult,
+	types.ResourceComparison,
+	error,
+) {
+	namespace := input.Namespace
+	if !k8s.IsClusterScoped(k8s.ResourceType(input.ResourceType)) {
+		namespace, _ = s.resolveNamespace(ctx, namespace, false, input.ClusterA)
+	}
+
+	result, err := s.resourceOps.CompareResource(ctx, k8s.ResourceType(input.ResourceType), namespace, input.Name, input.ClusterA, input.ClusterB)
+	if err != nil {
+		return nil, types.ResourceComparison{}, fmt.Errorf("failed to compare resource: %w", err)
+	}
+	return nil, *result, nil
+}
+
+// handleCompareNamespace handles compare_namespace tool
+// handleCompareNamespace 处理 compare_namespace 工具
+func (s *Server) handleCompareNamespace(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace     string   `json:"namespace,omitempty"`
+	ResourceTypes []string `json:"resource_types,omitempty"`
+	ClusterA      string   `json:"cluster_a"`
+	ClusterB      string   `json:"cluster_b"`
+}) (
+	*mcp.CallToolResult,
+	types.NamespaceComparison,
+	error,
+) {
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, input.ClusterA)
+
+	resourceTypes := make([]k8s.ResourceType, 0, len(input.ResourceTypes))
+	for _, resourceType := range input.ResourceTypes {
+		resourceTypes = append(resourceTypes, k8s.ResourceType(resourceType))
+	}
+
+	result, err := s.resourceOps.CompareNamespace(ctx, namespace, resourceTypes, input.ClusterA, input.ClusterB)
+	if err != nil {
+		return nil, types.NamespaceComparison{}, fmt.Errorf("failed to compare namespace: %w", err)
+	}
+	return nil, *result, nil
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCompareTools 测试 compare_resource 和 compare_namespace 对比模拟集群与另一个集群
+func TestCompareTools(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.clusterManager.AddClientset("prod", fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}},
+				},
+			},
+		},
+	))
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callMockTool(t, session, "compare_resource", map[string]any{"resource_type": "deployment", "name": "web", "namespace": "shop", "cluster_a": "mock", "cluster_b": "prod"})
+	for _, want := range []string{`"exists_in_a":true`, `"exists_in_b":true`, `"identical":false`, "image of container web differs: nginx:1.25 in mock vs nginx:1.27 in prod", "+++ prod/shop/web"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in compare_resource result: %s", want, result)
+		}
+	}
+
+	result = callMockTool(t, session, "compare_resource", map[string]any{"resource_type": "configmaps", "name": "web-config", "namespace": "shop", "cluster_a": "mock", "cluster_b": "prod"})
+	if !strings.Contains(result, "configmaps shop/web-config only exists in mock, it is missing from prod") {
+		t.Errorf("expected missing object in summary: %s", result)
+	}
+
+	result = callMockTool(t, session, "compare_namespace", map[string]any{"namespace": "shop", "cluster_a": "mock", "cluster_b": "prod"})
+	for _, want := range []string{`{"different":["web"],"resource_type":"deployments"}`, `{"only_in_a":["web-config"],"resource_type":"configmaps"}`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in compare_namespace result: %s", want, result)
+		}
+	}
+}
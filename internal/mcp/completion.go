@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletionValues is the maximum number of values per completion/complete response
+// maxCompletionValues 每个 completion/complete 响应最多返回的值数量
+const maxCompletionValues = 100
+
+// handleComplete serves completion/complete for prompt arguments and resource template
+// variables. cluster_name (or cluster) completes to the loaded clusters and namespace
+// to the namespaces of the selected cluster; other arguments have no suggestions.
+// handleComplete 处理 prompt 参数和资源模板变量的 completion/complete 请求。
+// cluster_name（或 cluster）补全为已加载的集群，namespace 补全为所选集群的命名空间，其他参数没有建议。
+func (s *Server) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	params := req.Params
+
+	var candidates []string
+	switch params.Argument.Name {
+	case "cluster_name", "cluster":
+		candidates = s.clusterManager.GetClusters()
+	case "namespace":
+		candidates = s.namespaceCandidates(ctx, completionCluster(params.Context))
+	}
+
+	return &mcp.CompleteResult{Completion: filterCompletions(candidates, params.Argument.Value)}, nil
+}
+
+// completionCluster returns the cluster already chosen in the completion context, if any
+// completionCluster 返回补全上下文中已选择的集群（如果有）
+func completionCluster(completeCtx *mcp.CompleteContext) string {
+	if completeCtx == nil {
+		return ""
+	}
+	if cluster := completeCtx.Arguments["cluster_name"]; cluster != "" {
+		return cluster
+	}
+	return completeCtx.Arguments["cluster"]
+}
+
+// namespaceCandidates lists namespace names of a cluster; failures yield no suggestions
+// namespaceCandidates 列出集群的命名空间名称，失败时不返回建议
+func (s *Server) namespaceCandidates(ctx context.Context, clusterName string) []string {
+	namespaces, err := s.resourceOps.ListNamespaces(ctx, clusterName)
+	if err != nil {
+		s.logger.Debug("Namespace completion failed", "cluster", clusterName, "error", err)
+		return nil
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	return names
+}
+
+// filterCompletions keeps the candidates starting with prefix (case-insensitive), sorted
+// and capped at maxCompletionValues
+// filterCompletions 保留以 prefix 开头（不区分大小写）的候选值，排序并限制为 maxCompletionValues 个
+func filterCompletions(candidates []string, prefix string) mcp.CompletionResultDetails {
+	prefix = strings.ToLower(prefix)
+	values := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+	sort.Strings(values)
+
+	details := mcp.CompletionResultDetails{Total: len(values), Values: values}
+	if len(values) > maxCompletionValues {
+		details.Values = values[:maxCompletionValues]
+		details.HasMore = true
+	}
+	return details
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestFilterCompletions 测试前缀过滤、排序和数量上限
+func TestFilterCompletions(t *testing.T) {
+	got := filterCompletions([]string{"prod-us", "dev", "Prod-eu"}, "prod")
+	if !reflect.DeepEqual(got.Values, []string{"Prod-eu", "prod-us"}) || got.Total != 2 || got.HasMore {
+		t.Errorf("unexpected completion: %+v", got)
+	}
+
+	var many []string
+	for i := 0; i < maxCompletionValues+20; i++ {
+		many = append(many, fmt.Sprintf("ns-%03d", i))
+	}
+	got = filterCompletions(many, "")
+	if len(got.Values) != maxCompletionValues || !got.HasMore || got.Total != maxCompletionValues+20 {
+		t.Errorf("expected values capped at %d, got %d (total %d, hasMore %v)", maxCompletionValues, len(got.Values), got.Total, got.HasMore)
+	}
+
+	if got = filterCompletions(nil, "x"); got.Values == nil {
+		t.Errorf("values must be an empty list, not null")
+	}
+}
+
+// TestCompletePromptArguments 测试声明 completions 能力并补全集群名称
+func TestCompletePromptArguments(t *testing.T) {
+	s := newTestServer(t, "prod", "dev", "preview")
+	s.RegisterPrompts()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	if session.InitializeResult().Capabilities.Completions == nil {
+		t.Errorf("expected the completions capability to be advertised")
+	}
+
+	result, err := session.Complete(ctx, &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "generate_kubectl_commands"},
+		Argument: mcp.CompleteParamsArgument{Name: "cluster_name", Value: "pr"},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Completion.Values, []string{"preview", "prod"}) {
+		t.Errorf("unexpected cluster completion: %+v", result.Completion)
+	}
+
+	// Unreachable clusters yield no namespace suggestions instead of an error
+	// 集群不可达时命名空间补全返回空列表而不是错误
+	result, err = session.Complete(ctx, &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "generate_kubectl_commands"},
+		Argument: mcp.CompleteParamsArgument{Name: "namespace", Value: ""},
+		Context:  &mcp.CompleteContext{Arguments: map[string]string{"cluster_name": "prod"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(result.Completion.Values) != 0 {
+		t.Errorf("expected no namespace suggestions, got %v", result.Completion.Values)
+	}
+}
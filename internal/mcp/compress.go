@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// Compression settings of the HTTP transport
+// HTTP 传输的压缩设置
+const (
+	// minCompressBytes is the response size below which compression doesn't pay off
+	// minCompressBytes 是响应大小低于该值时不值得压缩
+	minCompressBytes = 1 << 10
+
+	// maxDecompressedRequestBytes bounds a gzip request body once inflated, so a small
+	// compressed body cannot expand without limit
+	// maxDecompressedRequestBytes 限制 gzip 请求体解压后的大小，避免很小的压缩数据无限膨胀
+	maxDecompressedRequestBytes = 32 << 20
+)
+
+// CompressionMiddleware adds gzip content coding to the HTTP transport:
+//   - request bodies sent with Content-Encoding: gzip are inflated before the next
+//     handler reads them; other codings are refused with 415
+//   - responses are gzipped when the client accepts gzip and the body reaches 1KB.
+//     Smaller bodies, including SSE streams flushed before reaching 1KB such as the
+//     standalone GET stream, are sent as is. Each flush of a compressed stream also
+//     flushes the gzip stream, so SSE events are still delivered one at a time.
+//
+// Every response varies on Accept-Encoding.
+// CompressionMiddleware 为 HTTP 传输添加 gzip 内容编码：
+//   - Content-Encoding 为 gzip 的请求体在下一个处理器读取之前解压；其他编码返回 415
+//   - 客户端接受 gzip 且响应体达到 1KB 时压缩响应。更小的响应体，包括在达到 1KB 之前就刷新的 SSE 流
+//     （例如独立的 GET 流）原样发送。压缩流每次刷新时同时刷新 gzip 流，因此 SSE 事件仍然逐个送达。
+//
+// 所有响应都按 Accept-Encoding 变化。
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			body, err := gzip.NewReader(r.Body)
+			if err != nil {
+				writeJSONRPCError(w, jsonrpc.CodeParseError, "Parse error: invalid gzip body")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, body, maxDecompressedRequestBytes)
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		default:
+			// RFC 7694: tell the client which request codings are supported
+			// RFC 7694：告知客户端支持的请求编码
+			w.Header().Set("Accept-Encoding", "gzip")
+			http.Error(w, "unsupported Content-Encoding "+encoding, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, honoring q=0
+// acceptsGzip 判断 Accept-Encoding 头是否允许 gzip，q=0 表示不接受
+func acceptsGzip(header string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		// An explicit gzip entry wins over the wildcard
+		// 显式的 gzip 条目优先于通配符
+		if coding != "*" {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
+
+// gzipResponseWriter holds back the first minCompressBytes of a response to decide
+// whether to compress it: a response that ends or is flushed before reaching that size
+// is sent as is
+// gzipResponseWriter 暂存响应的前 minCompressBytes 字节以决定是否压缩：在达到该大小之前结束或被刷新的响应原样发送
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	status    int
+	buf       []byte
+	committed bool
+	gz        *gzip.Writer
+}
+
+// WriteHeader records the status; it is sent once the encoding is decided
+// WriteHeader 记录状态码，确定编码后才发送
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.committed {
+		return
+	}
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write buffers the body until the encoding is decided, then writes through
+// Write 在确定编码之前缓存响应体，之后直接写入
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.committed {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) >= minCompressBytes {
+			if err := w.commit(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far. A response that has not reached
+// minCompressBytes yet is committed uncompressed, since the handler wants its data
+// delivered now.
+// Flush 发送目前已写入的内容。尚未达到 minCompressBytes 的响应以不压缩的方式提交，因为处理器希望数据立即送达。
+func (w *gzipResponseWriter) Flush() {
+	if !w.committed {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.commit(false); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+// Unwrap 使 http.ResponseController 能够访问底层的 writer
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// commit sends the header and the buffered body, gzipped when compress is set and the
+// response may be compressed
+// commit 发送响应头和缓存的响应体，compress 为 true 且响应可以压缩时进行 gzip 压缩
+func (w *gzipResponseWriter) commit(compress bool) error {
+	w.committed = true
+	h := w.ResponseWriter.Header()
+	if compress && h.Get("Content-Encoding") == "" && bodyAllowed(w.status) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends a response that never reached minCompressBytes and ends the gzip stream
+// close 发送未达到 minCompressBytes 的响应并结束 gzip 流
+func (w *gzipResponseWriter) close() {
+	if !w.committed && w.status != 0 {
+		_ = w.commit(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
+
+// bodyAllowed reports whether a response with status may carry a body
+// bodyAllowed 判断该状态码的响应是否可以携带响应体
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified
+}
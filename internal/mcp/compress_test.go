@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipBytes 返回 data 的 gzip 压缩结果
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip failed: %v", err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+// TestCompressionMiddlewareResponses 测试响应按 Accept-Encoding 和大小决定是否压缩，并始终设置 Vary 头
+func TestCompressionMiddlewareResponses(t *testing.T) {
+	large := strings.Repeat(`{"name":"pod","phase":"Running"}`, 100)
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("size") == "small" {
+			io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		// 分多次写入，跨过 1KB 阈值
+		io.WriteString(w, large[:500])
+		io.WriteString(w, large[500:])
+	}))
+
+	tests := []struct {
+		name           string
+		size           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{"large with gzip", "large", "gzip, deflate", true},
+		{"large with wildcard", "large", "*", true},
+		{"large without Accept-Encoding", "large", "", false},
+		{"large with gzip refused", "large", "gzip;q=0, *", false},
+		{"large with other codings only", "large", "br", false},
+		{"small with gzip", "small", "gzip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/?size="+tt.size, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+			}
+			if got := rec.Header().Get("Content-Encoding") == "gzip"; got != tt.wantGzip {
+				t.Fatalf("expected gzip %v, got Content-Encoding %q", tt.wantGzip, rec.Header().Get("Content-Encoding"))
+			}
+
+			body := rec.Body.String()
+			if tt.wantGzip {
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("invalid gzip response: %v", err)
+				}
+				data, _ := io.ReadAll(gz)
+				body = string(data)
+			}
+			want := large
+			if tt.size == "small" {
+				want = `{"ok":true}`
+			} else if rec.Code != http.StatusCreated {
+				t.Errorf("expected status 201, got %d", rec.Code)
+			}
+			if body != want {
+				t.Errorf("unexpected body %q", body)
+			}
+		})
+	}
+}
+
+// TestCompressionMiddlewareSSE 测试达到 1KB 之前就刷新的 SSE 流不压缩，而压缩的 SSE 流每次刷新后事件都可以立即解压
+func TestCompressionMiddlewareSSE(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for data := range events {
+			io.WriteString(w, "event: message\ndata: "+data+"\n\n")
+			w.(http.Flusher).Flush()
+		}
+	})))
+	defer server.Close()
+
+	get := func() (*http.Response, *bufio.Reader) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	// 第一个事件很小：整个流不压缩，事件立即送达。处理器写入第一个事件后才发送响应头
+	go func() { events <- "small" }()
+	resp, reader := get()
+	line, _ := reader.ReadString('\n')
+	if resp.Header.Get("Content-Encoding") != "" || line != "event: message\n" {
+		t.Errorf("expected an uncompressed stream, got %q with Content-Encoding %q", line, resp.Header.Get("Content-Encoding"))
+	}
+	close(events)
+	resp.Body.Close()
+
+	// 第一个事件超过 1KB：流被压缩，每个事件在刷新后即可解压，不需要等待流结束
+	events = make(chan string)
+	big := strings.Repeat("x", 2000)
+	go func() { events <- big }()
+	resp, _ = get()
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip stream, got Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip stream: %v", err)
+	}
+	reader = bufio.NewReader(gz)
+	for _, want := range []string{big, "second"} {
+		if want == "second" {
+			events <- want
+		}
+		reader.ReadString('\n')
+		line, err := reader.ReadString('\n')
+		if err != nil || line != "data: "+want+"\n" {
+			t.Fatalf("expected event %.10q, got %.10q: %v", want, line, err)
+		}
+		reader.ReadString('\n')
+	}
+	close(events)
+}
+
+// TestCompressionMiddlewareRequests 测试 gzip 请求体被解压，不支持的编码和无效的 gzip 数据被拒绝
+func TestCompressionMiddlewareRequests(t *testing.T) {
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("expected Content-Encoding to be removed, got %q", r.Header.Get("Content-Encoding"))
+		}
+		w.Write(body)
+	}))
+
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("gzip", gzipBytes(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"jsonrpc":"2.0","id":1,"method":"ping"}` {
+		t.Errorf("expected the inflated body, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = post("br", []byte("data"))
+	if rec.Code != http.StatusUnsupportedMediaType || rec.Header().Get("Accept-Encoding") != "gzip" {
+		t.Errorf("expected 415 with Accept-Encoding: gzip, got %d %q", rec.Code, rec.Header().Get("Accept-Encoding"))
+	}
+
+	rec = post("gzip", []byte("not gzip"))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid gzip body") {
+		t.Errorf("expected a parse error, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// 解压后超过上限
+	rec = post("gzip", gzipBytes(t, strings.Repeat(" ", maxDecompressedRequestBytes+1)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an oversized body to be refused, got %d", rec.Code)
+	}
+}
+
+// TestCompressionRoundTrip 测试通过 MCP HTTP 处理器发送压缩的请求并接收压缩的 tools/list 响应
+func TestCompressionRoundTrip(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.RegisterTools()
+	server := httptest.NewServer(s.CreateHTTPHandler())
+	defer server.Close()
+
+	send := func(sessionID, body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(gzipBytes(t, body)))
+		req.Header.Set("Authorization", "Bearer test-token")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Accept-Encoding", "gzip")
+		if sessionID != "" {
+			req.Header.Set(sessionIDHeader, sessionID)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := send("", initializeBody("2025-06-18"))
+	resp.Body.Close()
+	sessionID := resp.Header.Get(sessionIDHeader)
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize with a gzip body failed: %d", resp.StatusCode)
+	}
+	send(sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+
+	resp = send(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" || !strings.Contains(resp.Header.Get("Vary"), "Accept-Encoding") {
+		t.Fatalf("expected a gzip response varying on Accept-Encoding, got %q %q", resp.Header.Get("Content-Encoding"), resp.Header.Get("Vary"))
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip response: %v", err)
+	}
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data: ") {
+			if !strings.Contains(scanner.Text(), `"name":"get_pod_logs"`) {
+				t.Errorf("expected the tool list, got %.200s", scanner.Text())
+			}
+			return
+		}
+	}
+	t.Fatalf("no response event: %v", scanner.Err())
+}
@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmField is the boolean field of the confirmation form, and the tool argument
+// clients without elicitation support must set instead
+// confirmField 确认表单中的布尔字段，不支持 elicitation 的客户端需要改为设置同名工具参数
+const confirmField = "confirm"
+
+// confirmationSchema is the elicitation form asking for a single yes/no answer. The field
+// is not marked required because the SDK validates declined responses against it too.
+// confirmationSchema 只询问是/否的 elicitation 表单。字段未标记为必填，因为 SDK 也会用它校验拒绝的响应。
+var confirmationSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		confirmField: map[string]interface{}{
+			"type":        "boolean",
+			"title":       "Confirm",
+			"description": "Proceed with this action",
+		},
+	},
+}
+
+// confirmDestructive puts a human in the loop before a destructive tool runs. If the
+// client advertised elicitation support, the user is asked "Confirm <action>? (yes/no)"
+// through elicitation/create; otherwise the caller must have passed confirm=true.
+// A nil result means the tool may proceed; a non-nil result is the error result to return.
+// confirmDestructive 在执行破坏性工具前引入人工确认。如果客户端声明了 elicitation 能力，
+// 通过 elicitation/create 询问用户 "Confirm <action>? (yes/no)"；否则要求调用方传入 confirm=true。
+// 返回 nil 表示可以继续执行，非 nil 时返回该错误结果。
+func (s *Server) confirmDestructive(ctx context.Context, req *mcp.CallToolRequest, action string, confirmed bool) (*mcp.CallToolResult, error) {
+	language := s.sessionLanguage(req.Session)
+	if !supportsElicitation(req.Session) {
+		if confirmed {
+			return nil, nil
+		}
+		return toolError(localize(language, msgConfirmationRequired, action, confirmField)), nil
+	}
+
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         localize(language, msgConfirmQuestion, action),
+		RequestedSchema: confirmationSchema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request confirmation: %w", err)
+	}
+	if result.Action != "accept" || result.Content[confirmField] != true {
+		s.logger.Info("Destructive action cancelled by user", "action", action, "response", result.Action)
+		return toolError(localize(language, msgCancelledByUser)), nil
+	}
+	return nil, nil
+}
+
+// supportsElicitation reports whether the client advertised the elicitation capability
+// supportsElicitation 判断客户端是否声明了 elicitation 能力
+func supportsElicitation(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// toolError builds an IsError tool result with a text message
+// toolError 构造带文本消息的 IsError 工具结果
+func toolError(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: message}},
+	}
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// addDestructiveTestTool 注册一个在执行前要求确认的测试工具
+func addDestructiveTestTool(s *Server, ran *bool) {
+	mcp.AddTool(s.mcpServer, &mcp.Tool{Name: "delete_test"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Confirm bool `json:"confirm,omitempty"`
+	}) (*mcp.CallToolResult, struct{}, error) {
+		if result, err := s.confirmDestructive(ctx, req, "deletion of deployment payments in namespace prod on cluster prod", input.Confirm); result != nil || err != nil {
+			return result, struct{}{}, err
+		}
+		*ran = true
+		return nil, struct{}{}, nil
+	})
+}
+
+// resultText 返回工具结果中的文本内容
+func resultText(result *mcp.CallToolResult) string {
+	var sb strings.Builder
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+	return sb.String()
+}
+
+// TestConfirmDestructiveElicitation 测试客户端支持 elicitation 时根据用户回答决定是否执行
+func TestConfirmDestructiveElicitation(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *mcp.ElicitResult
+		wantRun bool
+	}{
+		{"accepted", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true}}, true},
+		{"answered no", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}}, false},
+		{"declined", &mcp.ElicitResult{Action: "decline"}, false},
+		{"cancelled", &mcp.ElicitResult{Action: "cancel"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			ran := false
+			addDestructiveTestTool(s, &ran)
+
+			var message string
+			session := connectTestClient(t, s, &mcp.ClientOptions{
+				ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					message = req.Params.Message
+					return tt.result, nil
+				},
+			})
+
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "delete_test", Arguments: map[string]any{}})
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if message != "Confirm deletion of deployment payments in namespace prod on cluster prod? (yes/no)" {
+				t.Errorf("unexpected elicitation message %q", message)
+			}
+			if ran != tt.wantRun || result.IsError == tt.wantRun {
+				t.Errorf("ran=%v isError=%v, want ran=%v", ran, result.IsError, tt.wantRun)
+			}
+			if !tt.wantRun && resultText(result) != "cancelled by user" {
+				t.Errorf("unexpected result text %q", resultText(result))
+			}
+		})
+	}
+}
+
+// TestConfirmDestructiveWithoutElicitation 测试客户端不支持 elicitation 时需要 confirm=true
+func TestConfirmDestructiveWithoutElicitation(t *testing.T) {
+	s := newTestServer(t)
+	ran := false
+	addDestructiveTestTool(s, &ran)
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_test", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if ran || !result.IsError || !strings.Contains(resultText(result), "confirm=true") {
+		t.Errorf("expected the call to be refused, ran=%v result=%q", ran, resultText(result))
+	}
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_test", Arguments: map[string]any{"confirm": true}})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !ran || result.IsError {
+		t.Errorf("expected confirm=true to proceed, ran=%v result=%q", ran, resultText(result))
+	}
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleCpFromPod handles cp_from_pod tool. The file is returned as a blob in an
+// embedded resource next to a one-line summary; max_bytes caps how much of it is read.
+// handleCpFromPod 处理 cp_from_pod 工具。文件作为嵌入资源中的 blob 与一行摘要一起返回，max_bytes 限制读取的大小。
+func (s *Server) handleCpFromPod(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Pod         string `json:"pod"`
+	Namespace   string `json:"namespace,omitempty"`
+	Container   string `json:"container,omitempty"`
+	RemotePath  string `json:"remote_path"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.PodFile,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	maxBytes := int64(k8s.DefaultCopyMaxBytes)
+	if limit, ok := requestedMaxBytes(ctx); ok {
+		maxBytes = int64(limit)
+	}
+
+	file, content, err := s.resourceOps.CopyFromPod(ctx, namespace, input.Pod, input.Container, input.RemotePath, maxBytes, clusterName)
+	if err != nil {
+		return nil, types.PodFile{}, err
+	}
+
+	summary := fmt.Sprintf("%s from pod %s/%s (container %s): %d bytes, %s", file.Path, file.Namespace, file.Pod, file.Container, file.Bytes, file.MimeType)
+	if file.Truncated {
+		summary += fmt.Sprintf(" — truncated, the file has %d bytes; pass max_bytes (up to %d) to read more", file.Size, MaxResultBytesCeiling)
+	}
+	if clusterName == "" {
+		clusterName = s.clusterManager.GetCurrentCluster()
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{
+				URI:      podFileURI(clusterName, file),
+				MIMEType: file.MimeType,
+				Blob:     content,
+			}},
+		},
+	}, *file, nil
+}
+
+// podFileURI identifies a file copied from a pod, e.g.
+// k8s://cluster/prod/namespace/shop/pod/web-0/file/var/log/app.log
+// podFileURI 标识从 Pod 中复制的文件，例如 k8s://cluster/prod/namespace/shop/pod/web-0/file/var/log/app.log
+func podFileURI(clusterName string, file *types.PodFile) string {
+	location := url.URL{Path: fmt.Sprintf("cluster/%s/namespace/%s/pod/%s/file/%s", clusterName, file.Namespace, file.Pod, strings.TrimPrefix(file.Path, "/"))}
+	return resourceURIScheme + location.EscapedPath()
+}
+
+// handleCpToPod handles cp_to_pod tool
+// handleCpToPod 处理 cp_to_pod 工具
+func (s *Server) handleCpToPod(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Pod         string `json:"pod"`
+	Namespace   string `json:"namespace,omitempty"`
+	Container   string `json:"container,omitempty"`
+	RemotePath  string `json:"remote_path"`
+	Content     string `json:"content"`
+	Confirm     bool   `json:"confirm,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.PodFileUpload,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	content, err := base64.StdEncoding.DecodeString(input.Content)
+	if err != nil {
+		return nil, types.PodFileUpload{}, fmt.Errorf("content must be base64-encoded: %w", err)
+	}
+
+	// Refuse paths outside the allowed directories before asking the user
+	// 在询问用户之前拒绝允许目录之外的路径
+	allowed := s.copyAllowedPaths
+	if allowed == nil {
+		allowed = k8s.DefaultCopyAllowedPaths
+	}
+	remotePath, err := k8s.ValidateCopyPath(input.RemotePath, allowed)
+	if err != nil {
+		return nil, types.PodFileUpload{}, err
+	}
+	action := fmt.Sprintf("write of %d bytes to %s in pod %s/%s, replacing any existing file", len(content), remotePath, namespace, input.Pod)
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+	if result, err := s.confirmDestructive(ctx, req, action, input.Confirm); result != nil || err != nil {
+		return result, types.PodFileUpload{}, err
+	}
+
+	upload, err := s.resourceOps.CopyToPod(ctx, namespace, input.Pod, input.Container, remotePath, content, allowed, clusterName)
+	if err != nil {
+		return nil, types.PodFileUpload{}, err
+	}
+	return nil, *upload, nil
+}
@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestCopyToolsRegistration 测试 cp_from_pod 需要 AllowExec，cp_to_pod 同时需要 AllowExec 和 AllowWrite
+func TestCopyToolsRegistration(t *testing.T) {
+	for _, tt := range []struct{ exec, write bool }{{false, false}, {false, true}, {true, false}, {true, true}} {
+		s := NewServer("test-token", &Options{AllowExec: tt.exec, AllowWrite: tt.write})
+		s.RegisterTools()
+		session := connectTestClient(t, s, nil)
+
+		result, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		registered := map[string]bool{}
+		for _, tool := range result.Tools {
+			registered[tool.Name] = true
+		}
+		if registered["cp_from_pod"] != tt.exec || registered["cp_to_pod"] != (tt.exec && tt.write) {
+			t.Errorf("AllowExec=%v AllowWrite=%v: cp_from_pod=%v cp_to_pod=%v", tt.exec, tt.write, registered["cp_from_pod"], registered["cp_to_pod"])
+		}
+	}
+}
+
+// TestCopyToolsRejectPaths 测试在询问确认和执行命令之前拒绝 ".." 路径、允许目录之外的目标以及非 base64 内容
+func TestCopyToolsRejectPaths(t *testing.T) {
+	s := NewServer("test-token", &Options{AllowExec: true, AllowWrite: true, CopyAllowedPaths: []string{"/srv/upload"}})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+	content := base64.StdEncoding.EncodeToString([]byte("hello"))
+
+	calls := []struct {
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"cp_to_pod", map[string]any{"pod": "web-0", "remote_path": "/tmp/hello.txt", "content": content}, "PathNotAllowed"},
+		{"cp_to_pod", map[string]any{"pod": "web-0", "remote_path": "/srv/upload/../../etc/passwd", "content": content}, "PathNotAllowed"},
+		{"cp_to_pod", map[string]any{"pod": "web-0", "remote_path": "/srv/upload/hello.txt", "content": "not base64!"}, "content must be base64-encoded"},
+		{"cp_from_pod", map[string]any{"pod": "web-0", "remote_path": "../../etc/shadow"}, "PathNotAllowed"},
+		{"cp_from_pod", map[string]any{"pod": "web-0", "remote_path": "/etc/hostname"}, "mock cluster cannot run commands"},
+	}
+	for _, call := range calls {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: call.tool, Arguments: call.args})
+		if err != nil || !result.IsError {
+			t.Fatalf("%s %v: expected an error, got %v %+v", call.tool, call.args, err, result)
+		}
+		data, _ := json.Marshal(result.StructuredContent)
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(string(data), call.want) && !strings.Contains(text, call.want) {
+			t.Errorf("%s %v: expected %q, got %s", call.tool, call.args, call.want, data)
+		}
+	}
+}
+
+// TestPodFileURI 测试复制文件的 URI 对路径中的特殊字符进行转义
+func TestPodFileURI(t *testing.T) {
+	uri := podFileURI("prod", &types.PodFile{Namespace: "shop", Pod: "web-0", Path: "/var/log/my app.log"})
+	if uri != "k8s://cluster/prod/namespace/shop/pod/web-0/file/var/log/my%20app.log" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+}
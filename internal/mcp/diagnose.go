@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleExplainPodFailure handles explain_pod_failure tool
+// handleExplainPodFailure 处理 explain_pod_failure 工具
+func (s *Server) handleExplainPodFailure(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	PodName     string `json:"pod_name"`
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.PodFailureExplanation,
+	error,
+) {
+	if input.PodName == "" {
+		return toolError("pod_name is required"), types.PodFailureExplanation{}, nil
+	}
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	if namespace == "" {
+		return toolError("explain_pod_failure needs the pod's namespace: pass namespace, one of " + allowedNamespacesScope(s.clusterManager.NamespacePolicy())), types.PodFailureExplanation{}, nil
+	}
+
+	explanation, err := s.resourceOps.ExplainPodFailure(ctx, namespace, input.PodName, clusterName)
+	if err != nil {
+		return nil, types.PodFailureExplanation{}, fmt.Errorf("failed to explain pod failure: %w", err)
+	}
+	return nil, *explanation, nil
+}
@@ -0,0 +1,35 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestExplainPodFailure 测试 explain_pod_failure 对模拟集群中处于 CrashLoopBackOff 的 Pod 给出结论、事件和上一个实例的日志
+func TestExplainPodFailure(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "explain_pod_failure", map[string]any{"pod_name": "worker-5f6d7c9b4-xk2lp", "namespace": "shop"})
+	var explanation types.PodFailureExplanation
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &explanation); err != nil {
+		t.Fatalf("failed to decode explanation: %v", err)
+	}
+	if explanation.Status != "CrashLoopBackOff" || len(explanation.Events) != 1 || explanation.Containers[0].PreviousLogs == "" {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+	if len(explanation.Findings) != 1 || explanation.Findings[0].Rule != "crash-exit" {
+		t.Errorf("unexpected findings %+v", explanation.Findings)
+	}
+	if !strings.Contains(explanation.Text, "Likely cause: The application keeps exiting") {
+		t.Errorf("unexpected text:\n%s", explanation.Text)
+	}
+}
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RefreshDiscoveryResult represents the result of refresh_discovery tool
+// RefreshDiscoveryResult 表示 refresh_discovery 工具的结果
+type RefreshDiscoveryResult struct {
+	Cluster string `json:"cluster"`
+	Message string `json:"message"`
+}
+
+// handleRefreshDiscovery handles refresh_discovery tool
+// handleRefreshDiscovery 处理 refresh_discovery 工具
+func (s *Server) handleRefreshDiscovery(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	RefreshDiscoveryResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	if err := s.clusterManager.InvalidateDiscovery(clusterName); err != nil {
+		return nil, RefreshDiscoveryResult{}, err
+	}
+	return nil, RefreshDiscoveryResult{
+		Cluster: clusterName,
+		Message: fmt.Sprintf("Cached discovery information of cluster %s was dropped and will be fetched again on next use", clusterName),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestRefreshDiscovery 测试 refresh_discovery 使发现缓存失效，get_server_info 报告获取发现信息的次数
+func TestRefreshDiscovery(t *testing.T) {
+	s := NewServer("test-token", nil)
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}}},
+	}
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	resolve := func() {
+		t.Helper()
+		for i := 0; i < 2; i++ {
+			if _, err := s.clusterManager.RESTMapping("test", deployment); err != nil {
+				t.Fatalf("RESTMapping failed: %v", err)
+			}
+		}
+	}
+	resolve()
+	refreshed, _ := callQueryTool[RefreshDiscoveryResult](t, session, "refresh_discovery", nil)
+	if refreshed.Cluster != "test" || !strings.Contains(refreshed.Message, "fetched again") {
+		t.Errorf("unexpected result %+v", refreshed)
+	}
+	resolve()
+
+	out, _ := callQueryTool[ServerInfoResult](t, session, "get_server_info", nil)
+	var info ServerInfo
+	if err := json.Unmarshal([]byte(out.Info), &info); err != nil {
+		t.Fatalf("invalid server info %q: %v", out.Info, err)
+	}
+	if info.DiscoveryFetches != 2 {
+		t.Errorf("expected 2 discovery fetches, got %d", info.DiscoveryFetches)
+	}
+
+	if result, text := callWithArguments(t, session, "refresh_discovery", map[string]any{"cluster_name": "missing"}); !result.IsError || !strings.Contains(text, "missing") {
+		t.Errorf("expected an unknown cluster error, got %q", text)
+	}
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// drain_node timeouts for evictions refused by a PodDisruptionBudget
+// drain_node 中被 PodDisruptionBudget 拒绝的驱逐的超时时间
+const (
+	defaultDrainTimeout = 60 * time.Second
+	maxDrainTimeout     = 600 * time.Second
+)
+
+// nodeAction describes an action on a node for the confirmation prompt
+// nodeAction 为确认提示描述对节点的操作
+func nodeAction(verb, nodeName, clusterName string) string {
+	action := fmt.Sprintf("%s of node %s", verb, nodeName)
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+	return action
+}
+
+// handleCordonNode handles cordon_node tool
+// handleCordonNode 处理 cordon_node 工具
+func (s *Server) handleCordonNode(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	NodeName    string `json:"node_name"`
+	Confirm     bool   `json:"confirm,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.NodeSchedulingResult,
+	error,
+) {
+	return s.setNodeSchedulable(ctx, req, input.NodeName, true, input.Confirm, input.ClusterName)
+}
+
+// handleUncordonNode handles uncordon_node tool
+// handleUncordonNode 处理 uncordon_node 工具
+func (s *Server) handleUncordonNode(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	NodeName    string `json:"node_name"`
+	Confirm     bool   `json:"confirm,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.NodeSchedulingResult,
+	error,
+) {
+	return s.setNodeSchedulable(ctx, req, input.NodeName, false, input.Confirm, input.ClusterName)
+}
+
+// setNodeSchedulable confirms and applies cordon_node/uncordon_node
+// setNodeSchedulable 确认并执行 cordon_node/uncordon_node
+func (s *Server) setNodeSchedulable(ctx context.Context, req *mcp.CallToolRequest, nodeName string, unschedulable, confirm bool, clusterName string) (
+	*mcp.CallToolResult,
+	types.NodeSchedulingResult,
+	error,
+) {
+	clusterName = s.resolveClusterName(ctx, clusterName)
+
+	verb := "uncordon"
+	if unschedulable {
+		verb = "cordon"
+	}
+	if result, err := s.confirmDestructive(ctx, req, nodeAction(verb, nodeName, clusterName), confirm); result != nil || err != nil {
+		return result, types.NodeSchedulingResult{}, err
+	}
+
+	result, err := s.resourceOps.CordonNode(ctx, nodeName, unschedulable, clusterName)
+	if err != nil {
+		return nil, types.NodeSchedulingResult{}, fmt.Errorf("failed to %s node: %w", verb, err)
+	}
+	return nil, *result, nil
+}
+
+// handleDrainNode handles drain_node tool
+// handleDrainNode 处理 drain_node 工具
+func (s *Server) handleDrainNode(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	NodeName           string `json:"node_name"`
+	IgnoreDaemonSets   *bool  `json:"ignore_daemonsets,omitempty"`
+	DeleteEmptyDirData bool   `json:"delete_emptydir_data,omitempty"`
+	GracePeriodSeconds *int64 `json:"grace_period_seconds,omitempty"`
+	TimeoutSeconds     int    `json:"timeout_seconds,omitempty"`
+	Confirm            bool   `json:"confirm,omitempty"`
+	ClusterName        string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.DrainResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	opts := k8s.DrainOptions{
+		IgnoreDaemonSets:   true,
+		DeleteEmptyDirData: input.DeleteEmptyDirData,
+		GracePeriodSeconds: input.GracePeriodSeconds,
+		Timeout:            defaultDrainTimeout,
+	}
+	if input.IgnoreDaemonSets != nil {
+		opts.IgnoreDaemonSets = *input.IgnoreDaemonSets
+	}
+	if input.TimeoutSeconds > 0 {
+		opts.Timeout = time.Duration(input.TimeoutSeconds) * time.Second
+	}
+	if opts.Timeout > maxDrainTimeout {
+		opts.Timeout = maxDrainTimeout
+	}
+
+	if result, err := s.confirmDestructive(ctx, req, nodeAction("drain", input.NodeName, clusterName), input.Confirm); result != nil || err != nil {
+		return result, types.DrainResult{}, err
+	}
+
+	result, err := s.resourceOps.DrainNode(ctx, input.NodeName, opts, clusterName)
+	if err != nil {
+		return nil, types.DrainResult{}, fmt.Errorf("failed to drain node: %w", err)
+	}
+	return nil, *result, nil
+}
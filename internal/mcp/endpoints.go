@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleGetServiceEndpoints handles get_service_endpoints tool
+// handleGetServiceEndpoints 处理 get_service_endpoints 工具
+func (s *Server) handleGetServiceEndpoints(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ServiceEndpoints,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	endpoints, err := s.resourceOps.GetServiceEndpoints(ctx, namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, types.ServiceEndpoints{}, fmt.Errorf("failed to get service endpoints: %w", err)
+	}
+	return nil, *endpoints, nil
+}
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestGetServiceEndpoints 测试 get_service_endpoints 使用会话的默认命名空间，返回选择器匹配的 Pod 和文本报告
+func TestGetServiceEndpoints(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	callTool(t, session, "set_namespace", map[string]any{"namespace": "shop"})
+	result := callTool(t, session, "get_service_endpoints", map[string]any{"name": "web"})
+
+	var endpoints types.ServiceEndpoints
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		t.Fatalf("failed to decode endpoints: %v", err)
+	}
+	if endpoints.Namespace != "shop" || endpoints.Service != "web" || len(endpoints.Pods) == 0 || len(endpoints.Ports) != 1 {
+		t.Fatalf("unexpected endpoints %+v", endpoints)
+	}
+	if !strings.HasPrefix(endpoints.Text, "Service shop/web (ClusterIP 10.96.12.34), selector app=web\n") {
+		t.Errorf("unexpected text:\n%s", endpoints.Text)
+	}
+}
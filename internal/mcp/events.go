@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// stream_events durations; the cap keeps a tool call from holding a watch open for long
+// stream_events 的监听时长，上限避免单次工具调用长时间占用监听
+const (
+	defaultStreamDuration = 30 * time.Second
+	maxStreamDuration     = 120 * time.Second
+)
+
+// handleStreamEvents handles stream_events tool
+// handleStreamEvents 处理 stream_events 工具
+func (s *Server) handleStreamEvents(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace       string `json:"namespace,omitempty"`
+	Kind            string `json:"kind,omitempty"`
+	Name            string `json:"name,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	ClusterName     string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.EventStream,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	if namespace == "" {
+		return toolError("stream_events watches a single namespace: pass namespace, one of " + allowedNamespacesScope(s.clusterManager.NamespacePolicy())), types.EventStream{}, nil
+	}
+
+	duration := defaultStreamDuration
+	if input.DurationSeconds > 0 {
+		duration = time.Duration(input.DurationSeconds) * time.Second
+	}
+	if duration > maxStreamDuration {
+		duration = maxStreamDuration
+	}
+
+	stream, err := s.resourceOps.StreamEvents(ctx, namespace, k8s.EventFilter{Kind: input.Kind, Name: input.Name}, duration, clusterName, s.eventProgress(ctx, req))
+	if err != nil {
+		return nil, types.EventStream{}, fmt.Errorf("failed to stream events: %w", err)
+	}
+	return nil, *stream, nil
+}
+
+// eventProgress returns a callback that forwards each streamed event as a progress
+// notification carrying the event as JSON, or nil when the client sent no progress token.
+// The final result always holds every event, so clients that ignore progress lose nothing.
+// eventProgress 返回将每个事件以 JSON 形式作为进度通知转发的回调；客户端未提供 progress token 时返回 nil。
+// 最终结果总是包含所有事件，忽略进度通知的客户端不会丢失数据。
+func (s *Server) eventProgress(ctx context.Context, req *mcp.CallToolRequest) func(types.StreamedEvent) {
+	token := req.Params.GetProgressToken()
+	if token == nil || req.Session == nil {
+		return nil
+	}
+
+	var received int
+	return func(event types.StreamedEvent) {
+		received++
+		message, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      float64(received),
+			Message:       string(message),
+		}); err != nil {
+			s.logger.Debug("Failed to send event progress notification", "error", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// fakeEventWatchServer 模拟 default 命名空间的事件监听：依次发送 BackOff 和 Unhealthy 事件后保持连接
+func fakeEventWatchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			json.NewEncoder(w).Encode(corev1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: "100"}})
+			return
+		}
+		flusher := w.(http.Flusher)
+		for i, reason := range []string{"BackOff", "Unhealthy"} {
+			json.NewEncoder(w).Encode(map[string]interface{}{"type": "ADDED", "object": &corev1.Event{
+				TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+				ObjectMeta:     metav1.ObjectMeta{Name: reason, Namespace: "default", ResourceVersion: strconv.Itoa(101 + i)},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+				Type:           corev1.EventTypeWarning,
+				Reason:         reason,
+			}})
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestStreamEventsProgress 测试通过 streamable HTTP 传输时每个事件以进度通知发送，最终结果包含全部事件
+func TestStreamEventsProgress(t *testing.T) {
+	apiServer := fakeEventWatchServer(t)
+	s := NewServer("test-token", nil)
+	if err := s.clusterManager.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	var mu sync.Mutex
+	var progress []types.StreamedEvent
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			var event types.StreamedEvent
+			if err := json.Unmarshal([]byte(req.Params.Message), &event); err != nil {
+				t.Errorf("progress message is not an event: %q", req.Params.Message)
+			}
+			mu.Lock()
+			progress = append(progress, event)
+			mu.Unlock()
+		},
+	})
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: headerTransport{token: "test-token"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer session.Close()
+
+	// SetProgressToken 在 Meta 为空时不会生效，因此直接设置 Meta
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "events-1"},
+		Name:      "stream_events",
+		Arguments: map[string]any{"namespace": "default", "duration_seconds": 1},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("stream_events failed: %v %+v", err, result)
+	}
+
+	var stream types.EventStream
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &stream)
+	if len(stream.Events) != 2 || stream.Events[0].Reason != "BackOff" || stream.Events[1].Reason != "Unhealthy" {
+		t.Fatalf("unexpected result events: %+v", stream.Events)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 2 || progress[0].Reason != "BackOff" || progress[1].Reason != "Unhealthy" {
+		t.Errorf("unexpected progress notifications: %+v", progress)
+	}
+}
+
+// TestGetEventsWindow 测试 get_events 回显解析后的绝对时间段，以及 until 早于 since 时返回清晰的错误
+func TestGetEventsWindow(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "get_events", map[string]any{"namespace": "shop", "since": "2h", "until": "now"})
+	var events EventsResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatalf("failed to decode events: %v", err)
+	}
+	if !strings.Contains(events.Window, " (2h ago) to ") || !strings.HasSuffix(events.Window, "Z (now)") {
+		t.Errorf("unexpected window %q", events.Window)
+	}
+
+	bad, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_events", Arguments: map[string]any{"since": "1h", "until": "2h"}})
+	if err != nil {
+		t.Fatalf("get_events failed: %v", err)
+	}
+	if text := bad.Content[0].(*mcp.TextContent).Text; !bad.IsError || !strings.HasPrefix(text, "until (") || !strings.Contains(text, ") is before since (") {
+		t.Errorf("expected the until before since error, got %+v", bad.Content[0])
+	}
+}
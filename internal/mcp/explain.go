@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleExplainResource handles explain_resource tool
+// handleExplainResource 处理 explain_resource 工具
+func (s *Server) handleExplainResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType string `json:"resource_type"`
+	FieldPath    string `json:"field_path,omitempty"`
+	ClusterName  string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ExplainResult,
+	error,
+) {
+	if input.ResourceType == "" {
+		return toolError("resource_type is required"), types.ExplainResult{}, nil
+	}
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	result, err := s.resourceOps.ExplainResource(ctx, input.ResourceType, input.FieldPath, clusterName)
+	var unknown *k8s.UnknownFieldError
+	if errors.As(err, &unknown) {
+		return toolError(unknownFieldMessage(unknown)), types.ExplainResult{}, nil
+	}
+	if err != nil {
+		return nil, types.ExplainResult{}, fmt.Errorf("failed to explain %s: %w", input.ResourceType, err)
+	}
+	return nil, *result, nil
+}
+
+// unknownFieldMessage points from a field that does not exist to the nearest valid parent
+// path and its fields, e.g. `field "replica" does not exist in deployments.spec (did you
+// mean "replicas"?); fields of deployments.spec: minReadySeconds <integer>, ...`
+// unknownFieldMessage 说明字段不存在，并给出最近的有效父路径及其子字段，附带拼写建议
+func unknownFieldMessage(err *k8s.UnknownFieldError) string {
+	path := k8s.ExplainPath(err.Parent)
+	names := make([]string, 0, len(err.Parent.Fields))
+	fields := make([]string, 0, len(err.Parent.Fields))
+	for _, field := range err.Parent.Fields {
+		names = append(names, field.Name)
+		fields = append(fields, fmt.Sprintf("%s <%s>", field.Name, field.Type))
+	}
+	message := err.Error()
+	if suggestion := suggestName(err.Field, names); suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	if len(fields) == 0 {
+		return fmt.Sprintf("%s; %s is of type %s and has no fields", message, path, err.Parent.Type)
+	}
+	return fmt.Sprintf("%s; fields of %s: %s", message, path, strings.Join(fields, ", "))
+}
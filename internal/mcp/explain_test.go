@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+// crontabOpenAPI 是示例 CRD CronTab 的 OpenAPI v3 文档
+const crontabOpenAPI = `{"components": {"schemas": {"com.example.stable.v1.CronTab": {
+	"type": "object",
+	"x-kubernetes-group-version-kind": [{"group": "stable.example.com", "version": "v1", "kind": "CronTab"}],
+	"properties": {"spec": {"type": "object", "required": ["cronSpec"], "properties": {
+		"cronSpec": {"type": "string", "description": "Schedule in cron format. Example: */5 * * * *"},
+		"replicas": {"type": "integer"}
+	}}}
+}}}}`
+
+// TestExplainResourceTool 测试 explain_resource 返回字段的类型和子字段，未知字段返回父路径的子字段和拼写建议
+func TestExplainResourceTool(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "stable.example.com/v1", APIResources: []metav1.APIResource{{Name: "crontabs", SingularName: "crontab", Kind: "CronTab"}}},
+	}
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", client)
+	s.clusterManager.SetOpenAPIClient("test", &openapitest.FakeClient{PathsMap: map[string]openapi.GroupVersion{
+		"apis/stable.example.com/v1": openapitest.FakeGroupVersion{GVSpec: []byte(crontabOpenAPI)},
+	}})
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, _ := callQueryTool[types.ExplainResult](t, session, "explain_resource", map[string]any{"resource_type": "crontabs", "field_path": "spec"})
+	want := []types.ExplainField{
+		{Name: "cronSpec", Type: "string", Required: true, Description: "Schedule in cron format."},
+		{Name: "replicas", Type: "integer"},
+	}
+	if result.Kind != "CronTab" || result.APIVersion != "stable.example.com/v1" || result.Type != "Object" || len(result.Fields) != len(want) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	for i := range want {
+		if result.Fields[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], result.Fields[i])
+		}
+	}
+
+	callResult, text := callWithArguments(t, session, "explain_resource", map[string]any{"resource_type": "crontab", "field_path": "spec.replica"})
+	wantText := `field "replica" does not exist in crontabs.spec (did you mean "replicas"?); fields of crontabs.spec: cronSpec <string>, replicas <integer>`
+	if !callResult.IsError || text != wantText {
+		t.Errorf("expected %q, got %q", wantText, text)
+	}
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// allClustersWildcard selects every registered cluster when passed as cluster_name
+	// allClustersWildcard 作为 cluster_name 传入时表示选择所有已注册的集群
+	allClustersWildcard = "*"
+
+	// defaultFanOutConcurrency is the maximum number of clusters queried at the same time
+	// defaultFanOutConcurrency 同时查询的最大集群数量
+	defaultFanOutConcurrency = 4
+
+	// defaultFanOutTimeout is the overall deadline for a fan-out call
+	// defaultFanOutTimeout 跨集群调用的总超时时间
+	defaultFanOutTimeout = 30 * time.Second
+)
+
+// clusterResult holds the outcome of a tool call against one cluster
+// clusterResult 保存针对单个集群的调用结果
+type clusterResult struct {
+	Cluster string
+	Output  string
+	Err     error
+}
+
+// isAllClusters reports whether a call should fan out across all clusters
+// isAllClusters 判断调用是否需要分发到所有集群
+func isAllClusters(allClusters bool, clusterName string) bool {
+	return allClusters || clusterName == allClustersWildcard
+}
+
+// fanOutClusters runs fn against every registered cluster with bounded concurrency.
+// Results are returned in cluster name order. A cluster that fails or does not
+// finish before the overall deadline contributes an error instead of failing the call.
+// fanOutClusters 以有限并发对每个已注册集群执行 fn，结果按集群名称排序返回。
+// 失败或在总超时前未完成的集群会记录错误，而不会导致整个调用失败。
+func (s *Server) fanOutClusters(ctx context.Context, fn func(ctx context.Context, clusterName string) (string, error)) []clusterResult {
+	clusters := s.clusterManager.GetClusters()
+	sort.Strings(clusters)
+
+	ctx, cancel := context.WithTimeout(ctx, s.fanOutTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		results  = make([]clusterResult, len(clusters))
+		finished = make([]bool, len(clusters))
+		sem      = make(chan struct{}, s.fanOutConcurrency)
+	)
+
+	for i, name := range clusters {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			// Wait for a free slot, giving up if the deadline passes first
+			// 等待空闲槽位，如果先到达超时则放弃
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			output, err := fn(ctx, name)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i] = clusterResult{Cluster: name, Output: output, Err: err}
+			finished[i] = true
+		}(i, name)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Don't let one unreachable cluster stall the response
+	// 不让单个不可达集群阻塞整个响应
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	collected := make([]clusterResult, len(clusters))
+	for i, name := range clusters {
+		if finished[i] {
+			collected[i] = results[i]
+		} else {
+			collected[i] = clusterResult{Cluster: name, Err: fmt.Errorf("no response before deadline: %w", ctx.Err())}
+		}
+	}
+	return collected
+}
+
+// formatClusterResults renders per-cluster results grouped under cluster headers in language
+// formatClusterResults 按集群分组并使用指定语言渲染结果
+func formatClusterResults(language string, results []clusterResult) string {
+	if len(results) == 0 {
+		return localize(language, msgNoClustersAvailable)
+	}
+
+	var sb strings.Builder
+	for i, result := range results {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(localize(language, msgClusterHeader, result.Cluster) + "\n")
+		if result.Err != nil {
+			sb.WriteString(localize(language, msgClusterError, result.Err))
+			continue
+		}
+		sb.WriteString(result.Output)
+	}
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFanOutClustersPartialFailure 测试单个集群出错或超时不会影响其他集群的结果
+func TestFanOutClustersPartialFailure(t *testing.T) {
+	s := newTestServer(t, "alpha", "beta", "gamma")
+	s.fanOutTimeout = 200 * time.Millisecond
+
+	results := s.fanOutClusters(context.Background(), func(ctx context.Context, clusterName string) (string, error) {
+		switch clusterName {
+		case "beta":
+			return "", errors.New("connection refused")
+		case "gamma":
+			// Simulate an unreachable cluster that only gives up at the deadline
+			// 模拟一个直到超时才放弃的不可达集群
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "ok from " + clusterName, nil
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Cluster != "alpha" || results[0].Err != nil || results[0].Output != "ok from alpha" {
+		t.Errorf("unexpected alpha result: %+v", results[0])
+	}
+	if results[1].Cluster != "beta" || results[1].Err == nil {
+		t.Errorf("expected beta to report an error: %+v", results[1])
+	}
+	if results[2].Cluster != "gamma" || !errors.Is(results[2].Err, context.DeadlineExceeded) {
+		t.Errorf("expected gamma to hit the deadline: %+v", results[2])
+	}
+
+	text := formatClusterResults("", results)
+	for _, want := range []string{"=== Cluster: alpha ===\nok from alpha", "=== Cluster: beta ===\nError: connection refused", "=== Cluster: gamma ==="} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
+
+// TestFanOutClustersBoundedConcurrency 测试并发数不超过限制
+func TestFanOutClustersBoundedConcurrency(t *testing.T) {
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = fmt.Sprintf("cluster-%02d", i)
+	}
+	s := newTestServer(t, names...)
+	s.fanOutConcurrency = 2
+
+	var running, maxRunning int32
+	results := s.fanOutClusters(context.Background(), func(ctx context.Context, clusterName string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&maxRunning)
+			if n <= old || atomic.CompareAndSwapInt32(&maxRunning, old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return clusterName, nil
+	})
+
+	if len(results) != len(names) {
+		t.Fatalf("expected %d results, got %d", len(names), len(results))
+	}
+	for i, result := range results {
+		if result.Cluster != names[i] || result.Err != nil {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", maxRunning)
+	}
+}
+
+// TestIsAllClusters 测试 all_clusters 和通配符判断
+func TestIsAllClusters(t *testing.T) {
+	if !isAllClusters(true, "") || !isAllClusters(false, "*") {
+		t.Errorf("expected all-clusters selection")
+	}
+	if isAllClusters(false, "prod") || isAllClusters(false, "") {
+		t.Errorf("expected single-cluster selection")
+	}
+}
@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// skippedNamespacesMiddleware appends a note to the result of a tools/call whose
+// all-namespaces lists skipped namespaces the server is forbidden to list, so a partial
+// result is not mistaken for a complete one
+// skippedNamespacesMiddleware 在 tools/call 的全命名空间列表跳过了服务器无权列出的命名空间时，
+// 向结果追加说明，避免部分结果被误认为完整结果
+func (s *Server) skippedNamespacesMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+		ctx, skipped := k8s.WithSkippedNamespaces(ctx)
+		result, err := next(ctx, method, req)
+		callResult, ok := result.(*mcp.CallToolResult)
+		if !ok || err != nil || callResult.IsError {
+			return result, err
+		}
+		if names := skipped.Names(); len(names) > 0 {
+			s.logger.Info("Skipped forbidden namespaces", "count", len(names), "namespaces", names)
+			callResult.Content = append(callResult.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("skipped %d forbidden namespaces: %s", len(names), strings.Join(names, ", ")),
+			})
+		}
+		return result, err
+	}
+}
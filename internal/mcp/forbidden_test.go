@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestSkippedNamespacesNote 测试全命名空间列表跳过被禁止的命名空间时，结果包含部分数据和跳过说明
+func TestSkippedNamespacesNote(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vault"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "vault-0", Namespace: "vault"}},
+	)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if ns := action.GetNamespace(); ns == "" || ns == "vault" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC denied"))
+		}
+		return false, nil, nil
+	})
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_pods", Arguments: map[string]any{"all_namespaces": true}})
+	if err != nil || result.IsError {
+		t.Fatalf("expected a partial result, got %+v %v", result, err)
+	}
+	var texts []string
+	for _, content := range result.Content {
+		texts = append(texts, content.(*mcp.TextContent).Text)
+	}
+	text := strings.Join(texts, "\n")
+	if !strings.Contains(text, `\"name\":\"web\"`) || strings.Contains(text, "vault-0") {
+		t.Errorf("expected the pods of shop only, got %s", text)
+	}
+	if note := texts[len(texts)-1]; note != "skipped 1 forbidden namespaces: vault" {
+		t.Errorf("unexpected note %q", note)
+	}
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleListHelmReleases handles list_helm_releases tool
+// handleListHelmReleases 处理 list_helm_releases 工具
+func (s *Server) handleListHelmReleases(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.HelmReleasesResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	releases, err := s.resourceOps.ListHelmReleases(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, types.HelmReleasesResult{Releases: []types.HelmRelease{}}, fmt.Errorf("failed to list helm releases: %w", err)
+	}
+	return nil, types.HelmReleasesResult{Releases: releases, Scope: scope}, nil
+}
+
+// handleGetHelmRelease handles get_helm_release tool. Secret-looking keys of the computed
+// values are redacted like the arguments in the audit log.
+// handleGetHelmRelease 处理 get_helm_release 工具。计算值中疑似敏感的键与审计日志中的参数一样被脱敏
+func (s *Server) handleGetHelmRelease(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Revision    int    `json:"revision,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.HelmReleaseDetails,
+	error,
+) {
+	empty := types.HelmReleaseDetails{Resources: []types.HelmResource{}}
+	if input.Name == "" {
+		return toolError("name is required"), empty, nil
+	}
+	if input.Revision < 0 {
+		return toolError(fmt.Sprintf("revision must be positive, got %d", input.Revision)), empty, nil
+	}
+
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+
+	release, err := s.resourceOps.GetHelmRelease(ctx, namespace, input.Name, input.Revision, clusterName)
+	if err != nil {
+		return nil, empty, fmt.Errorf("failed to get helm release: %w", err)
+	}
+	release.Values = redactArguments(release.Values)
+	return nil, *release, nil
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestGetHelmReleaseRedactsValues 测试 get_helm_release 返回的计算值中敏感键被脱敏
+func TestGetHelmReleaseRedactsValues(t *testing.T) {
+	record, _ := json.Marshal(map[string]interface{}{
+		"name":      "web",
+		"namespace": "shop",
+		"version":   1,
+		"info":      map[string]interface{}{"status": "deployed"},
+		"chart": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "version": "1.0.0"},
+			"values":   map[string]interface{}{"auth": map[string]interface{}{"user": "admin", "password": ""}},
+		},
+		"config": map[string]interface{}{"auth": map[string]interface{}{"password": "hunter2"}},
+	})
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(record)
+	w.Close()
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.web.v1",
+			Namespace: "shop",
+			Labels:    map[string]string{"owner": "helm", "name": "web", "status": "deployed", "version": "1"},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))},
+	})
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_helm_release", Arguments: map[string]any{"name": "web", "namespace": "shop"}})
+	if err != nil || result.IsError {
+		t.Fatalf("get_helm_release failed: %+v %v", result, err)
+	}
+	data, _ := json.Marshal(result.StructuredContent)
+	var release types.HelmReleaseDetails
+	if err := json.Unmarshal(data, &release); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	auth, _ := release.Values["auth"].(map[string]interface{})
+	if auth["password"] != auditRedacted || auth["user"] != "admin" {
+		t.Errorf("expected the password to be redacted, got %v", release.Values)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_helm_release", Arguments: map[string]any{"name": "", "namespace": "shop"}})
+	if err != nil || !result.IsError {
+		t.Errorf("expected a tool error for an empty name, got %+v %v", result, err)
+	}
+}
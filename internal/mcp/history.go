@@ -0,0 +1,256 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultHistorySize is the number of recent tool calls kept by default
+// DefaultHistorySize 默认保留的最近工具调用数量
+const DefaultHistorySize = 200
+
+// historyToolName is the tool that reads the history; its own calls are not recorded
+// historyToolName 读取调用历史的工具，其自身的调用不会被记录
+const historyToolName = "get_call_history"
+
+// historyMaxArgumentLength is the length above which string arguments, such as
+// manifests or file contents, are shortened in the history
+// historyMaxArgumentLength 字符串参数 (例如清单或文件内容) 超过该长度时在历史中被截短
+const historyMaxArgumentLength = 200
+
+// defaultHistoryLimit is the number of calls get_call_history returns by default
+// defaultHistoryLimit get_call_history 默认返回的调用数量
+const defaultHistoryLimit = 50
+
+// CallRecord is one tool call in the call history
+// CallRecord 是调用历史中的一次工具调用
+type CallRecord struct {
+	Time       time.Time              `json:"time"`
+	Caller     string                 `json:"caller"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Cluster    string                 `json:"cluster,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// callHistory is a fixed-size ring buffer of the most recent tool calls
+// callHistory 是保存最近工具调用的固定大小环形缓冲区
+type callHistory struct {
+	mu      sync.Mutex
+	records []CallRecord
+	next    int
+	full    bool
+}
+
+// newCallHistory creates a history keeping the last size calls
+// newCallHistory 创建保留最近 size 次调用的历史
+func newCallHistory(size int) *callHistory {
+	return &callHistory{records: make([]CallRecord, size)}
+}
+
+// add records a call, overwriting the oldest once the buffer is full
+// add 记录一次调用，缓冲区满后覆盖最早的记录
+func (h *callHistory) add(record CallRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the recorded calls, newest first
+// snapshot 返回记录的调用，最新的在前
+func (h *callHistory) snapshot() []CallRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := h.next
+	if h.full {
+		count = len(h.records)
+	}
+	records := make([]CallRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		records = append(records, h.records[(h.next-i+len(h.records))%len(h.records)])
+	}
+	return records
+}
+
+// capacity returns the number of calls the history keeps
+// capacity 返回历史保留的调用数量
+func (h *callHistory) capacity() int {
+	return len(h.records)
+}
+
+// historyMiddleware records every tool call except get_call_history, with the same
+// redacted arguments as the audit log
+// historyMiddleware 记录除 get_call_history 外的每次工具调用，参数与审计日志一样经过脱敏
+func (s *Server) historyMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+		params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+		if method != "tools/call" || !ok || params.Name == historyToolName {
+			return next(ctx, method, req)
+		}
+
+		record := CallRecord{
+			Time:   time.Now().UTC(),
+			Caller: callerIdentity(req),
+			Tool:   params.Name,
+		}
+		var args map[string]interface{}
+		if len(params.Arguments) > 0 && json.Unmarshal(params.Arguments, &args) == nil {
+			record.Arguments = summarizeArguments(redactArguments(args))
+			record.Cluster, _ = args["cluster_name"].(string)
+		}
+		if record.Cluster == "" {
+			record.Cluster = s.currentCluster(ctx)
+		}
+
+		start := time.Now()
+		defer func() {
+			record.DurationMs = time.Since(start).Milliseconds()
+			if r := recover(); r != nil {
+				record.Outcome = auditOutcomePanic
+				record.Error = fmt.Sprint(r)
+				s.history.add(record)
+				panic(r)
+			}
+			record.Outcome, record.Error = auditOutcome(result, err)
+			s.history.add(record)
+		}()
+
+		return next(ctx, method, req)
+	}
+}
+
+// summarizeArguments shortens long string values, recursively, so the history stays small
+// summarizeArguments 递归截短较长的字符串值，使历史保持较小
+func summarizeArguments(args map[string]interface{}) map[string]interface{} {
+	for k, v := range args {
+		args[k] = summarizeValue(v)
+	}
+	return args
+}
+
+// summarizeValue shortens one argument value
+// summarizeValue 截短单个参数值
+func summarizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if len(val) > historyMaxArgumentLength {
+			return fmt.Sprintf("%s... (%d bytes)", strings.ToValidUTF8(val[:historyMaxArgumentLength], ""), len(val))
+		}
+	case map[string]interface{}:
+		return summarizeArguments(val)
+	case []interface{}:
+		for i, item := range val {
+			val[i] = summarizeValue(item)
+		}
+	}
+	return v
+}
+
+// visibleCalls returns the recorded calls the caller may see, newest first. When callers
+// have their own identities (token identities, client certificates or OIDC) each only
+// sees its own calls; otherwise every client acts as the server and sees all calls.
+// visibleCalls 返回调用者可以看到的调用记录，最新的在前。调用者拥有独立身份 (token 身份、客户端证书或 OIDC) 时只能看到自己的调用，
+// 否则所有客户端都以服务器身份操作，可以看到全部调用
+func (s *Server) visibleCalls(req mcp.Request) []CallRecord {
+	records := s.history.snapshot()
+	if len(s.tokenIdentities) == 0 && !s.clientCertAuth && s.oidc == nil {
+		return records
+	}
+	caller := callerIdentity(req)
+	visible := records[:0]
+	for _, record := range records {
+		if record.Caller == caller {
+			visible = append(visible, record)
+		}
+	}
+	return visible
+}
+
+// CallHistoryResult represents the result of get_call_history tool and the k8s://server/history resource
+// CallHistoryResult 表示 get_call_history 工具和 k8s://server/history 资源的结果
+type CallHistoryResult struct {
+	// Calls are the matching calls, newest first
+	// Calls 为匹配的调用，最新的在前
+	Calls []CallRecord `json:"calls"`
+	// Capacity is the number of calls the server keeps
+	// Capacity 为服务器保留的调用数量
+	Capacity int `json:"capacity"`
+	// Text renders one line per call, e.g. "2024-05-02T10:00:00Z token:3f2a9c1b7d4e get_pod_logs prod success 42ms"
+	// Text 每次调用一行，例如 "2024-05-02T10:00:00Z token:3f2a9c1b7d4e get_pod_logs prod success 42ms"
+	Text string `json:"text"`
+}
+
+// handleGetCallHistory handles get_call_history tool
+// handleGetCallHistory 处理 get_call_history 工具
+func (s *Server) handleGetCallHistory(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Tool       string `json:"tool,omitempty"`
+	Since      string `json:"since,omitempty"`
+	OnlyErrors bool   `json:"only_errors,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	CallHistoryResult,
+	error,
+) {
+	window, err := k8s.ParseTimeWindow(input.Since, "", time.Now())
+	if err != nil {
+		return toolError(err.Error()), CallHistoryResult{Calls: []CallRecord{}}, nil
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	calls := []CallRecord{}
+	for _, record := range s.visibleCalls(req) {
+		if len(calls) == limit {
+			break
+		}
+		// Since is truncated to the second, so compare at that precision
+		// since 截断到秒，因此按秒比较
+		if !window.Contains(record.Time.Truncate(time.Second)) {
+			continue
+		}
+		if (input.Tool != "" && record.Tool != input.Tool) || (input.OnlyErrors && record.Outcome == auditOutcomeSuccess) {
+			continue
+		}
+		calls = append(calls, record)
+	}
+	return nil, newCallHistoryResult(s.sessionLanguage(req.Session), calls, s.history.capacity()), nil
+}
+
+// newCallHistoryResult builds the result with its text rendering in language
+// newCallHistoryResult 构造结果及其指定语言的文本形式
+func newCallHistoryResult(language string, calls []CallRecord, capacity int) CallHistoryResult {
+	lines := make([]string, 0, len(calls))
+	for _, call := range calls {
+		line := fmt.Sprintf("%s %s %s", call.Time.Format(time.RFC3339), call.Caller, call.Tool)
+		if call.Cluster != "" {
+			line += " " + call.Cluster
+		}
+		line += fmt.Sprintf(" %s %dms", call.Outcome, call.DurationMs)
+		if call.Error != "" {
+			line += ": " + call.Error
+		}
+		lines = append(lines, line)
+	}
+	text := strings.Join(lines, "\n")
+	if len(calls) == 0 {
+		text = localize(language, msgNoMatchingCalls)
+	}
+	return CallHistoryResult{Calls: calls, Capacity: capacity, Text: text}
+}
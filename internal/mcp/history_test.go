@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestCallHistoryRing 测试并发写入后缓冲区只保留最近的记录，且最新的在前
+func TestCallHistoryRing(t *testing.T) {
+	h := newCallHistory(10)
+	if got := h.snapshot(); len(got) != 0 {
+		t.Fatalf("expected an empty history, got %+v", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				h.add(CallRecord{Tool: fmt.Sprintf("tool-%d", i)})
+				h.snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := h.snapshot(); len(got) != 10 {
+		t.Fatalf("expected the history to keep 10 records, got %d", len(got))
+	}
+
+	for i := 0; i < 13; i++ {
+		h.add(CallRecord{Tool: fmt.Sprintf("call-%d", i)})
+	}
+	got := h.snapshot()
+	for i, record := range got {
+		if want := fmt.Sprintf("call-%d", 12-i); record.Tool != want {
+			t.Errorf("record %d = %s, want %s", i, record.Tool, want)
+		}
+	}
+}
+
+// decodeCallHistory 调用 get_call_history 并解析结果
+func decodeCallHistory(t *testing.T, session *mcp.ClientSession, args map[string]any) CallHistoryResult {
+	t.Helper()
+	result := callTool(t, session, "get_call_history", args)
+	var history CallHistoryResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatalf("failed to decode call history: %v", err)
+	}
+	return history
+}
+
+// TestCallHistory 测试工具调用被记录并可按工具、错误过滤，参数被脱敏和截短，get_call_history 自身不被记录，资源返回相同内容
+func TestCallHistory(t *testing.T) {
+	s := NewServer("test-token", &Options{HistorySize: 5})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	s.RegisterResources()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	callTool(t, session, "list_namespaces", nil)
+	// Unknown arguments are rejected but still recorded
+	// 未知参数会被拒绝，但同样会被记录
+	if result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_cluster_status",
+		Arguments: map[string]any{"cluster_name": "mock", "password": "hunter2"},
+	}); err != nil || !result.IsError {
+		t.Fatalf("expected unknown argument to be rejected, got %+v %v", result, err)
+	}
+	longName := strings.Repeat("x", 300)
+	session.CallTool(ctx, &mcp.CallToolParams{Name: "get_resource", Arguments: map[string]any{"resource_type": "pods", "name": longName}})
+
+	history := decodeCallHistory(t, session, nil)
+	if history.Capacity != 5 || len(history.Calls) != 3 {
+		t.Fatalf("expected 3 calls of capacity 5, got %+v", history)
+	}
+	if history.Calls[0].Tool != "get_resource" || history.Calls[2].Tool != "list_namespaces" {
+		t.Errorf("expected the newest call first, got %+v", history.Calls)
+	}
+	first := history.Calls[2]
+	if first.Caller != "local" || first.Cluster != "mock" || first.Outcome != auditOutcomeSuccess {
+		t.Errorf("unexpected record %+v", first)
+	}
+	if name, _ := history.Calls[0].Arguments["name"].(string); len(name) >= len(longName) || !strings.HasSuffix(name, "(300 bytes)") {
+		t.Errorf("expected the long argument to be shortened, got %q", name)
+	}
+	data, _ := json.Marshal(history)
+	if strings.Contains(string(data), "hunter2") || history.Calls[1].Arguments["password"] != auditRedacted {
+		t.Errorf("call history leaked a secret: %s", data)
+	}
+
+	// 过滤
+	if got := decodeCallHistory(t, session, map[string]any{"tool": "list_namespaces"}); len(got.Calls) != 1 {
+		t.Errorf("expected one list_namespaces call, got %+v", got.Calls)
+	}
+	if got := decodeCallHistory(t, session, map[string]any{"only_errors": true}); len(got.Calls) != 2 || got.Calls[1].Error == "" {
+		t.Errorf("expected the two failed calls, got %+v", got.Calls)
+	}
+	if got := decodeCallHistory(t, session, map[string]any{"limit": 1}); len(got.Calls) != 1 {
+		t.Errorf("expected the limit to apply, got %+v", got.Calls)
+	}
+	if got := decodeCallHistory(t, session, map[string]any{"since": "2000-01-01T00:00:00Z"}); len(got.Calls) != 3 {
+		t.Errorf("expected every call since 2000, got %+v", got.Calls)
+	}
+	if result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_call_history", Arguments: map[string]any{"since": "yesterday"}}); err != nil || !result.IsError {
+		t.Errorf("expected an invalid since to be a tool error, got %+v %v", result, err)
+	}
+
+	// 资源与工具返回相同的记录，读取历史不会产生新的记录
+	read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "k8s://server/history"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	var resource CallHistoryResult
+	if err := json.Unmarshal([]byte(read.Contents[0].Text), &resource); err != nil {
+		t.Fatalf("failed to decode resource: %v", err)
+	}
+	if len(resource.Calls) != 3 {
+		t.Errorf("expected the history to exclude itself, got %+v", resource.Calls)
+	}
+}
+
+// TestCallHistoryDisabled 测试 HistorySize 为负数时不注册工具和资源
+func TestCallHistoryDisabled(t *testing.T) {
+	s := NewServer("test-token", &Options{HistorySize: -1})
+	s.RegisterTools()
+	s.RegisterResources()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_call_history"}); err == nil {
+		t.Errorf("expected get_call_history not to be registered")
+	}
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "k8s://server/history"}); err == nil {
+		t.Errorf("expected k8s://server/history not to be registered")
+	}
+}
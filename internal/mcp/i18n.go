@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Languages of the answer instruction of prompts and the fixed strings of tool results
+// prompt 的回答语言指令和工具结果中固定字符串支持的语言
+const (
+	LanguageEnglish = "en"
+	LanguageChinese = "zh"
+)
+
+// languageMetaKey is the key of the initialize request's _meta a client picks the
+// language of its session with, e.g. {"_meta": {"language": "zh"}}
+// languageMetaKey 是客户端在 initialize 请求的 _meta 中选择会话语言的键，例如 {"_meta": {"language": "zh"}}
+const languageMetaKey = "language"
+
+// message identifies a fixed string of the catalog
+// message 标识消息目录中的一个固定字符串
+type message int
+
+const (
+	msgAnswerLanguage message = iota
+	msgNoClustersLoaded
+	msgNoClustersAvailable
+	msgClusterHeader
+	msgClusterError
+	msgNoMatchingCalls
+	msgConfirmationRequired
+	msgConfirmQuestion
+	msgCancelledByUser
+)
+
+// messages is the catalog of fixed strings by language. English is used for a message a
+// language lacks and when no language is set
+// messages 是按语言划分的固定字符串目录。某种语言缺少的消息以及未设置语言时使用英文
+var messages = map[string]map[message]string{
+	LanguageEnglish: {
+		msgAnswerLanguage:       "Write your answer in English.",
+		msgNoClustersLoaded:     "No clusters loaded",
+		msgNoClustersAvailable:  "No clusters available",
+		msgClusterHeader:        "=== Cluster: %s ===",
+		msgClusterError:         "Error: %v",
+		msgNoMatchingCalls:      "No matching tool calls",
+		msgConfirmationRequired: "%s requires confirmation: the client does not support elicitation, so call the tool again with %s=true",
+		msgConfirmQuestion:      "Confirm %s? (yes/no)",
+		msgCancelledByUser:      "cancelled by user",
+	},
+	LanguageChinese: {
+		msgAnswerLanguage:       "请用中文提供你的回答。",
+		msgNoClustersLoaded:     "未加载任何集群",
+		msgNoClustersAvailable:  "没有可用的集群",
+		msgClusterHeader:        "=== 集群: %s ===",
+		msgClusterError:         "错误: %v",
+		msgNoMatchingCalls:      "没有匹配的工具调用",
+		msgConfirmationRequired: "%s 需要确认：客户端不支持 elicitation，请设置 %s=true 后再次调用该工具",
+		msgConfirmQuestion:      "确认执行 %s？(yes/no)",
+		msgCancelledByUser:      "已被用户取消",
+	},
+}
+
+// ParseLanguage normalizes a language setting: "" and "auto" mean none, and region
+// variants such as "zh-CN" or "en_US" map to their base language
+// ParseLanguage 规范化语言设置："" 和 "auto" 表示不设置，"zh-CN"、"en_US" 等地区变体映射为其基础语言
+func ParseLanguage(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "auto" {
+		return "", nil
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(value, "_", "-"), "-")
+	if _, ok := messages[base]; !ok {
+		return "", fmt.Errorf("unsupported language %q, expected %s or %s", value, LanguageEnglish, LanguageChinese)
+	}
+	return base, nil
+}
+
+// sessionLanguage returns the language of a session: the one the client asked for in
+// the _meta of its initialize request, else the server's --language
+// sessionLanguage 返回会话的语言：客户端在 initialize 请求的 _meta 中指定的语言，否则为服务器的 --language
+func (s *Server) sessionLanguage(session *mcp.ServerSession) string {
+	if session == nil {
+		return s.language
+	}
+	if params := session.InitializeParams(); params != nil {
+		if value, ok := params.Meta[languageMetaKey].(string); ok {
+			if language, err := ParseLanguage(value); err == nil && language != "" {
+				return language
+			}
+		}
+	}
+	return s.language
+}
+
+// localize returns a message of the catalog in language, formatted with args
+// localize 返回目录中指定语言的消息，并使用 args 格式化
+func localize(language string, id message, args ...interface{}) string {
+	format, ok := messages[language][id]
+	if !ok {
+		format = messages[LanguageEnglish][id]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
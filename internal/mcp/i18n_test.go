@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// connectLanguageClient 连接一个在 initialize 请求的 _meta 中指定 language 的测试客户端
+func connectLanguageClient(t *testing.T, s *Server, language string) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if params, ok := req.GetParams().(*mcp.InitializeParams); ok {
+				params.Meta = mcp.Meta{languageMetaKey: language}
+			}
+			return next(ctx, method, req)
+		}
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() {
+		clientSession.Close()
+		serverSession.Wait()
+		s.Close()
+	})
+	return clientSession
+}
+
+// TestParseLanguage 测试语言设置的规范化和非法值
+func TestParseLanguage(t *testing.T) {
+	for value, want := range map[string]string{"": "", "auto": "", "en": "en", "EN-us": "en", "zh": "zh", "zh_CN": "zh", " zh-Hans ": "zh"} {
+		got, err := ParseLanguage(value)
+		if err != nil || got != want {
+			t.Errorf("ParseLanguage(%q) = %q, %v; expected %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseLanguage("fr"); err == nil || !strings.Contains(err.Error(), "expected en or zh") {
+		t.Errorf("expected fr to be refused, got %v", err)
+	}
+}
+
+// TestPromptLanguage 测试 prompt 根据 --language 和会话的 _meta.language 追加回答语言指令，未设置时不追加
+func TestPromptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		meta     string
+		want     string
+	}{
+		{name: "none", want: ""},
+		{name: "english", language: LanguageEnglish, want: "Write your answer in English."},
+		{name: "chinese", language: LanguageChinese, want: "请用中文提供你的回答。"},
+		{name: "session override", language: LanguageEnglish, meta: "zh-CN", want: "请用中文提供你的回答。"},
+		{name: "unknown session language", language: LanguageChinese, meta: "fr", want: "请用中文提供你的回答。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("test-token", &Options{Language: tt.language})
+			s.RegisterPrompts()
+			var session *mcp.ClientSession
+			if tt.meta != "" {
+				session = connectLanguageClient(t, s, tt.meta)
+			} else {
+				session = connectTestClient(t, s, nil)
+			}
+
+			result, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{
+				Name:      "generate_kubectl_commands",
+				Arguments: map[string]string{"intent": "list pods"},
+			})
+			if err != nil {
+				t.Fatalf("GetPrompt failed: %v", err)
+			}
+			text := promptText(t, result)
+			if tt.want == "" {
+				if strings.Contains(text, "Write your answer") || strings.Contains(text, "中文") {
+					t.Errorf("expected no answer language instruction:\n%s", text)
+				}
+				return
+			}
+			if !strings.HasSuffix(text, tt.want+"\n") {
+				t.Errorf("expected the prompt to end with %q:\n%s", tt.want, text)
+			}
+		})
+	}
+}
+
+// TestLocalizedToolResults 测试 list_clusters 的空结果和确认提示使用会话语言
+func TestLocalizedToolResults(t *testing.T) {
+	tests := []struct {
+		language     string
+		noClusters   string
+		confirmation string
+	}{
+		{language: "", noClusters: "No clusters loaded", confirmation: "deletion of configmaps stale in namespace default on cluster test requires confirmation"},
+		{language: LanguageEnglish, noClusters: "No clusters loaded", confirmation: "deletion of configmaps stale in namespace default on cluster test requires confirmation"},
+		{language: LanguageChinese, noClusters: "未加载任何集群", confirmation: "deletion of configmaps stale in namespace default on cluster test 需要确认"},
+	}
+	for _, tt := range tests {
+		t.Run("language="+tt.language, func(t *testing.T) {
+			s := NewServer("test-token", &Options{Language: tt.language})
+			s.RegisterTools()
+			session := connectTestClient(t, s, nil)
+			clusters, _ := callQueryTool[ClustersResult](t, session, "list_clusters", map[string]any{})
+			if clusters.Clusters != tt.noClusters {
+				t.Errorf("expected %q, got %q", tt.noClusters, clusters.Clusters)
+			}
+
+			s = NewServer("test-token", &Options{Language: tt.language, AllowWrite: true})
+			s.clusterManager.AddClientset("test", fake.NewSimpleClientset(
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default"}},
+			))
+			s.RegisterTools()
+			session = connectTestClient(t, s, nil)
+			result, text := callWithArguments(t, session, "delete_resource", map[string]any{"resource_type": "configmaps", "name": "stale"})
+			if !result.IsError || !strings.Contains(text, tt.confirmation) {
+				t.Errorf("expected %q, got %s", tt.confirmation, text)
+			}
+		})
+	}
+}
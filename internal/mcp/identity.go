@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// Identity is the Kubernetes user a bearer token acts as through impersonation
+// Identity 是 bearer token 通过身份模拟所代表的 Kubernetes 用户
+type Identity struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// tokenIdentitiesFile is the layout of the token identities file:
+//
+//	tokens:
+//	  - token: s3cr3t
+//	    user: alice@example.com
+//	    groups: [team-a]
+//
+// tokenIdentitiesFile 是 token 身份映射文件的格式
+type tokenIdentitiesFile struct {
+	Tokens []struct {
+		Token string `json:"token"`
+		Identity
+	} `json:"tokens"`
+}
+
+// LoadTokenIdentities reads the extra bearer tokens and the identity each one is
+// impersonated as from a YAML or JSON file
+// LoadTokenIdentities 从 YAML 或 JSON 文件读取额外的 bearer token 及其模拟的身份
+func LoadTokenIdentities(path string) (map[string]Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token identities: %w", err)
+	}
+
+	var file tokenIdentitiesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid token identities %s: %w", path, err)
+	}
+
+	identities := make(map[string]Identity, len(file.Tokens))
+	for i, entry := range file.Tokens {
+		if entry.Token == "" || entry.User == "" {
+			return nil, fmt.Errorf("invalid token identities %s: entry %d needs both token and user", path, i+1)
+		}
+		if _, exists := identities[entry.Token]; exists {
+			return nil, fmt.Errorf("invalid token identities %s: entry %d repeats a token", path, i+1)
+		}
+		identities[entry.Token] = entry.Identity
+	}
+	return identities, nil
+}
+
+// validToken reports whether token is the server token or one of the identity tokens.
+// Every token is compared so the time taken doesn't reveal which one matched.
+// validToken 判断 token 是否为服务器 token 或任一身份 token，逐个比较所有 token，避免耗时泄露匹配结果
+func (s *Server) validToken(token string) bool {
+	valid := s.authToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) == 1
+	for candidate := range s.tokenIdentities {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// requestIdentity returns the identity of the request's verified client certificate,
+// or else that of its OIDC token or the identity mapped to its bearer token, if any
+// requestIdentity 返回请求已验证客户端证书的身份，否则返回其 OIDC token 的身份或 bearer token 映射的身份（如果有）
+func (s *Server) requestIdentity(req mcp.Request) (Identity, bool) {
+	extra := req.GetExtra()
+	if extra == nil || extra.Header == nil {
+		return Identity{}, false
+	}
+	if s.clientCertAuth {
+		if identity, ok := headerCertIdentity(extra.Header); ok {
+			return identity, true
+		}
+	}
+	if s.oidc != nil {
+		if identity, ok := headerOIDCIdentity(extra.Header); ok {
+			return identity, true
+		}
+	}
+	const prefix = "Bearer "
+	authHeader := extra.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, prefix) {
+		return Identity{}, false
+	}
+	identity, ok := s.tokenIdentities[strings.TrimPrefix(authHeader, prefix)]
+	return identity, ok
+}
+
+// impersonationMiddleware makes every Kubernetes request of a tool call, resource
+// read or prompt run as the caller's certificate, OIDC or token identity
+// impersonationMiddleware 使工具调用、资源读取和 prompt 发出的所有 Kubernetes 请求以调用者证书、OIDC 或 token 对应的身份执行
+func (s *Server) impersonationMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if identity, ok := s.requestIdentity(req); ok {
+			ctx = k8s.WithImpersonation(ctx, rest.ImpersonationConfig{
+				UserName: identity.User,
+				Groups:   identity.Groups,
+			})
+		}
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// TestLoadTokenIdentities 测试读取 token 身份映射文件及其校验
+func TestLoadTokenIdentities(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	identities, err := LoadTokenIdentities(write("ok.yaml", `
+tokens:
+  - token: alice-token
+    user: alice@example.com
+    groups: [team-a]
+  - token: bot-token
+    user: system:serviceaccount:ci:deployer
+`))
+	if err != nil {
+		t.Fatalf("LoadTokenIdentities failed: %v", err)
+	}
+	want := map[string]Identity{
+		"alice-token": {User: "alice@example.com", Groups: []string{"team-a"}},
+		"bot-token":   {User: "system:serviceaccount:ci:deployer"},
+	}
+	if !reflect.DeepEqual(identities, want) {
+		t.Errorf("unexpected identities: %+v", identities)
+	}
+
+	for name, content := range map[string]string{
+		"no-user.yaml":   "tokens:\n  - token: a\n",
+		"duplicate.yaml": "tokens:\n  - token: a\n    user: x\n  - token: a\n    user: y\n",
+		"unknown.yaml":   "tokens:\n  - token: a\n    user: x\n    role: admin\n",
+	} {
+		if _, err := LoadTokenIdentities(write(name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// headerTransport 为每个请求添加 Authorization 请求头
+type headerTransport struct {
+	token string
+}
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+h.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestTokenIdentityImpersonation 测试身份 token 的请求以映射的用户访问集群，服务器 token 使用服务器级模拟身份
+func TestTokenIdentityImpersonation(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var review authorizationv1.SelfSubjectAccessReview
+		json.Unmarshal(body, &review)
+		review.Status.Allowed = r.Header.Get("Impersonate-User") == "alice"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	defer apiServer.Close()
+
+	s := NewServer("server-token", &Options{
+		Impersonate:     rest.ImpersonationConfig{UserName: "readonly"},
+		TokenIdentities: map[string]Identity{"alice-token": {User: "alice", Groups: []string{"team-a"}}},
+	})
+	if err := s.clusterManager.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	checkAs := func(token string) (PermissionsResult, error) {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+			Endpoint:   httpServer.URL,
+			HTTPClient: &http.Client{Transport: headerTransport{token: token}},
+		}, nil)
+		if err != nil {
+			return PermissionsResult{}, err
+		}
+		defer session.Close()
+
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "check_permissions",
+			Arguments: map[string]any{"verb": "delete", "resource": "pods", "namespace": "team-a"},
+		})
+		if err != nil {
+			return PermissionsResult{}, err
+		}
+		var decoded PermissionsResult
+		data, _ := json.Marshal(result.StructuredContent)
+		json.Unmarshal(data, &decoded)
+		return decoded, nil
+	}
+
+	alice, err := checkAs("alice-token")
+	if err != nil {
+		t.Fatalf("check as alice failed: %v", err)
+	}
+	if !alice.Allowed || alice.User != "alice" || !reflect.DeepEqual(alice.Groups, []string{"team-a"}) {
+		t.Errorf("unexpected result for alice: %+v", alice)
+	}
+
+	server, err := checkAs("server-token")
+	if err != nil {
+		t.Fatalf("check as server failed: %v", err)
+	}
+	if server.Allowed || server.User != "readonly" || server.Reason != "Permission denied" {
+		t.Errorf("unexpected result for the server token: %+v", server)
+	}
+
+	if _, err := checkAs("unknown-token"); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("expected an unauthorized error for an unknown token, got %v", err)
+	}
+}
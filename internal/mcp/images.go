@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleListImages handles list_images tool
+// handleListImages 处理 list_images 工具
+func (s *Server) handleListImages(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	Source        string `json:"source,omitempty"`
+	GroupBy       string `json:"group_by,omitempty"`
+	Format        string `json:"format,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ImageInventory,
+	error,
+) {
+	empty := types.ImageInventory{Images: []types.ImageUsage{}}
+	switch {
+	case input.Source != "" && input.Source != k8s.ImageSourcePods && input.Source != k8s.ImageSourceDeployments:
+		return toolError(fmt.Sprintf("unsupported source %q, expected %q or %q", input.Source, k8s.ImageSourcePods, k8s.ImageSourceDeployments)), empty, nil
+	case input.GroupBy != "" && input.GroupBy != k8s.ImageGroupByRegistry:
+		return toolError(fmt.Sprintf("unsupported group_by %q, expected %q", input.GroupBy, k8s.ImageGroupByRegistry)), empty, nil
+	case input.Format != "" && input.Format != "json" && input.Format != "text":
+		return toolError(fmt.Sprintf("unsupported format %q, expected \"json\" or \"text\"", input.Format)), empty, nil
+	}
+
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	inventory, err := s.resourceOps.ListImages(ctx, namespace, input.Source, input.GroupBy, clusterName)
+	if err != nil {
+		return nil, empty, fmt.Errorf("failed to list images: %w", err)
+	}
+	if input.Format == "text" {
+		inventory.Text = k8s.RenderImageInventory(inventory)
+	}
+	return nil, *inventory, nil
+}
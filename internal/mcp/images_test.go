@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestListImages 测试 list_images 列出模拟集群 shop 命名空间的镜像，按镜像仓库汇总并渲染文本，非法参数返回工具错误
+func TestListImages(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "list_images", map[string]any{"namespace": "shop", "group_by": "registry", "format": "text"})
+
+	var inventory types.ImageInventory
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		t.Fatalf("failed to decode inventory: %v", err)
+	}
+	if inventory.Scope != "namespace shop" || inventory.Source != "pods" || len(inventory.Images) == 0 || len(inventory.Registries) == 0 {
+		t.Fatalf("unexpected inventory %+v", inventory)
+	}
+	for _, image := range inventory.Images {
+		if image.Registry == "" || len(image.Workloads) == 0 || len(image.PullPolicies) == 0 {
+			t.Errorf("incomplete image %+v", image)
+		}
+	}
+	if !strings.Contains(inventory.Text, "Registries:") {
+		t.Errorf("unexpected text:\n%s", inventory.Text)
+	}
+
+	for _, args := range []map[string]any{{"source": "jobs"}, {"group_by": "team"}, {"format": "yaml"}} {
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_images", Arguments: args})
+		if err != nil || !result.IsError {
+			t.Errorf("expected %v to be a tool error, got %+v %v", args, result, err)
+		}
+	}
+}
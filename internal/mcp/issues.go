@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleFindIssues handles find_issues tool
+// handleFindIssues 处理 find_issues 工具
+func (s *Server) handleFindIssues(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.IssueReport,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	report, err := s.resourceOps.FindIssues(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, types.IssueReport{}, fmt.Errorf("failed to find issues: %w", err)
+	}
+	return nil, *report, nil
+}
@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestFindIssues 测试 find_issues 扫描模拟集群的 shop 命名空间，报告不受控制器管理的 worker Pod 和未被引用的 ConfigMap 并附带修复建议
+func TestFindIssues(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "find_issues", map[string]any{"namespace": "shop"})
+
+	var report types.IssueReport
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Scope != "namespace shop" || report.Counts["unmanaged-pod"] != 1 || report.Counts["unused-configmap"] != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	unmanaged := report.Issues[0]
+	if unmanaged.Category != "unmanaged-pod" || unmanaged.Severity != "warning" || unmanaged.Name != "worker-5f6d7c9b4-xk2lp" || unmanaged.Remediation == "" {
+		t.Errorf("unexpected first issue %+v", unmanaged)
+	}
+	if !strings.HasPrefix(report.Text, "5 issues in namespace shop (4 warning, 1 info)\n") {
+		t.Errorf("unexpected text:\n%s", report.Text)
+	}
+}
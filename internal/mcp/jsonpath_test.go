@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestGetResourceJSONPath 测试 get_resource 的 jsonpath 参数只返回提取的值，语法错误时返回包含位置的工具错误
+func TestGetResourceJSONPath(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	for expression, want := range map[string]string{
+		"spec.template.spec.containers[0].image":   "nginx:1.25",
+		"{.status.readyReplicas}/{.spec.replicas}": "2/2",
+	} {
+		result := callTool(t, session, "get_resource", map[string]any{
+			"resource_type": "deployments",
+			"name":          "web",
+			"namespace":     "shop",
+			"jsonpath":      expression,
+		})
+		var got ResourceResult
+		data, _ := json.Marshal(result.StructuredContent)
+		json.Unmarshal(data, &got)
+		if got.Resource != want {
+			t.Errorf("jsonpath %q returned %q, want %q", expression, got.Resource, want)
+		}
+	}
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_resource", Arguments: map[string]any{
+		"resource_type": "deployments",
+		"name":          "web",
+		"namespace":     "shop",
+		"jsonpath":      "spec.template.spec.containers[x]",
+	}})
+	if err != nil {
+		t.Fatalf("get_resource failed: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "at character 32") {
+		t.Errorf("expected a syntax error with its position, got %v %s", result.IsError, text)
+	}
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// sessionIDHeader carries the session ID the streamable HTTP transport assigns on initialize
+// sessionIDHeader 携带可流式 HTTP 传输在 initialize 时分配的会话 ID
+const sessionIDHeader = "Mcp-Session-Id"
+
+// jsonRPCErrorResponse is a JSON-RPC 2.0 error response, with a null id when the request's is unknown
+// jsonRPCErrorResponse JSON-RPC 2.0 错误响应，请求 id 未知时为 null
+type jsonRPCErrorResponse struct {
+	JSONRPC string         `json:"jsonrpc"`
+	ID      interface{}    `json:"id"`
+	Error   *jsonrpc.Error `json:"error"`
+}
+
+// ValidateJSONRPCMiddleware answers malformed POST bodies with a JSON-RPC error instead
+// of a plain-text HTTP error, so clients waiting for a response don't hang:
+//   - invalid JSON yields ParseError (-32700) with id null
+//   - batch arrays and non-object payloads yield InvalidRequest (-32600) with id null
+//   - requests other than initialize and ping without a session ID yield
+//     "Server not initialized" (-32002) with the request's id
+//
+// Valid requests and notifications are passed through unchanged; the SDK never
+// responds to notifications.
+// ValidateJSONRPCMiddleware 对格式错误的 POST 请求体返回 JSON-RPC 错误而不是纯文本 HTTP 错误，
+// 避免客户端一直等待响应：
+//   - 无效 JSON 返回 ParseError (-32700)，id 为 null
+//   - 批量数组和非对象请求返回 InvalidRequest (-32600)，id 为 null
+//   - 没有会话 ID 的请求（initialize 和 ping 除外）返回 "Server not initialized" (-32002)，id 为请求的 id
+//
+// 合法的请求和通知原样传递，SDK 不会响应通知。
+func ValidateJSONRPCMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		// Restore the body for the next handler
+		// 为下一个处理器恢复请求体
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		trimmed := bytes.TrimSpace(body)
+		switch {
+		case !json.Valid(trimmed):
+			writeJSONRPCError(w, jsonrpc.CodeParseError, "Parse error: invalid JSON")
+			return
+		case len(trimmed) > 0 && trimmed[0] == '[':
+			writeJSONRPCError(w, jsonrpc.CodeInvalidRequest, "Invalid Request: batch requests are not supported")
+			return
+		case len(trimmed) > 0 && trimmed[0] != '{':
+			writeJSONRPCError(w, jsonrpc.CodeInvalidRequest, "Invalid Request: expected a JSON object")
+			return
+		}
+
+		// Sessions are only created by initialize, so a request without a session ID that
+		// is not initialize or ping was sent before the client initialized
+		// 会话只由 initialize 创建，因此没有会话 ID 且不是 initialize 或 ping 的请求是在客户端初始化之前发送的
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if r.Header.Get(sessionIDHeader) == "" && json.Unmarshal(trimmed, &msg) == nil &&
+			len(msg.ID) > 0 && string(msg.ID) != "null" && msg.Method != "" &&
+			msg.Method != "initialize" && msg.Method != "ping" {
+			writeJSONRPCErrorWithID(w, msg.ID, codeServerNotInitialized, "Server not initialized: send initialize first")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeJSONRPCError writes a JSON-RPC error response with id null
+// writeJSONRPCError 写入 id 为 null 的 JSON-RPC 错误响应
+func writeJSONRPCError(w http.ResponseWriter, code int64, message string) {
+	writeJSONRPCErrorWithID(w, nil, code, message)
+}
+
+// writeJSONRPCErrorWithID writes a JSON-RPC error response for the request with the given id
+// writeJSONRPCErrorWithID 为指定 id 的请求写入 JSON-RPC 错误响应
+func writeJSONRPCErrorWithID(w http.ResponseWriter, id json.RawMessage, code int64, message string) {
+	resp := jsonRPCErrorResponse{
+		JSONRPC: "2.0",
+		ID:      nil,
+		Error:   &jsonrpc.Error{Code: code, Message: message},
+	}
+	if id != nil {
+		resp.ID = id
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(resp)
+}
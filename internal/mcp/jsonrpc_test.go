@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestValidateJSONRPCMiddleware 测试解析错误和批量请求返回 JSON-RPC 错误，合法请求原样传递
+func TestValidateJSONRPCMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int64
+	}{
+		{"invalid json", `{"jsonrpc":"2.0","id":1,"method":`, -32700},
+		{"batch", `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, -32600},
+		{"non-object", `"ping"`, -32600},
+		{"request", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, 0},
+		{"notification", `{"jsonrpc":"2.0","method":"notifications/initialized"}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var passedBody string
+			handler := ValidateJSONRPCMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				passedBody = string(body)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if tt.wantCode == 0 {
+				if rec.Code != http.StatusAccepted || passedBody != tt.body {
+					t.Errorf("expected request to pass through unchanged, got %d %q", rec.Code, passedBody)
+				}
+				return
+			}
+
+			var resp struct {
+				JSONRPC string          `json:"jsonrpc"`
+				ID      json.RawMessage `json:"id"`
+				Error   struct {
+					Code int64 `json:"code"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("expected a JSON-RPC response, got %q", rec.Body.String())
+			}
+			if resp.JSONRPC != "2.0" || string(resp.ID) != "null" || resp.Error.Code != tt.wantCode {
+				t.Errorf("unexpected response: %s", rec.Body.String())
+			}
+		})
+	}
+}
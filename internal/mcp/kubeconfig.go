@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// KubeConfigResult represents the result of get_kubeconfig tool
+// KubeConfigResult 表示 get_kubeconfig 工具的结果
+type KubeConfigResult struct {
+	Context  string `json:"context"`
+	Redacted bool   `json:"redacted"`
+	// Kubeconfig is the YAML of a kubeconfig with only Context and its cluster and user
+	// Kubeconfig 是只包含 Context 及其集群和用户的 kubeconfig YAML
+	Kubeconfig string `json:"kubeconfig"`
+}
+
+// handleGetKubeConfig handles get_kubeconfig tool. Credentials are only exported
+// unredacted when the call uses the server's own identity, so an impersonated caller
+// cannot obtain the server's credentials.
+// handleGetKubeConfig 处理 get_kubeconfig 工具。只有调用使用服务器自身身份时才导出未脱敏的凭据，
+// 以免被模拟身份的调用者获取服务器的凭据
+func (s *Server) handleGetKubeConfig(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Context           string `json:"context,omitempty"`
+	RedactCredentials *bool  `json:"redact_credentials,omitempty"`
+	ClusterName       string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	KubeConfigResult,
+	error,
+) {
+	contextName := input.Context
+	if contextName == "" {
+		clusterName := s.resolveClusterName(ctx, input.ClusterName)
+		if contextName = s.clusterManager.ClusterContext(clusterName); contextName == "" {
+			return toolError(fmt.Sprintf("cluster %s was not loaded from a kubeconfig", clusterName)), KubeConfigResult{}, nil
+		}
+	}
+	contexts := s.clusterManager.KubeConfigContexts()
+	if !containsString(contexts, contextName) {
+		return toolError(fmt.Sprintf("unknown context %q; available contexts: %s", contextName, strings.Join(contexts, ", "))), KubeConfigResult{}, nil
+	}
+
+	redact := input.RedactCredentials == nil || *input.RedactCredentials
+	if !redact && s.clusterManager.Impersonation(ctx).UserName != "" {
+		return toolError("credentials can only be exported when the server acts with its own identity; use redact_credentials=true"), KubeConfigResult{}, nil
+	}
+
+	data, err := s.clusterManager.ExportKubeConfig(contextName, redact)
+	if err != nil {
+		return nil, KubeConfigResult{}, fmt.Errorf("failed to export kubeconfig: %w", err)
+	}
+	if !redact {
+		s.logger.Warn("Exported kubeconfig with credentials", "context", contextName)
+	}
+	return nil, KubeConfigResult{Context: contextName, Redacted: redact, Kubeconfig: string(data)}, nil
+}
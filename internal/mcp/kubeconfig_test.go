@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+)
+
+// exportKubeConfig 是 get_kubeconfig 测试使用的 kubeconfig，包含两个上下文和一个 token
+const exportKubeConfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster: {server: "https://127.0.0.1:1"}
+- name: staging
+  cluster: {server: "https://127.0.0.1:2"}
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: staging
+  context: {cluster: staging, user: admin}
+users:
+- name: admin
+  user: {token: s3cr3t-token}
+`
+
+// newKubeConfigServer 返回加载了 exportKubeConfig 并注册了工具的服务器
+func newKubeConfigServer(t *testing.T, opts *Options) *mcp.ClientSession {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(exportKubeConfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	s := NewServer("test-token", opts)
+	if err := s.LoadKubeConfig(path); err != nil {
+		t.Fatalf("LoadKubeConfig failed: %v", err)
+	}
+	s.RegisterTools()
+	return connectTestClient(t, s, nil)
+}
+
+// decodeKubeConfig 解析 get_kubeconfig 的结果
+func decodeKubeConfig(t *testing.T, result *mcp.CallToolResult) KubeConfigResult {
+	t.Helper()
+	var exported KubeConfigResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("failed to decode kubeconfig result: %v", err)
+	}
+	return exported
+}
+
+// TestGetKubeConfig 测试默认脱敏导出当前集群的上下文，以及按名称导出和不脱敏导出
+func TestGetKubeConfig(t *testing.T) {
+	session := newKubeConfigServer(t, &Options{AllowKubeconfigExport: true})
+
+	exported := decodeKubeConfig(t, callTool(t, session, "get_kubeconfig", nil))
+	if exported.Context != "prod" || !exported.Redacted {
+		t.Errorf("expected the redacted context of the current cluster, got %+v", exported)
+	}
+	if strings.Contains(exported.Kubeconfig, "s3cr3t-token") || !strings.Contains(exported.Kubeconfig, "token: REDACTED") {
+		t.Errorf("expected the token to be redacted:\n%s", exported.Kubeconfig)
+	}
+	if strings.Contains(exported.Kubeconfig, "staging") {
+		t.Errorf("expected only the prod context:\n%s", exported.Kubeconfig)
+	}
+
+	exported = decodeKubeConfig(t, callTool(t, session, "get_kubeconfig", map[string]any{"cluster_name": "staging"}))
+	if exported.Context != "staging" || strings.Contains(exported.Kubeconfig, "s3cr3t-token") {
+		t.Errorf("expected the redacted staging context, got %+v", exported)
+	}
+
+	exported = decodeKubeConfig(t, callTool(t, session, "get_kubeconfig", map[string]any{"context": "staging", "redact_credentials": false}))
+	if exported.Redacted || !strings.Contains(exported.Kubeconfig, "s3cr3t-token") {
+		t.Errorf("expected the credentials to be exported, got %+v", exported)
+	}
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_kubeconfig", Arguments: map[string]any{"context": "dev"}})
+	if err != nil || !result.IsError {
+		t.Errorf("expected an unknown context to fail, got %+v %v", result, err)
+	}
+}
+
+// TestGetKubeConfigDisabled 测试未启用时不注册 get_kubeconfig，模拟身份时拒绝导出凭据
+func TestGetKubeConfigDisabled(t *testing.T) {
+	session := newKubeConfigServer(t, nil)
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name == "get_kubeconfig" {
+			t.Fatalf("expected get_kubeconfig to be disabled by default")
+		}
+	}
+
+	session = newKubeConfigServer(t, &Options{AllowKubeconfigExport: true, Impersonate: rest.ImpersonationConfig{UserName: "alice"}})
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_kubeconfig", Arguments: map[string]any{"redact_credentials": false}})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected credentials not to be exported under impersonation, got %+v %v", result, err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; strings.Contains(text, "s3cr3t-token") {
+		t.Errorf("unexpected credentials in %q", text)
+	}
+	if exported := decodeKubeConfig(t, callTool(t, session, "get_kubeconfig", nil)); strings.Contains(exported.Kubeconfig, "s3cr3t-token") {
+		t.Errorf("expected the redacted export to stay available:\n%s", exported.Kubeconfig)
+	}
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientLogBuffer bounds the log entries waiting to be forwarded; further entries are dropped
+// clientLogBuffer 限制等待转发的日志条目数，超出的条目被丢弃
+const clientLogBuffer = 256
+
+// clientLogTimeout bounds sending one notifications/message, so a stuck session cannot hold up the others
+// clientLogTimeout 限制发送单条 notifications/message 的时间，避免卡住的会话阻塞其他会话
+const clientLogTimeout = 5 * time.Second
+
+// clientLogName is the logger field of the forwarded notifications
+// clientLogName 转发通知中的 logger 字段
+const clientLogName = "k8s-mcp"
+
+// mcpLoggingLevels maps pkg/logger levels to MCP logging levels
+// mcpLoggingLevels 将 pkg/logger 的级别映射为 MCP 日志级别
+var mcpLoggingLevels = map[string]mcp.LoggingLevel{
+	logger.LevelDebug: "debug",
+	logger.LevelInfo:  "info",
+	logger.LevelWarn:  "warning",
+	logger.LevelError: "error",
+}
+
+// clientLogEntry is a log entry waiting to be forwarded
+// clientLogEntry 等待转发的日志条目
+type clientLogEntry struct {
+	level mcp.LoggingLevel
+	data  map[string]interface{}
+}
+
+// clientLogSink forwards server log entries to the connected sessions as notifications/message.
+// The SDK handles logging/setLevel and drops entries below each session's level; sessions
+// that never set a level receive nothing. Write never blocks: entries go through a buffered
+// channel drained by one goroutine, so the transport logging while it holds its own locks
+// cannot deadlock, and forwarding failures are logged to the base logger only.
+// clientLogSink 将服务器日志以 notifications/message 转发给已连接的会话。SDK 处理 logging/setLevel，
+// 并丢弃低于各会话级别的条目；从未设置级别的会话不会收到日志。Write 从不阻塞：条目经带缓冲的 channel
+// 由单个 goroutine 发送，因此传输层在持有自身锁时记录日志也不会死锁，转发失败只记录到底层 logger。
+type clientLogSink struct {
+	base    logger.Logger
+	entries chan clientLogEntry
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// newClientLogSink creates a sink; forwarding starts with start
+// newClientLogSink 创建 sink，调用 start 后开始转发
+func newClientLogSink(base logger.Logger) *clientLogSink {
+	return &clientLogSink{
+		base:    base,
+		entries: make(chan clientLogEntry, clientLogBuffer),
+		done:    make(chan struct{}),
+	}
+}
+
+// Write queues a log entry for the connected sessions, dropping it if the buffer is full
+// Write 将日志条目加入转发队列，缓冲区已满时丢弃
+func (l *clientLogSink) Write(level, msg string, keysAndValues []interface{}) {
+	data := map[string]interface{}{"msg": msg}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value := keysAndValues[i+1]
+		// errors marshal to {} in JSON
+		// error 序列化为 JSON 时为 {}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[fmt.Sprint(keysAndValues[i])] = value
+	}
+
+	select {
+	case <-l.done:
+	case l.entries <- clientLogEntry{level: mcpLoggingLevels[level], data: data}:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// start forwards queued entries to the sessions of server until close
+// start 将队列中的条目转发给 server 的会话，直到调用 close
+func (l *clientLogSink) start(server *mcp.Server) {
+	go func() {
+		for {
+			select {
+			case <-l.done:
+				return
+			case entry := <-l.entries:
+				if dropped := l.dropped.Swap(0); dropped > 0 {
+					entry.data["dropped_before"] = dropped
+				}
+				for session := range server.Sessions() {
+					ctx, cancel := context.WithTimeout(context.Background(), clientLogTimeout)
+					err := session.Log(ctx, &mcp.LoggingMessageParams{Level: entry.level, Logger: clientLogName, Data: entry.data})
+					cancel()
+					if err != nil {
+						l.base.Debug("Failed to forward log entry to client", "session", session.ID(), "error", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// close stops forwarding; entries written afterwards are discarded
+// close 停止转发，之后写入的条目被丢弃
+func (l *clientLogSink) close() {
+	l.once.Do(func() { close(l.done) })
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestClientLogForwarding 测试客户端设置 warning 级别后只收到 warn 和 error 日志通知
+func TestClientLogForwarding(t *testing.T) {
+	s := NewServer("test-token", nil)
+	received := make(chan *mcp.LoggingMessageParams, 10)
+	session := connectTestClient(t, s, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+
+	if err := session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+
+	log := s.logger.With("component", "test")
+	log.Debug("debug message")
+	log.Info("info message")
+	log.Warn("warn message", "cluster", "prod")
+	log.Error("error message", "error", errors.New("boom"))
+
+	want := []struct {
+		level mcp.LoggingLevel
+		msg   string
+	}{
+		{"warning", "warn message"},
+		{"error", "error message"},
+	}
+	for _, w := range want {
+		select {
+		case params := <-received:
+			data, _ := params.Data.(map[string]interface{})
+			if params.Level != w.level || data["msg"] != w.msg || data["component"] != "test" || params.Logger != clientLogName {
+				t.Errorf("unexpected notification %+v, want %s %q", params, w.level, w.msg)
+			}
+			if w.level == "error" && data["error"] != "boom" {
+				t.Errorf("error field not forwarded as text: %+v", data)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no notification for %q", w.msg)
+		}
+	}
+
+	// 低于 warning 的日志不应转发
+	select {
+	case params := <-received:
+		t.Errorf("unexpected extra notification %+v", params)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestClientLogSinkDoesNotBlock 测试转发停止后写入日志不会阻塞
+func TestClientLogSinkDoesNotBlock(t *testing.T) {
+	sink := newClientLogSink(nil)
+	done := make(chan struct{})
+	go func() {
+		// 未启动转发时缓冲区写满后丢弃，关闭后直接返回
+		for i := 0; i < clientLogBuffer*2; i++ {
+			sink.Write("info", "message", nil)
+		}
+		sink.close()
+		sink.Write("info", "after close", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write blocked")
+	}
+	if sink.dropped.Load() != clientLogBuffer {
+		t.Errorf("expected %d dropped entries, got %d", clientLogBuffer, sink.dropped.Load())
+	}
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleLabelResource handles label_resource tool
+// handleLabelResource 处理 label_resource 工具
+func (s *Server) handleLabelResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType   string             `json:"resource_type"`
+	Name           string             `json:"name"`
+	Namespace      string             `json:"namespace,omitempty"`
+	Labels         map[string]*string `json:"labels"`
+	Overwrite      bool               `json:"overwrite,omitempty"`
+	Confirm        bool               `json:"confirm,omitempty"`
+	WaitForReady   bool               `json:"wait_for_ready,omitempty"`
+	TimeoutSeconds int                `json:"timeout_seconds,omitempty"`
+	ClusterName    string             `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.MetadataResult,
+	error,
+) {
+	return s.patchMetadata(ctx, req, input.ResourceType, input.Namespace, input.Name, k8s.MetadataLabels, input.Labels, input.Overwrite, input.Confirm, readinessWait{input.WaitForReady, input.TimeoutSeconds}, input.ClusterName)
+}
+
+// handleAnnotateResource handles annotate_resource tool
+// handleAnnotateResource 处理 annotate_resource 工具
+func (s *Server) handleAnnotateResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType   string             `json:"resource_type"`
+	Name           string             `json:"name"`
+	Namespace      string             `json:"namespace,omitempty"`
+	Annotations    map[string]*string `json:"annotations"`
+	Overwrite      bool               `json:"overwrite,omitempty"`
+	Confirm        bool               `json:"confirm,omitempty"`
+	WaitForReady   bool               `json:"wait_for_ready,omitempty"`
+	TimeoutSeconds int                `json:"timeout_seconds,omitempty"`
+	ClusterName    string             `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.MetadataResult,
+	error,
+) {
+	return s.patchMetadata(ctx, req, input.ResourceType, input.Namespace, input.Name, k8s.MetadataAnnotations, input.Annotations, input.Overwrite, input.Confirm, readinessWait{input.WaitForReady, input.TimeoutSeconds}, input.ClusterName)
+}
+
+// readinessWait carries the wait_for_ready and timeout_seconds arguments of a mutating tool
+// readinessWait 携带修改类工具的 wait_for_ready 和 timeout_seconds 参数
+type readinessWait struct {
+	enabled        bool
+	timeoutSeconds int
+}
+
+// patchMetadata asks for confirmation and then sets or removes the labels or annotations,
+// waiting for the workload afterwards when wait.enabled
+// patchMetadata 请求确认后设置或删除标签或注解，wait.enabled 时随后等待工作负载就绪
+func (s *Server) patchMetadata(ctx context.Context, req *mcp.CallToolRequest, resourceType, namespace, name, field string, changes map[string]*string, overwrite, confirm bool, wait readinessWait, clusterName string) (
+	*mcp.CallToolResult,
+	types.MetadataResult,
+	error,
+) {
+	clusterName = s.resolveClusterName(ctx, clusterName)
+	if wait.enabled && !k8s.SupportsReadiness(k8s.ResourceType(resourceType)) {
+		return toolError(fmt.Sprintf("wait_for_ready is only supported for deployments, statefulsets and daemonsets, not %s", resourceType)), types.MetadataResult{}, nil
+	}
+
+	action := fmt.Sprintf("update of %s %s on %s %s", field, describeMetadataChanges(changes), resourceType, name)
+	if !k8s.IsClusterScoped(k8s.ResourceType(resourceType)) {
+		namespace, _ = s.resolveNamespace(ctx, namespace, false, clusterName)
+		action += " in namespace " + namespace
+	}
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+	if result, err := s.confirmDestructive(ctx, req, action, confirm); result != nil || err != nil {
+		return result, types.MetadataResult{}, err
+	}
+
+	result, err := s.resourceOps.PatchMetadata(ctx, k8s.ResourceType(resourceType), namespace, name, field, changes, overwrite, clusterName)
+	if err != nil {
+		return nil, types.MetadataResult{}, fmt.Errorf("failed to update %s: %w", field, err)
+	}
+	if wait.enabled {
+		result.Readiness = s.waitForReadiness(ctx, k8s.ResourceType(resourceType), namespace, name, wait.timeoutSeconds, clusterName)
+	}
+	return nil, *result, nil
+}
+
+// describeMetadataChanges renders changes like kubectl arguments: key=value to set, key- to remove
+// describeMetadataChanges 以 kubectl 参数的形式描述变更：key=value 表示设置，key- 表示删除
+func describeMetadataChanges(changes map[string]*string) string {
+	parts := make([]string, 0, len(changes))
+	for key, value := range changes {
+		if value == nil {
+			parts = append(parts, key+"-")
+		} else {
+			parts = append(parts, key+"="+*value)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
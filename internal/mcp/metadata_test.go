@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestLabelResourceTool 测试 label_resource 接受 null 删除标签，未确认时不修改对象
+func TestLabelResourceTool(t *testing.T) {
+	s := NewServer("test-token", &Options{AllowWrite: true})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+	args := map[string]any{
+		"resource_type": "deployments",
+		"name":          "web",
+		"namespace":     "shop",
+		"labels":        map[string]any{"app": nil, "tier": "frontend"},
+	}
+
+	// 客户端不支持 elicitation 且未传 confirm 时拒绝执行
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "label_resource", Arguments: args})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected a confirmation error, got %v %+v", err, result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "update of labels app- tier=frontend on deployments web in namespace shop") {
+		t.Errorf("unexpected confirmation message %q", text)
+	}
+
+	args["confirm"] = true
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "label_resource", Arguments: args})
+	if err != nil || result.IsError {
+		t.Fatalf("label_resource failed: %v %+v", err, result)
+	}
+	var labels types.MetadataResult
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &labels)
+	if !labels.Changed || !reflect.DeepEqual(labels.Before, map[string]string{"app": "web"}) || !reflect.DeepEqual(labels.After, map[string]string{"tier": "frontend"}) {
+		t.Errorf("unexpected result %+v", labels)
+	}
+
+	// 注解同样生效，已存在的值未设置 overwrite 时报错
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "annotate_resource", Arguments: map[string]any{
+		"resource_type": "nodes", "name": "node-1", "annotations": map[string]any{"owner": "ops"}, "confirm": true,
+	}})
+	if err != nil || result.IsError {
+		t.Fatalf("annotate_resource failed: %v %+v", err, result)
+	}
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "annotate_resource", Arguments: map[string]any{
+		"resource_type": "nodes", "name": "node-1", "annotations": map[string]any{"owner": "dev"}, "confirm": true,
+	}})
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "overwrite=true") {
+		t.Errorf("expected an overwrite error, got %v %+v", err, result)
+	}
+}
+
+// TestMutationWaitForReady 测试 wait_for_ready=true 时结果附带就绪摘要，不支持的资源类型在修改前被拒绝
+func TestMutationWaitForReady(t *testing.T) {
+	replicas := int32(2)
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}},
+	)
+	s := NewServer("test-token", &Options{AllowWrite: true})
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "label_resource", map[string]any{
+		"resource_type": "deployments", "name": "web", "labels": map[string]any{"tier": "frontend"},
+		"wait_for_ready": true, "timeout_seconds": 5, "confirm": true,
+	})
+	var labels types.MetadataResult
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &labels)
+	if labels.Readiness == nil || !labels.Readiness.Ready || labels.Readiness.Kind != "Deployment" ||
+		!strings.HasPrefix(labels.Readiness.Summary, "Deployment web is ready: generation 1 observed, 2/2 updated, 2 ready, 2 available") {
+		t.Errorf("expected a readiness summary, got %+v", labels.Readiness)
+	}
+
+	result, text := callWithArguments(t, session, "annotate_resource", map[string]any{
+		"resource_type": "configmaps", "name": "settings", "annotations": map[string]any{"owner": "ops"},
+		"wait_for_ready": true, "confirm": true,
+	})
+	if !result.IsError || !strings.Contains(text, "wait_for_ready is only supported for deployments, statefulsets and daemonsets, not configmaps") {
+		t.Fatalf("expected configmaps to be refused, got %s", text)
+	}
+	if cm, _ := client.CoreV1().ConfigMaps("default").Get(context.Background(), "settings", metav1.GetOptions{}); len(cm.Annotations) != 0 {
+		t.Errorf("expected the ConfigMap to be left alone, got %v", cm.Annotations)
+	}
+}
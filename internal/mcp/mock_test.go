@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// callMockTool 调用工具并返回结构化结果的 JSON 文本
+func callMockTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) string {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	if result.IsError {
+		t.Fatalf("%s returned an error: %s", name, result.Content[0].(*mcp.TextContent).Text)
+	}
+	data, _ := json.Marshal(result.StructuredContent)
+	return string(data)
+}
+
+// TestMockClusterEndToEnd 测试内置模拟数据下的 list/get/describe 工具，以及写入工具修改内存数据
+func TestMockClusterEndToEnd(t *testing.T) {
+	s := NewServer("test-token", &Options{AllowWrite: true})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	if got := callMockTool(t, session, "get_current_cluster", nil); !strings.Contains(got, `"mock"`) {
+		t.Errorf("expected current cluster mock, got %s", got)
+	}
+
+	namespaces := callMockTool(t, session, "list_namespaces", nil)
+	for _, name := range []string{"default", "kube-system", "shop"} {
+		if !strings.Contains(namespaces, `"`+name+`"`) {
+			t.Errorf("list_namespaces missing %s: %s", name, namespaces)
+		}
+	}
+
+	pods := callMockTool(t, session, "list_pods", map[string]any{"namespace": "shop"})
+	for _, name := range []string{"web-7d4b9c8f6-abcde", "web-7d4b9c8f6-fghij", "worker-5f6d7c9b4-xk2lp"} {
+		if !strings.Contains(pods, name) {
+			t.Errorf("list_pods missing %s: %s", name, pods)
+		}
+	}
+	if strings.Contains(pods, "coredns") {
+		t.Errorf("list_pods returned pods of another namespace: %s", pods)
+	}
+
+	deployment := callMockTool(t, session, "get_resource", map[string]any{"resource_type": "deployments", "name": "web", "namespace": "shop"})
+	if !strings.Contains(deployment, "nginx:1.25") {
+		t.Errorf("get_resource did not return the deployment: %s", deployment)
+	}
+
+	// describe_node 只列出调度到该节点的 Pod
+	node := callMockTool(t, session, "describe_node", map[string]any{"node_name": "node-2"})
+	if !strings.Contains(node, "worker-5f6d7c9b4-xk2lp") || strings.Contains(node, "web-7d4b9c8f6-abcde") {
+		t.Errorf("describe_node returned the wrong pods: %s", node)
+	}
+
+	// 写入工具修改内存中的数据，后续读取可见
+	callMockTool(t, session, "cordon_node", map[string]any{"node_name": "node-1", "confirm": true})
+	node = callMockTool(t, session, "describe_node", map[string]any{"node_name": "node-1"})
+	if !strings.Contains(node, `"unschedulable":true`) {
+		t.Errorf("expected node-1 to be unschedulable after cordon_node: %s", node)
+	}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// delete_namespace timeouts for wait=true
+// delete_namespace 在 wait=true 时的超时时间
+const (
+	defaultNamespaceDeleteTimeout = 60 * time.Second
+	maxNamespaceDeleteTimeout     = 600 * time.Second
+)
+
+// handleCreateNamespace handles create_namespace tool
+// handleCreateNamespace 处理 create_namespace 工具
+func (s *Server) handleCreateNamespace(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	ClusterName string            `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.NamespaceCreation,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	result, err := s.resourceOps.CreateNamespace(ctx, input.Name, input.Labels, input.Annotations, clusterName)
+	if err != nil {
+		return nil, types.NamespaceCreation{}, err
+	}
+	return nil, *result, nil
+}
+
+// handleDeleteNamespace handles delete_namespace tool
+// handleDeleteNamespace 处理 delete_namespace 工具
+func (s *Server) handleDeleteNamespace(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name           string `json:"name"`
+	Wait           bool   `json:"wait,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	Confirm        bool   `json:"confirm,omitempty"`
+	ClusterName    string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.NamespaceDeletion,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	opts := k8s.NamespaceDeleteOptions{
+		Protected: s.protectedNamespaces,
+		Wait:      input.Wait,
+		Timeout:   defaultNamespaceDeleteTimeout,
+	}
+	if input.TimeoutSeconds > 0 {
+		opts.Timeout = time.Duration(input.TimeoutSeconds) * time.Second
+	}
+	if opts.Timeout > maxNamespaceDeleteTimeout {
+		opts.Timeout = maxNamespaceDeleteTimeout
+	}
+
+	// Refuse protected namespaces before asking the user
+	// 在询问用户之前拒绝受保护的命名空间
+	if k8s.IsProtectedNamespace(input.Name, s.protectedNamespaces) {
+		return nil, types.NamespaceDeletion{}, fmt.Errorf("%w: %s cannot be deleted", k8s.ErrNamespaceProtected, input.Name)
+	}
+	action := fmt.Sprintf("deletion of namespace %s and everything in it", input.Name)
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+	if result, err := s.confirmDestructive(ctx, req, action, input.Confirm); result != nil || err != nil {
+		return result, types.NamespaceDeletion{}, err
+	}
+
+	result, err := s.resourceOps.DeleteNamespace(ctx, input.Name, opts, clusterName)
+	if err != nil {
+		return nil, types.NamespaceDeletion{}, err
+	}
+	return nil, *result, nil
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestNamespaceTools 测试 create_namespace 和 delete_namespace：受保护的命名空间被拒绝，删除需要确认，
+// 结果报告剩余的工作负载
+func TestNamespaceTools(t *testing.T) {
+	s := NewServer("test-token", &Options{AllowWrite: true, ProtectedNamespaces: []string{"prod"}})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	result := callTool(t, session, "create_namespace", map[string]any{"name": "prod", "labels": map[string]any{"env": "prod"}})
+	var created types.NamespaceCreation
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &created)
+	if created.Name != "prod" || created.Labels["env"] != "prod" {
+		t.Errorf("unexpected result %+v", created)
+	}
+
+	// 受保护的命名空间即使确认也被拒绝
+	for _, name := range []string{"kube-system", "prod"} {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_namespace", Arguments: map[string]any{"name": name, "confirm": true}})
+		if err != nil || !result.IsError {
+			t.Fatalf("%s: expected an error, got %v %+v", name, err, result)
+		}
+		var details ToolErrorResult
+		data, _ := json.Marshal(result.StructuredContent)
+		if json.Unmarshal(data, &details); details.Error.Reason != "NamespaceProtected" {
+			t.Errorf("%s: expected NamespaceProtected, got %s", name, data)
+		}
+	}
+
+	// 未确认时不删除
+	args := map[string]any{"name": "shop", "wait": true}
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_namespace", Arguments: args})
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "deletion of namespace shop and everything in it on cluster mock") {
+		t.Fatalf("expected a confirmation error, got %v %+v", err, result)
+	}
+
+	args["confirm"] = true
+	result = callTool(t, session, "delete_namespace", args)
+	var deleted types.NamespaceDeletion
+	data, _ = json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &deleted)
+	if !deleted.Terminated || deleted.Workloads["deployments"] == 0 || deleted.Workloads["pods"] == 0 {
+		t.Errorf("expected the namespace to be deleted with its workloads reported, got %+v", deleted)
+	}
+}
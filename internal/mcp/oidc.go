@@ -0,0 +1,495 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Headers carrying the verified OIDC identity from AuthMiddleware to the MCP handlers,
+// like the client certificate headers. AuthMiddleware removes any copies sent by the client.
+// 将已验证的 OIDC 身份从 AuthMiddleware 传递给 MCP 处理器，与客户端证书请求头相同，
+// AuthMiddleware 会先删除客户端自行发送的同名请求头
+const (
+	oidcUserHeader   = "X-K8s-Mcp-Oidc-User"
+	oidcGroupsHeader = "X-K8s-Mcp-Oidc-Groups"
+)
+
+const (
+	// oidcClockSkew is how far exp, nbf and iat may be off from the server's clock
+	// oidcClockSkew exp、nbf 和 iat 与服务器时钟之间允许的偏差
+	oidcClockSkew = time.Minute
+	// jwksCacheTTL is how long the issuer's signing keys are used before they are fetched again
+	// jwksCacheTTL 签名密钥缓存的有效期，过期后重新获取
+	jwksCacheTTL = time.Hour
+	// jwksMinRefresh limits fetches triggered by tokens signed with an unknown key
+	// jwksMinRefresh 限制因未知签名密钥触发的获取频率
+	jwksMinRefresh = 10 * time.Second
+	// oidcFetchTimeout bounds fetching the discovery document or the key set
+	// oidcFetchTimeout 限制获取 discovery 文档或密钥集的时间
+	oidcFetchTimeout = 10 * time.Second
+)
+
+// OIDCConfig configures bearer token validation as JWTs issued by an OIDC provider
+// OIDCConfig 配置将 bearer token 作为 OIDC 提供方签发的 JWT 进行验证
+type OIDCConfig struct {
+	// IssuerURL must equal the iss claim; the keys are found through its discovery document
+	// IssuerURL 必须与 iss 声明一致，签名密钥通过其 discovery 文档获取
+	IssuerURL string
+	// ClientID must be one of the aud claim values
+	// ClientID 必须是 aud 声明中的一个值
+	ClientID string
+	// UsernameClaim holds the user name (default "sub")
+	// UsernameClaim 保存用户名的声明（默认 "sub"）
+	UsernameClaim string
+	// GroupsClaim holds the groups as a string or a list of strings (optional)
+	// GroupsClaim 保存组的声明，可以是字符串或字符串列表（可选）
+	GroupsClaim string
+}
+
+// oidcVerifier validates JWTs against an OIDC issuer. The discovery document and the
+// key set are fetched on first use, so the server starts while the issuer is down; keys
+// are cached for jwksCacheTTL and fetched again early when a token names an unknown key,
+// which is how issuers rotate keys. Fetches run without holding the lock and concurrent
+// callers share one fetch.
+// oidcVerifier 根据 OIDC 提供方验证 JWT。discovery 文档和密钥集在首次使用时获取，因此提供方不可用时服务器仍能启动；
+// 密钥缓存 jwksCacheTTL，token 使用未知密钥时提前重新获取，以支持提供方轮换密钥。获取时不持有锁，并发的调用者共享同一次获取。
+type oidcVerifier struct {
+	config  OIDCConfig
+	client  *http.Client
+	now     func() time.Time
+	refresh singleflight.Group
+
+	mu      sync.Mutex
+	jwksURI string
+	keys    []signingKey
+	// fetchedAt is the time of the last successful fetch
+	// fetchedAt 是上一次成功获取的时间
+	fetchedAt time.Time
+}
+
+// signingKey is a public key of the issuer's key set
+// signingKey 提供方密钥集中的一个公钥
+type signingKey struct {
+	id  string
+	key crypto.PublicKey
+}
+
+// newOIDCVerifier creates a verifier; nothing is fetched until the first token
+// newOIDCVerifier 创建验证器，收到第一个 token 前不会发起请求
+func newOIDCVerifier(config OIDCConfig) *oidcVerifier {
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	config.IssuerURL = strings.TrimSuffix(config.IssuerURL, "/")
+	return &oidcVerifier{
+		config: config,
+		client: &http.Client{Timeout: oidcFetchTimeout},
+		now:    time.Now,
+	}
+}
+
+// jwtHeader is the JOSE header of a JWT
+// jwtHeader JWT 的 JOSE 头
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verify checks the signature and the iss, aud, exp, nbf and iat claims of token and
+// returns the identity in its username and groups claims. Errors describe why the token
+// was rejected and are safe to return to the client.
+// verify 检查 token 的签名以及 iss、aud、exp、nbf 和 iat 声明，返回其用户名和组声明中的身份。
+// 错误说明拒绝的原因，可以返回给客户端。
+func (v *oidcVerifier) verify(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("token is not a JWT")
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return Identity{}, fmt.Errorf("invalid token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.New("invalid token signature encoding")
+	}
+	keys, err := v.signingKeys(ctx, header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := verifyJWTSignature(header.Alg, parts[0]+"."+parts[1], signature, keys); err != nil {
+		return Identity{}, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("invalid token claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Identity{}, err
+	}
+	return v.identity(claims)
+}
+
+// checkClaims checks the issuer, audience and validity period of the token
+// checkClaims 检查 token 的签发方、受众和有效期
+func (v *oidcVerifier) checkClaims(claims map[string]interface{}) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != v.config.IssuerURL {
+		return fmt.Errorf("token issuer %q does not match %s", iss, v.config.IssuerURL)
+	}
+	if !audienceContains(claims["aud"], v.config.ClientID) {
+		return fmt.Errorf("token audience does not include %s", v.config.ClientID)
+	}
+
+	now := v.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(oidcClockSkew)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(oidcClockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if iat, ok := claims["iat"].(float64); ok && now.Add(oidcClockSkew).Before(time.Unix(int64(iat), 0)) {
+		return errors.New("token was issued in the future")
+	}
+	return nil
+}
+
+// identity reads the user and groups from the configured claims. Like the Kubernetes
+// API server, the email claim is only accepted when email_verified is not false.
+// identity 从配置的声明中读取用户和组。与 Kubernetes API server 相同，email 声明仅在 email_verified 不为 false 时接受。
+func (v *oidcVerifier) identity(claims map[string]interface{}) (Identity, error) {
+	user, _ := claims[v.config.UsernameClaim].(string)
+	if user == "" {
+		return Identity{}, fmt.Errorf("token has no %s claim", v.config.UsernameClaim)
+	}
+	if v.config.UsernameClaim == "email" {
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return Identity{}, errors.New("token email is not verified")
+		}
+	}
+
+	identity := Identity{User: user}
+	if v.config.GroupsClaim == "" {
+		return identity, nil
+	}
+	switch groups := claims[v.config.GroupsClaim].(type) {
+	case nil:
+	case string:
+		identity.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			name, ok := group.(string)
+			if !ok {
+				return Identity{}, fmt.Errorf("token %s claim must be a string or a list of strings", v.config.GroupsClaim)
+			}
+			identity.Groups = append(identity.Groups, name)
+		}
+	default:
+		return Identity{}, fmt.Errorf("token %s claim must be a string or a list of strings", v.config.GroupsClaim)
+	}
+	return identity, nil
+}
+
+// audienceContains reports whether the aud claim, a string or a list, contains clientID
+// audienceContains 判断 aud 声明（字符串或列表）是否包含 clientID
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, value := range aud {
+			if value == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeJWTPart decodes a base64url JSON part of a JWT into out
+// decodeJWTPart 将 JWT 中 base64url 编码的 JSON 部分解码到 out
+func decodeJWTPart(part string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// jwtAlgorithms maps the supported JWS algorithms to their hash; HMAC and "none" are
+// deliberately missing
+// jwtAlgorithms 将支持的 JWS 算法映射到其哈希算法，有意不支持 HMAC 和 "none"
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// verifyJWTSignature checks signature against any of keys whose type matches alg
+// verifyJWTSignature 使用与 alg 类型匹配的任一密钥验证签名
+func verifyJWTSignature(alg, signingInput string, signature []byte, keys []signingKey) error {
+	hash, ok := jwtAlgorithms[alg]
+	if !ok {
+		return fmt.Errorf("unsupported token signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	for _, k := range keys {
+		switch key := k.key.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if strings.HasPrefix(alg, "ES") && len(signature) == 2*size {
+				r := new(big.Int).SetBytes(signature[:size])
+				s := new(big.Int).SetBytes(signature[size:])
+				if ecdsa.Verify(key, digest, r, s) {
+					return nil
+				}
+			}
+		}
+	}
+	return errors.New("invalid token signature")
+}
+
+// signingKeys returns the issuer's keys with the given ID, or all keys when the token
+// names none. Unknown IDs fetch the key set again, at most once per jwksMinRefresh after a
+// successful fetch. When the cached keys are only past jwksCacheTTL they are still used
+// and refreshed in the background, so a slow issuer doesn't delay logins with known keys;
+// ctx only bounds how long the caller waits for a fetch it needs.
+// signingKeys 返回提供方中具有给定 ID 的密钥，token 未指定 ID 时返回全部密钥。未知的 ID 会重新获取密钥集，
+// 成功获取后每 jwksMinRefresh 最多一次。缓存的密钥仅超过 jwksCacheTTL 时仍会使用并在后台刷新，
+// 避免缓慢的提供方延迟使用已知密钥的登录；ctx 只限制调用者等待所需获取的时间。
+func (v *oidcVerifier) signingKeys(ctx context.Context, kid string) ([]signingKey, error) {
+	v.mu.Lock()
+	now := v.now()
+	matching := v.matchingKeys(kid)
+	stale := v.fetchedAt.IsZero() || now.Sub(v.fetchedAt) > jwksCacheTTL
+	due := v.fetchedAt.IsZero() || now.Sub(v.fetchedAt) > jwksMinRefresh
+	v.mu.Unlock()
+
+	if len(matching) > 0 {
+		if stale {
+			v.refresh.DoChan(jwksRefreshKey, v.fetchKeys)
+		}
+		return matching, nil
+	}
+	if !due {
+		return nil, fmt.Errorf("token signing key %q is not in the issuer's key set", kid)
+	}
+
+	select {
+	case result := <-v.refresh.DoChan(jwksRefreshKey, v.fetchKeys):
+		if result.Err != nil {
+			return nil, result.Err
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", ctx.Err())
+	}
+
+	v.mu.Lock()
+	matching = v.matchingKeys(kid)
+	v.mu.Unlock()
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("token signing key %q is not in the issuer's key set", kid)
+	}
+	return matching, nil
+}
+
+// jwksRefreshKey is the singleflight key of key set fetches
+// jwksRefreshKey 是密钥集获取在 singleflight 中使用的键
+const jwksRefreshKey = "jwks"
+
+// matchingKeys returns the cached keys with the given ID, or all of them when kid is empty
+// matchingKeys 返回缓存中具有给定 ID 的密钥，kid 为空时返回全部
+func (v *oidcVerifier) matchingKeys(kid string) []signingKey {
+	if kid == "" {
+		return v.keys
+	}
+	var matching []signingKey
+	for _, k := range v.keys {
+		if k.id == kid {
+			matching = append(matching, k)
+		}
+	}
+	return matching
+}
+
+// fetchKeys fetches the key set, finding its URL through the discovery document first.
+// It uses its own context bounded by oidcFetchTimeout, so a cancelled request doesn't fail
+// the fetch the other callers share; the cache is only updated when the fetch succeeds.
+// fetchKeys 获取密钥集，首次获取时先通过 discovery 文档找到其 URL。使用受 oidcFetchTimeout 限制的独立 context，
+// 因此某个请求被取消不会使其他调用者共享的获取失败；只有获取成功时才更新缓存。
+func (v *oidcVerifier) fetchKeys() (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcFetchTimeout)
+	defer cancel()
+
+	v.mu.Lock()
+	jwksURI := v.jwksURI
+	v.mu.Unlock()
+	if jwksURI == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, v.config.IssuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != v.config.IssuerURL {
+			return nil, fmt.Errorf("OIDC discovery document issuer %q does not match %s", discovery.Issuer, v.config.IssuerURL)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, errors.New("OIDC discovery document has no jwks_uri")
+		}
+		jwksURI = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	keys := make([]signingKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Keys that are not for signatures or of unsupported types are skipped
+		// 跳过非签名用途或类型不支持的密钥
+		if key, ok := jwk.publicKey(); ok {
+			keys = append(keys, signingKey{id: jwk.Kid, key: key})
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.jwksURI, v.keys, v.fetchedAt = jwksURI, keys, v.now()
+	return nil, nil
+}
+
+// getJSON fetches url and decodes its JSON body into out
+// getJSON 获取 url 并将 JSON 响应解码到 out
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// jsonWebKey is an RSA or EC public key of a JWKS
+// jsonWebKey JWKS 中的 RSA 或 EC 公钥
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwkCurves maps the JWK crv names to their curves
+// jwkCurves 将 JWK 的 crv 名称映射到椭圆曲线
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// publicKey returns the key, or false for keys not used for signatures or not parseable
+// publicKey 返回公钥；非签名用途或无法解析的密钥返回 false
+func (k jsonWebKey) publicKey() (crypto.PublicKey, bool) {
+	if k.Use != "" && k.Use != "sig" {
+		return nil, false
+	}
+	decode := func(s string) (*big.Int, bool) {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(data) == 0 {
+			return nil, false
+		}
+		return new(big.Int).SetBytes(data), true
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, okN := decode(k.N)
+		e, okE := decode(k.E)
+		if !okN || !okE || !e.IsInt64() {
+			return nil, false
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, true
+	case "EC":
+		curve, ok := jwkCurves[k.Crv]
+		x, okX := decode(k.X)
+		y, okY := decode(k.Y)
+		if !ok || !okX || !okY || !curve.IsOnCurve(x, y) {
+			return nil, false
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, true
+	}
+	return nil, false
+}
+
+// setOIDCIdentityHeaders replaces the OIDC identity headers of r with identity
+// setOIDCIdentityHeaders 使用 identity 替换 r 的 OIDC 身份请求头
+func setOIDCIdentityHeaders(r *http.Request, identity Identity) {
+	r.Header.Set(oidcUserHeader, identity.User)
+	for _, group := range identity.Groups {
+		r.Header.Add(oidcGroupsHeader, group)
+	}
+}
+
+// headerOIDCIdentity reads the OIDC identity set by AuthMiddleware
+// headerOIDCIdentity 读取 AuthMiddleware 设置的 OIDC 身份
+func headerOIDCIdentity(header http.Header) (Identity, bool) {
+	user := header.Get(oidcUserHeader)
+	if user == "" {
+		return Identity{}, false
+	}
+	return Identity{User: user, Groups: header.Values(oidcGroupsHeader)}, true
+}
+
+// writeInvalidToken rejects the request with 401 and a WWW-Authenticate header
+// describing the error, as RFC 6750 asks of bearer token resources
+// writeInvalidToken 按 RFC 6750 的要求以 401 和描述错误的 WWW-Authenticate 头拒绝请求
+func writeInvalidToken(w http.ResponseWriter, err error) {
+	description := strings.ReplaceAll(err.Error(), `"`, "'")
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="k8s-mcp", error="invalid_token", error_description="%s"`, description))
+	http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+}
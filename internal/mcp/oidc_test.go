@@ -0,0 +1,436 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// fakeIssuer 模拟 OIDC 提供方，提供 discovery 文档和可替换的 JWKS，并记录 JWKS 的获取次数
+type fakeIssuer struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	keys   []map[string]string
+	// fetches 为 JWKS 的获取次数
+	fetches int
+	// failures 为接下来返回 500 的 JWKS 请求数
+	failures int
+	// block 不为 nil 时 JWKS 请求等待其关闭后才返回
+	block chan struct{}
+}
+
+// newFakeIssuer 启动假提供方，初始密钥集为 keys
+func newFakeIssuer(t *testing.T, keys ...map[string]string) *fakeIssuer {
+	t.Helper()
+	issuer := &fakeIssuer{keys: keys}
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": issuer.server.URL, "jwks_uri": issuer.server.URL + "/keys"})
+		case "/keys":
+			issuer.mu.Lock()
+			issuer.fetches++
+			block, fail := issuer.block, issuer.failures > 0
+			if fail {
+				issuer.failures--
+			}
+			issuer.mu.Unlock()
+			if block != nil {
+				<-block
+			}
+			if fail {
+				http.Error(w, "unavailable", http.StatusInternalServerError)
+				return
+			}
+			issuer.mu.Lock()
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": issuer.keys})
+			issuer.mu.Unlock()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// setKeys 替换密钥集，模拟密钥轮换
+func (f *fakeIssuer) setKeys(keys ...map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+// fetchCount 返回 JWKS 的获取次数
+func (f *fakeIssuer) fetchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches
+}
+
+// b64 使用 base64url（无填充）编码
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// rsaJWK 返回 RSA 公钥的 JWK
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+// ecJWK 返回 P-256 公钥的 JWK
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))}
+}
+
+// signJWT 使用 key 手工签发 JWT，key 为 *rsa.PrivateKey (RS256) 或 P-256 的 *ecdsa.PrivateKey (ES256)；
+// key 为 nil 时签名为空
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + b64(signature)
+}
+
+// oidcTestKeys 测试用的 RSA 和 EC 签名密钥
+type oidcTestKeys struct {
+	rsa   *rsa.PrivateKey
+	ec    *ecdsa.PrivateKey
+	other *rsa.PrivateKey
+}
+
+// newOIDCTestKeys 生成测试密钥
+func newOIDCTestKeys(t *testing.T) oidcTestKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	return oidcTestKeys{rsa: rsaKey, ec: ecKey, other: other}
+}
+
+// claimsFor 返回由 issuer 签发给 k8s-mcp、一小时后过期的声明，overrides 覆盖或删除（值为 nil）其中的声明
+func claimsFor(issuer string, overrides map[string]interface{}) map[string]interface{} {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":    issuer,
+		"aud":    "k8s-mcp",
+		"sub":    "alice",
+		"groups": []string{"team-a", "oncall"},
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+	return claims
+}
+
+// TestOIDCVerify 测试签名、签发方、受众和有效期的校验，以及用户名和组的提取
+func TestOIDCVerify(t *testing.T) {
+	keys := newOIDCTestKeys(t)
+	issuer := newFakeIssuer(t, rsaJWK("rsa-1", &keys.rsa.PublicKey), ecJWK("ec-1", &keys.ec.PublicKey))
+	verifier := newOIDCVerifier(OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "k8s-mcp", GroupsClaim: "groups"})
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Identity
+		wantErr string
+	}{
+		{
+			name:  "RS256",
+			token: signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, nil)),
+			want:  Identity{User: "alice", Groups: []string{"team-a", "oncall"}},
+		},
+		{
+			name:  "ES256 with audience list and string groups",
+			token: signJWT(t, "ES256", "ec-1", keys.ec, claimsFor(issuer.server.URL, map[string]interface{}{"aud": []string{"other", "k8s-mcp"}, "groups": "team-b"})),
+			want:  Identity{User: "alice", Groups: []string{"team-b"}},
+		},
+		{
+			name:  "expired within clock skew",
+			token: signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})),
+			want:  Identity{User: "alice", Groups: []string{"team-a", "oncall"}},
+		},
+		{
+			name:    "expired",
+			token:   signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})),
+			wantErr: "token expired",
+		},
+		{
+			name:    "not valid yet",
+			token:   signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"nbf": now.Add(5 * time.Minute).Unix()})),
+			wantErr: "not valid yet",
+		},
+		{
+			name:    "wrong audience",
+			token:   signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"aud": "dashboard"})),
+			wantErr: "audience does not include k8s-mcp",
+		},
+		{
+			name:    "wrong issuer",
+			token:   signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor("https://evil.example.com", nil)),
+			wantErr: "does not match",
+		},
+		{
+			name:    "signed by another key",
+			token:   signJWT(t, "RS256", "rsa-1", keys.other, claimsFor(issuer.server.URL, nil)),
+			wantErr: "invalid token signature",
+		},
+		{
+			name:    "alg none",
+			token:   signJWT(t, "none", "rsa-1", nil, claimsFor(issuer.server.URL, nil)),
+			wantErr: "unsupported token signing algorithm",
+		},
+		{
+			name:    "missing username claim",
+			token:   signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"sub": nil})),
+			wantErr: "no sub claim",
+		},
+		{
+			name:    "not a JWT",
+			token:   "static-token",
+			wantErr: "not a JWT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.verify(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify failed: %v", err)
+			}
+			if !reflect.DeepEqual(identity, tt.want) {
+				t.Errorf("got identity %+v, want %+v", identity, tt.want)
+			}
+		})
+	}
+
+	// 密钥集被缓存
+	if issuer.fetchCount() != 1 {
+		t.Errorf("expected the key set to be fetched once, got %d", issuer.fetchCount())
+	}
+}
+
+// TestOIDCKeyRotation 测试 token 使用未知密钥时重新获取密钥集，且获取频率受限
+func TestOIDCKeyRotation(t *testing.T) {
+	keys := newOIDCTestKeys(t)
+	issuer := newFakeIssuer(t, rsaJWK("old", &keys.rsa.PublicKey))
+	verifier := newOIDCVerifier(OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "k8s-mcp"})
+	now := time.Now()
+	verifier.now = func() time.Time { return now }
+
+	if _, err := verifier.verify(context.Background(), signJWT(t, "RS256", "old", keys.rsa, claimsFor(issuer.server.URL, nil))); err != nil {
+		t.Fatalf("verify with the old key failed: %v", err)
+	}
+
+	issuer.setKeys(rsaJWK("new", &keys.other.PublicKey))
+	rotated := signJWT(t, "RS256", "new", keys.other, claimsFor(issuer.server.URL, nil))
+
+	// 距上次获取不足 jwksMinRefresh，不会重新获取
+	if _, err := verifier.verify(context.Background(), rotated); err == nil || !strings.Contains(err.Error(), `"new" is not in the issuer's key set`) {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+	if issuer.fetchCount() != 1 {
+		t.Fatalf("expected no refetch within jwksMinRefresh, got %d fetches", issuer.fetchCount())
+	}
+
+	now = now.Add(jwksMinRefresh + time.Second)
+	if _, err := verifier.verify(context.Background(), rotated); err != nil {
+		t.Fatalf("verify with the rotated key failed: %v", err)
+	}
+	if issuer.fetchCount() != 2 {
+		t.Errorf("expected the key set to be fetched again, got %d fetches", issuer.fetchCount())
+	}
+}
+
+// TestOIDCKeyFetch 测试并发的调用者共享一次获取且获取期间不持有锁，被取消的请求不影响共享的获取，
+// 获取失败后不会在 jwksMinRefresh 内拒绝所有 token
+func TestOIDCKeyFetch(t *testing.T) {
+	keys := newOIDCTestKeys(t)
+	issuer := newFakeIssuer(t, rsaJWK("rsa-1", &keys.rsa.PublicKey))
+	issuer.block = make(chan struct{})
+	verifier := newOIDCVerifier(OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "k8s-mcp"})
+	token := signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, nil))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := verifier.verify(context.Background(), token)
+			errs <- err
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for issuer.fetchCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 获取进行中时，被取消的请求立即返回
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := verifier.verify(cancelled, token); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("expected the cancelled request to stop waiting, got %v", err)
+	}
+
+	close(issuer.block)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("verify failed: %v", err)
+		}
+	}
+	if issuer.fetchCount() != 1 {
+		t.Errorf("expected concurrent callers to share one fetch, got %d fetches", issuer.fetchCount())
+	}
+
+	// 首次获取失败后，下一个 token 立即重试
+	issuer = newFakeIssuer(t, rsaJWK("rsa-1", &keys.rsa.PublicKey))
+	issuer.failures = 1
+	verifier = newOIDCVerifier(OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "k8s-mcp"})
+	token = signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, nil))
+	if _, err := verifier.verify(context.Background(), token); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("expected the failed fetch to be reported, got %v", err)
+	}
+	if _, err := verifier.verify(context.Background(), token); err != nil {
+		t.Errorf("expected a retry after a failed fetch, got %v", err)
+	}
+}
+
+// TestOIDCAuthMiddleware 测试过期或受众错误的 token 返回带 WWW-Authenticate 的 401，
+// 有效 token 的用户和组用于身份模拟，伪造的 OIDC 身份请求头被删除
+func TestOIDCAuthMiddleware(t *testing.T) {
+	keys := newOIDCTestKeys(t)
+	issuer := newFakeIssuer(t, rsaJWK("rsa-1", &keys.rsa.PublicKey))
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var review authorizationv1.SelfSubjectAccessReview
+		json.Unmarshal(body, &review)
+		review.Status.Allowed = r.Header.Get("Impersonate-User") == "alice"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	defer apiServer.Close()
+
+	s := NewServer("server-token", &Options{OIDC: &OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "k8s-mcp", GroupsClaim: "groups"}})
+	if err := s.clusterManager.AddCluster("test", &rest.Config{Host: apiServer.URL}); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	handler := s.CreateHTTPHandler()
+
+	rejected := map[string]string{
+		"expired":        signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"wrong audience": signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, map[string]interface{}{"aud": "dashboard"})),
+	}
+	for name, token := range rejected {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		challenge := rec.Header().Get("WWW-Authenticate")
+		if rec.Code != http.StatusUnauthorized || !strings.Contains(challenge, `error="invalid_token"`) {
+			t.Errorf("%s: expected 401 with an invalid_token challenge, got %d %q", name, rec.Code, challenge)
+		}
+	}
+
+	// 静态 token 仍然有效，且伪造的 OIDC 身份请求头被删除
+	var seen http.Header
+	middleware := s.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer server-token")
+	req.Header.Set(oidcUserHeader, "admin")
+	middleware.ServeHTTP(httptest.NewRecorder(), req)
+	if seen == nil {
+		t.Fatal("expected the static token to be accepted")
+	}
+	if _, ok := headerOIDCIdentity(seen); ok {
+		t.Errorf("expected forged OIDC headers to be removed, got %v", seen)
+	}
+
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	token := signJWT(t, "RS256", "rsa-1", keys.rsa, claimsFor(issuer.server.URL, nil))
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: headerTransport{token: token}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("connect with a valid token failed: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "check_permissions",
+		Arguments: map[string]any{"verb": "delete", "resource": "pods", "namespace": "team-a"},
+	})
+	if err != nil {
+		t.Fatalf("check_permissions failed: %v", err)
+	}
+	var decoded PermissionsResult
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &decoded)
+	if !decoded.Allowed || decoded.User != "alice" || !reflect.DeepEqual(decoded.Groups, []string{"team-a", "oncall"}) {
+		t.Errorf("unexpected result for the OIDC user: %+v", decoded)
+	}
+}
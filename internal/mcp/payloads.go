@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultPayloadLogLimit is the number of bytes of each payload logged with LogPayloads
+// DefaultPayloadLogLimit 是启用 LogPayloads 时每个消息体记录的字节数
+const DefaultPayloadLogLimit = 2048
+
+// payloadLogger logs the JSON-RPC messages exchanged with clients at debug level, with
+// secret-looking values redacted and each payload cut to limit bytes
+// payloadLogger 以 debug 级别记录与客户端交换的 JSON-RPC 消息，疑似敏感的值会被脱敏，每个消息体截断到 limit 字节
+type payloadLogger struct {
+	log   logger.Logger
+	limit int
+}
+
+// newPayloadLogger creates a payload logger; limit 0 uses DefaultPayloadLogLimit
+// newPayloadLogger 创建消息体日志记录器；limit 为 0 时使用 DefaultPayloadLogLimit
+func newPayloadLogger(log logger.Logger, limit int) *payloadLogger {
+	if limit <= 0 {
+		limit = DefaultPayloadLogLimit
+	}
+	return &payloadLogger{log: log, limit: limit}
+}
+
+// receivingMiddleware logs every message received from a client and the response sent back
+// receivingMiddleware 记录从客户端收到的每条消息以及返回的响应
+func (p *payloadLogger) receivingMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		session := sessionIDOf(req)
+		p.log.Debug("Received MCP message", "method", method, "session", session, "params", p.format(req.GetParams()))
+		result, err := next(ctx, method, req)
+		if err != nil {
+			p.log.Debug("Sent MCP error", "method", method, "session", session, "error", p.truncate(err.Error()))
+		} else if result != nil {
+			p.log.Debug("Sent MCP result", "method", method, "session", session, "result", p.format(result))
+		}
+		return result, err
+	}
+}
+
+// sendingMiddleware logs the requests and notifications sent to clients. Log notifications
+// are skipped: they carry server log entries, including these ones
+// sendingMiddleware 记录发送给客户端的请求和通知。跳过日志通知：它们携带的正是服务器日志，包括这些条目
+func (p *payloadLogger) sendingMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "notifications/message" {
+			return next(ctx, method, req)
+		}
+		session := sessionIDOf(req)
+		p.log.Debug("Sent MCP message", "method", method, "session", session, "params", p.format(req.GetParams()))
+		result, err := next(ctx, method, req)
+		if err == nil && result != nil {
+			p.log.Debug("Received MCP result", "method", method, "session", session, "result", p.format(result))
+		}
+		return result, err
+	}
+}
+
+// format renders a payload as JSON with secret-looking values redacted, cut to the limit
+// format 将消息体渲染为 JSON，脱敏疑似敏感的值并截断到上限
+func (p *payloadLogger) format(payload interface{}) string {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf("<%T: %v>", payload, err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Sprintf("<%T: %v>", payload, err)
+	}
+	data, err = marshalNoEscape(redactPayload(decoded))
+	if err != nil {
+		return fmt.Sprintf("<%T: %v>", payload, err)
+	}
+	return p.truncate(string(data))
+}
+
+// truncate cuts text to the limit, noting how many bytes were dropped
+// truncate 将文本截断到上限，并注明丢弃的字节数
+func (p *payloadLogger) truncate(text string) string {
+	if len(text) <= p.limit {
+		return text
+	}
+	kept := truncateText(text, p.limit)
+	return fmt.Sprintf("%s... (%d more bytes)", kept, len(text)-len(kept))
+}
+
+// redactPayload redacts like redactArguments and also inside strings holding a JSON
+// document, such as the text content of tool results
+// redactPayload 与 redactArguments 相同地脱敏，同时处理内容为 JSON 文档的字符串，例如工具结果的文本内容
+func redactPayload(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if isSensitiveKey(k) {
+				redacted[k] = auditRedacted
+				continue
+			}
+			redacted[k] = redactPayload(item)
+		}
+		return redacted
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = redactPayload(item)
+		}
+		return items
+	case string:
+		trimmed := strings.TrimSpace(val)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return val
+		}
+		var embedded interface{}
+		if err := json.Unmarshal([]byte(trimmed), &embedded); err != nil {
+			return val
+		}
+		if data, err := marshalNoEscape(redactPayload(embedded)); err == nil {
+			return string(data)
+		}
+	}
+	return v
+}
+
+// sessionIDOf returns the ID of the session a message belongs to ("" for none)
+// sessionIDOf 返回消息所属会话的 ID（没有时为 ""）
+func sessionIDOf(req mcp.Request) string {
+	if session := req.GetSession(); session != nil {
+		return session.ID()
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+)
+
+// recordingLogger 记录所有日志条目，供测试检查
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *recordingLogger) record(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf("%s %s %v", level, msg, keysAndValues))
+}
+
+func (l *recordingLogger) Debug(msg string, kv ...interface{})  { l.record("debug", msg, kv) }
+func (l *recordingLogger) Info(msg string, kv ...interface{})   { l.record("info", msg, kv) }
+func (l *recordingLogger) Warn(msg string, kv ...interface{})   { l.record("warn", msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...interface{})  { l.record("error", msg, kv) }
+func (l *recordingLogger) With(kv ...interface{}) logger.Logger { return l }
+
+// output 返回所有已记录的日志
+func (l *recordingLogger) output() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.entries, "\n")
+}
+
+// TestPayloadLogging 测试默认不记录消息体，启用 LogPayloads 后以 debug 级别记录脱敏并截断的消息体，
+// 任何情况下伪造的 token 都不会出现在日志中
+func TestPayloadLogging(t *testing.T) {
+	const fakeToken = "fake-token-5f3a9c"
+	args := map[string]any{
+		"resource_type": "configmaps",
+		"name":          "app",
+		"token":         fakeToken,
+		"labels":        map[string]any{"authorization": "Bearer " + fakeToken},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "defaults", want: nil},
+		{name: "enabled", opts: Options{LogPayloads: true}, want: []string{
+			"debug Received MCP message [method tools/call",
+			"debug Sent MCP result [method tools/call",
+			`"token":"[REDACTED]"`,
+			`"authorization":"[REDACTED]"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			opts := tt.opts
+			opts.Logger = log
+			s := NewServer("test-token", &opts)
+			s.RegisterTools()
+			session := connectTestClient(t, s, nil)
+			callWithArguments(t, session, "check_references", args)
+
+			output := log.output()
+			if strings.Contains(output, fakeToken) {
+				t.Fatalf("the token leaked into the log:\n%s", output)
+			}
+			if tt.want == nil && strings.Contains(output, "MCP message") {
+				t.Errorf("expected no payloads to be logged by default:\n%s", output)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected %q in the log:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+// TestPayloadLoggerFormat 测试嵌入在字符串中的 JSON 文档同样被脱敏，超出上限的消息体被截断
+func TestPayloadLoggerFormat(t *testing.T) {
+	p := newPayloadLogger(&recordingLogger{}, 0)
+	got := p.format(map[string]any{"content": []any{map[string]any{"type": "text", "text": `{"password": "hunter2", "name": "db"}`}}})
+	if strings.Contains(got, "hunter2") || !strings.Contains(got, `\"password\":\"[REDACTED]\"`) || !strings.Contains(got, `\"name\":\"db\"`) {
+		t.Errorf("unexpected payload %s", got)
+	}
+	if got := p.format(map[string]any{"text": "not json {password: hunter2}"}); !strings.Contains(got, "hunter2") {
+		t.Errorf("expected plain text to be kept, got %s", got)
+	}
+
+	p = newPayloadLogger(&recordingLogger{}, 32)
+	got = p.format(map[string]any{"data": strings.Repeat("x", 100)})
+	if !strings.HasPrefix(got, `{"data":"xxxx`) || !strings.HasSuffix(got, "... (79 more bytes)") {
+		t.Errorf("expected the payload to be cut at 32 bytes, got %s", got)
+	}
+}
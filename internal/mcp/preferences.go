@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// preferencesFileVersion is the layout version written to the state file
+// preferencesFileVersion 为写入状态文件的格式版本
+const preferencesFileVersion = 1
+
+// Preferences are the session defaults remembered for one caller across server restarts
+// Preferences 是为单个调用者在服务器重启后保留的会话默认值
+type Preferences struct {
+	Cluster   string    `json:"cluster,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// preferencesFile is the layout of the state file:
+//
+//	{"version": 1, "callers": {"user:alice@example.com": {"cluster": "prod", "namespace": "shop", "updated_at": "..."}},
+//	 "queries": {"user:alice@example.com": {"prod-payments": {"name": "prod-payments", "tool": "list_pods", ...}}}}
+//
+// preferencesFile 是状态文件的格式
+type preferencesFile struct {
+	Version int                              `json:"version"`
+	Callers map[string]Preferences           `json:"callers"`
+	Queries map[string]map[string]SavedQuery `json:"queries,omitempty"`
+}
+
+// preferenceStore keeps the preferences and saved queries of every caller in memory and
+// rewrites the state file atomically on every change. Writes are serialized, so
+// concurrent sessions never interleave partial files.
+// preferenceStore 在内存中保存所有调用者的偏好和已保存的查询，每次修改时原子地重写状态文件。写入是串行的，并发会话不会产生交错的文件内容
+type preferenceStore struct {
+	mu      sync.Mutex
+	path    string
+	callers map[string]Preferences
+	queries map[string]map[string]SavedQuery
+	logger  logger.Logger
+}
+
+// loadPreferenceStore reads the state file at path. A missing file starts empty; an
+// unreadable or corrupted one is logged, moved aside to path.corrupt and also starts
+// empty, so a bad state file never blocks startup.
+// loadPreferenceStore 读取 path 处的状态文件。文件不存在时从空状态开始；无法读取或已损坏时记录日志，
+// 将其移动到 path.corrupt 并同样从空状态开始，使损坏的状态文件不会阻止启动
+func loadPreferenceStore(path string, log logger.Logger) *preferenceStore {
+	store := &preferenceStore{path: path, callers: map[string]Preferences{}, queries: map[string]map[string]SavedQuery{}, logger: log}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Info("No session state file yet, starting fresh", "path", path)
+		return store
+	}
+	if err == nil {
+		var file preferencesFile
+		if err = json.Unmarshal(data, &file); err == nil && file.Version != preferencesFileVersion {
+			err = fmt.Errorf("unsupported version %d", file.Version)
+		}
+		if err == nil {
+			for key, prefs := range file.Callers {
+				store.callers[key] = prefs
+			}
+			for key, queries := range file.Queries {
+				store.queries[key] = queries
+			}
+			log.Info("Session state restored", "path", path, "callers", len(store.callers), "query_owners", len(store.queries))
+			return store
+		}
+	}
+
+	log.Warn("Ignoring unreadable session state file, starting fresh", "path", path, "error", err)
+	if renameErr := os.Rename(path, path+".corrupt"); renameErr != nil && !errors.Is(renameErr, fs.ErrNotExist) {
+		log.Warn("Failed to move the session state file aside", "path", path, "error", renameErr)
+	}
+	return store
+}
+
+// get returns the preferences remembered for a caller
+// get 返回为调用者保存的偏好
+func (ps *preferenceStore) get(key string) (Preferences, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	prefs, ok := ps.callers[key]
+	return prefs, ok
+}
+
+// set remembers a caller's preferences and writes the state file; empty preferences
+// forget the caller
+// set 保存调用者的偏好并写入状态文件，偏好为空时删除该调用者
+func (ps *preferenceStore) set(key string, prefs Preferences) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if prefs.Cluster == "" && prefs.Namespace == "" {
+		delete(ps.callers, key)
+	} else {
+		ps.callers[key] = prefs
+	}
+	return ps.writeLocked()
+}
+
+// writeLocked writes the state file; ps.mu must be held
+// writeLocked 写入状态文件，调用方必须持有 ps.mu
+func (ps *preferenceStore) writeLocked() error {
+	data, err := json.MarshalIndent(preferencesFile{Version: preferencesFileVersion, Callers: ps.callers, Queries: ps.queries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session state: %w", err)
+	}
+	return writeFileAtomic(ps.path, append(data, '\n'), 0o600)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it over
+// path, so readers and a crash mid-write never see a partial file
+// writeFileAtomic 将数据写入 path 所在目录的临时文件后重命名覆盖 path，读取方和写入过程中的崩溃都不会看到不完整的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	return nil
+}
+
+// preferencesKeyKey is the context key of the calling caller's preferences key
+// preferencesKeyKey 调用者偏好键的 context 键
+type preferencesKeyKey struct{}
+
+// preferencesKey identifies whose preferences a request reads and writes: the
+// authenticated user when the request has one, else callerIdentity (a fingerprint of
+// the bearer token, or "local" for stdio), never the ephemeral session ID
+// preferencesKey 标识请求读写谁的偏好：请求有已认证用户时为该用户，否则为 callerIdentity (bearer token 的指纹，
+// stdio 时为 "local")，而不是临时的会话 ID
+func (s *Server) preferencesKey(req mcp.Request) string {
+	if identity, ok := s.requestIdentity(req); ok {
+		return "user:" + identity.User
+	}
+	return callerIdentity(req)
+}
+
+// restorePreferences applies the caller's remembered cluster and namespace to a new
+// session. A cluster that no longer exists or a namespace no longer allowed is skipped,
+// leaving the session on the server defaults.
+// restorePreferences 将调用者保存的集群和命名空间应用到新会话。集群已不存在或命名空间不再允许时跳过，会话使用服务器默认值
+func (s *Server) restorePreferences(id, key string) {
+	prefs, ok := s.preferences.get(key)
+	if !ok {
+		return
+	}
+	if prefs.Cluster != "" && !containsString(s.clusterManager.GetClusters(), prefs.Cluster) {
+		s.logger.Warn("Not restoring session preferences for a cluster that no longer exists", "caller", key, "cluster", prefs.Cluster)
+		return
+	}
+	if prefs.Namespace != "" && !s.clusterManager.NamespacePolicy().Allows(prefs.Namespace) {
+		s.logger.Warn("Not restoring a session namespace that is no longer allowed", "caller", key, "namespace", prefs.Namespace)
+		prefs.Namespace = ""
+	}
+	s.sessions.update(id, func(state *sessionState) {
+		state.cluster = prefs.Cluster
+		state.namespace = prefs.Namespace
+	})
+}
+
+// rememberPreferences saves the session's cluster and namespace for its caller. A
+// failed write is logged; the session keeps its choice either way.
+// rememberPreferences 为调用者保存会话的集群和命名空间。写入失败时记录日志，会话仍保留其选择
+func (s *Server) rememberPreferences(ctx context.Context, state sessionState) {
+	if s.preferences == nil {
+		return
+	}
+	key, ok := ctx.Value(preferencesKeyKey{}).(string)
+	if !ok {
+		return
+	}
+	prefs := Preferences{Cluster: state.cluster, Namespace: state.namespace, UpdatedAt: time.Now().UTC()}
+	if err := s.preferences.set(key, prefs); err != nil {
+		s.logger.Warn("Failed to save session preferences", "caller", key, "error", err)
+	}
+}
+
+// containsString reports whether values contains value
+// containsString 判断 values 是否包含 value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
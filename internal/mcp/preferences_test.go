@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"k8s.io/client-go/rest"
+)
+
+// newStateServer 创建使用 stateFile 的测试服务器并添加 clusters
+func newStateServer(t *testing.T, stateFile string, clusters ...string) *Server {
+	t.Helper()
+	s := NewServer("test-token", &Options{StateFile: stateFile})
+	for _, name := range clusters {
+		if err := s.clusterManager.AddCluster(name, &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+			t.Fatalf("AddCluster(%s) failed: %v", name, err)
+		}
+	}
+	s.clusterManager.SwitchCluster(clusters[0])
+	s.RegisterTools()
+	return s
+}
+
+// TestPreferenceStoreConcurrentWrites 测试多个会话并发写入后状态文件完整且可以重新加载，且不留下临时文件
+func TestPreferenceStoreConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	store := loadPreferenceStore(path, logger.Get())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("user:user-%d", i)
+			for j := 0; j < 5; j++ {
+				if err := store.set(key, Preferences{Cluster: "prod", Namespace: fmt.Sprintf("ns-%d-%d", i, j)}); err != nil {
+					t.Errorf("set failed: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	reloaded := loadPreferenceStore(path, logger.Get())
+	if len(reloaded.callers) != 20 {
+		t.Fatalf("expected 20 callers after reload, got %d", len(reloaded.callers))
+	}
+	for i := 0; i < 20; i++ {
+		prefs, ok := reloaded.get(fmt.Sprintf("user:user-%d", i))
+		if !ok || prefs.Namespace != fmt.Sprintf("ns-%d-4", i) {
+			t.Errorf("expected the last write of user-%d, got %+v", i, prefs)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file to remain, got %d entries", len(entries))
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the state file to be private, got %v %v", info.Mode(), err)
+	}
+
+	// 偏好为空时删除该调用者
+	if err := reloaded.set("user:user-0", Preferences{}); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, ok := loadPreferenceStore(path, logger.Get()).get("user:user-0"); ok {
+		t.Errorf("expected empty preferences to forget the caller")
+	}
+}
+
+// TestPreferencesRestore 测试重启后同一调用者的新会话恢复其集群和命名空间，已不存在的集群不会被恢复
+func TestPreferencesRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	first := connectTestClient(t, newStateServer(t, path, "prod", "staging"), nil)
+	callSessionTool(t, first, "switch_cluster", map[string]any{"cluster_name": "staging"})
+	if got, isError := callSessionTool(t, first, "set_namespace", map[string]any{"namespace": "api"}); isError || got.Namespace != "api" {
+		t.Fatalf("unexpected set_namespace result: %+v", got)
+	}
+
+	// 重启：新服务器使用同一状态文件
+	restarted := newStateServer(t, path, "prod", "staging")
+	session := connectTestClient(t, restarted, nil)
+	want := SessionContextResult{Cluster: "staging", Namespace: "api", Source: "session"}
+	if got, _ := callSessionTool(t, session, "get_current_cluster", nil); got != want {
+		t.Errorf("expected the restored preferences %+v, got %+v", want, got)
+	}
+	if current := restarted.clusterManager.GetCurrentCluster(); current != "prod" {
+		t.Errorf("restoring changed the server's current cluster to %s", current)
+	}
+
+	// 保存的集群已不存在时使用服务器默认值
+	session = connectTestClient(t, newStateServer(t, path, "prod"), nil)
+	if got, _ := callSessionTool(t, session, "get_current_cluster", nil); got.Cluster != "prod" || got.Source != "server" {
+		t.Errorf("expected the server defaults for a removed cluster, got %+v", got)
+	}
+}
+
+// TestPreferencesCorruptStateFile 测试损坏的状态文件不会阻止启动：文件被移动到 .corrupt 并从空状态开始
+func TestPreferencesCorruptStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "callers": {`), 0o600); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	s := newStateServer(t, path, "prod", "staging")
+	if len(s.preferences.callers) != 0 {
+		t.Errorf("expected an empty store, got %+v", s.preferences.callers)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("expected the corrupted file to be kept aside: %v", err)
+	}
+
+	session := connectTestClient(t, s, nil)
+	if got, _ := callSessionTool(t, session, "get_current_cluster", nil); got.Source != "server" {
+		t.Errorf("expected the server defaults, got %+v", got)
+	}
+	callSessionTool(t, session, "switch_cluster", map[string]any{"cluster_name": "staging"})
+	if prefs, ok := loadPreferenceStore(path, logger.Get()).get("local"); !ok || prefs.Cluster != "staging" {
+		t.Errorf("expected a fresh state file with the new preferences, got %+v", prefs)
+	}
+}
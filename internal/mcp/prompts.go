@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// generateKubectlCommandsPrompt translates an intent into kubectl commands
+// generateKubectlCommandsPrompt 将意图转换为 kubectl 命令
+var generateKubectlCommandsPrompt = &mcp.Prompt{
+	Name:        "generate_kubectl_commands",
+	Title:       "Generate kubectl commands",
+	Description: "Translate an intent into the exact kubectl commands for the resolved cluster and namespace, with warnings for destructive commands and a verification step",
+	Arguments: []*mcp.PromptArgument{
+		{Name: "intent", Description: "What you want to do, e.g. \"restart the payments deployment and watch the rollout\"", Required: true},
+		{Name: "namespace", Description: "Namespace to target (defaults to the kubeconfig context namespace)"},
+		{Name: "cluster_name", Description: "Cluster to target (defaults to the current cluster)"},
+	},
+}
+
+// RegisterPrompts registers all prompts
+// RegisterPrompts 注册所有 prompt
+func (s *Server) RegisterPrompts() {
+	s.mcpServer.AddPrompt(generateKubectlCommandsPrompt, s.handleGenerateKubectlCommands)
+}
+
+// checkPromptArguments returns an InvalidParams error naming every missing required argument
+// checkPromptArguments 缺少必需参数时返回 InvalidParams 错误，并列出所有缺少的参数
+func checkPromptArguments(prompt *mcp.Prompt, args map[string]string) error {
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: fmt.Sprintf("prompt %s: missing required arguments: %s", prompt.Name, strings.Join(missing, ", ")),
+	}
+}
+
+// resolvePromptTarget resolves the cluster and namespace a prompt talks about,
+// using the same defaults as the tools
+// resolvePromptTarget 使用与工具相同的默认值解析 prompt 涉及的集群和命名空间
+func (s *Server) resolvePromptTarget(ctx context.Context, args map[string]string) (string, string) {
+	cluster := s.resolveClusterName(ctx, args["cluster_name"])
+	namespace, _ := s.resolveNamespace(ctx, args["namespace"], false, cluster)
+	return cluster, namespace
+}
+
+// handleGenerateKubectlCommands handles the generate_kubectl_commands prompt
+// handleGenerateKubectlCommands 处理 generate_kubectl_commands prompt
+func (s *Server) handleGenerateKubectlCommands(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	if err := checkPromptArguments(generateKubectlCommandsPrompt, req.Params.Arguments); err != nil {
+		return nil, err
+	}
+
+	cluster, namespace := s.resolvePromptTarget(ctx, req.Params.Arguments)
+	clusterFlag := "--cluster " + cluster
+	if cluster == "" {
+		cluster = "(none loaded)"
+		clusterFlag = "the current kubeconfig context"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Translate the following intent into the exact kubectl commands to run.\n\n")
+	fmt.Fprintf(&sb, "Intent: %s\n\n", strings.TrimSpace(req.Params.Arguments["intent"]))
+	fmt.Fprintf(&sb, "Target cluster: %s\nTarget namespace: %s\n\n", cluster, namespace)
+	sb.WriteString("Requirements:\n")
+	fmt.Fprintf(&sb, "1. Output every command in a shell code block, one per line, always passing %s and -n %s explicitly (omit -n only for cluster-scoped resources) so nothing depends on the caller's kubeconfig defaults.\n", clusterFlag, namespace)
+	sb.WriteString("2. Prefer declarative and reversible commands. Do not invent resource names; if a name is unknown, first show the kubectl get command that finds it.\n")
+	sb.WriteString("3. Mark each destructive or disruptive command (delete, drain, cordon, scale to zero, rollout restart, replace --force, patch of a running workload) with a WARNING line above it explaining the impact, and suggest a --dry-run=server variant where available.\n")
+	sb.WriteString("4. Finish with a verification step: the commands that confirm the intent took effect (for example kubectl rollout status, kubectl get with -w, or kubectl describe) and what output to expect.\n")
+	sb.WriteString("5. Keep explanations short; the commands are the answer.\n")
+	if language := s.sessionLanguage(req.Session); language != "" {
+		sb.WriteString("\n" + localize(language, msgAnswerLanguage) + "\n")
+	}
+
+	return &mcp.GetPromptResult{
+		Description: generateKubectlCommandsPrompt.Description,
+		Messages: []*mcp.PromptMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: sb.String()},
+		}},
+	}, nil
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// promptText 返回 prompt 结果中第一条消息的文本
+func promptText(t *testing.T, result *mcp.GetPromptResult) string {
+	t.Helper()
+	if len(result.Messages) == 0 {
+		t.Fatalf("prompt returned no messages")
+	}
+	text, ok := result.Messages[0].Content.(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+// TestGenerateKubectlCommandsPrompt 测试参数替换以及集群和命名空间的默认值解析
+func TestGenerateKubectlCommandsPrompt(t *testing.T) {
+	s := newTestServer(t, "prod")
+	s.clusterManager.SetDefaultNamespace("prod", "payments")
+	s.RegisterPrompts()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		args map[string]string
+		want []string
+	}{
+		{
+			name: "explicit",
+			args: map[string]string{"intent": "restart the web deployment", "namespace": "frontend", "cluster_name": "staging"},
+			want: []string{"Intent: restart the web deployment", "Target cluster: staging", "Target namespace: frontend", "--cluster staging and -n frontend"},
+		},
+		{
+			name: "defaults",
+			args: map[string]string{"intent": "scale api to 3"},
+			want: []string{"Intent: scale api to 3", "Target cluster: prod", "Target namespace: payments", "WARNING", "verification step"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "generate_kubectl_commands", Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("GetPrompt failed: %v", err)
+			}
+			text := promptText(t, result)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in prompt:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
+
+// TestPromptsRequireArguments 测试所有 prompt 缺少必需参数时返回错误
+func TestPromptsRequireArguments(t *testing.T) {
+	s := newTestServer(t, "prod")
+	s.RegisterPrompts()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(prompts.Prompts) == 0 {
+		t.Fatalf("expected registered prompts")
+	}
+	for _, prompt := range prompts.Prompts {
+		for _, arg := range prompt.Arguments {
+			if !arg.Required {
+				continue
+			}
+			// Provide every other required argument so only this one is missing
+			// 提供其他所有必需参数，只缺少当前参数
+			args := map[string]string{}
+			for _, other := range prompt.Arguments {
+				if other.Required && other.Name != arg.Name {
+					args[other.Name] = "x"
+				}
+			}
+			_, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: prompt.Name, Arguments: args})
+			var wireErr *jsonrpc.Error
+			if !errors.As(err, &wireErr) || wireErr.Code != jsonrpc.CodeInvalidParams || !strings.Contains(wireErr.Message, arg.Name) {
+				t.Errorf("%s without %s: expected InvalidParams error, got %v", prompt.Name, arg.Name, err)
+			}
+		}
+	}
+}
+
+// TestPromptsOverHTTP 测试通过 streamable HTTP 连接的客户端可以看到 prompt 及其参数，并且服务器声明了 prompts 和 resources 能力
+func TestPromptsOverHTTP(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.RegisterTools()
+	s.RegisterResources()
+	s.RegisterPrompts()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: headerTransport{token: "test-token"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer session.Close()
+
+	capabilities := session.InitializeResult().Capabilities
+	if capabilities.Prompts == nil || capabilities.Resources == nil {
+		t.Errorf("expected prompts and resources capabilities, got %+v", capabilities)
+	}
+
+	result, err := session.ListPrompts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(result.Prompts) != 1 || result.Prompts[0].Name != generateKubectlCommandsPrompt.Name {
+		t.Fatalf("unexpected prompts %+v", result.Prompts)
+	}
+	var args []string
+	for _, arg := range result.Prompts[0].Arguments {
+		args = append(args, arg.Name)
+	}
+	if strings.Join(args, ",") != "intent,namespace,cluster_name" || !result.Prompts[0].Arguments[0].Required {
+		t.Errorf("unexpected arguments %v", args)
+	}
+}
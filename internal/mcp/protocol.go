@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// supportedProtocolVersions are the MCP protocol versions this server speaks, newest first
+// supportedProtocolVersions 是服务器支持的 MCP 协议版本，按从新到旧排列
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// codeServerNotInitialized is the JSON-RPC error code for requests sent before initialize,
+// the code LSP uses for the same error
+// codeServerNotInitialized 是在 initialize 之前发送请求时的 JSON-RPC 错误码，与 LSP 中同一错误的错误码相同
+const codeServerNotInitialized = -32002
+
+// protocolVersionPattern matches the date-based protocol versions
+// protocolVersionPattern 匹配基于日期的协议版本
+var protocolVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// negotiateProtocolVersion picks the protocol version for a client that requested
+// requested: the same version when supported, or the newest supported version when the
+// client is newer than this server, since clients support the versions before their own.
+// A client older than every supported version, or with an unknown version, has no
+// version in common with the server and gets an error carrying the supported versions.
+// negotiateProtocolVersion 为请求 requested 版本的客户端选择协议版本：支持时使用相同版本；
+// 客户端比服务器新时使用服务器支持的最新版本，因为客户端也支持其之前的版本。
+// 客户端比所有支持的版本都旧或版本未知时与服务器没有共同版本，返回带有支持版本列表的错误。
+func negotiateProtocolVersion(requested string) (string, *jsonrpc.Error) {
+	for _, version := range supportedProtocolVersions {
+		if requested == version {
+			return version, nil
+		}
+	}
+	if protocolVersionPattern.MatchString(requested) && requested > supportedProtocolVersions[0] {
+		return supportedProtocolVersions[0], nil
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"supported": supportedProtocolVersions,
+		"requested": requested,
+	})
+	return "", &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Unsupported protocol version",
+		Data:    data,
+	}
+}
+
+// protocolMiddleware negotiates the protocol version of initialize requests and replies
+// with the negotiated version, or with an error when there is none in common
+// protocolMiddleware 为 initialize 请求协商协议版本并在响应中返回协商结果，没有共同版本时返回错误
+func (s *Server) protocolMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		params, ok := req.GetParams().(*mcp.InitializeParams)
+		if method != "initialize" || !ok || params == nil {
+			return next(ctx, method, req)
+		}
+
+		version, rpcErr := negotiateProtocolVersion(params.ProtocolVersion)
+		if rpcErr != nil {
+			s.logger.Warn("Rejected initialize with unsupported protocol version",
+				"requested", params.ProtocolVersion,
+				"supported", supportedProtocolVersions)
+			return nil, rpcErr
+		}
+		if version != params.ProtocolVersion {
+			s.logger.Info("Downgraded protocol version", "requested", params.ProtocolVersion, "negotiated", version)
+		}
+
+		// The SDK records the params as the session state, so it sees the negotiated version too
+		// SDK 将 params 记录为会话状态，因此它看到的也是协商后的版本
+		params.ProtocolVersion = version
+		result, err := next(ctx, method, req)
+		if initResult, ok := result.(*mcp.InitializeResult); ok && err == nil {
+			initResult.ProtocolVersion = version
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,154 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// TestNegotiateProtocolVersion 测试协议版本协商：相同版本、降级到最新版本以及没有共同版本
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"2025-06-18", "2025-06-18"},
+		{"2025-03-26", "2025-03-26"},
+		{"2024-11-05", "2024-11-05"},
+		{"2025-11-25", "2025-06-18"},
+		{"2099-01-01", "2025-06-18"},
+		{"2024-10-07", ""},
+		{"1.0.0", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, rpcErr := negotiateProtocolVersion(tt.requested)
+		if got != tt.want {
+			t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.want)
+		}
+		if (rpcErr != nil) != (tt.want == "") {
+			t.Errorf("negotiateProtocolVersion(%q): unexpected error %v", tt.requested, rpcErr)
+		}
+		if rpcErr == nil {
+			continue
+		}
+		var data struct {
+			Supported []string `json:"supported"`
+			Requested string   `json:"requested"`
+		}
+		if err := json.Unmarshal(rpcErr.Data, &data); err != nil {
+			t.Fatalf("invalid error data %s: %v", rpcErr.Data, err)
+		}
+		if rpcErr.Code != jsonrpc.CodeInvalidParams || !reflect.DeepEqual(data.Supported, supportedProtocolVersions) || data.Requested != tt.requested {
+			t.Errorf("unexpected error for %q: %d %s", tt.requested, rpcErr.Code, rpcErr.Data)
+		}
+	}
+}
+
+// rpcResponse 是测试中解析的 JSON-RPC 响应
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	} `json:"result"`
+	Error *jsonrpc.Error `json:"error"`
+}
+
+// postRPC 向 MCP HTTP 处理器发送原始 JSON-RPC 消息，返回响应和分配的会话 ID。
+// 响应可能是 JSON 或 SSE 流，SSE 时取第一条 data；通知返回空响应
+func postRPC(t *testing.T, url, sessionID, body string) (rpcResponse, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var data string
+	scanner := bufio.NewScanner(resp.Body)
+	// tools/list 的响应是一整行，超过 Scanner 默认的 64KB 上限
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+			break
+		}
+		if strings.HasPrefix(line, "{") {
+			data = line
+			break
+		}
+	}
+	var result rpcResponse
+	if resp.StatusCode == http.StatusAccepted {
+		// 通知没有响应
+		return result, ""
+	}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("expected a JSON-RPC response, got status %d %q", resp.StatusCode, data)
+	}
+	return result, resp.Header.Get(sessionIDHeader)
+}
+
+// initializeBody 返回请求指定协议版本的 initialize 消息
+func initializeBody(version string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`, version)
+}
+
+// TestInitializeProtocolNegotiation 测试 initialize 降级到共同版本、没有共同版本时返回错误，以及初始化之前的请求被拒绝
+func TestInitializeProtocolNegotiation(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.RegisterTools()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	defer httpServer.Close()
+
+	// 只实现 2024-11-05 的客户端得到相同版本，之后的请求可以正常处理
+	resp, sessionID := postRPC(t, httpServer.URL, "", initializeBody("2024-11-05"))
+	if resp.Error != nil || resp.Result.ProtocolVersion != "2024-11-05" {
+		t.Fatalf("expected protocol version 2024-11-05, got %+v", resp)
+	}
+	if sessionID == "" {
+		t.Fatal("expected a session ID")
+	}
+	postRPC(t, httpServer.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp, _ = postRPC(t, httpServer.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	if resp.Error != nil {
+		t.Errorf("tools/list after initialize failed: %+v", resp.Error)
+	}
+
+	// 比服务器新的客户端降级到服务器支持的最新版本
+	resp, _ = postRPC(t, httpServer.URL, "", initializeBody("2099-01-01"))
+	if resp.Error != nil || resp.Result.ProtocolVersion != supportedProtocolVersions[0] {
+		t.Errorf("expected downgrade to %s, got %+v", supportedProtocolVersions[0], resp)
+	}
+
+	// 没有共同版本
+	resp, _ = postRPC(t, httpServer.URL, "", initializeBody("2024-01-01"))
+	if resp.Error == nil || resp.Error.Code != jsonrpc.CodeInvalidParams || resp.Error.Message != "Unsupported protocol version" {
+		t.Fatalf("expected unsupported protocol version error, got %+v", resp)
+	}
+	if !strings.Contains(string(resp.Error.Data), `"supported":["2025-06-18","2025-03-26","2024-11-05"]`) {
+		t.Errorf("expected supported versions in error data, got %s", resp.Error.Data)
+	}
+
+	// 初始化之前的请求
+	resp, _ = postRPC(t, httpServer.URL, "", `{"jsonrpc":"2.0","id":"early","method":"tools/list"}`)
+	if resp.Error == nil || resp.Error.Code != codeServerNotInitialized || string(resp.ID) != `"early"` {
+		t.Errorf("expected server not initialized error, got %+v", resp)
+	}
+}
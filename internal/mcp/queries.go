@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxSavedQueries is the number of queries one caller may save
+// maxSavedQueries 单个调用者最多可以保存的查询数
+const maxSavedQueries = 100
+
+// queryToolNames are the tools that cannot be saved as a query: the tools managing saved
+// queries, and batch_call, which would otherwise be nested by running the query in a batch
+// queryToolNames 是不能保存为查询的工具：管理已保存查询的工具，以及 batch_call (否则在批量调用中执行该查询会造成嵌套)
+var queryToolNames = map[string]bool{
+	"save_query":   true,
+	"run_query":    true,
+	"list_queries": true,
+	"delete_query": true,
+	batchToolName:  true,
+}
+
+// queryNamePattern matches valid query names, e.g. prod-payments-pods
+// queryNamePattern 匹配有效的查询名称，例如 prod-payments-pods
+var queryNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+var (
+	// errQueryExists is returned when saving under a taken name without overwrite
+	// errQueryExists 未指定 overwrite 时保存到已存在的名称返回的错误
+	errQueryExists = errors.New("query already exists")
+	// errTooManyQueries is returned when a caller already has maxSavedQueries queries
+	// errTooManyQueries 调用者已有 maxSavedQueries 个查询时返回的错误
+	errTooManyQueries = fmt.Errorf("at most %d queries can be saved", maxSavedQueries)
+)
+
+// SavedQuery is a named tool call template of one caller
+// SavedQuery 是单个调用者保存的命名工具调用模板
+type SavedQuery struct {
+	Name        string                 `json:"name"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	// Mutating is set when the tool modifies the cluster; running it then needs write mode
+	// Mutating 在工具会修改集群时为 true，此时运行需要开启写模式
+	Mutating bool      `json:"mutating,omitempty"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+// savedQueries returns the queries of a caller, sorted by name
+// savedQueries 返回调用者的查询，按名称排序
+func (ps *preferenceStore) savedQueries(key string) []SavedQuery {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	queries := make([]SavedQuery, 0, len(ps.queries[key]))
+	for _, query := range ps.queries[key] {
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries
+}
+
+// savedQuery returns one query of a caller
+// savedQuery 返回调用者的一个查询
+func (ps *preferenceStore) savedQuery(key, name string) (SavedQuery, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	query, ok := ps.queries[key][name]
+	return query, ok
+}
+
+// saveQuery stores a query of a caller and writes the state file. A query with the same
+// name is only replaced when overwrite is set; replaced reports whether one was.
+// saveQuery 保存调用者的查询并写入状态文件。只有 overwrite 为 true 时才替换同名查询，replaced 表示是否发生了替换
+func (ps *preferenceStore) saveQuery(key string, query SavedQuery, overwrite bool) (replaced bool, err error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	queries := ps.queries[key]
+	_, replaced = queries[query.Name]
+	switch {
+	case replaced && !overwrite:
+		return false, errQueryExists
+	case !replaced && len(queries) >= maxSavedQueries:
+		return false, errTooManyQueries
+	}
+	if queries == nil {
+		queries = map[string]SavedQuery{}
+		ps.queries[key] = queries
+	}
+	queries[query.Name] = query
+	return replaced, ps.writeLocked()
+}
+
+// deleteQuery removes a query of a caller and writes the state file; it reports whether
+// the query existed
+// deleteQuery 删除调用者的查询并写入状态文件，返回该查询是否存在
+func (ps *preferenceStore) deleteQuery(key, name string) (bool, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.queries[key][name]; !ok {
+		return false, nil
+	}
+	delete(ps.queries[key], name)
+	if len(ps.queries[key]) == 0 {
+		delete(ps.queries, key)
+	}
+	return true, ps.writeLocked()
+}
+
+// mergeQueryArguments returns the saved arguments with the overrides applied on top: an
+// override replaces the saved value of its key and a null override removes the key
+// mergeQueryArguments 返回在已保存参数之上应用覆盖值后的参数：覆盖值替换同名键的已保存值，null 覆盖值删除该键
+func mergeQueryArguments(saved, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(saved)+len(overrides))
+	for key, value := range saved {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// containsRedacted reports whether a value, or any value nested in it, was redacted when
+// saved, including file contents and Secret manifests redacted in part
+// containsRedacted 判断值或其中嵌套的值是否在保存时被脱敏，包括被替换的文件内容和部分脱敏的 Secret 清单
+func containsRedacted(v interface{}) bool {
+	switch val := v.(type) {
+	case string:
+		return strings.Contains(val, auditRedacted)
+	case map[string]interface{}:
+		for _, item := range val {
+			if containsRedacted(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if containsRedacted(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SaveQueryResult represents the result of save_query tool
+// SaveQueryResult 表示 save_query 工具的结果
+type SaveQueryResult struct {
+	// Query is the query as stored, with secret-looking arguments redacted
+	// Query 为保存的查询，疑似敏感的参数已脱敏
+	Query SavedQuery `json:"query"`
+	// Replaced is true when a query with the same name was overwritten
+	// Replaced 在覆盖了同名查询时为 true
+	Replaced bool `json:"replaced"`
+}
+
+// handleSaveQuery handles save_query tool
+// handleSaveQuery 处理 save_query 工具
+func (s *Server) handleSaveQuery(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string                 `json:"name"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Overwrite   bool                   `json:"overwrite,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	SaveQueryResult,
+	error,
+) {
+	if !queryNamePattern.MatchString(input.Name) {
+		return toolError(fmt.Sprintf("invalid query name %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", input.Name)), SaveQueryResult{}, nil
+	}
+	if queryToolNames[input.Tool] {
+		return toolError(fmt.Sprintf("%s cannot be saved as a query", input.Tool)), SaveQueryResult{}, nil
+	}
+
+	// Only tools available to this session can be saved; tools that modify the cluster
+	// are only registered in write mode
+	// 只能保存当前会话可用的工具；修改集群的工具只在写模式下注册
+	tools, err := s.listTools(ctx, req.Session)
+	if err != nil {
+		return nil, SaveQueryResult{}, fmt.Errorf("failed to list tools: %w", err)
+	}
+	var tool *mcp.Tool
+	for _, candidate := range tools {
+		if candidate.Name == input.Tool {
+			tool = candidate
+			break
+		}
+	}
+	if tool == nil {
+		return toolError(fmt.Sprintf("unknown tool %q", input.Tool)), SaveQueryResult{}, nil
+	}
+
+	query := SavedQuery{
+		Name:        input.Name,
+		Tool:        input.Tool,
+		Description: input.Description,
+		Mutating:    tool.Annotations != nil && !tool.Annotations.ReadOnlyHint,
+		SavedAt:     time.Now().UTC(),
+	}
+	if len(input.Arguments) > 0 {
+		query.Arguments = redactArguments(input.Arguments)
+	}
+	replaced, err := s.preferences.saveQuery(s.preferencesKey(req), query, input.Overwrite)
+	switch {
+	case errors.Is(err, errQueryExists):
+		return toolError(fmt.Sprintf("query %q already exists; pass overwrite=true to replace it", input.Name)), SaveQueryResult{}, nil
+	case errors.Is(err, errTooManyQueries):
+		return toolError(fmt.Sprintf("%v; delete_query removes one", err)), SaveQueryResult{}, nil
+	case err != nil:
+		return nil, SaveQueryResult{}, err
+	}
+	return nil, SaveQueryResult{Query: query, Replaced: replaced}, nil
+}
+
+// RunQueryResult represents the result of run_query tool. The content of the call is the
+// content of the tool it ran.
+// RunQueryResult 表示 run_query 工具的结果。调用的 content 为所执行工具的 content
+type RunQueryResult struct {
+	Name string `json:"name"`
+	Tool string `json:"tool"`
+	// Arguments are the merged arguments the tool ran with, secret-looking values redacted
+	// Arguments 为工具实际使用的合并后参数，疑似敏感的值已脱敏
+	Arguments         map[string]interface{} `json:"arguments,omitempty"`
+	IsError           bool                   `json:"is_error"`
+	StructuredContent interface{}            `json:"structured_content,omitempty"`
+}
+
+// handleRunQuery handles run_query tool. The tool runs through the full request chain
+// (auditing, history, impersonation, confirmation) like a direct call.
+// handleRunQuery 处理 run_query 工具。工具与直接调用一样经过完整的请求处理链 (审计、调用历史、身份模拟、确认)
+func (s *Server) handleRunQuery(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	RunQueryResult,
+	error,
+) {
+	query, ok := s.preferences.savedQuery(s.preferencesKey(req), input.Name)
+	if !ok {
+		return toolError(fmt.Sprintf("no saved query %q; list_queries shows the saved queries", input.Name)), RunQueryResult{}, nil
+	}
+	if queryToolNames[query.Tool] {
+		return toolError(fmt.Sprintf("query %q runs %s, which cannot be run as a query", query.Name, query.Tool)), RunQueryResult{}, nil
+	}
+	if query.Mutating && !s.allowWrite {
+		return toolError(fmt.Sprintf("query %q runs %s, which modifies the cluster, and write tools are disabled", query.Name, query.Tool)), RunQueryResult{}, nil
+	}
+
+	args := mergeQueryArguments(query.Arguments, input.Arguments)
+	for key, value := range args {
+		if containsRedacted(value) {
+			return toolError(fmt.Sprintf("argument %s of query %q was redacted when it was saved; pass it in arguments", key, query.Name)), RunQueryResult{}, nil
+		}
+	}
+
+	result, err := s.dispatchToolCall(ctx, req, query.Tool, args)
+	if err != nil {
+		return nil, RunQueryResult{}, err
+	}
+	return &mcp.CallToolResult{Content: result.Content, IsError: result.IsError}, RunQueryResult{
+		Name:              query.Name,
+		Tool:              query.Tool,
+		Arguments:         redactArguments(args),
+		IsError:           result.IsError,
+		StructuredContent: result.StructuredContent,
+	}, nil
+}
+
+// ListQueriesResult represents the result of list_queries tool
+// ListQueriesResult 表示 list_queries 工具的结果
+type ListQueriesResult struct {
+	// Queries are the caller's saved queries, sorted by name
+	// Queries 为调用者保存的查询，按名称排序
+	Queries []SavedQuery `json:"queries"`
+}
+
+// handleListQueries handles list_queries tool
+// handleListQueries 处理 list_queries 工具
+func (s *Server) handleListQueries(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+	*mcp.CallToolResult,
+	ListQueriesResult,
+	error,
+) {
+	return nil, ListQueriesResult{Queries: s.preferences.savedQueries(s.preferencesKey(req))}, nil
+}
+
+// DeleteQueryResult represents the result of delete_query tool
+// DeleteQueryResult 表示 delete_query 工具的结果
+type DeleteQueryResult struct {
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+// handleDeleteQuery handles delete_query tool
+// handleDeleteQuery 处理 delete_query 工具
+func (s *Server) handleDeleteQuery(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name string `json:"name"`
+}) (
+	*mcp.CallToolResult,
+	DeleteQueryResult,
+	error,
+) {
+	deleted, err := s.preferences.deleteQuery(s.preferencesKey(req), input.Name)
+	if err != nil {
+		return nil, DeleteQueryResult{}, err
+	}
+	if !deleted {
+		return toolError(fmt.Sprintf("no saved query %q", input.Name)), DeleteQueryResult{}, nil
+	}
+	return nil, DeleteQueryResult{Name: input.Name, Deleted: true}, nil
+}
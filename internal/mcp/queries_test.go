@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newQueryServer 创建使用 stateFile 和模拟集群的测试服务器并连接一个客户端
+func newQueryServer(t *testing.T, stateFile string, allowWrite bool) *mcp.ClientSession {
+	t.Helper()
+	s := NewServer("test-token", &Options{StateFile: stateFile, AllowWrite: allowWrite})
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	return connectTestClient(t, s, nil)
+}
+
+// callQueryTool 调用工具，返回结构化结果解码后的 T 以及是否为工具错误
+func callQueryTool[T any](t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (T, *mcp.CallToolResult) {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	var decoded T
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &decoded)
+	return decoded, result
+}
+
+// TestMergeQueryArguments 测试覆盖值优先、null 删除已保存的键，且不修改已保存的参数
+func TestMergeQueryArguments(t *testing.T) {
+	saved := map[string]interface{}{"namespace": "payments", "cluster_name": "prod", "tail_lines": 100}
+	merged := mergeQueryArguments(saved, map[string]interface{}{"cluster_name": "staging", "tail_lines": nil, "previous": true})
+	want := map[string]interface{}{"namespace": "payments", "cluster_name": "staging", "previous": true}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("expected %v, got %v", want, merged)
+	}
+	if saved["cluster_name"] != "prod" || saved["tail_lines"] != 100 {
+		t.Errorf("the saved arguments were modified: %v", saved)
+	}
+	if got := mergeQueryArguments(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("expected empty arguments, got %v", got)
+	}
+}
+
+// TestSavedQueries 测试保存、覆盖、运行 (覆盖值优先)、列出和删除查询，以及查询在重启后保留
+func TestSavedQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	session := newQueryServer(t, path, false)
+
+	saved, result := callQueryTool[SaveQueryResult](t, session, "save_query", map[string]any{
+		"name":        "web-pods",
+		"tool":        "list_pods",
+		"arguments":   map[string]any{"namespace": "default", "all_namespaces": false},
+		"description": "pods of the web app",
+	})
+	if result.IsError || saved.Replaced || saved.Query.Tool != "list_pods" || saved.Query.Mutating {
+		t.Fatalf("unexpected save_query result %+v (%s)", saved, resultText(result))
+	}
+
+	// 同名查询只有在 overwrite=true 时才被替换
+	_, result = callQueryTool[SaveQueryResult](t, session, "save_query", map[string]any{"name": "web-pods", "tool": "list_pods"})
+	if !result.IsError || !strings.Contains(resultText(result), "pass overwrite=true") {
+		t.Errorf("expected saving over an existing query to fail, got %s", resultText(result))
+	}
+	saved, result = callQueryTool[SaveQueryResult](t, session, "save_query", map[string]any{
+		"name":      "web-pods",
+		"tool":      "list_pods",
+		"arguments": map[string]any{"namespace": "kube-system", "all_namespaces": false},
+		"overwrite": true,
+	})
+	if result.IsError || !saved.Replaced {
+		t.Fatalf("expected the query to be replaced, got %+v (%s)", saved, resultText(result))
+	}
+
+	// 覆盖值优先于已保存的参数，null 删除已保存的参数
+	run, result := callQueryTool[RunQueryResult](t, session, "run_query", map[string]any{
+		"name":      "web-pods",
+		"arguments": map[string]any{"namespace": "default", "all_namespaces": nil},
+	})
+	if result.IsError || run.Tool != "list_pods" || run.IsError {
+		t.Fatalf("unexpected run_query result %+v (%s)", run, resultText(result))
+	}
+	if want := map[string]interface{}{"namespace": "default"}; !reflect.DeepEqual(run.Arguments, want) {
+		t.Errorf("expected the merged arguments %v, got %v", want, run.Arguments)
+	}
+	direct := callTool(t, session, "list_pods", map[string]any{"namespace": "default"})
+	if resultText(result) != resultText(direct) {
+		t.Errorf("expected the content of a direct call, got %s", resultText(result))
+	}
+
+	// 无法保存未知工具或查询工具本身，无法运行不存在的查询
+	for _, args := range []map[string]any{
+		{"name": "unknown", "tool": "no_such_tool"},
+		{"name": "nested", "tool": "run_query"},
+		{"name": "../bad", "tool": "list_pods"},
+	} {
+		if _, result := callQueryTool[SaveQueryResult](t, session, "save_query", args); !result.IsError {
+			t.Errorf("expected save_query %v to fail", args)
+		}
+	}
+	if _, result := callQueryTool[RunQueryResult](t, session, "run_query", map[string]any{"name": "missing"}); !result.IsError {
+		t.Error("expected running a missing query to fail")
+	}
+
+	// 重启后查询仍然存在
+	restarted := newQueryServer(t, path, false)
+	list, _ := callQueryTool[ListQueriesResult](t, restarted, "list_queries", nil)
+	if len(list.Queries) != 1 || list.Queries[0].Name != "web-pods" || list.Queries[0].Arguments["namespace"] != "kube-system" {
+		t.Fatalf("expected the saved query after a restart, got %+v", list.Queries)
+	}
+	if _, result := callQueryTool[RunQueryResult](t, restarted, "run_query", map[string]any{"name": "web-pods"}); result.IsError {
+		t.Errorf("expected the restored query to run, got %s", resultText(result))
+	}
+
+	deleted, result := callQueryTool[DeleteQueryResult](t, restarted, "delete_query", map[string]any{"name": "web-pods"})
+	if result.IsError || !deleted.Deleted {
+		t.Errorf("unexpected delete_query result %+v", deleted)
+	}
+	if queries := loadPreferenceStore(path, logger.Get()).savedQueries("local"); len(queries) != 0 {
+		t.Errorf("expected the deleted query to be gone from the state file, got %+v", queries)
+	}
+}
+
+// TestSavedQueryRedaction 测试疑似敏感的参数在写入状态文件前被脱敏，运行时必须重新传入
+func TestSavedQueryRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	session := newQueryServer(t, path, false)
+
+	saved, result := callQueryTool[SaveQueryResult](t, session, "save_query", map[string]any{
+		"name":      "with-secrets",
+		"tool":      "list_pods",
+		"arguments": map[string]any{"namespace": "default", "api_token": "s3cr3t", "extra": map[string]any{"password": "hunter2"}},
+	})
+	if result.IsError || saved.Query.Arguments["api_token"] != auditRedacted {
+		t.Fatalf("expected the token to be redacted, got %+v (%s)", saved, resultText(result))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read state file: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t") || strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), "with-secrets") {
+		t.Errorf("expected only redacted values in the state file:\n%s", data)
+	}
+
+	_, result = callQueryTool[RunQueryResult](t, session, "run_query", map[string]any{"name": "with-secrets", "arguments": map[string]any{"api_token": "s3cr3t"}})
+	if !result.IsError || !strings.Contains(resultText(result), "argument extra of query \"with-secrets\" was redacted") {
+		t.Errorf("expected the redacted nested argument to be reported, got %s", resultText(result))
+	}
+}
+
+// TestSavedQueryMutating 测试修改集群的工具只能在写模式下保存，且运行时同样需要写模式
+func TestSavedQueryMutating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	writable := newQueryServer(t, path, true)
+
+	saved, result := callQueryTool[SaveQueryResult](t, writable, "save_query", map[string]any{
+		"name":      "cordon",
+		"tool":      "cordon_node",
+		"arguments": map[string]any{"node_name": "node-1", "confirm": true},
+	})
+	if result.IsError || !saved.Query.Mutating {
+		t.Fatalf("expected a mutating query, got %+v (%s)", saved, resultText(result))
+	}
+	if _, result := callQueryTool[RunQueryResult](t, writable, "run_query", map[string]any{"name": "cordon"}); result.IsError {
+		t.Errorf("expected the query to run in write mode, got %s", resultText(result))
+	}
+
+	// 未启用写操作时无法保存，也无法运行之前保存的查询
+	readOnly := newQueryServer(t, path, false)
+	if _, result := callQueryTool[SaveQueryResult](t, readOnly, "save_query", map[string]any{"name": "uncordon", "tool": "uncordon_node"}); !result.IsError {
+		t.Error("expected saving a mutating tool without write mode to fail")
+	}
+	_, result = callQueryTool[RunQueryResult](t, readOnly, "run_query", map[string]any{"name": "cordon"})
+	if !result.IsError || !strings.Contains(resultText(result), "write tools are disabled") {
+		t.Errorf("expected the mutating query to be refused, got %s", resultText(result))
+	}
+}
+
+// TestSavedQueriesPerCaller 测试查询按调用者隔离，同名查询互不影响
+func TestSavedQueriesPerCaller(t *testing.T) {
+	store := loadPreferenceStore(filepath.Join(t.TempDir(), "state.json"), logger.Get())
+	for _, key := range []string{"user:alice", "user:bob"} {
+		if _, err := store.saveQuery(key, SavedQuery{Name: "pods", Tool: "list_pods", Arguments: map[string]interface{}{"namespace": key}}, false); err != nil {
+			t.Fatalf("saveQuery failed: %v", err)
+		}
+	}
+	if query, ok := store.savedQuery("user:alice", "pods"); !ok || query.Arguments["namespace"] != "user:alice" {
+		t.Errorf("expected alice's own query, got %+v", query)
+	}
+	if _, ok := store.savedQuery("token:abc", "pods"); ok {
+		t.Error("expected another caller to see no queries")
+	}
+
+	// 偏好为空时只删除该调用者的偏好，查询保留
+	if err := store.set("user:alice", Preferences{}); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, ok := store.savedQuery("user:alice", "pods"); !ok {
+		t.Error("expected clearing preferences to keep the queries")
+	}
+}
@@ -0,0 +1,33 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// recoverMiddleware turns a panic in any request handler into a JSON-RPC internal error,
+// so one bad request cannot take down the server and all of its sessions
+// recoverMiddleware 将任意请求处理器中的 panic 转换为 JSON-RPC 内部错误，
+// 避免单个错误请求导致整个服务器及其所有会话崩溃
+func (s *Server) recoverMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (result mcp.Result, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger.Error("Recovered from panic in request handler",
+					"method", method,
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()))
+				result = nil
+				err = &jsonrpc.Error{
+					Code:    jsonrpc.CodeInternalError,
+					Message: fmt.Sprintf("internal error while handling %s", method),
+				}
+			}
+		}()
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestRecoverFromHandlerPanic 测试处理器 panic 后返回内部错误，且服务器继续处理后续请求
+func TestRecoverFromHandlerPanic(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.RegisterTools()
+	mcp.AddTool(s.mcpServer, &mcp.Tool{Name: "panic_tool"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, struct{}, error) {
+		var pod *struct{ Name string }
+		_ = pod.Name // nil pointer dereference
+		return nil, struct{}{}, nil
+	})
+	session := connectTestClient(t, s, nil)
+
+	ctx := context.Background()
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "panic_tool"})
+	var wireErr *jsonrpc.Error
+	if !errors.As(err, &wireErr) || wireErr.Code != jsonrpc.CodeInternalError {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+
+	// The session keeps serving requests after the panic
+	// panic 之后会话仍能继续处理请求
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil || len(tools.Tools) == 0 {
+		t.Fatalf("expected server to keep serving, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleCheckReferences handles check_references tool
+// handleCheckReferences 处理 check_references 工具
+func (s *Server) handleCheckReferences(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	ClusterName  string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ConfigReferenceReport,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+
+	report, err := s.resourceOps.CheckReferences(ctx, k8s.ResourceType(input.ResourceType), namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, types.ConfigReferenceReport{}, fmt.Errorf("failed to check references: %w", err)
+	}
+	return nil, *report, nil
+}
+
+// handleDeleteResource handles delete_resource tool. ConfigMaps and Secrets are checked
+// for references before the user is asked, and referenced ones are refused unless force
+// handleDeleteResource 处理 delete_resource 工具。ConfigMap 和 Secret 在询问用户之前检查引用，仍被引用时除非设置 force 否则拒绝删除
+func (s *Server) handleDeleteResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	Force        bool   `json:"force,omitempty"`
+	Confirm      bool   `json:"confirm,omitempty"`
+	ClusterName  string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ResourceDeletion,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	resourceType := k8s.ResourceType(input.ResourceType)
+
+	namespace := input.Namespace
+	action := fmt.Sprintf("deletion of %s %s", resourceType, input.Name)
+	if !k8s.IsClusterScoped(resourceType) {
+		namespace, _ = s.resolveNamespace(ctx, namespace, false, clusterName)
+		action += " in namespace " + namespace
+	}
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+
+	var references *types.ConfigReferenceReport
+	switch resourceType {
+	case k8s.ResourceTypeConfigMaps, k8s.ResourceTypeConfigMap, k8s.ResourceTypeSecrets, k8s.ResourceTypeSecret:
+		report, err := s.resourceOps.CheckReferences(ctx, resourceType, namespace, input.Name, clusterName)
+		if err != nil {
+			return nil, types.ResourceDeletion{}, fmt.Errorf("failed to check references: %w", err)
+		}
+		switch {
+		case report.Referenced && !input.Force:
+			return toolError(fmt.Sprintf("%s %s is still referenced by %s; deleting it breaks them the next time their pods start. Pass force=true to delete it anyway",
+				report.Kind, input.Name, describeConfigReferences(report.References))), types.ResourceDeletion{}, nil
+		case len(report.Skipped) > 0 && !input.Force:
+			return toolError(fmt.Sprintf("cannot verify that %s %s is unused (%s); pass force=true to delete it anyway",
+				report.Kind, input.Name, strings.Join(report.Skipped, "; "))), types.ResourceDeletion{}, nil
+		case report.Referenced:
+			action += ", still referenced by " + describeConfigReferences(report.References)
+		}
+		references = report
+	}
+	if result, err := s.confirmDestructive(ctx, req, action, input.Confirm); result != nil || err != nil {
+		return result, types.ResourceDeletion{}, err
+	}
+
+	result, err := s.resourceOps.DeleteResource(ctx, resourceType, namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, types.ResourceDeletion{}, err
+	}
+	if references != nil && (references.Referenced || len(references.Skipped) > 0) {
+		result.Forced = true
+		result.References = references.References
+		result.Skipped = references.Skipped
+	}
+	return nil, *result, nil
+}
+
+// describeConfigReferences renders referrers as "Kind/name (via, via)", comma separated
+// describeConfigReferences 将引用者描述为 "Kind/name (via, via)"，以逗号分隔
+func describeConfigReferences(references []types.ConfigReference) string {
+	parts := make([]string, 0, len(references))
+	for _, ref := range references {
+		parts = append(parts, fmt.Sprintf("%s/%s (%s)", ref.Kind, ref.Name, strings.Join(ref.Via, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestDeleteResourceReferences 测试 delete_resource 拒绝删除仍被引用的 ConfigMap 并列出引用者，force=true 时删除，
+// 未被引用的 ConfigMap 在确认后删除，以及 check_references 返回相同的引用者
+func TestDeleteResourceReferences(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web", Image: "nginx:1.25"}},
+				Volumes: []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}},
+				}}},
+			}}},
+		},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default"}},
+	)
+	s := NewServer("test-token", &Options{AllowWrite: true})
+	s.clusterManager.AddClientset("test", client)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	result := callTool(t, session, "check_references", map[string]any{"resource_type": "configmaps", "name": "web-config"})
+	var report types.ConfigReferenceReport
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &report)
+	if !report.Referenced || len(report.References) != 1 || report.References[0].Kind != "Deployment" || report.References[0].Name != "web" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	// 仍被引用时即使确认也拒绝删除
+	result, text := callWithArguments(t, session, "delete_resource", map[string]any{"resource_type": "configmaps", "name": "web-config", "confirm": true})
+	if !result.IsError || !strings.Contains(text, "ConfigMap web-config is still referenced by Deployment/web (volume config)") || !strings.Contains(text, "force=true") {
+		t.Fatalf("expected the delete to be refused, got %s", text)
+	}
+	if _, err := client.CoreV1().ConfigMaps("default").Get(ctx, "web-config", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the ConfigMap to be kept, got %v", err)
+	}
+
+	// force=true 时在确认中说明引用者
+	result, text = callWithArguments(t, session, "delete_resource", map[string]any{"resource_type": "configmaps", "name": "web-config", "force": true})
+	if !result.IsError || !strings.Contains(text, "deletion of configmaps web-config in namespace default on cluster test, still referenced by Deployment/web (volume config) requires confirmation") {
+		t.Fatalf("expected a confirmation error naming the referrers, got %s", text)
+	}
+	result = callTool(t, session, "delete_resource", map[string]any{"resource_type": "configmaps", "name": "web-config", "force": true, "confirm": true})
+	var deletion types.ResourceDeletion
+	data, _ = json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &deletion)
+	if !deletion.Forced || len(deletion.References) != 1 || deletion.Namespace != "default" {
+		t.Errorf("expected a forced deletion listing the referrer, got %+v", deletion)
+	}
+	if _, err := client.CoreV1().ConfigMaps("default").Get(ctx, "web-config", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the ConfigMap to be deleted, got %v", err)
+	}
+
+	// 未被引用的 ConfigMap 和其他资源类型只需要确认
+	result = callTool(t, session, "delete_resource", map[string]any{"resource_type": "configmap", "name": "stale", "confirm": true})
+	deletion = types.ResourceDeletion{}
+	data, _ = json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &deletion)
+	if deletion.Forced || len(deletion.References) != 0 {
+		t.Errorf("unexpected result %+v", deletion)
+	}
+	callTool(t, session, "delete_resource", map[string]any{"resource_type": "deployments", "name": "web", "confirm": true})
+	if _, err := client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the Deployment to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Output formats of generate_cluster_report
+// generate_cluster_report 的输出格式
+const (
+	reportFormatMarkdown = "markdown"
+	reportFormatJSON     = "json"
+)
+
+// ClusterReportResult represents the result of generate_cluster_report tool
+// ClusterReportResult 表示 generate_cluster_report 工具的结果
+type ClusterReportResult struct {
+	Report string `json:"report"`
+	Format string `json:"format"`
+}
+
+// handleGenerateClusterReport handles generate_cluster_report tool
+// handleGenerateClusterReport 处理 generate_cluster_report 工具
+func (s *Server) handleGenerateClusterReport(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Format      string `json:"format,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	ClusterReportResult,
+	error,
+) {
+	format := input.Format
+	if format == "" {
+		format = reportFormatMarkdown
+	}
+	if format != reportFormatMarkdown && format != reportFormatJSON {
+		return toolError(fmt.Sprintf("unsupported format %q, expected %q or %q", input.Format, reportFormatMarkdown, reportFormatJSON)), ClusterReportResult{}, nil
+	}
+
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	report, err := s.resourceOps.GenerateClusterReport(ctx, clusterName)
+	if err != nil {
+		return nil, ClusterReportResult{}, fmt.Errorf("failed to generate cluster report: %w", err)
+	}
+
+	if format == reportFormatJSON {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return nil, ClusterReportResult{}, fmt.Errorf("failed to serialize cluster report: %w", err)
+		}
+		return nil, ClusterReportResult{Report: string(data), Format: format}, nil
+	}
+	return nil, ClusterReportResult{Report: formatClusterReportMarkdown(report), Format: format}, nil
+}
+
+// formatClusterReportMarkdown renders a cluster report as markdown; sections that
+// could not be fetched show their "section unavailable" reason
+// formatClusterReportMarkdown 将集群报告渲染为 markdown，获取失败的部分显示 "section unavailable" 原因
+func formatClusterReportMarkdown(report *types.ClusterReport) string {
+	var sb strings.Builder
+	// unavailable writes the reason of each failed section and reports whether any failed
+	// unavailable 输出每个失败部分的原因，并返回是否有部分失败
+	unavailable := func(sections ...string) bool {
+		failed := false
+		for _, section := range sections {
+			if reason, ok := report.Unavailable[section]; ok {
+				fmt.Fprintf(&sb, "_%s: %s_\n\n", section, reason)
+				failed = true
+			}
+		}
+		return failed
+	}
+
+	fmt.Fprintf(&sb, "# Cluster report: %s\n\n", report.Cluster)
+	fmt.Fprintf(&sb, "Generated at %s\n\n", report.GeneratedAt)
+
+	sb.WriteString("## Overview\n\n")
+	if !unavailable(k8s.ReportSectionOverview) {
+		fmt.Fprintf(&sb, "- Version: %s\n- Platform: %s\n\n", report.Version, report.Platform)
+	}
+
+	if report.Nodes != nil || report.Unavailable[k8s.ReportSectionNodes] != "" {
+		sb.WriteString("## Nodes\n\n")
+		if !unavailable(k8s.ReportSectionNodes) {
+			fmt.Fprintf(&sb, "- Total: %d (Ready: %d, NotReady: %d)\n", report.Nodes.Total, report.Nodes.Ready, report.Nodes.NotReady)
+			fmt.Fprintf(&sb, "- Kubelet versions: %s\n\n", strings.Join(report.Nodes.Versions, ", "))
+		}
+	}
+
+	sb.WriteString("## Namespaces\n\n")
+	if !unavailable(k8s.ReportSectionNamespaces, k8s.ReportSectionPods, k8s.ReportSectionDeployments) || len(report.Namespaces) > 0 {
+		sb.WriteString("| Namespace | Status | Pods | Deployments |\n|---|---|---|---|\n")
+		for _, ns := range report.Namespaces {
+			fmt.Fprintf(&sb, "| %s | %s | %d | %d |\n", ns.Name, ns.Status, ns.Pods, ns.Deployments)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Workloads not fully ready\n\n")
+	if !unavailable(k8s.ReportSectionDeployments, k8s.ReportSectionStatefulSets) || len(report.UnreadyWorkloads) > 0 {
+		if len(report.UnreadyWorkloads) == 0 {
+			sb.WriteString("None\n\n")
+		} else {
+			sb.WriteString("| Kind | Namespace | Name | Ready |\n|---|---|---|---|\n")
+			for _, w := range report.UnreadyWorkloads {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", w.Kind, w.Namespace, w.Name, w.Ready)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("## Warning events (last hour)\n\n")
+	if !unavailable(k8s.ReportSectionEvents) {
+		if report.WarningEventCount == 0 {
+			sb.WriteString("None\n\n")
+		} else {
+			if report.WarningEventCount > len(report.WarningEvents) {
+				fmt.Fprintf(&sb, "Showing the %d most recent of %d\n\n", len(report.WarningEvents), report.WarningEventCount)
+			}
+			sb.WriteString("| Last seen | Namespace | Object | Reason | Message |\n|---|---|---|---|---|\n")
+			for _, e := range report.WarningEvents {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", e.LastTimestamp, e.Namespace, e.Object, e.Reason, markdownCell(e.Message))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if report.Nodes != nil || report.Unavailable[k8s.ReportSectionNodeConditions] != "" {
+		sb.WriteString("## Node conditions\n\n")
+		if !unavailable(k8s.ReportSectionNodeConditions) {
+			if len(report.NodeConditions) == 0 {
+				sb.WriteString("None\n\n")
+			} else {
+				sb.WriteString("| Node | Condition | Status | Reason |\n|---|---|---|---|\n")
+				for _, c := range report.NodeConditions {
+					fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.Node, c.Type, c.Status, c.Reason)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	sb.WriteString("## Unbound volume claims\n\n")
+	if !unavailable(k8s.ReportSectionVolumeClaims) {
+		if len(report.VolumeClaims) == 0 {
+			sb.WriteString("None\n\n")
+		} else {
+			sb.WriteString("| Namespace | Name | Status | Storage class | Age | Message |\n|---|---|---|---|---|---|\n")
+			for _, pvc := range report.VolumeClaims {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n", pvc.Namespace, pvc.Name, pvc.Status, pvc.StorageClass, pvc.Age, markdownCell(pvc.Message))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// markdownCell keeps a value on one line and escapes pipes so it fits in a table cell
+// markdownCell 将值保持在一行内并转义竖线，使其适合放入表格单元格
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
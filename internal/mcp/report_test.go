@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestFormatClusterReportMarkdown 测试 markdown 报告包含各部分内容，失败的部分显示原因
+func TestFormatClusterReportMarkdown(t *testing.T) {
+	report := &types.ClusterReport{
+		Cluster:     "prod",
+		GeneratedAt: "2024-05-02T12:00:00Z",
+		Version:     "v1.28.4",
+		Nodes:       &types.NodeSummary{Total: 3, Ready: 2, NotReady: 1, Versions: []string{"v1.28.4"}},
+		Namespaces:  []types.NamespaceSummary{{Name: "default", Status: "Active", Pods: 4, Deployments: 2}},
+		UnreadyWorkloads: []types.UnreadyWorkload{
+			{Kind: "Deployment", Namespace: "default", Name: "web", Ready: "1/2"},
+		},
+		Unavailable: map[string]string{
+			k8s.ReportSectionEvents: "section unavailable: events is forbidden",
+		},
+	}
+
+	text := formatClusterReportMarkdown(report)
+	for _, want := range []string{
+		"# Cluster report: prod",
+		"- Total: 3 (Ready: 2, NotReady: 1)",
+		"| default | Active | 4 | 2 |",
+		"| Deployment | default | web | 1/2 |",
+		"_events: section unavailable: events is forbidden_",
+		"## Node conditions\n\nNone",
+		"## Unbound volume claims\n\nNone",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+// TestMarkdownCell 测试表格单元格转义竖线并合并换行
+func TestMarkdownCell(t *testing.T) {
+	if got := markdownCell("a | b\n  c"); got != `a \| b c` {
+		t.Errorf("unexpected cell: %q", got)
+	}
+}
@@ -0,0 +1,382 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// resourceURIScheme is the scheme of all resource URIs served by this server
+	// resourceURIScheme 本服务器提供的资源 URI 协议前缀
+	resourceURIScheme = "k8s://"
+
+	// resourceMIMEType is the MIME type of resource contents
+	// resourceMIMEType 资源内容的 MIME 类型
+	resourceMIMEType = "application/json"
+)
+
+// Resource URI kinds
+// 资源 URI 类型
+const (
+	resourceKindClusters   = "clusters"
+	resourceKindInfo       = "info"
+	resourceKindNamespaces = "namespaces"
+	resourceKindPods       = "pods"
+	resourceKindHistory    = "history"
+	resourceKindObject     = "object"
+)
+
+// resourceURI is a parsed k8s:// resource URI
+// resourceURI 解析后的 k8s:// 资源 URI
+type resourceURI struct {
+	Kind         string
+	Cluster      string
+	Namespace    string
+	ResourceType k8s.ResourceType
+	Name         string
+}
+
+// parseResourceURI parses one of the supported resource URIs:
+//
+//	k8s://clusters
+//	k8s://cluster/{cluster}/info
+//	k8s://cluster/{cluster}/namespaces
+//	k8s://cluster/{cluster}/namespace/{namespace}/pods
+//	k8s://cluster/{cluster}/namespace/{namespace}/{resource_type}/{name}
+//	k8s://cluster/{cluster}/{cluster_scoped_type}/{name}
+//	k8s://server/history
+//
+// The error names what is wrong with a malformed URI, e.g. a missing name or a
+// namespaced type addressed without a namespace.
+// parseResourceURI 解析支持的资源 URI，URI 格式错误时错误信息说明具体问题，例如缺少名称或命名空间级类型未指定命名空间
+func parseResourceURI(uri string) (resourceURI, error) {
+	if !strings.HasPrefix(uri, resourceURIScheme) {
+		return resourceURI{}, fmt.Errorf("unsupported resource URI %q", uri)
+	}
+	t := &uriTokens{uri: uri, parts: strings.Split(strings.TrimPrefix(uri, resourceURIScheme), "/")}
+	for i, part := range t.parts {
+		if part == "" {
+			return resourceURI{}, fmt.Errorf("invalid resource URI %q: segment %d is empty", uri, i+1)
+		}
+	}
+
+	switch root := t.parts[0]; root {
+	case resourceKindClusters:
+		t.pos = 1
+		return resourceURI{Kind: resourceKindClusters}, t.end()
+	case "server":
+		t.pos = 1
+		name, err := t.next("server resource")
+		if err != nil {
+			return resourceURI{}, err
+		}
+		if name != resourceKindHistory {
+			return resourceURI{}, fmt.Errorf("unsupported resource URI %q", uri)
+		}
+		return resourceURI{Kind: resourceKindHistory}, t.end()
+	case "cluster":
+		t.pos = 1
+		return parseClusterURI(t)
+	}
+	return resourceURI{}, fmt.Errorf("unsupported resource URI %q", uri)
+}
+
+// parseClusterURI parses the part of a k8s://cluster/... URI after "cluster"
+// parseClusterURI 解析 k8s://cluster/... URI 中 "cluster" 之后的部分
+func parseClusterURI(t *uriTokens) (resourceURI, error) {
+	cluster, err := t.next("cluster name")
+	if err != nil {
+		return resourceURI{}, err
+	}
+	segment, err := t.next("info, namespaces, namespace/{namespace}/... or {resource_type}/{name}")
+	if err != nil {
+		return resourceURI{}, err
+	}
+
+	switch {
+	case segment == resourceKindInfo:
+		return resourceURI{Kind: resourceKindInfo, Cluster: cluster}, t.end()
+	case segment == resourceKindNamespaces && t.done():
+		return resourceURI{Kind: resourceKindNamespaces, Cluster: cluster}, nil
+	case segment == "namespace" && len(t.parts)-t.pos > 1:
+		namespace, _ := t.next("namespace")
+		resourceType, _ := t.next("resource type")
+		if resourceType == resourceKindPods && t.done() {
+			return resourceURI{Kind: resourceKindPods, Cluster: cluster, Namespace: namespace}, nil
+		}
+		if err := t.resourceType(resourceType); err != nil {
+			return resourceURI{}, err
+		}
+		if k8s.IsClusterScoped(k8s.ResourceType(resourceType)) {
+			return resourceURI{}, fmt.Errorf("invalid resource URI %q: %s is cluster-scoped, use k8s://cluster/%s/%s/{name}", t.uri, resourceType, cluster, resourceType)
+		}
+		name, err := t.next(resourceType + " name")
+		if err != nil {
+			return resourceURI{}, err
+		}
+		return resourceURI{Kind: resourceKindObject, Cluster: cluster, Namespace: namespace, ResourceType: k8s.ResourceType(resourceType), Name: name}, t.end()
+	}
+
+	// k8s://cluster/{cluster}/{cluster_scoped_type}/{name}
+	if err := t.resourceType(segment); err != nil {
+		return resourceURI{}, err
+	}
+	if !k8s.IsClusterScoped(k8s.ResourceType(segment)) {
+		return resourceURI{}, fmt.Errorf("invalid resource URI %q: %s is namespaced, use k8s://cluster/%s/namespace/{namespace}/%s/{name}", t.uri, segment, cluster, segment)
+	}
+	name, err := t.next(segment + " name")
+	if err != nil {
+		return resourceURI{}, err
+	}
+	return resourceURI{Kind: resourceKindObject, Cluster: cluster, ResourceType: k8s.ResourceType(segment), Name: name}, t.end()
+}
+
+// uriTokens walks the path segments of a resource URI
+// uriTokens 逐个读取资源 URI 的路径段
+type uriTokens struct {
+	uri   string
+	parts []string
+	pos   int
+}
+
+// next returns the next segment, or an error naming what is missing
+// next 返回下一个路径段，没有时返回说明缺少内容的错误
+func (t *uriTokens) next(what string) (string, error) {
+	if t.done() {
+		return "", fmt.Errorf("invalid resource URI %q: missing %s", t.uri, what)
+	}
+	t.pos++
+	return t.parts[t.pos-1], nil
+}
+
+// done reports whether every segment has been read
+// done 判断是否已读取所有路径段
+func (t *uriTokens) done() bool {
+	return t.pos >= len(t.parts)
+}
+
+// end returns an error if segments are left
+// end 仍有未读取的路径段时返回错误
+func (t *uriTokens) end() error {
+	if t.done() {
+		return nil
+	}
+	return fmt.Errorf("invalid resource URI %q: unexpected segment %q", t.uri, strings.Join(t.parts[t.pos:], "/"))
+}
+
+// resourceType returns an error unless the segment is a supported resource type
+// resourceType 路径段不是支持的资源类型时返回错误
+func (t *uriTokens) resourceType(segment string) error {
+	if k8s.IsSupportedResourceType(k8s.ResourceType(segment)) {
+		return nil
+	}
+	return fmt.Errorf("invalid resource URI %q: unknown resource type %q", t.uri, segment)
+}
+
+// RegisterResources registers the k8s:// resources and resource templates
+// RegisterResources 注册 k8s:// 资源和资源模板
+func (s *Server) RegisterResources() {
+	s.mcpServer.AddResource(&mcp.Resource{
+		Name:        "clusters",
+		URI:         resourceURIScheme + resourceKindClusters,
+		Description: "Registered clusters and the current cluster",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "cluster-info",
+		URITemplate: resourceURIScheme + "cluster/{cluster}/info",
+		Description: "Version, node count and namespace count of a cluster, plus the effective client QPS, burst and user agent",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "namespaces",
+		URITemplate: resourceURIScheme + "cluster/{cluster}/namespaces",
+		Description: "Namespaces of a cluster (subscribable when subscriptions are enabled)",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "pods",
+		URITemplate: resourceURIScheme + "cluster/{cluster}/namespace/{namespace}/pods",
+		Description: "Pods of a namespace (subscribable when subscriptions are enabled)",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "object",
+		URITemplate: resourceURIScheme + "cluster/{cluster}/namespace/{namespace}/{resource_type}/{name}",
+		Description: "One namespaced object as sanitized JSON, like get_resource; secret data is redacted",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "cluster-object",
+		URITemplate: resourceURIScheme + "cluster/{cluster}/{resource_type}/{name}",
+		Description: "One cluster-scoped object (node, namespace, persistentvolume) as sanitized JSON, like get_resource",
+		MIMEType:    resourceMIMEType,
+	}, s.handleReadResource)
+
+	if s.history != nil {
+		s.mcpServer.AddResource(&mcp.Resource{
+			Name:        "history",
+			URI:         resourceURIScheme + "server/" + resourceKindHistory,
+			Description: "Recent tool calls, newest first, with redacted arguments, outcome and duration; callers with their own identity only see their own calls",
+			MIMEType:    resourceMIMEType,
+		}, s.handleReadResource)
+	}
+}
+
+// clustersOverview lists the loaded clusters with their cached reachability, plus the
+// kubeconfig clusters that failed to load as "unavailable: <reason>"
+// clustersOverview 列出已加载的集群及其缓存的可达性，以及 kubeconfig 中加载失败的集群（"unavailable: <原因>"）
+func (s *Server) clustersOverview(ctx context.Context) map[string]interface{} {
+	clusters := s.clusterManager.GetClusters()
+	sort.Strings(clusters)
+	overview := map[string]interface{}{
+		"current":  s.currentCluster(ctx),
+		"clusters": clusters,
+	}
+
+	reachability := make(map[string]k8s.Reachability)
+	for _, name := range clusters {
+		if status, ok := s.clusterManager.GetReachability(name); ok {
+			reachability[name] = status
+		}
+	}
+	if len(reachability) > 0 {
+		overview["reachability"] = reachability
+	}
+
+	if failed := s.clusterManager.GetUnavailableClusters(); len(failed) > 0 {
+		unavailable := make(map[string]string, len(failed))
+		for name, err := range failed {
+			unavailable[name] = "unavailable: " + err.Error()
+		}
+		overview["unavailable"] = unavailable
+	}
+	return overview
+}
+
+// handleReadResource serves resources/read for all k8s:// URIs
+// handleReadResource 处理所有 k8s:// URI 的 resources/read 请求
+func (s *Server) handleReadResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	parsed, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()}
+	}
+	if parsed.Kind == resourceKindHistory && s.history == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if parsed.Kind == resourceKindObject {
+		return s.readObjectResource(ctx, uri, parsed)
+	}
+
+	var data interface{}
+	switch parsed.Kind {
+	case resourceKindClusters:
+		data = s.clustersOverview(ctx)
+	case resourceKindInfo:
+		data, err = s.clusterInfo(ctx, parsed.Cluster)
+	case resourceKindNamespaces:
+		data, err = s.resourceOps.ListNamespaces(ctx, parsed.Cluster)
+	case resourceKindPods:
+		data, err = s.resourceOps.ListPods(ctx, parsed.Namespace, parsed.Cluster)
+	case resourceKindHistory:
+		data = newCallHistoryResult(s.sessionLanguage(req.Session), s.visibleCalls(req), s.history.capacity())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource %s: %w", uri, err)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize resource %s: %w", uri, err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: resourceMIMEType,
+			Text:     string(content),
+		}},
+	}, nil
+}
+
+// readObjectResource serves a single object URI with the same sanitized JSON and
+// secret redaction as get_resource
+// readObjectResource 返回单个对象 URI 的内容，与 get_resource 一样清理字段并脱敏 secret
+func (s *Server) readObjectResource(ctx context.Context, uri string, parsed resourceURI) (*mcp.ReadResourceResult, error) {
+	resource, err := s.resourceOps.GetResourceDetails(ctx, parsed.ResourceType, parsed.Namespace, parsed.Name, parsed.Cluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		return nil, fmt.Errorf("failed to read resource %s: %w", uri, err)
+	}
+	if parsed.ResourceType == k8s.ResourceTypeSecrets || parsed.ResourceType == k8s.ResourceTypeSecret {
+		resource = s.redactSecretData(resource)
+	}
+
+	content, err := s.resourceOps.SerializeResource(resource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize resource %s: %w", uri, err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      uri,
+			MIMEType: resourceMIMEType,
+			Text:     content,
+		}},
+	}, nil
+}
+
+// clusterInfo returns the cluster info together with the effective client settings,
+// so operators can verify the configured rate limits
+// clusterInfo 返回集群信息以及实际生效的客户端配置，便于运维人员核对限流设置
+func (s *Server) clusterInfo(ctx context.Context, clusterName string) (map[string]interface{}, error) {
+	settings, userAgent, err := s.clusterManager.EffectiveClientSettings(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.resourceOps.GetClusterInfo(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	info["client"] = map[string]interface{}{
+		"qps":        settings.QPS,
+		"burst":      settings.Burst,
+		"user_agent": userAgent,
+	}
+	return info, nil
+}
+
+// watchResource starts a Kubernetes watch backing a subscribable resource URI
+// watchResource 为可订阅的资源 URI 启动对应的 Kubernetes watch
+func (s *Server) watchResource(ctx context.Context, uri string) (watch.Interface, error) {
+	parsed, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsed.Kind {
+	case resourceKindNamespaces:
+		return s.resourceOps.WatchNamespaces(ctx, parsed.Cluster)
+	case resourceKindPods:
+		return s.resourceOps.WatchPods(ctx, parsed.Namespace, parsed.Cluster)
+	}
+	return nil, fmt.Errorf("resource %s does not support subscriptions", uri)
+}
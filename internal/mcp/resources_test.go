@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseResourceURI 测试资源 URI 解析，格式错误的 URI 返回说明具体问题的错误
+func TestParseResourceURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    resourceURI
+		wantErr string
+	}{
+		{uri: "k8s://clusters", want: resourceURI{Kind: resourceKindClusters}},
+		{uri: "k8s://cluster/prod/info", want: resourceURI{Kind: resourceKindInfo, Cluster: "prod"}},
+		{uri: "k8s://cluster/prod/namespaces", want: resourceURI{Kind: resourceKindNamespaces, Cluster: "prod"}},
+		{uri: "k8s://cluster/prod/namespace/default/pods", want: resourceURI{Kind: resourceKindPods, Cluster: "prod", Namespace: "default"}},
+		{uri: "k8s://server/history", want: resourceURI{Kind: resourceKindHistory}},
+
+		// 单个对象
+		{uri: "k8s://cluster/prod/namespace/shop/pods/web-1", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", Namespace: "shop", ResourceType: "pods", Name: "web-1"}},
+		{uri: "k8s://cluster/prod/namespace/shop/secret/db", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", Namespace: "shop", ResourceType: "secret", Name: "db"}},
+		{uri: "k8s://cluster/prod/namespace/shop/routes/web", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", Namespace: "shop", ResourceType: "routes", Name: "web"}},
+		{uri: "k8s://cluster/prod/nodes/node-1", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", ResourceType: "nodes", Name: "node-1"}},
+		{uri: "k8s://cluster/prod/persistentvolume/pv-1", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", ResourceType: "persistentvolume", Name: "pv-1"}},
+		{uri: "k8s://cluster/prod/namespaces/shop", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", ResourceType: "namespaces", Name: "shop"}},
+		{uri: "k8s://cluster/prod/namespace/shop", want: resourceURI{Kind: resourceKindObject, Cluster: "prod", ResourceType: "namespace", Name: "shop"}},
+
+		// 协议或根路径错误
+		{uri: "http://cluster/prod/info", wantErr: "unsupported resource URI"},
+		{uri: "k8s://", wantErr: "segment 1 is empty"},
+		{uri: "k8s://pods", wantErr: "unsupported resource URI"},
+		{uri: "k8s://server/info", wantErr: "unsupported resource URI"},
+		{uri: "k8s://server", wantErr: "missing server resource"},
+		{uri: "k8s://clusters/prod", wantErr: `unexpected segment "prod"`},
+		{uri: "k8s://server/history/1", wantErr: `unexpected segment "1"`},
+
+		// 空路径段
+		{uri: "k8s://cluster//namespaces", wantErr: "segment 2 is empty"},
+		{uri: "k8s://cluster/prod/namespace/shop/pods/", wantErr: "segment 6 is empty"},
+
+		// 路径段过少
+		{uri: "k8s://cluster", wantErr: "missing cluster name"},
+		{uri: "k8s://cluster/prod", wantErr: "missing info, namespaces, namespace/{namespace}/... or {resource_type}/{name}"},
+		{uri: "k8s://cluster/prod/namespace", wantErr: "missing namespace name"},
+		{uri: "k8s://cluster/prod/nodes", wantErr: "missing nodes name"},
+		{uri: "k8s://cluster/prod/namespace/shop/secrets", wantErr: "missing secrets name"},
+
+		// 路径段过多
+		{uri: "k8s://cluster/prod/info/extra", wantErr: `unexpected segment "extra"`},
+		{uri: "k8s://cluster/prod/nodes/node-1/status", wantErr: `unexpected segment "status"`},
+		{uri: "k8s://cluster/prod/namespace/shop/pods/web/logs/tail", wantErr: `unexpected segment "logs/tail"`},
+
+		// 未知类型
+		{uri: "k8s://cluster/prod/widgets/w", wantErr: `unknown resource type "widgets"`},
+		{uri: "k8s://cluster/prod/namespace/shop/widgets/w", wantErr: `unknown resource type "widgets"`},
+
+		// 作用域错误
+		{uri: "k8s://cluster/prod/secrets", wantErr: "secrets is namespaced, use k8s://cluster/prod/namespace/{namespace}/secrets/{name}"},
+		{uri: "k8s://cluster/prod/pods/web", wantErr: "pods is namespaced, use k8s://cluster/prod/namespace/{namespace}/pods/{name}"},
+		{uri: "k8s://cluster/prod/namespace/shop/nodes/node-1", wantErr: "nodes is cluster-scoped, use k8s://cluster/prod/nodes/{name}"},
+		{uri: "k8s://cluster/prod/namespace/shop/projects/shop", wantErr: "projects is cluster-scoped"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseResourceURI(tt.uri)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %+v %v", tt.uri, tt.wantErr, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.uri, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.uri, got, tt.want)
+		}
+	}
+}
+
+// TestReadObjectResource 测试按 URI 读取单个对象：返回清理后的 JSON，secret 数据被脱敏，对象不存在和 URI 格式错误时返回错误
+func TestReadObjectResource(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	))
+	s.RegisterTools()
+	s.RegisterResources()
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	read := func(uri string) map[string]interface{} {
+		t.Helper()
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) failed: %v", uri, err)
+		}
+		if result.Contents[0].MIMEType != resourceMIMEType || result.Contents[0].URI != uri {
+			t.Errorf("unexpected contents %+v", result.Contents[0])
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &obj); err != nil {
+			t.Fatalf("failed to decode %s: %v", uri, err)
+		}
+		return obj
+	}
+
+	secret := read("k8s://cluster/test/namespace/shop/secrets/db")
+	if secret["kind"] != "Secret" || secret["data"] != "***REDACTED***" {
+		t.Errorf("expected a redacted secret, got %v", secret)
+	}
+	if metadata, _ := secret["metadata"].(map[string]interface{}); metadata["managedFields"] != nil {
+		t.Errorf("expected managedFields to be stripped, got %v", metadata)
+	}
+	if node := read("k8s://cluster/test/nodes/node-1"); node["kind"] != "Node" {
+		t.Errorf("unexpected node %v", node)
+	}
+
+	// get_resource 同样脱敏类型化的 secret
+	result := callTool(t, session, "get_resource", map[string]any{"resource_type": "secret", "namespace": "shop", "name": "db", "cluster_name": "test"})
+	data, _ := json.Marshal(result.StructuredContent)
+	if strings.Contains(string(data), base64.StdEncoding.EncodeToString([]byte("hunter2"))) || !strings.Contains(string(data), "REDACTED") {
+		t.Errorf("get_resource leaked secret data: %s", data)
+	}
+
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "k8s://cluster/test/namespace/shop/secrets/missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing object to be not found, got %v", err)
+	}
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "k8s://cluster/test/pods/web"}); err == nil || !strings.Contains(err.Error(), "pods is namespaced") {
+		t.Errorf("expected a namespaced type without namespace to fail, got %v", err)
+	}
+}
+
+// TestClustersOverview 测试 k8s://clusters 报告加载失败的集群和缓存的可达性
+func TestClustersOverview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://127.0.0.1:1
+- name: broken
+  cluster:
+    server: https://127.0.0.1:2
+    certificate-authority: /nonexistent/ca.crt
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: broken
+  context: {cluster: broken, user: admin}
+users:
+- name: admin
+  user: {token: test}
+`
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	s := newTestServer(t)
+	if err := s.LoadKubeConfig(path); err != nil {
+		t.Fatalf("LoadKubeConfig failed: %v", err)
+	}
+
+	overview := s.clustersOverview(context.Background())
+	if _, probed := overview["reachability"]; probed {
+		t.Errorf("expected no reachability before probing")
+	}
+	unavailable, _ := overview["unavailable"].(map[string]string)
+	if !strings.HasPrefix(unavailable["broken"], "unavailable: ") || !strings.Contains(unavailable["broken"], "ca.crt") {
+		t.Errorf("unexpected unavailable clusters: %v", overview["unavailable"])
+	}
+
+	s.clusterManager.ProbeClusters(context.Background(), 1, time.Second)
+	reachability, _ := s.clustersOverview(context.Background())["reachability"].(map[string]k8s.Reachability)
+	if status, ok := reachability["prod"]; !ok || status.Reachable || status.CheckedAt.IsZero() {
+		t.Errorf("unexpected reachability: %v", reachability)
+	}
+}
@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool result size limits. DefaultMaxResultBytes applies when the server is not
+// configured otherwise; a per-call max_bytes may raise or lower it within
+// [minResultBytes, MaxResultBytesCeiling].
+// 工具结果大小限制。服务器未另行配置时使用 DefaultMaxResultBytes；单次调用的 max_bytes 可以在
+// [minResultBytes, MaxResultBytesCeiling] 范围内调高或调低该限制。
+const (
+	DefaultMaxResultBytes = 1 << 20
+	MaxResultBytesCeiling = 8 << 20
+	minResultBytes        = 1 << 10
+)
+
+// maxBytesArgument is the tools/call argument overriding the result size limit
+// maxBytesArgument 是覆盖结果大小限制的 tools/call 参数
+const maxBytesArgument = "max_bytes"
+
+// truncationHint ends the note appended to a truncated result
+// truncationHint 是追加到截断结果的说明的结尾
+const truncationHint = "refine your query with limit/field selectors or request a specific key"
+
+// resultLimitMiddleware enforces the result size limit on every tools/call: it takes the
+// optional max_bytes argument out of the arguments before the tool sees them, and
+// truncates an oversized result after the tool returns. tools/list advertises max_bytes
+// in every input schema.
+// resultLimitMiddleware 对每个 tools/call 执行结果大小限制：在工具处理参数之前取出可选的 max_bytes 参数，
+// 并在工具返回后截断过大的结果。tools/list 在每个输入 schema 中声明 max_bytes。
+func (s *Server) resultLimitMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "tools/list":
+			result, err := next(ctx, method, req)
+			if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				list.Tools = withMaxBytesArgument(list.Tools)
+			}
+			return result, err
+
+		case "tools/call":
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if !ok || params == nil {
+				return next(ctx, method, req)
+			}
+			limit, requested, rpcErr := s.takeMaxBytes(params)
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			if requested {
+				ctx = context.WithValue(ctx, maxBytesKey{}, limit)
+			}
+			result, err := next(ctx, method, req)
+			if callResult, ok := result.(*mcp.CallToolResult); ok && err == nil {
+				if note := limitToolResult(callResult, limit); note != "" {
+					s.logger.Info("Truncated tool result", "tool", params.Name, "limit", limit, "note", note)
+				}
+			}
+			return result, err
+
+		default:
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// maxBytesKey is the context key of the max_bytes a tools/call passed
+// maxBytesKey 是 tools/call 传入的 max_bytes 的 context 键
+type maxBytesKey struct{}
+
+// requestedMaxBytes returns the max_bytes the call passed, within the allowed range. Tools
+// that read data of unbounded size, such as cp_from_pod, use it to stop reading early.
+// requestedMaxBytes 返回调用传入的 max_bytes（已限制在允许范围内）。读取大小不受限的数据的工具（例如 cp_from_pod）用它提前停止读取。
+func requestedMaxBytes(ctx context.Context) (int, bool) {
+	limit, ok := ctx.Value(maxBytesKey{}).(int)
+	return limit, ok
+}
+
+// takeMaxBytes removes max_bytes from the call arguments, so the tool's own schema still
+// validates them, and returns the size limit for the call and whether max_bytes was passed
+// takeMaxBytes 从调用参数中删除 max_bytes，使工具自身的 schema 仍能校验参数，并返回本次调用的大小限制以及是否传入了 max_bytes
+func (s *Server) takeMaxBytes(params *mcp.CallToolParamsRaw) (int, bool, *jsonrpc.Error) {
+	limit := s.maxResultBytes
+	var args map[string]json.RawMessage
+	if len(params.Arguments) == 0 || json.Unmarshal(params.Arguments, &args) != nil {
+		return limit, false, nil
+	}
+	raw, ok := args[maxBytesArgument]
+	if !ok {
+		return limit, false, nil
+	}
+
+	var requested int
+	if err := json.Unmarshal(raw, &requested); err != nil || requested <= 0 {
+		return 0, false, &jsonrpc.Error{
+			Code:    jsonrpc.CodeInvalidParams,
+			Message: fmt.Sprintf("max_bytes must be a positive integer, got %s", raw),
+		}
+	}
+	limit = min(max(requested, minResultBytes), MaxResultBytesCeiling)
+
+	delete(args, maxBytesArgument)
+	stripped, err := json.Marshal(args)
+	if err != nil {
+		return 0, false, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+	}
+	params.Arguments = stripped
+	return limit, true, nil
+}
+
+// withMaxBytesArgument returns copies of tools whose input schemas also accept max_bytes.
+// The SDK returns its own tool definitions, so they are never modified in place.
+// withMaxBytesArgument 返回工具的副本，其输入 schema 同时接受 max_bytes。SDK 返回的是其内部的工具定义，因此不能原地修改。
+func withMaxBytesArgument(tools []*mcp.Tool) []*mcp.Tool {
+	copies := make([]*mcp.Tool, len(tools))
+	for i, tool := range tools {
+		copies[i] = tool
+		schema, ok := tool.InputSchema.(*jsonschema.Schema)
+		if !ok || schema == nil {
+			continue
+		}
+
+		schemaCopy := *schema
+		schemaCopy.Properties = make(map[string]*jsonschema.Schema, len(schema.Properties)+1)
+		for name, property := range schema.Properties {
+			schemaCopy.Properties[name] = property
+		}
+		minimum, maximum := float64(1), float64(MaxResultBytesCeiling)
+		schemaCopy.Properties[maxBytesArgument] = &jsonschema.Schema{
+			Type:        "integer",
+			Description: fmt.Sprintf("Maximum size of the result in bytes; larger results are truncated (default: the server's --max-result-bytes, %d to %d)", minResultBytes, MaxResultBytesCeiling),
+			Minimum:     &minimum,
+			Maximum:     &maximum,
+		}
+
+		toolCopy := *tool
+		toolCopy.InputSchema = &schemaCopy
+		copies[i] = &toolCopy
+	}
+	return copies
+}
+
+// limitToolResult truncates the text content and structured content of result that are
+// larger than limit bytes and appends a note saying what was cut. JSON is truncated
+// structurally so it stays valid; other text is cut at a line or rune boundary. It
+// returns the note, or "" when nothing was truncated.
+// limitToolResult 截断 result 中超过 limit 字节的文本内容和结构化内容，并追加说明截断内容的注释。
+// JSON 按结构截断以保持合法；其他文本在换行或字符边界截断。返回该说明，未截断时返回 ""。
+func limitToolResult(result *mcp.CallToolResult, limit int) string {
+	var (
+		shown, total int
+		omitted      []string
+	)
+
+	// The SDK fills the text content with the structured content, so the truncated
+	// structured content is reused for that text
+	// SDK 使用结构化内容填充文本内容，因此该文本复用截断后的结构化内容
+	var original, truncated string
+	if structured, ok := result.StructuredContent.(json.RawMessage); ok && len(structured) > limit {
+		if out, info, err := truncateJSON(structured, limit); err == nil {
+			original, truncated = string(structured), string(out)
+			result.StructuredContent = json.RawMessage(out)
+			shown, total, omitted = len(out), len(structured), info.omitted
+		}
+	}
+
+	textTruncated := false
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok || len(text.Text) <= limit {
+			continue
+		}
+		before := len(text.Text)
+		switch {
+		case original != "" && text.Text == original:
+			text.Text = truncated
+		case json.Valid([]byte(text.Text)):
+			out, info, err := truncateJSON([]byte(text.Text), limit)
+			if err != nil {
+				text.Text = truncateText(text.Text, limit)
+				break
+			}
+			text.Text = string(out)
+			if original == "" {
+				omitted = append(omitted, info.omitted...)
+			}
+		default:
+			text.Text = truncateText(text.Text, limit)
+		}
+		if !textTruncated {
+			// The text content replaces the structured content in the count
+			// 文本内容在计数中取代结构化内容
+			shown, total, textTruncated = 0, 0, true
+		}
+		shown += len(text.Text)
+		total += before
+	}
+	if total == 0 {
+		return ""
+	}
+
+	note := fmt.Sprintf("%d of %d bytes shown", shown, total)
+	if len(omitted) > 0 {
+		note += ", omitted " + strings.Join(omitted, ", ")
+	}
+	result.Content = append(result.Content, &mcp.TextContent{
+		Text: "[truncated, " + note + " — " + truncationHint + "]",
+	})
+	return note
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newLargeClusterServer 创建一个集群中包含大量 Pod 和一个大 ConfigMap 的服务器
+func newLargeClusterServer(t *testing.T, opts *Options) *mcp.ClientSession {
+	t.Helper()
+	var objects []runtime.Object
+	for i := 0; i < 300; i++ {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%03d", i), Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	objects = append(objects, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: "default"},
+		Data:       map[string]string{"bundle.js": strings.Repeat("console.log('日志');\n", 20000)},
+	})
+
+	s := NewServer("test-token", opts)
+	s.clusterManager.AddClientset("test", fake.NewSimpleClientset(objects...))
+	s.RegisterTools()
+	return connectTestClient(t, s, nil)
+}
+
+// callTool 调用工具并返回结果，工具返回错误时测试失败
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	if result.IsError {
+		t.Fatalf("%s returned an error: %s", name, result.Content[0].(*mcp.TextContent).Text)
+	}
+	return result
+}
+
+// TestResultLimitListPods 测试列表结果按完整条目截断，并在说明中报告省略的条目数
+func TestResultLimitListPods(t *testing.T) {
+	session := newLargeClusterServer(t, &Options{MaxResultBytes: 4096})
+
+	result := callTool(t, session, "list_pods", map[string]any{"namespace": "default"})
+	if len(result.Content) != 2 {
+		t.Fatalf("expected the result and a truncation note, got %d contents", len(result.Content))
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if len(text) > 4096 {
+		t.Errorf("expected at most 4096 bytes, got %d", len(text))
+	}
+	var out PodsResult
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("truncated result is not valid JSON: %v", err)
+	}
+	var pods []map[string]any
+	if err := json.Unmarshal([]byte(out.Pods), &pods); err != nil {
+		t.Fatalf("truncated pods are not valid JSON: %v", err)
+	}
+	if len(pods) == 0 || out.Scope != "namespace default" {
+		t.Errorf("unexpected truncated result: %s", text)
+	}
+
+	note := result.Content[1].(*mcp.TextContent).Text
+	wantOmitted := fmt.Sprintf("omitted %d of 300 entries of pods", 300-len(pods))
+	for _, want := range []string{"[truncated, ", fmt.Sprintf("%d of ", len(text)), wantOmitted, truncationHint + "]"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("expected %q in note %q", want, note)
+		}
+	}
+	structured, _ := json.Marshal(result.StructuredContent)
+	var fromStructured PodsResult
+	if err := json.Unmarshal(structured, &fromStructured); err != nil || fromStructured != out {
+		t.Errorf("expected the structured content to match the text content: %s", structured)
+	}
+}
+
+// TestResultLimitMaxBytesArgument 测试 max_bytes 参数覆盖服务器限制、受上限约束，且无效值被拒绝
+func TestResultLimitMaxBytesArgument(t *testing.T) {
+	session := newLargeClusterServer(t, nil)
+	args := map[string]any{"resource_type": "configmaps", "name": "bundle", "namespace": "default"}
+
+	// 默认限制下完整返回（约 500KB）
+	result := callTool(t, session, "get_resource", args)
+	if len(result.Content) != 1 {
+		t.Fatalf("expected no truncation under the default limit, got %d contents", len(result.Content))
+	}
+
+	args["max_bytes"] = 2000
+	result = callTool(t, session, "get_resource", args)
+	text := result.Content[0].(*mcp.TextContent).Text
+	if len(result.Content) != 2 || len(text) > 2000 {
+		t.Fatalf("expected a result of at most 2000 bytes and a note, got %d bytes and %d contents", len(text), len(result.Content))
+	}
+	var out ResourceResult
+	if err := json.Unmarshal([]byte(text), &out); err != nil || !json.Valid([]byte(out.Resource)) {
+		t.Fatalf("expected the truncated resource to stay valid JSON: %v\n%s", err, text)
+	}
+	if !strings.Contains(out.Resource, `"name": "bundle"`) {
+		t.Errorf("expected the object metadata to be kept: %s", out.Resource)
+	}
+
+	// 低于下限的值被提升到下限
+	args["max_bytes"] = 1
+	result = callTool(t, session, "get_resource", args)
+	if text := result.Content[0].(*mcp.TextContent).Text; len(text) > minResultBytes || len(text) < minResultBytes/2 {
+		t.Errorf("expected about %d bytes, got %d", minResultBytes, len(text))
+	}
+
+	for _, invalid := range []any{0, -5, "big", 1.5} {
+		args["max_bytes"] = invalid
+		if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_resource", Arguments: args}); err == nil || !strings.Contains(err.Error(), "max_bytes must be a positive integer") {
+			t.Errorf("max_bytes %v: expected an invalid params error, got %v", invalid, err)
+		}
+	}
+}
+
+// TestResultLimitToolsList 测试 tools/list 中每个工具的输入 schema 都声明了 max_bytes
+func TestResultLimitToolsList(t *testing.T) {
+	session := newLargeClusterServer(t, nil)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		data, _ := json.Marshal(tool.InputSchema)
+		var schema jsonschema.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("%s: invalid input schema: %v", tool.Name, err)
+		}
+		property, ok := schema.Properties[maxBytesArgument]
+		if !ok || property.Type != "integer" || property.Maximum == nil || *property.Maximum != MaxResultBytesCeiling {
+			t.Errorf("%s: expected a max_bytes integer property, got %s", tool.Name, data)
+		}
+	}
+
+	// 再次列出的结果相同
+	again, _ := session.ListTools(context.Background(), nil)
+	first, _ := json.Marshal(tools.Tools)
+	second, _ := json.Marshal(again.Tools)
+	if string(first) != string(second) {
+		t.Errorf("expected tools/list to be stable")
+	}
+}
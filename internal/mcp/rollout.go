@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleRolloutHistory handles rollout_history tool
+// handleRolloutHistory 处理 rollout_history 工具
+func (s *Server) handleRolloutHistory(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Revision    int64  `json:"revision,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.RolloutHistory,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	history, err := s.resourceOps.RolloutHistory(ctx, namespace, input.Name, input.Revision, clusterName)
+	if err != nil {
+		return nil, types.RolloutHistory{}, fmt.Errorf("failed to get rollout history: %w", err)
+	}
+	return nil, *history, nil
+}
+
+// handleRollbackDeployment handles rollback_deployment tool
+// handleRollbackDeployment 处理 rollback_deployment 工具
+func (s *Server) handleRollbackDeployment(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace,omitempty"`
+	Revision       int64  `json:"revision,omitempty"`
+	Confirm        bool   `json:"confirm,omitempty"`
+	WaitForReady   bool   `json:"wait_for_ready,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	ClusterName    string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.RollbackResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	target := "the previous revision"
+	if input.Revision > 0 {
+		target = fmt.Sprintf("revision %d", input.Revision)
+	}
+	action := fmt.Sprintf("rollback of deployment %s in namespace %s to %s", input.Name, namespace, target)
+	if clusterName != "" {
+		action += " on cluster " + clusterName
+	}
+	if result, err := s.confirmDestructive(ctx, req, action, input.Confirm); result != nil || err != nil {
+		return result, types.RollbackResult{}, err
+	}
+
+	result, err := s.resourceOps.RollbackDeployment(ctx, namespace, input.Name, input.Revision, clusterName)
+	if err != nil {
+		return nil, types.RollbackResult{}, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+	if input.WaitForReady {
+		result.Readiness = s.waitForReadiness(ctx, k8s.ResourceTypeDeployments, namespace, input.Name, input.TimeoutSeconds, clusterName)
+	}
+	return nil, *result, nil
+}
+
+// waitForReadiness waits for the workload a mutating tool changed when the caller passed
+// wait_for_ready=true. The change is already made, so a failure to wait is reported in
+// the summary rather than failing the call
+// waitForReadiness 在调用方传入 wait_for_ready=true 时等待修改操作涉及的工作负载。修改已经完成，
+// 因此等待失败时在摘要中说明，而不是使调用失败
+func (s *Server) waitForReadiness(ctx context.Context, resourceType k8s.ResourceType, namespace, name string, timeoutSeconds int, clusterName string) *types.WorkloadReadiness {
+	readiness, err := s.resourceOps.WaitForReady(ctx, resourceType, namespace, name, waitTimeout(timeoutSeconds), clusterName)
+	if err != nil {
+		return &types.WorkloadReadiness{
+			Name:      name,
+			Namespace: namespace,
+			Summary:   "readiness unavailable: " + err.Error(),
+			Error:     err.Error(),
+		}
+	}
+	return readiness
+}
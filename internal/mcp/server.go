@@ -4,16 +4,26 @@ package mcp
 
 import (
 	"context"
-	"crypto/subtle"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"encoding/json"
 
 	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 )
 
 // Server wraps the MCP server with k8s integration
@@ -23,27 +33,359 @@ type Server struct {
 	clusterManager *k8s.ClusterManager
 	resourceOps    *k8s.ResourceOperations
 	authToken      string
+	logger         logger.Logger
+
+	// tokenIdentities maps extra bearer tokens to the identity they impersonate
+	// tokenIdentities 将额外的 bearer token 映射到其模拟的身份
+	tokenIdentities map[string]Identity
+
+	// clientCertAuth accepts verified client certificates in place of a bearer token
+	// clientCertAuth 接受已验证的客户端证书代替 bearer token
+	clientCertAuth bool
+
+	// oidc validates bearer tokens as JWTs of an OIDC issuer; nil when not configured
+	// oidc 将 bearer token 作为 OIDC 提供方的 JWT 进行验证，未配置时为 nil
+	oidc *oidcVerifier
+
+	// Fan-out settings for calls across all clusters
+	// 跨集群调用的并发和超时设置
+	fanOutConcurrency int
+	fanOutTimeout     time.Duration
+
+	// subscriptions is nil unless resource subscriptions are enabled
+	// subscriptions 仅在启用资源订阅时非空
+	subscriptions *subscriptionManager
+
+	// audit is nil unless an audit log is configured
+	// audit 仅在配置了审计日志时非空
+	audit *auditLogger
+
+	// clientLogs forwards server logs as notifications/message; nil when callers have their own identities
+	// clientLogs 将服务器日志以 notifications/message 转发，调用者拥有独立身份时为 nil
+	clientLogs *clientLogSink
+
+	// authz asks the authorization webhook about every tool call; nil when not configured
+	// authz 就每次工具调用询问授权 webhook，未配置时为 nil
+	authz *authzWebhook
+
+	// history keeps the most recent tool calls; nil when disabled
+	// history 保存最近的工具调用，禁用时为 nil
+	history *callHistory
+
+	// allowExec enables the tools that run processes in pods, such as debug_pod
+	// allowExec 启用在 Pod 中运行进程的工具，例如 debug_pod
+	allowExec bool
+
+	// allowWrite enables the tools that modify cluster objects, such as rollback_deployment and drain_node
+	// allowWrite 启用修改集群对象的工具，例如 rollback_deployment 和 drain_node
+	allowWrite bool
+
+	// allowKubeconfigExport enables get_kubeconfig
+	// allowKubeconfigExport 启用 get_kubeconfig
+	allowKubeconfigExport bool
+
+	// lenientArgs drops unknown tool arguments instead of failing the call
+	// lenientArgs 丢弃未知的工具参数，而不是使调用失败
+	lenientArgs bool
+
+	// language is the default language of prompt answers and fixed result strings ("" for none)
+	// language 是 prompt 回答和结果固定字符串的默认语言（"" 表示不设置）
+	language string
+
+	// sessions holds the cluster and namespace selected by each MCP session
+	// sessions 保存每个 MCP 会话选择的集群和命名空间
+	sessions *sessionStore
+
+	// preferences remembers each caller's cluster and namespace across restarts; nil unless a state file is configured
+	// preferences 在重启后保留每个调用者的集群和命名空间，仅在配置了状态文件时非空
+	preferences *preferenceStore
+
+	// httpRequests holds the contexts of the HTTP requests being served, so calls are
+	// cancelled along with them
+	// httpRequests 保存正在处理的 HTTP 请求的上下文，使调用随请求一起取消
+	httpRequests httpRequests
+
+	// startedAt and toolsPageSize are reported by get_server_info
+	// startedAt 和 toolsPageSize 由 get_server_info 报告
+	startedAt     time.Time
+	toolsPageSize int
+
+	// maxResultBytes is the size above which tool results are truncated
+	// maxResultBytes 是工具结果被截断的大小上限
+	maxResultBytes int
+
+	// protectedNamespaces are refused by delete_namespace besides k8s.DefaultProtectedNamespaces
+	// protectedNamespaces 是除 k8s.DefaultProtectedNamespaces 外 delete_namespace 同样拒绝删除的命名空间
+	protectedNamespaces []string
+
+	// copyAllowedPaths are the directories cp_to_pod may write under
+	// copyAllowedPaths 是 cp_to_pod 允许写入的目录
+	copyAllowedPaths []string
+
+	// methodHandler is the SDK handler without middleware, and callHandler the full
+	// middleware chain, used by batch_call to list and call tools
+	// methodHandler 是不含中间件的 SDK 处理器，callHandler 是完整的中间件处理链，batch_call 用它们列出和调用工具
+	methodHandler mcp.MethodHandler
+	callHandler   mcp.MethodHandler
 }
 
-// NewServer creates a new MCP server instance
-// NewServer 创建一个新的 MCP 服务器实例
-func NewServer(authToken string) *Server {
-	// 创建 ClusterManager，传入 nil 使用默认的 console logger
-	cm := k8s.NewClusterManager(nil)
+// Options configures optional server features
+// Options 配置服务器的可选功能
+type Options struct {
+	// EnableSubscriptions enables resources/subscribe backed by Kubernetes watches
+	// EnableSubscriptions 启用基于 Kubernetes watch 的资源订阅
+	EnableSubscriptions bool
+
+	// ToolsPageSize is the maximum number of tools per tools/list page (0 uses the SDK default)
+	// ToolsPageSize tools/list 每页返回的最大工具数（0 表示使用 SDK 默认值）
+	ToolsPageSize int
+
+	// AuditLog receives one JSON line per tools/call, resources/read and prompts/get (nil disables auditing)
+	// AuditLog 为每个 tools/call、resources/read 和 prompts/get 写入一行 JSON（nil 表示不审计）
+	AuditLog io.Writer
+
+	// Logger is used by the server and cluster manager (nil uses the global logger)
+	// Logger 服务器和集群管理器使用的日志接口（nil 表示使用全局 logger）
+	Logger logger.Logger
+
+	// K8sClient sets the QPS/Burst of every cluster client (zero fields keep the client-go defaults)
+	// K8sClient 设置所有集群客户端的 QPS/Burst（零值字段保留 client-go 默认值）
+	K8sClient k8s.ClientSettings
+
+	// K8sClusterClients overrides K8sClient per cluster name
+	// K8sClusterClients 按集群名称覆盖 K8sClient
+	K8sClusterClients map[string]k8s.ClientSettings
+
+	// NamespacePolicy restricts every operation to the allowed namespaces (nil allows all)
+	// NamespacePolicy 将所有操作限制在允许的命名空间内（nil 时不限制）
+	NamespacePolicy *k8s.NamespacePolicy
+
+	// Impersonate runs every Kubernetes request as this user and groups (empty uses the kubeconfig credential)
+	// Impersonate 使所有 Kubernetes 请求以该用户和组执行（为空时直接使用 kubeconfig 凭据）
+	Impersonate rest.ImpersonationConfig
+
+	// TokenIdentities are extra bearer tokens accepted besides authToken; requests made
+	// with one of them impersonate its identity instead of Impersonate
+	// TokenIdentities 是除 authToken 外额外接受的 bearer token，使用这些 token 的请求模拟其对应身份而不是 Impersonate
+	TokenIdentities map[string]Identity
+
+	// ClientCertAuth accepts requests carrying a verified TLS client certificate without
+	// a bearer token. The certificate CN and O become the caller's user and groups for
+	// auditing and impersonation. The TLS listener must be configured with
+	// NewClientCertTLSConfig for certificates to be verified.
+	// ClientCertAuth 接受携带已验证 TLS 客户端证书但没有 bearer token 的请求，证书的 CN 和 O
+	// 作为调用者的用户和组用于审计和身份模拟。TLS 监听器需使用 NewClientCertTLSConfig 配置才会验证证书。
+	ClientCertAuth bool
+
+	// OIDC accepts bearer tokens that are JWTs of this OIDC issuer besides authToken and
+	// TokenIdentities. The username and groups claims become the caller's user and groups
+	// for auditing and impersonation. nil disables OIDC.
+	// OIDC 除 authToken 和 TokenIdentities 外，还接受该 OIDC 提供方签发的 JWT 作为 bearer token，
+	// 其用户名和组声明作为调用者的用户和组用于审计和身份模拟。nil 表示不启用。
+	OIDC *OIDCConfig
+
+	// AuthzWebhook authorizes every tool call with an HTTP webhook; nil disables it
+	// AuthzWebhook 通过 HTTP webhook 对每次工具调用进行授权，nil 表示不启用
+	AuthzWebhook *AuthzWebhookConfig
+
+	// AllowExec registers the tools that run processes in pods, such as debug_pod
+	// AllowExec 注册在 Pod 中运行进程的工具，例如 debug_pod
+	AllowExec bool
+
+	// AllowWrite registers the tools that modify cluster objects, such as rollback_deployment
+	// AllowWrite 注册修改集群对象的工具，例如 rollback_deployment
+	AllowWrite bool
+
+	// AllowKubeconfigExport registers get_kubeconfig, which exports the kubeconfig of a context
+	// AllowKubeconfigExport 注册导出上下文 kubeconfig 的 get_kubeconfig 工具
+	AllowKubeconfigExport bool
+
+	// MaxResultBytes is the size above which tool results are truncated; a call may
+	// override it with max_bytes (0 uses DefaultMaxResultBytes)
+	// MaxResultBytes 是工具结果被截断的大小上限，单次调用可以用 max_bytes 覆盖（0 表示使用 DefaultMaxResultBytes）
+	MaxResultBytes int
+
+	// ProtectedNamespaces are refused by delete_namespace besides k8s.DefaultProtectedNamespaces
+	// ProtectedNamespaces 是除 k8s.DefaultProtectedNamespaces 外 delete_namespace 同样拒绝删除的命名空间
+	ProtectedNamespaces []string
+
+	// CopyAllowedPaths are the directories cp_to_pod may write under (nil uses k8s.DefaultCopyAllowedPaths)
+	// CopyAllowedPaths 是 cp_to_pod 允许写入的目录（nil 表示使用 k8s.DefaultCopyAllowedPaths）
+	CopyAllowedPaths []string
+
+	// EagerConnect builds every kubeconfig cluster's client at load instead of on first use
+	// EagerConnect 在加载 kubeconfig 时创建所有集群的客户端，而不是在首次使用时创建
+	EagerConnect bool
+
+	// StateFile persists each caller's selected cluster and namespace so a new session of
+	// the same caller, also after a restart, starts from them, and holds the queries saved
+	// with save_query (empty disables both)
+	// StateFile 持久化每个调用者选择的集群和命名空间，使同一调用者的新会话 (包括重启后) 从这些值开始，并保存 save_query 保存的查询（为空表示均不启用）
+	StateFile string
+
+	// HistorySize is the number of recent tool calls kept for get_call_history and
+	// k8s://server/history (0 uses DefaultHistorySize, a negative value disables it)
+	// HistorySize 是为 get_call_history 和 k8s://server/history 保留的最近工具调用数量（0 表示使用 DefaultHistorySize，负数表示不启用）
+	HistorySize int
+
+	// LenientArgs drops unknown tool arguments with a warning instead of failing the call
+	// LenientArgs 丢弃未知的工具参数并记录警告，而不是使调用失败
+	LenientArgs bool
+
+	// Language is the language prompts ask answers in and fixed strings of tool results use
+	// (LanguageEnglish or LanguageChinese); a client can override it per session with
+	// _meta.language at initialize. Empty adds no answer instruction and keeps English strings
+	// Language 是 prompt 要求的回答语言及工具结果固定字符串使用的语言 (LanguageEnglish 或 LanguageChinese)；
+	// 客户端可以在 initialize 时通过 _meta.language 按会话覆盖。为空时不添加回答语言指令，固定字符串保持英文
+	Language string
+
+	// LogPayloads logs every JSON-RPC message exchanged with clients at debug level, with
+	// secret-looking values redacted; off by default since results can hold cluster data
+	// LogPayloads 以 debug 级别记录与客户端交换的每条 JSON-RPC 消息并脱敏疑似敏感的值；结果可能包含集群数据，因此默认关闭
+	LogPayloads bool
+
+	// PayloadLogLimit is the number of bytes of each logged payload (0 uses DefaultPayloadLogLimit)
+	// PayloadLogLimit 是每个被记录消息体的字节数（0 表示使用 DefaultPayloadLogLimit）
+	PayloadLogLimit int
+}
+
+// NewServer creates a new MCP server instance. A nil opts uses the defaults.
+// NewServer 创建一个新的 MCP 服务器实例，opts 为 nil 时使用默认配置
+func NewServer(authToken string, opts *Options) *Server {
+	if opts == nil {
+		opts = &Options{}
+	}
+	log := opts.Logger
+	if log == nil {
+		log = logger.Get()
+	}
+	// Server logs are not tied to a caller, so they are only forwarded to clients
+	// when every client acts with the server's own identity
+	// 服务器日志不区分调用者，因此只有所有客户端都使用服务器自身身份时才转发给客户端
+	var clientLogs *clientLogSink
+	baseLog := log
+	if len(opts.TokenIdentities) == 0 && !opts.ClientCertAuth && opts.OIDC == nil {
+		clientLogs = newClientLogSink(log)
+		log = logger.NewTee(log, clientLogs)
+	}
+
+	cm := k8s.NewClusterManager(&k8s.Options{
+		Logger:          log,
+		Client:          opts.K8sClient,
+		ClusterClients:  opts.K8sClusterClients,
+		UserAgent:       version.UserAgent("k8s-mcp"),
+		NamespacePolicy: opts.NamespacePolicy,
+		Impersonate:     opts.Impersonate,
+		EagerConnect:    opts.EagerConnect,
+	})
 	resourceOps := k8s.NewResourceOperations(cm)
 
 	server := &Server{
-		clusterManager: cm,
-		resourceOps:    resourceOps,
-		authToken:      authToken,
+		clusterManager:        cm,
+		resourceOps:           resourceOps,
+		authToken:             authToken,
+		logger:                log,
+		fanOutConcurrency:     defaultFanOutConcurrency,
+		fanOutTimeout:         defaultFanOutTimeout,
+		startedAt:             time.Now(),
+		toolsPageSize:         opts.ToolsPageSize,
+		tokenIdentities:       opts.TokenIdentities,
+		allowExec:             opts.AllowExec,
+		allowWrite:            opts.AllowWrite,
+		lenientArgs:           opts.LenientArgs,
+		language:              opts.Language,
+		allowKubeconfigExport: opts.AllowKubeconfigExport,
+		protectedNamespaces:   opts.ProtectedNamespaces,
+		copyAllowedPaths:      opts.CopyAllowedPaths,
+		clientCertAuth:        opts.ClientCertAuth,
+		sessions:              newSessionStore(sessionIdleTimeout),
+		clientLogs:            clientLogs,
+	}
+	if opts.OIDC != nil {
+		server.oidc = newOIDCVerifier(*opts.OIDC)
+	}
+	if opts.StateFile != "" {
+		server.preferences = loadPreferenceStore(opts.StateFile, log)
+	}
+	switch {
+	case opts.HistorySize == 0:
+		server.history = newCallHistory(DefaultHistorySize)
+	case opts.HistorySize > 0:
+		server.history = newCallHistory(opts.HistorySize)
+	}
+	server.maxResultBytes = opts.MaxResultBytes
+	if server.maxResultBytes <= 0 {
+		server.maxResultBytes = DefaultMaxResultBytes
+	}
+
+	// The SDK only advertises the subscribe capability when the handlers are set
+	// 只有设置了订阅处理器，SDK 才会声明 subscribe 能力
+	serverOpts := &mcp.ServerOptions{
+		PageSize:          opts.ToolsPageSize,
+		CompletionHandler: server.handleComplete,
+	}
+	if opts.EnableSubscriptions {
+		server.subscriptions = newSubscriptionManager(server.watchResource, server.notifyResourceUpdated)
+		serverOpts.SubscribeHandler = server.handleSubscribe
+		serverOpts.UnsubscribeHandler = server.handleUnsubscribe
 	}
 
 	// Initialize MCP server using SDK
 	// 使用 SDK 初始化 MCP 服务器
 	server.mcpServer = mcp.NewServer(&mcp.Implementation{
 		Name:    "k8s-mcp-server",
-		Version: "1.0.0",
-	}, nil)
+		Title:   "Kubernetes MCP Server",
+		Version: version.Version,
+	}, serverOpts)
+
+	if clientLogs != nil {
+		clientLogs.start(server.mcpServer)
+	}
+
+	server.mcpServer.AddReceivingMiddleware(captureHandler(&server.methodHandler))
+	server.mcpServer.AddReceivingMiddleware(server.argumentsMiddleware)
+	server.mcpServer.AddReceivingMiddleware(server.toolErrorMiddleware)
+	server.mcpServer.AddReceivingMiddleware(server.skippedNamespacesMiddleware)
+	server.mcpServer.AddReceivingMiddleware(server.protocolMiddleware)
+	server.mcpServer.AddReceivingMiddleware(server.instructionsMiddleware)
+	server.mcpServer.AddReceivingMiddleware(server.resultLimitMiddleware)
+
+	// Inside auditing and history so denied calls are recorded, outside of the tool handlers
+	// 位于审计和调用历史之内，使被拒绝的调用同样被记录
+	if opts.AuthzWebhook != nil {
+		server.authz = newAuthzWebhook(*opts.AuthzWebhook)
+		server.mcpServer.AddReceivingMiddleware(server.authzMiddleware)
+	}
+
+	if opts.AuditLog != nil {
+		server.audit = &auditLogger{w: opts.AuditLog}
+		server.mcpServer.AddReceivingMiddleware(server.auditMiddleware)
+	}
+	if server.history != nil {
+		server.mcpServer.AddReceivingMiddleware(server.historyMiddleware)
+	}
+
+	server.mcpServer.AddReceivingMiddleware(server.sessionMiddleware)
+
+	if len(opts.TokenIdentities) > 0 || opts.ClientCertAuth || opts.OIDC != nil {
+		server.mcpServer.AddReceivingMiddleware(server.impersonationMiddleware)
+	}
+
+	server.mcpServer.AddReceivingMiddleware(server.httpCancelMiddleware)
+
+	// Added last so it is the outermost middleware and also catches panics re-raised by auditing
+	// 最后添加，使其成为最外层中间件，同样能捕获审计中间件重新抛出的 panic
+	server.mcpServer.AddReceivingMiddleware(server.recoverMiddleware)
+	server.mcpServer.AddReceivingMiddleware(captureHandler(&server.callHandler))
+
+	// Outside of everything so it sees the messages as they are on the wire. Payloads are never
+	// forwarded to clients as log notifications: they may belong to another caller
+	// 位于所有中间件之外，记录与传输中一致的消息。消息体不会作为日志通知转发给客户端：它们可能属于其他调用者
+	if opts.LogPayloads {
+		payloads := newPayloadLogger(baseLog, opts.PayloadLogLimit)
+		server.mcpServer.AddReceivingMiddleware(payloads.receivingMiddleware)
+		server.mcpServer.AddSendingMiddleware(payloads.sendingMiddleware)
+	}
 
 	return server
 }
@@ -60,99 +402,523 @@ func (s *Server) LoadKubeConfig(configPath string) error {
 	return s.clusterManager.LoadKubeConfigAndInitCluster(configPath)
 }
 
+// LoadKubeConfigs loads and merges the kubeconfig files of configPath, a list separated
+// like $KUBECONFIG, and of configDir (every *.yaml and *.yml file)
+// LoadKubeConfigs 加载并合并 configPath (与 $KUBECONFIG 相同分隔方式的列表) 和 configDir (其中所有 *.yaml 和 *.yml 文件) 中的 kubeconfig 文件
+func (s *Server) LoadKubeConfigs(configPath, configDir string) error {
+	return s.clusterManager.LoadKubeConfigs(configPath, configDir)
+}
+
+// LoadMockCluster serves the in-memory mock cluster seeded from dataDir (the built-in
+// fixtures if empty) instead of real clusters
+// LoadMockCluster 使用由 dataDir（为空时使用内置资源集）预置的内存模拟集群代替真实集群
+func (s *Server) LoadMockCluster(dataDir string) error {
+	return s.clusterManager.LoadMockCluster(dataDir)
+}
+
+// Cluster probes run after the kubeconfig is loaded so the first tool call
+// doesn't pay for discovering unreachable clusters
+// 加载 kubeconfig 后进行集群探测，避免第一次工具调用才发现集群不可达
+const (
+	clusterProbeConcurrency = 4
+	clusterProbeTimeout     = 5 * time.Second
+)
+
+// ProbeClusters health-checks every loaded cluster and caches the reachability
+// reported by the k8s://clusters resource. It blocks until all probes finish.
+// ProbeClusters 检查所有已加载集群的健康状态，并缓存 k8s://clusters 资源报告的可达性，会阻塞直到所有探测完成。
+func (s *Server) ProbeClusters(ctx context.Context) {
+	s.clusterManager.ProbeClusters(ctx, clusterProbeConcurrency, clusterProbeTimeout)
+}
+
+// WarmUpCurrentCluster builds and probes the client of the current cluster only; the
+// other clusters build their clients on first use. It blocks until the probe finishes.
+// WarmUpCurrentCluster 只创建并探测当前集群的客户端，其他集群在首次使用时创建客户端，会阻塞直到探测完成。
+func (s *Server) WarmUpCurrentCluster(ctx context.Context) {
+	s.clusterManager.WarmUpCurrentCluster(ctx, clusterProbeTimeout)
+}
+
+// wait_for timeouts; the cap keeps a tool call from holding a watch open indefinitely
+// wait_for 的超时时间，上限避免单次工具调用无限期占用监听
+const (
+	defaultWaitTimeout = 60 * time.Second
+	maxWaitTimeout     = 300 * time.Second
+)
+
+// waitTimeout converts a timeout_seconds argument, applying the default and the cap
+// waitTimeout 转换 timeout_seconds 参数，应用默认值和上限
+func waitTimeout(seconds int) time.Duration {
+	timeout := defaultWaitTimeout
+	if seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout > maxWaitTimeout {
+		timeout = maxWaitTimeout
+	}
+	return timeout
+}
+
+// resourceTypesHint lists the plural resource types accepted by resource_type; singular forms work too
+// resourceTypesHint 列出 resource_type 接受的复数资源类型，单数形式同样可用
+const resourceTypesHint = "pods, services, deployments, statefulsets, configmaps, secrets, namespaces, nodes, events, persistentvolumes, persistentvolumeclaims, ingresses, networkpolicies, horizontalpodautoscalers, poddisruptionbudgets, cronjobs, jobs, plus routes and projects on OpenShift clusters"
+
 // RegisterTools registers all k8s tools
 // RegisterTools 注册所有 k8s 工具
 func (s *Server) RegisterTools() {
-	// Register tools using SDK's AddTool
-	// 使用 SDK 的 AddTool 注册工具
+	// Register tools with addTool, which returns handler errors with their classification
+	// 使用 addTool 注册工具，处理器返回的错误会附带其分类
 
 	// get_cluster_status
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_cluster_status",
-		Description: "Get cluster status information (version, node count, namespace count)",
+		Description: "Get cluster status information: version, control plane endpoint, node, namespace, pod and CRD counts, whether the metrics.k8s.io and apiextensions.k8s.io APIs are served, and the minor version skew of the node kubelets against the API server. Optional parts that fail or time out are listed under 'unavailable' instead of failing the call. Parameters: cluster_name (string, optional, '*' for all clusters), all_clusters (bool, optional)",
 	}, s.handleGetClusterStatus)
 
+	// get_server_info
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_server_info",
+		Description: "Get information about this k8s-mcp server: version, git commit, build date, uptime, number of loaded clusters and enabled features. No parameters",
+	}, s.handleGetServerInfo)
+
+	// get_server_capabilities
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_server_capabilities",
+		Description: "Get what this server allows, the same summary as the initialize instructions: each optional feature (write, exec, kubeconfig_export, ...) with whether it is enabled, the flag that enables it and the tools it registers, the namespaces operations are restricted to, the result size, fan-out and wait limits, and the client-side rate limits of the Kubernetes API requests. Check it before using a tool that may not be enabled. No parameters",
+	}, s.handleGetServerCapabilities)
+
+	// refresh_discovery
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "refresh_discovery",
+		Description: "Drop the cached discovery information of a cluster (the API resources used to map kinds to resources, the served APIs and the OpenAPI schemas of explain_resource) so it is fetched again on next use, e.g. after installing a CRD or an aggregated API. A kind missing from the cache already triggers one refresh on its own. Parameters: cluster_name (string, optional)",
+	}, s.handleRefreshDiscovery)
+
+	// get_call_history
+	if s.history != nil {
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        historyToolName,
+			Description: "List recent tool calls made to this server, newest first: time, caller, tool, redacted arguments, cluster, outcome and duration. Callers with their own identity only see their own calls; calls to this tool are not recorded. Parameters: tool (string, optional, only calls of this tool), since (string, optional, relative duration like 15m or RFC3339 timestamp), only_errors (bool, optional, only failed calls), limit (int, optional, default 50)",
+		}, s.handleGetCallHistory)
+	}
+
+	// list_clusters
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_clusters",
+		Description: "List the loaded clusters with the current one marked, checking each for reachability and its Kubernetes version (concurrently, 3s per cluster; results are cached for 30 seconds). Contexts that failed to load are listed as unreachable. Returns a table with NAME, CURRENT, STATUS, VERSION and, when the clusters come from several kubeconfig files, SOURCE columns in 'clusters', and items with name, current, reachable, version and error. Use it before operating on a cluster to know whether it is up. Parameters: skip_health_check (bool, optional, list the names only without checking), columns (string, optional, comma-separated subset of the table columns, e.g. 'name,status')",
+	}, s.handleListClusters)
+
+	// get_current_cluster
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_current_cluster",
+		Description: "Get the cluster and namespace that tools use by default in this session, and whether they were chosen with switch_cluster/set_namespace ('session') or are the server defaults ('server'). No parameters",
+	}, s.handleGetCurrentCluster)
+
+	// switch_cluster
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "switch_cluster",
+		Description: "Make a cluster the default for the rest of this session only; other clients keep their own. The session namespace resets to that cluster's kubeconfig context namespace. Idle sessions return to the server defaults after 5 minutes. Parameters: cluster_name (string, required)",
+	}, s.handleSwitchCluster)
+
+	// set_namespace
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "set_namespace",
+		Description: "Make a namespace the default for namespaced tools for the rest of this session only, until switch_cluster or idle expiry. Parameters: namespace (string, required)",
+	}, s.handleSetNamespace)
+
+	// list_resources
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_resources",
+		Description: "List resources of a given type. Parameters: resource_type (string, required, one of " + resourceTypesHint + "), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), cronjob (string, optional, with resource_type jobs only lists jobs owned by this cronjob), cluster_name (string, optional, '*' for all clusters), all_clusters (bool, optional), format (string, optional, 'json' (default) or 'text' for a table with kubectl-like columns per resource type, long values truncated), columns (string, optional, with format 'text', comma-separated columns to show, e.g. 'name,status'; 'labels' is only shown when asked for)",
+	}, s.handleListResources)
+
+	// search_resources
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "search_resources",
+		Description: "Find resources by name substring and/or label selector across resource types and namespaces. Parameters: query (string, optional, case-insensitive name substring), resource_types (array of string, optional, defaults to pods, deployments, statefulsets, services, configmaps, secrets), label_selector (string, optional), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), max_results (int, optional, default 200), cluster_name (string, optional)",
+	}, s.handleSearchResources)
+
 	// list_pods
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_pods",
-		Description: "List pods in a namespace. Parameters: namespace (string, required)",
+		Description: "List pods in a namespace with their status, readiness, restarts and owning controller as kind/name (ReplicaSets resolved to their Deployment, <none> for bare pods). Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional)",
 	}, s.handleListPods)
 
 	// list_services
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_services",
-		Description: "List services in a namespace. Parameters: namespace (string, required)",
+		Description: "List services in a namespace. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional)",
 	}, s.handleListServices)
 
 	// list_deployments
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_deployments",
-		Description: "List deployments in a namespace. Parameters: namespace (string, required)",
+		Description: "List deployments in a namespace. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional)",
 	}, s.handleListDeployments)
 
 	// list_nodes
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_nodes",
-		Description: "List all nodes in the cluster",
+		Description: "List all nodes in the cluster. Parameters: format (string, optional, 'json' (default) or 'text' for a table with NAME, STATUS, ROLES, AGE and VERSION columns), columns (string, optional, with format 'text', comma-separated columns to show, e.g. 'name,status')",
 	}, s.handleListNodes)
 
+	// describe_node
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "describe_node",
+		Description: "Describe a node like 'kubectl describe node': status and any MemoryPressure/DiskPressure/PIDPressure/NetworkUnavailable conditions first, then kubelet/OS/kernel/container runtime versions, roles, taints, all conditions with transition times, capacity and allocatable, the non-terminated pods on the node with their CPU/memory requests and limits, and the 'Allocated resources' totals as a share of allocatable. Parameters: node_name (string, required), cluster_name (string, optional)",
+	}, s.handleDescribeNode)
+
 	// list_namespaces
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_namespaces",
-		Description: "List all namespaces in the cluster",
+		Description: "List all namespaces in the cluster with status and age. On OpenShift, callers not allowed to list namespaces get their projects instead. Parameters: cluster_name (string, optional, '*' for all clusters), all_clusters (bool, optional), include_quotas (bool, optional, also fetch ResourceQuotas and LimitRanges), format (string, optional, 'json' (default) or 'text'), columns (string, optional, with format 'text', comma-separated columns of the namespace table, e.g. 'name,status')",
 	}, s.handleListNamespaces)
 
 	// get_resource
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_resource",
-		Description: "Get detailed information about a specific resource (JSON format). Secrets will be redacted. Parameters: resource_type (string, required, e.g. 'pods' or 'pod'), name (string, required), namespace (string, required)",
+		Description: "Get detailed information about a specific resource. Secrets will be redacted and metadata.managedFields, the last-applied-configuration annotation and empty fields are stripped by default. Parameters: resource_type (string, required, one of " + resourceTypesHint + " except events, e.g. 'pods' or 'pod'), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), format (string, optional, 'json' (default) or 'yaml'), include_managed_fields (bool, optional), include_raw (bool, optional, return the object unmodified), jsonpath (string, optional, return only the matching value(s), one per line, e.g. 'spec.template.spec.containers[0].image', \"metadata.labels['app.kubernetes.io/name']\" or a kubectl template such as '{.status.replicas}'; format is ignored), cluster_name (string, optional)",
 	}, s.handleGetResource)
 
 	// get_resource_yaml
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_resource_yaml",
-		Description: "Get the full YAML definition of a resource. Secrets will be redacted. Parameters: resource_type (string, required, e.g. 'pods' or 'pod'), name (string, required), namespace (string, required)",
+		Description: "Get the full YAML definition of a resource, suitable for kubectl apply. Secrets will be redacted and metadata.managedFields, the last-applied-configuration annotation and empty fields are stripped by default. Parameters: resource_type (string, required, one of " + resourceTypesHint + " except events, e.g. 'pods' or 'pod'), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), format (string, optional, 'yaml' (default) or 'json'), include_managed_fields (bool, optional), include_raw (bool, optional, return the object unmodified), cluster_name (string, optional)",
 	}, s.handleGetResourceYAML)
 
+	// explain_resource
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "explain_resource",
+		Description: "Describe a resource type or one of its fields, like 'kubectl explain', from the OpenAPI v3 schema the cluster publishes, so CRDs and aggregated APIs are covered: the kind and API version, the field's type and description, and its immediate children with their types (e.g. []Container, map[string]string, ObjectMeta), whether they are required and the first sentence of their description. List fields are stepped through to their items, so spec.template.spec.containers.image works. An unknown field returns the nearest valid parent path and its fields as a hint. Schemas are cached per cluster for 10 minutes. Parameters: resource_type (string, required, plural, singular or short name or kind, optionally with the group, e.g. deployments, deploy, crontabs.stable.example.com), field_path (string, optional, dot-separated, e.g. spec.template.spec.containers), cluster_name (string, optional)",
+	}, s.handleExplainResource)
+
+	// diff_resource
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "diff_resource",
+		Description: "Show a unified diff between the live object and a manifest, like kubectl diff (read-only). status, managedFields, resourceVersion and creationTimestamp are ignored and secret values are redacted. Parameters: manifest (string, required, YAML or JSON of a single object), cluster_name (string, optional)",
+	}, s.handleDiffResource)
+
+	// get_change_info
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_change_info",
+		Description: "Answer 'who changed this and when' for a resource: the field managers from metadata.managedFields, newest first, each with its operation (Apply or Update), subresource, time and the fields it owns as readable paths (e.g. spec.template.spec.containers[name=app].image), the manager and time of the last change, whether the kubectl.kubernetes.io/last-applied-configuration annotation is present, and the last 10 events about the object. Parameters: resource_type (string, required, one of " + resourceTypesHint + " except events, e.g. 'deployments'), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace; ignored for cluster-scoped types), cluster_name (string, optional)",
+	}, s.handleGetChangeInfo)
+
+	// compare_resource
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "compare_resource",
+		Description: "Compare the same resource in two clusters, e.g. staging and prod, to find configuration drift (read-only). status, resourceVersion, uid, managedFields, creationTimestamp, controller-set annotations and cluster-allocated fields (clusterIP, nodePort, nodeName, volumeName) are ignored and secret values are redacted. Returns a unified diff from cluster_a to cluster_b and a short summary such as 'image of container web differs: v1.2 in staging vs v1.3 in prod' or 'env var FOO of container web only in prod'; an object missing from a cluster is reported explicitly. Parameters: resource_type (string, required, one of " + resourceTypesHint + " except events), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace of cluster_a), cluster_a (string, required), cluster_b (string, required)",
+	}, s.handleCompareResource)
+
+	// compare_namespace
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "compare_namespace",
+		Description: "Compare which objects exist in a namespace of two clusters (read-only): per resource type, the names only in cluster_a, only in cluster_b, and those in both that differ or are identical after the same normalization as compare_resource. Use compare_resource on a name listed as different to see the diff. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace of cluster_a), resource_types (array of string, optional, any of configmaps, cronjobs, deployments, ingresses, secrets, services, statefulsets; defaults to deployments and configmaps), cluster_a (string, required), cluster_b (string, required)",
+	}, s.handleCompareNamespace)
+
 	// get_events
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_events",
-		Description: "Get cluster events. Parameters: namespace (string, required)",
+		Description: "Get cluster events, optionally only those last seen within a time window; the resolved absolute window is echoed in 'window'. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), since (string, optional, RFC3339 timestamp with timezone or a duration before now such as 15m, 2h, 1d), until (string, optional, same format as since)",
 	}, s.handleGetEvents)
 
+	// stream_events
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "stream_events",
+		Description: "Watch the events of a namespace for a bounded time, e.g. while restarting a deployment, and return the events created or updated in that time in arrival order, each with its offset from the start (e.g. '+2.5s'). Only new events are returned. When the request carries a progress token, each event is also sent as it arrives in a notifications/progress message whose message is the event as JSON. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), kind (string, optional, involved object kind, e.g. 'Pod'), name (string, optional, involved object name), duration_seconds (int, optional, default 30, max 120), cluster_name (string, optional)",
+	}, s.handleStreamEvents)
+
 	// get_pod_logs
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_pod_logs",
-		Description: "Get pod logs. Default tail_lines=100, max_bytes=1MB. Parameters: pod_name (string, required), namespace (string, required), container_name (string, optional), tail_lines (int, optional), previous (bool, optional), cluster_name (string, optional)",
+		Description: "Get pod logs, optionally within a time window whose resolved absolute bounds are echoed in 'window'. Default tail_lines=100, max_bytes=1MB. Parameters: pod_name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), container_name (string, optional), tail_lines (int, optional), previous (bool, optional), since (string, optional, RFC3339 timestamp with timezone or a duration before now such as 15m, 2h, 1d; returns every line since then unless tail_lines is set), until (string, optional, same format as since; drops later lines and tail_lines then counts back from it), since_time (string, optional, older name of since), timestamps (bool, optional, prefix each line with its RFC3339Nano timestamp), cluster_name (string, optional)",
 	}, s.handleGetPodLogs)
 
+	// explain_pod_failure
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "explain_pod_failure",
+		Description: "Explain why a pod is failing. Gathers the state of every container (waiting and terminated reasons, exit codes, OOMKilled), the last 20 events of the pod and the last 50 lines of the previous logs of restarted containers, then maps them to findings as symptom, evidence and likely cause. Recognized patterns: OOMKilled (memory limit), failing liveness probe, image pull rejected by the registry (credentials) or image not found, missing ConfigMap or Secret, crash loop with a configuration error in the logs, and other crash loops. Returns JSON plus the same report as text in 'text'. Start here when a pod is in CrashLoopBackOff, ImagePullBackOff or keeps restarting. Parameters: pod_name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleExplainPodFailure)
+
 	// check_rbac_permission
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "check_rbac_permission",
 		Description: "Check if the current user has permission to perform an action (kubectl auth can-i). Parameters: verb (string, required, e.g. 'get', 'list'), resource (string, required, e.g. 'pods'), namespace (string, required)",
 	}, s.handleCheckRBACPermission)
 
+	// check_permissions
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "check_permissions",
+		Description: "Check whether the caller's Kubernetes identity (the impersonated user when impersonation is configured) may perform an action, using a SelfSubjectAccessReview. Use it before an action that may be forbidden. Parameters: verb (string, required, e.g. 'get', 'list', 'delete'), resource (string, required, e.g. 'pods'), group (string, optional, API group such as 'apps'), subresource (string, optional, e.g. 'log'), name (string, optional), namespace (string, optional, empty for cluster-scoped resources or all namespaces), cluster_name (string, optional)",
+	}, s.handleCheckPermissions)
+
+	// can_i
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "can_i",
+		Description: "Check whether an action is allowed, like 'kubectl auth can-i'. Returns allowed plus the authorizer's reason, useful to pre-check an action or explain an RBAC denial. Parameters: verb (string, required, e.g. 'get', 'delete', '*'), resource (string, required, kubectl style such as 'pods', 'deployments.apps' or 'pods/log'), subresource (string, optional), name (string, optional), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), cluster_name (string, optional)",
+	}, s.handleCanI)
+
+	// list_permissions
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_permissions",
+		Description: "List everything the current credential can do in a namespace, like 'kubectl auth can-i --list' (SelfSubjectRulesReview). Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleListPermissions)
+
 	// list_configmaps
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_configmaps",
-		Description: "List configmaps in a namespace. Parameters: namespace (string, required)",
+		Description: "List configmaps in a namespace. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional)",
 	}, s.handleListConfigMaps)
 
+	// get_configmap_data
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_configmap_data",
+		Description: "Get only the data of a configmap, without metadata. Returns the whole data map as JSON (binaryData values base64 encoded), or the plain value of a single key. Parameters: name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), key (string, optional), cluster_name (string, optional)",
+	}, s.handleGetConfigMapData)
+
+	// get_secret_keys
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_secret_keys",
+		Description: "List the key names and value sizes (bytes) of a secret. Values are never returned. Parameters: name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleGetSecretKeys)
+
 	// list_statefulsets
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
+	addTool(s.mcpServer, &mcp.Tool{
 		Name:        "list_statefulsets",
-		Description: "List statefulsets in a namespace. Parameters: namespace (string, required)",
+		Description: "List statefulsets in a namespace. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional)",
 	}, s.handleListStatefulSets)
+
+	// get_workload_topology
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_workload_topology",
+		Description: "Map what talks to what in a namespace: ingresses to their backend services, services to the pods their selector matches, and pods to the deployment, statefulset or other controller owning them (through ReplicaSets for deployments). Returns the graph as nodes (id 'Kind/name') and edges (relation 'routes', 'selects' or 'owns') plus the same graph as an indented text tree. Services whose selector matches no pod are flagged 'orphaned', pods without a controller 'unowned', and services an ingress routes to that do not exist 'missing'. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleGetWorkloadTopology)
+
+	// get_service_endpoints
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_service_endpoints",
+		Description: "Explain why a service does or does not route traffic: fetches the service, its EndpointSlices (falling back to the legacy Endpoints object when the discovery API is unavailable) and the pods its selector matches. Reports each service port against the container ports its targetPort resolves to, the ready and not-ready endpoints with the pod and node behind each, the selected pods with their readiness, and warnings for a selector matching no pods, no ready endpoints, unresolved named ports and failing readiness probes. Returns JSON plus the same report as text in 'text'. Parameters: name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleGetServiceEndpoints)
+
+	// get_resource_usage
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_resource_usage",
+		Description: "Sum the CPU and memory requests and limits of the running pods of a namespace (or all namespaces) and compare them against the ResourceQuota hard limits and, for all namespaces, the allocatable resources of the nodes, with percentages. Effective pod requests follow the scheduler: init containers count at their peak, sidecars add to the containers, and a resource with only a limit is requested at the limit. Also counts the pods without requests or limits and lists the top 10 pods by requested CPU and by requested memory. Returns JSON plus the same summary as text tables in 'text'. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), cluster_name (string, optional)",
+	}, s.handleGetResourceUsage)
+
+	// find_issues
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "find_issues",
+		Description: "Scan a namespace (or all namespaces) for common hygiene problems: pods not managed by any controller, deployments scaled to 0 replicas, services without ready endpoints, ConfigMaps and Secrets not referenced by any pod spec (volumes, envFrom, env valueFrom, imagePullSecrets), service account or ingress TLS, images on the :latest tag or untagged, and containers without CPU/memory requests (warning) or limits (info). Images and resources are checked once per workload template rather than per replica; namespaces with LimitRange container defaults are not reported for missing resources, and kube-system objects are not reported as unused. Each finding has a severity (warning or info), the offending object and a one-line remediation hint. Lists are paged and capped at 5000 objects per kind; checks that need a truncated or unlistable kind (e.g. secrets without RBAC) are skipped and reported under 'skipped'. Returns JSON plus the findings grouped by category as text in 'text'. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), cluster_name (string, optional)",
+	}, s.handleFindIssues)
+
+	// check_references
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "check_references",
+		Description: "List what in a namespace still uses a ConfigMap or Secret, before deleting or renaming it: deployments, statefulsets, daemonsets, cronjobs and pods referencing it through volumes (including projected and CSI volumes), env valueFrom, envFrom or imagePullSecrets, and for Secrets also service accounts and ingress TLS. Each referrer lists how it references the object. Pods created by a workload are only listed when the workload itself no longer references the object, e.g. pods of an old ReplicaSet. Uses the same checks as the unused ConfigMap and Secret findings of find_issues. Kinds that cannot be listed are reported under 'skipped', in which case the list may be incomplete. Parameters: resource_type (string, required, configmaps or secrets), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), cluster_name (string, optional)",
+	}, s.handleCheckReferences)
+
+	// list_images
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_images",
+		Description: "List the deduplicated container images running in a namespace (or all namespaces), e.g. to hand them to a vulnerability scanner. Each image has its registry, repository, tag and digest, whether it is mutable (not pinned by digest, including :latest and untagged images), the imagePullPolicy values, the pull secrets referenced (namespace/name) and the workloads using it with their container names; init and ephemeral containers are included, prefixed 'init:' and 'ephemeral:'. Pods are reported as the workload that owns them (a Deployment for ReplicaSet pods), so replicas count once. group_by=registry adds image, workload and mutable image counts per registry host. format=text adds a rendering sorted by usage count in 'text'. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), source (string, optional, 'pods' (default, running pods) or 'deployments' (deployment pod templates)), group_by (string, optional, 'registry'), format (string, optional, 'json' (default) or 'text'), cluster_name (string, optional)",
+	}, s.handleListImages)
+
+	// list_helm_releases
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_helm_releases",
+		Description: "List the Helm releases of a namespace with their latest revision, like 'helm list': release name, namespace, chart name and version, app version, status (deployed, failed, pending-upgrade, ...), revision and last deployed time. Read directly from Helm's release Secrets (type helm.sh/release.v1); no helm binary is needed. Use it to answer which chart version is deployed. Parameters: namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), all_namespaces (bool, optional), cluster_name (string, optional)",
+	}, s.handleListHelmReleases)
+
+	// get_helm_release
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_helm_release",
+		Description: "Get one revision of a Helm release: the fields of list_helm_releases plus its description, the computed values (chart defaults merged with the user-supplied values, like 'helm get values --all'; secret-looking keys such as password or token are redacted) and the objects its manifest created (api_version, kind, name, namespace). Parameters: name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), revision (int, optional, defaults to the latest), cluster_name (string, optional)",
+	}, s.handleGetHelmRelease)
+
+	// wait_for
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "wait_for",
+		Description: "Wait until a resource meets a condition, e.g. after an action: pods Ready/ContainersReady/Initialized/PodScheduled/Running/Succeeded/Failed, deployments Available/Progressing/Complete (rollout finished), statefulsets Ready, daemonsets Ready, nodes Ready, namespaces Active, and Deleted for any type. Returns as soon as the condition holds with the elapsed time, or an error with the last observed status on timeout. Parameters: resource_type (string, required), name (string, required), condition (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), timeout_seconds (int, optional, default 60, max 300), cluster_name (string, optional)",
+	}, s.handleWaitFor)
+
+	// generate_cluster_report
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "generate_cluster_report",
+		Description: "Generate a one-shot snapshot of a cluster: version and node summary, namespaces with pod and deployment counts, workloads not fully ready, Warning events from the last hour, node pressure conditions and unbound PVCs. Sections that cannot be fetched are marked 'section unavailable: <reason>'. Parameters: format (string, optional, 'markdown' (default) or 'json'), cluster_name (string, optional)",
+	}, s.handleGenerateClusterReport)
+
+	// rollout_history
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "rollout_history",
+		Description: "List the revisions of a deployment from its ReplicaSets, like 'kubectl rollout history': revision, creation time, replicas, change-cause and container images. Pass revision to also get that revision's full pod template as YAML. Parameters: name (string, required, deployment name), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), revision (int, optional), cluster_name (string, optional)",
+	}, s.handleRolloutHistory)
+
+	// batch_call
+	addTool(s.mcpServer, &mcp.Tool{
+		Name:        "batch_call",
+		Description: "Run up to 10 tool calls in one request, e.g. pods, events and deployment status of the same namespace. The calls run concurrently and each is handled, audited and recorded like a separate call; the results come back in the order of the calls, each with the tool name, is_error and the tool's content and structured_content. A failing call does not affect the others. Tools that modify the cluster are refused unless write tools are enabled and serial=true, which runs every call one after another in order; batch_call cannot be nested. Parameters: calls (array, required, each {tool (string), arguments (object, optional)}), serial (bool, optional)",
+	}, s.handleBatchCall)
+
+	// Saved queries live in the state file next to the session preferences
+	// 已保存的查询与会话偏好一起保存在状态文件中
+	if s.preferences != nil {
+		// save_query
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "save_query",
+			Description: "Save a named tool call for the calling user, e.g. 'prod-payments-pods' for list_pods with namespace payments and cluster_name prod, to repeat it later with run_query. Queries are kept per authenticated user (or token) in the server's state file and survive restarts. Secret-looking arguments (token, password, secret, ...) are stored as [REDACTED] and must be passed to run_query. Tools that modify the cluster can only be saved while write tools are enabled. Parameters: name (string, required, up to 64 letters, digits, '.', '_' or '-'), tool (string, required), arguments (object, optional), description (string, optional), overwrite (bool, optional, required to replace an existing query)",
+		}, s.handleSaveQuery)
+
+		// run_query
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "run_query",
+			Description: "Run a query saved with save_query. arguments are merged over the saved ones: a key given here replaces the saved value and null removes it. The tool runs like a direct call and its content is returned as is; the structured result adds the query name, the tool and the merged arguments. Queries of tools that modify the cluster are refused while write tools are disabled. Parameters: name (string, required), arguments (object, optional)",
+		}, s.handleRunQuery)
+
+		// list_queries
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "list_queries",
+			Description: "List the queries the calling user saved with save_query, sorted by name, with their tool, arguments and description. Parameters: none",
+		}, s.handleListQueries)
+
+		// delete_query
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "delete_query",
+			Description: "Delete a query the calling user saved with save_query. Parameters: name (string, required)",
+		}, s.handleDeleteQuery)
+	}
+
+	if s.allowExec {
+		// debug_pod
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "debug_pod",
+			Description: "Add an ephemeral debug container to a running pod, like 'kubectl debug -it'. The container shares the pod's network and keeps a TTY open; the result contains the kubectl commands to attach or exec into it. Requires Kubernetes 1.23+. Parameters: pod_name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), image (string, optional, default 'busybox'), container_name (string, optional, default 'debugger-xxxxx'), command (array of strings, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		}, s.handleDebugPod)
+
+		// cp_from_pod
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "cp_from_pod",
+			Description: "Copy a file out of a running container, like 'kubectl cp' (the container needs tar). The file is returned base64-encoded as a blob in an embedded resource content item with its detected MIME type; the structured result has the path, size and MIME type. Reading stops at max_bytes and the result is then marked truncated with the full size. Directories and paths with '..' are rejected. Parameters: pod (string, required), remote_path (string, required, absolute or relative to the container's working directory), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), container (string, optional, defaults to the kubectl.kubernetes.io/default-container annotation or the first container), max_bytes (int, optional, default 1048576, max 8388608), cluster_name (string, optional)",
+		}, s.handleCpFromPod)
+
+		if s.allowWrite {
+			// cp_to_pod
+			addTool(s.mcpServer, &mcp.Tool{
+				Name:        "cp_to_pod",
+				Description: "Write a file into a running container, like 'kubectl cp' (the container needs tar and the directory must exist). An existing file is overwritten. The destination must be an absolute path inside the server's allowed directories (--copy-allowed-paths, default /tmp); paths with '..' are rejected. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: pod (string, required), remote_path (string, required), content (string, required, base64-encoded file content), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), container (string, optional, defaults to the kubectl.kubernetes.io/default-container annotation or the first container), confirm (bool, optional), cluster_name (string, optional)",
+				Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+			}, s.handleCpToPod)
+		}
+	}
+
+	if s.allowKubeconfigExport {
+		// get_kubeconfig
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "get_kubeconfig",
+			Description: "Export a minimal kubeconfig for one kubeconfig context, e.g. to hand the connection details of a cluster to a colleague. It contains only that context with its cluster and user, with the files they refer to (CA, client certificate) embedded. By default the token, password, client key, auth provider settings and exec environment values are replaced with REDACTED, so the result is a connection template rather than a credential; redact_credentials=false exports them and is refused when the call runs under an impersonated identity. Parameters: context (string, optional, defaults to the context of cluster_name), redact_credentials (bool, optional, default true), cluster_name (string, optional, defaults to the session cluster from switch_cluster)",
+		}, s.handleGetKubeConfig)
+	}
+
+	if s.allowWrite {
+		// rollback_deployment
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "rollback_deployment",
+			Description: "Roll a deployment back to an earlier revision, like 'kubectl rollout undo': its pod template is replaced with the template of that revision (see rollout_history). With wait_for_ready=true the tool then waits like wait_for until the rollout finished and adds a readiness summary to the result: generation vs observed generation, updated/ready/available replicas and, if still not ready at the timeout, the first failing pod and its reason. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: name (string, required, deployment name), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace), revision (int, optional, defaults to the previous revision), wait_for_ready (bool, optional), timeout_seconds (int, optional, default 60, max 300, with wait_for_ready=true), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		}, s.handleRollbackDeployment)
+
+		// cordon_node
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "cordon_node",
+			Description: "Mark a node unschedulable, like 'kubectl cordon': new pods are no longer scheduled on it, running pods are left alone. Changed is false when the node was already cordoned. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: node_name (string, required), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		}, s.handleCordonNode)
+
+		// uncordon_node
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "uncordon_node",
+			Description: "Mark a node schedulable again, like 'kubectl uncordon'. Changed is false when the node was not cordoned. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: node_name (string, required), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		}, s.handleUncordonNode)
+
+		// drain_node
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "drain_node",
+			Description: "Cordon a node and evict its pods through the Eviction API, like 'kubectl drain'. Mirror pods are skipped, and so are DaemonSet pods unless ignore_daemonsets=false. If a pod cannot be drained (DaemonSet pods with ignore_daemonsets=false, pods not managed by a controller, or pods with emptyDir volumes without delete_emptydir_data) nothing is evicted and those pods are listed. Evictions refused by a PodDisruptionBudget are retried until the timeout; pods still blocked are listed with the budget that blocked them. The node stays cordoned either way. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: node_name (string, required), ignore_daemonsets (bool, optional, default true), delete_emptydir_data (bool, optional), grace_period_seconds (int, optional, overrides the pods' termination grace period), timeout_seconds (int, optional, default 60, max 600), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		}, s.handleDrainNode)
+
+		// label_resource
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "label_resource",
+			Description: "Set or remove labels on a resource, like 'kubectl label', with a JSON merge patch. A null value removes the label. Changing the value of an existing label is refused unless overwrite=true. The result shows the labels before and after; changed is false when every label already had the wanted value. For deployments, statefulsets and daemonsets, wait_for_ready=true then waits like wait_for until the rollout finished and adds a readiness summary to the result: generation vs observed generation, updated/ready/available replicas and, if still not ready at the timeout, the first failing pod and its reason. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: resource_type (string, required, e.g. pods, deployments, nodes; events are not supported), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace; ignored for cluster-scoped types), labels (object, required, label key to value or null), overwrite (bool, optional, default false), wait_for_ready (bool, optional), timeout_seconds (int, optional, default 60, max 300, with wait_for_ready=true), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		}, s.handleLabelResource)
+
+		// annotate_resource
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "annotate_resource",
+			Description: "Set or remove annotations on a resource, like 'kubectl annotate', with a JSON merge patch. A null value removes the annotation. Changing the value of an existing annotation is refused unless overwrite=true. The result shows the annotations before and after; changed is false when every annotation already had the wanted value. For deployments, statefulsets and daemonsets, wait_for_ready=true then waits like wait_for until the rollout finished and adds a readiness summary to the result: generation vs observed generation, updated/ready/available replicas and, if still not ready at the timeout, the first failing pod and its reason. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: resource_type (string, required, e.g. pods, deployments, nodes; events are not supported), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace; ignored for cluster-scoped types), annotations (object, required, annotation key to value or null), overwrite (bool, optional, default false), wait_for_ready (bool, optional), timeout_seconds (int, optional, default 60, max 300, with wait_for_ready=true), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		}, s.handleAnnotateResource)
+
+		// delete_resource
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "delete_resource",
+			Description: "Delete a resource, like 'kubectl delete'; objects it owns are garbage collected in the background. Namespaces are refused, use delete_namespace. A ConfigMap or Secret is first checked like check_references, and the delete is refused with the list of referencing workloads while anything still uses it, or when the check could not list everything, unless force=true. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: resource_type (string, required, e.g. configmaps, secrets, pods, deployments; events are not supported), name (string, required), namespace (string, optional, defaults to the session namespace from set_namespace or the kubeconfig context namespace; ignored for cluster-scoped types), force (bool, optional, delete a ConfigMap or Secret that is still referenced), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		}, s.handleDeleteResource)
+
+		// create_namespace
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "create_namespace",
+			Description: "Create a namespace, like 'kubectl create namespace'. Not available in namespace-scoped mode. Parameters: name (string, required), labels (object, optional, label key to value), annotations (object, optional, annotation key to value), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		}, s.handleCreateNamespace)
+
+		// delete_namespace
+		addTool(s.mcpServer, &mcp.Tool{
+			Name:        "delete_namespace",
+			Description: "Delete a namespace and everything in it, like 'kubectl delete namespace'. default, kube-system, kube-public, kube-node-lease and the server's --protected-namespaces are refused. The result counts the workloads (pods, deployments, statefulsets, daemonsets, jobs, cronjobs) the namespace still held. With wait=true the tool blocks until the namespace is gone; if it is still terminating at the timeout, the finalizers and conditions holding it up are listed. Requires confirmation: the user is asked through elicitation, or clients without elicitation support must pass confirm=true. Parameters: name (string, required), wait (bool, optional), timeout_seconds (int, optional, default 60, max 600, with wait=true), confirm (bool, optional), cluster_name (string, optional)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		}, s.handleDeleteNamespace)
+	}
+}
+
+// boolPtr returns a pointer to b, for the optional hints of tool annotations
+// boolPtr 返回指向 b 的指针，用于工具注解中的可选提示
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// RemoveTools unregisters tools at runtime; connected clients receive notifications/tools/list_changed
+// RemoveTools 在运行时注销工具，已连接的客户端会收到 notifications/tools/list_changed
+func (s *Server) RemoveTools(names ...string) {
+	s.mcpServer.RemoveTools(names...)
 }
 
 // AuthMiddleware creates an authentication middleware
 // AuthMiddleware 创建认证中间件
 func (s *Server) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A verified client certificate is enough on its own; a bearer token sent
+		// along with it must still be valid
+		// 已验证的客户端证书本身即可通过认证；同时携带的 bearer token 仍需有效
+		_, hasCert := setCertIdentityHeaders(r)
+		hasCert = hasCert && s.clientCertAuth
+		r.Header.Del(oidcUserHeader)
+		r.Header.Del(oidcGroupsHeader)
+
 		// Check for Authorization header
 		// 检查 Authorization 头
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && hasCert {
+			next.ServeHTTP(w, r)
+			return
+		}
 		if authHeader == "" {
+			if s.oidc != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="k8s-mcp"`)
+			}
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
 			return
 		}
@@ -166,9 +932,21 @@ func (s *Server) AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		token := authHeader[len(prefix):]
-		if subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
+		if !s.validToken(token) {
+			if s.oidc == nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			// Not a static token, so it must be a JWT of the OIDC issuer
+			// 不是静态 token，则必须是 OIDC 提供方签发的 JWT
+			identity, err := s.oidc.verify(r.Context(), token)
+			if err != nil {
+				s.logger.Debug("Rejected OIDC token", "error", err)
+				writeInvalidToken(w, err)
+				return
+			}
+			setOIDCIdentityHeaders(r, identity)
 		}
 
 		// Token is valid, proceed to next handler
@@ -185,13 +963,21 @@ func (s *Server) CreateHTTPHandler() http.Handler {
 	mcpHandler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
 		return s.mcpServer
 	}, &mcp.StreamableHTTPOptions{
-		SessionTimeout: 5 * time.Minute,
+		SessionTimeout: sessionIdleTimeout,
 		Stateless:      false,
 	})
 
-	// Wrap with authentication middleware
-	// 使用认证中间件包装
-	return s.AuthMiddleware(mcpHandler)
+	// Wrap with JSON-RPC validation, request tracking and authentication middleware;
+	// compression is outermost so gzip request bodies are inflated before they are validated
+	// 使用 JSON-RPC 校验、请求跟踪和认证中间件包装；压缩位于最外层，使 gzip 请求体在校验之前解压
+	mux := http.NewServeMux()
+	mux.Handle("/", CompressionMiddleware(s.AuthMiddleware(s.HTTPRequestMiddleware(ValidateJSONRPCMiddleware(mcpHandler)))))
+
+	// The WebSocket transport is authenticated the same way; its frames are neither
+	// compressed nor validated as HTTP bodies
+	// WebSocket 传输使用相同的认证；其帧不作为 HTTP 请求体压缩或校验
+	mux.Handle(WebSocketPath, s.AuthMiddleware(s.HTTPRequestMiddleware(s.webSocketHandler())))
+	return mux
 }
 
 // Close closes the server
@@ -199,6 +985,12 @@ func (s *Server) CreateHTTPHandler() http.Handler {
 func (s *Server) Close() error {
 	// The SDK server doesn't have a Close method, but we can clean up k8s clients if needed
 	// SDK 服务器没有 Close 方法，但如果需要我们可以清理 k8s 客户端
+	if s.subscriptions != nil {
+		s.subscriptions.close()
+	}
+	if s.clientLogs != nil {
+		s.clientLogs.close()
+	}
 	return nil
 }
 
@@ -208,25 +1000,65 @@ func (s *Server) Close() error {
 // ClusterStatusResult represents the result of get_cluster_status tool
 // ClusterStatusResult 表示 get_cluster_status 工具的结果
 type ClusterStatusResult struct {
-	Status string `json:"status"`
+	Status string             `json:"status"`
+	Info   *ClusterStatusInfo `json:"info,omitempty"`
+}
+
+// ClusterStatusInfo is the structured form of a single cluster's status
+// ClusterStatusInfo 单个集群状态的结构化形式
+type ClusterStatusInfo struct {
+	Cluster        string `json:"cluster"`
+	Version        string `json:"version"`
+	Platform       string `json:"platform"`
+	BuildDate      string `json:"build_date,omitempty"`
+	NodeCount      int    `json:"node_count"`
+	NamespaceCount int    `json:"namespace_count"`
+	// The fields below come from optional queries; failed ones are listed in Unavailable
+	// 以下字段来自可选查询，失败的查询列在 Unavailable 中
+	Endpoint                  string             `json:"endpoint,omitempty"`
+	PodCount                  *int               `json:"pod_count,omitempty"`
+	CRDCount                  *int               `json:"crd_count,omitempty"`
+	MetricsAPIAvailable       *bool              `json:"metrics_api_available,omitempty"`
+	APIExtensionsAPIAvailable *bool              `json:"apiextensions_api_available,omitempty"`
+	VersionSkew               *types.VersionSkew `json:"version_skew,omitempty"`
+	Unavailable               map[string]string  `json:"unavailable,omitempty"`
+}
+
+// ResourcesResult represents the result of list_resources tool
+// ResourcesResult 表示 list_resources 工具的结果
+type ResourcesResult struct {
+	Resources string             `json:"resources"`
+	Items     []k8s.ResourceInfo `json:"items,omitempty"`
+	Scope     string             `json:"scope,omitempty"`
+}
+
+// SearchResult represents the result of search_resources tool
+// SearchResult 表示 search_resources 工具的结果
+type SearchResult struct {
+	Resources string `json:"resources"`
+	Scope     string `json:"scope,omitempty"`
+	Note      string `json:"note,omitempty"`
 }
 
 // PodsResult represents the result of list_pods tool
 // PodsResult 表示 list_pods 工具的结果
 type PodsResult struct {
-	Pods string `json:"pods"`
+	Pods  string `json:"pods"`
+	Scope string `json:"scope,omitempty"`
 }
 
 // ServicesResult represents the result of list_services tool
 // ServicesResult 表示 list_services 工具的结果
 type ServicesResult struct {
 	Services string `json:"services"`
+	Scope    string `json:"scope,omitempty"`
 }
 
 // DeploymentsResult represents the result of list_deployments tool
 // DeploymentsResult 表示 list_deployments 工具的结果
 type DeploymentsResult struct {
 	Deployments string `json:"deployments"`
+	Scope       string `json:"scope,omitempty"`
 }
 
 // NodesResult represents the result of list_nodes tool
@@ -238,25 +1070,37 @@ type NodesResult struct {
 // NamespacesResult represents the result of list_namespaces tool
 // NamespacesResult 表示 list_namespaces 工具的结果
 type NamespacesResult struct {
-	Namespaces string `json:"namespaces"`
+	Namespaces string            `json:"namespaces"`
+	Items      []types.Namespace `json:"items,omitempty"`
 }
 
 // ConfigMapsResult represents the result of list_configmaps tool
 // ConfigMapsResult 表示 list_configmaps 工具的结果
 type ConfigMapsResult struct {
 	ConfigMaps string `json:"configmaps"`
+	Scope      string `json:"scope,omitempty"`
 }
 
 // StatefulSetsResult represents the result of list_statefulsets tool
 // StatefulSetsResult 表示 list_statefulsets 工具的结果
 type StatefulSetsResult struct {
 	StatefulSets string `json:"statefulsets"`
+	Scope        string `json:"scope,omitempty"`
 }
 
 // ResourceResult represents the result of get_resource tool
 // ResourceResult 表示 get_resource 工具的结果
 type ResourceResult struct {
 	Resource string `json:"resource"`
+	// Routes lists an ingress's host -> path -> backend mappings
+	// Routes 列出 Ingress 的 host -> path -> 后端映射
+	Routes string `json:"routes,omitempty"`
+}
+
+// DiffResult represents the result of diff_resource tool
+// DiffResult 表示 diff_resource 工具的结果
+type DiffResult struct {
+	Diff string `json:"diff"`
 }
 
 // YAMLResult represents the result of get_resource_yaml tool
@@ -268,13 +1112,29 @@ type YAMLResult struct {
 // EventsResult represents the result of get_events tool
 // EventsResult 表示 get_events 工具的结果
 type EventsResult struct {
+	Window string `json:"window,omitempty"`
 	Events string `json:"events"`
+	Scope  string `json:"scope,omitempty"`
 }
 
 // LogsResult represents the result of get_pod_logs tool
 // LogsResult 表示 get_pod_logs 工具的结果
 type LogsResult struct {
-	Logs string `json:"logs"`
+	Window string `json:"window,omitempty"`
+	Logs   string `json:"logs"`
+}
+
+// ConfigMapDataResult represents the result of get_configmap_data tool
+// ConfigMapDataResult 表示 get_configmap_data 工具的结果
+type ConfigMapDataResult struct {
+	Data     string `json:"data"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// SecretKeysResult represents the result of get_secret_keys tool
+// SecretKeysResult 表示 get_secret_keys 工具的结果
+type SecretKeysResult struct {
+	Keys string `json:"keys"`
 }
 
 // RBACPermissionResult represents the result of check_rbac_permission tool
@@ -284,6 +1144,17 @@ type RBACPermissionResult struct {
 	Reason  string `json:"reason"`
 }
 
+// PermissionsResult represents the result of check_permissions tool
+// PermissionsResult 表示 check_permissions 工具的结果
+type PermissionsResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+	// User and Groups are the impersonated identity; empty when the kubeconfig credential is used
+	// User 和 Groups 为模拟的身份，直接使用 kubeconfig 凭据时为空
+	User   string   `json:"user,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
 // serializeResourceList serializes a list of resources to JSON string
 // serializeResourceList 将资源列表序列化为 JSON 字符串
 func serializeResourceList(resources interface{}) (string, error) {
@@ -295,41 +1166,328 @@ func serializeResourceList(resources interface{}) (string, error) {
 	return string(data), nil
 }
 
+// resolveNamespace picks the namespace a namespaced tool operates on, in order of
+// precedence: the explicit argument, all_namespaces (empty namespace), the default
+// namespace selected by the calling session with set_namespace, the default namespace
+// of the cluster's kubeconfig context, and finally "default". It also returns a short
+// description of the scope for the tool output.
+// resolveNamespace 确定命名空间级工具实际使用的命名空间，优先级依次为：显式参数、
+// all_namespaces（空命名空间）、调用方会话通过 set_namespace 选择的命名空间、
+// 集群 kubeconfig 上下文的默认命名空间，最后是 "default"。同时返回用于工具输出的范围描述。
+func (s *Server) resolveNamespace(ctx context.Context, namespace string, allNamespaces bool, clusterName string) (string, string) {
+	switch {
+	case namespace != "":
+		return namespace, "namespace " + namespace
+	case allNamespaces:
+		if policy := s.clusterManager.NamespacePolicy(); policy.Restricted() {
+			return "", allowedNamespacesScope(policy)
+		}
+		return "", "all namespaces"
+	default:
+		namespace = s.sessionDefaults(ctx).namespace
+		if namespace == "" {
+			namespace = s.clusterManager.GetDefaultNamespace(clusterName)
+		}
+		// In namespace-scoped mode a disallowed default namespace falls back to the allowed set
+		// 命名空间受限模式下，不被允许的默认命名空间回退为允许的命名空间集合
+		if policy := s.clusterManager.NamespacePolicy(); !policy.Allows(namespace) {
+			return "", allowedNamespacesScope(policy)
+		}
+		return namespace, fmt.Sprintf("namespace %s (default, pass namespace or all_namespaces=true to change)", namespace)
+	}
+}
+
+// allowedNamespacesScope describes the scope of a list over the allowed namespaces
+// allowedNamespacesScope 描述遍历允许的命名空间的列表范围
+func allowedNamespacesScope(policy *k8s.NamespacePolicy) string {
+	return "allowed namespaces (" + strings.Join(policy.Patterns(), ",") + ")"
+}
+
 // Tool handlers
 // 工具处理函数
 
 // handleGetClusterStatus handles get_cluster_status tool
 // handleGetClusterStatus 处理 get_cluster_status 工具
-func (s *Server) handleGetClusterStatus(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+func (s *Server) handleGetClusterStatus(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ClusterName string `json:"cluster_name,omitempty"`
+	AllClusters bool   `json:"all_clusters,omitempty"`
+}) (
 	*mcp.CallToolResult,
 	ClusterStatusResult,
 	error,
 ) {
-	info, err := s.resourceOps.GetClusterInfo(ctx, "")
-	if err != nil {
-		return nil, ClusterStatusResult{}, fmt.Errorf("failed to get cluster info: %w", err)
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	if isAllClusters(input.AllClusters, clusterName) {
+		results := s.fanOutClusters(ctx, s.clusterStatusText)
+		return nil, ClusterStatusResult{
+			Status: formatClusterResults(s.sessionLanguage(req.Session), results),
+		}, nil
 	}
 
-	// Format the output
-	// 格式化输出
-	statusText := fmt.Sprintf("Cluster Status:\n  Version: %s\n  Platform: %s\n  Node Count: %d\n  Namespace Count: %d",
-		info["version"], info["platform"], info["nodeCount"], info["namespaceCount"])
+	info, err := s.clusterStatus(ctx, clusterName)
+	if err != nil {
+		return nil, ClusterStatusResult{}, err
+	}
 
 	return nil, ClusterStatusResult{
-		Status: statusText,
+		Status: formatClusterStatus(info),
+		Info:   info,
+	}, nil
+}
+
+// clusterStatus gets cluster info as a ClusterStatusInfo
+// clusterStatus 获取集群信息并转换为 ClusterStatusInfo
+func (s *Server) clusterStatus(ctx context.Context, clusterName string) (*ClusterStatusInfo, error) {
+	info, err := s.resourceOps.GetClusterInfo(ctx, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	status := &ClusterStatusInfo{Cluster: s.resolveClusterName(ctx, clusterName)}
+	status.Version, _ = info["version"].(string)
+	status.Platform, _ = info["platform"].(string)
+	status.BuildDate, _ = info["buildDate"].(string)
+	status.NodeCount, _ = info["nodeCount"].(int)
+	status.NamespaceCount, _ = info["namespaceCount"].(int)
+	status.Endpoint, _ = info["endpoint"].(string)
+	if count, ok := info["podCount"].(int); ok {
+		status.PodCount = &count
+	}
+	if count, ok := info["crdCount"].(int); ok {
+		status.CRDCount = &count
+	}
+	if available, ok := info["metricsAPIAvailable"].(bool); ok {
+		status.MetricsAPIAvailable = &available
+	}
+	if available, ok := info["apiextensionsAPIAvailable"].(bool); ok {
+		status.APIExtensionsAPIAvailable = &available
+	}
+	status.VersionSkew, _ = info["versionSkew"].(*types.VersionSkew)
+	status.Unavailable, _ = info["unavailable"].(map[string]string)
+	return status, nil
+}
+
+// clusterStatusText gets cluster info and formats it as status text
+// clusterStatusText 获取集群信息并格式化为状态文本
+func (s *Server) clusterStatusText(ctx context.Context, clusterName string) (string, error) {
+	info, err := s.clusterStatus(ctx, clusterName)
+	if err != nil {
+		return "", err
+	}
+	return formatClusterStatus(info), nil
+}
+
+// formatClusterStatus formats cluster info as status text
+// formatClusterStatus 将集群信息格式化为状态文本
+func formatClusterStatus(info *ClusterStatusInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cluster Status:\n  Version: %s\n  Platform: %s\n  Node Count: %d\n  Namespace Count: %d",
+		info.Version, info.Platform, info.NodeCount, info.NamespaceCount)
+	if info.Endpoint != "" {
+		fmt.Fprintf(&b, "\n  Endpoint: %s", info.Endpoint)
+	}
+	if info.PodCount != nil {
+		fmt.Fprintf(&b, "\n  Pod Count: %d", *info.PodCount)
+	}
+	if info.CRDCount != nil {
+		fmt.Fprintf(&b, "\n  CRD Count: %d", *info.CRDCount)
+	}
+	if info.MetricsAPIAvailable != nil {
+		fmt.Fprintf(&b, "\n  Metrics API: %s", availability(*info.MetricsAPIAvailable))
+	}
+	if info.APIExtensionsAPIAvailable != nil {
+		fmt.Fprintf(&b, "\n  API Extensions API: %s", availability(*info.APIExtensionsAPIAvailable))
+	}
+	if skew := info.VersionSkew; skew != nil {
+		support := "supported"
+		if !skew.Supported {
+			support = "unsupported"
+		}
+		fmt.Fprintf(&b, "\n  Kubelet Version Skew: %d minor (%s)", skew.MaxMinorSkew, support)
+	}
+	keys := make([]string, 0, len(info.Unavailable))
+	for key := range info.Unavailable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n  %s: %s", key, info.Unavailable[key])
+	}
+	return b.String()
+}
+
+// availability describes whether an API is served
+// availability 描述 API 是否可用
+func availability(available bool) string {
+	if available {
+		return "available"
+	}
+	return "not available"
+}
+
+// handleListResources handles list_resources tool
+// handleListResources 处理 list_resources 工具
+func (s *Server) handleListResources(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType  string `json:"resource_type"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	CronJob       string `json:"cronjob,omitempty"`
+	Format        string `json:"format,omitempty"`
+	Columns       string `json:"columns,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+	AllClusters   bool   `json:"all_clusters,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	ResourcesResult,
+	error,
+) {
+	if result := validateTableFormat(input.Format, input.Columns); result != nil {
+		return result, ResourcesResult{}, nil
+	}
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	resourceType := k8s.ResourceType(input.ResourceType)
+	if input.CronJob != "" && resourceType != k8s.ResourceTypeJobs && resourceType != k8s.ResourceTypeJob {
+		return nil, ResourcesResult{}, fmt.Errorf("cronjob can only be used with resource_type jobs")
+	}
+
+	// scopeFor resolves the namespace per cluster, since each context may have its own default
+	// scopeFor 按集群解析命名空间，因为每个上下文可能有自己的默认命名空间
+	scopeFor := func(clusterName string) (string, string) {
+		if k8s.IsClusterScoped(resourceType) {
+			return "", "cluster-scoped"
+		}
+		return s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	}
+
+	list := func(ctx context.Context, clusterName, namespace string) (interface{}, error) {
+		var resources interface{}
+		var err error
+		if input.CronJob != "" {
+			resources, err = s.resourceOps.ListJobs(ctx, namespace, clusterName, input.CronJob)
+		} else {
+			resources, err = s.resourceOps.ListResourcesByType(ctx, resourceType, namespace, clusterName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", input.ResourceType, err)
+		}
+		return resources, nil
+	}
+
+	if isAllClusters(input.AllClusters, clusterName) {
+		results := s.fanOutClusters(ctx, func(ctx context.Context, clusterName string) (string, error) {
+			namespace, scope := scopeFor(clusterName)
+			resources, err := list(ctx, clusterName, namespace)
+			if err != nil {
+				return "", err
+			}
+			out, err := formatResourceList(resources, input.Format, input.Columns)
+			if err != nil {
+				return "", err
+			}
+			return "Scope: " + scope + "\n" + out, nil
+		})
+		return nil, ResourcesResult{
+			Resources: formatClusterResults(s.sessionLanguage(req.Session), results),
+		}, nil
+	}
+
+	namespace, scope := scopeFor(clusterName)
+	resources, err := list(ctx, clusterName, namespace)
+	if err != nil {
+		return nil, ResourcesResult{}, err
+	}
+
+	// Serialize to JSON, or render a table
+	// 序列化为 JSON 或渲染为表格
+	output, err := formatResourceList(resources, input.Format, input.Columns)
+	if errors.Is(err, errUnknownTableColumn) {
+		return toolError(err.Error()), ResourcesResult{}, nil
+	}
+	if err != nil {
+		return nil, ResourcesResult{}, err
+	}
+
+	// Types without a ResourceInfo form (events) only have the text output
+	// 没有 ResourceInfo 形式的类型（events）只返回文本输出
+	items, _ := k8s.ToResourceInfos(resources)
+
+	return nil, ResourcesResult{
+		Resources: output,
+		Items:     items,
+		Scope:     scope,
 	}, nil
 }
 
+// handleSearchResources handles search_resources tool
+// handleSearchResources 处理 search_resources 工具
+func (s *Server) handleSearchResources(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Query         string   `json:"query,omitempty"`
+	ResourceTypes []string `json:"resource_types,omitempty"`
+	LabelSelector string   `json:"label_selector,omitempty"`
+	Namespace     string   `json:"namespace,omitempty"`
+	AllNamespaces bool     `json:"all_namespaces,omitempty"`
+	MaxResults    int      `json:"max_results,omitempty"`
+	ClusterName   string   `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	SearchResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	if input.Query == "" && input.LabelSelector == "" {
+		return nil, SearchResult{}, fmt.Errorf("at least one of query or label_selector is required")
+	}
+
+	// An empty namespace lists across all namespaces, so only use it when asked to
+	// 空命名空间表示所有命名空间，因此仅在明确要求时使用
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	opts := k8s.SearchOptions{
+		Query:         input.Query,
+		LabelSelector: input.LabelSelector,
+		Namespace:     namespace,
+		MaxResults:    input.MaxResults,
+	}
+	for _, resourceType := range input.ResourceTypes {
+		opts.ResourceTypes = append(opts.ResourceTypes, k8s.ResourceType(resourceType))
+	}
+
+	result, err := s.resourceOps.SearchResources(ctx, opts, clusterName)
+	if err != nil {
+		return nil, SearchResult{}, fmt.Errorf("failed to search resources: %w", err)
+	}
+
+	// Serialize to JSON
+	// 序列化为 JSON
+	jsonStr, err := serializeResourceList(result.Matches)
+	if err != nil {
+		return nil, SearchResult{}, fmt.Errorf("failed to serialize search results: %w", err)
+	}
+
+	searchResult := SearchResult{Resources: jsonStr, Scope: scope}
+	if result.Truncated {
+		searchResult.Note = fmt.Sprintf("truncated: showing %d of %d matches, refine the query or raise max_results", len(result.Matches), result.Total)
+	}
+	return nil, searchResult, nil
+}
+
 // handleListPods handles list_pods tool
 // handleListPods 处理 list_pods 工具
 func (s *Server) handleListPods(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	PodsResult,
 	error,
 ) {
-	pods, err := s.resourceOps.ListPods(ctx, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	pods, err := s.resourceOps.ListPods(ctx, namespace, clusterName)
 	if err != nil {
 		return nil, PodsResult{}, fmt.Errorf("failed to list pods: %w", err)
 	}
@@ -342,20 +1500,25 @@ func (s *Server) handleListPods(ctx context.Context, req *mcp.CallToolRequest, i
 	}
 
 	return nil, PodsResult{
-		Pods: jsonStr,
+		Pods:  jsonStr,
+		Scope: scope,
 	}, nil
 }
 
 // handleListServices handles list_services tool
 // handleListServices 处理 list_services 工具
 func (s *Server) handleListServices(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	ServicesResult,
 	error,
 ) {
-	services, err := s.resourceOps.ListServices(ctx, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	services, err := s.resourceOps.ListServices(ctx, namespace, clusterName)
 	if err != nil {
 		return nil, ServicesResult{}, fmt.Errorf("failed to list services: %w", err)
 	}
@@ -369,19 +1532,24 @@ func (s *Server) handleListServices(ctx context.Context, req *mcp.CallToolReques
 
 	return nil, ServicesResult{
 		Services: jsonStr,
+		Scope:    scope,
 	}, nil
 }
 
 // handleListDeployments handles list_deployments tool
 // handleListDeployments 处理 list_deployments 工具
 func (s *Server) handleListDeployments(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	DeploymentsResult,
 	error,
 ) {
-	deployments, err := s.resourceOps.ListDeployments(ctx, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	deployments, err := s.resourceOps.ListDeployments(ctx, namespace, clusterName)
 	if err != nil {
 		return nil, DeploymentsResult{}, fmt.Errorf("failed to list deployments: %w", err)
 	}
@@ -395,69 +1563,270 @@ func (s *Server) handleListDeployments(ctx context.Context, req *mcp.CallToolReq
 
 	return nil, DeploymentsResult{
 		Deployments: jsonStr,
+		Scope:       scope,
 	}, nil
 }
 
 // handleListNodes handles list_nodes tool
 // handleListNodes 处理 list_nodes 工具
-func (s *Server) handleListNodes(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+func (s *Server) handleListNodes(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Format  string `json:"format,omitempty"`
+	Columns string `json:"columns,omitempty"`
+}) (
 	*mcp.CallToolResult,
 	NodesResult,
 	error,
 ) {
-	nodes, err := s.resourceOps.ListResourcesByType(ctx, k8s.ResourceTypeNodes, "", "")
+	if result := validateTableFormat(input.Format, input.Columns); result != nil {
+		return result, NodesResult{}, nil
+	}
+	clusterName := s.currentCluster(ctx)
+
+	nodes, err := s.resourceOps.ListResourcesByType(ctx, k8s.ResourceTypeNodes, "", clusterName)
 	if err != nil {
 		return nil, NodesResult{}, fmt.Errorf("failed to list nodes: %w", err)
 	}
 
-	// Serialize to JSON
-	// 序列化为 JSON
-	jsonStr, err := serializeResourceList(nodes)
+	// Serialize to JSON, or render a table
+	// 序列化为 JSON 或渲染为表格
+	output, err := formatResourceList(nodes, input.Format, input.Columns)
+	if errors.Is(err, errUnknownTableColumn) {
+		return toolError(err.Error()), NodesResult{}, nil
+	}
 	if err != nil {
 		return nil, NodesResult{}, fmt.Errorf("failed to serialize nodes: %w", err)
 	}
 
 	return nil, NodesResult{
-		Nodes: jsonStr,
+		Nodes: output,
 	}, nil
 }
 
+// handleDescribeNode handles describe_node tool
+// handleDescribeNode 处理 describe_node 工具
+func (s *Server) handleDescribeNode(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	NodeName    string `json:"node_name"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.NodeDetails,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	details, err := s.resourceOps.DescribeNode(ctx, input.NodeName, clusterName)
+	if err != nil {
+		return nil, types.NodeDetails{}, fmt.Errorf("failed to describe node: %w", err)
+	}
+	return nil, *details, nil
+}
+
 // handleListNamespaces handles list_namespaces tool
 // handleListNamespaces 处理 list_namespaces 工具
-func (s *Server) handleListNamespaces(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+func (s *Server) handleListNamespaces(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ClusterName   string `json:"cluster_name,omitempty"`
+	AllClusters   bool   `json:"all_clusters,omitempty"`
+	IncludeQuotas bool   `json:"include_quotas,omitempty"`
+	Format        string `json:"format,omitempty"`
+	Columns       string `json:"columns,omitempty"`
+}) (
 	*mcp.CallToolResult,
 	NamespacesResult,
 	error,
 ) {
-	namespaces, err := s.resourceOps.ListNamespaces(ctx, "")
-	if err != nil {
-		return nil, NamespacesResult{}, fmt.Errorf("failed to list namespaces: %w", err)
+	if result := validateTableFormat(input.Format, input.Columns); result != nil {
+		return result, NamespacesResult{}, nil
 	}
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
 
-	// Serialize to JSON
-	// 序列化为 JSON
-	jsonStr, err := serializeResourceList(namespaces)
+	fetch := func(ctx context.Context, clusterName string) ([]types.Namespace, error) {
+		namespaces, err := s.resourceOps.ListNamespaces(ctx, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+
+		// Attach quotas and limit ranges if requested
+		// 如果需要，附加 ResourceQuota 和 LimitRange 信息
+		if input.IncludeQuotas {
+			if err := s.attachNamespaceQuotas(ctx, namespaces, clusterName); err != nil {
+				return nil, err
+			}
+		}
+		return namespaces, nil
+	}
+
+	format := func(clusterName string, namespaces []types.Namespace) (string, error) {
+		if input.Format == "text" {
+			return formatNamespacesText(clusterName, namespaces, input.Columns)
+		}
+
+		// Serialize to JSON
+		// 序列化为 JSON
+		jsonStr, err := serializeResourceList(namespaces)
+		if err != nil {
+			return "", fmt.Errorf("failed to serialize namespaces: %w", err)
+		}
+		return jsonStr, nil
+	}
+
+	if isAllClusters(input.AllClusters, clusterName) {
+		results := s.fanOutClusters(ctx, func(ctx context.Context, clusterName string) (string, error) {
+			namespaces, err := fetch(ctx, clusterName)
+			if err != nil {
+				return "", err
+			}
+			return format(clusterName, namespaces)
+		})
+		return nil, NamespacesResult{
+			Namespaces: formatClusterResults(s.sessionLanguage(req.Session), results),
+		}, nil
+	}
+
+	namespaces, err := fetch(ctx, clusterName)
+	if err != nil {
+		return nil, NamespacesResult{}, err
+	}
+	output, err := format(clusterName, namespaces)
+	if errors.Is(err, errUnknownTableColumn) {
+		return toolError(err.Error()), NamespacesResult{}, nil
+	}
 	if err != nil {
-		return nil, NamespacesResult{}, fmt.Errorf("failed to serialize namespaces: %w", err)
+		return nil, NamespacesResult{}, err
 	}
 
 	return nil, NamespacesResult{
-		Namespaces: jsonStr,
+		Namespaces: output,
+		Items:      namespaces,
 	}, nil
 }
 
+// attachNamespaceQuotas fetches resource quotas and limit ranges and attaches them to namespaces
+// attachNamespaceQuotas 获取 ResourceQuota 和 LimitRange 并附加到对应的命名空间
+func (s *Server) attachNamespaceQuotas(ctx context.Context, namespaces []types.Namespace, clusterName string) error {
+	// List across all namespaces once instead of once per namespace
+	// 一次性列出所有命名空间的数据，避免逐个命名空间请求
+	quotas, err := s.resourceOps.ListResourceQuotas(ctx, "", clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	limitRanges, err := s.resourceOps.ListLimitRanges(ctx, "", clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+
+	for i := range namespaces {
+		for _, quota := range quotas {
+			if quota.Namespace == namespaces[i].Name {
+				namespaces[i].Quotas = append(namespaces[i].Quotas, quota)
+			}
+		}
+		for _, lr := range limitRanges {
+			if lr.Namespace == namespaces[i].Name {
+				namespaces[i].LimitRanges = append(namespaces[i].LimitRanges, lr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveClusterName returns the given cluster name, or the calling session's current cluster if empty
+// resolveClusterName 返回指定的集群名称，为空时返回调用方会话的当前集群
+func (s *Server) resolveClusterName(ctx context.Context, clusterName string) string {
+	if clusterName != "" {
+		return clusterName
+	}
+	return s.currentCluster(ctx)
+}
+
+// formatNamespacesText renders namespaces as a human-readable table with the given
+// columns (see selectTableColumns), followed by their quotas and limit ranges
+// formatNamespacesText 使用指定的列 (见 selectTableColumns) 将命名空间渲染为可读的文本表格，之后是其配额和 LimitRange
+func formatNamespacesText(clusterName string, namespaces []types.Namespace, columns string) (string, error) {
+	if clusterName == "" {
+		clusterName = "<none>"
+	}
+	table, err := renderResourceTable(namespaces, columns)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Namespaces in cluster %s (%d):\n", clusterName, len(namespaces))
+	sb.WriteString(table + "\n")
+
+	for _, ns := range namespaces {
+		if len(ns.Quotas) == 0 && len(ns.LimitRanges) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", ns.Name)
+		for _, quota := range ns.Quotas {
+			fmt.Fprintf(&sb, "  ResourceQuota %s:\n", quota.Name)
+			for _, name := range sortedKeys(quota.Hard) {
+				used := quota.Used[name]
+				if used == "" {
+					used = "0"
+				}
+				fmt.Fprintf(&sb, "    %s: %s / %s\n", name, used, quota.Hard[name])
+			}
+		}
+		for _, lr := range ns.LimitRanges {
+			fmt.Fprintf(&sb, "  LimitRange %s:\n", lr.Name)
+			for _, item := range lr.Limits {
+				fmt.Fprintf(&sb, "    %s: max=%s min=%s default=%s defaultRequest=%s\n",
+					item.Type, formatQuantityMap(item.Max), formatQuantityMap(item.Min),
+					formatQuantityMap(item.Default), formatQuantityMap(item.DefaultRequest))
+			}
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// formatQuantityMap formats a resource map as "cpu=1,memory=1Gi"
+// formatQuantityMap 将资源映射格式化为 "cpu=1,memory=1Gi"
+func formatQuantityMap(m map[string]string) string {
+	if len(m) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, m[k]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortedKeys returns the keys of a map in sorted order
+// sortedKeys 返回排序后的 map 键列表
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // handleGetResource handles get_resource tool
 // handleGetResource 处理 get_resource 工具
 func (s *Server) handleGetResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	ResourceType string `json:"resource_type"`
-	Name         string `json:"name"`
-	Namespace    string `json:"namespace"`
+	ResourceType         string `json:"resource_type"`
+	Name                 string `json:"name"`
+	Namespace            string `json:"namespace,omitempty"`
+	Format               string `json:"format,omitempty"`
+	IncludeManagedFields bool   `json:"include_managed_fields,omitempty"`
+	IncludeRaw           bool   `json:"include_raw,omitempty"`
+	JSONPath             string `json:"jsonpath,omitempty"`
+	ClusterName          string `json:"cluster_name,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	ResourceResult,
 	error,
 ) {
-	resource, err := s.resourceOps.GetResourceDetails(ctx, k8s.ResourceType(input.ResourceType), input.Namespace, input.Name, "")
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	resource, err := s.resourceOps.GetResourceDetails(ctx, k8s.ResourceType(input.ResourceType), namespace, input.Name, clusterName)
 	if err != nil {
 		return nil, ResourceResult{}, fmt.Errorf("failed to get resource: %w", err)
 	}
@@ -468,30 +1837,52 @@ func (s *Server) handleGetResource(ctx context.Context, req *mcp.CallToolRequest
 		resource = s.redactSecretData(resource)
 	}
 
-	// Serialize to JSON
-	// 序列化为 JSON
-	jsonStr, err := s.resourceOps.SerializeResource(resource)
+	// Return only the extracted value(s) when a jsonpath is given
+	// 指定 jsonpath 时只返回提取的值
+	if input.JSONPath != "" {
+		value, err := k8s.ExtractJSONPath(resource, input.JSONPath)
+		if err != nil {
+			return toolError(err.Error()), ResourceResult{}, nil
+		}
+		return nil, ResourceResult{Resource: value}, nil
+	}
+
+	// Serialize to JSON (default) or YAML
+	// 序列化为 JSON（默认）或 YAML
+	jsonStr, err := s.resourceOps.SerializeResource(resource, &k8s.SerializeOptions{
+		Format:               input.Format,
+		IncludeManagedFields: input.IncludeManagedFields,
+		IncludeRaw:           input.IncludeRaw,
+	})
 	if err != nil {
 		return nil, ResourceResult{}, fmt.Errorf("failed to serialize resource: %w", err)
 	}
 
 	return nil, ResourceResult{
 		Resource: jsonStr,
+		Routes:   k8s.DescribeIngressRoutes(resource),
 	}, nil
 }
 
 // handleGetResourceYAML handles get_resource_yaml tool
 // handleGetResourceYAML 处理 get_resource_yaml 工具
 func (s *Server) handleGetResourceYAML(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	ResourceType string `json:"resource_type"`
-	Name         string `json:"name"`
-	Namespace    string `json:"namespace"`
+	ResourceType         string `json:"resource_type"`
+	Name                 string `json:"name"`
+	Namespace            string `json:"namespace,omitempty"`
+	Format               string `json:"format,omitempty"`
+	IncludeManagedFields bool   `json:"include_managed_fields,omitempty"`
+	IncludeRaw           bool   `json:"include_raw,omitempty"`
+	ClusterName          string `json:"cluster_name,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	YAMLResult,
 	error,
 ) {
-	resource, err := s.resourceOps.GetResourceDetails(ctx, k8s.ResourceType(input.ResourceType), input.Namespace, input.Name, "")
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	resource, err := s.resourceOps.GetResourceDetails(ctx, k8s.ResourceType(input.ResourceType), namespace, input.Name, clusterName)
 	if err != nil {
 		return nil, YAMLResult{}, fmt.Errorf("failed to get resource: %w", err)
 	}
@@ -502,9 +1893,17 @@ func (s *Server) handleGetResourceYAML(ctx context.Context, req *mcp.CallToolReq
 		resource = s.redactSecretData(resource)
 	}
 
-	// Serialize to JSON (we'll convert to YAML in the future if needed, for now JSON is fine)
-	// 序列化为 JSON（如果需要，我们将来可以转换为 YAML，目前 JSON 即可）
-	jsonStr, err := s.resourceOps.SerializeResource(resource)
+	// Serialize to YAML unless another format is requested
+	// 除非指定了其他格式，否则序列化为 YAML
+	format := input.Format
+	if format == "" {
+		format = k8s.OutputFormatYAML
+	}
+	jsonStr, err := s.resourceOps.SerializeResource(resource, &k8s.SerializeOptions{
+		Format:               format,
+		IncludeManagedFields: input.IncludeManagedFields,
+		IncludeRaw:           input.IncludeRaw,
+	})
 	if err != nil {
 		return nil, YAMLResult{}, fmt.Errorf("failed to serialize resource: %w", err)
 	}
@@ -514,16 +1913,49 @@ func (s *Server) handleGetResourceYAML(ctx context.Context, req *mcp.CallToolReq
 	}, nil
 }
 
+// handleDiffResource handles diff_resource tool
+// handleDiffResource 处理 diff_resource 工具
+func (s *Server) handleDiffResource(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Manifest    string `json:"manifest"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	DiffResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	diff, err := s.resourceOps.DiffResource(ctx, input.Manifest, clusterName)
+	if err != nil {
+		return nil, DiffResult{}, fmt.Errorf("failed to diff resource: %w", err)
+	}
+
+	return nil, DiffResult{
+		Diff: diff,
+	}, nil
+}
+
 // handleGetEvents handles get_events tool
 // handleGetEvents 处理 get_events 工具
 func (s *Server) handleGetEvents(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	Since         string `json:"since,omitempty"`
+	Until         string `json:"until,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	EventsResult,
 	error,
 ) {
-	events, err := s.resourceOps.ListResourcesByType(ctx, k8s.ResourceTypeEvent, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	window, err := k8s.ParseTimeWindow(input.Since, input.Until, time.Now())
+	if err != nil {
+		return toolError(err.Error()), EventsResult{}, nil
+	}
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	events, err := s.resourceOps.ListEvents(ctx, namespace, window, clusterName)
 	if err != nil {
 		return nil, EventsResult{}, fmt.Errorf("failed to list events: %w", err)
 	}
@@ -535,42 +1967,74 @@ func (s *Server) handleGetEvents(ctx context.Context, req *mcp.CallToolRequest,
 		return nil, EventsResult{}, fmt.Errorf("failed to serialize events: %w", err)
 	}
 
-	return nil, EventsResult{
+	result := EventsResult{
 		Events: jsonStr,
-	}, nil
+		Scope:  scope,
+	}
+	if !window.IsZero() {
+		result.Window = window.String()
+	}
+	return nil, result, nil
 }
 
 // handleGetPodLogs handles get_pod_logs tool
 // handleGetPodLogs 处理 get_pod_logs 工具
 func (s *Server) handleGetPodLogs(ctx context.Context, req *mcp.CallToolRequest, input struct {
 	PodName       string `json:"pod_name"`
-	Namespace     string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
 	ContainerName string `json:"container_name,omitempty"`
 	TailLines     *int64 `json:"tail_lines,omitempty"`
 	Previous      bool   `json:"previous,omitempty"`
+	Since         string `json:"since,omitempty"`
+	Until         string `json:"until,omitempty"`
+	SinceTime     string `json:"since_time,omitempty"`
+	Timestamps    bool   `json:"timestamps,omitempty"`
 	ClusterName   string `json:"cluster_name,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	LogsResult,
 	error,
 ) {
-	// Set default tail_lines to 100 if not specified
-	// 如果未指定，默认 tail_lines 为 100
-	tailLines := int64(100)
-	if input.TailLines != nil {
-		tailLines = *input.TailLines
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	opts := k8s.PodLogOptions{
+		Container:  input.ContainerName,
+		TailLines:  input.TailLines,
+		Previous:   input.Previous,
+		Timestamps: input.Timestamps,
+	}
+	// since_time is the older name of since
+	// since_time 是 since 的旧名称
+	since := input.Since
+	if input.SinceTime != "" {
+		if since != "" {
+			return toolError("since and since_time are the same option, pass only one of them"), LogsResult{}, nil
+		}
+		since = input.SinceTime
 	}
+	// Without since, tail_lines defaults to 100; with it, every line since that time is returned
+	// 未指定 since 时 tail_lines 默认为 100；指定后返回该时间之后的所有日志行
+	window, err := k8s.ParseTimeWindow(since, input.Until, time.Now())
+	if err != nil {
+		return toolError(err.Error()), LogsResult{}, nil
+	}
+	opts.ApplyWindow(window)
 
 	// Get logs
 	// 获取日志
-	logs, err := s.resourceOps.GetPodLogs(ctx, input.Namespace, input.PodName, input.ContainerName, &tailLines, input.Previous, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	logs, err := s.resourceOps.GetPodLogs(ctx, namespace, input.PodName, opts, clusterName)
 	if err != nil {
 		return nil, LogsResult{}, fmt.Errorf("failed to get pod logs: %w", err)
 	}
 
-	return nil, LogsResult{
+	result := LogsResult{
 		Logs: logs,
-	}, nil
+	}
+	if !window.IsZero() {
+		result.Window = window.String()
+	}
+	return nil, result, nil
 }
 
 // handleCheckRBACPermission handles check_rbac_permission tool
@@ -584,7 +2048,7 @@ func (s *Server) handleCheckRBACPermission(ctx context.Context, req *mcp.CallToo
 	RBACPermissionResult,
 	error,
 ) {
-	allowed, err := s.resourceOps.CheckRBACPermission(ctx, input.Verb, input.Resource, input.Namespace)
+	allowed, err := s.resourceOps.CheckRBACPermission(ctx, input.Verb, input.Resource, input.Namespace, s.currentCluster(ctx))
 	if err != nil {
 		return nil, RBACPermissionResult{}, fmt.Errorf("failed to check RBAC permission: %w", err)
 	}
@@ -602,16 +2066,248 @@ func (s *Server) handleCheckRBACPermission(ctx context.Context, req *mcp.CallToo
 	return nil, result, nil
 }
 
+// handleCheckPermissions handles check_permissions tool
+// handleCheckPermissions 处理 check_permissions 工具
+func (s *Server) handleCheckPermissions(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Verb        string `json:"verb"`
+	Resource    string `json:"resource"`
+	Group       string `json:"group,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	PermissionsResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	permission, err := s.resourceOps.CheckPermission(ctx, k8s.PermissionCheck{
+		Verb:        input.Verb,
+		Group:       input.Group,
+		Resource:    input.Resource,
+		Subresource: input.Subresource,
+		Name:        input.Name,
+		Namespace:   input.Namespace,
+	}, clusterName)
+	if err != nil {
+		return nil, PermissionsResult{}, err
+	}
+
+	identity := s.clusterManager.Impersonation(ctx)
+	return nil, PermissionsResult{
+		Allowed: permission.Allowed,
+		Reason:  permission.Reason,
+		User:    identity.UserName,
+		Groups:  identity.Groups,
+	}, nil
+}
+
+// handleCanI handles can_i tool
+// handleCanI 处理 can_i 工具
+func (s *Server) handleCanI(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Verb          string `json:"verb"`
+	Resource      string `json:"resource"`
+	Subresource   string `json:"subresource,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.RBACPermission,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	resource, group, subresource := k8s.ParsePermissionResource(input.Resource)
+	if input.Subresource != "" {
+		subresource = input.Subresource
+	}
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	permission, err := s.resourceOps.CheckPermission(ctx, k8s.PermissionCheck{
+		Verb:        input.Verb,
+		Group:       group,
+		Resource:    resource,
+		Subresource: subresource,
+		Name:        input.Name,
+		Namespace:   namespace,
+	}, clusterName)
+	if err != nil {
+		return nil, types.RBACPermission{}, err
+	}
+	return nil, *permission, nil
+}
+
+// handleListPermissions handles list_permissions tool
+// handleListPermissions 处理 list_permissions 工具
+func (s *Server) handleListPermissions(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.PermissionList,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	permissions, err := s.resourceOps.ListPermissions(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, types.PermissionList{}, err
+	}
+	return nil, *permissions, nil
+}
+
+// handleWaitFor handles wait_for tool
+// handleWaitFor 处理 wait_for 工具
+func (s *Server) handleWaitFor(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ResourceType   string `json:"resource_type"`
+	Name           string `json:"name"`
+	Condition      string `json:"condition"`
+	Namespace      string `json:"namespace,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	ClusterName    string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.WaitResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	timeout := waitTimeout(input.TimeoutSeconds)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	result, err := s.resourceOps.WaitForCondition(ctx, k8s.ResourceType(input.ResourceType), namespace, input.Name, input.Condition, timeout, clusterName)
+	if errors.Is(err, k8s.ErrWaitTimeout) {
+		return nil, types.WaitResult{}, fmt.Errorf("%w: %s %s was not %s after %s; last observed status: %s",
+			err, input.ResourceType, input.Name, result.Condition, timeout, result.Status)
+	}
+	if err != nil {
+		return nil, types.WaitResult{}, fmt.Errorf("failed to wait for %s: %w", input.Condition, err)
+	}
+	return nil, *result, nil
+}
+
+// handleDebugPod handles debug_pod tool
+// handleDebugPod 处理 debug_pod 工具
+func (s *Server) handleDebugPod(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	PodName       string   `json:"pod_name"`
+	Namespace     string   `json:"namespace,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	ContainerName string   `json:"container_name,omitempty"`
+	Command       []string `json:"command,omitempty"`
+	ClusterName   string   `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.DebugContainer,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	container, err := s.resourceOps.DebugPod(ctx, namespace, input.PodName, k8s.DebugOptions{
+		Image:         input.Image,
+		ContainerName: input.ContainerName,
+		Command:       input.Command,
+	}, clusterName)
+	if errors.Is(err, k8s.ErrEphemeralContainersUnsupported) {
+		return nil, types.DebugContainer{}, err
+	}
+	if err != nil {
+		return nil, types.DebugContainer{}, fmt.Errorf("failed to debug pod: %w", err)
+	}
+	return nil, *container, nil
+}
+
+// handleGetConfigMapData handles get_configmap_data tool
+// handleGetConfigMapData 处理 get_configmap_data 工具
+func (s *Server) handleGetConfigMapData(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Key         string `json:"key,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	ConfigMapDataResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+
+	// A single key is returned as plain text
+	// 单个键以纯文本返回
+	if input.Key != "" {
+		value, binary, err := s.resourceOps.GetConfigMapValue(ctx, namespace, input.Name, input.Key, clusterName)
+		if err != nil {
+			return nil, ConfigMapDataResult{}, fmt.Errorf("failed to get configmap data: %w", err)
+		}
+		result := ConfigMapDataResult{Data: value}
+		if binary {
+			result.Encoding = "base64"
+		}
+		return nil, result, nil
+	}
+
+	data, err := s.resourceOps.GetConfigMapData(ctx, namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, ConfigMapDataResult{}, fmt.Errorf("failed to get configmap data: %w", err)
+	}
+	jsonStr, err := serializeResourceList(data)
+	if err != nil {
+		return nil, ConfigMapDataResult{}, fmt.Errorf("failed to serialize configmap data: %w", err)
+	}
+
+	return nil, ConfigMapDataResult{
+		Data: jsonStr,
+	}, nil
+}
+
+// handleGetSecretKeys handles get_secret_keys tool
+// handleGetSecretKeys 处理 get_secret_keys 工具
+func (s *Server) handleGetSecretKeys(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	SecretKeysResult,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	keys, err := s.resourceOps.GetSecretKeys(ctx, namespace, input.Name, clusterName)
+	if err != nil {
+		return nil, SecretKeysResult{}, fmt.Errorf("failed to get secret keys: %w", err)
+	}
+
+	jsonStr, err := serializeResourceList(keys)
+	if err != nil {
+		return nil, SecretKeysResult{}, fmt.Errorf("failed to serialize secret keys: %w", err)
+	}
+
+	return nil, SecretKeysResult{
+		Keys: jsonStr,
+	}, nil
+}
+
 // handleListConfigMaps handles list_configmaps tool
 // handleListConfigMaps 处理 list_configmaps 工具
 func (s *Server) handleListConfigMaps(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	ConfigMapsResult,
 	error,
 ) {
-	configMaps, err := s.resourceOps.ListConfigMaps(ctx, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	configMaps, err := s.resourceOps.ListConfigMaps(ctx, namespace, clusterName)
 	if err != nil {
 		return nil, ConfigMapsResult{}, fmt.Errorf("failed to list configmaps: %w", err)
 	}
@@ -625,19 +2321,24 @@ func (s *Server) handleListConfigMaps(ctx context.Context, req *mcp.CallToolRequ
 
 	return nil, ConfigMapsResult{
 		ConfigMaps: jsonStr,
+		Scope:      scope,
 	}, nil
 }
 
 // handleListStatefulSets handles list_statefulsets tool
 // handleListStatefulSets 处理 list_statefulsets 工具
 func (s *Server) handleListStatefulSets(ctx context.Context, req *mcp.CallToolRequest, input struct {
-	Namespace string `json:"namespace"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }) (
 	*mcp.CallToolResult,
 	StatefulSetsResult,
 	error,
 ) {
-	statefulSets, err := s.resourceOps.ListStatefulSets(ctx, input.Namespace, "")
+	clusterName := s.currentCluster(ctx)
+
+	namespace, scope := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+	statefulSets, err := s.resourceOps.ListStatefulSets(ctx, namespace, clusterName)
 	if err != nil {
 		return nil, StatefulSetsResult{}, fmt.Errorf("failed to list statefulsets: %w", err)
 	}
@@ -651,12 +2352,25 @@ func (s *Server) handleListStatefulSets(ctx context.Context, req *mcp.CallToolRe
 
 	return nil, StatefulSetsResult{
 		StatefulSets: jsonStr,
+		Scope:        scope,
 	}, nil
 }
 
-// redactSecretData redacts sensitive data from secret resources
-// redactSecretData 脱敏 secret 资源中的敏感数据
+// redactSecretData redacts sensitive data from secret resources. Typed secrets from the
+// clientset are converted to a map first so the output has the same shape either way.
+// redactSecretData 脱敏 secret 资源中的敏感数据。clientset 返回的类型化 secret 会先转换为 map，使两种情况输出格式相同
 func (s *Server) redactSecretData(resource interface{}) interface{} {
+	if secret, ok := resource.(*corev1.Secret); ok {
+		secret = secret.DeepCopy()
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret)
+		if err != nil {
+			secret.Data, secret.StringData = nil, nil
+			return secret
+		}
+		resource = obj
+	}
+
 	// Type assertion to check if it's a secret
 	// 类型断言检查是否是 secret
 	if secretMap, ok := resource.(map[string]interface{}); ok {
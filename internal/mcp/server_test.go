@@ -0,0 +1,396 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+)
+
+// newTestServer 创建一个注册了离线集群的测试服务器
+func newTestServer(t *testing.T, clusters ...string) *Server {
+	t.Helper()
+	s := NewServer("test-token", nil)
+	for _, name := range clusters {
+		if err := s.clusterManager.AddCluster(name, &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+			t.Fatalf("AddCluster(%s) failed: %v", name, err)
+		}
+	}
+	return s
+}
+
+// connectTestClient 通过内存传输连接一个测试客户端，测试结束时关闭会话
+func connectTestClient(t *testing.T, s *Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, opts)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() {
+		clientSession.Close()
+		serverSession.Wait()
+		s.Close()
+	})
+	return clientSession
+}
+
+// TestFormatNamespacesTextResolvesClusterName 测试未指定 cluster_name 时标题显示当前集群
+func TestFormatNamespacesTextResolvesClusterName(t *testing.T) {
+	s := newTestServer(t, "prod")
+
+	namespaces := []types.Namespace{
+		{Name: "default", Status: "Active", Age: "10d"},
+		{Name: "kube-system", Status: "Active", Age: "10d"},
+	}
+
+	text, err := formatNamespacesText(s.resolveClusterName(context.Background(), ""), namespaces, "")
+	if err != nil {
+		t.Fatalf("formatNamespacesText failed: %v", err)
+	}
+	header := strings.SplitN(text, "\n", 2)[0]
+	if header != "Namespaces in cluster prod (2):" {
+		t.Errorf("unexpected header: %q", header)
+	}
+	if strings.Contains(text, "cluster  ") {
+		t.Errorf("header contains empty cluster name: %q", text)
+	}
+	if !strings.Contains(text, "NAME") || !strings.Contains(text, "STATUS") || !strings.Contains(text, "AGE") {
+		t.Errorf("missing column headers: %q", text)
+	}
+}
+
+// TestFormatNamespacesTextNoCluster 测试没有任何集群时的标题
+func TestFormatNamespacesTextNoCluster(t *testing.T) {
+	s := newTestServer(t)
+
+	text, err := formatNamespacesText(s.resolveClusterName(context.Background(), ""), nil, "")
+	if err != nil {
+		t.Fatalf("formatNamespacesText failed: %v", err)
+	}
+	if !strings.HasPrefix(text, "Namespaces in cluster <none> (0):") {
+		t.Errorf("unexpected header: %q", text)
+	}
+}
+
+// TestFormatNamespacesTextQuotas 测试配额摘要的渲染
+func TestFormatNamespacesTextQuotas(t *testing.T) {
+	namespaces := []types.Namespace{
+		{
+			Name:   "team-a",
+			Status: "Active",
+			Age:    "1d",
+			Quotas: []types.ResourceQuota{{
+				Name:      "compute",
+				Namespace: "team-a",
+				Hard:      map[string]string{"pods": "10", "requests.cpu": "4"},
+				Used:      map[string]string{"pods": "3"},
+			}},
+		},
+	}
+
+	text, err := formatNamespacesText("dev", namespaces, "")
+	if err != nil {
+		t.Fatalf("formatNamespacesText failed: %v", err)
+	}
+	for _, want := range []string{"ResourceQuota compute:", "pods: 3 / 10", "requests.cpu: 0 / 4"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
+
+// TestGetClusterStatusDetails 测试 get_cluster_status 返回控制平面地址、Pod 数量、API 可用性和版本偏差，不可用的部分列在 unavailable 中
+func TestGetClusterStatusDetails(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "get_cluster_status", nil)
+	var status ClusterStatusResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	info := status.Info
+	if info.Endpoint != "mock://mock" || info.PodCount == nil || *info.PodCount == 0 || info.MetricsAPIAvailable == nil || *info.MetricsAPIAvailable {
+		t.Fatalf("unexpected status %+v", info)
+	}
+	if info.VersionSkew == nil || !info.VersionSkew.Supported || info.VersionSkew.Kubelets["v1.28.4"] != 2 {
+		t.Errorf("unexpected version skew %+v", info.VersionSkew)
+	}
+	// 模拟集群不提供 apiextensions.k8s.io，CRD 数量不可用
+	if info.CRDCount != nil || !strings.HasPrefix(info.Unavailable["crdCount"], "unavailable: ") {
+		t.Errorf("expected the CRD count to be unavailable, got %+v", info)
+	}
+	for _, want := range []string{"  Endpoint: mock://mock", "  Metrics API: not available", "  Kubelet Version Skew: 0 minor (supported)", "  crdCount: unavailable: "} {
+		if !strings.Contains(status.Status, want) {
+			t.Errorf("expected %q in status:\n%s", want, status.Status)
+		}
+	}
+}
+
+// TestToolsListPagination 测试 tools/list 按页大小分页
+func TestToolsListPagination(t *testing.T) {
+	s := NewServer("test-token", &Options{ToolsPageSize: 5})
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	ctx := context.Background()
+	first, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(first.Tools) != 5 || first.NextCursor == "" {
+		t.Fatalf("expected a first page of 5 tools with a cursor, got %d (cursor %q)", len(first.Tools), first.NextCursor)
+	}
+
+	seen := map[string]bool{}
+	params := &mcp.ListToolsParams{}
+	for {
+		page, err := session.ListTools(ctx, params)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		for _, tool := range page.Tools {
+			if seen[tool.Name] {
+				t.Errorf("tool %s returned twice", tool.Name)
+			}
+			seen[tool.Name] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+	if !seen["get_cluster_status"] || !seen["list_statefulsets"] || len(seen) <= 5 {
+		t.Errorf("expected all tools across pages, got %d", len(seen))
+	}
+}
+
+// TestRemoveToolsNotifiesListChanged 测试运行时移除工具会发送 list_changed 通知
+func TestRemoveToolsNotifiesListChanged(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.RegisterTools()
+
+	changed := make(chan struct{}, 1)
+	session := connectTestClient(t, s, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+	if caps := session.InitializeResult().Capabilities; caps.Tools == nil || !caps.Tools.ListChanged {
+		t.Fatalf("expected tools.listChanged capability, got %+v", caps.Tools)
+	}
+
+	s.RemoveTools("list_statefulsets")
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected notifications/tools/list_changed")
+	}
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range result.Tools {
+		if tool.Name == "list_statefulsets" {
+			t.Errorf("expected list_statefulsets to be removed")
+		}
+	}
+}
+
+// TestResolveNamespacePrecedence 测试命名空间优先级：显式参数 > all_namespaces > 上下文默认值 > "default"
+func TestResolveNamespacePrecedence(t *testing.T) {
+	s := newTestServer(t, "prod", "dev")
+	s.clusterManager.SetDefaultNamespace("prod", "payments")
+
+	tests := []struct {
+		name          string
+		namespace     string
+		allNamespaces bool
+		cluster       string
+		want          string
+		wantScope     string
+	}{
+		{"explicit", "kube-system", true, "prod", "kube-system", "namespace kube-system"},
+		{"all namespaces", "", true, "prod", "", "all namespaces"},
+		{"context default", "", false, "prod", "payments", "namespace payments (default"},
+		{"fallback", "", false, "dev", "default", "namespace default (default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, scope := s.resolveNamespace(context.Background(), tt.namespace, tt.allNamespaces, tt.cluster)
+			if got != tt.want || !strings.HasPrefix(scope, tt.wantScope) {
+				t.Errorf("resolveNamespace() = %q, %q; want %q, %q", got, scope, tt.want, tt.wantScope)
+			}
+		})
+	}
+}
+
+// TestResolveNamespaceRestricted 测试命名空间受限模式下 all_namespaces 和不被允许的默认命名空间回退为允许的集合
+func TestResolveNamespaceRestricted(t *testing.T) {
+	policy, err := k8s.ParseNamespacePolicy("team-a-*", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+	s := NewServer("test-token", &Options{NamespacePolicy: policy})
+	for _, name := range []string{"prod", "dev"} {
+		if err := s.clusterManager.AddCluster(name, &rest.Config{Host: "https://127.0.0.1:1"}); err != nil {
+			t.Fatalf("AddCluster(%s) failed: %v", name, err)
+		}
+	}
+	s.clusterManager.SetDefaultNamespace("prod", "team-a-dev")
+
+	tests := []struct {
+		name          string
+		allNamespaces bool
+		cluster       string
+		want          string
+		wantScope     string
+	}{
+		{"all namespaces", true, "prod", "", "allowed namespaces (team-a-*)"},
+		{"allowed default", false, "prod", "team-a-dev", "namespace team-a-dev (default"},
+		{"disallowed default", false, "dev", "", "allowed namespaces (team-a-*)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, scope := s.resolveNamespace(context.Background(), "", tt.allNamespaces, tt.cluster)
+			if got != tt.want || !strings.HasPrefix(scope, tt.wantScope) {
+				t.Errorf("resolveNamespace() = %q, %q; want %q, %q", got, scope, tt.want, tt.wantScope)
+			}
+		})
+	}
+
+	// 显式指定不允许的命名空间时，工具调用返回 IsError 且不访问集群
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "list_resources",
+		Arguments: map[string]any{"resource_type": "pods", "namespace": "kube-system", "cluster_name": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected an IsError result")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, `namespace "kube-system" is not in the allowed namespaces`) {
+		t.Errorf("unexpected error text: %s", text)
+	}
+}
+
+// TestStructuredOutputSchemas 测试工具声明了包含结构化字段的 outputSchema
+func TestStructuredOutputSchemas(t *testing.T) {
+	s := newTestServer(t)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	schemas := make(map[string]map[string]interface{})
+	for _, tool := range result.Tools {
+		data, _ := json.Marshal(tool.OutputSchema)
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("%s: invalid output schema: %v", tool.Name, err)
+		}
+		schemas[tool.Name] = schema
+	}
+
+	tests := []struct {
+		tool, field, wantType string
+	}{
+		{"list_resources", "items", "array"},
+		{"list_namespaces", "items", "array"},
+		{"get_cluster_status", "info", "object"},
+	}
+	for _, tt := range tests {
+		properties, _ := schemas[tt.tool]["properties"].(map[string]interface{})
+		field, _ := properties[tt.field].(map[string]interface{})
+		if !strings.Contains(fmt.Sprint(field["type"]), tt.wantType) {
+			t.Errorf("%s: expected %s of type %s in output schema, got %v", tt.tool, tt.field, tt.wantType, field)
+		}
+	}
+}
+
+// TestDebugPodRequiresAllowExec 测试只有启用 AllowExec 时才注册 debug_pod
+func TestDebugPodRequiresAllowExec(t *testing.T) {
+	for _, allowExec := range []bool{false, true} {
+		s := NewServer("test-token", &Options{AllowExec: allowExec})
+		s.RegisterTools()
+		session := connectTestClient(t, s, nil)
+
+		result, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		registered := false
+		for _, tool := range result.Tools {
+			if tool.Name == "debug_pod" {
+				registered = true
+			}
+		}
+		if registered != allowExec {
+			t.Errorf("AllowExec=%v: debug_pod registered=%v", allowExec, registered)
+		}
+		if s.serverInfo().Features["exec"] != allowExec {
+			t.Errorf("AllowExec=%v: unexpected exec feature", allowExec)
+		}
+	}
+}
+
+// TestWriteToolsRequireAllowWrite 测试只有启用 AllowWrite 时才注册写工具且带有破坏性注解，rollout_history 始终注册
+func TestWriteToolsRequireAllowWrite(t *testing.T) {
+	destructive := map[string]bool{"rollback_deployment": true, "cordon_node": false, "uncordon_node": false, "drain_node": true, "label_resource": false, "annotate_resource": false}
+	for _, allowWrite := range []bool{false, true} {
+		s := NewServer("test-token", &Options{AllowWrite: allowWrite})
+		s.RegisterTools()
+		session := connectTestClient(t, s, nil)
+
+		result, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		registered := map[string]*mcp.Tool{}
+		for _, tool := range result.Tools {
+			registered[tool.Name] = tool
+		}
+		if registered["rollout_history"] == nil {
+			t.Errorf("AllowWrite=%v: rollout_history not registered", allowWrite)
+		}
+		for name, wantDestructive := range destructive {
+			tool := registered[name]
+			if (tool != nil) != allowWrite {
+				t.Errorf("AllowWrite=%v: %s registered=%v", allowWrite, name, tool != nil)
+				continue
+			}
+			if tool != nil && (tool.Annotations == nil || tool.Annotations.DestructiveHint == nil || *tool.Annotations.DestructiveHint != wantDestructive) {
+				t.Errorf("%s: unexpected annotations %+v", name, tool.Annotations)
+			}
+		}
+		if s.serverInfo().Features["write"] != allowWrite {
+			t.Errorf("AllowWrite=%v: unexpected write feature", allowWrite)
+		}
+	}
+}
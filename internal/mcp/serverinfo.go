@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerInfo describes the running server, as returned by get_server_info
+// ServerInfo 描述运行中的服务器，由 get_server_info 返回
+type ServerInfo struct {
+	Version   string          `json:"version"`
+	GitCommit string          `json:"git_commit"`
+	BuildDate string          `json:"build_date"`
+	StartedAt string          `json:"started_at"`
+	Uptime    string          `json:"uptime"`
+	Clusters  int             `json:"clusters"`
+	Current   string          `json:"current_cluster,omitempty"`
+	Features  map[string]bool `json:"features"`
+	PageSize  int             `json:"tools_page_size,omitempty"`
+	// MaxResultBytes is the size above which tool results are truncated
+	// MaxResultBytes 是工具结果被截断的大小上限
+	MaxResultBytes int `json:"max_result_bytes"`
+	// APIRetries counts the Kubernetes API requests retried after a transient failure
+	// APIRetries 统计暂时失败后重试的 Kubernetes API 请求次数
+	APIRetries int64 `json:"api_retries"`
+	// DiscoveryFetches counts the fetches of the clusters' API resources made to fill the discovery caches
+	// DiscoveryFetches 统计为填充发现缓存而获取集群 API 资源的次数
+	DiscoveryFetches int64 `json:"discovery_fetches"`
+}
+
+// ServerInfoResult represents the result of get_server_info tool
+// ServerInfoResult 表示 get_server_info 工具的结果
+type ServerInfoResult struct {
+	Info string `json:"info"`
+}
+
+// serverInfo collects the build and runtime information of the server
+// serverInfo 收集服务器的构建和运行时信息
+func (s *Server) serverInfo() ServerInfo {
+	return ServerInfo{
+		Version:          version.Version,
+		GitCommit:        version.GitCommit,
+		BuildDate:        version.BuildDate,
+		StartedAt:        s.startedAt.UTC().Format(time.RFC3339),
+		Uptime:           time.Since(s.startedAt).Round(time.Second).String(),
+		Clusters:         len(s.clusterManager.GetClusters()),
+		Current:          s.clusterManager.GetCurrentCluster(),
+		Features:         s.features(),
+		PageSize:         s.toolsPageSize,
+		MaxResultBytes:   s.maxResultBytes,
+		APIRetries:       s.clusterManager.APIRetries(),
+		DiscoveryFetches: s.clusterManager.DiscoveryFetches(),
+	}
+}
+
+// handleGetServerInfo handles get_server_info tool
+// handleGetServerInfo 处理 get_server_info 工具
+func (s *Server) handleGetServerInfo(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+	*mcp.CallToolResult,
+	ServerInfoResult,
+	error,
+) {
+	data, err := json.Marshal(s.serverInfo())
+	if err != nil {
+		return nil, ServerInfoResult{}, fmt.Errorf("failed to serialize server info: %w", err)
+	}
+
+	return nil, ServerInfoResult{
+		Info: string(data),
+	}, nil
+}
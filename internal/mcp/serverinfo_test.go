@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestGetServerInfo 测试 initialize 返回构建版本，get_server_info 返回集群数量和已启用的功能
+func TestGetServerInfo(t *testing.T) {
+	s := newTestServer(t, "dev", "prod")
+	s.subscriptions = newSubscriptionManager(s.watchResource, s.notifyResourceUpdated)
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	serverInfo := session.InitializeResult().ServerInfo
+	if serverInfo.Version != version.Version || serverInfo.Title == "" {
+		t.Errorf("unexpected server implementation: %+v", serverInfo)
+	}
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_server_info"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	var out ServerInfoResult
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid structured content: %v", err)
+	}
+	var info ServerInfo
+	if err := json.Unmarshal([]byte(out.Info), &info); err != nil {
+		t.Fatalf("invalid server info %q: %v", out.Info, err)
+	}
+
+	if info.Version != version.Version || info.Clusters != 2 || info.Uptime == "" {
+		t.Errorf("unexpected server info: %+v", info)
+	}
+	if !info.Features["subscriptions"] || info.Features["audit_log"] {
+		t.Errorf("unexpected features: %v", info.Features)
+	}
+}
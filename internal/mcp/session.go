@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionIdleTimeout is how long an idle MCP session, and the cluster and namespace
+// it selected, are kept
+// sessionIdleTimeout 空闲 MCP 会话及其选择的集群和命名空间的保留时间
+const sessionIdleTimeout = 5 * time.Minute
+
+// sessionState is the cluster and namespace selected by one MCP session; empty fields
+// fall back to the server defaults
+// sessionState 是单个 MCP 会话选择的集群和命名空间，字段为空时使用服务器默认值
+type sessionState struct {
+	cluster   string
+	namespace string
+	lastUsed  time.Time
+}
+
+// sessionStore tracks the state of each MCP session by session ID. Sessions idle for
+// longer than idleTimeout are dropped, so they return to the server defaults.
+// sessionStore 按会话 ID 跟踪每个 MCP 会话的状态，空闲超过 idleTimeout 的会话会被删除并恢复为服务器默认值
+type sessionStore struct {
+	mu          sync.Mutex
+	sessions    map[string]*sessionState
+	idleTimeout time.Duration
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+// newSessionStore creates an empty session store
+// newSessionStore 创建空的会话存储
+func newSessionStore(idleTimeout time.Duration) *sessionStore {
+	return &sessionStore{
+		sessions:    make(map[string]*sessionState),
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+	}
+}
+
+// touch records activity on a session, creating its state if needed, and reports
+// whether the state was created
+// touch 记录会话的活动，必要时创建其状态，并返回是否新建了状态
+func (st *sessionStore) touch(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.expireLocked()
+	_, ok := st.sessions[id]
+	st.lookupLocked(id)
+	return !ok
+}
+
+// get returns a copy of the session's state without creating it
+// get 返回会话状态的副本，不会创建新状态
+func (st *sessionStore) get(id string) (sessionState, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.expireLocked()
+	state, ok := st.sessions[id]
+	if !ok {
+		return sessionState{}, false
+	}
+	return *state, true
+}
+
+// update changes the session's state with fn
+// update 使用 fn 修改会话状态
+func (st *sessionStore) update(id string, fn func(*sessionState)) sessionState {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	state := st.lookupLocked(id)
+	fn(state)
+	return *state
+}
+
+// count returns the number of tracked sessions
+// count 返回跟踪的会话数量
+func (st *sessionStore) count() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.expireLocked()
+	return len(st.sessions)
+}
+
+// lookupLocked returns the session's state, creating it if needed, and marks it used
+// lookupLocked 返回会话状态（必要时创建）并标记为已使用
+func (st *sessionStore) lookupLocked(id string) *sessionState {
+	st.expireLocked()
+	state, ok := st.sessions[id]
+	if !ok {
+		state = &sessionState{}
+		st.sessions[id] = state
+	}
+	state.lastUsed = st.now()
+	return state
+}
+
+// expireLocked drops idle sessions. It walks the sessions at most once per idleTimeout
+// so it stays cheap on every request.
+// expireLocked 删除空闲的会话，每个 idleTimeout 最多遍历一次，避免每个请求都产生开销
+func (st *sessionStore) expireLocked() {
+	now := st.now()
+	if now.Sub(st.lastSweep) < st.idleTimeout {
+		return
+	}
+	st.lastSweep = now
+	for id, state := range st.sessions {
+		if now.Sub(state.lastUsed) >= st.idleTimeout {
+			delete(st.sessions, id)
+		}
+	}
+}
+
+// sessionIDKey is the context key of the calling MCP session's ID
+// sessionIDKey 调用方 MCP 会话 ID 的 context 键
+type sessionIDKey struct{}
+
+// sessionMiddleware tracks the session of every request and makes its ID available
+// to the handlers, which resolve the current cluster and namespace from it
+// sessionMiddleware 跟踪每个请求所属的会话，并将会话 ID 提供给处理器，用于解析当前集群和命名空间
+func (s *Server) sessionMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if session := req.GetSession(); session != nil {
+			// Transports without session IDs (stdio, in-memory) are told apart by their session object
+			// 没有会话 ID 的传输（stdio、内存传输）通过会话对象区分
+			id := session.ID()
+			if id == "" {
+				id = fmt.Sprintf("%p", session)
+			}
+			created := s.sessions.touch(id)
+			ctx = context.WithValue(ctx, sessionIDKey{}, id)
+			// A new session starts from its caller's remembered preferences
+			// 新会话从其调用者保存的偏好开始
+			if s.preferences != nil {
+				key := s.preferencesKey(req)
+				if created {
+					s.restorePreferences(id, key)
+				}
+				ctx = context.WithValue(ctx, preferencesKeyKey{}, key)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// sessionID returns the ID of the calling session, if the request came through sessionMiddleware
+// sessionID 返回调用方会话的 ID（请求经过 sessionMiddleware 时）
+func sessionID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(sessionIDKey{}).(string)
+	return id, ok
+}
+
+// sessionDefaults returns the cluster and namespace selected by the calling session
+// sessionDefaults 返回调用方会话选择的集群和命名空间
+func (s *Server) sessionDefaults(ctx context.Context) sessionState {
+	id, ok := sessionID(ctx)
+	if !ok {
+		return sessionState{}
+	}
+	state, _ := s.sessions.get(id)
+	return state
+}
+
+// currentCluster returns the cluster selected by the calling session, or the server's current cluster
+// currentCluster 返回调用方会话选择的集群，未选择时返回服务器的当前集群
+func (s *Server) currentCluster(ctx context.Context) string {
+	if cluster := s.sessionDefaults(ctx).cluster; cluster != "" {
+		return cluster
+	}
+	return s.clusterManager.GetCurrentCluster()
+}
+
+// SessionContextResult represents the result of switch_cluster, set_namespace and get_current_cluster
+// SessionContextResult 表示 switch_cluster、set_namespace 和 get_current_cluster 工具的结果
+type SessionContextResult struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	// Source tells whether the values were selected by this session or are the server defaults
+	// Source 表示这些值是本会话选择的还是服务器默认值
+	Source string `json:"source"`
+}
+
+// sessionContext describes the effective cluster and namespace of the calling session
+// sessionContext 描述调用方会话实际使用的集群和命名空间
+func (s *Server) sessionContext(ctx context.Context) SessionContextResult {
+	state := s.sessionDefaults(ctx)
+	cluster := s.currentCluster(ctx)
+	namespace := state.namespace
+	if namespace == "" {
+		namespace = s.clusterManager.GetDefaultNamespace(cluster)
+	}
+	source := "server"
+	if state.cluster != "" || state.namespace != "" {
+		source = "session"
+	}
+	return SessionContextResult{Cluster: cluster, Namespace: namespace, Source: source}
+}
+
+// handleSwitchCluster handles switch_cluster tool
+// handleSwitchCluster 处理 switch_cluster 工具
+func (s *Server) handleSwitchCluster(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	ClusterName string `json:"cluster_name"`
+}) (
+	*mcp.CallToolResult,
+	SessionContextResult,
+	error,
+) {
+	if input.ClusterName == "" {
+		return toolError("cluster_name is required"), SessionContextResult{}, nil
+	}
+	if _, err := s.clusterManager.GetClientForCluster(input.ClusterName); err != nil {
+		return nil, SessionContextResult{}, err
+	}
+	id, ok := sessionID(ctx)
+	if !ok {
+		return toolError("switch_cluster requires an MCP session"), SessionContextResult{}, nil
+	}
+
+	// Like switching kubeconfig contexts, the namespace returns to the new cluster's default
+	// 与切换 kubeconfig 上下文相同，命名空间恢复为新集群的默认值
+	state := s.sessions.update(id, func(state *sessionState) {
+		state.cluster = input.ClusterName
+		state.namespace = ""
+	})
+	s.rememberPreferences(ctx, state)
+	return nil, s.sessionContext(ctx), nil
+}
+
+// handleSetNamespace handles set_namespace tool
+// handleSetNamespace 处理 set_namespace 工具
+func (s *Server) handleSetNamespace(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace string `json:"namespace"`
+}) (
+	*mcp.CallToolResult,
+	SessionContextResult,
+	error,
+) {
+	if input.Namespace == "" {
+		return toolError("namespace is required"), SessionContextResult{}, nil
+	}
+	if policy := s.clusterManager.NamespacePolicy(); !policy.Allows(input.Namespace) {
+		return nil, SessionContextResult{}, fmt.Errorf("%w: namespace %q is not in the allowed namespaces (%s)", k8s.ErrNamespaceNotAllowed, input.Namespace, strings.Join(policy.Patterns(), ","))
+	}
+	id, ok := sessionID(ctx)
+	if !ok {
+		return toolError("set_namespace requires an MCP session"), SessionContextResult{}, nil
+	}
+
+	state := s.sessions.update(id, func(state *sessionState) {
+		state.namespace = input.Namespace
+	})
+	s.rememberPreferences(ctx, state)
+	return nil, s.sessionContext(ctx), nil
+}
+
+// handleGetCurrentCluster handles get_current_cluster tool
+// handleGetCurrentCluster 处理 get_current_cluster 工具
+func (s *Server) handleGetCurrentCluster(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (
+	*mcp.CallToolResult,
+	SessionContextResult,
+	error,
+) {
+	return nil, s.sessionContext(ctx), nil
+}
@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// callSessionTool 调用会话工具并解析结果
+func callSessionTool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (SessionContextResult, bool) {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	var decoded SessionContextResult
+	data, _ := json.Marshal(result.StructuredContent)
+	json.Unmarshal(data, &decoded)
+	return decoded, result.IsError
+}
+
+// TestSessionContextIsolation 测试 switch_cluster 和 set_namespace 只影响调用方会话
+func TestSessionContextIsolation(t *testing.T) {
+	s := newTestServer(t, "prod", "staging")
+	s.clusterManager.SwitchCluster("prod")
+	s.clusterManager.SetDefaultNamespace("staging", "web")
+	s.RegisterTools()
+	alice := connectTestClient(t, s, nil)
+	bob := connectTestClient(t, s, nil)
+
+	got, isError := callSessionTool(t, alice, "switch_cluster", map[string]any{"cluster_name": "staging"})
+	if isError || got != (SessionContextResult{Cluster: "staging", Namespace: "web", Source: "session"}) {
+		t.Errorf("unexpected switch_cluster result: %+v", got)
+	}
+	got, isError = callSessionTool(t, alice, "set_namespace", map[string]any{"namespace": "api"})
+	if isError || got != (SessionContextResult{Cluster: "staging", Namespace: "api", Source: "session"}) {
+		t.Errorf("unexpected set_namespace result: %+v", got)
+	}
+
+	if got, _ := callSessionTool(t, alice, "get_current_cluster", nil); got.Cluster != "staging" || got.Namespace != "api" {
+		t.Errorf("unexpected context for alice: %+v", got)
+	}
+	if got, _ := callSessionTool(t, bob, "get_current_cluster", nil); got != (SessionContextResult{Cluster: "prod", Namespace: "default", Source: "server"}) {
+		t.Errorf("expected bob to keep the server defaults, got %+v", got)
+	}
+	if current := s.clusterManager.GetCurrentCluster(); current != "prod" {
+		t.Errorf("switch_cluster changed the server's current cluster to %s", current)
+	}
+
+	if _, isError := callSessionTool(t, alice, "switch_cluster", map[string]any{"cluster_name": "missing"}); !isError {
+		t.Errorf("expected an error for an unknown cluster")
+	}
+}
+
+// TestSetNamespacePolicy 测试命名空间受限模式下 set_namespace 拒绝不允许的命名空间
+func TestSetNamespacePolicy(t *testing.T) {
+	policy, err := k8s.ParseNamespacePolicy("team-a", false)
+	if err != nil {
+		t.Fatalf("ParseNamespacePolicy failed: %v", err)
+	}
+	s := NewServer("test-token", &Options{NamespacePolicy: policy})
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	if _, isError := callSessionTool(t, session, "set_namespace", map[string]any{"namespace": "kube-system"}); !isError {
+		t.Errorf("expected an error for a namespace outside the policy")
+	}
+	if got, isError := callSessionTool(t, session, "set_namespace", map[string]any{"namespace": "team-a"}); isError || got.Namespace != "team-a" {
+		t.Errorf("unexpected set_namespace result: %+v", got)
+	}
+}
+
+// TestSessionStoreExpiry 测试空闲超时的会话被删除，活跃的会话保留
+func TestSessionStoreExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	st := newSessionStore(5 * time.Minute)
+	st.now = func() time.Time { return now }
+
+	st.update("idle", func(state *sessionState) { state.cluster = "staging" })
+	st.update("active", func(state *sessionState) { state.namespace = "api" })
+
+	now = now.Add(4 * time.Minute)
+	st.touch("active")
+	now = now.Add(2 * time.Minute)
+
+	if _, ok := st.get("idle"); ok {
+		t.Errorf("expected the idle session to expire")
+	}
+	if state, ok := st.get("active"); !ok || state.namespace != "api" {
+		t.Errorf("expected the active session to be kept, got %+v", state)
+	}
+	if n := st.count(); n != 1 {
+		t.Errorf("expected 1 session, got %d", n)
+	}
+}
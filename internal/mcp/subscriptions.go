@@ -0,0 +1,233 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// defaultSubscriptionDebounce is the minimum interval between two update notifications for the same URI
+// defaultSubscriptionDebounce 同一 URI 两次更新通知之间的最小间隔
+const defaultSubscriptionDebounce = 2 * time.Second
+
+// subscriptionManager runs one watch per subscribed URI and notifies on changes.
+// A watch is started by the first subscriber of a URI and stopped when the last
+// one goes away.
+// subscriptionManager 为每个被订阅的 URI 运行一个 watch 并在变化时发送通知。
+// watch 由 URI 的第一个订阅者启动，在最后一个订阅者离开时停止。
+type subscriptionManager struct {
+	mu      sync.Mutex
+	watches map[string]*uriWatch
+	// subscribers tracks every subscriber seen, so disconnect cleanup is registered once
+	// subscribers 记录所有已知订阅者，保证断开连接的清理只注册一次
+	subscribers map[any]bool
+
+	watchFn  func(ctx context.Context, uri string) (watch.Interface, error)
+	notify   func(uri string)
+	debounce time.Duration
+}
+
+// uriWatch is the running watch of a single URI
+// uriWatch 单个 URI 正在运行的 watch
+type uriWatch struct {
+	cancel      context.CancelFunc
+	subscribers map[any]bool
+}
+
+// newSubscriptionManager creates a subscription manager
+// newSubscriptionManager 创建订阅管理器
+func newSubscriptionManager(watchFn func(ctx context.Context, uri string) (watch.Interface, error), notify func(uri string)) *subscriptionManager {
+	return &subscriptionManager{
+		watches:     make(map[string]*uriWatch),
+		subscribers: make(map[any]bool),
+		watchFn:     watchFn,
+		notify:      notify,
+		debounce:    defaultSubscriptionDebounce,
+	}
+}
+
+// subscribe adds a subscriber to a URI, starting its watch if needed. The watch is started
+// without holding the lock, so a slow API server doesn't block other URIs; when another
+// caller started the same watch meanwhile, the duplicate is stopped.
+// It reports whether the subscriber has not been seen before.
+// subscribe 为 URI 添加订阅者，必要时启动 watch。启动 watch 时不持有锁，避免缓慢的 API Server 阻塞其他 URI；
+// 若其间另一个调用者已启动同一 watch，则停止重复的 watch。返回该订阅者是否首次出现。
+func (m *subscriptionManager) subscribe(subscriber any, uri string) (bool, error) {
+	m.mu.Lock()
+	if w, ok := m.watches[uri]; ok {
+		defer m.mu.Unlock()
+		return m.addSubscriberLocked(w, subscriber), nil
+	}
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wi, err := m.watchFn(ctx, uri)
+	if err != nil {
+		cancel()
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.watches[uri]
+	if ok {
+		wi.Stop()
+		cancel()
+	} else {
+		w = &uriWatch{cancel: cancel, subscribers: make(map[any]bool)}
+		m.watches[uri] = w
+		go m.run(ctx, uri, wi)
+	}
+	return m.addSubscriberLocked(w, subscriber), nil
+}
+
+// addSubscriberLocked adds a subscriber to a running watch and reports whether the
+// subscriber has not been seen before
+// addSubscriberLocked 将订阅者添加到正在运行的 watch，返回该订阅者是否首次出现
+func (m *subscriptionManager) addSubscriberLocked(w *uriWatch, subscriber any) bool {
+	w.subscribers[subscriber] = true
+	isNew := !m.subscribers[subscriber]
+	m.subscribers[subscriber] = true
+	return isNew
+}
+
+// unsubscribe removes a subscriber from a URI, stopping the watch when no subscribers remain
+// unsubscribe 从 URI 移除订阅者，没有订阅者时停止 watch
+func (m *subscriptionManager) unsubscribe(subscriber any, uri string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unsubscribeLocked(subscriber, uri)
+}
+
+func (m *subscriptionManager) unsubscribeLocked(subscriber any, uri string) {
+	w, ok := m.watches[uri]
+	if !ok {
+		return
+	}
+	delete(w.subscribers, subscriber)
+	if len(w.subscribers) == 0 {
+		w.cancel()
+		delete(m.watches, uri)
+	}
+}
+
+// removeSubscriber drops all subscriptions of a disconnected subscriber
+// removeSubscriber 移除已断开连接的订阅者的所有订阅
+func (m *subscriptionManager) removeSubscriber(subscriber any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for uri := range m.watches {
+		m.unsubscribeLocked(subscriber, uri)
+	}
+	delete(m.subscribers, subscriber)
+}
+
+// close stops all watches
+// close 停止所有 watch
+func (m *subscriptionManager) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for uri, w := range m.watches {
+		w.cancel()
+		delete(m.watches, uri)
+	}
+	m.subscribers = make(map[any]bool)
+}
+
+// run forwards watch events as debounced notifications until ctx is cancelled.
+// The API server closes watches periodically, so a closed watch is re-established.
+// run 将 watch 事件转换为去抖后的通知，直到 ctx 被取消。
+// API Server 会定期关闭 watch，因此关闭后会重新建立。
+func (m *subscriptionManager) run(ctx context.Context, uri string, w watch.Interface) {
+	defer func() { w.Stop() }()
+
+	var (
+		timer    *time.Timer
+		timerC   <-chan time.Time
+		lastSent time.Time
+	)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				w.Stop()
+				if w = m.rewatch(ctx, uri); w == nil {
+					return
+				}
+				// Changes may have been missed while reconnecting
+				// 重连期间可能错过了变化
+			}
+			if timerC != nil {
+				continue
+			}
+			wait := m.debounce - time.Since(lastSent)
+			if wait < 0 {
+				wait = 0
+			}
+			timer = time.NewTimer(wait)
+			timerC = timer.C
+		case <-timerC:
+			timerC = nil
+			lastSent = time.Now()
+			m.notify(uri)
+		}
+	}
+}
+
+// rewatch re-establishes a watch, retrying until it succeeds or ctx is cancelled
+// rewatch 重新建立 watch，直到成功或 ctx 被取消
+func (m *subscriptionManager) rewatch(ctx context.Context, uri string) watch.Interface {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(m.debounce):
+		}
+		if w, err := m.watchFn(ctx, uri); err == nil {
+			return w
+		}
+	}
+}
+
+// handleSubscribe serves resources/subscribe by starting a watch for the URI
+// handleSubscribe 处理 resources/subscribe 请求，为 URI 启动 watch
+func (s *Server) handleSubscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	session := req.Session
+	isNew, err := s.subscriptions.subscribe(session, req.Params.URI)
+	if err != nil {
+		return err
+	}
+	if isNew && session != nil {
+		// Clean up all subscriptions of the session once the client disconnects
+		// 客户端断开连接后清理该会话的所有订阅
+		go func() {
+			_ = session.Wait()
+			s.subscriptions.removeSubscriber(session)
+		}()
+	}
+	return nil
+}
+
+// handleUnsubscribe serves resources/unsubscribe
+// handleUnsubscribe 处理 resources/unsubscribe 请求
+func (s *Server) handleUnsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	s.subscriptions.unsubscribe(req.Session, req.Params.URI)
+	return nil
+}
+
+// notifyResourceUpdated sends notifications/resources/updated to the subscribers of a URI
+// notifyResourceUpdated 向 URI 的订阅者发送 notifications/resources/updated
+func (s *Server) notifyResourceUpdated(uri string) {
+	_ = s.mcpServer.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+}
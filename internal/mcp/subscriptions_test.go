@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakeWatcher 记录启动的 fake watch，并收集发送的通知
+type fakeWatcher struct {
+	mu       sync.Mutex
+	watches  map[string]*watch.FakeWatcher
+	started  map[string]int
+	notified chan string
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		watches:  make(map[string]*watch.FakeWatcher),
+		started:  make(map[string]int),
+		notified: make(chan string, 16),
+	}
+}
+
+func (f *fakeWatcher) watch(ctx context.Context, uri string) (watch.Interface, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := watch.NewFake()
+	f.watches[uri] = w
+	f.started[uri]++
+	return w, nil
+}
+
+func (f *fakeWatcher) get(uri string) *watch.FakeWatcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.watches[uri]
+}
+
+// TestSubscriptionDebounce 测试短时间内的多个事件只产生一次通知
+func TestSubscriptionDebounce(t *testing.T) {
+	f := newFakeWatcher()
+	m := newSubscriptionManager(f.watch, func(uri string) { f.notified <- uri })
+	m.debounce = 200 * time.Millisecond
+	defer m.close()
+
+	const uri = "k8s://cluster/prod/namespace/default/pods"
+	if _, err := m.subscribe("session-1", uri); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	w := f.get(uri)
+	w.Add(&corev1.Pod{})
+	select {
+	case got := <-f.notified:
+		if got != uri {
+			t.Errorf("unexpected notification for %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a notification for the first event")
+	}
+
+	// Events inside the debounce window are coalesced into one notification
+	// 去抖窗口内的事件会合并为一次通知
+	for i := 0; i < 5; i++ {
+		w.Modify(&corev1.Pod{})
+	}
+	select {
+	case <-f.notified:
+	case <-time.After(time.Second):
+		t.Fatalf("expected a coalesced notification")
+	}
+	select {
+	case <-f.notified:
+		t.Errorf("expected burst to produce a single notification")
+	case <-time.After(3 * m.debounce):
+	}
+}
+
+// TestSubscriptionRefCounting 测试 watch 在最后一个订阅者离开时停止
+func TestSubscriptionRefCounting(t *testing.T) {
+	f := newFakeWatcher()
+	m := newSubscriptionManager(f.watch, func(string) {})
+	defer m.close()
+
+	const uri = "k8s://cluster/prod/namespaces"
+	isNew, err := m.subscribe("session-1", uri)
+	if err != nil || !isNew {
+		t.Fatalf("expected first subscription of a new subscriber: %v %v", isNew, err)
+	}
+	if isNew, _ := m.subscribe("session-2", uri); !isNew {
+		t.Errorf("expected session-2 to be a new subscriber")
+	}
+	if isNew, _ := m.subscribe("session-1", uri); isNew {
+		t.Errorf("expected session-1 to be known")
+	}
+	if f.started[uri] != 1 {
+		t.Errorf("expected a single shared watch, got %d", f.started[uri])
+	}
+
+	m.unsubscribe("session-1", uri)
+	if _, ok := m.watches[uri]; !ok {
+		t.Fatalf("watch stopped while session-2 is still subscribed")
+	}
+
+	// Disconnecting the last subscriber stops the watch
+	// 最后一个订阅者断开后停止 watch
+	m.removeSubscriber("session-2")
+	if _, ok := m.watches[uri]; ok {
+		t.Errorf("expected watch to stop after the last subscriber left")
+	}
+	deadline := time.After(time.Second)
+	for !f.get(uri).IsStopped() {
+		select {
+		case <-deadline:
+			t.Fatalf("expected underlying watch to be stopped")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// TestSubscribeWatchOutsideLock 测试启动 watch 时不持有锁：缓慢的 watch 不阻塞其他 URI，
+// 并发订阅同一 URI 时只保留一个 watch 并停止重复的 watch
+func TestSubscribeWatchOutsideLock(t *testing.T) {
+	const slowURI = "k8s://cluster/prod/namespace/default/pods"
+	const fastURI = "k8s://cluster/prod/namespaces"
+	f := newFakeWatcher()
+	release := make(chan struct{})
+	var (
+		mu      sync.Mutex
+		started []*watch.FakeWatcher
+	)
+	watchFn := func(ctx context.Context, uri string) (watch.Interface, error) {
+		if uri == slowURI {
+			<-release
+		}
+		w, err := f.watch(ctx, uri)
+		mu.Lock()
+		started = append(started, w.(*watch.FakeWatcher))
+		mu.Unlock()
+		return w, err
+	}
+	m := newSubscriptionManager(watchFn, func(string) {})
+	defer m.close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := m.subscribe(fmt.Sprintf("session-%d", i), slowURI); err != nil {
+				t.Errorf("subscribe failed: %v", err)
+			}
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.subscribe("session-3", fastURI)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected a slow watch not to block other URIs")
+	}
+
+	close(release)
+	wg.Wait()
+	m.mu.Lock()
+	subscribers := len(m.watches[slowURI].subscribers)
+	m.mu.Unlock()
+	if subscribers != 2 {
+		t.Errorf("expected both sessions on one watch, got %d subscribers", subscribers)
+	}
+
+	// 两个调用都启动了 watch 时，输掉的一方被停止
+	mu.Lock()
+	defer mu.Unlock()
+	running := 0
+	for _, w := range started {
+		if !w.IsStopped() {
+			running++
+		}
+	}
+	if running != 2 {
+		t.Errorf("expected one watch per URI to keep running, got %d of %d", running, len(started))
+	}
+}
+
+// TestSubscribeCapability 测试只有启用订阅时才声明 subscribe 能力
+func TestSubscribeCapability(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		s := NewServer("test-token", &Options{EnableSubscriptions: enabled})
+		s.RegisterResources()
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			caps := connectTestClient(t, s, nil).InitializeResult().Capabilities
+			if caps.Resources == nil || caps.Resources.Subscribe != enabled {
+				t.Errorf("unexpected resources capability %+v", caps.Resources)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxTableCellWidth is the widest a table cell gets; longer values are cut with an
+// ellipsis. The JSON output always keeps the full values.
+// maxTableCellWidth 为表格单元格的最大宽度，更长的值以省略号截断。JSON 输出始终保留完整的值
+const maxTableCellWidth = 60
+
+// tableNone is shown for empty cells, like kubectl
+// tableNone 与 kubectl 相同，用于显示空单元格
+const tableNone = "<none>"
+
+// errUnknownTableColumn is returned for a column the table does not have
+// errUnknownTableColumn 表示表格中没有指定的列
+var errUnknownTableColumn = errors.New("unknown column")
+
+// tableColumn is one column of a text table: its header and the JSON key of the field
+// it shows. Columns marked optional are only shown when asked for through columns;
+// columns with hideUniform are left out by default when every row has the same value (the
+// namespace of a single-namespace list, the source of clusters from one kubeconfig).
+// tableColumn 是文本表格的一列：表头及其显示的字段的 JSON 键。optional 列只在通过 columns 指定时显示；
+// hideUniform 的列在所有行的值都相同时默认不显示 (例如单个命名空间列表的命名空间、来自同一 kubeconfig 的集群的来源)
+type tableColumn struct {
+	header      string
+	key         string
+	optional    bool
+	hideUniform bool
+}
+
+// Common columns of the resource tables
+// 资源表格的通用列
+var (
+	namespaceColumn = tableColumn{header: "NAMESPACE", key: "namespace", hideUniform: true}
+	nameColumn      = tableColumn{header: "NAME", key: "name"}
+	statusColumn    = tableColumn{header: "STATUS", key: "status"}
+	ageColumn       = tableColumn{header: "AGE", key: "age"}
+	labelsColumn    = tableColumn{header: "LABELS", key: "labels", optional: true}
+)
+
+// resourceTableColumns returns the columns of a list of resources, following kubectl get;
+// types without their own set are shown through their ResourceInfo form
+// resourceTableColumns 返回资源列表的列，与 kubectl get 一致；没有专门列集合的类型通过其 ResourceInfo 形式显示
+func resourceTableColumns(resources interface{}) ([]tableColumn, bool) {
+	switch resources.(type) {
+	case []types.Pod:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "READY", key: "ready"}, statusColumn, {header: "RESTARTS", key: "restarts"}, {header: "OWNER", key: "owner"}, ageColumn, labelsColumn}, true
+	case []types.Service:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "TYPE", key: "type"}, {header: "CLUSTER-IP", key: "cluster_ip"}, {header: "PORTS", key: "ports"}, ageColumn, labelsColumn}, true
+	case []types.Deployment:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "READY", key: "ready"}, {header: "UP-TO-DATE", key: "up_to_date"}, {header: "AVAILABLE", key: "available"}, ageColumn, labelsColumn}, true
+	case []types.StatefulSet:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "READY", key: "ready"}, ageColumn, labelsColumn}, true
+	case []types.ConfigMap:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "DATA", key: "data_count"}, ageColumn, labelsColumn}, true
+	case []types.Node:
+		return []tableColumn{nameColumn, statusColumn, {header: "ROLES", key: "roles"}, ageColumn, {header: "VERSION", key: "version"}, labelsColumn}, true
+	case []types.Namespace:
+		return []tableColumn{nameColumn, statusColumn, ageColumn, labelsColumn}, true
+	case []types.PersistentVolume:
+		return []tableColumn{nameColumn, {header: "CAPACITY", key: "capacity"}, {header: "ACCESS-MODES", key: "access_modes"}, {header: "RECLAIM-POLICY", key: "reclaim_policy"}, statusColumn, {header: "CLAIM", key: "claim"}, {header: "STORAGECLASS", key: "storage_class"}, ageColumn, labelsColumn}, true
+	case []types.PersistentVolumeClaim:
+		return []tableColumn{namespaceColumn, nameColumn, statusColumn, {header: "VOLUME", key: "volume"}, {header: "CAPACITY", key: "capacity"}, {header: "ACCESS-MODES", key: "access_modes"}, {header: "STORAGECLASS", key: "storage_class"}, ageColumn, labelsColumn}, true
+	case []types.Ingress:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "CLASS", key: "class"}, {header: "HOSTS", key: "hosts"}, {header: "ADDRESS", key: "address"}, ageColumn, labelsColumn}, true
+	case []types.CronJob:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "SCHEDULE", key: "schedule"}, {header: "SUSPEND", key: "suspend"}, {header: "ACTIVE", key: "active"}, {header: "LAST-SCHEDULE", key: "last_schedule"}, ageColumn, labelsColumn}, true
+	case []types.Job:
+		return []tableColumn{namespaceColumn, nameColumn, statusColumn, {header: "COMPLETIONS", key: "completions"}, {header: "DURATION", key: "duration"}, ageColumn, labelsColumn}, true
+	case []types.Event:
+		return []tableColumn{namespaceColumn, {header: "LAST-SEEN", key: "last_seen"}, {header: "TYPE", key: "type"}, {header: "REASON", key: "reason"}, {header: "OBJECT", key: "object"}, {header: "MESSAGE", key: "message"}, labelsColumn}, true
+	case []k8s.ResourceInfo:
+		return []tableColumn{namespaceColumn, nameColumn, {header: "KIND", key: "kind"}, statusColumn, ageColumn, labelsColumn}, true
+	}
+	return nil, false
+}
+
+// validateTableFormat checks the format and columns arguments of the listing tools,
+// returning a tool error for invalid ones
+// validateTableFormat 检查列表工具的 format 和 columns 参数，无效时返回工具错误
+func validateTableFormat(format, columns string) *mcp.CallToolResult {
+	switch {
+	case format != "" && format != "json" && format != "text":
+		return toolError(fmt.Sprintf("unsupported format %q, expected \"json\" or \"text\"", format))
+	case columns != "" && format != "text":
+		return toolError("columns can only be used with format \"text\"")
+	}
+	return nil
+}
+
+// formatResourceList serializes resources to compact JSON, or renders them as a table
+// with the given columns when format is "text"
+// formatResourceList 将资源序列化为紧凑的 JSON，format 为 "text" 时使用指定的列渲染为表格
+func formatResourceList(resources interface{}, format, columns string) (string, error) {
+	if format == "text" {
+		return renderResourceTable(resources, columns)
+	}
+	return serializeResourceList(resources)
+}
+
+// renderResourceTable renders a list of resources as a table with the given columns
+// (comma separated, see selectTableColumns)
+// renderResourceTable 使用指定的列 (逗号分隔，见 selectTableColumns) 将资源列表渲染为表格
+func renderResourceTable(resources interface{}, columns string) (string, error) {
+	available, ok := resourceTableColumns(resources)
+	if !ok {
+		infos, err := k8s.ToResourceInfos(resources)
+		if err != nil {
+			return "", err
+		}
+		resources = infos
+		available, _ = resourceTableColumns(infos)
+	}
+	rows, err := tableRows(resources)
+	if err != nil {
+		return "", err
+	}
+	selected, err := selectTableColumns(available, columns, rows)
+	if err != nil {
+		return "", err
+	}
+	return renderTable(rows, selected), nil
+}
+
+// tableRows converts a slice of structs to rows keyed by their JSON field names
+// tableRows 将结构体切片转换为以 JSON 字段名为键的行
+func tableRows(items interface{}) ([]map[string]interface{}, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render table: %w", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to render table: %w", err)
+	}
+	return rows, nil
+}
+
+// selectTableColumns picks the columns to show. Without a request these are the
+// non-optional columns, minus hideUniform columns whose value is the same in every row.
+// A request is a comma-separated list of headers in any case, with "_" accepted for "-"
+// (e.g. "name,status" or "up_to_date"), shown in the order given.
+// selectTableColumns 选择要显示的列。未指定时为所有非 optional 的列，去掉所有行的值都相同的 hideUniform 列。
+// 指定时为逗号分隔的表头列表，不区分大小写，"_" 等同于 "-" (如 "name,status" 或 "up_to_date")，按给定顺序显示
+func selectTableColumns(available []tableColumn, requested string, rows []map[string]interface{}) ([]tableColumn, error) {
+	if strings.TrimSpace(requested) == "" {
+		var selected []tableColumn
+		for _, column := range available {
+			if column.optional || (column.hideUniform && uniformColumn(rows, column.key)) {
+				continue
+			}
+			selected = append(selected, column)
+		}
+		return selected, nil
+	}
+
+	byName := make(map[string]tableColumn, len(available))
+	names := make([]string, 0, len(available))
+	for _, column := range available {
+		name := strings.ToLower(column.header)
+		byName[name] = column
+		names = append(names, name)
+	}
+	var selected []tableColumn
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+		if name == "" {
+			continue
+		}
+		column, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q, available columns: %s", errUnknownTableColumn, name, strings.Join(names, ", "))
+		}
+		selected = append(selected, column)
+	}
+	return selected, nil
+}
+
+// uniformColumn reports whether every row has the same value for key
+// uniformColumn 判断所有行中 key 的值是否都相同
+func uniformColumn(rows []map[string]interface{}, key string) bool {
+	for _, row := range rows {
+		if formatTableCell(row[key]) != formatTableCell(rows[0][key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTable renders rows as aligned columns under a header line
+// renderTable 将行渲染为带表头的对齐列
+func renderTable(rows []map[string]interface{}, columns []tableColumn) string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = truncateTableCell(formatTableCell(row[column.key]))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatTableCell formats a JSON value for a table cell: lists joined with ",", maps as
+// sorted key=value pairs, and empty values as <none>
+// formatTableCell 将 JSON 值格式化为表格单元格：列表以 "," 连接，map 为排序后的 key=value，空值显示为 <none>
+func formatTableCell(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatTableCell(item))
+		}
+		text = strings.Join(items, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, key+"="+formatTableCell(v[key]))
+		}
+		text = strings.Join(pairs, ",")
+	default:
+		text = fmt.Sprint(v)
+	}
+	if text == "" {
+		return tableNone
+	}
+	// Tabs and newlines would break the alignment
+	// 制表符和换行会破坏对齐
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(text)
+}
+
+// truncateTableCell cuts a cell longer than maxTableCellWidth runes with an ellipsis
+// truncateTableCell 将超过 maxTableCellWidth 个字符的单元格以省略号截断
+func truncateTableCell(cell string) string {
+	if utf8.RuneCountInString(cell) <= maxTableCellWidth {
+		return cell
+	}
+	return string([]rune(cell)[:maxTableCellWidth-1]) + "…"
+}
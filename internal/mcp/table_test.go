@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden 将输出与 testdata 中的 golden 文件比较，-update 时重写文件
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %v", path, err)
+	}
+	if string(want) != got {
+		t.Errorf("output does not match %s:\n--- want ---\n%s\n--- got ---\n%s", path, want, got)
+	}
+}
+
+// tablePods 表格测试使用的 Pod，其中一个名称超过单元格宽度
+var tablePods = []types.Pod{
+	{Name: "web-5f6d8c7b9-abcde", Namespace: "shop", Status: "Running", Ready: "1/1", Owner: "Deployment/web", Age: "3d", Labels: map[string]string{"app": "web", "tier": "frontend"}},
+	{Name: "payments-reconciliation-worker-with-a-very-long-generated-name-7f9c4", Namespace: "shop", Status: "CrashLoopBackOff", Ready: "0/1", Restarts: 42, Owner: "Deployment/payments-reconciliation-worker", Age: "12m"},
+	{Name: "debug", Namespace: "shop", Status: "Pending", Ready: "0/1", Owner: "<none>", Age: "5s"},
+}
+
+// TestRenderResourceTables 测试各资源类型的表格渲染，包括没有专门列集合的类型、列选择和长值截断
+func TestRenderResourceTables(t *testing.T) {
+	cases := []struct {
+		golden    string
+		resources interface{}
+		columns   string
+	}{
+		{golden: "pods.txt", resources: tablePods},
+		{golden: "pods_columns.txt", resources: tablePods, columns: "name,STATUS,restarts"},
+		{golden: "pods_labels.txt", resources: tablePods, columns: "name,labels"},
+		{golden: "pods_all_namespaces.txt", resources: append([]types.Pod{{Name: "coredns-76f75df574-x2x9p", Namespace: "kube-system", Status: "Running", Ready: "1/1", Owner: "Deployment/coredns", Age: "40d"}}, tablePods...)},
+		{golden: "deployments.txt", resources: []types.Deployment{
+			{Name: "web", Namespace: "shop", Ready: "3/3", UpToDate: "3", Available: "3", Age: "3d"},
+			{Name: "payments", Namespace: "shop", Ready: "0/2", UpToDate: "2", Available: "0", Age: "12m"},
+		}},
+		{golden: "nodes.txt", resources: []types.Node{
+			{Name: "control-plane-1", Status: "Ready", Roles: "control-plane", Version: "v1.29.3", Age: "120d"},
+			{Name: "worker-1", Status: "Ready,SchedulingDisabled", Roles: "", Version: "v1.29.3", Age: "90d"},
+		}},
+		{golden: "hpas.txt", resources: []types.HorizontalPodAutoscaler{
+			{Name: "web", Namespace: "shop", Target: "Deployment/web", MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 3, DesiredReplicas: 4, Age: "3d",
+				Metrics: []types.HPAMetric{{Type: "Resource", Name: "cpu", Current: "85%", Target: "80%"}}},
+		}},
+		{golden: "empty.txt", resources: []types.Deployment{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.golden, func(t *testing.T) {
+			got, err := renderResourceTable(tc.resources, tc.columns)
+			if err != nil {
+				t.Fatalf("renderResourceTable failed: %v", err)
+			}
+			assertGolden(t, filepath.Join("tables", tc.golden), got+"\n")
+		})
+	}
+}
+
+// TestRenderResourceTableUnknownColumn 测试未知列返回 errUnknownTableColumn 并列出可用的列
+func TestRenderResourceTableUnknownColumn(t *testing.T) {
+	_, err := renderResourceTable(tablePods, "name,node")
+	if !errors.Is(err, errUnknownTableColumn) || !strings.Contains(err.Error(), "available columns: namespace, name, ready") {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
+
+// TestFormatResourceListJSONKeepsFullValues 测试 JSON 模式不截断长值
+func TestFormatResourceListJSONKeepsFullValues(t *testing.T) {
+	got, err := formatResourceList(tablePods, "", "")
+	if err != nil {
+		t.Fatalf("formatResourceList failed: %v", err)
+	}
+	if !strings.Contains(got, tablePods[1].Name) || strings.Contains(got, "…") {
+		t.Errorf("expected the full name in JSON output, got %s", got)
+	}
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+)
+
+// tlsVersions maps the --tls-min-version values to TLS versions
+// tlsVersions 将 --tls-min-version 的取值映射为 TLS 版本
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTLSConfig returns the TLS config of the HTTPS listener. minVersion is "1.2" or "1.3"
+// ("" means 1.2). cipherSuites are Go cipher suite names such as
+// TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256; they only apply to TLS 1.2, since TLS 1.3 suites
+// are not configurable, so they are rejected with a 1.3 minimum. Unknown and insecure suite
+// names are errors. An empty list keeps Go's defaults.
+// NewTLSConfig 返回 HTTPS 监听器的 TLS 配置。minVersion 为 "1.2" 或 "1.3"（为空表示 1.2）。
+// cipherSuites 为 Go 的密码套件名称，例如 TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256；它们只作用于 TLS 1.2，
+// TLS 1.3 的套件不可配置，因此最低版本为 1.3 时指定套件会报错。未知或不安全的套件名称会报错，列表为空时使用 Go 的默认值。
+func NewTLSConfig(minVersion string, cipherSuites []string) (*tls.Config, error) {
+	if minVersion == "" {
+		minVersion = "1.2"
+	}
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS minimum version %q: must be 1.2 or 1.3", minVersion)
+	}
+	config := &tls.Config{MinVersion: version}
+	if len(cipherSuites) == 0 {
+		return config, nil
+	}
+	if version == tls.VersionTLS13 {
+		return nil, fmt.Errorf("cipher suites cannot be configured with TLS minimum version 1.3: TLS 1.3 suites are fixed")
+	}
+
+	secure := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite.ID
+	}
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+	for _, name := range cipherSuites {
+		name = strings.TrimSpace(name)
+		if id, ok := secure[name]; ok {
+			config.CipherSuites = append(config.CipherSuites, id)
+			continue
+		}
+		if insecure[name] {
+			return nil, fmt.Errorf("cipher suite %s is insecure and not allowed", name)
+		}
+		return nil, fmt.Errorf("unknown cipher suite %q; supported: %s", name, strings.Join(SupportedCipherSuites(), ", "))
+	}
+	return config, nil
+}
+
+// SupportedCipherSuites returns the names accepted by NewTLSConfig, sorted
+// SupportedCipherSuites 返回 NewTLSConfig 接受的套件名称（已排序）
+func SupportedCipherSuites() []string {
+	var names []string
+	for _, suite := range tls.CipherSuites() {
+		for _, version := range suite.SupportedVersions {
+			if version == tls.VersionTLS12 {
+				names = append(names, suite.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CertReloader serves the certificate/key pair of the HTTPS listener through
+// tls.Config.GetCertificate and reloads it when asked to, so rotated certificates (e.g.
+// by cert-manager) take effect without a restart. A failed reload keeps the current pair.
+// CertReloader 通过 tls.Config.GetCertificate 提供 HTTPS 监听器的证书和私钥，并在需要时重新加载，
+// 使轮换后的证书（例如由 cert-manager 轮换）无需重启即可生效。重新加载失败时保留当前证书。
+type CertReloader struct {
+	certPath string
+	keyPath  string
+	logger   logger.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+// NewCertReloader loads the pair at certPath and keyPath
+// NewCertReloader 加载 certPath 和 keyPath 处的证书和私钥
+func NewCertReloader(certPath, keyPath string, log logger.Logger) (*CertReloader, error) {
+	if log == nil {
+		log = logger.Get()
+	}
+	r := &CertReloader{certPath: certPath, keyPath: keyPath, logger: log}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the pair from disk again; on error the current pair stays in use
+// Reload 重新从磁盘读取证书和私钥；出错时继续使用当前证书
+func (r *CertReloader) Reload() error {
+	modTimes := r.fileModTimes()
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+// GetCertificate 实现 tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch checks the modification times of the files every interval until ctx is done and
+// reloads the pair when either changed. os.Stat follows symlinks, so the symlink swap used
+// for Kubernetes Secret volumes is noticed too.
+// Watch 每隔 interval 检查文件的修改时间，直到 ctx 结束，任一文件变化时重新加载。
+// os.Stat 会跟随符号链接，因此 Kubernetes Secret 卷使用的符号链接切换同样能被发现。
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		changed := r.fileModTimes() != r.modTimes
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			r.logger.Warn("Failed to reload TLS certificate, keeping the current one", "error", err)
+			continue
+		}
+		r.logger.Info("TLS certificate reloaded", "cert", r.certPath)
+	}
+}
+
+// fileModTimes returns the modification times of the certificate and key files;
+// a file that cannot be read has the zero time
+// fileModTimes 返回证书和私钥文件的修改时间，无法读取的文件为零值
+func (r *CertReloader) fileModTimes() [2]time.Time {
+	var modTimes [2]time.Time
+	for i, path := range []string{r.certPath, r.keyPath} {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeServerCert 在 dir 中写入序列号为 serial 的自签名 ECDSA 服务器证书和私钥
+func writeServerCert(t *testing.T, dir string, serial int64) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate server key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "k8s-mcp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create server certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal server key: %v", err)
+	}
+
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+// startTLSListener 使用 config 和 reloader 提供的证书启动只完成握手的 TLS 监听器，返回地址
+func startTLSListener(t *testing.T, config *tls.Config, reloader *CertReloader) string {
+	t.Helper()
+	config.GetCertificate = reloader.GetCertificate
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// handshake 使用 client 配置连接 addr，返回服务器证书的序列号
+func handshake(addr string, client *tls.Config) (int64, error) {
+	client.InsecureSkipVerify = true
+	conn, err := tls.Dial("tcp", addr, client)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+// TestTLSConfigHandshake 测试最低版本和密码套件限制对允许和不允许的客户端设置生效
+func TestTLSConfigHandshake(t *testing.T) {
+	certPath, keyPath := writeServerCert(t, t.TempDir(), 1)
+	reloader, err := NewCertReloader(certPath, keyPath, nil)
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		minVersion   string
+		cipherSuites []string
+		client       *tls.Config
+		wantErr      bool
+	}{
+		{"default allows TLS 1.2", "", nil, &tls.Config{MaxVersion: tls.VersionTLS12}, false},
+		{"1.3 allows TLS 1.3", "1.3", nil, &tls.Config{MinVersion: tls.VersionTLS13}, false},
+		{"1.3 rejects TLS 1.2", "1.3", nil, &tls.Config{MaxVersion: tls.VersionTLS12}, true},
+		{
+			"configured suite allowed", "1.2", []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			&tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}, false,
+		},
+		{
+			"other suite rejected", "1.2", []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			&tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}}, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewTLSConfig(tt.minVersion, tt.cipherSuites)
+			if err != nil {
+				t.Fatalf("NewTLSConfig failed: %v", err)
+			}
+			addr := startTLSListener(t, config, reloader)
+			_, err = handshake(addr, tt.client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handshake error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestNewTLSConfigErrors 测试未知版本、未知或不安全的套件以及 1.3 下指定套件时报错
+func TestNewTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		minVersion   string
+		cipherSuites []string
+		wantErr      string
+	}{
+		{"unknown version", "1.1", nil, "must be 1.2 or 1.3"},
+		{"unknown suite", "1.2", []string{"TLS_FAKE_SUITE"}, `unknown cipher suite "TLS_FAKE_SUITE"`},
+		{"insecure suite", "1.2", []string{"TLS_RSA_WITH_RC4_128_SHA"}, "is insecure"},
+		{"suites with 1.3", "1.3", []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}, "TLS minimum version 1.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTLSConfig(tt.minVersion, tt.cipherSuites)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestCertReloader 测试 Reload 和文件监视使新连接使用轮换后的证书，加载失败时保留原证书
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeServerCert(t, dir, 1)
+	reloader, err := NewCertReloader(certPath, keyPath, nil)
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+	config, _ := NewTLSConfig("", nil)
+	addr := startTLSListener(t, config, reloader)
+
+	if serial, err := handshake(addr, &tls.Config{}); err != nil || serial != 1 {
+		t.Fatalf("expected certificate 1, got %d (%v)", serial, err)
+	}
+
+	// 显式 Reload（SIGHUP 时调用）
+	writeServerCert(t, dir, 2)
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if serial, err := handshake(addr, &tls.Config{}); err != nil || serial != 2 {
+		t.Fatalf("expected certificate 2 after Reload, got %d (%v)", serial, err)
+	}
+
+	// 损坏的文件不会替换当前证书
+	os.WriteFile(keyPath, []byte("garbage"), 0o600)
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("expected Reload to fail with an invalid key")
+	}
+	if serial, err := handshake(addr, &tls.Config{}); err != nil || serial != 2 {
+		t.Fatalf("expected certificate 2 after failed Reload, got %d (%v)", serial, err)
+	}
+
+	// 文件监视发现修改时间变化后重新加载
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+	writeServerCert(t, dir, 3)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certPath, future, future)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		serial, err := handshake(addr, &tls.Config{})
+		if err == nil && serial == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("watcher did not reload certificate 3, got %d (%v)", serial, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/k8s"
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolErrorResult is the machine-readable block of a failed tool call, sent as the
+// structured content and as a JSON text after the error message
+// ToolErrorResult 工具调用失败时的机器可读块，作为结构化内容发送，同时以 JSON 文本附在错误信息之后
+type ToolErrorResult struct {
+	Error types.ToolErrorDetails `json:"error"`
+}
+
+// newToolError builds the result of a tool call that failed with err: the error message
+// for humans, followed by the classification of err as JSON
+// newToolError 构造因 err 失败的工具调用结果：可读的错误信息，后跟 err 分类的 JSON
+func newToolError(err error) *mcp.CallToolResult {
+	block := ToolErrorResult{Error: k8s.ClassifyError(err)}
+	content := []mcp.Content{&mcp.TextContent{Text: err.Error()}}
+	if data, marshalErr := json.Marshal(block); marshalErr == nil {
+		content = append(content, &mcp.TextContent{Text: string(data)})
+	}
+	return &mcp.CallToolResult{
+		IsError:           true,
+		Content:           content,
+		StructuredContent: block,
+	}
+}
+
+// toolErrorKey is the context key of the slot the error block of a failed tool call is recorded in
+// toolErrorKey 记录失败工具调用错误块的槽位的 context 键
+type toolErrorKey struct{}
+
+// addTool registers a tool like mcp.AddTool, but turns an error returned by the handler
+// into a newToolError result. Protocol errors (*jsonrpc.Error) are passed through.
+// addTool 与 mcp.AddTool 一样注册工具，但将处理器返回的错误转换为 newToolError 结果。协议错误（*jsonrpc.Error）原样返回。
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	mcp.AddTool(server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, out, err := handler(ctx, req, input)
+		if err == nil {
+			return result, out, nil
+		}
+		if _, ok := err.(*jsonrpc.Error); ok {
+			return result, out, err
+		}
+
+		result = newToolError(err)
+		if slot, ok := ctx.Value(toolErrorKey{}).(*any); ok {
+			*slot = result.StructuredContent
+		}
+		var zero Out
+		return result, zero, nil
+	})
+}
+
+// toolErrorMiddleware restores the structured content of a failed tool call: the SDK
+// replaces it with the tool's empty output after the handler returns
+// toolErrorMiddleware 恢复失败工具调用的结构化内容：处理器返回后 SDK 会将其替换为工具的空输出
+func (s *Server) toolErrorMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+		var block any
+		result, err := next(context.WithValue(ctx, toolErrorKey{}, &block), method, req)
+		if callResult, ok := result.(*mcp.CallToolResult); ok && err == nil && block != nil && callResult.IsError {
+			callResult.StructuredContent = block
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestToolErrorDetails 测试失败的工具调用在可读信息之后附带错误分类，结构化内容为同一分类块
+func TestToolErrorDetails(t *testing.T) {
+	s := NewServer("test-token", nil)
+	s.clusterManager.AddClientset("test", fake.NewSimpleClientset())
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	tests := []struct {
+		tool    string
+		args    map[string]any
+		message string
+		want    types.ToolErrorDetails
+	}{
+		{
+			tool:    "get_resource",
+			args:    map[string]any{"resource_type": "pods", "name": "missing", "namespace": "default"},
+			message: `"missing" not found`,
+			want:    types.ToolErrorDetails{Reason: "NotFound", Resource: "pods/missing"},
+		},
+		{
+			tool:    "switch_cluster",
+			args:    map[string]any{"cluster_name": "prod"},
+			message: "client for cluster prod not found",
+			want:    types.ToolErrorDetails{Reason: "ClusterNotFound"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: tt.tool, Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if !result.IsError || len(result.Content) != 2 {
+				t.Fatalf("expected an error result with a message and a details block, got %+v", result)
+			}
+			if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, tt.message) {
+				t.Errorf("expected %q in the message, got %q", tt.message, text)
+			}
+
+			var fromText, fromStructured ToolErrorResult
+			if err := json.Unmarshal([]byte(result.Content[1].(*mcp.TextContent).Text), &fromText); err != nil {
+				t.Fatalf("details block is not valid JSON: %v", err)
+			}
+			structured, _ := json.Marshal(result.StructuredContent)
+			if err := json.Unmarshal(structured, &fromStructured); err != nil || fromStructured != fromText {
+				t.Errorf("expected the structured content to match the details block, got %s", structured)
+			}
+			got := fromText.Error
+			if got.Suggestion == "" {
+				t.Errorf("expected a suggestion for %s", got.Reason)
+			}
+			got.Suggestion = ""
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
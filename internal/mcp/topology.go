@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleGetWorkloadTopology handles get_workload_topology tool
+// handleGetWorkloadTopology 处理 get_workload_topology 工具
+func (s *Server) handleGetWorkloadTopology(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace   string `json:"namespace,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.WorkloadTopology,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, false, clusterName)
+	if namespace == "" {
+		return toolError("get_workload_topology maps a single namespace: pass namespace, one of " + allowedNamespacesScope(s.clusterManager.NamespacePolicy())), types.WorkloadTopology{}, nil
+	}
+
+	topology, err := s.resourceOps.GetWorkloadTopology(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, types.WorkloadTopology{}, fmt.Errorf("failed to get workload topology: %w", err)
+	}
+	return nil, *topology, nil
+}
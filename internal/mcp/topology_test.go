@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestGetWorkloadTopology 测试 get_workload_topology 使用会话的默认命名空间，返回关系图和文本形式
+func TestGetWorkloadTopology(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	callTool(t, session, "set_namespace", map[string]any{"namespace": "shop"})
+	result := callTool(t, session, "get_workload_topology", map[string]any{})
+
+	var topology types.WorkloadTopology
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &topology); err != nil {
+		t.Fatalf("failed to decode topology: %v", err)
+	}
+	if topology.Namespace != "shop" || len(topology.Edges) == 0 {
+		t.Fatalf("unexpected topology %+v", topology)
+	}
+	for _, edge := range topology.Edges {
+		if edge.Relation == "selects" && edge.From != "Service/web" {
+			t.Errorf("unexpected edge %+v", edge)
+		}
+	}
+	if !strings.HasPrefix(topology.Text, "Service/web\n  Deployment/web\n    Pod/") {
+		t.Errorf("unexpected text:\n%s", topology.Text)
+	}
+}
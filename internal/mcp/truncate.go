@@ -0,0 +1,389 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonNode is a parsed JSON value that keeps the key order of objects, so a truncated
+// document reads like the original
+// jsonNode 是保留对象键顺序的 JSON 值，使截断后的文档与原文档一致
+type jsonNode struct {
+	// kind is '{' for objects, '[' for arrays and 0 for scalars
+	// kind 对象为 '{'，数组为 '['，标量为 0
+	kind   byte
+	keys   []string
+	values []*jsonNode
+	// scalar is the encoded scalar; str holds the value of a string scalar
+	// scalar 是编码后的标量；str 保存字符串标量的值
+	scalar   []byte
+	str      string
+	isString bool
+}
+
+// jsonTruncation describes what truncateJSON removed
+// jsonTruncation 描述 truncateJSON 删除的内容
+type jsonTruncation struct {
+	// omitted lists the entries dropped from lists, e.g. "2700 of 3000 entries of pods"
+	// omitted 列出从列表中删除的条目，例如 "2700 of 3000 entries of pods"
+	omitted []string
+}
+
+// truncateJSON shrinks a JSON document to at most limit bytes while keeping it valid:
+// it first drops whole entries from the end of the largest lists, then shortens the
+// longest strings (a string holding JSON is truncated the same way, so it stays
+// parseable), then drops trailing keys of the largest objects. Strings are only cut at
+// rune boundaries. The result may still exceed limit if the document's skeleton alone
+// does.
+// truncateJSON 将 JSON 文档缩减到最多 limit 字节并保持其合法：先从最大的列表末尾删除完整条目，
+// 再缩短最长的字符串（内容为 JSON 的字符串按同样方式截断，因此仍可解析），最后删除最大对象末尾的键。
+// 字符串只在字符边界截断。如果文档的骨架本身就超过 limit，结果仍可能超出。
+func truncateJSON(data []byte, limit int) ([]byte, jsonTruncation, error) {
+	return truncateJSONAt(data, limit, "")
+}
+
+// truncateJSONAt is truncateJSON for a document found at path of an outer document
+// truncateJSONAt 是针对外层文档中位于 path 的文档的 truncateJSON
+func truncateJSONAt(data []byte, limit int, path string) ([]byte, jsonTruncation, error) {
+	var info jsonTruncation
+	root, err := parseJSONNode(data)
+	if err != nil {
+		return nil, info, err
+	}
+	excess := root.size() - limit
+	if excess <= 0 {
+		return data, info, nil
+	}
+
+	// Whole entries of the largest lists first, keeping the first entry so a single
+	// oversized entry is shortened rather than dropped
+	// 首先删除最大列表中的完整条目，保留第一个条目，使单个过大的条目被缩短而不是被删除
+	for _, candidate := range collectNodes(root, path, '[') {
+		if excess <= 0 {
+			break
+		}
+		total := len(candidate.node.values)
+		excess -= candidate.node.dropTrailing(excess, 1)
+		if dropped := total - len(candidate.node.values); dropped > 0 {
+			info.omitted = append(info.omitted, fmt.Sprintf("%d of %d entries of %s", dropped, total, displayPath(candidate.path)))
+		}
+	}
+
+	// Then the longest strings
+	// 然后缩短最长的字符串
+	for _, candidate := range collectNodes(root, path, '"') {
+		if excess <= 0 {
+			break
+		}
+		saved, omitted := candidate.node.shortenString(candidate.node.size()-excess, candidate.path)
+		excess -= saved
+		info.omitted = append(info.omitted, omitted...)
+	}
+
+	// Then trailing keys of the largest objects
+	// 最后删除最大对象末尾的键
+	for _, candidate := range collectNodes(root, path, '{') {
+		if excess <= 0 {
+			break
+		}
+		total := len(candidate.node.keys)
+		excess -= candidate.node.dropTrailing(excess, 0)
+		if dropped := total - len(candidate.node.keys); dropped > 0 {
+			info.omitted = append(info.omitted, fmt.Sprintf("%d of %d keys of %s", dropped, total, displayPath(candidate.path)))
+		}
+	}
+
+	var buf bytes.Buffer
+	root.encode(&buf)
+	return buf.Bytes(), info, nil
+}
+
+// truncateText cuts text to at most limit bytes at a line break when one is in the
+// second half of the kept text, otherwise at a rune boundary
+// truncateText 将文本截断到最多 limit 字节，保留部分的后半段有换行时在换行处截断，否则在字符边界截断
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if newline := strings.LastIndexByte(text[:cut], '\n'); newline >= cut/2 && newline > 0 {
+		cut = newline + 1
+	}
+	return text[:cut]
+}
+
+// parseJSONNode parses a JSON document keeping object key order and number literals
+// parseJSONNode 解析 JSON 文档，保留对象键顺序和数字字面量
+func parseJSONNode(data []byte) (*jsonNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := decodeJSONNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return node, nil
+}
+
+func decodeJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		node := &jsonNode{}
+		if s, ok := tok.(string); ok {
+			node.str, node.isString = s, true
+		}
+		node.scalar, err = marshalNoEscape(tok)
+		return node, err
+	}
+
+	node := &jsonNode{kind: byte(delim)}
+	for dec.More() {
+		if node.kind == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key.(string))
+		}
+		value, err := decodeJSONNode(dec)
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, value)
+	}
+	// Closing delimiter
+	// 结束分隔符
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// marshalNoEscape encodes v without escaping <, > and &, which are common in logs and
+// manifests and would grow sixfold
+// marshalNoEscape 编码 v 时不转义 <、> 和 &，它们在日志和清单中很常见，转义后会变为六倍大小
+func marshalNoEscape(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// size returns the length of the compact encoding of n
+// size 返回 n 紧凑编码后的长度
+func (n *jsonNode) size() int {
+	if n.kind == 0 {
+		return len(n.scalar)
+	}
+	size := 2
+	for i := range n.values {
+		if i > 0 {
+			size++
+		}
+		size += n.entrySize(i)
+	}
+	return size
+}
+
+// entrySize returns the encoded length of the i-th entry, including its key for objects
+// entrySize 返回第 i 个条目编码后的长度，对象包括其键
+func (n *jsonNode) entrySize(i int) int {
+	size := n.values[i].size()
+	if n.kind == '{' {
+		key, _ := marshalNoEscape(n.keys[i])
+		size += len(key) + 1
+	}
+	return size
+}
+
+// encode writes the compact encoding of n
+// encode 写入 n 的紧凑编码
+func (n *jsonNode) encode(buf *bytes.Buffer) {
+	if n.kind == 0 {
+		buf.Write(n.scalar)
+		return
+	}
+	buf.WriteByte(n.kind)
+	for i, value := range n.values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if n.kind == '{' {
+			key, _ := marshalNoEscape(n.keys[i])
+			buf.Write(key)
+			buf.WriteByte(':')
+		}
+		value.encode(buf)
+	}
+	if n.kind == '{' {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+}
+
+// dropTrailing removes entries from the end of an array or object until at least want
+// bytes are saved or only keep entries are left, and returns the bytes saved
+// dropTrailing 从数组或对象末尾删除条目，直到至少节省 want 字节或只剩 keep 个条目，返回节省的字节数
+func (n *jsonNode) dropTrailing(want, keep int) int {
+	saved := 0
+	for len(n.values) > keep && saved < want {
+		last := len(n.values) - 1
+		saved += n.entrySize(last)
+		if last > 0 {
+			saved++ // the separating comma
+		}
+		n.values = n.values[:last]
+		if n.kind == '{' {
+			n.keys = n.keys[:last]
+		}
+	}
+	return saved
+}
+
+// shortenString shortens a string scalar found at path so its encoding fits in target
+// bytes, and returns the bytes saved and the entries omitted. A string holding a JSON
+// document is truncated with truncateJSON and keeps its indentation; other strings are
+// cut with truncateText.
+// shortenString 缩短位于 path 的字符串标量使其编码不超过 target 字节，返回节省的字节数和删除的条目。
+// 内容为 JSON 文档的字符串使用 truncateJSON 截断并保留缩进；其他字符串使用 truncateText 截断。
+func (n *jsonNode) shortenString(target int, path string) (int, []string) {
+	before := len(n.scalar)
+	trimmed := strings.TrimSpace(n.str)
+	isJSON := (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed))
+
+	// Escaping makes the encoding longer than the string, so search for the longest raw
+	// length whose encoding fits, starting from the length that keeps the same ratio
+	// 转义使编码比字符串更长，因此从按相同比例估算的长度开始，查找编码后不超过 target 的最大原始长度
+	var (
+		best        []byte
+		bestStr     string
+		bestOmitted []string
+	)
+	lo, hi := 0, len(n.str)
+	guess := (target - 2) * len(n.str) / before
+	for attempt := 0; attempt < 16 && lo <= hi; attempt++ {
+		shortened := truncateText(n.str, guess)
+		var omitted []string
+		if isJSON {
+			shortened, omitted = truncateJSONString(trimmed, guess, path)
+		}
+		encoded, err := marshalNoEscape(shortened)
+		if err != nil {
+			break
+		}
+		if len(encoded) <= target {
+			best, bestStr, bestOmitted = encoded, shortened, omitted
+			lo = guess + 1
+		} else {
+			hi = guess - 1
+		}
+		guess = lo + (hi-lo)/2
+	}
+
+	// Nothing short enough: keep an empty string
+	// 无法缩短到目标大小时保留空字符串
+	if best == nil {
+		best, bestStr, bestOmitted = []byte(`""`), "", nil
+	}
+	n.str, n.scalar = bestStr, best
+	return before - len(best), bestOmitted
+}
+
+// truncateJSONString truncates a JSON document held in the string at path to about limit
+// bytes, re-indenting it when the original was indented
+// truncateJSONString 将位于 path 的字符串中的 JSON 文档截断到约 limit 字节，原文档有缩进时重新缩进
+func truncateJSONString(doc string, limit int, path string) (string, []string) {
+	indented := strings.Contains(doc, "\n")
+	if indented {
+		// Scale the budget by the share of the document that is not indentation
+		// 按文档中非缩进部分的比例缩放预算
+		var buf bytes.Buffer
+		if json.Compact(&buf, []byte(doc)) == nil {
+			limit = limit * buf.Len() / len(doc)
+		}
+	}
+	truncated, info, err := truncateJSONAt([]byte(doc), limit, path)
+	if err != nil {
+		return truncateText(doc, limit), nil
+	}
+	if indented {
+		var buf bytes.Buffer
+		if json.Indent(&buf, truncated, "", "  ") == nil {
+			return buf.String(), info.omitted
+		}
+	}
+	return string(truncated), info.omitted
+}
+
+// nodeCandidate is a node reached by collectNodes with its path in the document
+// nodeCandidate 是 collectNodes 找到的节点及其在文档中的路径
+type nodeCandidate struct {
+	node *jsonNode
+	path string
+	size int
+}
+
+// collectNodes returns the non-empty arrays ('['), objects ('{') or strings ('"') under
+// n, largest first
+// collectNodes 返回 n 之下非空的数组（'['）、对象（'{'）或字符串（'"'），按大小降序排列
+func collectNodes(n *jsonNode, path string, kind byte) []nodeCandidate {
+	var candidates []nodeCandidate
+	var walk func(n *jsonNode, path string)
+	walk = func(n *jsonNode, path string) {
+		switch {
+		case kind == '"' && n.isString && len(n.str) > 0:
+			candidates = append(candidates, nodeCandidate{node: n, path: path, size: n.size()})
+		case n.kind == kind && len(n.values) > 0:
+			candidates = append(candidates, nodeCandidate{node: n, path: path, size: n.size()})
+		}
+		for i, value := range n.values {
+			if n.kind == '{' {
+				walk(value, joinPath(path, n.keys[i]))
+			} else {
+				walk(value, path+"["+strconv.Itoa(i)+"]")
+			}
+		}
+	}
+	walk(n, path)
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].size > candidates[j].size })
+	return candidates
+}
+
+// joinPath appends key to a dotted path
+// joinPath 将 key 追加到点分路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath names a path in a truncation note
+// displayPath 在截断说明中显示路径名称
+func displayPath(path string) string {
+	if path == "" {
+		return "the result"
+	}
+	return path
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTruncateJSONList 测试列表只保留完整条目、保持 JSON 合法并报告省略的条目数
+func TestTruncateJSONList(t *testing.T) {
+	var items []string
+	for i := 0; i < 100; i++ {
+		items = append(items, fmt.Sprintf(`{"name":"pod-%03d","phase":"Running"}`, i))
+	}
+	data := []byte("[" + strings.Join(items, ",") + "]")
+
+	out, info, err := truncateJSON(data, 1000)
+	if err != nil {
+		t.Fatalf("truncateJSON failed: %v", err)
+	}
+	if len(out) > 1000 {
+		t.Errorf("expected at most 1000 bytes, got %d", len(out))
+	}
+	var pods []map[string]string
+	if err := json.Unmarshal(out, &pods); err != nil {
+		t.Fatalf("truncated list is not valid JSON: %v\n%s", err, out)
+	}
+	for i, pod := range pods {
+		if pod["name"] != fmt.Sprintf("pod-%03d", i) || pod["phase"] != "Running" {
+			t.Errorf("entry %d was modified: %v", i, pod)
+		}
+	}
+	want := fmt.Sprintf("%d of 100 entries of the result", 100-len(pods))
+	if len(info.omitted) != 1 || info.omitted[0] != want {
+		t.Errorf("expected omitted %q, got %v", want, info.omitted)
+	}
+}
+
+// TestTruncateJSONNestedList 测试字符串中的 JSON 列表（例如 list_pods 的 pods 字段）按条目截断并保持可解析
+func TestTruncateJSONNestedList(t *testing.T) {
+	var items []string
+	for i := 0; i < 200; i++ {
+		items = append(items, fmt.Sprintf(`{"name":"pod-%03d"}`, i))
+	}
+	outer, _ := json.Marshal(map[string]string{"pods": "[" + strings.Join(items, ",") + "]", "scope": "namespace default"})
+
+	out, info, err := truncateJSON(outer, 1024)
+	if err != nil {
+		t.Fatalf("truncateJSON failed: %v", err)
+	}
+	if len(out) > 1024 {
+		t.Errorf("expected at most 1024 bytes, got %d", len(out))
+	}
+	var result struct {
+		Pods  string `json:"pods"`
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("truncated result is not valid JSON: %v", err)
+	}
+	var pods []map[string]string
+	if err := json.Unmarshal([]byte(result.Pods), &pods); err != nil {
+		t.Fatalf("truncated pods are not valid JSON: %v\n%s", err, result.Pods)
+	}
+	if len(pods) == 0 || result.Scope != "namespace default" {
+		t.Errorf("unexpected truncated result: %s", out)
+	}
+	want := fmt.Sprintf("%d of 200 entries of pods", 200-len(pods))
+	if len(info.omitted) != 1 || info.omitted[0] != want {
+		t.Errorf("expected omitted %q, got %v", want, info.omitted)
+	}
+}
+
+// TestTruncateJSONStrings 测试过长的字符串在字符边界截断，不拆分多字节字符，且保留对象的键顺序
+func TestTruncateJSONStrings(t *testing.T) {
+	bundle := strings.Repeat("配置数据😀", 500)
+	data, _ := marshalNoEscape(map[string]string{"data": bundle})
+	data = []byte(`{"name":"bundle",` + string(data[1:]))
+
+	for _, limit := range []int{200, 201, 202, 203, 1000} {
+		out, _, err := truncateJSON(data, limit)
+		if err != nil {
+			t.Fatalf("truncateJSON failed: %v", err)
+		}
+		if len(out) > limit {
+			t.Errorf("limit %d: got %d bytes", limit, len(out))
+		}
+		if !strings.HasPrefix(string(out), `{"name":"bundle","data":"配置`) {
+			t.Errorf("limit %d: expected the key order and the start of the data to be kept: %s", limit, out)
+		}
+		var result map[string]string
+		if err := json.Unmarshal(out, &result); err != nil {
+			t.Fatalf("limit %d: not valid JSON: %v", limit, err)
+		}
+		if !utf8.ValidString(result["data"]) || !strings.HasPrefix(bundle, result["data"]) {
+			t.Errorf("limit %d: data is not a prefix made of whole runes: %q", limit, result["data"])
+		}
+	}
+}
+
+// TestTruncateJSONIndentedString 测试字符串中带缩进的 JSON 文档截断后仍可解析并保留缩进
+func TestTruncateJSONIndentedString(t *testing.T) {
+	var containers []map[string]string
+	for i := 0; i < 50; i++ {
+		containers = append(containers, map[string]string{"name": fmt.Sprintf("c%d", i), "image": "nginx"})
+	}
+	doc, _ := json.MarshalIndent(map[string]interface{}{"kind": "Pod", "containers": containers}, "", "  ")
+	outer, _ := json.Marshal(map[string]string{"resource": string(doc)})
+
+	out, info, err := truncateJSON(outer, 1024)
+	if err != nil {
+		t.Fatalf("truncateJSON failed: %v", err)
+	}
+	var result map[string]string
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("not valid JSON: %v", err)
+	}
+	if !json.Valid([]byte(result["resource"])) || !strings.Contains(result["resource"], "\n  \"kind\": \"Pod\"") {
+		t.Errorf("expected a valid indented document, got %s", result["resource"])
+	}
+	if len(info.omitted) != 1 || !strings.HasSuffix(info.omitted[0], "of 50 entries of resource.containers") {
+		t.Errorf("unexpected omitted entries: %v", info.omitted)
+	}
+}
+
+// TestTruncateJSONFits 测试未超过限制的文档原样返回
+func TestTruncateJSONFits(t *testing.T) {
+	data := []byte(`{"b":1,"a":"<x>"}`)
+	out, info, err := truncateJSON(data, 100)
+	if err != nil || string(out) != string(data) || len(info.omitted) != 0 {
+		t.Errorf("expected the document unchanged, got %s %v %v", out, info.omitted, err)
+	}
+}
+
+// TestTruncateText 测试纯文本在换行或字符边界截断
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"line one\nline two\nline three", 22, "line one\nline two\n"},
+		{"line one\nline two", 12, "line one\n"},
+		{"no line breaks here", 8, "no line "},
+		{"日志日志", 7, "日志"},
+		{"日志日志", 5, "日"},
+		{"😀", 3, ""},
+	}
+
+	for _, tt := range tests {
+		got := truncateText(tt.text, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateText(%q, %d) split a rune: %q", tt.text, tt.limit, got)
+		}
+	}
+}
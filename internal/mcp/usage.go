@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleGetResourceUsage handles get_resource_usage tool
+// handleGetResourceUsage 处理 get_resource_usage 工具
+func (s *Server) handleGetResourceUsage(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}) (
+	*mcp.CallToolResult,
+	types.ResourceUsage,
+	error,
+) {
+	clusterName := s.resolveClusterName(ctx, input.ClusterName)
+	namespace, _ := s.resolveNamespace(ctx, input.Namespace, input.AllNamespaces, clusterName)
+
+	usage, err := s.resourceOps.GetResourceUsage(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, types.ResourceUsage{}, fmt.Errorf("failed to get resource usage: %w", err)
+	}
+	return nil, *usage, nil
+}
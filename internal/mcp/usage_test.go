@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// TestGetResourceUsage 测试 get_resource_usage 在所有命名空间范围时与节点可分配资源对比，并返回文本表格
+func TestGetResourceUsage(t *testing.T) {
+	s := NewServer("test-token", nil)
+	if err := s.LoadMockCluster(""); err != nil {
+		t.Fatalf("LoadMockCluster failed: %v", err)
+	}
+	s.RegisterTools()
+	session := connectTestClient(t, s, nil)
+
+	result := callTool(t, session, "get_resource_usage", map[string]any{"all_namespaces": true})
+	var usage types.ResourceUsage
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &usage); err != nil {
+		t.Fatalf("failed to decode usage: %v", err)
+	}
+	if usage.Scope != "all namespaces" || usage.Pods == 0 || len(usage.Resources) != 2 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if cpu := usage.Resources[0]; cpu.Resource != "cpu" || cpu.Allocatable != "12" || cpu.RequestsPercent == nil {
+		t.Errorf("expected cpu compared against the nodes, got %+v", cpu)
+	}
+	if !strings.HasPrefix(usage.Text, "Resource usage in all namespaces") {
+		t.Errorf("unexpected text:\n%s", usage.Text)
+	}
+
+	result = callTool(t, session, "get_resource_usage", map[string]any{"namespace": "shop"})
+	data, _ = json.Marshal(result.StructuredContent)
+	usage = types.ResourceUsage{}
+	json.Unmarshal(data, &usage)
+	if usage.Scope != "namespace shop" || usage.Resources[0].Allocatable != "" {
+		t.Errorf("unexpected namespace usage %+v", usage)
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/wsconn"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketPath is where the WebSocket transport is served, next to the streamable
+// HTTP transport on the same listener
+// WebSocketPath 为 WebSocket 传输的路径，与可流式 HTTP 传输共用同一监听地址
+const WebSocketPath = "/ws"
+
+// webSocketHandler serves one MCP session per WebSocket connection, with JSON-RPC
+// messages as text frames. Authentication happens before the upgrade, in AuthMiddleware.
+// webSocketHandler 为每个 WebSocket 连接提供一个 MCP 会话，JSON-RPC 消息以文本帧传输。认证在升级之前由 AuthMiddleware 完成
+func (s *Server) webSocketHandler() http.Handler {
+	return websocket.Server{
+		Handshake: checkWebSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			// The hijacked connection may keep the deadlines of the HTTP server
+			// 被接管的连接可能保留 HTTP 服务器设置的超时
+			ws.SetDeadline(time.Time{})
+
+			req := ws.Request()
+			conn := wsconn.NewConn(ws, nil, req.Header, wsconn.DefaultKeepAlive)
+			session, err := s.mcpServer.Connect(req.Context(), &wsconn.Transport{Conn: conn}, nil)
+			if err != nil {
+				s.logger.Warn("Failed to start WebSocket session", "remote", req.RemoteAddr, "error", err)
+				conn.Close()
+				return
+			}
+			s.logger.Debug("WebSocket session started", "session", session.ID(), "remote", req.RemoteAddr)
+			session.Wait()
+			conn.Close()
+			s.logger.Debug("WebSocket session ended", "session", session.ID())
+		},
+	}
+}
+
+// checkWebSocketOrigin accepts requests without an Origin header and requests whose
+// Origin is the server itself, as the Host or, behind a proxy, the X-Forwarded-Host
+// header names it. Cross-origin browser pages are rejected so they can't reuse the
+// browser's client certificate.
+// checkWebSocketOrigin 接受没有 Origin 头的请求，以及 Origin 为服务器自身 (由 Host 头或代理之后的
+// X-Forwarded-Host 头给出) 的请求。拒绝跨源的浏览器页面，使其无法借用浏览器的客户端证书
+func checkWebSocketOrigin(_ *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err == nil && u.Host != "" && (u.Host == r.Host || u.Host == r.Header.Get("X-Forwarded-Host")) {
+		return nil
+	}
+	return fmt.Errorf("cross-origin WebSocket request from %s", origin)
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/mcpclient"
+
+	"golang.org/x/net/websocket"
+)
+
+// newWebSocketTestServer 启动提供完整 HTTP 处理器的测试服务器，返回其地址
+func newWebSocketTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	s := newTestServer(t, "mock")
+	s.RegisterTools()
+	httpServer := httptest.NewServer(s.CreateHTTPHandler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		s.Close()
+	})
+	return s, httpServer.URL
+}
+
+// TestWebSocketTransport 测试通过真实的 WebSocket 完成 initialize、tools/list 和 tools/call，请求携带握手时的身份
+func TestWebSocketTransport(t *testing.T) {
+	s, serverURL := newWebSocketTestServer(t)
+	ctx := context.Background()
+
+	// Connect 完成 initialize 握手
+	client, err := mcpclient.NewClient(mcpclient.Config{
+		ServerURL: serverURL,
+		AuthToken: "test-token",
+		Transport: mcpclient.TransportWebSocket,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	found := false
+	for _, tool := range tools {
+		found = found || tool.Name == "list_clusters"
+	}
+	if !found {
+		t.Fatalf("expected list_clusters in %d tools", len(tools))
+	}
+
+	result, err := client.CallTool(ctx, "list_clusters", nil)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError || !strings.Contains(resultText(result), "mock") {
+		t.Errorf("unexpected list_clusters result %s", resultText(result))
+	}
+
+	// 服务器从握手请求头得到调用者身份
+	if records := s.history.snapshot(); len(records) == 0 || !strings.HasPrefix(records[0].Caller, "token:") {
+		t.Errorf("expected the call to be recorded with the token identity, got %+v", records)
+	}
+
+	// 关闭握手由服务器立即应答，不必等待超时
+	start := time.Now()
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the close handshake to finish at once, took %v", elapsed)
+	}
+}
+
+// TestWebSocketTransportRejectsUnauthenticated 测试没有有效 token 或来自其他源的升级请求被拒绝
+func TestWebSocketTransportRejectsUnauthenticated(t *testing.T) {
+	_, serverURL := newWebSocketTestServer(t)
+	wsURL := "ws" + strings.TrimPrefix(serverURL, "http") + WebSocketPath
+
+	dial := func(token, origin string) error {
+		config, err := websocket.NewConfig(wsURL, origin)
+		if err != nil {
+			t.Fatalf("NewConfig failed: %v", err)
+		}
+		if token != "" {
+			config.Header.Set("Authorization", "Bearer "+token)
+		}
+		ws, err := websocket.DialConfig(config)
+		if err == nil {
+			ws.Close()
+		}
+		return err
+	}
+
+	if err := dial("test-token", serverURL); err != nil {
+		t.Fatalf("expected a same-origin upgrade to succeed, got %v", err)
+	}
+	if err := dial("", serverURL); err == nil {
+		t.Error("expected an upgrade without a token to fail")
+	}
+	if err := dial("wrong", serverURL); err == nil {
+		t.Error("expected an upgrade with an invalid token to fail")
+	}
+	if err := dial("test-token", "https://evil.example.com"); err == nil {
+		t.Error("expected a cross-origin upgrade to fail")
+	}
+
+	// 普通的 HTTP 请求得到 401，而不是 WebSocket 错误
+	resp, err := http.Get(serverURL + WebSocketPath)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
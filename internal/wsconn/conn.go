@@ -0,0 +1,245 @@
+// Package wsconn carries MCP JSON-RPC messages over a WebSocket, one message per text
+// frame, for clients whose proxies don't pass the streamable HTTP transport through.
+// Package wsconn 通过 WebSocket 传输 MCP JSON-RPC 消息，每个文本帧一条消息，
+// 供代理无法透传可流式 HTTP 传输的客户端使用
+package wsconn
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/net/websocket"
+)
+
+// DefaultKeepAlive is the interval between the pings that keep idle connections open
+// through proxies
+// DefaultKeepAlive 为保持空闲连接不被代理关闭而发送 ping 的间隔
+const DefaultKeepAlive = 30 * time.Second
+
+// closeTimeout is how long Close waits for the peer to answer the close frame
+// closeTimeout 为 Close 等待对端回复关闭帧的时间
+const closeTimeout = 5 * time.Second
+
+// closeStatusNormal is the status code of a normal closure
+// closeStatusNormal 为正常关闭的状态码
+const closeStatusNormal = 1000
+
+// errClosed is returned by Write once the connection is closing
+// errClosed 为连接关闭后 Write 返回的错误
+var errClosed = errors.New("websocket connection closed")
+
+// readResult is one message, or the error that ended the read loop
+// readResult 为一条消息，或结束读取循环的错误
+type readResult struct {
+	data string
+	err  error
+}
+
+// Conn is an mcp.Connection over a WebSocket. Requests read by a server connection carry
+// the header of the handshake request in their RequestExtra, so identities and audit
+// work as they do for the streamable HTTP transport.
+// Conn 是基于 WebSocket 的 mcp.Connection。服务器端连接读取的请求在 RequestExtra 中携带握手请求的请求头，
+// 使身份和审计与可流式 HTTP 传输一致
+type Conn struct {
+	ws        *websocket.Conn
+	raw       io.Closer
+	header    http.Header
+	sessionID string
+
+	controlMu sync.Mutex // serializes control frames, which go through ws.PayloadType / 串行发送控制帧
+	incoming  chan readResult
+	closing   chan struct{} // closed when Close starts / Close 开始时关闭
+	readDone  chan struct{} // closed when the read loop ends / 读取循环结束时关闭
+	done      chan struct{} // closed when Close is finished / Close 完成时关闭
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewConn wraps ws and starts reading from it, pinging the peer every keepAlive (never
+// when keepAlive is zero). raw is the network connection below ws, closed at the end of
+// Close; a server passes nil and closes it by returning from its websocket.Handler
+// after Done. header, when not nil, is attached to every request read.
+// NewConn 包装 ws 并开始读取，每隔 keepAlive 向对端发送 ping (keepAlive 为 0 时不发送)。
+// raw 为 ws 之下的网络连接，在 Close 最后关闭；服务器传入 nil，在 Done 之后从 websocket.Handler 返回以关闭连接。
+// header 不为 nil 时附加到读取的每个请求上
+func NewConn(ws *websocket.Conn, raw io.Closer, header http.Header, keepAlive time.Duration) *Conn {
+	c := &Conn{
+		ws:        ws,
+		raw:       raw,
+		header:    header,
+		sessionID: newSessionID(),
+		incoming:  make(chan readResult),
+		closing:   make(chan struct{}),
+		readDone:  make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop()
+	if keepAlive > 0 {
+		go c.keepAlive(keepAlive)
+	}
+	return c
+}
+
+// newSessionID returns a random session ID
+// newSessionID 返回随机的会话 ID
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("ws-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// readLoop receives messages until the peer closes the connection or it fails. Once
+// Close has started, messages are dropped while waiting for the peer's close frame.
+// readLoop 接收消息，直到对端关闭连接或连接出错。Close 开始后，在等待对端关闭帧期间丢弃收到的消息
+func (c *Conn) readLoop() {
+	defer close(c.readDone)
+	for {
+		var data string
+		err := websocket.Message.Receive(c.ws, &data)
+		select {
+		case c.incoming <- readResult{data: data, err: err}:
+		case <-c.closing:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// keepAlive pings the peer every interval. A failed ping means the connection is gone,
+// so it is closed.
+// keepAlive 每隔 interval 向对端发送 ping。ping 失败说明连接已断开，因此关闭连接
+func (c *Conn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closing:
+			return
+		case <-ticker.C:
+			if err := c.writeControl(websocket.PingFrame, nil); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeControl writes a control frame. Messages are sent with websocket.Message, which
+// doesn't use ws.PayloadType, so only control frames need the lock.
+// writeControl 写入控制帧。消息通过 websocket.Message 发送，不使用 ws.PayloadType，因此只有控制帧需要加锁
+func (c *Conn) writeControl(payloadType byte, payload []byte) error {
+	c.controlMu.Lock()
+	defer c.controlMu.Unlock()
+	c.ws.PayloadType = payloadType
+	_, err := c.ws.Write(payload)
+	return err
+}
+
+// Read implements mcp.Connection
+// Read 实现 mcp.Connection
+func (c *Conn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	var result readResult
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closing:
+		return nil, io.EOF
+	case <-c.readDone:
+		return nil, io.EOF
+	case result = <-c.incoming:
+	}
+	if result.err != nil {
+		if errors.Is(result.err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read websocket message: %w", result.err)
+	}
+
+	msg, err := jsonrpc.DecodeMessage([]byte(result.data))
+	if err != nil {
+		return nil, err
+	}
+	if req, ok := msg.(*jsonrpc.Request); ok && c.header != nil {
+		req.Extra = &mcp.RequestExtra{Header: c.header}
+	}
+	return msg, nil
+}
+
+// Write implements mcp.Connection
+// Write 实现 mcp.Connection
+func (c *Conn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.closing:
+		return errClosed
+	default:
+	}
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := websocket.Message.Send(c.ws, string(data)); err != nil {
+		return fmt.Errorf("failed to write websocket message: %w", err)
+	}
+	return nil
+}
+
+// Close sends a close frame and waits up to closeTimeout for the peer's answer, which
+// ends the read loop, before closing the network connection. When the peer closed
+// first, its close frame has already ended the read loop and the answer is sent at once.
+// Close 发送关闭帧，最多等待 closeTimeout 让对端回复 (回复会结束读取循环)，然后关闭网络连接。
+// 对端先关闭时，其关闭帧已结束读取循环，回复立即发出
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		status := make([]byte, 2)
+		binary.BigEndian.PutUint16(status, closeStatusNormal)
+		if err := c.writeControl(websocket.CloseFrame, status); err == nil {
+			select {
+			case <-c.readDone:
+			case <-time.After(closeTimeout):
+			}
+		}
+		if c.raw != nil {
+			c.closeErr = c.raw.Close()
+		}
+		close(c.done)
+	})
+	return c.closeErr
+}
+
+// Done returns a channel closed when Close has finished
+// Done 返回 Close 完成后关闭的通道
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// SessionID implements mcp.Connection
+// SessionID 实现 mcp.Connection
+func (c *Conn) SessionID() string {
+	return c.sessionID
+}
+
+// Transport is an mcp.Transport handing out an established connection
+// Transport 是返回已建立连接的 mcp.Transport
+type Transport struct {
+	Conn *Conn
+}
+
+// Connect implements mcp.Transport
+// Connect 实现 mcp.Transport
+func (t *Transport) Connect(context.Context) (mcp.Connection, error) {
+	return t.Conn, nil
+}
@@ -0,0 +1,164 @@
+package wsconn
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/net/websocket"
+)
+
+// newTestServer 启动一个 WebSocket 服务器，每个连接由 NewConn 包装后交给 serve，serve 返回后关闭连接
+func newTestServer(t *testing.T, keepAlive time.Duration, serve func(*Conn)) string {
+	t.Helper()
+	server := httptest.NewServer(websocket.Server{Handler: func(ws *websocket.Conn) {
+		conn := NewConn(ws, nil, ws.Request().Header, keepAlive)
+		serve(conn)
+		conn.Close()
+		<-conn.Done()
+	}})
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// rawHandshake 通过原始 TCP 连接完成客户端握手，返回连接和读取服务器帧的 reader
+func rawHandshake(t *testing.T, serverURL string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	request := "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatalf("write handshake failed: %v", err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake failed: %v %v", resp, err)
+	}
+	return conn, reader
+}
+
+// readFrame 读取一个服务器帧 (不带掩码、载荷不超过 125 字节)，返回操作码和载荷
+func readFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		t.Fatalf("read frame failed: %v", err)
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("read payload failed: %v", err)
+	}
+	return header[0] & 0x0f, payload
+}
+
+// TestKeepAliveAndPeerClose 测试空闲连接定期收到 ping，对端发起关闭时得到状态码为 1000 的关闭帧应答
+func TestKeepAliveAndPeerClose(t *testing.T) {
+	readErr := make(chan error, 1)
+	serverURL := newTestServer(t, 20*time.Millisecond, func(conn *Conn) {
+		_, err := conn.Read(context.Background())
+		readErr <- err
+	})
+	conn, reader := rawHandshake(t, serverURL)
+
+	for i := 0; i < 2; i++ {
+		if opcode, payload := readFrame(t, reader); opcode != websocket.PingFrame || len(payload) != 0 {
+			t.Fatalf("expected a ping, got opcode %d", opcode)
+		}
+	}
+
+	// 客户端发送带掩码的关闭帧 (掩码为 0，载荷即状态码 1000)
+	if _, err := conn.Write([]byte{0x88, 0x82, 0, 0, 0, 0, 0x03, 0xe8}); err != nil {
+		t.Fatalf("write close failed: %v", err)
+	}
+	if err := <-readErr; err != io.EOF {
+		t.Errorf("expected Read to report io.EOF, got %v", err)
+	}
+	for {
+		opcode, payload := readFrame(t, reader)
+		if opcode == websocket.PingFrame {
+			continue
+		}
+		if opcode != websocket.CloseFrame || string(payload) != "\x03\xe8" {
+			t.Fatalf("expected a close frame with status 1000, got opcode %d payload %x", opcode, payload)
+		}
+		break
+	}
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Errorf("expected the server to close the connection, got %v", err)
+	}
+}
+
+// TestConnRoundTrip 测试消息以文本帧往返，服务器读取的请求携带握手请求头，Close 完成关闭握手
+func TestConnRoundTrip(t *testing.T) {
+	serverClosed := make(chan error, 1)
+	serverURL := newTestServer(t, 0, func(conn *Conn) {
+		msg, err := conn.Read(context.Background())
+		if err != nil {
+			serverClosed <- err
+			return
+		}
+		req := msg.(*jsonrpc.Request)
+		extra, _ := req.Extra.(*mcp.RequestExtra)
+		if extra == nil || extra.Header.Get("Authorization") != "Bearer abc" {
+			t.Errorf("expected the handshake header on the request, got %+v", req.Extra)
+		}
+		conn.Write(context.Background(), &jsonrpc.Response{ID: req.ID, Result: []byte(`{"method":"` + req.Method + `"}`)})
+		_, err = conn.Read(context.Background())
+		serverClosed <- err
+	})
+
+	config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(serverURL, "http"), serverURL)
+	config.Header.Set("Authorization", "Bearer abc")
+	raw, err := net.Dial("tcp", config.Location.Host)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	ws, err := websocket.NewClient(config, raw)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	client := NewConn(ws, raw, nil, time.Hour)
+
+	id, _ := jsonrpc.MakeID(float64(1))
+	if err := client.Write(context.Background(), &jsonrpc.Request{ID: id, Method: "ping"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	msg, err := client.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if resp, ok := msg.(*jsonrpc.Response); !ok || string(resp.Result) != `{"method":"ping"}` {
+		t.Errorf("unexpected response %+v", msg)
+	}
+
+	start := time.Now()
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > closeTimeout/2 {
+		t.Errorf("expected the server to answer the close frame at once, took %v", elapsed)
+	}
+	if err := <-serverClosed; err != io.EOF {
+		t.Errorf("expected the server to see io.EOF, got %v", err)
+	}
+	if _, err := client.Read(context.Background()); err != io.EOF {
+		t.Errorf("expected Read after Close to report io.EOF, got %v", err)
+	}
+	if err := client.Write(context.Background(), &jsonrpc.Request{Method: "late"}); err == nil {
+		t.Error("expected Write after Close to fail")
+	}
+}
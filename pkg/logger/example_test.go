@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
@@ -297,3 +298,31 @@ func TestFileOutput(t *testing.T) {
 		t.Error("Log file should exist")
 	}
 }
+
+// recordingSink 记录收到的日志条目
+type recordingSink struct {
+	entries [][]interface{}
+}
+
+func (s *recordingSink) Write(level, msg string, keysAndValues []interface{}) {
+	s.entries = append(s.entries, append([]interface{}{level, msg}, keysAndValues...))
+}
+
+// TestNewTee 测试 tee logger 将日志和 With 添加的字段传给 sink
+func TestNewTee(t *testing.T) {
+	sink := &recordingSink{}
+	log := NewTee(NewDefaultConsoleLogger(), sink)
+
+	log.Info("plain", "a", 1)
+	log.With("component", "test").Warn("with fields", "b", 2)
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", sink.entries)
+	}
+	if got := fmt.Sprint(sink.entries[0]); got != "[info plain a 1]" {
+		t.Errorf("unexpected first entry %s", got)
+	}
+	if got := fmt.Sprint(sink.entries[1]); got != "[warn with fields component test b 2]" {
+		t.Errorf("unexpected second entry %s", got)
+	}
+}
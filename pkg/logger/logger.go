@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"go.uber.org/zap"
@@ -149,16 +150,9 @@ func buildZapLogger(cfg *Config) (*zap.Logger, error) {
 			))
 		} else {
 			// 文件输出，支持日志轮转
-			writer := &lumberjack.Logger{
-				Filename:   path,
-				MaxSize:    cfg.RotationConfig.MaxSize,
-				MaxBackups: cfg.RotationConfig.MaxBackups,
-				MaxAge:     cfg.RotationConfig.MaxAge,
-				Compress:   cfg.RotationConfig.Compress,
-			}
 			cores = append(cores, zapcore.NewCore(
 				encoder,
-				zapcore.AddSync(writer),
+				zapcore.AddSync(NewRotatingWriter(path, cfg.RotationConfig)),
 				level,
 			))
 		}
@@ -187,6 +181,21 @@ func buildZapLogger(cfg *Config) (*zap.Logger, error) {
 	return zap.New(core, opts...), nil
 }
 
+// NewRotatingWriter 创建按轮转配置写入指定文件的 writer
+// 供审计日志等需要独立文件的场景复用日志轮转配置，rc 为 nil 时使用默认轮转配置
+func NewRotatingWriter(filename string, rc *RotationConfig) io.WriteCloser {
+	if rc == nil {
+		rc = NewDefaultConfig().RotationConfig
+	}
+	return &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    rc.MaxSize,
+		MaxBackups: rc.MaxBackups,
+		MaxAge:     rc.MaxAge,
+		Compress:   rc.Compress,
+	}
+}
+
 // Sync 同步所有缓冲的日志条目
 // 应该在程序退出前调用
 func Sync() error {
@@ -0,0 +1,70 @@
+package logger
+
+// 日志级别名称，传给 Sink
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Sink 接收日志条目的额外输出，例如转发给 MCP 客户端
+// Write 可能在任意 goroutine 中被调用，包括正在持有传输层锁的调用方，因此实现不应阻塞，
+// 也不应再通过同一个 Logger 记录日志
+type Sink interface {
+	Write(level, msg string, keysAndValues []interface{})
+}
+
+// teeLogger 将日志同时写入 base 和 sink
+type teeLogger struct {
+	base   Logger
+	sink   Sink
+	fields []interface{}
+}
+
+// NewTee 返回同时写入 base 和 sink 的 Logger，With 添加的字段会一并传给 sink
+func NewTee(base Logger, sink Sink) Logger {
+	return &teeLogger{base: base, sink: sink}
+}
+
+// Debug 记录调试级别日志
+func (l *teeLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.base.Debug(msg, keysAndValues...)
+	l.write(LevelDebug, msg, keysAndValues)
+}
+
+// Info 记录信息级别日志
+func (l *teeLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.base.Info(msg, keysAndValues...)
+	l.write(LevelInfo, msg, keysAndValues)
+}
+
+// Warn 记录警告级别日志
+func (l *teeLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.base.Warn(msg, keysAndValues...)
+	l.write(LevelWarn, msg, keysAndValues)
+}
+
+// Error 记录错误级别日志
+func (l *teeLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.base.Error(msg, keysAndValues...)
+	l.write(LevelError, msg, keysAndValues)
+}
+
+// With 创建带有额外字段的子 logger
+func (l *teeLogger) With(keysAndValues ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	fields = append(append(fields, l.fields...), keysAndValues...)
+	return &teeLogger{base: l.base.With(keysAndValues...), sink: l.sink, fields: fields}
+}
+
+// write 将日志条目连同 With 添加的字段传给 sink
+func (l *teeLogger) write(level, msg string, keysAndValues []interface{}) {
+	if len(l.fields) == 0 {
+		l.sink.Write(level, msg, keysAndValues)
+		return
+	}
+	all := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	all = append(append(all, l.fields...), keysAndValues...)
+	l.sink.Write(level, msg, all)
+}
@@ -3,6 +3,11 @@ package mcpclient
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -13,7 +18,15 @@ type Client struct {
 	config        Config
 	customHeaders map[string]string
 	mcpClient     *mcp.Client
-	session       *mcp.ClientSession
+	logger        logger.Logger
+	reconnect     *reconnectConfig
+	callTimeout   time.Duration
+	retry         *RetryPolicy
+	onToolsChange func()
+
+	mu          sync.RWMutex // 保护 session / guards session
+	session     *mcp.ClientSession
+	reconnectMu sync.Mutex // 保证同一时间只有一次重连 / serializes reconnects
 }
 
 // NewClient 创建客户端实例，支持通过 Option 自定义配置
@@ -28,6 +41,7 @@ func NewClient(config Config, opts ...Option) (*Client, error) {
 	client := &Client{
 		config:        config,
 		customHeaders: make(map[string]string),
+		logger:        nopLogger{},
 	}
 
 	// 应用可选配置
@@ -42,23 +56,29 @@ func NewClient(config Config, opts ...Option) (*Client, error) {
 // Connect 建立连接
 // Connect establishes a connection to the MCP server
 func (c *Client) Connect(ctx context.Context) error {
-	// 创建 HTTP 客户端和传输层
-	// Create HTTP client and transport
-	httpClient := createHTTPClient(c.config, c.customHeaders)
+	// 创建传输层
+	// Create transport
+	transport, err := c.createTransport()
+	if err != nil {
+		return err
+	}
 
 	// 创建 MCP 客户端
 	// Create MCP client
+	var clientOpts *mcp.ClientOptions
+	if c.onToolsChange != nil {
+		clientOpts = &mcp.ClientOptions{
+			// 在独立的 goroutine 中回调，允许处理函数再次调用 ListTools
+			// Call back on a separate goroutine so the handler may call ListTools
+			ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+				go c.onToolsChange()
+			},
+		}
+	}
 	c.mcpClient = mcp.NewClient(&mcp.Implementation{
 		Name:    c.config.UserAgent,
-		Version: "1.0.0",
-	}, nil)
-
-	// 创建可流式传输
-	// Create streamable transport
-	transport := &mcp.StreamableClientTransport{
-		Endpoint:   c.config.ServerURL,
-		HTTPClient: httpClient,
-	}
+		Version: version.Version,
+	}, clientOpts)
 
 	// 连接到服务器
 	// Connect to server
@@ -67,15 +87,45 @@ func (c *Client) Connect(ctx context.Context) error {
 		return fmt.Errorf("connection failed: %w", err)
 	}
 
+	c.mu.Lock()
 	c.session = session
+	c.mu.Unlock()
 	return nil
 }
 
+// createTransport 根据 Config.Transport 创建可流式 HTTP 或 WebSocket 传输
+// createTransport creates the streamable HTTP or the WebSocket transport, as Config.Transport selects
+func (c *Client) createTransport() (mcp.Transport, error) {
+	switch c.config.Transport {
+	case "", TransportStreamable:
+		httpClient, err := createHTTPClient(c.config, c.customHeaders)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.StreamableClientTransport{
+			Endpoint:   c.config.ServerURL,
+			HTTPClient: httpClient,
+		}, nil
+	case TransportWebSocket:
+		return createWebSocketTransport(c.config, c.customHeaders)
+	default:
+		return nil, fmt.Errorf("unknown transport %q: use %q or %q", c.config.Transport, TransportStreamable, TransportWebSocket)
+	}
+}
+
 // Close 关闭连接
 // Close closes the connection to the MCP server
 func (c *Client) Close() error {
-	if c.session != nil {
-		return c.session.Close()
+	if session := c.currentSession(); session != nil {
+		return session.Close()
 	}
 	return nil
 }
+
+// currentSession 返回当前会话，未连接时返回 nil
+// currentSession returns the current session, or nil when not connected
+func (c *Client) currentSession() *mcp.ClientSession {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.session
+}
@@ -3,6 +3,8 @@ package mcpclient
 import (
 	"os"
 	"strings"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/version"
 )
 
 // Config 定义客户端配置
@@ -12,6 +14,11 @@ type Config struct {
 	AuthToken          string // 认证 Token
 	InsecureSkipVerify bool   // 是否跳过 TLS 证书验证
 	UserAgent          string // 可选：标识客户端身份
+	ClientCertPath     string // 可选：mTLS 客户端证书（PEM）
+	ClientKeyPath      string // 可选：mTLS 客户端私钥（PEM）
+	CAPath             string // 可选：验证服务器证书的 CA（PEM），为空时使用系统 CA
+	CompressRequests   bool   // 可选：以 gzip 压缩超过 1KB 的请求体
+	Transport          string // 可选：传输方式，"streamable"（默认）或 "websocket"
 }
 
 // LoadConfig 从环境变量加载配置
@@ -21,7 +28,12 @@ func LoadConfig() (Config, error) {
 		ServerURL:          getEnvWithDefault("MCP_CLIENT_SERVER", "https://localhost:8443"),
 		AuthToken:          os.Getenv("MCP_CLIENT_TOKEN"),
 		InsecureSkipVerify: strings.ToLower(getEnvWithDefault("MCP_CLIENT_INSECURE_SKIP_VERIFY", "false")) == "true",
-		UserAgent:          getEnvWithDefault("MCP_CLIENT_USER_AGENT", "k8s-mcp-client/1.0.0"),
+		UserAgent:          getEnvWithDefault("MCP_CLIENT_USER_AGENT", version.UserAgent("k8s-mcp-client")),
+		ClientCertPath:     os.Getenv("MCP_CLIENT_CERT"),
+		ClientKeyPath:      os.Getenv("MCP_CLIENT_KEY"),
+		CAPath:             os.Getenv("MCP_CLIENT_CA"),
+		CompressRequests:   strings.ToLower(getEnvWithDefault("MCP_CLIENT_COMPRESS_REQUESTS", "false")) == "true",
+		Transport:          os.Getenv("MCP_CLIENT_TRANSPORT"),
 	}
 	return cfg, nil
 }
@@ -0,0 +1,310 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clustersResourceURI 列出已注册集群的资源 URI
+// clustersResourceURI is the resource listing the registered clusters
+const clustersResourceURI = "k8s://clusters"
+
+// ToolError 工具调用返回 IsError 结果时的错误，Message 为服务器返回的错误信息，
+// Details 为服务器附带的错误分类（reason、resource、retryable、suggestion），服务器未提供时为 nil
+// ToolError is returned when a tool call completes with an IsError result; Message is the
+// server's message and Details the error classification it sent, if any
+type ToolError struct {
+	Tool    string
+	Message string
+	Details *types.ToolErrorDetails
+}
+
+// Error 实现 error 接口
+// Error implements the error interface
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("tool %s failed: %s", e.Tool, e.Message)
+}
+
+// ListOptions 列表类方法的可选参数
+// ListOptions holds optional arguments of the list helpers
+type ListOptions struct {
+	// AllNamespaces 列出所有命名空间，忽略 namespace 参数
+	// AllNamespaces lists across all namespaces, ignoring the namespace argument
+	AllNamespaces bool
+}
+
+// ListClusters 返回已注册的集群名称和当前集群
+// ListClusters returns the registered cluster names and the current cluster
+func (c *Client) ListClusters(ctx context.Context) ([]string, string, error) {
+	contents, err := c.ReadResource(ctx, clustersResourceURI)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(contents) == 0 {
+		return nil, "", fmt.Errorf("empty response for %s", clustersResourceURI)
+	}
+
+	var clusters struct {
+		Current  string   `json:"current"`
+		Clusters []string `json:"clusters"`
+	}
+	if err := json.Unmarshal([]byte(contents[0].Text), &clusters); err != nil {
+		return nil, "", fmt.Errorf("failed to decode clusters: %w", err)
+	}
+	return clusters.Clusters, clusters.Current, nil
+}
+
+// ListNamespaces 列出集群的命名空间，cluster 为空时使用当前集群
+// ListNamespaces lists the namespaces of a cluster (the current cluster if empty)
+func (c *Client) ListNamespaces(ctx context.Context, cluster string) ([]types.Namespace, error) {
+	args := map[string]interface{}{}
+	setIfNotEmpty(args, "cluster_name", cluster)
+
+	result, err := c.callTool(ctx, "list_namespaces", args)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := DecodeResult[types.NamespacesResult](result)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Items != nil {
+		return decoded.Items, nil
+	}
+
+	var namespaces []types.Namespace
+	if err := unmarshalList(decoded.Namespaces, &namespaces); err != nil {
+		return nil, fmt.Errorf("failed to decode namespaces: %w", err)
+	}
+	return namespaces, nil
+}
+
+// ListPods 列出 Pod，namespace 为空时使用服务器端的默认命名空间
+// ListPods lists pods; an empty namespace uses the server's default namespace
+func (c *Client) ListPods(ctx context.Context, namespace, cluster string, opts *ListOptions) ([]types.Pod, error) {
+	var pods []types.Pod
+	return pods, c.listResources(ctx, "pods", namespace, cluster, opts, &pods)
+}
+
+// ListServices 列出 Service
+// ListServices lists services
+func (c *Client) ListServices(ctx context.Context, namespace, cluster string, opts *ListOptions) ([]types.Service, error) {
+	var services []types.Service
+	return services, c.listResources(ctx, "services", namespace, cluster, opts, &services)
+}
+
+// ListDeployments 列出 Deployment
+// ListDeployments lists deployments
+func (c *Client) ListDeployments(ctx context.Context, namespace, cluster string, opts *ListOptions) ([]types.Deployment, error) {
+	var deployments []types.Deployment
+	return deployments, c.listResources(ctx, "deployments", namespace, cluster, opts, &deployments)
+}
+
+// ListEvents 列出事件
+// ListEvents lists events
+func (c *Client) ListEvents(ctx context.Context, namespace, cluster string, opts *ListOptions) ([]types.Event, error) {
+	var events []types.Event
+	return events, c.listResources(ctx, "events", namespace, cluster, opts, &events)
+}
+
+// GetResource 获取单个资源的 JSON 定义（Secret 已脱敏）
+// GetResource returns the JSON definition of a single resource (secrets are redacted)
+func (c *Client) GetResource(ctx context.Context, resourceType, name, namespace, cluster string) (json.RawMessage, error) {
+	args := map[string]interface{}{
+		"resource_type": resourceType,
+		"name":          name,
+		"format":        "json",
+	}
+	setIfNotEmpty(args, "namespace", namespace)
+	setIfNotEmpty(args, "cluster_name", cluster)
+
+	result, err := c.callTool(ctx, "get_resource", args)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := DecodeResult[struct {
+		Resource string `json:"resource"`
+	}](result)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid([]byte(decoded.Resource)) {
+		return nil, fmt.Errorf("get_resource returned invalid JSON")
+	}
+	return json.RawMessage(decoded.Resource), nil
+}
+
+// GetResourceField 使用 JSONPath 或 dot-path (例如 spec.template.spec.containers[0].image) 只获取资源的字段值，多个匹配每行一个
+// GetResourceField returns only the value(s) of a resource matched by a JSONPath or dot-path
+// such as spec.template.spec.containers[0].image, one per line
+func (c *Client) GetResourceField(ctx context.Context, resourceType, name, namespace, cluster, jsonpath string) (string, error) {
+	if jsonpath == "" {
+		return "", fmt.Errorf("jsonpath is required")
+	}
+	args := map[string]interface{}{
+		"resource_type": resourceType,
+		"name":          name,
+		"jsonpath":      jsonpath,
+	}
+	setIfNotEmpty(args, "namespace", namespace)
+	setIfNotEmpty(args, "cluster_name", cluster)
+
+	result, err := c.callTool(ctx, "get_resource", args)
+	if err != nil {
+		return "", err
+	}
+	decoded, err := DecodeResult[struct {
+		Resource string `json:"resource"`
+	}](result)
+	if err != nil {
+		return "", err
+	}
+	return decoded.Resource, nil
+}
+
+// GetPodLogs 获取 Pod 日志
+// GetPodLogs returns the logs of a pod
+func (c *Client) GetPodLogs(ctx context.Context, opts types.PodLogOptions) (string, error) {
+	if opts.PodName == "" {
+		return "", fmt.Errorf("PodName is required")
+	}
+
+	args := map[string]interface{}{"pod_name": opts.PodName}
+	setIfNotEmpty(args, "namespace", opts.Namespace)
+	setIfNotEmpty(args, "container_name", opts.ContainerName)
+	setIfNotEmpty(args, "cluster_name", opts.ClusterName)
+	if opts.TailLines > 0 {
+		args["tail_lines"] = opts.TailLines
+	}
+	if opts.Previous {
+		args["previous"] = true
+	}
+	if opts.SinceTime != nil {
+		args["since_time"] = opts.SinceTime.Format(time.RFC3339Nano)
+	}
+	if opts.Timestamps {
+		args["timestamps"] = true
+	}
+
+	result, err := c.callTool(ctx, "get_pod_logs", args)
+	if err != nil {
+		return "", err
+	}
+	decoded, err := DecodeResult[struct {
+		Logs string `json:"logs"`
+	}](result)
+	if err != nil {
+		return "", err
+	}
+	return decoded.Logs, nil
+}
+
+// listResources 通过 list_resources 列出单个集群中的资源并解码到 out
+// listResources lists resources of one cluster through list_resources and decodes them into out
+func (c *Client) listResources(ctx context.Context, resourceType, namespace, cluster string, opts *ListOptions, out interface{}) error {
+	if cluster == "*" {
+		return fmt.Errorf("typed helpers query a single cluster, use CallTool for all clusters")
+	}
+
+	args := map[string]interface{}{"resource_type": resourceType}
+	setIfNotEmpty(args, "namespace", namespace)
+	setIfNotEmpty(args, "cluster_name", cluster)
+	if opts != nil && opts.AllNamespaces {
+		args["all_namespaces"] = true
+	}
+
+	result, err := c.callTool(ctx, "list_resources", args)
+	if err != nil {
+		return err
+	}
+	decoded, err := DecodeResult[struct {
+		Resources string `json:"resources"`
+	}](result)
+	if err != nil {
+		return err
+	}
+	if err := unmarshalList(decoded.Resources, out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", resourceType, err)
+	}
+	return nil
+}
+
+// callTool 调用工具，并将 IsError 结果转换为 *ToolError；辅助方法封装的都是只读工具，因此标记为幂等
+// callTool calls a tool and turns IsError results into a *ToolError. The
+// helpers only wrap read-only tools, so calls are marked idempotent.
+func (c *Client) callTool(ctx context.Context, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	result, err := c.CallTool(ctx, toolName, args, CallIdempotent())
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return nil, toolErrorFromResult(toolName, result)
+	}
+	return result, nil
+}
+
+// toolErrorFromResult 从 IsError 结果构造 ToolError：structuredContent 中的 {"error": {...}} 分类块解码到 Details，
+// 与之相同的 JSON 文本不计入 Message
+// toolErrorFromResult builds the ToolError of an IsError result: the {"error": {...}} block
+// in the structured content is decoded into Details, and its JSON text is left out of Message
+func toolErrorFromResult(toolName string, result *mcp.CallToolResult) *ToolError {
+	toolErr := &ToolError{Tool: toolName}
+	var block struct {
+		Error *types.ToolErrorDetails `json:"error"`
+	}
+	if result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil && json.Unmarshal(data, &block) == nil && block.Error != nil && block.Error.Reason != "" {
+			toolErr.Details = block.Error
+		}
+	}
+
+	var texts []string
+	for _, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok || text.Text == "" {
+			continue
+		}
+		if toolErr.Details != nil && strings.HasPrefix(text.Text, `{"error":`) {
+			continue
+		}
+		texts = append(texts, text.Text)
+	}
+	toolErr.Message = strings.Join(texts, "\n")
+	return toolErr
+}
+
+// resultText 拼接结果中的所有文本内容
+// resultText joins the text contents of a result
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok && text.Text != "" {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// unmarshalList 解码 JSON 数组字符串，服务器对空列表返回 "null"
+// unmarshalList decodes a JSON array string; the server returns "null" for empty lists
+func unmarshalList(data string, out interface{}) error {
+	if data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(data), out)
+}
+
+// setIfNotEmpty 仅在值非空时设置参数
+// setIfNotEmpty sets an argument only when the value is not empty
+func setIfNotEmpty(args map[string]interface{}, key, value string) {
+	if value != "" {
+		args[key] = value
+	}
+}
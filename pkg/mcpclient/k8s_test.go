@@ -0,0 +1,225 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// stubArgs 记录桩工具收到的参数
+type stubArgs struct {
+	ResourceType  string `json:"resource_type,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+	Format        string `json:"format,omitempty"`
+	PodName       string `json:"pod_name,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	TailLines     int    `json:"tail_lines,omitempty"`
+	Previous      bool   `json:"previous,omitempty"`
+	JSONPath      string `json:"jsonpath,omitempty"`
+}
+
+// newStubClient 创建连接到内存桩服务器的客户端，桩工具返回固定结果并记录最后一次调用的参数
+func newStubClient(t *testing.T, outputs map[string]map[string]any) (*Client, map[string]stubArgs) {
+	t.Helper()
+	calls := make(map[string]stubArgs)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "stub", Version: "test"}, nil)
+	for name, output := range outputs {
+		name, output := name, output
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(ctx context.Context, req *mcp.CallToolRequest, input stubArgs) (*mcp.CallToolResult, map[string]any, error) {
+			calls[name] = input
+			if msg, ok := output["error"].(string); ok {
+				return nil, nil, errors.New(msg)
+			}
+			return nil, output, nil
+		})
+	}
+	server.AddResource(&mcp.Resource{Name: "clusters", URI: clustersResourceURI}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{
+			URI:  clustersResourceURI,
+			Text: `{"current":"prod","clusters":["dev","prod"]}`,
+		}}}, nil
+	})
+	return connectStubServer(t, server), calls
+}
+
+// connectStubServer 通过内存传输将客户端连接到桩服务器
+func connectStubServer(t *testing.T, server *mcp.Server) *Client {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() {
+		session.Close()
+		serverSession.Wait()
+	})
+	return &Client{session: session}
+}
+
+// TestListClusters 测试从 k8s://clusters 资源读取集群列表
+func TestListClusters(t *testing.T) {
+	client, _ := newStubClient(t, nil)
+
+	clusters, current, err := client.ListClusters(context.Background())
+	if err != nil {
+		t.Fatalf("ListClusters failed: %v", err)
+	}
+	if !reflect.DeepEqual(clusters, []string{"dev", "prod"}) || current != "prod" {
+		t.Errorf("unexpected clusters %v, current %q", clusters, current)
+	}
+}
+
+// TestListNamespaces 测试优先使用结构化 items，否则解析文本 JSON
+func TestListNamespaces(t *testing.T) {
+	client, calls := newStubClient(t, map[string]map[string]any{
+		"list_namespaces": {"namespaces": `[{"name":"default","status":"Active"},{"name":"kube-system","status":"Active"}]`},
+	})
+
+	namespaces, err := client.ListNamespaces(context.Background(), "prod")
+	if err != nil {
+		t.Fatalf("ListNamespaces failed: %v", err)
+	}
+	if len(namespaces) != 2 || namespaces[1].Name != "kube-system" {
+		t.Errorf("unexpected namespaces: %+v", namespaces)
+	}
+	if calls["list_namespaces"].ClusterName != "prod" {
+		t.Errorf("unexpected arguments: %+v", calls["list_namespaces"])
+	}
+}
+
+// TestListPods 测试通过 list_resources 列出 Pod 并传递参数
+func TestListPods(t *testing.T) {
+	client, calls := newStubClient(t, map[string]map[string]any{
+		"list_resources": {"resources": `[{"name":"web-0","namespace":"shop","status":"Running","restarts":2}]`},
+	})
+	ctx := context.Background()
+
+	pods, err := client.ListPods(ctx, "", "dev", &ListOptions{AllNamespaces: true})
+	if err != nil {
+		t.Fatalf("ListPods failed: %v", err)
+	}
+	if len(pods) != 1 || !reflect.DeepEqual(pods[0], types.Pod{Name: "web-0", Namespace: "shop", Status: "Running", Restarts: 2}) {
+		t.Errorf("unexpected pods: %+v", pods)
+	}
+	want := stubArgs{ResourceType: "pods", AllNamespaces: true, ClusterName: "dev"}
+	if calls["list_resources"] != want {
+		t.Errorf("unexpected arguments: %+v", calls["list_resources"])
+	}
+
+	if _, err := client.ListPods(ctx, "default", "*", nil); err == nil {
+		t.Errorf("expected the all-clusters wildcard to be rejected")
+	}
+}
+
+// TestGetResource 测试返回原始 JSON
+func TestGetResource(t *testing.T) {
+	client, calls := newStubClient(t, map[string]map[string]any{
+		"get_resource": {"resource": `{"kind":"Pod","metadata":{"name":"web-0"}}`},
+	})
+
+	raw, err := client.GetResource(context.Background(), "pod", "web-0", "shop", "")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	if string(raw) != `{"kind":"Pod","metadata":{"name":"web-0"}}` {
+		t.Errorf("unexpected resource: %s", raw)
+	}
+	want := stubArgs{ResourceType: "pod", Name: "web-0", Namespace: "shop", Format: "json"}
+	if calls["get_resource"] != want {
+		t.Errorf("unexpected arguments: %+v", calls["get_resource"])
+	}
+}
+
+// TestGetResourceField 测试 jsonpath 参数映射以及提取值原样返回
+func TestGetResourceField(t *testing.T) {
+	client, calls := newStubClient(t, map[string]map[string]any{
+		"get_resource": {"resource": "nginx:1.25\nenvoyproxy/envoy:v1.29"},
+	})
+
+	value, err := client.GetResourceField(context.Background(), "deployment", "web", "shop", "prod", "spec.template.spec.containers[*].image")
+	if err != nil {
+		t.Fatalf("GetResourceField failed: %v", err)
+	}
+	if value != "nginx:1.25\nenvoyproxy/envoy:v1.29" {
+		t.Errorf("unexpected value: %q", value)
+	}
+	want := stubArgs{ResourceType: "deployment", Name: "web", Namespace: "shop", ClusterName: "prod", JSONPath: "spec.template.spec.containers[*].image"}
+	if calls["get_resource"] != want {
+		t.Errorf("unexpected arguments: %+v", calls["get_resource"])
+	}
+	if _, err := client.GetResourceField(context.Background(), "deployment", "web", "shop", "", ""); err == nil {
+		t.Errorf("expected an error without a jsonpath")
+	}
+}
+
+// TestGetPodLogs 测试日志参数映射
+func TestGetPodLogs(t *testing.T) {
+	client, calls := newStubClient(t, map[string]map[string]any{
+		"get_pod_logs": {"logs": "line 1\nline 2"},
+	})
+
+	logs, err := client.GetPodLogs(context.Background(), types.PodLogOptions{PodName: "web-0", Namespace: "shop", TailLines: 50, Previous: true})
+	if err != nil {
+		t.Fatalf("GetPodLogs failed: %v", err)
+	}
+	if logs != "line 1\nline 2" {
+		t.Errorf("unexpected logs %q", logs)
+	}
+	want := stubArgs{PodName: "web-0", Namespace: "shop", TailLines: 50, Previous: true}
+	if calls["get_pod_logs"] != want {
+		t.Errorf("unexpected arguments: %+v", calls["get_pod_logs"])
+	}
+}
+
+// TestToolErrorCarriesServerMessage 测试 IsError 结果转换为带服务器消息的 ToolError
+func TestToolErrorCarriesServerMessage(t *testing.T) {
+	client, _ := newStubClient(t, map[string]map[string]any{
+		"get_pod_logs": {"error": "pods \"web-0\" not found"},
+	})
+
+	_, err := client.GetPodLogs(context.Background(), types.PodLogOptions{PodName: "web-0"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Tool != "get_pod_logs" || toolErr.Message != "pods \"web-0\" not found" {
+		t.Errorf("expected ToolError with the server message, got %v", err)
+	}
+}
+
+// TestToolErrorDetails 测试服务器附带的错误分类解码到 ToolError.Details，分类块的 JSON 文本不计入 Message
+func TestToolErrorDetails(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "stub", Version: "test"}, nil)
+	server.AddTool(&mcp.Tool{Name: "get_pod_logs", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		block := `{"error":{"reason":"NotFound","resource":"pods/web-0","retryable":false,"suggestion":"check the name"}}`
+		return &mcp.CallToolResult{
+			IsError:           true,
+			Content:           []mcp.Content{&mcp.TextContent{Text: `pods "web-0" not found`}, &mcp.TextContent{Text: block}},
+			StructuredContent: json.RawMessage(block),
+		}, nil
+	})
+	client := connectStubServer(t, server)
+
+	_, err := client.GetPodLogs(context.Background(), types.PodLogOptions{PodName: "web-0"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("expected a ToolError, got %v", err)
+	}
+	want := types.ToolErrorDetails{Reason: "NotFound", Resource: "pods/web-0", Suggestion: "check the name"}
+	if toolErr.Message != `pods "web-0" not found` || toolErr.Details == nil || *toolErr.Details != want {
+		t.Errorf("unexpected ToolError: %q %+v", toolErr.Message, toolErr.Details)
+	}
+}
@@ -0,0 +1,310 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+)
+
+// StreamPodLogs 的默认值
+// Defaults of StreamPodLogs
+const (
+	defaultLogPollInterval = 2 * time.Second
+	defaultLogBufferLines  = 1000
+)
+
+// logsTruncatedMarker get_pod_logs 在输出超过 1MB 时追加的标记
+// logsTruncatedMarker is appended by get_pod_logs when the output exceeds 1MB
+const logsTruncatedMarker = "\n\n[Logs truncated: exceeded 1MB limit]"
+
+// LogStreamOptions StreamPodLogs 的选项
+// LogStreamOptions configures StreamPodLogs
+type LogStreamOptions struct {
+	// PodLogOptions 选择 Pod 和容器；TailLines 只作用于第一次轮询，SinceTime 和 Timestamps 由 StreamPodLogs 管理
+	// PodLogOptions selects the pod and container; TailLines only applies to the first poll,
+	// SinceTime and Timestamps are managed by StreamPodLogs
+	types.PodLogOptions
+	// PollInterval 两次轮询之间的间隔，默认 2 秒 / wait between two polls, 2s by default
+	PollInterval time.Duration
+	// BufferLines 等待回调处理的最大行数，默认 1000；写满时丢弃最旧的行
+	// BufferLines bounds the lines waiting for the callback, 1000 by default; the oldest line is dropped when full
+	BufferLines int
+}
+
+// LogStreamStats StreamPodLogs 返回时的统计
+// LogStreamStats reports what StreamPodLogs did by the time it returned
+type LogStreamStats struct {
+	Delivered int64 // 传给回调的行数 / lines passed to the callback
+	Dropped   int64 // 因回调过慢而丢弃的行数 / lines dropped because the callback fell behind
+}
+
+// StreamPodLogs 持续读取 Pod 日志并逐行传给 onLine，直到 ctx 结束、onLine 返回错误或轮询失败。
+// 服务器不支持 follow 模式，因此以 timestamps=true 轮询 get_pod_logs，并用最后一行的时间戳作为下次的
+// since_time；API server 将 since_time 截断到秒，重复的行按时间戳和同一时间戳已传递的行数跳过，
+// 因此不会重复或遗漏行。传给 onLine 的行不含时间戳前缀。轮询和回调在不同 goroutine 中执行，
+// 回调过慢时缓冲区丢弃最旧的行，丢弃数量在返回的统计中报告。ctx 结束时返回 ctx.Err()。
+// StreamPodLogs keeps reading the logs of a pod and passes them to onLine line by line until ctx
+// is done, onLine returns an error or polling fails. The server has no follow mode, so it polls
+// get_pod_logs with timestamps=true, using the timestamp of the last line as the next since_time;
+// the API server truncates since_time to seconds, and repeated lines are skipped by timestamp and
+// by how many lines with the same timestamp were already delivered, so no line is duplicated or
+// lost. Lines reach onLine without their timestamp prefix. Polling runs apart from the callback:
+// when the callback falls behind, the buffer drops the oldest lines and the returned stats count
+// them. When ctx is done it returns ctx.Err().
+func (c *Client) StreamPodLogs(ctx context.Context, opts LogStreamOptions, onLine func(line string) error) (LogStreamStats, error) {
+	if opts.PodName == "" {
+		return LogStreamStats{}, fmt.Errorf("PodName is required")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultLogPollInterval
+	}
+	if opts.BufferLines <= 0 {
+		opts.BufferLines = defaultLogBufferLines
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	buffer := newLineBuffer(opts.BufferLines)
+	pollErr := make(chan error, 1)
+	go func() {
+		pollErr <- c.pollPodLogs(ctx, opts, buffer.push)
+		buffer.close()
+	}()
+
+	var stats LogStreamStats
+	for ctx.Err() == nil {
+		line, ok := buffer.pop(ctx)
+		if !ok {
+			break
+		}
+		if err := onLine(line); err != nil {
+			cancel()
+			<-pollErr
+			stats.Dropped = buffer.droppedLines()
+			return stats, err
+		}
+		stats.Delivered++
+	}
+
+	cancel()
+	err := <-pollErr
+	stats.Dropped = buffer.droppedLines()
+	if err == nil {
+		err = parent.Err()
+	}
+	return stats, err
+}
+
+// pollPodLogs 轮询 get_pod_logs 并将新行传给 emit，直到 ctx 结束或调用失败
+// pollPodLogs polls get_pod_logs and passes new lines to emit until ctx is done or a call fails
+func (c *Client) pollPodLogs(ctx context.Context, opts LogStreamOptions, emit func(string)) error {
+	var cursor logCursor
+	for {
+		req := opts.PodLogOptions
+		req.Timestamps = true
+		req.SinceTime = nil
+		if cursor.started {
+			since := cursor.since()
+			req.SinceTime = &since
+			req.TailLines = 0
+		}
+
+		logs, err := c.GetPodLogs(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		truncated := strings.HasSuffix(logs, logsTruncatedMarker)
+		delivered := cursor.consume(logs, truncated, emit)
+		if truncated && delivered == 0 {
+			// 同一秒内的日志超过 1MB，无法再前进，跳过该秒剩余的行
+			// more than 1MB was logged within one second; skip the rest of it to make progress
+			cursor.skipSecond()
+		}
+
+		// 被截断时还有未读的行，立即再次轮询
+		// a truncated response leaves unread lines, so poll again right away
+		if truncated {
+			if ctx.Err() != nil {
+				return nil
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// logCursor 记录已传递的位置：最后一行的时间戳以及具有该时间戳的已传递行数
+// logCursor records how far the stream got: the timestamp of the last delivered line
+// and how many delivered lines carry exactly that timestamp
+type logCursor struct {
+	started bool
+	last    time.Time
+	atLast  int
+}
+
+// since 下次轮询的 since_time；尚无任何行时为零点，即从头读取此后出现的全部日志
+// since is the since_time of the next poll; before any line it is the epoch, so every line logged from then on is read
+func (lc *logCursor) since() time.Time {
+	if lc.last.IsZero() {
+		return time.Unix(0, 0).UTC()
+	}
+	return lc.last
+}
+
+// consume 解析一次轮询的输出，将未传递过的行传给 emit 并返回传递的行数。
+// 截断的输出的最后一行可能不完整，留给下次轮询读取。
+// consume parses the output of one poll, passes the lines not delivered before to emit and
+// returns how many it passed. The last line of a truncated output may be cut off, so it is
+// left for the next poll.
+func (lc *logCursor) consume(logs string, truncated bool, emit func(string)) int {
+	lc.started = true
+	logs = strings.TrimSuffix(logs, logsTruncatedMarker)
+	logs = strings.TrimSuffix(logs, "\n")
+	if logs == "" {
+		return 0
+	}
+	lines := strings.Split(logs, "\n")
+	if truncated {
+		lines = lines[:len(lines)-1]
+	}
+
+	delivered, sameAsLast := 0, 0
+	for _, raw := range lines {
+		ts, text, ok := splitLogTimestamp(raw)
+		if !ok || ts.Before(lc.last) {
+			continue
+		}
+		if ts.Equal(lc.last) {
+			sameAsLast++
+			if sameAsLast <= lc.atLast {
+				continue
+			}
+			lc.atLast++
+		} else {
+			lc.last, lc.atLast, sameAsLast = ts, 1, 1
+		}
+		emit(text)
+		delivered++
+	}
+	return delivered
+}
+
+// skipSecond 将位置移到下一整秒
+// skipSecond moves the cursor to the start of the next second
+func (lc *logCursor) skipSecond() {
+	lc.last = lc.since().Truncate(time.Second).Add(time.Second)
+	lc.atLast = 0
+}
+
+// splitLogTimestamp 拆分 timestamps=true 输出中一行的 RFC3339Nano 时间戳和内容
+// splitLogTimestamp splits a line of timestamps=true output into its RFC3339Nano timestamp and text
+func splitLogTimestamp(line string) (time.Time, string, bool) {
+	stamp, text, _ := strings.Cut(line, " ")
+	ts, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return ts, text, true
+}
+
+// lineBuffer 有界的行队列，写满时丢弃最旧的行
+// lineBuffer is a bounded queue of lines that drops the oldest line when full
+type lineBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	head    int
+	size    int
+	closed  bool
+	dropped int64
+	ready   chan struct{}
+}
+
+// newLineBuffer 创建最多保存 capacity 行的缓冲区
+// newLineBuffer creates a buffer holding at most capacity lines
+func newLineBuffer(capacity int) *lineBuffer {
+	return &lineBuffer{lines: make([]string, capacity), ready: make(chan struct{}, 1)}
+}
+
+// push 加入一行，缓冲区已满时丢弃最旧的行
+// push appends a line, dropping the oldest one when the buffer is full
+func (b *lineBuffer) push(line string) {
+	b.mu.Lock()
+	if b.size == len(b.lines) {
+		b.lines[b.head] = ""
+		b.head = (b.head + 1) % len(b.lines)
+		b.size--
+		b.dropped++
+	}
+	b.lines[(b.head+b.size)%len(b.lines)] = line
+	b.size++
+	b.mu.Unlock()
+	b.signal()
+}
+
+// pop 取出最旧的行；缓冲区为空时等待，缓冲区已关闭且为空或 ctx 结束时返回 false
+// pop removes the oldest line, waiting while the buffer is empty; it returns false once the
+// buffer is closed and empty or ctx is done
+func (b *lineBuffer) pop(ctx context.Context) (string, bool) {
+	for {
+		b.mu.Lock()
+		if b.size > 0 {
+			line := b.lines[b.head]
+			b.lines[b.head] = ""
+			b.head = (b.head + 1) % len(b.lines)
+			b.size--
+			b.mu.Unlock()
+			return line, true
+		}
+		closed := b.closed
+		b.mu.Unlock()
+		if closed {
+			return "", false
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-b.ready:
+		}
+	}
+}
+
+// close 标记不会再有新行
+// close marks that no more lines will be pushed
+func (b *lineBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.signal()
+}
+
+// droppedLines 返回丢弃的行数
+// droppedLines returns how many lines were dropped
+func (b *lineBuffer) droppedLines() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// signal 唤醒等待中的 pop
+// signal wakes up a waiting pop
+func (b *lineBuffer) signal() {
+	select {
+	case b.ready <- struct{}{}:
+	default:
+	}
+}
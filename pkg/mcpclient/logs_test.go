@@ -0,0 +1,203 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// 假日志服务器的参数：共 10000 行，每 4 行共用一个时间戳，每次调用后新增 1500 行，单次最多返回 700 行
+const (
+	fakeLogTotal       = 10000
+	fakeLogGrowth      = 1500
+	fakeLogMaxResponse = 700
+)
+
+// fakeLogServer 模拟 get_pod_logs：日志随调用次数增长，since_time 按 API server 的行为截断到秒，
+// 输出过多时在行中间截断并追加截断标记
+type fakeLogServer struct {
+	t         *testing.T
+	mu        sync.Mutex
+	available int
+	servedAll bool
+	// drained 在所有行都已返回后的下一次调用时关闭，此时客户端已将全部行放入缓冲区
+	drained chan struct{}
+}
+
+// fakeLogTimestamp 返回第 i 行的时间戳
+func fakeLogTimestamp(i int) time.Time {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return base.Add(time.Duration(i/4) * 250 * time.Millisecond)
+}
+
+// fakeLogLine 返回第 i 行带时间戳的内容
+func fakeLogLine(i int) string {
+	return fakeLogTimestamp(i).Format(time.RFC3339Nano) + " " + fmt.Sprintf("line-%05d", i)
+}
+
+// getPodLogs 处理 get_pod_logs 调用
+func (f *fakeLogServer) getPodLogs(ctx context.Context, req *mcp.CallToolRequest, input struct {
+	PodName    string `json:"pod_name"`
+	TailLines  int    `json:"tail_lines,omitempty"`
+	SinceTime  string `json:"since_time,omitempty"`
+	Timestamps bool   `json:"timestamps,omitempty"`
+}) (*mcp.CallToolResult, map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !input.Timestamps {
+		f.t.Errorf("StreamPodLogs must request timestamps")
+	}
+	if f.servedAll {
+		select {
+		case <-f.drained:
+		default:
+			close(f.drained)
+		}
+	}
+
+	first := 0
+	if input.SinceTime == "" {
+		tail := input.TailLines
+		if tail == 0 {
+			tail = 100
+		}
+		first = f.available - tail
+	} else {
+		since, err := time.Parse(time.RFC3339Nano, input.SinceTime)
+		if err != nil {
+			return nil, nil, err
+		}
+		since = since.Truncate(time.Second)
+		for first < f.available && fakeLogTimestamp(first).Before(since) {
+			first++
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	var lines []string
+	for i := first; i < f.available; i++ {
+		lines = append(lines, fakeLogLine(i))
+	}
+	logs := strings.Join(lines, "\n")
+	if len(lines) > fakeLogMaxResponse {
+		// 与 1MB 截断一样，最后一行可能只返回一半
+		cut := lines[fakeLogMaxResponse]
+		logs = strings.Join(lines[:fakeLogMaxResponse], "\n") + "\n" + cut[:len(cut)/2] + logsTruncatedMarker
+	} else if f.available == fakeLogTotal {
+		f.servedAll = true
+	}
+
+	f.available += fakeLogGrowth
+	if f.available > fakeLogTotal {
+		f.available = fakeLogTotal
+	}
+	return nil, map[string]any{"logs": logs}, nil
+}
+
+// newFakeLogClient 创建连接到假日志服务器的客户端
+func newFakeLogClient(t *testing.T) (*Client, *fakeLogServer) {
+	t.Helper()
+	fake := &fakeLogServer{t: t, drained: make(chan struct{})}
+	server := mcp.NewServer(&mcp.Implementation{Name: "stub", Version: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_pod_logs"}, fake.getPodLogs)
+	return connectStubServer(t, server), fake
+}
+
+// TestStreamPodLogs 测试 10000 行日志在截断和秒级 since_time 下按顺序传递且不重复、不遗漏
+func TestStreamPodLogs(t *testing.T) {
+	client, _ := newFakeLogClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var lines []string
+	stats, err := client.StreamPodLogs(ctx, LogStreamOptions{
+		PodLogOptions: types.PodLogOptions{PodName: "web-0"},
+		PollInterval:  time.Millisecond,
+		BufferLines:   fakeLogTotal,
+	}, func(line string) error {
+		lines = append(lines, line)
+		if len(lines) == fakeLogTotal {
+			cancel()
+		}
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(lines) != fakeLogTotal || stats.Delivered != fakeLogTotal || stats.Dropped != 0 {
+		t.Fatalf("got %d lines, stats %+v", len(lines), stats)
+	}
+	for i, line := range lines {
+		if want := fmt.Sprintf("line-%05d", i); line != want {
+			t.Fatalf("line %d: got %q, want %q", i, line, want)
+		}
+	}
+}
+
+// TestStreamPodLogsDropsOldest 测试回调过慢时缓冲区丢弃最旧的行，并在统计中报告丢弃数量
+func TestStreamPodLogsDropsOldest(t *testing.T) {
+	client, fake := newFakeLogClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var lines []string
+	stats, err := client.StreamPodLogs(ctx, LogStreamOptions{
+		PodLogOptions: types.PodLogOptions{PodName: "web-0"},
+		PollInterval:  time.Millisecond,
+		BufferLines:   100,
+	}, func(line string) error {
+		if len(lines) == 0 {
+			// 第一行处理完之前所有日志都已进入缓冲区
+			select {
+			case <-fake.drained:
+			case <-time.After(10 * time.Second):
+				t.Error("fake server never served all lines")
+			}
+		}
+		lines = append(lines, line)
+		if line == fmt.Sprintf("line-%05d", fakeLogTotal-1) {
+			cancel()
+		}
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	// 第一行之外只保留最后 100 行
+	if stats.Delivered != 101 || stats.Delivered+stats.Dropped != fakeLogTotal || len(lines) != 101 {
+		t.Fatalf("unexpected stats %+v with %d lines", stats, len(lines))
+	}
+	for i := 1; i < len(lines); i++ {
+		if want := fmt.Sprintf("line-%05d", fakeLogTotal-100+i-1); lines[i] != want {
+			t.Fatalf("line %d: got %q, want %q", i, lines[i], want)
+		}
+	}
+}
+
+// TestStreamPodLogsCallbackError 测试回调返回错误时停止并返回该错误
+func TestStreamPodLogsCallbackError(t *testing.T) {
+	client, _ := newFakeLogClient(t)
+	stop := errors.New("stop")
+
+	stats, err := client.StreamPodLogs(context.Background(), LogStreamOptions{
+		PodLogOptions: types.PodLogOptions{PodName: "web-0"},
+		PollInterval:  time.Millisecond,
+	}, func(line string) error {
+		return stop
+	})
+	if !errors.Is(err, stop) || stats.Delivered != 0 {
+		t.Errorf("expected callback error and no delivered lines, got %v %+v", err, stats)
+	}
+}
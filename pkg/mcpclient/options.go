@@ -1,5 +1,11 @@
 package mcpclient
 
+import (
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+)
+
 // Option 定义配置选项函数类型
 // Option defines the function type for configuration options
 type Option func(*Client)
@@ -19,3 +25,56 @@ func WithUserAgent(userAgent string) Option {
 		c.config.UserAgent = userAgent
 	}
 }
+
+// WithAutoReconnect 启用自动重连：CallTool、ListTools 等遇到连接级错误时，按指数退避（带抖动）最多重试 maxRetries 次 Connect，
+// 成功后重放失败的调用一次。注意被重放的工具调用可能已在服务器上执行过。
+// WithAutoReconnect enables auto-reconnect: on a connection-level failure, CallTool,
+// ListTools and the typed helpers re-run Connect up to maxRetries times with
+// exponential backoff and jitter starting at backoff, then replay the failed call
+// once. Note that a replayed tool call may already have run on the server.
+func WithAutoReconnect(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		if maxRetries <= 0 {
+			c.reconnect = nil
+			return
+		}
+		c.reconnect = &reconnectConfig{maxRetries: maxRetries, backoff: backoff}
+	}
+}
+
+// WithLogger 设置日志记录器，用于记录重连尝试及结果
+// WithLogger sets the logger used to report reconnect attempts and outcomes
+func WithLogger(l logger.Logger) Option {
+	return func(c *Client) {
+		if l != nil {
+			c.logger = l
+		}
+	}
+}
+
+// WithCallTimeout 设置每次工具调用尝试的默认超时时间，可通过 CallWithTimeout 按调用覆盖
+// WithCallTimeout sets the default per-attempt timeout of tool calls; override
+// it per call with CallWithTimeout
+func WithCallTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.callTimeout = d
+	}
+}
+
+// WithRetry 设置幂等调用的默认重试策略，可通过 CallWithRetry 按调用覆盖
+// WithRetry sets the default retry policy for idempotent calls; override it per
+// call with CallWithRetry
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// WithToolListChangedHandler 设置服务器发送 tools/list_changed 通知时的回调，回调在独立的 goroutine 中执行
+// WithToolListChangedHandler sets a callback for tools/list_changed notifications;
+// it runs on its own goroutine
+func WithToolListChangedHandler(h func()) Option {
+	return func(c *Client) {
+		c.onToolsChange = h
+	}
+}
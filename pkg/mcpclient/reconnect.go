@@ -0,0 +1,121 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/pkg/logger"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// codeRejectedByTransport SDK 传输层发送请求失败（如 HTTP 请求出错、会话已失效）时使用的 JSON-RPC 错误码
+// codeRejectedByTransport is the JSON-RPC code the SDK uses when the transport
+// failed to deliver a request (HTTP errors, expired session)
+const codeRejectedByTransport = -32005
+
+// errNotConnected 客户端尚未连接
+// errNotConnected is returned when the client has no session
+var errNotConnected = errors.New("client not connected")
+
+// reconnectConfig 自动重连配置
+// reconnectConfig holds the auto-reconnect settings
+type reconnectConfig struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// withSession 使用当前会话执行 op；启用自动重连时，连接级错误会触发重连并重放一次 op
+// withSession runs op on the current session. With auto-reconnect enabled, a
+// connection-level failure triggers a reconnect and op is replayed once.
+func (c *Client) withSession(ctx context.Context, op func(*mcp.ClientSession) error) error {
+	session := c.currentSession()
+	if session == nil {
+		if c.reconnect == nil {
+			return errNotConnected
+		}
+	} else {
+		err := op(session)
+		if c.reconnect == nil || !isConnectionError(ctx, err) {
+			return err
+		}
+		c.logger.Warn("MCP connection lost", "error", err)
+	}
+
+	session, err := c.reconnectSession(ctx, session)
+	if err != nil {
+		return err
+	}
+	return op(session)
+}
+
+// reconnectSession 重新建立连接；并发调用者共享同一次重连，若其他调用者已替换 failed 会话则直接返回新会话
+// reconnectSession re-establishes the connection. Concurrent callers share a
+// single reconnect: if another caller already replaced the failed session, the
+// new session is returned without dialing again.
+func (c *Client) reconnectSession(ctx context.Context, failed *mcp.ClientSession) (*mcp.ClientSession, error) {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	if session := c.currentSession(); session != nil && session != failed {
+		return session, nil
+	}
+	if failed != nil {
+		_ = failed.Close()
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= c.reconnect.maxRetries; attempt++ {
+		if attempt > 1 {
+			if err := sleepContext(ctx, backoffDelay(c.reconnect.backoff, attempt-1)); err != nil {
+				return nil, fmt.Errorf("reconnect aborted: %w", err)
+			}
+		}
+
+		c.logger.Info("Reconnecting to MCP server", "server", c.config.ServerURL, "attempt", attempt, "max_retries", c.reconnect.maxRetries)
+		if lastErr = c.Connect(ctx); lastErr == nil {
+			c.logger.Info("Reconnected to MCP server", "server", c.config.ServerURL, "attempt", attempt)
+			return c.currentSession(), nil
+		}
+		c.logger.Warn("Reconnect attempt failed", "attempt", attempt, "error", lastErr)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	c.logger.Error("Giving up reconnecting to MCP server", "server", c.config.ServerURL, "error", lastErr)
+	return nil, fmt.Errorf("reconnect failed after %d attempts: %w", c.reconnect.maxRetries, lastErr)
+}
+
+// isConnectionError 判断错误是否为连接级错误：服务器返回的 JSON-RPC 错误和调用方取消不算，传输层拒绝的请求算
+// isConnectionError reports whether err is a connection-level failure. JSON-RPC
+// errors returned by the server and caller cancellation are not; requests
+// rejected by the transport are.
+func isConnectionError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, mcp.ErrConnectionClosed) {
+		return true
+	}
+	var rpcErr *jsonrpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == codeRejectedByTransport
+	}
+	return true
+}
+
+// nopLogger 未设置日志记录器时使用的空实现
+// nopLogger is used when no logger is configured
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (l nopLogger) With(keysAndValues ...interface{}) logger.Logger {
+	return l
+}
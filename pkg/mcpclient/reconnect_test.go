@@ -0,0 +1,172 @@
+package mcpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// flakyServer 可以重启、丢弃前 N 个请求并延迟 slow 工具调用的测试服务器
+type flakyServer struct {
+	mu          sync.Mutex
+	server      *mcp.Server
+	handler     http.Handler
+	drops       int
+	initializes atomic.Int32
+	slowCalls   atomic.Int32 // 接下来需要延迟的 slow 调用次数
+	calls       atomic.Int32 // slow 工具被调用的总次数
+}
+
+// restart 模拟服务器重启：替换处理器使旧会话失效，并丢弃接下来的 drops 个请求
+func (f *flakyServer) restart(drops int) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "flaky", Version: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, struct {
+		Text string `json:"text"`
+	}, error) {
+		return nil, input, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		f.calls.Add(1)
+		if f.slowCalls.Add(-1) >= 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.server = server
+	f.handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	f.drops = drops
+}
+
+// drop 丢弃接下来的 n 个请求，但保留现有会话
+func (f *flakyServer) drop(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drops = n
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	handler := f.handler
+	drop := f.drops > 0
+	if drop {
+		f.drops--
+	}
+	f.mu.Unlock()
+
+	if drop {
+		// 直接断开连接，模拟网络抖动
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+		return
+	}
+	if r.Method == http.MethodPost && r.Header.Get("Mcp-Session-Id") == "" {
+		f.initializes.Add(1)
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// newFlakyClient 启动测试服务器并返回已连接的客户端
+func newFlakyClient(t *testing.T, opts ...Option) (*Client, *flakyServer) {
+	t.Helper()
+	flaky := &flakyServer{}
+	flaky.restart(0)
+	ts := httptest.NewServer(flaky)
+	t.Cleanup(ts.Close)
+
+	client, err := NewClient(Config{ServerURL: ts.URL, AuthToken: "test-token", UserAgent: "test"}, opts...)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, flaky
+}
+
+// TestAutoReconnectAfterRestart 测试服务器重启并丢弃请求后自动重连并重放调用
+func TestAutoReconnectAfterRestart(t *testing.T) {
+	client, flaky := newFlakyClient(t, WithAutoReconnect(5, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	flaky.restart(2)
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hello"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text := resultText(result); text != `{"text":"hello"}` {
+		t.Errorf("unexpected result %q", text)
+	}
+
+	if _, err := client.ListTools(ctx); err != nil {
+		t.Errorf("ListTools failed after reconnect: %v", err)
+	}
+}
+
+// TestAutoReconnectGivesUp 测试重连次数耗尽后返回错误
+func TestAutoReconnectGivesUp(t *testing.T) {
+	client, flaky := newFlakyClient(t, WithAutoReconnect(2, time.Millisecond))
+
+	flaky.restart(100)
+	if _, err := client.CallTool(context.Background(), "echo", nil); err == nil {
+		t.Fatalf("expected an error while the server keeps dropping requests")
+	}
+}
+
+// TestWithoutAutoReconnect 测试未启用自动重连时直接返回连接错误
+func TestWithoutAutoReconnect(t *testing.T) {
+	client, flaky := newFlakyClient(t)
+
+	flaky.restart(0)
+	if _, err := client.CallTool(context.Background(), "echo", nil); err == nil {
+		t.Fatalf("expected the stale session to fail")
+	}
+	if got := flaky.initializes.Load(); got != 1 {
+		t.Errorf("expected no reconnect, got %d initialize requests", got)
+	}
+}
+
+// TestAutoReconnectSingleFlight 测试并发调用只触发一次重连
+func TestAutoReconnectSingleFlight(t *testing.T) {
+	client, flaky := newFlakyClient(t, WithAutoReconnect(5, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	flaky.restart(0)
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("CallTool failed: %v", err)
+	}
+
+	if got := flaky.initializes.Load(); got != 2 {
+		t.Errorf("expected exactly one reconnect, got %d initialize requests", got)
+	}
+}
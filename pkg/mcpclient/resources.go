@@ -0,0 +1,128 @@
+package mcpclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListResources 获取资源列表，自动跟随 nextCursor 获取所有分页
+// ListResources retrieves the list of resources, following nextCursor across pages
+func (c *Client) ListResources(ctx context.Context) ([]*mcp.Resource, error) {
+	var resources []*mcp.Resource
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		resources = nil
+		params := &mcp.ListResourcesParams{}
+		for {
+			result, err := session.ListResources(ctx, params)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, result.Resources...)
+			if result.NextCursor == "" {
+				return nil
+			}
+			params.Cursor = result.NextCursor
+		}
+	})
+	if err == errNotConnected {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	return resources, nil
+}
+
+// ListResourceTemplates 获取资源模板列表，自动跟随 nextCursor 获取所有分页
+// ListResourceTemplates retrieves the list of resource templates, following nextCursor across pages
+func (c *Client) ListResourceTemplates(ctx context.Context) ([]*mcp.ResourceTemplate, error) {
+	var templates []*mcp.ResourceTemplate
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		templates = nil
+		params := &mcp.ListResourceTemplatesParams{}
+		for {
+			result, err := session.ListResourceTemplates(ctx, params)
+			if err != nil {
+				return err
+			}
+			templates = append(templates, result.ResourceTemplates...)
+			if result.NextCursor == "" {
+				return nil
+			}
+			params.Cursor = result.NextCursor
+		}
+	})
+	if err == errNotConnected {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource templates: %w", err)
+	}
+	return templates, nil
+}
+
+// ReadResource 读取指定 URI 的资源内容
+// ReadResource reads the contents of the resource at uri
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]*mcp.ResourceContents, error) {
+	var result *mcp.ReadResourceResult
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		var err error
+		result, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		return err
+	})
+	if err == errNotConnected {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+	}
+	return result.Contents, nil
+}
+
+// ListPrompts 获取提示列表，自动跟随 nextCursor 获取所有分页
+// ListPrompts retrieves the list of prompts, following nextCursor across pages
+func (c *Client) ListPrompts(ctx context.Context) ([]*mcp.Prompt, error) {
+	var prompts []*mcp.Prompt
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		prompts = nil
+		params := &mcp.ListPromptsParams{}
+		for {
+			result, err := session.ListPrompts(ctx, params)
+			if err != nil {
+				return err
+			}
+			prompts = append(prompts, result.Prompts...)
+			if result.NextCursor == "" {
+				return nil
+			}
+			params.Cursor = result.NextCursor
+		}
+	})
+	if err == errNotConnected {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+// GetPrompt 使用给定参数获取提示内容
+// GetPrompt fetches a prompt rendered with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	var result *mcp.GetPromptResult
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		var err error
+		result, err = session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name, Arguments: args})
+		return err
+	})
+	if err == errNotConnected {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt %s: %w", name, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// maxBackoff 单次等待时间的上限
+// maxBackoff caps the wait between two retry or reconnect attempts
+const maxBackoff = 30 * time.Second
+
+// RetryPolicy 定义幂等调用的重试策略
+// RetryPolicy defines how idempotent calls are retried
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数（不含首次调用） / retries after the first attempt
+	Backoff    time.Duration // 首次重试前的等待时间，之后指数增长 / initial wait, doubled on every retry
+}
+
+// CallOption 定义单次调用的选项，覆盖客户端级别的设置
+// CallOption customizes a single call, overriding the client-level settings
+type CallOption func(*callOptions)
+
+// callOptions 单次调用的有效设置
+// callOptions holds the effective settings of a call
+type callOptions struct {
+	timeout    time.Duration
+	retry      *RetryPolicy
+	idempotent bool
+}
+
+// CallWithTimeout 设置本次调用每次尝试的超时时间，0 表示不限制
+// CallWithTimeout sets the per-attempt timeout of this call; 0 disables it
+func CallWithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// CallWithRetry 设置本次调用的重试策略，仅对幂等调用生效
+// CallWithRetry sets the retry policy of this call; it only applies to idempotent calls
+func CallWithRetry(policy RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retry = &policy
+	}
+}
+
+// CallIdempotent 将本次调用标记为幂等，允许失败后重试
+// CallIdempotent marks this call as idempotent so it may be retried on failure
+func CallIdempotent() CallOption {
+	return func(o *callOptions) {
+		o.idempotent = true
+	}
+}
+
+// callOptions 合并客户端默认设置和单次调用选项
+// callOptions merges the client defaults with the per-call options
+func (c *Client) callOptions(opts []CallOption) callOptions {
+	o := callOptions{timeout: c.callTimeout, retry: c.retry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// retryCall 执行 op；幂等调用遇到可重试错误时按指数退避重试，每次尝试受 timeout 限制
+// retryCall runs op, bounding each attempt by the timeout. Idempotent calls are
+// retried with exponential backoff on transient failures.
+func (c *Client) retryCall(ctx context.Context, name string, o callOptions, op func(ctx context.Context) error) error {
+	retries := 0
+	if o.idempotent && o.retry != nil {
+		retries = o.retry.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := attemptWithTimeout(ctx, name, o.timeout, op)
+		if err == nil || attempt >= retries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		c.logger.Debug("Retrying MCP call", "name", name, "attempt", attempt+1, "error", err)
+		if err := sleepContext(ctx, backoffDelay(o.retry.Backoff, attempt+1)); err != nil {
+			return fmt.Errorf("%s: retry aborted: %w", name, err)
+		}
+	}
+}
+
+// attemptWithTimeout 执行一次 op，超时时返回包含调用名称并包装 context.DeadlineExceeded 的错误
+// attemptWithTimeout runs op once. On timeout it returns an error naming the
+// call and wrapping context.DeadlineExceeded.
+func attemptWithTimeout(ctx context.Context, name string, timeout time.Duration, op func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return op(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := op(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s: %w", name, timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// isRetryable 判断错误是否值得重试：单次尝试超时或连接级错误，调用方取消时不重试
+// isRetryable reports whether err is transient: an attempt timeout or a
+// connection-level failure. Nothing is retried once the caller's context is done.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, context.DeadlineExceeded) || isConnectionError(ctx, err)
+}
+
+// sleepContext 等待 d，context 结束时提前返回其错误
+// sleepContext waits for d, returning early with the context's error
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoffDelay 计算第 n 次重试前的等待时间：指数退避加随机抖动，上限为 maxBackoff
+// backoffDelay returns the wait before retry n: exponential backoff with
+// jitter, capped at maxBackoff
+func backoffDelay(backoff time.Duration, n int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	delay := backoff
+	for i := 1; i < n && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	// 在 [delay/2, delay] 之间随机，避免多个客户端同时重试
+	// Pick a random wait in [delay/2, delay] so clients do not retry in lockstep
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,92 @@
+package mcpclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCallTimeout 测试超时错误包含工具名并包装 context.DeadlineExceeded
+func TestCallTimeout(t *testing.T) {
+	client, flaky := newFlakyClient(t, WithCallTimeout(50*time.Millisecond))
+
+	flaky.slowCalls.Store(1)
+	_, err := client.CallTool(context.Background(), "slow", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "slow") {
+		t.Errorf("expected the tool name in %q", err)
+	}
+
+	// 单次调用可以覆盖客户端级别的超时
+	flaky.slowCalls.Store(0)
+	if _, err := client.CallTool(context.Background(), "slow", nil, CallWithTimeout(0)); err != nil {
+		t.Errorf("CallTool without timeout failed: %v", err)
+	}
+}
+
+// TestRetryIdempotentCalls 测试只有幂等调用会在超时或连接错误后重试
+func TestRetryIdempotentCalls(t *testing.T) {
+	client, flaky := newFlakyClient(t,
+		WithCallTimeout(50*time.Millisecond),
+		WithRetry(RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+	)
+	ctx := context.Background()
+
+	flaky.slowCalls.Store(2)
+	result, err := client.CallTool(ctx, "slow", nil, CallIdempotent())
+	if err != nil {
+		t.Fatalf("idempotent call failed: %v", err)
+	}
+	if resultText(result) != "done" || flaky.calls.Load() != 3 {
+		t.Errorf("expected success on the third attempt, got %q after %d calls", resultText(result), flaky.calls.Load())
+	}
+
+	flaky.drop(2)
+	if _, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"}, CallIdempotent()); err != nil {
+		t.Errorf("idempotent call failed after dropped requests: %v", err)
+	}
+
+	flaky.calls.Store(0)
+	flaky.slowCalls.Store(1)
+	if _, err := client.CallTool(ctx, "slow", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected non-idempotent call to fail without retry, got %v", err)
+	}
+	if got := flaky.calls.Load(); got != 1 {
+		t.Errorf("expected a single attempt, got %d", got)
+	}
+}
+
+// TestRetryRespectsCancellation 测试调用方取消 context 后停止重试
+func TestRetryRespectsCancellation(t *testing.T) {
+	client, flaky := newFlakyClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	flaky.slowCalls.Store(100)
+	start := time.Now()
+	_, err := client.CallTool(ctx, "slow", nil, CallIdempotent(), CallWithRetry(RetryPolicy{MaxRetries: 10, Backoff: time.Second}))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("retries continued after cancellation: took %v", elapsed)
+	}
+	if got := flaky.calls.Load(); got != 1 {
+		t.Errorf("expected a single attempt, got %d", got)
+	}
+}
+
+// TestBackoffDelay 测试退避时间指数增长、带抖动且有上限
+func TestBackoffDelay(t *testing.T) {
+	for n, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 20: maxBackoff} {
+		for i := 0; i < 20; i++ {
+			if d := backoffDelay(100*time.Millisecond, n); d < max/2 || d > max {
+				t.Errorf("backoffDelay(n=%d) = %v, want within [%v, %v]", n, d, max/2, max)
+			}
+		}
+	}
+}
@@ -8,32 +8,52 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// ListTools 获取工具列表
-// ListTools retrieves the list of available tools
+// ListTools 获取工具列表，自动跟随 nextCursor 获取所有分页
+// ListTools retrieves the list of available tools, following nextCursor across pages
 func (c *Client) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
-	if c.session == nil {
-		return nil, fmt.Errorf("client not connected")
+	var tools []*mcp.Tool
+	err := c.withSession(ctx, func(session *mcp.ClientSession) error {
+		tools = nil
+		params := &mcp.ListToolsParams{}
+		for {
+			result, err := session.ListTools(ctx, params)
+			if err != nil {
+				return err
+			}
+			tools = append(tools, result.Tools...)
+			if result.NextCursor == "" {
+				return nil
+			}
+			params.Cursor = result.NextCursor
+		}
+	})
+	if err == errNotConnected {
+		return nil, err
 	}
-
-	result, err := c.session.ListTools(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
-
-	return result.Tools, nil
+	return tools, nil
 }
 
-// CallTool 调用工具
-// CallTool calls a specific tool with arguments
-func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error) {
-	if c.session == nil {
-		return nil, fmt.Errorf("client not connected")
-	}
-
-	result, err := c.session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
+// CallTool 调用工具，opts 可覆盖超时和重试设置；只有通过 CallIdempotent 标记的调用才会重试
+// CallTool calls a specific tool with arguments. opts override the timeout and
+// retry settings; only calls marked with CallIdempotent are retried.
+func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]interface{}, opts ...CallOption) (*mcp.CallToolResult, error) {
+	var result *mcp.CallToolResult
+	err := c.retryCall(ctx, toolName, c.callOptions(opts), func(ctx context.Context) error {
+		return c.withSession(ctx, func(session *mcp.ClientSession) error {
+			var err error
+			result, err = session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      toolName,
+				Arguments: args,
+			})
+			return err
+		})
 	})
+	if err == errNotConnected {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("tool call failed: %w", err)
 	}
@@ -41,8 +61,9 @@ func (c *Client) CallTool(ctx context.Context, toolName string, args map[string]
 	return result, nil
 }
 
-// DecodeResult 将 MCP 工具调用结果解码为指定的结构体
-// DecodeResult decodes the MCP tool call result into the specified struct type
+// DecodeResult 将 MCP 工具调用结果解码为指定的结构体，优先使用 structuredContent，否则解析 TextContent 中的 JSON
+// DecodeResult decodes the MCP tool call result into the specified struct type, preferring
+// structuredContent and falling back to the JSON in TextContent
 func DecodeResult[T any](result *mcp.CallToolResult) (*T, error) {
 	// 检查 result 是否为 nil
 	if result == nil {
@@ -51,10 +72,25 @@ func DecodeResult[T any](result *mcp.CallToolResult) (*T, error) {
 
 	// 检查是否有错误
 	if result.IsError {
-		return nil, fmt.Errorf("tool call returned error")
+		return nil, fmt.Errorf("tool call returned error: %s", resultText(result))
+	}
+
+	// 优先使用 structuredContent
+	// Prefer structuredContent when the server provides it
+	if result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal structured content: %w", err)
+		}
+		var target T
+		if err := json.Unmarshal(data, &target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal structured content: %w", err)
+		}
+		return &target, nil
 	}
 
 	// 遍历 Content，寻找 TextContent 并解码
+	// Fall back to the JSON in the first TextContent
 	for _, content := range result.Content {
 		// 使用类型断言判断是否为 TextContent
 		if textContent, ok := content.(*mcp.TextContent); ok {
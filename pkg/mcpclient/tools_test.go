@@ -0,0 +1,87 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type testResult struct {
+	Resources string `json:"resources"`
+	Items     []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+// roundTrip 模拟结果经过网络传输后的编解码
+func roundTrip(t *testing.T, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var decoded mcp.CallToolResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	return &decoded
+}
+
+// TestDecodeResultPrefersStructuredContent 测试同时存在 structuredContent 和文本时优先使用前者
+func TestDecodeResultPrefersStructuredContent(t *testing.T) {
+	result := roundTrip(t, &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: `{"resources":"from text"}`}},
+		StructuredContent: map[string]any{"resources": "from structured", "items": []any{map[string]any{"name": "web-0"}}},
+	})
+
+	decoded, err := DecodeResult[testResult](result)
+	if err != nil {
+		t.Fatalf("DecodeResult failed: %v", err)
+	}
+	if decoded.Resources != "from structured" || len(decoded.Items) != 1 || decoded.Items[0].Name != "web-0" {
+		t.Errorf("unexpected result: %+v", decoded)
+	}
+}
+
+// TestDecodeResultFallsBackToText 测试没有 structuredContent 时解析文本中的 JSON
+func TestDecodeResultFallsBackToText(t *testing.T) {
+	result := roundTrip(t, &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: `{"resources":"from text"}`}},
+	})
+
+	decoded, err := DecodeResult[testResult](result)
+	if err != nil {
+		t.Fatalf("DecodeResult failed: %v", err)
+	}
+	if decoded.Resources != "from text" {
+		t.Errorf("unexpected result: %+v", decoded)
+	}
+
+	if _, err := DecodeResult[testResult](&mcp.CallToolResult{IsError: true}); err == nil {
+		t.Errorf("expected error for IsError result")
+	}
+}
+
+// TestToolListChangedHandler 测试服务器工具列表变化时触发回调
+func TestToolListChangedHandler(t *testing.T) {
+	changed := make(chan struct{}, 1)
+	_, flaky := newFlakyClient(t, WithToolListChangedHandler(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}))
+
+	flaky.server.AddTool(&mcp.Tool{Name: "added", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a tools/list_changed notification")
+	}
+}
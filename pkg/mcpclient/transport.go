@@ -1,15 +1,25 @@
 package mcpclient
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 )
 
+// minCompressRequestBytes 是压缩请求体的最小大小，更小的请求体压缩后收益不大
+// minCompressRequestBytes is the request body size below which compression doesn't pay off
+const minCompressRequestBytes = 1 << 10
+
 // tokenAuthTransport 包装 http.RoundTripper 以添加授权头
 // tokenAuthTransport wraps http.RoundTripper to add authorization header
 type tokenAuthTransport struct {
 	token         string
+	userAgent     string
 	customHeaders map[string]string
 	transport     http.RoundTripper
 }
@@ -19,7 +29,14 @@ type tokenAuthTransport struct {
 func (t *tokenAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// 添加授权头
 	// Add authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
+	// 使用客户端证书认证时 Token 可以为空
+	// The token may be empty when authenticating with a client certificate
+	if t.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.token))
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
 
 	// 添加自定义头
 	// Add custom headers
@@ -30,27 +47,109 @@ func (t *tokenAuthTransport) RoundTrip(req *http.Request) (*http.Response, error
 	return t.transport.RoundTrip(req)
 }
 
-// createHTTPClient 创建带有 Token 认证和自定义头的 HTTP 客户端
-// createHTTPClient creates an HTTP client with token authentication and custom headers
-func createHTTPClient(config Config, customHeaders map[string]string) *http.Client {
-	// 创建基础 HTTP 客户端
-	// Create base HTTP client
+// createHTTPClient 创建带有 Token 认证、可选客户端证书和自定义头的 HTTP 客户端
+// createHTTPClient creates an HTTP client with token authentication, an optional client certificate and custom headers
+func createHTTPClient(config Config, customHeaders map[string]string) (*http.Client, error) {
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建基础 HTTP 客户端。未显式设置 Accept-Encoding 时 Transport 会请求 gzip 并透明解压响应
+	// Create base HTTP client. Unless Accept-Encoding is set explicitly, the transport
+	// asks for gzip and transparently decompresses responses
 	httpClient := &http.Client{
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: config.InsecureSkipVerify,
-			},
+			TLSClientConfig:    tlsConfig,
+			DisableCompression: false,
 		},
 	}
+	if config.CompressRequests {
+		httpClient.Transport = &gzipRequestTransport{transport: httpClient.Transport}
+	}
 
 	// 注入 Token 和自定义头到请求中
 	// Inject token and custom headers into requests
 	tokenTransport := &tokenAuthTransport{
 		token:         config.AuthToken,
+		userAgent:     config.UserAgent,
 		customHeaders: customHeaders,
 		transport:     httpClient.Transport,
 	}
 	httpClient.Transport = tokenTransport
 
-	return httpClient
+	return httpClient, nil
+}
+
+// gzipRequestTransport 以 gzip 压缩大于 minCompressRequestBytes 的请求体，服务器会在处理之前解压
+// gzipRequestTransport gzips request bodies larger than minCompressRequestBytes; the server inflates them before handling
+type gzipRequestTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+// RoundTrip implements http.RoundTripper interface
+func (t *gzipRequestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.ContentLength < minCompressRequestBytes || req.Header.Get("Content-Encoding") != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	// RoundTripper 不能修改原请求，因此发送副本
+	// A RoundTripper must not modify the request, so send a copy
+	compressed := buf.Bytes()
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(compressed))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	out.ContentLength = int64(len(compressed))
+	out.Header.Set("Content-Encoding", "gzip")
+	return t.transport.RoundTrip(out)
+}
+
+// createTLSConfig 根据配置加载客户端证书和服务器 CA
+// createTLSConfig loads the client certificate and the server CA from the configuration
+func createTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if (config.ClientCertPath == "") != (config.ClientKeyPath == "") {
+		return nil, fmt.Errorf("client certificate and key must be set together")
+	}
+	if config.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCertPath, config.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.CAPath != "" {
+		data, err := os.ReadFile(config.CAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("invalid CA %s: no PEM certificates found", config.CAPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
 }
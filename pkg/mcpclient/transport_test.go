@@ -0,0 +1,184 @@
+package mcpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePEM 将 PEM 块写入 dir 下的文件并返回路径
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// newClientCertFiles 生成 CA 和由其签发的客户端证书，返回 CA 证书池以及客户端证书和私钥的路径
+func newClientCertFiles(t *testing.T, dir, user string) (*x509.CertPool, string, string) {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA certificate: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: user},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create client certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, writePEM(t, dir, user+".crt", "CERTIFICATE", der), writePEM(t, dir, user+".key", "EC PRIVATE KEY", keyDER)
+}
+
+// TestCreateHTTPClientClientCert 测试 mTLS 握手：配置客户端证书时握手成功且不发送空 token，未配置时被服务器拒绝
+func TestCreateHTTPClientClientCert(t *testing.T) {
+	dir := t.TempDir()
+	clientCAs, certPath, keyPath := newClientCertFiles(t, dir, "alice")
+
+	var user, authHeader string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.TLS.PeerCertificates[0].Subject.CommonName
+		authHeader = r.Header.Get("Authorization")
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caPath := writePEM(t, dir, "server-ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	client, err := createHTTPClient(Config{ClientCertPath: certPath, ClientKeyPath: keyPath, CAPath: caPath}, nil)
+	if err != nil {
+		t.Fatalf("createHTTPClient failed: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with a client certificate failed: %v", err)
+	}
+	resp.Body.Close()
+	if user != "alice" || authHeader != "" {
+		t.Errorf("unexpected peer %q and Authorization %q", user, authHeader)
+	}
+
+	client, err = createHTTPClient(Config{AuthToken: "token", CAPath: caPath}, nil)
+	if err != nil {
+		t.Fatalf("createHTTPClient failed: %v", err)
+	}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Errorf("expected the handshake to fail without a client certificate")
+	}
+}
+
+// TestCreateHTTPClientInvalidTLSConfig 测试证书配置错误时返回错误
+func TestCreateHTTPClientInvalidTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, certPath, keyPath := newClientCertFiles(t, dir, "alice")
+	invalidPath := filepath.Join(dir, "invalid.pem")
+	os.WriteFile(invalidPath, []byte("not a certificate"), 0o600)
+
+	for name, config := range map[string]Config{
+		"cert without key": {ClientCertPath: certPath},
+		"key without cert": {ClientKeyPath: keyPath},
+		"invalid key pair": {ClientCertPath: certPath, ClientKeyPath: invalidPath},
+		"invalid CA":       {CAPath: invalidPath},
+		"missing CA":       {CAPath: filepath.Join(dir, "missing.pem")},
+	} {
+		if _, err := createHTTPClient(config, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestCreateHTTPClientCompression 测试 gzip 响应被透明解压，启用 CompressRequests 时只压缩较大的请求体
+func TestCreateHTTPClientCompression(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			body = gz
+		}
+		data, _ := io.ReadAll(body)
+
+		// 回显请求体，客户端接受 gzip 时压缩响应
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write(data)
+		gz.Close()
+	}))
+	defer server.Close()
+
+	for _, compress := range []bool{false, true} {
+		encodings = nil
+		client, err := createHTTPClient(Config{AuthToken: "test-token", CompressRequests: compress}, nil)
+		if err != nil {
+			t.Fatalf("createHTTPClient failed: %v", err)
+		}
+		for _, body := range []string{"small", strings.Repeat("manifest ", 500)} {
+			resp, err := client.Post(server.URL, "application/json", bytes.NewReader([]byte(body)))
+			if err != nil {
+				t.Fatalf("POST failed: %v", err)
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(data) != body || !resp.Uncompressed {
+				t.Errorf("compress=%v: expected the transparently decompressed body, got %d bytes (uncompressed %v)", compress, len(data), resp.Uncompressed)
+			}
+		}
+		want := []string{"", ""}
+		if compress {
+			want = []string{"", "gzip"}
+		}
+		if strings.Join(encodings, ",") != strings.Join(want, ",") {
+			t.Errorf("compress=%v: expected request encodings %q, got %q", compress, want, encodings)
+		}
+	}
+}
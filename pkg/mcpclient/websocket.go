@@ -0,0 +1,170 @@
+package mcpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AceDarkknight/k8s-mcp/internal/wsconn"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/net/websocket"
+)
+
+// Config.Transport 的取值
+// Values of Config.Transport
+const (
+	TransportStreamable = "streamable" // 可流式 HTTP（默认） / streamable HTTP (the default)
+	TransportWebSocket  = "websocket"
+)
+
+// webSocketPath 服务器提供 WebSocket 传输的路径
+// webSocketPath is where the server serves the WebSocket transport
+const webSocketPath = "/ws"
+
+// WebSocketClientTransport 通过 WebSocket 连接 MCP 服务器，每个 JSON-RPC 消息一个文本帧，
+// 用于无法透传可流式 HTTP 传输的代理之后的客户端。每隔 KeepAlive 发送 ping，Close 时完成关闭握手
+// WebSocketClientTransport connects to the MCP server over a WebSocket, one JSON-RPC
+// message per text frame, for clients behind proxies that don't pass the streamable
+// HTTP transport through. It pings every KeepAlive and completes the close handshake on Close.
+type WebSocketClientTransport struct {
+	// Endpoint 为 ws:// 或 wss:// 地址
+	// Endpoint is the ws:// or wss:// URL
+	Endpoint string
+	// Header 随握手请求发送，如 Authorization
+	// Header is sent with the handshake request, e.g. Authorization
+	Header http.Header
+	// TLSConfig 用于 wss:// 地址，为空时使用默认配置
+	// TLSConfig is used for wss:// URLs; nil means the defaults
+	TLSConfig *tls.Config
+	// KeepAlive 为 ping 间隔，为 0 时使用 30 秒，为负数时不发送 ping
+	// KeepAlive is the ping interval: zero means 30 seconds, negative disables pings
+	KeepAlive time.Duration
+}
+
+// Connect 实现 mcp.Transport 接口，握手受 ctx 的截止时间限制
+// Connect implements mcp.Transport; the handshake is bounded by the deadline of ctx
+func (t *WebSocketClientTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	endpoint, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket endpoint: %w", err)
+	}
+	origin := "http://" + endpoint.Host
+	switch endpoint.Scheme {
+	case "ws":
+	case "wss":
+		origin = "https://" + endpoint.Host
+	default:
+		return nil, fmt.Errorf("invalid websocket endpoint %s: scheme must be ws or wss", t.Endpoint)
+	}
+
+	config, err := websocket.NewConfig(t.Endpoint, origin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket endpoint: %w", err)
+	}
+	for key, values := range t.Header {
+		config.Header[key] = values
+	}
+
+	address := endpoint.Host
+	if endpoint.Port() == "" {
+		port := "80"
+		if endpoint.Scheme == "wss" {
+			port = "443"
+		}
+		address = net.JoinHostPort(endpoint.Hostname(), port)
+	}
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", t.Endpoint, err)
+	}
+	if endpoint.Scheme == "wss" {
+		tlsConfig := &tls.Config{}
+		if t.TLSConfig != nil {
+			tlsConfig = t.TLSConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = endpoint.Hostname()
+		}
+		tlsConn := tls.Client(raw, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			raw.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", t.Endpoint, err)
+		}
+		raw = tlsConn
+	}
+
+	// The WebSocket handshake doesn't take a context, so bound it with the deadline of ctx
+	// WebSocket 握手不接受 context，因此以 ctx 的截止时间限制握手
+	if deadline, ok := ctx.Deadline(); ok {
+		raw.SetDeadline(deadline)
+	}
+	ws, err := websocket.NewClient(config, raw)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("websocket handshake with %s failed: %w", t.Endpoint, err)
+	}
+	raw.SetDeadline(time.Time{})
+
+	keepAlive := t.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = wsconn.DefaultKeepAlive
+	} else if keepAlive < 0 {
+		keepAlive = 0
+	}
+	return wsconn.NewConn(ws, raw, nil, keepAlive), nil
+}
+
+// webSocketURL 由服务器地址得到 WebSocket 地址：http(s):// 换为 ws(s):// 并追加 /ws，ws(s):// 地址原样使用
+// webSocketURL derives the WebSocket URL from the server URL: http(s):// becomes
+// ws(s):// with /ws appended, while ws(s):// URLs are used as they are
+func webSocketURL(serverURL string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+		return serverURL, nil
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("invalid server URL %s: unsupported scheme %q", serverURL, u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + webSocketPath
+	return u.String(), nil
+}
+
+// createWebSocketTransport 创建带有 Token、User-Agent、自定义头和 TLS 配置的 WebSocket 传输
+// createWebSocketTransport creates a WebSocket transport carrying the token, the
+// User-Agent, the custom headers and the TLS configuration
+func createWebSocketTransport(config Config, customHeaders map[string]string) (*WebSocketClientTransport, error) {
+	endpoint, err := webSocketURL(config.ServerURL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if config.AuthToken != "" {
+		header.Set("Authorization", "Bearer "+config.AuthToken)
+	}
+	if config.UserAgent != "" {
+		header.Set("User-Agent", config.UserAgent)
+	}
+	for key, value := range customHeaders {
+		header.Set(key, value)
+	}
+	return &WebSocketClientTransport{Endpoint: endpoint, Header: header, TLSConfig: tlsConfig}, nil
+}
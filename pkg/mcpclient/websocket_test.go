@@ -0,0 +1,53 @@
+package mcpclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestWebSocketURL 测试由服务器地址推导 WebSocket 地址
+func TestWebSocketURL(t *testing.T) {
+	cases := []struct {
+		serverURL string
+		want      string
+		wantErr   bool
+	}{
+		{serverURL: "https://localhost:8443", want: "wss://localhost:8443/ws"},
+		{serverURL: "http://mcp.example.com/", want: "ws://mcp.example.com/ws"},
+		{serverURL: "https://gateway.example.com/k8s-mcp", want: "wss://gateway.example.com/k8s-mcp/ws"},
+		{serverURL: "wss://gateway.example.com/custom", want: "wss://gateway.example.com/custom"},
+		{serverURL: "ftp://localhost", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := webSocketURL(tc.serverURL)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tc.serverURL, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%s: expected %s, got %s (%v)", tc.serverURL, tc.want, got, err)
+		}
+	}
+}
+
+// TestConnectUnknownTransport 测试未知的传输方式在连接前报错
+func TestConnectUnknownTransport(t *testing.T) {
+	client, err := NewClient(Config{ServerURL: "https://localhost:8443", AuthToken: "token", Transport: "grpc"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := client.Connect(context.Background()); err == nil || !strings.Contains(err.Error(), `unknown transport "grpc"`) {
+		t.Errorf("expected an unknown transport error, got %v", err)
+	}
+}
+
+// TestWebSocketTransportRejectsBadEndpoint 测试非 ws/wss 地址在拨号前报错
+func TestWebSocketTransportRejectsBadEndpoint(t *testing.T) {
+	transport := &WebSocketClientTransport{Endpoint: "https://localhost:8443/ws"}
+	if _, err := transport.Connect(context.Background()); err == nil || !strings.Contains(err.Error(), "scheme must be ws or wss") {
+		t.Errorf("expected a scheme error, got %v", err)
+	}
+}
@@ -1,25 +1,60 @@
 package types
 
+import "time"
+
 // Namespace 命名空间信息
 type Namespace struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Age    string `json:"age"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Age         string            `json:"age"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Quotas      []ResourceQuota   `json:"quotas,omitempty"`
+	LimitRanges []LimitRange      `json:"limit_ranges,omitempty"`
+}
+
+// ResourceQuota ResourceQuota 信息（硬限制与已使用量）
+type ResourceQuota struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Hard      map[string]string `json:"hard,omitempty"`
+	Used      map[string]string `json:"used,omitempty"`
+}
+
+// LimitRange LimitRange 信息
+type LimitRange struct {
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	Limits    []LimitRangeItem `json:"limits,omitempty"`
+}
+
+// LimitRangeItem LimitRange 中的单条限制
+type LimitRangeItem struct {
+	Type           string            `json:"type"`
+	Max            map[string]string `json:"max,omitempty"`
+	Min            map[string]string `json:"min,omitempty"`
+	Default        map[string]string `json:"default,omitempty"`
+	DefaultRequest map[string]string `json:"default_request,omitempty"`
 }
 
 // NamespacesResult list_namespaces 命令的结果
 type NamespacesResult struct {
-	Namespaces string `json:"namespaces"`
+	Namespaces string      `json:"namespaces"`
+	Items      []Namespace `json:"items,omitempty"`
 }
 
 // Pod Pod 信息
 type Pod struct {
-	Name      string            `json:"name"`
-	Namespace string            `json:"namespace"`
-	Status    string            `json:"status"`
-	Ready     string            `json:"ready"`
-	Restarts  int               `json:"restarts"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"`
+	Ready     string `json:"ready"`
+	Restarts  int    `json:"restarts"`
+	// Owner 控制器，格式为 kind/name，ReplicaSet 解析为其 Deployment；没有控制器时为 "<none>"
+	Owner     string            `json:"owner"`
+	OwnerKind string            `json:"owner_kind,omitempty"`
 	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
@@ -31,6 +66,7 @@ type Service struct {
 	ClusterIP string            `json:"cluster_ip"`
 	Ports     string            `json:"ports"`
 	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
@@ -42,29 +78,86 @@ type Deployment struct {
 	UpToDate  string            `json:"up_to_date"`
 	Available string            `json:"available"`
 	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
 // Node 节点信息
 type Node struct {
-	Name    string            `json:"name"`
-	Status  string            `json:"status"`
-	Roles   string            `json:"roles"`
-	Version string            `json:"version"`
-	Age     string            `json:"age"`
-	Labels  map[string]string `json:"labels,omitempty"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Roles     string            `json:"roles"`
+	Version   string            `json:"version"`
+	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// NodeDetails describe_node 返回的节点详情，Pressure 列出当前为 True 的压力状况，Allocated 为节点上 Pod 的资源汇总
+type NodeDetails struct {
+	Name             string                `json:"name"`
+	Status           string                `json:"status"`
+	Roles            string                `json:"roles"`
+	Unschedulable    bool                  `json:"unschedulable,omitempty"`
+	Pressure         []string              `json:"pressure,omitempty"`
+	KubeletVersion   string                `json:"kubelet_version"`
+	OSImage          string                `json:"os_image"`
+	KernelVersion    string                `json:"kernel_version"`
+	Architecture     string                `json:"architecture"`
+	ContainerRuntime string                `json:"container_runtime"`
+	Addresses        []string              `json:"addresses,omitempty"`
+	Taints           []string              `json:"taints,omitempty"`
+	Conditions       []NodeConditionDetail `json:"conditions"`
+	Capacity         map[string]string     `json:"capacity,omitempty"`
+	Allocatable      map[string]string     `json:"allocatable,omitempty"`
+	Allocated        []AllocatedResource   `json:"allocated"`
+	Pods             []NodePod             `json:"pods"`
+	Age              string                `json:"age"`
+	CreatedAt        string                `json:"created_at,omitempty"`
+	Labels           map[string]string     `json:"labels,omitempty"`
+}
+
+// NodeConditionDetail 节点状况及其心跳和变更时间
+type NodeConditionDetail struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastHeartbeatTime  string `json:"last_heartbeat_time,omitempty"`
+	LastTransitionTime string `json:"last_transition_time,omitempty"`
+}
+
+// AllocatedResource 节点上 Pod 的 requests/limits 之和，例如 "750m (37%)"
+type AllocatedResource struct {
+	Resource string `json:"resource"`
+	Requests string `json:"requests"`
+	Limits   string `json:"limits"`
+}
+
+// NodePod 节点上未终止的 Pod 及其 CPU/内存 requests 和 limits
+type NodePod struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	CPURequests    string `json:"cpu_requests"`
+	CPULimits      string `json:"cpu_limits"`
+	MemoryRequests string `json:"memory_requests"`
+	MemoryLimits   string `json:"memory_limits"`
+	Age            string `json:"age"`
 }
 
 // Event 事件信息
 type Event struct {
-	Type      string            `json:"type"`
-	Reason    string            `json:"reason"`
-	Message   string            `json:"message"`
-	Source    string            `json:"source"`
-	Count     int               `json:"count"`
-	FirstSeen string            `json:"first_seen"`
-	LastSeen  string            `json:"last_seen"`
-	Labels    map[string]string `json:"labels,omitempty"`
+	Type          string            `json:"type"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Object        string            `json:"object,omitempty"`
+	Reason        string            `json:"reason"`
+	Message       string            `json:"message"`
+	Source        string            `json:"source"`
+	Count         int               `json:"count"`
+	FirstSeen     string            `json:"first_seen"`
+	LastSeen      string            `json:"last_seen"`
+	LastTimestamp string            `json:"last_timestamp,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
 }
 
 // RBACPermission RBAC 权限检查结果
@@ -73,12 +166,41 @@ type RBACPermission struct {
 	Reason  string `json:"reason,omitempty"`
 }
 
+// ResourceRule 允许对资源执行的操作，"*" 表示全部
+type ResourceRule struct {
+	Verbs         []string `json:"verbs"`
+	APIGroups     []string `json:"api_groups,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resource_names,omitempty"`
+}
+
+// NonResourceRule 允许对非资源 URL（如 /healthz）执行的操作
+type NonResourceRule struct {
+	Verbs           []string `json:"verbs"`
+	NonResourceURLs []string `json:"non_resource_urls"`
+}
+
+// PermissionList 当前凭据在命名空间中的权限列表，Incomplete 为 true 时列表可能不完整
+type PermissionList struct {
+	Namespace        string            `json:"namespace"`
+	ResourceRules    []ResourceRule    `json:"resource_rules"`
+	NonResourceRules []NonResourceRule `json:"non_resource_rules,omitempty"`
+	Incomplete       bool              `json:"incomplete,omitempty"`
+	EvaluationError  string            `json:"evaluation_error,omitempty"`
+}
+
 // PodLogOptions Pod 日志选项
 type PodLogOptions struct {
+	PodName       string `json:"pod_name"`
+	Namespace     string `json:"namespace,omitempty"`
 	ContainerName string `json:"container_name,omitempty"`
 	TailLines     int    `json:"tail_lines,omitempty"`
 	Previous      bool   `json:"previous,omitempty"`
-	ClusterName   string `json:"cluster_name,omitempty"`
+	// SinceTime 只返回该时间及之后的日志，未设置 TailLines 时不限制行数
+	SinceTime *time.Time `json:"since_time,omitempty"`
+	// Timestamps 在每行前加上 RFC3339Nano 时间戳
+	Timestamps  bool   `json:"timestamps,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
 }
 
 // ConfigMap 信息
@@ -87,14 +209,829 @@ type ConfigMap struct {
 	Namespace string            `json:"namespace"`
 	DataCount int               `json:"data_count"`
 	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
 
+// ConfigMapData ConfigMap 的数据，BinaryData 的值为 base64 编码
+type ConfigMapData struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string]string `json:"binary_data,omitempty"`
+}
+
+// SecretKey Secret 中的键及其值的大小（字节），不包含值本身
+type SecretKey struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+}
+
+// SecretKeys Secret 的类型和键列表
+type SecretKeys struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Type      string      `json:"type"`
+	Keys      []SecretKey `json:"keys"`
+}
+
 // StatefulSet 信息
 type StatefulSet struct {
 	Name      string            `json:"name"`
 	Namespace string            `json:"namespace"`
 	Ready     string            `json:"ready"`
 	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// PersistentVolume PersistentVolume 信息，Claim 为绑定的 PVC（namespace/name）
+type PersistentVolume struct {
+	Name          string            `json:"name"`
+	Capacity      string            `json:"capacity"`
+	AccessModes   string            `json:"access_modes"`
+	ReclaimPolicy string            `json:"reclaim_policy"`
+	Status        string            `json:"status"`
+	Claim         string            `json:"claim,omitempty"`
+	StorageClass  string            `json:"storage_class,omitempty"`
+	Age           string            `json:"age"`
+	CreatedAt     string            `json:"created_at,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
+// PersistentVolumeClaim PersistentVolumeClaim 信息，Pending 时 Message 为最近一条相关事件的消息
+type PersistentVolumeClaim struct {
+	Name         string            `json:"name"`
+	Namespace    string            `json:"namespace"`
+	Status       string            `json:"status"`
+	Volume       string            `json:"volume,omitempty"`
+	Capacity     string            `json:"capacity,omitempty"`
+	AccessModes  string            `json:"access_modes,omitempty"`
+	StorageClass string            `json:"storage_class,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	Age          string            `json:"age"`
+	CreatedAt    string            `json:"created_at,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// Ingress Ingress 信息，Address 为负载均衡器地址
+type Ingress struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Class     string            `json:"class,omitempty"`
+	Hosts     []string          `json:"hosts"`
+	Address   string            `json:"address,omitempty"`
+	Rules     []IngressRoute    `json:"rules,omitempty"`
+	TLS       []IngressTLS      `json:"tls,omitempty"`
+	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// IngressRoute Ingress 中 host 和 path 到后端的映射，Backend 为 service:port 或 Kind/name
+type IngressRoute struct {
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+	Backend string `json:"backend"`
+}
+
+// IngressTLS Ingress 的 TLS 配置
+type IngressTLS struct {
+	Hosts      []string `json:"hosts,omitempty"`
+	SecretName string   `json:"secret_name,omitempty"`
+}
+
+// NetworkPolicy NetworkPolicy 信息，Ingress 和 Egress 描述该方向的限制（not restricted、deny all 或规则数量）
+type NetworkPolicy struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	PodSelector string            `json:"pod_selector"`
+	PolicyTypes []string          `json:"policy_types,omitempty"`
+	Ingress     string            `json:"ingress"`
+	Egress      string            `json:"egress"`
+	Age         string            `json:"age"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// CronJob CronJob 信息，LastSchedule 为上次调度距今的时间（例如 "5h ago"），从未调度时为 "<none>"
+type CronJob struct {
+	Name               string            `json:"name"`
+	Namespace          string            `json:"namespace"`
+	Schedule           string            `json:"schedule"`
+	Suspend            bool              `json:"suspend"`
+	Active             int               `json:"active"`
+	LastSchedule       string            `json:"last_schedule"`
+	LastScheduleTime   string            `json:"last_schedule_time,omitempty"`
+	LastSuccessfulTime string            `json:"last_successful_time,omitempty"`
+	Age                string            `json:"age"`
+	CreatedAt          string            `json:"created_at,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+}
+
+// Job Job 信息，Completions 为 "成功数/期望完成数"，CronJob 为所属的 CronJob
+type Job struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Status      string            `json:"status"`
+	Completions string            `json:"completions"`
+	Active      int32             `json:"active"`
+	Succeeded   int32             `json:"succeeded"`
+	Failed      int32             `json:"failed"`
+	Duration    string            `json:"duration"`
+	CronJob     string            `json:"cronjob,omitempty"`
+	StartTime   string            `json:"start_time,omitempty"`
+	Age         string            `json:"age"`
+	CreatedAt   string            `json:"created_at,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// HorizontalPodAutoscaler HorizontalPodAutoscaler（autoscaling/v2）信息，Target 为扩缩容目标（Kind/name）
+type HorizontalPodAutoscaler struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	Target          string            `json:"target"`
+	MinReplicas     int32             `json:"min_replicas"`
+	MaxReplicas     int32             `json:"max_replicas"`
+	CurrentReplicas int32             `json:"current_replicas"`
+	DesiredReplicas int32             `json:"desired_replicas"`
+	Metrics         []HPAMetric       `json:"metrics,omitempty"`
+	Conditions      []HPACondition    `json:"conditions,omitempty"`
+	Age             string            `json:"age"`
+	CreatedAt       string            `json:"created_at,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+}
+
+// HPAMetric HPA 的指标及其当前值和目标值，例如 Current "45%"、Target "80%"
+type HPAMetric struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Target  string `json:"target"`
+}
+
+// HPACondition HPA 的状况，例如 ScalingLimited
+type HPACondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PodDisruptionBudget PodDisruptionBudget 信息，未设置的 MinAvailable 或 MaxUnavailable 为 "N/A"
+type PodDisruptionBudget struct {
+	Name               string            `json:"name"`
+	Namespace          string            `json:"namespace"`
+	MinAvailable       string            `json:"min_available"`
+	MaxUnavailable     string            `json:"max_unavailable"`
+	AllowedDisruptions int32             `json:"allowed_disruptions"`
+	CurrentHealthy     int32             `json:"current_healthy"`
+	DesiredHealthy     int32             `json:"desired_healthy"`
+	ExpectedPods       int32             `json:"expected_pods"`
+	Age                string            `json:"age"`
+	CreatedAt          string            `json:"created_at,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+}
+
+// WaitResult wait_for 的结果，Status 为最后观察到的状态摘要
+type WaitResult struct {
+	Condition string `json:"condition"`
+	Met       bool   `json:"met"`
+	Elapsed   string `json:"elapsed"`
+	Status    string `json:"status,omitempty"`
+}
+
+// StreamedEvent stream_events 收到的事件，Offset 为相对开始监听的时间，例如 "+2.5s"
+type StreamedEvent struct {
+	Offset  string `json:"offset"`
+	Type    string `json:"type"`
+	Object  string `json:"object"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Source  string `json:"source,omitempty"`
+	Count   int    `json:"count,omitempty"`
+}
+
+// EventStream stream_events 的结果，Events 按到达顺序排列，超过上限时 Truncated 为 true
+type EventStream struct {
+	Namespace string          `json:"namespace"`
+	Filter    string          `json:"filter,omitempty"`
+	Duration  string          `json:"duration"`
+	Events    []StreamedEvent `json:"events"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// DebugContainer debug_pod 添加的临时容器，以及进入该容器的命令
+type DebugContainer struct {
+	Pod           string   `json:"pod"`
+	Namespace     string   `json:"namespace"`
+	Container     string   `json:"container"`
+	Image         string   `json:"image"`
+	Command       []string `json:"command,omitempty"`
+	AttachCommand string   `json:"attach_command"`
+	ExecCommand   string   `json:"exec_command"`
+}
+
+// PodFile cp_from_pod 从容器中读取的文件，Size 为文件的完整大小，Bytes 为返回的字节数，超过上限时 Truncated 为 true
+type PodFile struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Bytes     int    `json:"bytes"`
+	Truncated bool   `json:"truncated"`
+	MimeType  string `json:"mime_type"`
+}
+
+// PodFileUpload cp_to_pod 写入容器的文件
+type PodFileUpload struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Bytes     int    `json:"bytes"`
+}
+
+// RolloutHistory rollout_history 返回的 Deployment 历史版本，Template 为指定版本的 Pod 模板（YAML）
+type RolloutHistory struct {
+	Deployment      string            `json:"deployment"`
+	Namespace       string            `json:"namespace"`
+	CurrentRevision int64             `json:"current_revision"`
+	Revisions       []RolloutRevision `json:"revisions"`
+	Template        string            `json:"template,omitempty"`
+}
+
+// RolloutRevision Deployment 的一个历史版本及其对应的 ReplicaSet
+type RolloutRevision struct {
+	Revision      int64    `json:"revision"`
+	ReplicaSet    string   `json:"replicaset"`
+	Replicas      int32    `json:"replicas"`
+	ReadyReplicas int32    `json:"ready_replicas"`
+	ChangeCause   string   `json:"change_cause,omitempty"`
+	Images        []string `json:"images"`
+	Current       bool     `json:"current,omitempty"`
+	Age           string   `json:"age"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+}
+
+// RollbackResult rollback_deployment 的结果，模板已与目标版本相同时 Rolled 为 false
+type RollbackResult struct {
+	Deployment   string   `json:"deployment"`
+	Namespace    string   `json:"namespace"`
+	FromRevision int64    `json:"from_revision"`
+	ToRevision   int64    `json:"to_revision"`
+	Images       []string `json:"images"`
+	Rolled       bool     `json:"rolled"`
+	Message      string   `json:"message"`
+	// Readiness 为 wait_for_ready=true 时回滚后的就绪摘要
+	Readiness *WorkloadReadiness `json:"readiness,omitempty"`
+}
+
+// WorkloadReadiness 修改操作后 Deployment、StatefulSet 或 DaemonSet 的就绪摘要。Replicas 为期望副本数
+// (DaemonSet 为期望调度的节点数)，超时仍未就绪时 TimedOut 为 true，FailingPod、FailingReason 为第一个未就绪的 Pod 及其原因；
+// 无法获取就绪状态时 Error 说明原因
+type WorkloadReadiness struct {
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	Namespace          string `json:"namespace"`
+	Ready              bool   `json:"ready"`
+	TimedOut           bool   `json:"timed_out,omitempty"`
+	Elapsed            string `json:"elapsed,omitempty"`
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observed_generation"`
+	Replicas           int32  `json:"replicas"`
+	UpdatedReplicas    int32  `json:"updated_replicas"`
+	ReadyReplicas      int32  `json:"ready_replicas"`
+	AvailableReplicas  int32  `json:"available_replicas"`
+	FailingPod         string `json:"failing_pod,omitempty"`
+	FailingReason      string `json:"failing_reason,omitempty"`
+	Summary            string `json:"summary"`
+	Error              string `json:"error,omitempty"`
+}
+
+// NodeSchedulingResult cordon_node/uncordon_node 的结果，节点已处于目标状态时 Changed 为 false
+type NodeSchedulingResult struct {
+	Node          string `json:"node"`
+	Unschedulable bool   `json:"unschedulable"`
+	Changed       bool   `json:"changed"`
+}
+
+// MetadataResult label_resource/annotate_resource 的结果，Field 为 labels 或 annotations，
+// Before、After 为修改前后的完整集合（为空时省略），没有变更时 Changed 为 false
+type MetadataResult struct {
+	ResourceType string            `json:"resource_type"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Name         string            `json:"name"`
+	Field        string            `json:"field"`
+	Before       map[string]string `json:"before,omitempty"`
+	After        map[string]string `json:"after,omitempty"`
+	Changed      bool              `json:"changed"`
+	// Readiness 为 wait_for_ready=true 时修改后的就绪摘要
+	Readiness *WorkloadReadiness `json:"readiness,omitempty"`
+}
+
+// DrainResult drain_node 的结果，Evicted、Skipped 为 namespace/name 形式的 Pod
+type DrainResult struct {
+	Node     string            `json:"node"`
+	Cordoned bool              `json:"cordoned"`
+	Evicted  []string          `json:"evicted,omitempty"`
+	Skipped  []string          `json:"skipped,omitempty"`
+	Blocked  []DrainBlockedPod `json:"blocked,omitempty"`
+	Complete bool              `json:"complete"`
+	Message  string            `json:"message"`
+}
+
+// DrainBlockedPod 阻止排空的 Pod 及原因，被 PDB 阻止时 PodDisruptionBudget 为该 PDB 名称
+type DrainBlockedPod struct {
+	Pod                 string `json:"pod"`
+	Reason              string `json:"reason"`
+	PodDisruptionBudget string `json:"pod_disruption_budget,omitempty"`
+}
+
+// ClusterReport generate_cluster_report 生成的集群快照，Unavailable 记录获取失败的部分及原因
+type ClusterReport struct {
+	Cluster           string                  `json:"cluster"`
+	GeneratedAt       string                  `json:"generated_at"`
+	Version           string                  `json:"version,omitempty"`
+	Platform          string                  `json:"platform,omitempty"`
+	Nodes             *NodeSummary            `json:"nodes,omitempty"`
+	Namespaces        []NamespaceSummary      `json:"namespaces,omitempty"`
+	UnreadyWorkloads  []UnreadyWorkload       `json:"unready_workloads,omitempty"`
+	WarningEvents     []Event                 `json:"warning_events,omitempty"`
+	WarningEventCount int                     `json:"warning_event_count"`
+	NodeConditions    []NodeCondition         `json:"node_conditions,omitempty"`
+	VolumeClaims      []PersistentVolumeClaim `json:"volume_claims,omitempty"`
+	Unavailable       map[string]string       `json:"unavailable,omitempty"`
+}
+
+// NodeSummary 节点数量及 kubelet 版本汇总
+type NodeSummary struct {
+	Total    int      `json:"total"`
+	Ready    int      `json:"ready"`
+	NotReady int      `json:"not_ready"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// NamespaceSummary 命名空间及其中的 Pod 和 Deployment 数量
+type NamespaceSummary struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Pods        int    `json:"pods"`
+	Deployments int    `json:"deployments"`
+}
+
+// UnreadyWorkload 未全部就绪的 Deployment 或 StatefulSet
+type UnreadyWorkload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Ready     string `json:"ready"`
+}
+
+// NodeCondition 节点的异常状况（压力状况为 True 或 Ready 不为 True）
+type NodeCondition struct {
+	Node    string `json:"node"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ResourceComparison compare_resource 的结果，Diff 为从 ClusterA 到 ClusterB 的 unified diff，
+// Summary 为差异摘要；对象在某个集群中不存在时 ExistsInA/ExistsInB 为 false，并在 Summary 中说明
+type ResourceComparison struct {
+	ResourceType string   `json:"resource_type"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Name         string   `json:"name"`
+	ClusterA     string   `json:"cluster_a"`
+	ClusterB     string   `json:"cluster_b"`
+	ExistsInA    bool     `json:"exists_in_a"`
+	ExistsInB    bool     `json:"exists_in_b"`
+	Identical    bool     `json:"identical"`
+	Summary      []string `json:"summary,omitempty"`
+	Diff         string   `json:"diff,omitempty"`
+}
+
+// NamespaceComparison compare_namespace 的结果，每种资源类型一项
+type NamespaceComparison struct {
+	Namespace string                        `json:"namespace"`
+	ClusterA  string                        `json:"cluster_a"`
+	ClusterB  string                        `json:"cluster_b"`
+	Resources []NamespaceResourceComparison `json:"resources"`
+}
+
+// NamespaceResourceComparison 某种资源类型的对象名称对比，Different、Identical 为两个集群中都存在的对象
+type NamespaceResourceComparison struct {
+	ResourceType string   `json:"resource_type"`
+	OnlyInA      []string `json:"only_in_a,omitempty"`
+	OnlyInB      []string `json:"only_in_b,omitempty"`
+	Different    []string `json:"different,omitempty"`
+	Identical    []string `json:"identical,omitempty"`
+}
+
+// ToolErrorDetails 工具调用失败时的机器可读错误分类，与可读的错误信息一起返回。
+// Reason 为错误类别（如 NotFound、Forbidden、Timeout），Resource 为出错的对象（kind[.group]/name），
+// Retryable 表示原样重试是否可能成功，Suggestion 为下一步建议
+type ToolErrorDetails struct {
+	Reason     string `json:"reason"`
+	Resource   string `json:"resource,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// NamespaceCreation create_namespace 的结果
+type NamespaceCreation struct {
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NamespaceDeletion delete_namespace 的结果，Workloads 为删除前命名空间中仍有的工作负载数量（按资源类型）。
+// Waited 为 true 时 Terminated 表示命名空间已被完全删除；超时仍未删除时 Phase、Finalizers 和 Conditions 说明阻塞删除的原因
+type NamespaceDeletion struct {
+	Namespace  string         `json:"namespace"`
+	Workloads  map[string]int `json:"workloads,omitempty"`
+	Waited     bool           `json:"waited"`
+	Terminated bool           `json:"terminated"`
+	Phase      string         `json:"phase,omitempty"`
+	Finalizers []string       `json:"finalizers,omitempty"`
+	Conditions []string       `json:"conditions,omitempty"`
+	Elapsed    string         `json:"elapsed,omitempty"`
+	Message    string         `json:"message"`
+}
+
+// ResourceDeletion delete_resource 的结果。删除 ConfigMap 或 Secret 时 References 为设置 force 后仍被忽略的引用者，
+// Skipped 为未能完成的引用检查
+type ResourceDeletion struct {
+	ResourceType string            `json:"resource_type"`
+	Namespace    string            `json:"namespace,omitempty"`
+	Name         string            `json:"name"`
+	Forced       bool              `json:"forced,omitempty"`
+	References   []ConfigReference `json:"references,omitempty"`
+	Skipped      []string          `json:"skipped,omitempty"`
+	Message      string            `json:"message"`
+}
+
+// WorkloadTopology get_workload_topology 的结果：命名空间中 Ingress、Service、工作负载和 Pod 之间的关系图，
+// Text 为同一关系图的缩进文本形式。OrphanedServices 为选择器不匹配任何 Pod 的 Service，UnownedPods 为没有控制器的 Pod
+type WorkloadTopology struct {
+	Namespace        string         `json:"namespace"`
+	Nodes            []TopologyNode `json:"nodes"`
+	Edges            []TopologyEdge `json:"edges"`
+	OrphanedServices []string       `json:"orphaned_services,omitempty"`
+	UnownedPods      []string       `json:"unowned_pods,omitempty"`
+	Text             string         `json:"text"`
+}
+
+// TopologyNode 关系图中的节点，ID 为 Kind/name；Status 为 Pod 的状态，Flags 标记问题（orphaned、unowned、missing）
+type TopologyNode struct {
+	ID     string   `json:"id"`
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Status string   `json:"status,omitempty"`
+	Flags  []string `json:"flags,omitempty"`
+}
+
+// TopologyEdge 关系图中的边，Relation 为 routes（Ingress 到 Service）、selects（Service 到 Pod）或 owns（工作负载到 Pod），
+// Detail 为补充说明，如 Ingress 的 host/path 和端口
+type TopologyEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// ServiceEndpoints get_service_endpoints 的结果：Service 端口与容器端口的对应关系、就绪和未就绪的端点及其后端 Pod，
+// 以及选择器匹配的 Pod。Source 为端点的来源（EndpointSlice 或旧版 Endpoints），Warnings 列出发现的问题，Text 为同样内容的文本
+type ServiceEndpoints struct {
+	Service   string               `json:"service"`
+	Namespace string               `json:"namespace"`
+	Type      string               `json:"type"`
+	ClusterIP string               `json:"cluster_ip,omitempty"`
+	Selector  map[string]string    `json:"selector,omitempty"`
+	Source    string               `json:"source,omitempty"`
+	Ports     []ServicePortMapping `json:"ports"`
+	Ready     int                  `json:"ready"`
+	NotReady  int                  `json:"not_ready"`
+	Endpoints []ServiceEndpoint    `json:"endpoints"`
+	Pods      []BackendPod         `json:"pods"`
+	Warnings  []string             `json:"warnings,omitempty"`
+	Text      string               `json:"text"`
+}
+
+// ServicePortMapping Service 端口及其 targetPort 在选中的 Pod 中对应的容器端口（"容器:端口"），
+// UnresolvedPods 为没有该命名端口的 Pod
+type ServicePortMapping struct {
+	Name           string   `json:"name,omitempty"`
+	Protocol       string   `json:"protocol"`
+	Port           int32    `json:"port"`
+	TargetPort     string   `json:"target_port"`
+	ContainerPorts []string `json:"container_ports,omitempty"`
+	UnresolvedPods []string `json:"unresolved_pods,omitempty"`
+}
+
+// ServiceEndpoint Service 的一个端点地址，Ports 为其端口（"名称 端口/协议"），Pod 和 Node 为其后端 Pod 和所在节点
+type ServiceEndpoint struct {
+	Address     string   `json:"address"`
+	Ports       []string `json:"ports,omitempty"`
+	Ready       bool     `json:"ready"`
+	Terminating bool     `json:"terminating,omitempty"`
+	Pod         string   `json:"pod,omitempty"`
+	Node        string   `json:"node,omitempty"`
+}
+
+// BackendPod Service 选择器匹配的 Pod；Endpoint 为其端点地址（没有端点时为空），ReadinessFailing 为就绪探针失败的容器
+type BackendPod struct {
+	Name             string   `json:"name"`
+	Status           string   `json:"status"`
+	Ready            bool     `json:"ready"`
+	Endpoint         string   `json:"endpoint,omitempty"`
+	ReadinessFailing []string `json:"readiness_failing,omitempty"`
+}
+
+// ResourceUsage get_resource_usage 的结果：Scope 内未终止 Pod 的 CPU 和内存 requests/limits 汇总，
+// 与 ResourceQuota 硬限制以及（所有命名空间范围时）节点可分配总量的对比，和按 requests 排名前 10 的 Pod。Text 为同样内容的文本表格
+type ResourceUsage struct {
+	Scope     string               `json:"scope"`
+	Pods      int                  `json:"pods"`
+	Resources []ResourceUsageEntry `json:"resources"`
+	Quotas    []QuotaUsage         `json:"quotas,omitempty"`
+	TopCPU    []PodResourceUsage   `json:"top_cpu,omitempty"`
+	TopMemory []PodResourceUsage   `json:"top_memory,omitempty"`
+	Text      string               `json:"text"`
+}
+
+// ResourceUsageEntry 单项资源（cpu 或 memory）的汇总。Allocatable 为节点可分配总量，百分比为占可分配量的比例，
+// 只在所有命名空间范围时提供；PodsWithoutRequests 和 PodsWithoutLimits 为未设置该资源 request 或 limit 的 Pod 数，
+// PodsWithoutLimits 大于 0 时 Limits 只是下限
+type ResourceUsageEntry struct {
+	Resource            string   `json:"resource"`
+	Requests            string   `json:"requests"`
+	Limits              string   `json:"limits"`
+	Allocatable         string   `json:"allocatable,omitempty"`
+	RequestsPercent     *float64 `json:"requests_percent,omitempty"`
+	LimitsPercent       *float64 `json:"limits_percent,omitempty"`
+	PodsWithoutRequests int      `json:"pods_without_requests"`
+	PodsWithoutLimits   int      `json:"pods_without_limits"`
+}
+
+// QuotaUsage ResourceQuota 中的一项 CPU 或内存硬限制（如 requests.cpu、limits.memory），Used 为命名空间中 Pod 的汇总，
+// Percent 为占 Hard 的百分比。带 scope 的 ResourceQuota 只统计部分 Pod，此时 Used 取自其 status.used
+type QuotaUsage struct {
+	Namespace string  `json:"namespace"`
+	Quota     string  `json:"quota"`
+	Resource  string  `json:"resource"`
+	Used      string  `json:"used"`
+	Hard      string  `json:"hard"`
+	Percent   float64 `json:"percent"`
+}
+
+// PodResourceUsage 单个 Pod 的有效 requests 和 limits，未设置的 limit 为空
+type PodResourceUsage struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	CPURequests    string `json:"cpu_requests"`
+	CPULimits      string `json:"cpu_limits,omitempty"`
+	MemoryRequests string `json:"memory_requests"`
+	MemoryLimits   string `json:"memory_limits,omitempty"`
+}
+
+// PodFailureExplanation explain_pod_failure 的结果：各容器的状态、Pod 最近的事件、重启过的容器上一个实例的日志，
+// 以及按规则得出的"症状 → 证据 → 可能原因"。Text 为同样内容的文本报告
+type PodFailureExplanation struct {
+	Namespace  string               `json:"namespace"`
+	Pod        string               `json:"pod"`
+	Status     string               `json:"status"`
+	Containers []ContainerDiagnosis `json:"containers"`
+	Events     []Event              `json:"events"`
+	Findings   []FailureFinding     `json:"findings"`
+	Text       string               `json:"text"`
+}
+
+// ContainerDiagnosis 单个容器（Init 为 true 时是 init 容器）的当前状态和上一次终止的信息。
+// PreviousLogs 为上一个实例的最后 50 行日志，获取失败时原因记录在 LogsError 中
+type ContainerDiagnosis struct {
+	Name         string `json:"name"`
+	Init         bool   `json:"init,omitempty"`
+	Image        string `json:"image"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restart_count"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+	Message      string `json:"message,omitempty"`
+	ExitCode     *int32 `json:"exit_code,omitempty"`
+	LastReason   string `json:"last_reason,omitempty"`
+	LastExitCode *int32 `json:"last_exit_code,omitempty"`
+	OOMKilled    bool   `json:"oom_killed,omitempty"`
+	PreviousLogs string `json:"previous_logs,omitempty"`
+	LogsError    string `json:"logs_error,omitempty"`
+}
+
+// FailureFinding 一条诊断结论：Rule 为命中的规则名，Symptom 为现象，Evidence 为支持的证据，Cause 为可能原因和建议
+type FailureFinding struct {
+	Container string   `json:"container"`
+	Rule      string   `json:"rule"`
+	Symptom   string   `json:"symptom"`
+	Evidence  []string `json:"evidence"`
+	Cause     string   `json:"cause"`
+}
+
+// VersionSkew 节点 kubelet 版本与 API server 版本的差异。Kubelets 为各 kubelet 版本的节点数，
+// MaxMinorSkew 为 API server 次版本号与最旧 kubelet 次版本号之差。有 kubelet 比 API server 新，
+// 或落后超过版本偏差策略允许的次版本数 (1.28 起为 3，此前为 2) 时 Supported 为 false
+type VersionSkew struct {
+	APIServer    string         `json:"api_server"`
+	Kubelets     map[string]int `json:"kubelets"`
+	MaxMinorSkew int            `json:"max_minor_skew"`
+	Supported    bool           `json:"supported"`
+}
+
+// IssueReport find_issues 的结果：Scope 内发现的卫生问题，按类别排序。Scanned 为每种资源扫描的对象数，
+// Truncated 为达到扫描上限而只扫描了部分对象的资源类型，Skipped 为因所需资源无法完整列出而未执行的检查及原因，
+// Counts 为每个类别的问题数。Text 为按类别分组的文本
+type IssueReport struct {
+	Scope     string         `json:"scope"`
+	Scanned   map[string]int `json:"scanned"`
+	Truncated []string       `json:"truncated,omitempty"`
+	Skipped   []string       `json:"skipped,omitempty"`
+	Counts    map[string]int `json:"counts"`
+	Issues    []Issue        `json:"issues"`
+	Text      string         `json:"text"`
+}
+
+// Issue 一个问题：Category 为类别，Severity 为 warning 或 info，Kind/Namespace/Name 为有问题的对象，
+// Message 说明问题，Remediation 为一行修复建议
+type Issue struct {
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// ConfigReference 引用 ConfigMap 或 Secret 的对象：Kind/Namespace/Name 为引用者 (工作负载、不由工作负载创建的 Pod、
+// ServiceAccount 或 Ingress)，Via 为引用方式，例如 "volume config"、"projected volume certs"、"env DB_PASSWORD (container api)"
+type ConfigReference struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Via       []string `json:"via"`
+}
+
+// ConfigReferenceReport check_references 的结果：Kind/Namespace/Name 为被检查的 ConfigMap 或 Secret，References 为引用它的对象，
+// 按类型和名称排序。Scanned 为每种资源扫描的对象数；Skipped 非空时检查不完整 (资源无法列出或被截断)，未扫描到的对象可能仍在引用它
+type ConfigReferenceReport struct {
+	Kind       string            `json:"kind"`
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	Referenced bool              `json:"referenced"`
+	References []ConfigReference `json:"references"`
+	Scanned    map[string]int    `json:"scanned"`
+	Skipped    []string          `json:"skipped,omitempty"`
+}
+
+// DistroResource 发行版特有资源 (例如 OpenShift Route) 的摘要信息，Fields 为该类型的摘要字段，
+// 例如 Route 的 host、service、port 和 tls
+type DistroResource struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Kind      string            `json:"kind"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Age       string            `json:"age"`
+	CreatedAt string            `json:"created_at,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
 }
+
+// ImageInventory 工作负载使用的镜像清单：Scope 为扫描范围，Source 为 pods 或 deployments，Images 为去重后按镜像引用排序的镜像，
+// Registries 为按镜像仓库汇总的数量 (仅 group_by=registry)，Text 为按使用次数排序的文本 (仅 format=text)
+type ImageInventory struct {
+	Scope      string          `json:"scope"`
+	Source     string          `json:"source"`
+	Images     []ImageUsage    `json:"images"`
+	Registries []RegistryUsage `json:"registries,omitempty"`
+	Text       string          `json:"text,omitempty"`
+}
+
+// ImageUsage 一个镜像及其使用情况：Registry/Repository/Tag/Digest 为解析后的镜像引用，Mutable 表示镜像没有通过摘要固定
+// (标签可以被重新推送，包括 :latest 和没有标签的镜像)，PullPolicies 为使用该镜像的容器的拉取策略，PullSecrets 为引用的拉取凭证 (namespace/name)
+type ImageUsage struct {
+	Image        string          `json:"image"`
+	Registry     string          `json:"registry"`
+	Repository   string          `json:"repository"`
+	Tag          string          `json:"tag,omitempty"`
+	Digest       string          `json:"digest,omitempty"`
+	Mutable      bool            `json:"mutable"`
+	PullPolicies []string        `json:"pull_policies"`
+	PullSecrets  []string        `json:"pull_secrets,omitempty"`
+	Workloads    []ImageWorkload `json:"workloads"`
+}
+
+// ImageWorkload 使用镜像的工作负载，Containers 为其中使用该镜像的容器，init 容器和临时容器分别以 init: 和 ephemeral: 开头
+type ImageWorkload struct {
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	Containers []string `json:"containers"`
+}
+
+// RegistryUsage 一个镜像仓库的汇总：不同镜像数、使用这些镜像的工作负载数，以及其中没有通过摘要固定的镜像数
+type RegistryUsage struct {
+	Registry      string `json:"registry"`
+	Images        int    `json:"images"`
+	Workloads     int    `json:"workloads"`
+	MutableImages int    `json:"mutable_images"`
+}
+
+// HelmRelease 从 Helm 的 release 存储 (类型为 helm.sh/release.v1 的 Secret) 读取的 release 最新版本：
+// Chart/ChartVersion/AppVersion 来自 chart 元数据，Status 为 Helm 状态 (deployed、failed、pending-upgrade 等)，
+// Revision 为版本号，LastDeployed 为最近部署时间 (RFC3339 UTC)
+type HelmRelease struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chart_version"`
+	AppVersion   string `json:"app_version,omitempty"`
+	Status       string `json:"status"`
+	Revision     int    `json:"revision"`
+	LastDeployed string `json:"last_deployed,omitempty"`
+}
+
+// HelmReleasesResult list_helm_releases 的结果，Scope 为实际查询的范围
+type HelmReleasesResult struct {
+	Releases []HelmRelease `json:"releases"`
+	Scope    string        `json:"scope,omitempty"`
+}
+
+// HelmReleaseDetails Helm release 的某个版本：除 HelmRelease 的字段外，Description 为 Helm 记录的说明，
+// Values 为 chart 默认值与用户值合并后的计算值 (疑似敏感的键已脱敏)，Resources 为 manifest 创建的对象
+type HelmReleaseDetails struct {
+	Name         string                 `json:"name"`
+	Namespace    string                 `json:"namespace"`
+	Chart        string                 `json:"chart"`
+	ChartVersion string                 `json:"chart_version"`
+	AppVersion   string                 `json:"app_version,omitempty"`
+	Status       string                 `json:"status"`
+	Revision     int                    `json:"revision"`
+	LastDeployed string                 `json:"last_deployed,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	Values       map[string]interface{} `json:"values,omitempty"`
+	Resources    []HelmResource         `json:"resources"`
+}
+
+// HelmResource Helm release 的 manifest 中的一个对象，Namespace 仅在 manifest 中指定时出现
+type HelmResource struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// ChangeInfo get_change_info 的结果：对象的各字段管理者 (来自 metadata.managedFields，按时间从新到旧排列)、
+// 是否存在 kubectl.kubernetes.io/last-applied-configuration 注解，以及与该对象相关的最近事件。
+// LastChangedBy/LastChangedAt 为最近一次写入的管理者和时间；事件获取失败时 EventsError 给出原因
+type ChangeInfo struct {
+	Kind          string         `json:"kind"`
+	Namespace     string         `json:"namespace,omitempty"`
+	Name          string         `json:"name"`
+	LastChangedBy string         `json:"last_changed_by,omitempty"`
+	LastChangedAt string         `json:"last_changed_at,omitempty"`
+	LastApplied   bool           `json:"last_applied_configuration"`
+	Managers      []FieldManager `json:"managers"`
+	Events        []Event        `json:"events"`
+	EventsError   string         `json:"events_error,omitempty"`
+}
+
+// FieldManager managedFields 中的一个条目：Operation 为 Apply 或 Update，Subresource 为写入的子资源 (如 status)，
+// Time 为该管理者最近一次修改的时间 (RFC3339 UTC)，Fields 为其拥有的字段，
+// 以可读路径表示 (如 spec.template.spec.containers[name=app].image)
+type FieldManager struct {
+	Manager     string   `json:"manager"`
+	Operation   string   `json:"operation"`
+	Subresource string   `json:"subresource,omitempty"`
+	APIVersion  string   `json:"api_version,omitempty"`
+	Time        string   `json:"time,omitempty"`
+	Fields      []string `json:"fields"`
+}
+
+// ExplainResult explain_resource 的结果：与 kubectl explain 相同，说明资源类型 (FieldPath 为空) 或其字段的类型、描述和直接子字段
+type ExplainResult struct {
+	Kind        string         `json:"kind"`
+	APIVersion  string         `json:"api_version"`
+	Resource    string         `json:"resource"`
+	FieldPath   string         `json:"field_path,omitempty"`
+	Type        string         `json:"type"`
+	Description string         `json:"description,omitempty"`
+	Fields      []ExplainField `json:"fields,omitempty"`
+}
+
+// ExplainField 字段的一个直接子字段，Description 只保留描述的第一句
+type ExplainField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
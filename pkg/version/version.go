@@ -0,0 +1,36 @@
+// Package version holds build information injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/AceDarkknight/k8s-mcp/pkg/version.Version=v1.2.0 \
+//	  -X github.com/AceDarkknight/k8s-mcp/pkg/version.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X github.com/AceDarkknight/k8s-mcp/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+//
+// 包 version 保存链接时通过 -ldflags 注入的构建信息。
+package version
+
+import "fmt"
+
+var (
+	// Version is the release version, "dev" for local builds
+	// Version 发布版本号，本地构建为 "dev"
+	Version = "dev"
+
+	// GitCommit is the commit the binary was built from
+	// GitCommit 构建二进制文件所用的提交
+	GitCommit = "unknown"
+
+	// BuildDate is the build time in RFC3339 UTC
+	// BuildDate 构建时间（RFC3339 UTC 格式）
+	BuildDate = "unknown"
+)
+
+// String returns a one-line description of the build, as printed by the version command
+// String 返回构建信息的单行描述，供 version 命令输出
+func String(program string) string {
+	return fmt.Sprintf("%s %s (commit %s, built %s)", program, Version, GitCommit, BuildDate)
+}
+
+// UserAgent returns "<program>/<version>" for HTTP user agents
+// UserAgent 返回用于 HTTP UserAgent 的 "<program>/<version>"
+func UserAgent(program string) string {
+	return program + "/" + Version
+}
@@ -0,0 +1,16 @@
+package version
+
+import "testing"
+
+// TestString 测试版本信息格式
+func TestString(t *testing.T) {
+	defer func(v, c, d string) { Version, GitCommit, BuildDate = v, c, d }(Version, GitCommit, BuildDate)
+	Version, GitCommit, BuildDate = "v1.2.0", "abc1234", "2024-01-01T00:00:00Z"
+
+	if got, want := String("k8s-mcp-server"), "k8s-mcp-server v1.2.0 (commit abc1234, built 2024-01-01T00:00:00Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := UserAgent("k8s-mcp"), "k8s-mcp/v1.2.0"; got != want {
+		t.Errorf("UserAgent() = %q, want %q", got, want)
+	}
+}